@@ -13,6 +13,37 @@ hive.s3.aws-access-key={{ .Values.spec.config.awsAccessKeyID }}
 {{- if .Values.spec.config.awsSecretAccessKey }}
 hive.s3.aws-secret-key={{ .Values.spec.config.awsSecretAccessKey }}
 {{- end}}
+{{- if .Values.spec.config.awsS3Endpoint }}
+hive.s3.endpoint={{ .Values.spec.config.awsS3Endpoint }}
+{{- if hasPrefix "http://" .Values.spec.config.awsS3Endpoint }}
+hive.s3.ssl.enabled=false
+{{- end}}
+{{- end}}
+{{- if .Values.spec.config.awsS3PathStyleAccess }}
+hive.s3.path-style-access=true
+{{- end}}
+{{- if .Values.spec.config.awsS3ServerSideEncryption }}
+hive.s3.sse.enabled=true
+{{- if eq .Values.spec.config.awsS3ServerSideEncryption "SSE-KMS" }}
+hive.s3.sse.type=KMS
+{{- if .Values.spec.config.awsS3KMSKeyID }}
+hive.s3.sse.kms-key-id={{ .Values.spec.config.awsS3KMSKeyID }}
+{{- end}}
+{{- else }}
+hive.s3.sse.type=S3
+{{- end}}
+{{- end}}
+{{- if .Values.spec.config.azureStorageAccountName }}
+hive.azure.wasb-storage-account={{ .Values.spec.config.azureStorageAccountName }}
+hive.azure.abfs-storage-account={{ .Values.spec.config.azureStorageAccountName }}
+{{- end}}
+{{- if .Values.spec.config.azureStorageAccountKey }}
+hive.azure.wasb-access-key={{ .Values.spec.config.azureStorageAccountKey }}
+hive.azure.abfs-access-key={{ .Values.spec.config.azureStorageAccountKey }}
+{{- end}}
+{{- if .Values.spec.config.gcs.serviceAccountKeySecretName }}
+hive.gcs.json-key-file-path=/opt/gcs-credentials/{{ .Values.spec.config.gcs.serviceAccountKeySecretKey }}
+{{- end}}
 {{ end }}
 
 {{- define "presto-jmx-catalog-properties" -}}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/operator-framework/operator-metering/pkg/configbundle"
+	cbClientset "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned"
+)
+
+var (
+	configBundleKubeconfig       string
+	configBundleNamespace        string
+	configBundleFile             string
+	configBundleOutputFormat     string
+	configBundleNamespaceMapping []string
+)
+
+var exportConfigCmd = &cobra.Command{
+	Use:   "export-config",
+	Short: "exports the ReportDataSources, ReportGenerationQueries and ScheduledReports as a single bundle",
+	RunE:  exportConfig,
+}
+
+var importConfigCmd = &cobra.Command{
+	Use:   "import-config",
+	Short: "imports a bundle created by export-config, creating or updating each resource",
+	RunE:  importConfig,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{exportConfigCmd, importConfigCmd} {
+		cmd.Flags().StringVar(&configBundleKubeconfig, "kubeconfig", "", "use kubeconfig provided instead of detecting defaults")
+		cmd.Flags().StringVarP(&configBundleFile, "file", "f", "-", "the file to write the bundle to, or read it from. - uses stdout or stdin")
+	}
+	exportConfigCmd.Flags().StringVarP(&configBundleNamespace, "namespace", "n", "", "the namespace to export resources from. If empty, resources from all namespaces are exported")
+	exportConfigCmd.Flags().StringVarP(&configBundleOutputFormat, "output", "o", "yaml", "the format to write the bundle in, either yaml or json")
+	importConfigCmd.Flags().StringSliceVar(&configBundleNamespaceMapping, "namespace-mapping", nil, "a list of source=destination pairs, importing resources from the source namespace of the bundle into the destination namespace")
+}
+
+func exportConfig(cmd *cobra.Command, args []string) error {
+	client, err := newConfigBundleClient()
+	if err != nil {
+		return err
+	}
+
+	namespace := configBundleNamespace
+	if namespace == "" {
+		namespace = metav1.NamespaceAll
+	}
+	dataSources, err := client.MeteringV1alpha1().ReportDataSources(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list ReportDataSources: %v", err)
+	}
+	queries, err := client.MeteringV1alpha1().ReportGenerationQueries(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list ReportGenerationQueries: %v", err)
+	}
+	scheduledReports, err := client.MeteringV1alpha1().ScheduledReports(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list ScheduledReports: %v", err)
+	}
+
+	bundle := configbundle.New(dataSources.Items, queries.Items, scheduledReports.Items)
+
+	var data []byte
+	switch configBundleOutputFormat {
+	case "yaml":
+		data, err = yaml.Marshal(bundle)
+	case "json":
+		data, err = json.MarshalIndent(bundle, "", "  ")
+		data = append(data, '\n')
+	default:
+		return fmt.Errorf("invalid output format %q, must be yaml or json", configBundleOutputFormat)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to encode bundle: %v", err)
+	}
+
+	if configBundleFile == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return ioutil.WriteFile(configBundleFile, data, 0644)
+}
+
+func importConfig(cmd *cobra.Command, args []string) error {
+	mapping, err := parseNamespaceMapping(configBundleNamespaceMapping)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if configBundleFile != "-" {
+		f, err := os.Open(configBundleFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("unable to read bundle: %v", err)
+	}
+	// JSON is valid YAML, so bundles in either format can be imported.
+	var bundle configbundle.Bundle
+	if err := yaml.Unmarshal(data, &bundle); err != nil {
+		return fmt.Errorf("unable to decode bundle: %v", err)
+	}
+	if err := bundle.Validate(); err != nil {
+		return fmt.Errorf("invalid bundle: %v", err)
+	}
+
+	client, err := newConfigBundleClient()
+	if err != nil {
+		return err
+	}
+	results, err := configbundle.Import(client, bundle.RemapNamespaces(mapping))
+	for _, result := range results {
+		fmt.Printf("%s %s %s/%s\n", strings.ToLower(result.Action), result.Kind, result.Namespace, result.Name)
+	}
+	return err
+}
+
+// parseNamespaceMapping parses a list of source=destination pairs.
+func parseNamespaceMapping(pairs []string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, pair := range pairs {
+		split := strings.SplitN(pair, "=", 2)
+		if len(split) != 2 || split[0] == "" || split[1] == "" {
+			return nil, fmt.Errorf("invalid namespace mapping %q, must be in the form source=destination", pair)
+		}
+		mapping[split[0]] = split[1]
+	}
+	return mapping, nil
+}
+
+func newConfigBundleClient() (cbClientset.Interface, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = configBundleKubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{})
+	kubeConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get Kubernetes client config: %v", err)
+	}
+	client, err := cbClientset.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create Metering client: %v", err)
+	}
+	return client, nil
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-metering/pkg/operator"
+)
+
+var openAPICmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "prints the OpenAPI document describing the report results API",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Print(operator.OpenAPISpec)
+	},
+}
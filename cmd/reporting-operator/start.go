@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	goflag "flag"
 	"fmt"
 	"io/ioutil"
@@ -16,6 +17,8 @@ import (
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/operator-framework/operator-metering/pkg/operator"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+	"github.com/operator-framework/operator-metering/pkg/presto"
 )
 
 var (
@@ -28,6 +31,7 @@ var (
 	prometheusDataSourceImportFrom string
 
 	logLevelStr         string
+	logFormat           string
 	logFullTimestamp    bool
 	logDisableTimestamp bool
 )
@@ -48,6 +52,9 @@ var startCmd = &cobra.Command{
 
 func AddCommands() {
 	rootCmd.AddCommand(startCmd)
+	rootCmd.AddCommand(exportConfigCmd)
+	rootCmd.AddCommand(importConfigCmd)
+	rootCmd.AddCommand(openAPICmd)
 }
 
 func init() {
@@ -60,21 +67,90 @@ func init() {
 	cfg.PrometheusQueryConfig.ChunkSize = new(meta.Duration)
 
 	startCmd.Flags().StringVar(&logLevelStr, "log-level", log.DebugLevel.String(), "log level")
+	startCmd.Flags().StringVar(&logFormat, "log-format", "text", "the format of log messages, either text, or json to log each message as a JSON object so logs can be indexed")
 	startCmd.Flags().BoolVar(&logFullTimestamp, "log-timestamp", true, "log full timestamp if true, otherwise log time since startup")
 	startCmd.Flags().BoolVar(&logDisableTimestamp, "disable-timestamp", false, "disable timestamp logging")
 
 	startCmd.Flags().StringVar(&cfg.Kubeconfig, "kubeconfig", "", "use kubeconfig provided instead of detecting defaults")
 	startCmd.Flags().StringVar(&cfg.Namespace, "namespace", "", "namespace the operator is running in")
+	startCmd.Flags().BoolVar(&cfg.AllNamespaces, "all-namespaces", false, "If true, process resources in all namespaces instead of only the namespace the operator is running in. Table names will include the namespace of the resource.")
+	startCmd.Flags().StringSliceVar(&cfg.TargetNamespaces, "target-namespaces", nil, "If non-empty, a list of namespaces to process resources in instead of the namespace the operator is running in. Table names will include the namespace of the resource.")
 	startCmd.Flags().StringVar(&cfg.HiveHost, "hive-host", defaultHiveHost, "the hostname:port for connecting to Hive")
+	startCmd.Flags().BoolVar(&cfg.HiveTLSConfig.Enabled, "hive-use-tls", false, "If true, uses TLS to connect to Hive")
+	startCmd.Flags().StringVar(&cfg.HiveTLSConfig.CAFile, "hive-ca-file", "", "File containing the CA certificate used to verify Hive's serving certificate.")
+	startCmd.Flags().StringVar(&cfg.HiveTLSConfig.ClientCertFile, "hive-client-cert-file", "", "File containing the client certificate used to authenticate against Hive.")
+	startCmd.Flags().StringVar(&cfg.HiveTLSConfig.ClientKeyFile, "hive-client-key-file", "", "File containing the client private key used to authenticate against Hive.")
+	startCmd.Flags().StringVar(&cfg.HiveTLSConfig.ServerName, "hive-tls-server-name", "", "Server name used to verify Hive's serving certificate, if different from the hostname of hive-host.")
+	startCmd.Flags().BoolVar(&cfg.HiveTLSConfig.InsecureSkipVerify, "hive-skip-tls-verify", false, "Skip TLS verification of Hive's serving certificate")
+	startCmd.Flags().DurationVar(&cfg.HiveConnBackoff, "hive-conn-backoff", operator.DefaultHiveConnBackoff, "how long to wait after the first failed attempt to connect to Hive, increasing after each further attempt")
+	startCmd.Flags().IntVar(&cfg.HiveMaxConnRetries, "hive-max-conn-retries", operator.DefaultHiveMaxConnRetries, "how many attempts are made to connect to Hive, and to retry queries failing due to closed connections")
+	startCmd.Flags().DurationVar(&cfg.HiveQueryTimeout, "hive-query-timeout", 0, "if non-zero, the timeout of queries run using Hive")
+	startCmd.Flags().StringVar(&cfg.TableCompression, "table-compression", "", "if set, the codec used to compress the files of datasource and report tables stored as orc or parquet, one of none, snappy, zstd or gzip")
+	startCmd.Flags().StringVar(&cfg.Currency.Code, "currency", reporting.DefaultCurrencyCode, "the ISO 4217 code of the currency costs are reported in, which PricingPolicy rates are assumed to use")
+	startCmd.Flags().IntVar(&cfg.Currency.Precision, "currency-precision", reporting.DefaultCurrencyPrecision, "the number of decimal places costs are rounded to by queries using the costPrecision template function")
+	startCmd.Flags().StringVar(&cfg.ClusterID, "cluster-id", "", "identifies the cluster in the cluster_id column of the data collected, so data from multiple clusters can be combined. Defaults to the UID of the kube-system namespace")
+	startCmd.Flags().StringVar(&cfg.HiveMetastoreHost, "hive-metastore-host", "", "the hostname:port of the Hive Metastore's Thrift API, if set tables and partitions are managed using the Metastore directly instead of running DDL statements using Hive")
 	startCmd.Flags().StringVar(&cfg.PrestoHost, "presto-host", defaultPrestoHost, "the hostname:port for connecting to Presto")
+	startCmd.Flags().StringVar(&cfg.QueryBackend, "query-backend", operator.QueryBackendPresto, "what's used to run queries and manage tables, either presto to use Presto and Hive, athena to use AWS Athena, bigquery to use Google BigQuery, or clickhouse to use ClickHouse")
+	startCmd.Flags().StringVar(&cfg.AthenaConfig.Region, "athena-region", "", "the AWS region Athena queries are run in when using the athena query backend")
+	startCmd.Flags().StringVar(&cfg.AthenaConfig.Database, "athena-database", "default", "the Athena database tables are created in when using the athena query backend")
+	startCmd.Flags().StringVar(&cfg.AthenaConfig.OutputLocation, "athena-output-location", "", "the s3:// URL Athena query results are written to when using the athena query backend")
+	startCmd.Flags().StringVar(&cfg.BigQueryConfig.ProjectID, "bigquery-project-id", "", "the Google Cloud project BigQuery queries are run in when using the bigquery query backend")
+	startCmd.Flags().StringVar(&cfg.BigQueryConfig.Dataset, "bigquery-dataset", "", "the BigQuery dataset tables are created in when using the bigquery query backend")
+	startCmd.Flags().StringVar(&cfg.BigQueryConfig.Location, "bigquery-location", "", "the location BigQuery queries are run in when using the bigquery query backend, if unset it's determined by BigQuery")
+	startCmd.Flags().StringVar(&cfg.ClickHouseConfig.Address, "clickhouse-address", "", "the URL of ClickHouse's HTTP interface when using the clickhouse query backend, for example http://clickhouse:8123")
+	startCmd.Flags().StringVar(&cfg.ClickHouseConfig.Database, "clickhouse-database", "default", "the ClickHouse database tables are created in when using the clickhouse query backend")
+	startCmd.Flags().StringVar(&cfg.ClickHouseConfig.Username, "clickhouse-username", "", "the username used to authenticate to ClickHouse when using the clickhouse query backend")
+	startCmd.Flags().StringVar(&cfg.ClickHouseConfig.Password, "clickhouse-password", "", "the password used to authenticate to ClickHouse when using the clickhouse query backend")
+	startCmd.Flags().StringVar((*string)(&cfg.PrestoDialect), "presto-dialect", string(presto.DialectPresto), "the protocol used for connecting to Presto, either presto for PrestoDB and PrestoSQL, or trino for Trino")
 	startCmd.Flags().StringVar(&cfg.PrometheusConfig.Address, "prometheus-host", defaultPromHost, "the URL string for connecting to Prometheus")
 	startCmd.Flags().BoolVar(&cfg.PrometheusConfig.SkipTLSVerify, "prometheus-skip-tls-verify", false, "Skip TLS verification")
 	startCmd.Flags().StringVar(&cfg.PrometheusConfig.BearerToken, "prometheus-bearer-token", "", "Bearer token to authenticate against Prometheus.")
+	startCmd.Flags().StringVar(&cfg.PrometheusConfig.BearerTokenFile, "prometheus-bearer-token-file", "", "File containing the bearer token to authenticate against Prometheus. Used if prometheus-bearer-token is empty.")
+	startCmd.Flags().BoolVar(&cfg.PrometheusConfig.UseServiceAccountToken, "prometheus-use-service-account-token", false, "If true, authenticates against Prometheus using the token of the service account the operator is running as.")
+	startCmd.Flags().StringVar(&cfg.PrometheusConfig.BasicAuthUsername, "prometheus-basic-auth-username", "", "Username to authenticate against Prometheus using basic auth.")
+	startCmd.Flags().StringVar(&cfg.PrometheusConfig.BasicAuthPassword, "prometheus-basic-auth-password", "", "Password to authenticate against Prometheus using basic auth.")
+	startCmd.Flags().StringVar(&cfg.PrometheusConfig.CAFile, "prometheus-ca-file", "", "File containing the CA certificate used to verify Prometheus' serving certificate.")
+	startCmd.Flags().StringVar(&cfg.PrometheusConfig.ClientCertFile, "prometheus-client-cert-file", "", "File containing the client certificate used to authenticate against Prometheus.")
+	startCmd.Flags().StringVar(&cfg.PrometheusConfig.ClientKeyFile, "prometheus-client-key-file", "", "File containing the client private key used to authenticate against Prometheus.")
+	startCmd.Flags().StringVar(&cfg.PrometheusConfig.TLSServerName, "prometheus-tls-server-name", "", "Server name used to verify Prometheus' serving certificate, if different from the hostname of prometheus-host.")
+	startCmd.Flags().Float64Var(&cfg.PrometheusConfig.QueryQPS, "prometheus-query-qps", 0, "If non-zero, limits the number of queries per second sent to each Prometheus instance, allowing bursts of up to the limit rounded up")
+	startCmd.Flags().IntVar(&cfg.PrometheusConfig.MaxInFlightQueries, "prometheus-max-in-flight-queries", 0, "If non-zero, limits the number of queries running at once against each Prometheus instance")
+
+	startCmd.Flags().StringVar(&cfg.AgentConfig.CentralURL, "agent-central-url", "", "If set, runs as an agent which only collects Prometheus metrics, pushing them to the reporting-operator with this API URL instead of storing them in Presto")
+	startCmd.Flags().StringVar(&cfg.AgentConfig.CentralNamespace, "agent-central-namespace", "", "the namespace of the ReportDataSources on the central reporting-operator metrics are pushed to. Defaults to the namespace the central reporting-operator is running in")
+	startCmd.Flags().StringVar(&cfg.AgentConfig.BearerTokenFile, "agent-bearer-token-file", "", "File containing the bearer token used to authenticate against the central reporting-operator.")
+	startCmd.Flags().StringVar(&cfg.AgentConfig.CAFile, "agent-ca-file", "", "File containing the CA certificate used to verify the central reporting-operator's serving certificate.")
+	startCmd.Flags().BoolVar(&cfg.AgentConfig.SkipTLSVerify, "agent-skip-tls-verify", false, "Skip verifying the central reporting-operator's serving certificate")
+
+	startCmd.Flags().StringVar(&cfg.TracingConfig.OTLPEndpoint, "tracing-otlp-endpoint", "", "If set, exports traces of report generation, Prometheus imports, and the queries they run to this OTLP/HTTP endpoint, such as http://jaeger-collector:4318")
+	startCmd.Flags().StringVar(&cfg.TracingConfig.ServiceName, "tracing-service-name", operator.DefaultTracingServiceName, "the service name of the exported spans")
+	startCmd.Flags().Float64Var(&cfg.TracingConfig.SampleRatio, "tracing-sample-ratio", 1, "the ratio of traces exported, between 0 and 1")
+	startCmd.Flags().DurationVar(&cfg.ResyncPeriod, "resync-period", operator.DefaultResyncPeriod, "how often every resource is added to it's work queue again, even if it hasn't changed. Set to 0 to disable")
+	startCmd.Flags().DurationVar(&cfg.QueueRateLimiterConfig.BaseDelay, "queue-base-delay", operator.DefaultQueueBaseDelay, "how long a resource which failed to be processed waits before it's first retry, doubling after each failure")
+	startCmd.Flags().DurationVar(&cfg.QueueRateLimiterConfig.MaxDelay, "queue-max-delay", operator.DefaultQueueMaxDelay, "the longest a resource which failed to be processed waits before being retried")
+	startCmd.Flags().Float64Var(&cfg.QueueRateLimiterConfig.QPS, "queue-qps", operator.DefaultQueueQPS, "the number of resources added to each work queue per second")
+	startCmd.Flags().IntVar(&cfg.QueueRateLimiterConfig.Burst, "queue-burst", operator.DefaultQueueBurst, "the number of resources which can be added to each work queue at once, above queue-qps")
+	startCmd.Flags().IntVar(&cfg.MaxRetriesConfig.Report, "report-max-retries", operator.DefaultReportMaxRetries, "the number of times a Report which failed to be processed is retried before it's marked as failed")
+	startCmd.Flags().IntVar(&cfg.MaxRetriesConfig.ScheduledReport, "scheduled-report-max-retries", operator.DefaultScheduledReportMaxRetries, "the number of times a ScheduledReport which failed to be processed is retried before it's marked as failed")
+	startCmd.Flags().IntVar(&cfg.MaxRetriesConfig.ReportDataSource, "report-datasource-max-retries", operator.DefaultReportDataSourceMaxRetries, "the number of times a ReportDataSource which failed to be processed is retried before it's marked as failed")
+	startCmd.Flags().IntVar(&cfg.MaxRetriesConfig.ReportGenerationQuery, "report-generation-query-max-retries", operator.DefaultReportGenerationQueryMaxRetries, "the number of times a ReportGenerationQuery which failed to be processed is retried before it's marked as failed")
+	startCmd.Flags().IntVar(&cfg.MaxRetriesConfig.PrestoTable, "presto-table-max-retries", operator.DefaultPrestoTableMaxRetries, "the number of times a PrestoTable which failed to be processed is retried before it's marked as failed")
 
 	startCmd.Flags().BoolVar(&cfg.DisablePromsum, "disable-promsum", false, "disables collecting Prometheus metrics periodically")
+	startCmd.Flags().BoolVar(&cfg.EnableRemoteWrite, "enable-remote-write", false, "enables the Prometheus remote-write endpoint, used by ReportDataSources with remoteWrite configured")
+	startCmd.Flags().BoolVar(&cfg.EnableGRPCAPI, "enable-grpc-api", false, "enables the gRPC API, which streams the results of reports, on "+operator.GRPCAPIAddress)
+	startCmd.Flags().Float64Var(&cfg.APIRateLimits.QPS, "api-qps", 0, "If non-zero, limits the number of API requests per second accepted from all clients, allowing bursts of up to the limit rounded up")
+	startCmd.Flags().Float64Var(&cfg.APIRateLimits.ClientQPS, "api-client-qps", 0, "If non-zero, limits the number of API requests per second accepted from each client, allowing bursts of up to the limit rounded up")
+	startCmd.Flags().IntVar(&cfg.APIRateLimits.MaxConcurrentDownloads, "api-max-concurrent-downloads", 0, "If non-zero, limits the number of API requests returning report results handled at once")
+	startCmd.Flags().IntVar(&cfg.APIRateLimits.MaxConcurrentClientDownloads, "api-max-concurrent-client-downloads", 0, "If non-zero, limits the number of API requests returning report results handled at once for each client")
+	startCmd.Flags().StringSliceVar(&cfg.TrustedProxies, "trusted-proxies", nil, "the IP addresses and CIDR ranges of proxies in front of the API, such as the auth proxy, whose X-Forwarded-User and X-Forwarded-For headers are trusted")
+	startCmd.Flags().StringVar(&cfg.AuditLogPath, "audit-log-path", "", "If set, each access to report results is recorded as a JSON object in this file, or on stdout if set to '-'")
+	startCmd.Flags().BoolVar(&cfg.AuthorizeResultsAccess, "authorize-results-access", false, "If enabled along with all-namespaces or target-namespaces, then the results API only returns results from namespaces the caller's bearer token is allowed to get the Report, ScheduledReport or ReportDataSource in")
 	startCmd.Flags().BoolVar(&cfg.LogDMLQueries, "log-dml-queries", false, "logDMLQueries controls if we log data manipulation queries made via Presto (SELECT, INSERT, etc)")
 	startCmd.Flags().BoolVar(&cfg.LogDDLQueries, "log-ddl-queries", false, "logDDLQueries controls if we log data definition language queries made via Hive (CREATE TABLE, DROP TABLE, etc)")
 	startCmd.Flags().BoolVar(&cfg.EnableFinalizers, "enable-finalizers", false, "If enabled, then finalizers will be set on some resources to ensure the reporting-operator is able to perform cleanup before the resource is deleted from the API")
+	startCmd.Flags().BoolVar(&cfg.PurgeDeletedReportData, "purge-deleted-report-data", false, "If enabled along with enable-finalizers, then the data files backing a Report's table will be deleted when the Report is deleted, in addition to dropping the table")
 
 	startCmd.Flags().DurationVar(&cfg.PrometheusQueryConfig.QueryInterval.Duration, "promsum-interval", operator.DefaultPrometheusQueryInterval, "controls how often the operator polls Prometheus for metrics")
 	startCmd.Flags().DurationVar(&cfg.PrometheusQueryConfig.StepSize.Duration, "promsum-step-size", operator.DefaultPrometheusQueryStepSize, "the query step size for Promethus query. This controls resolution of results")
@@ -83,14 +159,43 @@ func init() {
 
 	startCmd.Flags().DurationVar(&cfg.PrometheusDataSourceMaxQueryRangeDuration, "prometheus-datasource-max-query-range-duration", operator.DefaultPrometheusDataSourceMaxQueryRangeDuration, "If non-zero specifies the maximum duration of time to query from Prometheus. When backfilling, this value is used for the ChunkSize when querying Prometheus.")
 	startCmd.Flags().DurationVar(&cfg.PrometheusDataSourceMaxBackfillImportDuration, "prometheus-datasource-max-import-backfill-duration", operator.DefaultPrometheusDataSourceMaxBackfillImportDuration, "If non-zero specifies the maximum duration of time before the current to look back for data when backfilling. Has no effect if prometheus-datasource-import-from is set.")
+	startCmd.Flags().IntVar(&cfg.ReportDataSourceWorkers, "report-datasource-workers", operator.DefaultReportDataSourceWorkers, "the number of ReportDataSources processed, and Prometheus imports run, concurrently")
+	startCmd.Flags().IntVar(&cfg.PrometheusMaxConcurrentImportsPerEndpoint, "prometheus-max-concurrent-imports-per-endpoint", 0, "If non-zero, limits the number of Prometheus ReportDataSource imports running concurrently against each Prometheus URL")
 	startCmd.Flags().StringVar(&prometheusDataSourceImportFrom, "prometheus-datasource-import-from", "", "If non-empty, expects an RFC3339 timestamp indicating when Prometheus ReportDataSource data should be backfilled from.")
 
 	startCmd.Flags().DurationVar(&cfg.LeaderLeaseDuration, "lease-duration", defaultLeaseDuration, "controls how much time elapses before declaring leader")
+	startCmd.Flags().DurationVar(&cfg.LeaderRenewDeadline, "lease-renew-deadline", 0, "the duration the leader will retry refreshing leadership before giving up. Defaults to half of lease-duration if unset")
+	startCmd.Flags().DurationVar(&cfg.LeaderRetryPeriod, "lease-retry-period", operator.DefaultLeaderRetryPeriod, "the duration standby replicas wait between attempts to acquire leadership")
+	startCmd.Flags().DurationVar(&cfg.StandbyCheckInterval, "standby-check-interval", operator.DefaultStandbyCheckInterval, "how often a standby replica checks it's connection to Presto to keep it warm. Set to 0 to disable")
+	startCmd.Flags().DurationVar(&cfg.StorageBudgetCheckInterval, "storage-budget-check-interval", operator.DefaultStorageBudgetCheckInterval, "how often the usage of StorageLocations with a storage budget is checked. Set to 0 to disable")
+	startCmd.Flags().DurationVar(&cfg.PartitionCompactionInterval, "partition-compaction-interval", operator.DefaultPartitionCompactionInterval, "how often the partitions of ReportDataSources with compaction enabled are compacted. Set to 0 to disable")
+	startCmd.Flags().DurationVar(&cfg.RetentionCheckInterval, "retention-check-interval", operator.DefaultRetentionCheckInterval, "how often partitions of ReportDataSources older than their retention are pruned. Set to 0 to disable")
+	startCmd.Flags().DurationVar(&cfg.HDFSStorageCheckInterval, "hdfs-storage-check-interval", operator.DefaultHDFSStorageCheckInterval, "how often the replication factor and umask of HDFS StorageLocations are applied to the files stored in them. Set to 0 to disable")
+
+	startCmd.Flags().DurationVar(&cfg.ReportQueryTimeout, "report-query-timeout", operator.DefaultReportQueryTimeout, "how long the queries generating a Report or ScheduledReport can run before they're cancelled. Set to 0 to disable")
+	startCmd.Flags().DurationVar(&cfg.ShutdownDrainTimeout, "shutdown-drain-timeout", operator.DefaultShutdownDrainTimeout, "how long in-flight work has to finish when shutting down before it's queries are cancelled. Set to 0 to cancel them immediately")
+	startCmd.Flags().IntVar(&cfg.MaxConcurrentReports, "max-concurrent-reports", 0, "the number of Reports and ScheduledReports generated at once, with the rest waiting in their work queues. Set to 0 to disable")
+	startCmd.Flags().IntVar(&cfg.PrestoMaxConcurrentQueries, "presto-max-concurrent-queries", 0, "the number of report queries run against Presto at once, with the rest waiting in order of priority. Set to 0 to disable")
+
+	startCmd.Flags().IntVar(&cfg.ReportResultsCacheMaxRows, "report-results-cache-max-rows", operator.DefaultReportResultsCacheMaxRows, "the total number of rows of finished report results the API caches. Set to 0 to disable caching")
+	startCmd.Flags().DurationVar(&cfg.ReportResultsCacheTTL, "report-results-cache-ttl", operator.DefaultReportResultsCacheTTL, "how long the API caches finished report results. Set to 0 to disable caching")
+	startCmd.Flags().IntVar(&cfg.AdHocQueryMaxRows, "adhoc-query-max-rows", operator.DefaultAdHocQueryMaxRows, "the maximum number of rows a ReportGenerationQuery executed using the ad-hoc query API can return")
+	startCmd.Flags().StringVar(&cfg.PushgatewayURL, "pushgateway-url", "", "the URL of a Prometheus Pushgateway the results of ScheduledReports with spec.metrics set are pushed to after each run. Pushing is disabled if unset")
+	startCmd.Flags().StringVar(&cfg.PushgatewayJob, "pushgateway-job", operator.DefaultPushgatewayJob, "the job label of the metrics pushed to the Pushgateway")
+	startCmd.Flags().IntVar(&cfg.APIMaxQueueDepth, "api-max-queue-depth", operator.DefaultAPIMaxQueueDepth, "the number of items waiting in the work queues above which API requests, except requests storing data, are rejected with a 503. Set to 0 to disable")
+	startCmd.Flags().StringVar(&cfg.OperatorConfigName, "operator-config-name", operator.DefaultOperatorConfigName, "the name of the ReportingOperatorConfig in the operator's namespace which overrides this configuration, applying changes without restarting where possible")
 
 	startCmd.Flags().BoolVar(&cfg.APITLSConfig.UseTLS, "use-tls", false, "If true, uses TLS to secure HTTP API traffix")
 	startCmd.Flags().StringVar(&cfg.APITLSConfig.TLSCert, "tls-cert", "", "If use-tls is true, specifies the path to the TLS certificate.")
 	startCmd.Flags().StringVar(&cfg.APITLSConfig.TLSKey, "tls-key", "", "If use-tls is true, specifies the path to the TLS private key.")
 
+	startCmd.Flags().BoolVar(&cfg.EnableWebhooks, "enable-webhooks", false, "enables the admission webhooks validating Reports and defaulting ScheduledReports, served with TLS on "+operator.WebhookAddress)
+	startCmd.Flags().DurationVar(&cfg.ScheduledReportDefaults.GracePeriod, "scheduled-report-default-grace-period", 0, "the spec.gracePeriod set on new ScheduledReports without one by the defaulting webhook. Unset if 0")
+	startCmd.Flags().StringVar(&cfg.ScheduledReportDefaults.Timezone, "scheduled-report-default-timezone", "", "the spec.schedule.timezone set on new ScheduledReports without one by the defaulting webhook")
+	startCmd.Flags().StringVar(&cfg.ScheduledReportDefaults.StorageLocationName, "scheduled-report-default-storage-location", "", "the name of the StorageLocation set as the spec.output of new ScheduledReports without one by the defaulting webhook")
+	startCmd.Flags().StringVar(&cfg.WebhookTLSConfig.TLSCert, "webhook-tls-cert", "", "If enable-webhooks is true, specifies the path to the TLS certificate to use for the webhooks.")
+	startCmd.Flags().StringVar(&cfg.WebhookTLSConfig.TLSKey, "webhook-tls-key", "", "If enable-webhooks is true, specifies the path to the TLS private key to use for the webhooks.")
+
 	startCmd.Flags().BoolVar(&cfg.MetricsTLSConfig.UseTLS, "metrics-use-tls", false, "If true, uses TLS to secure Prometheus Metrics endpoint traffix")
 	startCmd.Flags().StringVar(&cfg.MetricsTLSConfig.TLSCert, "metrics-tls-cert", "", "If metrics-use-tls is true, specifies the path to the TLS certificate to use for the Metrics endpoint.")
 	startCmd.Flags().StringVar(&cfg.MetricsTLSConfig.TLSKey, "metrics-tls-key", "", "If metrics-use-tls is true, specifies the path to the TLS private key to use for the Metrics endpoint.")
@@ -142,16 +247,15 @@ func startReporting(cmd *cobra.Command, args []string) {
 		cfg.PrometheusDataSourceGlobalImportFromTime = &importFrom
 	}
 
-	signalStopCh := setupSignals()
-	runReporting(logger, cfg, signalStopCh)
+	runReporting(setupSignals(), logger, cfg)
 }
 
-func runReporting(logger log.FieldLogger, cfg operator.Config, stopCh <-chan struct{}) {
+func runReporting(ctx context.Context, logger log.FieldLogger, cfg operator.Config) {
 	op, err := operator.New(logger, cfg)
 	if err != nil {
 		logger.WithError(err).Fatal("unable to setup reporting-operator")
 	}
-	if err = op.Run(stopCh); err != nil {
+	if err = op.Run(ctx); err != nil {
 		logger.WithError(err).Fatal("error occurred while the reporting-operator was running")
 	}
 	logger.Infof("reporting-operator has stopped")
@@ -182,20 +286,36 @@ func SetFlagsFromEnv(fs *pflag.FlagSet, prefix string) (err error) {
 	return err
 }
 
-func setupSignals() chan struct{} {
+// setupSignals returns a context which is cancelled when a SIGINT or SIGTERM
+// is received.
+func setupSignals() context.Context {
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 
-	stopCh := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
 		sig := <-sigs
 		log.Infof("got signal %s, performing shutdown", sig)
-		close(stopCh)
+		cancel()
 	}()
-	return stopCh
+	return ctx
 }
 
 func newLogger() log.FieldLogger {
+	switch logFormat {
+	case "text":
+		log.SetFormatter(&log.TextFormatter{
+			FullTimestamp:    logFullTimestamp,
+			DisableTimestamp: logDisableTimestamp,
+		})
+	case "json":
+		log.SetFormatter(&log.JSONFormatter{
+			DisableTimestamp: logDisableTimestamp,
+		})
+	default:
+		log.Fatalf("invalid log format %q, must be text or json", logFormat)
+	}
+
 	logger := log.WithFields(log.Fields{
 		"app": "metering",
 	})
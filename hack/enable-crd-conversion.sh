@@ -0,0 +1,44 @@
+#!/bin/bash -e
+
+# Serves the v1alpha2 version of the Report and ScheduledReport CRDs, using
+# the conversion webhook served by reporting-operator in $METERING_NAMESPACE
+# to convert them to and from v1alpha1, which remains the storage version.
+# reporting-operator must have webhooks enabled, and
+# $WEBHOOK_CA_BUNDLE_FILE must contain the CA certificate which signed it's
+# webhook certificate.
+
+ROOT_DIR=$(dirname "${BASH_SOURCE}")/..
+source "${ROOT_DIR}/hack/common.sh"
+
+: "${WEBHOOK_CA_BUNDLE_FILE:?}"
+: "${REPORTING_OPERATOR_SERVICE_NAME:=reporting-operator}"
+
+CA_BUNDLE="$(base64 < "$WEBHOOK_CA_BUNDLE_FILE" | tr -d '\n')"
+
+PATCH="$(cat <<PATCH_EOF
+{
+  "spec": {
+    "versions": [
+      {"name": "v1alpha1", "served": true, "storage": true},
+      {"name": "v1alpha2", "served": true, "storage": false}
+    ],
+    "conversion": {
+      "strategy": "Webhook",
+      "webhookClientConfig": {
+        "service": {
+          "namespace": "$METERING_NAMESPACE",
+          "name": "$REPORTING_OPERATOR_SERVICE_NAME",
+          "path": "/convert"
+        },
+        "caBundle": "$CA_BUNDLE"
+      }
+    }
+  }
+}
+PATCH_EOF
+)"
+
+for CRD in reports.metering.openshift.io scheduledreports.metering.openshift.io; do
+    msg "Enabling v1alpha2 conversion for $CRD"
+    kubectl patch crd "$CRD" --type merge -p "$PATCH"
+done
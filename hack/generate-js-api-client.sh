@@ -0,0 +1,27 @@
+#!/bin/bash
+set -e
+
+# Generates a JavaScript client for the reporting-operator's results API from
+# it's OpenAPI document, using openapi-generator.
+
+ROOT_DIR=$(dirname "${BASH_SOURCE}")/..
+source "${ROOT_DIR}/hack/common.sh"
+
+: "${OUTPUT_DIR:=${ROOT_DIR}/out/js-api-client}"
+: "${OPENAPI_GENERATOR_IMAGE:=openapitools/openapi-generator-cli:v3.3.4}"
+
+mkdir -p "${OUTPUT_DIR}"
+OUTPUT_DIR="$(cd "${OUTPUT_DIR}" && pwd)"
+
+echo "Writing OpenAPI document to ${OUTPUT_DIR}/openapi.json"
+go run "${ROOT_DIR}/cmd/reporting-operator/"*.go openapi > "${OUTPUT_DIR}/openapi.json"
+
+echo "Generating JavaScript client in ${OUTPUT_DIR}"
+docker run --rm \
+    -u "$(id -u):$(id -g)" \
+    -v "${OUTPUT_DIR}:/local" \
+    "${OPENAPI_GENERATOR_IMAGE}" generate \
+    -i /local/openapi.json \
+    -g javascript \
+    -o /local \
+    --additional-properties usePromises=true,projectName=reporting-operator-client
@@ -30,6 +30,15 @@ ${CODEGEN_PKG}/generate-groups.sh "deepcopy,client,informer,lister" \
     metering:v1alpha1 \
     --go-header-file ${SCRIPT_ROOT}/hack/boilerplate.go.txt
 
+# v1alpha2 is only served through the conversion webhook, and the operator
+# uses v1alpha1, so it only needs deepcopy functions
+echo "Generating v1alpha2 deepcopy functions"
+${GOPATH}/bin/deepcopy-gen \
+    --input-dirs "${SCRIPT_PACKAGE}/pkg/apis/metering/v1alpha2" \
+    --bounding-dirs "${SCRIPT_PACKAGE}/pkg/apis" \
+    -O zz_generated.deepcopy \
+    --go-header-file ${SCRIPT_ROOT}/hack/boilerplate.go.txt
+
 # generate-groups doesn't do defaulters
 echo "Generating defaulters"
 ${GOPATH}/bin/defaulter-gen \
@@ -0,0 +1,97 @@
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxRequestSize limits the size of AdmissionReviews, which the API server
+// limits to a few megabytes.
+const maxRequestSize = 10 * 1024 * 1024
+
+// HandlerFunc admits the object in an AdmissionRequest.
+type HandlerFunc func(req *AdmissionRequest) *AdmissionResponse
+
+// Serve returns a http.HandlerFunc decoding the AdmissionReview sent by the
+// API server, and responding with the AdmissionResponse returned by
+// handler.
+func Serve(handler HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("unable to read request: %v", err), http.StatusBadRequest)
+			return
+		}
+		var review AdmissionReview
+		if err := json.Unmarshal(body, &review); err != nil {
+			http.Error(w, fmt.Sprintf("unable to decode AdmissionReview: %v", err), http.StatusBadRequest)
+			return
+		}
+		if review.Request == nil {
+			http.Error(w, "AdmissionReview has no request", http.StatusBadRequest)
+			return
+		}
+
+		resp := handler(review.Request)
+		resp.UID = review.Request.UID
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: APIVersion, Kind: Kind},
+			Response: resp,
+		})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("unable to encode AdmissionReview: %v", err), http.StatusInternalServerError)
+		}
+	}
+}
+
+// Allowed returns a response admitting the object.
+func Allowed() *AdmissionResponse {
+	return &AdmissionResponse{Allowed: true}
+}
+
+// Patched returns a response admitting the object, after applying the JSON
+// patch to it.
+func Patched(patch []byte) *AdmissionResponse {
+	patchType := PatchTypeJSONPatch
+	return &AdmissionResponse{
+		Allowed:   true,
+		Patch:     patch,
+		PatchType: &patchType,
+	}
+}
+
+// Denied returns a response rejecting the object, with message explaining
+// why to the user.
+func Denied(message string) *AdmissionResponse {
+	return &AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: message,
+			Reason:  metav1.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+		},
+	}
+}
+
+// Errored returns a response rejecting the object because it couldn't be
+// admitted, such as when it can't be decoded.
+func Errored(code int32, err error) *AdmissionResponse {
+	return &AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: err.Error(),
+			Code:    code,
+		},
+	}
+}
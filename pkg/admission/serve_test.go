@@ -0,0 +1,33 @@
+package admission
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeInvalidRequests(t *testing.T) {
+	handler := Serve(func(req *AdmissionRequest) *AdmissionResponse {
+		t.Fatal("expected invalid requests not to be admitted")
+		return nil
+	})
+	tests := map[string]struct {
+		method string
+		body   string
+		code   int
+	}{
+		"GET":             {method: "GET", code: http.StatusMethodNotAllowed},
+		"invalid JSON":    {method: "POST", body: "{", code: http.StatusBadRequest},
+		"missing request": {method: "POST", body: `{"kind": "AdmissionReview"}`, code: http.StatusBadRequest},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(tt.method, "/validate", strings.NewReader(tt.body)))
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
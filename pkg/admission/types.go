@@ -0,0 +1,55 @@
+package admission
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// The following types match the admission.k8s.io/v1beta1 AdmissionReview
+// sent to admission webhooks, containing only the fields the webhooks
+// need.
+
+const (
+	APIVersion = "admission.k8s.io/v1beta1"
+	Kind       = "AdmissionReview"
+)
+
+type AdmissionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *AdmissionRequest  `json:"request,omitempty"`
+	Response        *AdmissionResponse `json:"response,omitempty"`
+}
+
+type AdmissionRequest struct {
+	UID       types.UID                   `json:"uid"`
+	Kind      metav1.GroupVersionKind     `json:"kind"`
+	Resource  metav1.GroupVersionResource `json:"resource"`
+	Name      string                      `json:"name,omitempty"`
+	Namespace string                      `json:"namespace,omitempty"`
+	Operation Operation                   `json:"operation"`
+	Object    runtime.RawExtension        `json:"object,omitempty"`
+	OldObject runtime.RawExtension        `json:"oldObject,omitempty"`
+}
+
+type AdmissionResponse struct {
+	UID     types.UID      `json:"uid"`
+	Allowed bool           `json:"allowed"`
+	Result  *metav1.Status `json:"status,omitempty"`
+	// Patch is a JSON patch applied to the object by mutating webhooks.
+	Patch     []byte     `json:"patch,omitempty"`
+	PatchType *PatchType `json:"patchType,omitempty"`
+}
+
+type Operation string
+
+const (
+	Create  Operation = "CREATE"
+	Update  Operation = "UPDATE"
+	Delete  Operation = "DELETE"
+	Connect Operation = "CONNECT"
+)
+
+type PatchType string
+
+const PatchTypeJSONPatch PatchType = "JSONPatch"
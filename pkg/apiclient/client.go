@@ -0,0 +1,215 @@
+// Package apiclient is a client for the reporting-operator's report results
+// API, described by the OpenAPI document served at /api/openapi.json.
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Formats the results of a report can be returned in.
+const (
+	FormatJSON    = "json"
+	FormatCSV     = "csv"
+	FormatTabular = "tabular"
+	FormatHTML    = "html"
+)
+
+// ErrNotReady is returned when a report's results aren't available yet,
+// because it's still running or hasn't been processed.
+var ErrNotReady = errors.New("the report's results aren't available yet")
+
+// Error is returned when the API responds with an error.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("reporting-operator API returned %d: %s", e.StatusCode, e.Message)
+}
+
+type ListReportsResponse struct {
+	Reports []ReportSummary `json:"reports"`
+}
+
+// ReportSummary describes a Report or ScheduledReport, and where it's
+// results can be downloaded from.
+type ReportSummary struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Phase       string            `json:"phase"`
+	PeriodStart *time.Time        `json:"periodStart,omitempty"`
+	PeriodEnd   *time.Time        `json:"periodEnd,omitempty"`
+	RowCount    *int64            `json:"rowCount,omitempty"`
+	ResultURLs  map[string]string `json:"resultURLs,omitempty"`
+}
+
+type GetReportResults struct {
+	Results []ReportResultEntry `json:"results"`
+}
+
+type ReportResultEntry struct {
+	Values []ReportResultValue `json:"values"`
+}
+
+type ReportResultValue struct {
+	Name        string      `json:"name"`
+	Value       interface{} `json:"value"`
+	TableHidden bool        `json:"tableHidden"`
+	Unit        string      `json:"unit,omitempty"`
+}
+
+// Client makes requests to the reporting-operator's API.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+}
+
+// New returns a Client for the API at baseURL, such as
+// https://metering.example.com. If httpClient is nil, http.DefaultClient is
+// used. Authentication, such as a bearer token, should be configured using
+// the httpClient's Transport.
+func New(baseURL string, httpClient *http.Client) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL %q: %v", baseURL, err)
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{baseURL: u, httpClient: httpClient}, nil
+}
+
+// ListReports returns the Reports in the namespace. If namespace is empty,
+// the namespace reporting-operator runs in is used.
+func (c *Client) ListReports(ctx context.Context, namespace string) ([]ReportSummary, error) {
+	var resp ListReportsResponse
+	if err := c.getJSON(ctx, "/api/v1/reports", namespaceQuery(namespace), &resp); err != nil {
+		return nil, err
+	}
+	return resp.Reports, nil
+}
+
+// ListScheduledReports returns the ScheduledReports in the namespace. If
+// namespace is empty, the namespace reporting-operator runs in is used.
+func (c *Client) ListScheduledReports(ctx context.Context, namespace string) ([]ReportSummary, error) {
+	var resp ListReportsResponse
+	if err := c.getJSON(ctx, "/api/v1/scheduledreports", namespaceQuery(namespace), &resp); err != nil {
+		return nil, err
+	}
+	return resp.Reports, nil
+}
+
+// GetReportResults returns the results of a finished Report, including
+// hidden columns. If table is true, hidden columns are omitted.
+func (c *Client) GetReportResults(ctx context.Context, namespace, name string, table bool) (*GetReportResults, error) {
+	var resp GetReportResults
+	query := namespaceQuery(namespace)
+	query.Set("format", FormatJSON)
+	if err := c.getJSON(ctx, reportResultsPath(name, table), query, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetScheduledReportResults returns the results of a ScheduledReport, with
+// a map of column name to value for each row.
+func (c *Client) GetScheduledReportResults(ctx context.Context, namespace, name string) ([]map[string]interface{}, error) {
+	var resp []map[string]interface{}
+	query := namespaceQuery(namespace)
+	query.Set("name", name)
+	query.Set("format", FormatJSON)
+	if err := c.getJSON(ctx, "/api/v1/scheduledreports/get", query, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DownloadReportResults returns the results of a finished Report in the
+// format, which the caller must close. If table is true, hidden columns are
+// omitted.
+func (c *Client) DownloadReportResults(ctx context.Context, namespace, name, format string, table bool) (io.ReadCloser, error) {
+	query := namespaceQuery(namespace)
+	query.Set("format", format)
+	return c.get(ctx, reportResultsPath(name, table), query)
+}
+
+// DownloadScheduledReportResults returns the results of a ScheduledReport
+// in the format, which the caller must close.
+func (c *Client) DownloadScheduledReportResults(ctx context.Context, namespace, name, format string) (io.ReadCloser, error) {
+	query := namespaceQuery(namespace)
+	query.Set("name", name)
+	query.Set("format", format)
+	return c.get(ctx, "/api/v1/scheduledreports/get", query)
+}
+
+func reportResultsPath(name string, table bool) string {
+	if table {
+		return "/api/v2/reports/" + name + "/table"
+	}
+	return "/api/v2/reports/" + name + "/full"
+}
+
+func namespaceQuery(namespace string) url.Values {
+	query := url.Values{}
+	if namespace != "" {
+		query.Set("namespace", namespace)
+	}
+	return query
+}
+
+func (c *Client) getJSON(ctx context.Context, apiPath string, query url.Values, v interface{}) error {
+	body, err := c.get(ctx, apiPath, query)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		return fmt.Errorf("could not decode response from %s: %v", apiPath, err)
+	}
+	return nil
+}
+
+// get performs a GET request, returning the body of successful responses.
+func (c *Client) get(ctx context.Context, apiPath string, query url.Values) (io.ReadCloser, error) {
+	u := *c.baseURL
+	// the API may be served under a path prefix, such as a Kubernetes
+	// service proxy URL
+	u.Path = strings.TrimSuffix(u.Path, "/") + apiPath
+	u.RawPath = ""
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusAccepted:
+		resp.Body.Close()
+		return nil, ErrNotReady
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		message := strings.TrimSpace(string(body))
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
+			message = errResp.Error
+		}
+		return nil, &Error{StatusCode: resp.StatusCode, Message: message}
+	}
+	return resp.Body, nil
+}
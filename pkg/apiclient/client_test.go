@@ -0,0 +1,61 @@
+package apiclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/proxy/api/v1/reports":
+			w.Write([]byte(`{"reports":[{"name":"cpu","namespace":"metering","phase":"Finished","rowCount":2}]}`))
+		case "/proxy/api/v2/reports/cpu/table":
+			w.Write([]byte(`{"results":[{"values":[{"name":"namespace","value":"default","tableHidden":false,"unit":"kubernetes_namespace"}]}]}`))
+		case "/proxy/api/v2/reports/running/full":
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"error":"report is not processed yet"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(server.URL+"/proxy/", nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	reports, err := client.ListReports(ctx, "metering")
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, "cpu", reports[0].Name)
+	require.NotNil(t, reports[0].RowCount)
+	assert.Equal(t, int64(2), *reports[0].RowCount)
+
+	results, err := client.GetReportResults(ctx, "metering", "cpu", true)
+	require.NoError(t, err)
+	require.Len(t, results.Results, 1)
+	assert.Equal(t, ReportResultValue{Name: "namespace", Value: "default", Unit: "kubernetes_namespace"}, results.Results[0].Values[0])
+
+	_, err = client.GetReportResults(ctx, "metering", "running", false)
+	assert.Equal(t, ErrNotReady, err)
+
+	_, err = client.ListScheduledReports(ctx, "")
+	assert.Equal(t, &Error{StatusCode: http.StatusNotFound, Message: "Not found"}, err)
+
+	assert.Equal(t, []string{
+		"/proxy/api/v1/reports?namespace=metering",
+		"/proxy/api/v2/reports/cpu/table?format=json&namespace=metering",
+		"/proxy/api/v2/reports/running/full?format=json&namespace=metering",
+		"/proxy/api/v1/scheduledreports",
+	}, requests)
+}
@@ -0,0 +1,36 @@
+package v1alpha1
+
+import (
+	"k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition describes the state of a resource which doesn't have it's own
+// condition type.
+type Condition struct {
+	// Type of the condition.
+	Type ConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status v1.ConditionStatus `json:"status"`
+	// Last time the condition was checked.
+	// +optional
+	LastUpdateTime meta.Time `json:"lastUpdateTime,omitempty"`
+	// Last time the condition transit from one status to another.
+	// +optional
+	LastTransitionTime meta.Time `json:"lastTransitionTime,omitempty"`
+	// (brief) reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Human readable message indicating details about last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+type ConditionType string
+
+const (
+	// ConditionFailed is added to a resource when reporting-operator has
+	// given up retrying processing it. It's removed once the resource is
+	// processed successfully.
+	ConditionFailed ConditionType = "Failed"
+)
@@ -35,4 +35,7 @@ type PrestoTableStatus struct {
 	Parameters TableParameters  `json:"parameters"`
 	Properties TableProperties  `json:"properties"`
 	Partitions []TablePartition `json:"partitions"`
+	// Conditions contains the Failed condition when processing the
+	// PrestoTable has failed too many times.
+	Conditions []Condition `json:"conditions,omitempty"`
 }
@@ -0,0 +1,106 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IsDefaultPricingPolicyAnnotation marks a PricingPolicy as the one used by
+// reports in it's namespace which don't set spec.pricingPolicy.
+const IsDefaultPricingPolicyAnnotation = "pricingpolicy.metering.openshift.io/is-default"
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type PricingPolicyList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+	Items         []*PricingPolicy `json:"items"`
+}
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type PricingPolicy struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PricingPolicySpec `json:"spec"`
+}
+
+type PricingPolicySpec struct {
+	// Rates are the prices of resources on nodes which don't match any of
+	// NodeRates.
+	Rates PricingRates `json:"rates"`
+	// NodeRates override Rates for nodes with matching labels, such as the
+	// nodes of an instance type. The first entry matching a node is used.
+	NodeRates []NodePricingRates `json:"nodeRates,omitempty"`
+	// Spot configures the rates of spot and preemptible nodes, which take
+	// precedence over NodeRates.
+	Spot *SpotPricing `json:"spot,omitempty"`
+	// StorageClassRates override Rates.StorageGBHour for volumes of a
+	// storage class.
+	StorageClassRates []StorageClassPricingRate `json:"storageClassRates,omitempty"`
+	// Markup adds overhead, such as the cost of shared platform services,
+	// to the costs calculated by queries.
+	Markup *PricingMarkup `json:"markup,omitempty"`
+}
+
+// PricingRates are the prices of using resources for an hour, or of
+// transferring a GB for NetworkEgressGB. Unset rates are free, or fall back
+// to the PricingPolicy's spec.rates when set in spec.nodeRates.
+type PricingRates struct {
+	CPUCoreHour   *float64 `json:"cpuCoreHour,omitempty"`
+	MemoryGBHour  *float64 `json:"memoryGBHour,omitempty"`
+	StorageGBHour *float64 `json:"storageGBHour,omitempty"`
+	NodeHour      *float64 `json:"nodeHour,omitempty"`
+	GPUHour       *float64 `json:"gpuHour,omitempty"`
+	// NetworkEgressGB is the price of transmitting a GB over the network.
+	NetworkEgressGB *float64 `json:"networkEgressGB,omitempty"`
+	// LicenseCoreHour is the price of the operating system license of a CPU
+	// core for an hour, such as the surcharge for Windows Server nodes.
+	LicenseCoreHour *float64 `json:"licenseCoreHour,omitempty"`
+}
+
+type NodePricingRates struct {
+	// NodeSelector is the labels a node must have for Rates to apply.
+	NodeSelector map[string]string `json:"nodeSelector"`
+	Rates        PricingRates      `json:"rates"`
+}
+
+type SpotPricing struct {
+	// NodeSelectors identify spot nodes. A node matching any of them is a
+	// spot node. Defaults to nodes whose lifecycle label is spot.
+	NodeSelectors []map[string]string `json:"nodeSelectors,omitempty"`
+	// Rates are the prices of resources on spot nodes. Unset rates use the
+	// rates the node would have if it wasn't a spot node, reduced by
+	// DiscountPercent.
+	Rates PricingRates `json:"rates,omitempty"`
+	// DiscountPercent is how much cheaper spot nodes are than other nodes
+	// for rates which aren't set in Rates, from 0 to 100.
+	DiscountPercent *float64 `json:"discountPercent,omitempty"`
+}
+
+type StorageClassPricingRate struct {
+	StorageClass string `json:"storageClass"`
+	// StorageGBHour is the price of a GB of storage of StorageClass for an
+	// hour.
+	StorageGBHour *float64 `json:"storageGBHour"`
+}
+
+type PricingMarkup struct {
+	// Percent is added to costs of namespaces which don't match any of
+	// Namespaces, for example 15 adds 15% to every cost.
+	Percent *float64 `json:"percent,omitempty"`
+	// Namespaces override Percent for matching namespaces. The first entry
+	// matching a namespace is used.
+	Namespaces []NamespacePricingMarkup `json:"namespaces,omitempty"`
+}
+
+type NamespacePricingMarkup struct {
+	// Names are the namespaces the markup applies to.
+	Names []string `json:"names,omitempty"`
+	// NamespaceSelector is the labels a namespace must have for the markup
+	// to apply. If Names is also set, the namespace must match both.
+	NamespaceSelector map[string]string `json:"namespaceSelector,omitempty"`
+	Percent           *float64          `json:"percent"`
+}
@@ -41,6 +41,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&PrestoTableList{},
 		&ScheduledReport{},
 		&ScheduledReportList{},
+		&PricingPolicy{},
+		&PricingPolicyList{},
+		&ReportingOperatorConfig{},
+		&ReportingOperatorConfigList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
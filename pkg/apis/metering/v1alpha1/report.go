@@ -52,14 +52,155 @@ type ReportSpec struct {
 	// Output is the storage location where results are sent.
 	Output *StorageLocationRef `json:"output,omitempty"`
 
+	// PricingPolicy is the name of the PricingPolicy in the report's
+	// namespace whose rates are available to it's query. If unset, the
+	// namespace's default PricingPolicy is used, if there is one.
+	PricingPolicy string `json:"pricingPolicy,omitempty"`
+
 	// ReportingEndInputName allows overriding the default expected input name that maps to the ReportPeriodEnd
 	ReportingEndInputName string `json:"reportingEndInputName,omitempty"`
+
+	// ChunkSize splits the period between ReportingStart and ReportingEnd
+	// into chunks of this duration. The query is run for each chunk in
+	// order, and it's results are appended to the report's table, so a
+	// failure only requires re-running the chunks that didn't complete.
+	ChunkSize *meta.Duration `json:"chunkSize,omitempty"`
+
+	// MaxRuntime is how long each query generating the report can run
+	// before it's cancelled, failing the report.
+	MaxRuntime *meta.Duration `json:"maxRuntime,omitempty"`
+
+	// MaxResultRows is the maximum number of rows the report's query can
+	// return, or each chunk's query if ChunkSize is set. If it's exceeded,
+	// the report fails.
+	MaxResultRows *int64 `json:"maxResultRows,omitempty"`
+
+	// RetryPolicy controls how many times, and how often, generating the
+	// report is retried when it fails for a reason which may be transient,
+	// such as a Presto query failing. By default, reports are attempted
+	// once, or 5 times if ChunkSize is set.
+	RetryPolicy *ReportRetryPolicy `json:"retryPolicy,omitempty"`
+
+	// DryRun renders the report's query and records the plan Presto would
+	// use to execute it in status.dryRun, instead of generating the report.
+	DryRun *ReportDryRun `json:"dryRun,omitempty"`
+}
+
+type ReportRetryPolicy struct {
+	// MaxAttempts is the number of times generating the report is attempted
+	// before it's marked as failed. For chunked reports, it's the number of
+	// consecutive attempts which failed without completing a chunk.
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+	// Backoff is how long to wait before the first retry, doubling after
+	// each failed attempt. Defaults to 1 minute.
+	Backoff *meta.Duration `json:"backoff,omitempty"`
+	// MaxBackoff is the longest to wait between attempts. Defaults to 1
+	// hour.
+	MaxBackoff *meta.Duration `json:"maxBackoff,omitempty"`
+}
+
+type ReportDryRun struct {
+	// Analyze runs the query using EXPLAIN ANALYZE, which executes the
+	// query and includes the measured cost of each stage in the plan,
+	// without storing any results.
+	Analyze bool `json:"analyze,omitempty"`
 }
 
 type ReportStatus struct {
-	Phase     ReportPhase `json:"phase,omitempty"`
-	Output    string      `json:"output,omitempty"`
-	TableName string      `json:"tableName"`
+	Phase  ReportPhase `json:"phase,omitempty"`
+	Output string      `json:"output,omitempty"`
+	// Reason classifies why the report failed when phase is Error.
+	Reason    string `json:"reason,omitempty"`
+	TableName string `json:"tableName"`
+	// RowCount is the number of rows in the report's results, recorded when
+	// the report finishes.
+	RowCount *int64 `json:"rowCount,omitempty"`
+
+	// GenerationQueryVersions records the version of the
+	// ReportGenerationQuery and each of it's ReportGenerationQuery
+	// dependencies used to generate the report.
+	GenerationQueryVersions []ReportGenerationQueryVersion `json:"generationQueryVersions,omitempty"`
+
+	// Chunks tracks the progress of a report with spec.chunkSize set.
+	Chunks *ReportChunksStatus `json:"chunks,omitempty"`
+	// Progress is periodically updated while the report is being
+	// generated.
+	Progress *ReportProgress `json:"progress,omitempty"`
+
+	// Attempts records the most recent attempts to generate the report.
+	Attempts []ReportAttempt `json:"attempts,omitempty"`
+	// FailedAttempts is the number of attempts to generate the report
+	// which failed.
+	FailedAttempts int `json:"failedAttempts,omitempty"`
+	// NextRetryTime is when generating the report will next be attempted,
+	// after a failed attempt is retried.
+	NextRetryTime *meta.Time `json:"nextRetryTime,omitempty"`
+
+	// DryRun contains the rendered query and it's plan when spec.dryRun is
+	// set.
+	DryRun *ReportDryRunStatus `json:"dryRun,omitempty"`
+}
+
+type ReportAttempt struct {
+	StartTime  meta.Time `json:"startTime"`
+	FinishTime meta.Time `json:"finishTime"`
+	// Reason classifies why the attempt failed. It's empty if the attempt
+	// succeeded.
+	Reason string `json:"reason,omitempty"`
+	// Message describes why the attempt failed.
+	Message string `json:"message,omitempty"`
+}
+
+type ReportDryRunStatus struct {
+	// Query is the report's query, rendered for the reporting period.
+	Query string `json:"query"`
+	// Plan is the output of EXPLAIN, or EXPLAIN ANALYZE if
+	// spec.dryRun.analyze is true.
+	Plan string `json:"plan"`
+}
+
+type ReportChunksStatus struct {
+	// Total is the number of chunks the reporting period is split into.
+	Total int `json:"total"`
+	// Completed is the number of chunks whose results have been stored.
+	// Chunks are run in order, so generation resumes from the next chunk.
+	Completed int `json:"completed"`
+	// CompletedUntil is the end of the last completed chunk.
+	CompletedUntil *meta.Time `json:"completedUntil,omitempty"`
+	// CompletedRows is the number of rows in the report's table once the
+	// last completed chunk was recorded.
+	CompletedRows *int64 `json:"completedRows,omitempty"`
+	// Failures is the number of consecutive times running the next chunk
+	// failed.
+	Failures int `json:"failures,omitempty"`
+}
+
+type ReportProgress struct {
+	// StartTime is when the current attempt to generate the report
+	// started.
+	StartTime meta.Time `json:"startTime"`
+	// LastUpdateTime is when the progress was last updated. If it stops
+	// advancing while the report is Started, the reporting-operator is no
+	// longer working on the report.
+	LastUpdateTime meta.Time `json:"lastUpdateTime"`
+	// Elapsed is how long the current attempt has been running for.
+	Elapsed meta.Duration `json:"elapsed"`
+	// PercentComplete is the percentage of chunks completed, for reports
+	// with spec.chunkSize set.
+	PercentComplete *int `json:"percentComplete,omitempty"`
+	// RowsWritten is the number of rows stored in the report's table so
+	// far. Reports without spec.chunkSize store their results once the
+	// query finishes.
+	RowsWritten *int64 `json:"rowsWritten,omitempty"`
+}
+
+// ReportGenerationQueryVersion identifies the version of a
+// ReportGenerationQuery used when generating a report.
+type ReportGenerationQueryVersion struct {
+	Name            string `json:"name"`
+	ResourceVersion string `json:"resourceVersion"`
+	// QueryHash is the hex encoded SHA256 hash of spec.query.
+	QueryHash string `json:"queryHash"`
 }
 
 type ReportPhase string
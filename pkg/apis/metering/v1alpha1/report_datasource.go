@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	"k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -29,16 +30,277 @@ type ReportDataSourceSpec struct {
 	// AWSBilling represents a datasource which points to a pre-existing S3
 	// bucket.
 	AWSBilling *AWSBillingDataSource `json:"awsBilling"`
+	// AzureCostManagement represents a datasource which points to the
+	// Cost Management exports stored in a pre-existing Azure storage
+	// container.
+	AzureCostManagement *AzureCostManagementDataSource `json:"azureCostManagement,omitempty"`
+	// File represents a datasource which points to a directory of CSV or
+	// JSON files in object storage.
+	File *FileDataSource `json:"file,omitempty"`
+	// SQL represents a datasource which periodically copies the results of
+	// a query against an external Postgres or MySQL database into a table.
+	SQL *SQLDataSource `json:"sql,omitempty"`
+	// Kafka represents a datasource which continuously consumes usage events
+	// from a Kafka topic and stores them in a table.
+	Kafka *KafkaDataSource `json:"kafka,omitempty"`
+	// S3Inventory represents a datasource which points to the reports of an
+	// S3 Inventory stored in a pre-existing S3 bucket.
+	S3Inventory *S3InventoryDataSource `json:"s3Inventory,omitempty"`
+	// NamespaceMetadata represents a datasource which periodically stores
+	// a snapshot of the labels and annotations of every namespace in a
+	// table.
+	NamespaceMetadata *NamespaceMetadataDataSource `json:"namespaceMetadata,omitempty"`
+
+	// DeletionPolicy controls what happens to the table and the data stored
+	// by this ReportDataSource when it's deleted. Defaults to Retain.
+	DeletionPolicy ReportDataSourceDeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// Priority controls whether collection for this ReportDataSource is
+	// paused when it's StorageLocation is projected to exceed it's storage
+	// budget. Defaults to Normal.
+	Priority ReportDataSourcePriority `json:"priority,omitempty"`
+
+	// Backfill controls how far back a newly created promsum
+	// ReportDataSource imports historical data from. If unset, the
+	// operator's global backfill settings are used.
+	Backfill *ReportDataSourceBackfill `json:"backfill,omitempty"`
+
+	// Retention is how long data stored by a promsum ReportDataSource is
+	// kept. Partitions older than the retention are dropped and their data
+	// deleted, unless they're needed by a Report or ScheduledReport which
+	// hasn't finished. If unset, data is kept forever.
+	Retention *meta.Duration `json:"retention,omitempty"`
 }
 
+type ReportDataSourceBackfill struct {
+	// Duration is how long before the ReportDataSource was created to begin
+	// importing data from.
+	Duration *meta.Duration `json:"duration,omitempty"`
+	// StartTime is when to begin importing data from. If set, Duration is
+	// ignored.
+	StartTime *meta.Time `json:"startTime,omitempty"`
+}
+
+type ReportDataSourcePriority string
+
+const (
+	// ReportDataSourcePriorityNormal ReportDataSources continue collecting
+	// data when their StorageLocation is over budget.
+	ReportDataSourcePriorityNormal ReportDataSourcePriority = "Normal"
+	// ReportDataSourcePriorityLow ReportDataSources stop collecting data
+	// while their StorageLocation is projected to exceed it's budget.
+	ReportDataSourcePriorityLow ReportDataSourcePriority = "Low"
+)
+
+type ReportDataSourceDeletionPolicy string
+
+const (
+	// ReportDataSourceDeletionPolicyRetain leaves the table and its data in
+	// place when the ReportDataSource is deleted.
+	ReportDataSourceDeletionPolicyRetain ReportDataSourceDeletionPolicy = "Retain"
+	// ReportDataSourceDeletionPolicyDelete drops the table when the
+	// ReportDataSource is deleted, and for promsum ReportDataSources, also
+	// removes the underlying data files.
+	ReportDataSourceDeletionPolicyDelete ReportDataSourceDeletionPolicy = "Delete"
+)
+
 type AWSBillingDataSource struct {
 	Source *S3Bucket `json:"source"`
+	// Notifications configures an SQS queue receiving the S3 event
+	// notifications of the source bucket, allowing new report manifests to
+	// be discovered as soon as they're written instead of when the bucket is
+	// next polled.
+	Notifications *SQSNotifications `json:"notifications,omitempty"`
+}
+
+type SQSNotifications struct {
+	// QueueURL is the URL of the SQS queue.
+	QueueURL string `json:"queueURL"`
+	// Region is the region of the SQS queue. Defaults to the region of the
+	// source bucket.
+	Region string `json:"region,omitempty"`
 }
 
 type S3Bucket struct {
 	Region string `json:"region"`
 	Bucket string `json:"bucket"`
 	Prefix string `json:"prefix"`
+	// Endpoint configures an S3 compatible object store to use instead of
+	// AWS S3.
+	Endpoint *S3Endpoint `json:"endpoint,omitempty"`
+	// RoleARN is the ARN of an IAM role the reporting-operator assumes to
+	// access the bucket, and the SQS queue of an awsBilling
+	// ReportDataSource's notifications. Its credentials are refreshed
+	// before they expire.
+	RoleARN string `json:"roleARN,omitempty"`
+}
+
+// S3Endpoint configures an S3 compatible object store, such as MinIO or
+// Ceph's RADOS Gateway.
+type S3Endpoint struct {
+	// URL is the URL of the object store's S3 API, such as
+	// https://minio.example.com:9000.
+	URL string `json:"url"`
+	// ForcePathStyle addresses buckets using the path of requests instead of
+	// a subdomain of the endpoint, which most S3 compatible object stores
+	// require.
+	ForcePathStyle bool `json:"forcePathStyle,omitempty"`
+	// InsecureSkipVerify disables verifying the endpoint's TLS certificate.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+type S3InventoryDataSource struct {
+	// Source is the destination bucket of the inventory. The prefix is the
+	// inventory's destination prefix, followed by the name of the bucket
+	// being inventoried and the inventory's configuration ID.
+	Source *S3Bucket `json:"source"`
+}
+
+type NamespaceMetadataDataSource struct {
+	// Labels limits the namespace labels stored. If empty, every label is
+	// stored.
+	Labels []string `json:"labels,omitempty"`
+	// Annotations limits the namespace annotations stored. If empty, every
+	// annotation is stored.
+	Annotations []string `json:"annotations,omitempty"`
+	// RefreshInterval is how often a snapshot is stored. Defaults to 1h.
+	RefreshInterval *meta.Duration `json:"refreshInterval,omitempty"`
+	// Storage controls where the table is stored.
+	Storage *StorageLocationRef `json:"storage,omitempty"`
+}
+
+type AzureCostManagementDataSource struct {
+	Source *AzureBlobContainer `json:"source"`
+}
+
+type AzureBlobContainer struct {
+	StorageAccount string `json:"storageAccount"`
+	Container      string `json:"container"`
+	// Directory is the path within the container of the export, including
+	// the export's name.
+	Directory string `json:"directory"`
+	// AccountKeySecret selects a key of a Secret in the ReportDataSource's
+	// namespace containing the access key of the storage account.
+	AccountKeySecret *v1.SecretKeySelector `json:"accountKeySecret"`
+}
+
+type FileDataSource struct {
+	// Location is the URL of the directory containing the files, for
+	// example s3a://bucket/path/. Any filesystem supported by Hive and Presto
+	// can be used.
+	Location string `json:"location"`
+	// Format is the format of the files.
+	Format FileDataSourceFormat `json:"format"`
+	// CSV configures how files are parsed when Format is CSV.
+	CSV *CSVFormatConfig `json:"csv,omitempty"`
+	// Columns are the columns of the table, in the order they appear in
+	// each row for CSV files, or the fields of each object for JSON files.
+	Columns []FileDataSourceColumn `json:"columns"`
+	// Partitions are columns whose values come from the names of
+	// directories within Location, which must be in the format
+	// <name>=<value>. New partitions are discovered periodically.
+	Partitions []FileDataSourceColumn `json:"partitions,omitempty"`
+}
+
+type FileDataSourceFormat string
+
+const (
+	FileDataSourceFormatCSV  FileDataSourceFormat = "CSV"
+	FileDataSourceFormatJSON FileDataSourceFormat = "JSON"
+)
+
+type CSVFormatConfig struct {
+	// Separator is the character separating fields. Defaults to ",".
+	Separator string `json:"separator,omitempty"`
+	// Quote is the character fields containing the separator are quoted
+	// with. Defaults to `"`.
+	Quote string `json:"quote,omitempty"`
+	// Escape is the character used to escape quotes within quoted fields.
+	// Defaults to a backslash.
+	Escape string `json:"escape,omitempty"`
+	// SkipHeaderLines is the number of lines skipped at the start of each
+	// file.
+	SkipHeaderLines int `json:"skipHeaderLines,omitempty"`
+}
+
+type FileDataSourceColumn struct {
+	Name string `json:"name"`
+	// Type is the Hive type of the column. Defaults to string.
+	Type string `json:"type,omitempty"`
+}
+
+type SQLDataSource struct {
+	// Driver is the type of database queried.
+	Driver SQLDataSourceDriver `json:"driver"`
+	// ConnectionSecret selects a key of a Secret in the ReportDataSource's
+	// namespace containing the connection string used to connect to the
+	// database, in the format expected by the driver.
+	ConnectionSecret *v1.SecretKeySelector `json:"connectionSecret"`
+	// Query is the SELECT query whose results replace the contents of the
+	// table each time it's run.
+	Query string `json:"query"`
+	// Columns are the columns of the table, in the order they're returned by
+	// the query.
+	Columns []SQLDataSourceColumn `json:"columns"`
+	// RefreshInterval is how often the query is run. Defaults to 1h.
+	RefreshInterval *meta.Duration `json:"refreshInterval,omitempty"`
+	// Storage controls where the table is stored.
+	Storage *StorageLocationRef `json:"storage,omitempty"`
+}
+
+type SQLDataSourceDriver string
+
+const (
+	SQLDataSourceDriverPostgres SQLDataSourceDriver = "postgres"
+	SQLDataSourceDriverMySQL    SQLDataSourceDriver = "mysql"
+)
+
+type SQLDataSourceColumn struct {
+	Name string `json:"name"`
+	// Type is the Hive type of the column. Defaults to string.
+	Type string `json:"type,omitempty"`
+}
+
+type KafkaDataSource struct {
+	// Brokers are the addresses of the Kafka brokers used to discover the
+	// rest of the cluster.
+	Brokers []string `json:"brokers"`
+	// Topic is the topic consumed.
+	Topic string `json:"topic"`
+	// GroupID is the consumer group used to track which messages have been
+	// stored. Defaults to metering-<namespace>-<name>.
+	GroupID string `json:"groupID,omitempty"`
+	// Format is the format of each message's value.
+	Format KafkaMessageFormat `json:"format"`
+	// AvroSchema is the schema of messages when Format is Avro.
+	AvroSchema string `json:"avroSchema,omitempty"`
+	// Columns are the columns of the table, and the fields of each message
+	// they're read from.
+	Columns []KafkaDataSourceColumn `json:"columns"`
+	// MaxBatchSize is the maximum number of messages stored by a single
+	// insert. Defaults to 1000.
+	MaxBatchSize int `json:"maxBatchSize,omitempty"`
+	// FlushInterval is the maximum amount of time messages are buffered for
+	// before being stored. Defaults to 1m.
+	FlushInterval *meta.Duration `json:"flushInterval,omitempty"`
+	// Storage controls where the table is stored.
+	Storage *StorageLocationRef `json:"storage,omitempty"`
+}
+
+type KafkaMessageFormat string
+
+const (
+	KafkaMessageFormatJSON KafkaMessageFormat = "JSON"
+	KafkaMessageFormatAvro KafkaMessageFormat = "Avro"
+)
+
+type KafkaDataSourceColumn struct {
+	Name string `json:"name"`
+	// Field is the field of the message the column's value is read from.
+	// Fields of nested objects are separated by a ".". Defaults to Name.
+	Field string `json:"field,omitempty"`
+	// Type is the Hive type of the column. Defaults to string.
+	Type string `json:"type,omitempty"`
 }
 
 type PrometheusQueryConfig struct {
@@ -47,8 +309,17 @@ type PrometheusQueryConfig struct {
 	ChunkSize     *meta.Duration `json:"chunkSize,omitempty"`
 }
 
+// PrometheusCredentialsSecretLabel must be set to "true" on the Secrets
+// selected by a ReportDataSource's prometheusConfig.bearerTokenSecret, so
+// only Secrets created for authenticating to Prometheus are sent to it.
+const PrometheusCredentialsSecretLabel = "metering.openshift.io/prometheus-credentials"
+
 type PrometheusConnectionConfig struct {
 	URL string `json:"url,omitempty"`
+	// BearerTokenSecret selects a key of a Secret in the ReportDataSource's
+	// namespace containing the bearer token to authenticate to Prometheus
+	// with. The Secret must have the PrometheusCredentialsSecretLabel.
+	BearerTokenSecret *v1.SecretKeySelector `json:"bearerTokenSecret,omitempty"`
 }
 
 type PrometheusMetricsDataSource struct {
@@ -56,15 +327,117 @@ type PrometheusMetricsDataSource struct {
 	QueryConfig      *PrometheusQueryConfig      `json:"queryConfig,omitempty"`
 	Storage          *StorageLocationRef         `json:"storage,omitempty"`
 	PrometheusConfig *PrometheusConnectionConfig `json:"prometheusConfig,omitempty"`
+	// RemoteWrite causes samples received by reporting-operator's Prometheus
+	// remote-write endpoint to be stored, instead of periodically running
+	// Query against Prometheus.
+	RemoteWrite *PrometheusRemoteWriteConfig `json:"remoteWrite,omitempty"`
+	// PartitionGranularity is the period of time covered by each partition
+	// of the table, one of hourly, daily or weekly. Defaults to daily. It
+	// only takes effect when the table is created.
+	PartitionGranularity string `json:"partitionGranularity,omitempty"`
+	// Compaction, if set, causes partitions of the table to be periodically
+	// rewritten into a small number of large files once they're complete.
+	Compaction *PartitionCompactionConfig `json:"compaction,omitempty"`
+}
+
+// PartitionCompactionConfig configures compacting the partitions of a
+// ReportDataSource's table.
+type PartitionCompactionConfig struct {
+	// Delay is how long after the end of a partition's time range it's
+	// compacted, giving late metrics time to be stored. Defaults to 1h.
+	Delay *meta.Duration `json:"delay,omitempty"`
+}
+
+// PrometheusRemoteWriteConfig selects which of the series received on the
+// remote-write endpoint are stored by a ReportDataSource.
+type PrometheusRemoteWriteConfig struct {
+	// MetricName is the name of the metric to store.
+	MetricName string `json:"metricName"`
+	// MatchLabels limits the series stored to those with all of the labels.
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+	// KeepLabels is the list of labels stored with each sample. If empty,
+	// every label except the metric name is stored.
+	KeepLabels []string `json:"keepLabels,omitempty"`
 }
 
 type ReportDataSourceStatus struct {
 	TableName                    string                        `json:"tableName,omitempty"`
 	PrometheusMetricImportStatus *PrometheusMetricImportStatus `json:"prometheusMetricImportStatus,omitempty"`
+	SQLImportStatus              *SQLImportStatus              `json:"sqlImportStatus,omitempty"`
+	// NamespaceMetadataStatus reports the last snapshot stored when
+	// spec.namespaceMetadata is set.
+	NamespaceMetadataStatus *NamespaceMetadataStatus `json:"namespaceMetadataStatus,omitempty"`
+	// PartitionGranularity is the partition granularity the table was
+	// created with. Empty for tables created before it was configurable,
+	// which are partitioned daily.
+	PartitionGranularity string `json:"partitionGranularity,omitempty"`
+	// CompactionStatus reports the progress of compacting the table's
+	// partitions when spec.promsum.compaction is set.
+	CompactionStatus *PartitionCompactionStatus `json:"compactionStatus,omitempty"`
+	// RetentionStatus reports the progress of pruning old partitions when
+	// spec.retention is set.
+	RetentionStatus *RetentionStatus `json:"retentionStatus,omitempty"`
+	// Conditions contains the Failed condition when processing the
+	// ReportDataSource has failed too many times.
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+type RetentionStatus struct {
+	// PrunedUntil is the end of the newest partition pruned. Every
+	// partition ending before it has been dropped.
+	PrunedUntil *meta.Time `json:"prunedUntil,omitempty"`
+	// LastPruneTime is when partitions were last pruned.
+	LastPruneTime *meta.Time `json:"lastPruneTime,omitempty"`
+}
+
+type PartitionCompactionStatus struct {
+	// CompactedUntil is the end of the newest partition compacted. Every
+	// partition ending before it has been compacted.
+	CompactedUntil *meta.Time `json:"compactedUntil,omitempty"`
+	// LastCompactionTime is when partitions were last compacted.
+	LastCompactionTime *meta.Time `json:"lastCompactionTime,omitempty"`
+}
+
+type SQLImportStatus struct {
+	// LastImportTime is when the table was last refreshed with the results
+	// of the query.
+	LastImportTime *meta.Time `json:"lastImportTime,omitempty"`
+	// ImportedRows is the number of rows returned by the query when the
+	// table was last refreshed.
+	ImportedRows int64 `json:"importedRows"`
+}
+
+type NamespaceMetadataStatus struct {
+	// LastSnapshotTime is when the last snapshot was stored.
+	LastSnapshotTime *meta.Time `json:"lastSnapshotTime,omitempty"`
+	// Namespaces is the number of namespaces in the last snapshot.
+	Namespaces int64 `json:"namespaces"`
 }
 
 type PrometheusMetricImportStatus struct {
 	LastImportTime             *meta.Time `json:"lastImportTime,omitempty"`
 	EarliestImportedMetricTime *meta.Time `json:"earliestImportedMetricTime,omitempty"`
 	NewestImportedMetricTime   *meta.Time `json:"newestImportedMetricTime,omitempty"`
+
+	// ImportCheckpoint is the end of the last time range successfully
+	// imported. When reporting-operator restarts, importing resumes from the
+	// checkpoint instead of the newest timestamp found in the table.
+	ImportCheckpoint *meta.Time `json:"importCheckpoint,omitempty"`
+
+	// Backfill reports the progress of importing historical data when
+	// spec.backfill is set.
+	Backfill *PrometheusBackfillStatus `json:"backfill,omitempty"`
+}
+
+type PrometheusBackfillStatus struct {
+	// StartTime is the time historical data is being imported from.
+	StartTime meta.Time `json:"startTime"`
+	// EndTime is when the backfill is complete, which is when the
+	// ReportDataSource was created.
+	EndTime meta.Time `json:"endTime"`
+	// PercentComplete is how much of the time between StartTime and EndTime
+	// has been imported.
+	PercentComplete int `json:"percentComplete"`
+	// Completed is true once data has been imported up to EndTime.
+	Completed bool `json:"completed"`
 }
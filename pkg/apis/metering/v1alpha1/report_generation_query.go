@@ -33,6 +33,51 @@ type ReportGenerationQuerySpec struct {
 	Reports              []string                               `json:"reports,omitempty"`
 	ScheduledReports     []string                               `json:"scheduledReports,omitempty"`
 	Inputs               []ReportGenerationQueryInputDefinition `json:"inputs,omitempty"`
+	// Session configures the Presto session the query is run in when
+	// generating or previewing reports.
+	Session *PrestoSession `json:"session,omitempty"`
+	// Rollup generates the query from the results of an existing Report or
+	// ScheduledReport, aggregating them into the reporting period, instead
+	// of running spec.query.
+	Rollup *ReportGenerationQueryRollup `json:"rollup,omitempty"`
+	// Materialized configures the operator to maintain a table containing
+	// the results of the query for each period since it's start, which is
+	// refreshed incrementally as new data is imported.
+	Materialized *ReportGenerationQueryMaterialization `json:"materialized,omitempty"`
+}
+
+type ReportGenerationQueryMaterialization struct {
+	// RefreshInterval is how often new results are added to the table, and
+	// the length of the period each run of the query covers. Defaults to 1h.
+	RefreshInterval *meta.Duration `json:"refreshInterval,omitempty"`
+	// Start is the beginning of the first period stored in the table.
+	// Defaults to the earliest metric imported by the ReportDataSources the
+	// query depends on.
+	Start *meta.Time `json:"start,omitempty"`
+}
+
+type ReportGenerationQueryRollup struct {
+	// Report is the name of the Report whose results are aggregated.
+	Report string `json:"report,omitempty"`
+	// ScheduledReport is the name of the ScheduledReport whose results are
+	// aggregated.
+	ScheduledReport string `json:"scheduledReport,omitempty"`
+	// Aggregations maps column names to the aggregate function used to
+	// combine them: sum, avg, min, max or count, or group to group by the
+	// column. By default numeric columns are summed, timestamp columns ending
+	// in _start use min, other timestamp columns use max, and everything else
+	// is grouped by.
+	Aggregations map[string]string `json:"aggregations,omitempty"`
+}
+
+type PrestoSession struct {
+	// Properties are the Presto session properties set when running the
+	// query, for example query_max_run_time.
+	Properties map[string]string `json:"properties,omitempty"`
+	// Source is the source reported to Presto when running the query,
+	// which can be used by resource group selectors to choose the resource
+	// group the query runs in.
+	Source string `json:"source,omitempty"`
 }
 
 type ReportGenerationQueryColumn struct {
@@ -51,8 +96,24 @@ type GenQueryView struct {
 type ReportGenerationQueryInputDefinition struct {
 	Name     string `json:"name"`
 	Required bool   `json:"required"`
+	// Type is the type the input's value is converted to before being
+	// passed to the query: string, integer, float, boolean or time. Defaults
+	// to string, except for the ReportingStart and ReportingEnd inputs,
+	// which are always times.
+	Type string `json:"type,omitempty"`
+	// Default is the value of the input if it's not set by the report.
+	Default *string `json:"default,omitempty"`
 }
 
+const (
+	ReportGenerationQueryInputTypeString  = "string"
+	ReportGenerationQueryInputTypeInteger = "integer"
+	ReportGenerationQueryInputTypeFloat   = "float"
+	ReportGenerationQueryInputTypeBoolean = "boolean"
+	// ReportGenerationQueryInputTypeTime inputs are RFC3339 timestamps.
+	ReportGenerationQueryInputTypeTime = "time"
+)
+
 type ReportGenerationQueryInputValue struct {
 	Name  string `json:"name"`
 	Value string `json:"value"`
@@ -64,4 +125,30 @@ type ReportGenerationQueryStatus struct {
 	// ViewName is the name of the view in Presto for this query, if the view
 	// has been created. If it is empty, the view does not exist.
 	ViewName string `json:"viewName,omitempty"`
+
+	// UnresolvedDependencies lists the dependencies of the query which don't
+	// exist, as kind/name.
+	UnresolvedDependencies []string `json:"unresolvedDependencies,omitempty"`
+	// DependencyCycle contains the names of the ReportGenerationQueries in a
+	// dependency cycle found in the query's dependencies, starting and ending
+	// with the same query.
+	DependencyCycle []string `json:"dependencyCycle,omitempty"`
+
+	// Materialized reports the progress of maintaining the materialized
+	// table when spec.materialized is set.
+	Materialized *ReportGenerationQueryMaterializedStatus `json:"materialized,omitempty"`
+	// Conditions contains the Failed condition when processing the
+	// ReportGenerationQuery has failed too many times.
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+type ReportGenerationQueryMaterializedStatus struct {
+	// TableName is the name of the table containing the materialized
+	// results.
+	TableName string `json:"tableName"`
+	// MaterializedUntil is the end of the most recent period stored in the
+	// table.
+	MaterializedUntil *meta.Time `json:"materializedUntil,omitempty"`
+	// LastRefreshTime is the last time new results were added to the table.
+	LastRefreshTime *meta.Time `json:"lastRefreshTime,omitempty"`
 }
@@ -0,0 +1,83 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ReportingOperatorConfigList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+	Items         []*ReportingOperatorConfig `json:"items"`
+}
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ReportingOperatorConfig overrides the command line configuration of the
+// reporting-operator running in it's namespace. Fields which aren't set use
+// the command line configuration.
+type ReportingOperatorConfig struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ReportingOperatorConfigSpec `json:"spec"`
+}
+
+type ReportingOperatorConfigSpec struct {
+	// LogLevel is the level of the messages logged, such as info or debug.
+	LogLevel string `json:"logLevel,omitempty"`
+	// LogDMLQueries and LogDDLQueries log the queries run which read and
+	// write data, and create and drop tables, respectively, at the debug
+	// level.
+	LogDMLQueries *bool `json:"logDMLQueries,omitempty"`
+	LogDDLQueries *bool `json:"logDDLQueries,omitempty"`
+
+	// Promsum configures the import of Prometheus metrics.
+	Promsum *ReportingOperatorPromsumConfig `json:"promsum,omitempty"`
+
+	// ReportDataSourceWorkers is the number of ReportDataSources processed,
+	// and Prometheus imports run, concurrently. Changing it restarts
+	// reporting-operator.
+	ReportDataSourceWorkers *int `json:"reportDataSourceWorkers,omitempty"`
+	// MaxConcurrentReports is the number of Reports and ScheduledReports
+	// generated at once. 0 disables the limit. Changing it restarts
+	// reporting-operator.
+	MaxConcurrentReports *int `json:"maxConcurrentReports,omitempty"`
+	// PrestoMaxConcurrentQueries is the number of report queries run
+	// against Presto at once. 0 disables the limit.
+	PrestoMaxConcurrentQueries *int `json:"prestoMaxConcurrentQueries,omitempty"`
+	// ReportQueryTimeout is how long the queries generating a report can
+	// run before they're cancelled. 0 disables the timeout.
+	ReportQueryTimeout *meta.Duration `json:"reportQueryTimeout,omitempty"`
+
+	// PrestoHost, HiveHost and HiveMetastoreHost are the hostname:port of
+	// Presto, HiveServer2 and the Hive Metastore's Thrift API. Changing
+	// them restarts reporting-operator.
+	PrestoHost        string `json:"prestoHost,omitempty"`
+	HiveHost          string `json:"hiveHost,omitempty"`
+	HiveMetastoreHost string `json:"hiveMetastoreHost,omitempty"`
+	// PrometheusURL is the URL of the Prometheus metrics are imported from.
+	// Changing it restarts reporting-operator.
+	PrometheusURL string `json:"prometheusURL,omitempty"`
+}
+
+type ReportingOperatorPromsumConfig struct {
+	// Interval is how often Prometheus ReportDataSources without their own
+	// queryInterval import metrics.
+	Interval *meta.Duration `json:"interval,omitempty"`
+	// StepSize and ChunkSize are the step size and chunk size used by
+	// Prometheus ReportDataSources which don't set their own.
+	StepSize  *meta.Duration `json:"stepSize,omitempty"`
+	ChunkSize *meta.Duration `json:"chunkSize,omitempty"`
+	// MaxQueryRangeDuration is the most data imported from Prometheus at
+	// once, and MaxBackfillImportDuration is how far back metrics are
+	// imported from when a ReportDataSource has no data.
+	MaxQueryRangeDuration     *meta.Duration `json:"maxQueryRangeDuration,omitempty"`
+	MaxBackfillImportDuration *meta.Duration `json:"maxBackfillImportDuration,omitempty"`
+	// MaxConcurrentImportsPerEndpoint limits the number of imports running
+	// concurrently against each Prometheus instance. 0 disables the limit.
+	MaxConcurrentImportsPerEndpoint *int `json:"maxConcurrentImportsPerEndpoint,omitempty"`
+}
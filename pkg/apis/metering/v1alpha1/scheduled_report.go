@@ -58,6 +58,36 @@ type ScheduledReportSpec struct {
 
 	// Output is the storage location where results are sent.
 	Output *StorageLocationRef `json:"output,omitempty"`
+
+	// PricingPolicy is the name of the PricingPolicy in the report's
+	// namespace whose rates are available to it's query. If unset, the
+	// namespace's default PricingPolicy is used, if there is one.
+	PricingPolicy string `json:"pricingPolicy,omitempty"`
+
+	// MaxRuntime is how long each run of the report's query can take before
+	// it's cancelled, failing the run.
+	MaxRuntime *meta.Duration `json:"maxRuntime,omitempty"`
+
+	// MaxResultRows is the maximum number of rows each run of the report's
+	// query can return. If it's exceeded, the run fails.
+	MaxResultRows *int64 `json:"maxResultRows,omitempty"`
+
+	// Metrics exports the results of the most recent run as Prometheus
+	// gauges on reporting-operator's /metrics endpoint.
+	Metrics *ScheduledReportMetrics `json:"metrics,omitempty"`
+}
+
+type ScheduledReportMetrics struct {
+	// Name is the prefix of each gauge's name, which is followed by the
+	// name of the column the gauge exports. Defaults to metering_ followed
+	// by the name of the ScheduledReport.
+	Name string `json:"name,omitempty"`
+	// ValueColumns are the numeric columns exported as gauges. Defaults to
+	// every numeric column.
+	ValueColumns []string `json:"valueColumns,omitempty"`
+	// LabelColumns are the columns used as the labels of each gauge.
+	// Defaults to every string column.
+	LabelColumns []string `json:"labelColumns,omitempty"`
 }
 
 type ScheduledReportPeriod string
@@ -72,6 +102,9 @@ const (
 
 type ScheduledReportSchedule struct {
 	Period ScheduledReportPeriod `json:"period"`
+	// Timezone is the name of the IANA time zone the schedule is
+	// evaluated in, such as America/New_York. Defaults to UTC.
+	Timezone string `json:"timezone,omitempty"`
 
 	Cron    *ScheduledReportScheduleCron    `json:"cron,omitempty"`
 	Hourly  *ScheduledReportScheduleHourly  `json:"hourly,omitempty"`
@@ -113,6 +146,22 @@ type ScheduledReportStatus struct {
 	Conditions     []ScheduledReportCondition `json:"conditions,omitempty"`
 	LastReportTime *meta.Time                 `json:"lastReportTime,omitempty"`
 	TableName      string                     `json:"tableName"`
+	// RowCount is the number of rows in the report's results, recorded
+	// after each run.
+	RowCount *int64 `json:"rowCount,omitempty"`
+
+	// RunHistory contains the most recent successful runs of the
+	// ScheduledReport, ordered from newest to oldest.
+	RunHistory []ScheduledReportRun `json:"runHistory,omitempty"`
+}
+
+type ScheduledReportRun struct {
+	PeriodStart meta.Time `json:"periodStart"`
+	PeriodEnd   meta.Time `json:"periodEnd"`
+	// GenerationQueryVersions records the version of the
+	// ReportGenerationQuery and each of it's ReportGenerationQuery
+	// dependencies used for this run.
+	GenerationQueryVersions []ReportGenerationQueryVersion `json:"generationQueryVersions,omitempty"`
 }
 
 type ScheduledReportCondition struct {
@@ -1,10 +1,23 @@
 package v1alpha1
 
 import (
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-const IsDefaultStorageLocationAnnotation = "storagelocation.metering.openshift.io/is-default"
+const (
+	IsDefaultStorageLocationAnnotation = "storagelocation.metering.openshift.io/is-default"
+	// IsDefaultPerNamespaceStorageLocationAnnotation marks a StorageLocation
+	// as the default for resources in namespaces without their own default.
+	// The namespace is appended to the StorageLocation's location so each
+	// namespace's data is stored under a separate prefix.
+	IsDefaultPerNamespaceStorageLocationAnnotation = "storagelocation.metering.openshift.io/is-default-per-namespace"
+	// NamespaceDefaultStorageLocationAnnotation is set on a Namespace to the
+	// name of the StorageLocation that resources in the namespace should use
+	// by default.
+	NamespaceDefaultStorageLocationAnnotation = "storagelocation.metering.openshift.io/default"
+)
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -21,15 +34,185 @@ type StorageLocation struct {
 	meta.TypeMeta   `json:",inline"`
 	meta.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec StorageLocationSpec `json:"spec"`
+	Spec   StorageLocationSpec   `json:"spec"`
+	Status StorageLocationStatus `json:"status,omitempty"`
 }
 
 type StorageLocationSpec struct {
 	Hive *HiveStorage `json:"hive,omitempty"`
+	// Azure stores tables in a container of an Azure storage account,
+	// using Hive to create the tables.
+	Azure *AzureStorage `json:"azure,omitempty"`
+	// GCS stores tables in a Google Cloud Storage bucket, using Hive to
+	// create the tables.
+	GCS *GCSStorage `json:"gcs,omitempty"`
+	// HDFS stores tables in a directory of a HDFS cluster, using Hive to
+	// create the tables.
+	HDFS *HDFSStorage `json:"hdfs,omitempty"`
+	// SharedVolume stores tables in a ReadWriteMany PersistentVolumeClaim
+	// mounted into the Presto and Hive pods.
+	SharedVolume *SharedVolumeStorage `json:"sharedVolume,omitempty"`
+	// S3 stores tables in an S3 bucket, or a bucket of an S3 compatible
+	// object store, using Hive to create the tables.
+	S3 *S3Storage `json:"s3,omitempty"`
+	// Budget limits how much data is stored in this StorageLocation.
+	Budget *StorageBudget `json:"budget,omitempty"`
+}
+
+type StorageBudget struct {
+	// MaxSize is the maximum amount of data to store in the StorageLocation.
+	MaxSize resource.Quantity `json:"maxSize"`
+	// ProjectionPeriod is how far ahead the current rate of growth is
+	// projected when determining if the budget will be exceeded. Defaults
+	// to 24 hours.
+	ProjectionPeriod *meta.Duration `json:"projectionPeriod,omitempty"`
+}
+
+type StorageLocationStatus struct {
+	UsedBytes      int64      `json:"usedBytes,omitempty"`
+	ProjectedBytes int64      `json:"projectedBytes,omitempty"`
+	BudgetExceeded bool       `json:"budgetExceeded,omitempty"`
+	LastCheckTime  *meta.Time `json:"lastCheckTime,omitempty"`
+	// CapacityBytes is the capacity of the PersistentVolumeClaim of a
+	// sharedVolume StorageLocation.
+	CapacityBytes int64 `json:"capacityBytes,omitempty"`
+	// CapacityWarning is set when the data stored in a sharedVolume
+	// StorageLocation exceeds it's capacityWarningThreshold.
+	CapacityWarning bool `json:"capacityWarning,omitempty"`
 }
 
 type HiveStorage struct {
 	TableProperties TableProperties `json:"tableProperties"`
+	// FileFormat is the format tables are stored in, either orc or parquet.
+	// If set, it's used instead of tableProperties.fileFormat.
+	FileFormat string `json:"fileFormat,omitempty"`
+	// Compression is the codec files are compressed using, one of none,
+	// snappy, zstd or gzip. It can only be set for tables stored as orc or
+	// parquet, or in the Hive server's default format.
+	Compression string `json:"compression,omitempty"`
+}
+
+type AzureStorageType string
+
+const (
+	// AzureStorageTypeBlob stores tables in Azure Blob storage, using the
+	// wasbs:// filesystem.
+	AzureStorageTypeBlob AzureStorageType = "Blob"
+	// AzureStorageTypeADLSGen2 stores tables in Azure Data Lake Storage
+	// Gen2, using the abfss:// filesystem. The storage account must have a
+	// hierarchical namespace enabled.
+	AzureStorageTypeADLSGen2 AzureStorageType = "ADLSGen2"
+)
+
+type AzureStorage struct {
+	// Type is either Blob or ADLSGen2. Defaults to Blob.
+	Type           AzureStorageType `json:"type,omitempty"`
+	StorageAccount string           `json:"storageAccount"`
+	// Container is the name of the container, which is the filesystem when
+	// using ADLSGen2.
+	Container string `json:"container"`
+	// Path is the directory within the container tables are stored in.
+	Path string `json:"path,omitempty"`
+	// AccountKeySecret selects a key of a Secret in the reporting-operator's
+	// namespace containing the access key of the storage account. Hive and
+	// Presto must be configured with the same Secret.
+	AccountKeySecret *v1.SecretKeySelector `json:"accountKeySecret"`
+	// FileFormat and Compression are the same as in HiveStorage.
+	FileFormat  string `json:"fileFormat,omitempty"`
+	Compression string `json:"compression,omitempty"`
+}
+
+type GCSStorage struct {
+	Bucket string `json:"bucket"`
+	// Path is the directory within the bucket tables are stored in.
+	Path string `json:"path,omitempty"`
+	// ServiceAccountKeySecret selects a key of a Secret in the
+	// reporting-operator's namespace containing the JSON key of a service
+	// account with access to the bucket. Hive and Presto must be configured
+	// with the same Secret. If unset, the Google service account bound to
+	// the pods using workload identity is used.
+	ServiceAccountKeySecret *v1.SecretKeySelector `json:"serviceAccountKeySecret,omitempty"`
+	// FileFormat and Compression are the same as in HiveStorage.
+	FileFormat  string `json:"fileFormat,omitempty"`
+	Compression string `json:"compression,omitempty"`
+}
+
+type HDFSStorage struct {
+	// Namenode is the host:port of the namenode's filesystem RPC server.
+	// Defaults to the HDFS deployed by Metering.
+	Namenode string `json:"namenode,omitempty"`
+	// WebHDFSAddress is the URL of the namenode's HTTP server, used to apply
+	// the replicationFactor and umask. Defaults to the HDFS deployed by
+	// Metering.
+	WebHDFSAddress string `json:"webHDFSAddress,omitempty"`
+	// BasePath is the directory tables are stored in.
+	BasePath string `json:"basePath"`
+	// ReplicationFactor is the number of replicas of each file stored. If
+	// unset, the replication factor files are written with is kept.
+	ReplicationFactor *int32 `json:"replicationFactor,omitempty"`
+	// Umask is an octal umask, such as 027, which is removed from the
+	// permissions of each file and directory stored.
+	Umask string `json:"umask,omitempty"`
+	// FileFormat and Compression are the same as in HiveStorage.
+	FileFormat  string `json:"fileFormat,omitempty"`
+	Compression string `json:"compression,omitempty"`
+}
+
+type SharedVolumeStorage struct {
+	// PersistentVolumeClaimName is the name of the PersistentVolumeClaim in
+	// the reporting-operator's namespace mounted into the Presto and Hive
+	// pods. Defaults to hive-warehouse-data.
+	PersistentVolumeClaimName string `json:"persistentVolumeClaimName,omitempty"`
+	// MountPath is where the volume is mounted in the Presto and Hive pods.
+	// Defaults to /user/hive/warehouse.
+	MountPath string `json:"mountPath,omitempty"`
+	// Path is the directory within the volume tables are stored in.
+	Path string `json:"path,omitempty"`
+	// CapacityWarningThreshold is the percentage of the volume's capacity
+	// which can be used before a warning is recorded. Defaults to 80.
+	CapacityWarningThreshold *int32 `json:"capacityWarningThreshold,omitempty"`
+	// FileFormat and Compression are the same as in HiveStorage.
+	FileFormat  string `json:"fileFormat,omitempty"`
+	Compression string `json:"compression,omitempty"`
+}
+
+type S3Storage struct {
+	Bucket string `json:"bucket"`
+	// Prefix is the path within the bucket tables are stored in.
+	Prefix string `json:"prefix,omitempty"`
+	Region string `json:"region,omitempty"`
+	// Endpoint configures an S3 compatible object store to use instead of
+	// AWS S3. Hive and Presto must be configured with the same endpoint.
+	Endpoint *S3Endpoint `json:"endpoint,omitempty"`
+	// Encryption is the server-side encryption the bucket must apply to
+	// tables by default. Hive and Presto must be configured to write using
+	// the same encryption.
+	Encryption *S3Encryption `json:"encryption,omitempty"`
+	// RoleARN is the ARN of an IAM role the reporting-operator assumes to
+	// check access to the bucket. Hive and Presto use their own credentials.
+	RoleARN string `json:"roleARN,omitempty"`
+	// FileFormat and Compression are the same as in HiveStorage.
+	FileFormat  string `json:"fileFormat,omitempty"`
+	Compression string `json:"compression,omitempty"`
+}
+
+type S3EncryptionType string
+
+const (
+	// S3EncryptionTypeSSES3 encrypts objects using keys managed by S3.
+	S3EncryptionTypeSSES3 S3EncryptionType = "SSE-S3"
+	// S3EncryptionTypeSSEKMS encrypts objects using a key managed by AWS
+	// KMS.
+	S3EncryptionTypeSSEKMS S3EncryptionType = "SSE-KMS"
+)
+
+// S3Encryption configures server-side encryption of objects stored in S3.
+type S3Encryption struct {
+	// Type is either SSE-S3 or SSE-KMS.
+	Type S3EncryptionType `json:"type"`
+	// KMSKeyID is the ARN of the KMS key objects are encrypted with. It's
+	// required when Type is SSE-KMS, and can't be set otherwise.
+	KMSKeyID string `json:"kmsKeyID,omitempty"`
 }
 
 type StorageLocationRef struct {
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+// NewCondition creates a new condition.
+func NewCondition(condType v1alpha1.ConditionType, status v1.ConditionStatus, reason, message string) *v1alpha1.Condition {
+	return &v1alpha1.Condition{
+		Type:               condType,
+		Status:             status,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// GetCondition returns the condition with the provided type.
+func GetCondition(conditions []v1alpha1.Condition, condType v1alpha1.ConditionType) *v1alpha1.Condition {
+	for i := range conditions {
+		c := conditions[i]
+		if c.Type == condType {
+			return &c
+		}
+	}
+	return nil
+}
+
+// SetCondition returns conditions including the provided condition,
+// replacing any existing condition of the same type. The
+// lastTransitionTime is kept if the status of the condition doesn't change.
+func SetCondition(conditions []v1alpha1.Condition, condition v1alpha1.Condition) []v1alpha1.Condition {
+	currentCond := GetCondition(conditions, condition.Type)
+	if currentCond != nil && currentCond.Status == condition.Status {
+		condition.LastTransitionTime = currentCond.LastTransitionTime
+	}
+	return append(RemoveCondition(conditions, condition.Type), condition)
+}
+
+// RemoveCondition returns a new slice of conditions without conditions with
+// the provided type.
+func RemoveCondition(conditions []v1alpha1.Condition, condType v1alpha1.ConditionType) []v1alpha1.Condition {
+	var newConditions []v1alpha1.Condition
+	for _, c := range conditions {
+		if c.Type == condType {
+			continue
+		}
+		newConditions = append(newConditions, c)
+	}
+	return newConditions
+}
@@ -0,0 +1,37 @@
+package util
+
+// Failure reasons classify why the reporting-operator failed to process a
+// resource. They're used as the reason of failure conditions and Events, and
+// as the reason label of the metering_sync_errors_total metric, allowing
+// automation to distinguish user errors from infrastructure failures.
+const (
+	// ValidationErrorReason indicates the resource is misconfigured and
+	// requires a change by the user.
+	ValidationErrorReason = "ValidationError"
+	// DependencyMissingReason indicates a resource the resource depends on
+	// does not exist or isn't initialized yet.
+	DependencyMissingReason = "DependencyMissing"
+	// StorageErrorReason indicates the resource's storage is misconfigured or
+	// unavailable.
+	StorageErrorReason = "StorageError"
+	// PrestoErrorReason indicates a Presto query failed.
+	PrestoErrorReason = "PrestoError"
+	// HiveErrorReason indicates a Hive query failed.
+	HiveErrorReason = "HiveError"
+	// PrometheusErrorReason indicates a Prometheus query failed.
+	PrometheusErrorReason = "PrometheusError"
+	// TimeoutReason indicates an operation timed out.
+	TimeoutReason = "Timeout"
+	// ExecutionLimitExceededReason indicates a report exceeded it's
+	// maxRuntime or maxResultRows.
+	ExecutionLimitExceededReason = "ExecutionLimitExceeded"
+	// UnknownErrorReason is used for errors that couldn't be classified.
+	UnknownErrorReason = "UnknownError"
+)
+
+// IsUserErrorReason returns true if the failure reason requires the user to
+// change their resources, as opposed to an infrastructure failure which may
+// resolve itself.
+func IsUserErrorReason(reason string) bool {
+	return reason == ValidationErrorReason || reason == ExecutionLimitExceededReason
+}
@@ -8,20 +8,8 @@ import (
 )
 
 const (
-	// Failure scheduledReport conditions:
-	//
-	// GenerateReportErrorReason is added to a ScheduledReport when an error
-	// occurs while generating the report data.
-	GenerateReportErrorReason = "GenerateReportError"
-
-	// InvalidReportingEndReason is added to a ScheduledReport when the
-	// spec.reportingEnd is set to a time before it's lastReportTime or before
-	// spec.reportingStart.
-	InvalidReportingEndReason = "InvalidReportingEnd"
-
-	// FailedValidationReason is added to a ScheduledReport when the it's
-	// ReportGenerationQuery or it's dependencies aren't ready
-	FailedValidationReason = "FailedValidation"
+	// Failure scheduledReport conditions use the reasons in
+	// failure_reasons.go.
 
 	// Running scheduledReport conditions:
 
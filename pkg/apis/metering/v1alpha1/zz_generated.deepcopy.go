@@ -7,7 +7,8 @@ package v1alpha1
 import (
 	hive "github.com/operator-framework/operator-metering/pkg/hive"
 	presto "github.com/operator-framework/operator-metering/pkg/presto"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -20,6 +21,15 @@ func (in *AWSBillingDataSource) DeepCopyInto(out *AWSBillingDataSource) {
 			*out = nil
 		} else {
 			*out = new(S3Bucket)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(SQSNotifications)
 			**out = **in
 		}
 	}
@@ -37,194 +47,268 @@ func (in *AWSBillingDataSource) DeepCopy() *AWSBillingDataSource {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GenQueryView) DeepCopyInto(out *GenQueryView) {
+func (in *AzureBlobContainer) DeepCopyInto(out *AzureBlobContainer) {
 	*out = *in
+	if in.AccountKeySecret != nil {
+		in, out := &in.AccountKeySecret, &out.AccountKeySecret
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.SecretKeySelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenQueryView.
-func (in *GenQueryView) DeepCopy() *GenQueryView {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureBlobContainer.
+func (in *AzureBlobContainer) DeepCopy() *AzureBlobContainer {
 	if in == nil {
 		return nil
 	}
-	out := new(GenQueryView)
+	out := new(AzureBlobContainer)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HiveStorage) DeepCopyInto(out *HiveStorage) {
+func (in *AzureCostManagementDataSource) DeepCopyInto(out *AzureCostManagementDataSource) {
 	*out = *in
-	in.TableProperties.DeepCopyInto(&out.TableProperties)
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(AzureBlobContainer)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HiveStorage.
-func (in *HiveStorage) DeepCopy() *HiveStorage {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureCostManagementDataSource.
+func (in *AzureCostManagementDataSource) DeepCopy() *AzureCostManagementDataSource {
 	if in == nil {
 		return nil
 	}
-	out := new(HiveStorage)
+	out := new(AzureCostManagementDataSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrestoTable) DeepCopyInto(out *PrestoTable) {
+func (in *AzureStorage) DeepCopyInto(out *AzureStorage) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.AccountKeySecret != nil {
+		in, out := &in.AccountKeySecret, &out.AccountKeySecret
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.SecretKeySelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrestoTable.
-func (in *PrestoTable) DeepCopy() *PrestoTable {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureStorage.
+func (in *AzureStorage) DeepCopy() *AzureStorage {
 	if in == nil {
 		return nil
 	}
-	out := new(PrestoTable)
+	out := new(AzureStorage)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PrestoTable) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrestoTableList) DeepCopyInto(out *PrestoTableList) {
+func (in *CSVFormatConfig) DeepCopyInto(out *CSVFormatConfig) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	out.ListMeta = in.ListMeta
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]*PrestoTable, len(*in))
-		for i := range *in {
-			if (*in)[i] == nil {
-				(*out)[i] = nil
-			} else {
-				(*out)[i] = new(PrestoTable)
-				(*in)[i].DeepCopyInto((*out)[i])
-			}
-		}
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrestoTableList.
-func (in *PrestoTableList) DeepCopy() *PrestoTableList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CSVFormatConfig.
+func (in *CSVFormatConfig) DeepCopy() *CSVFormatConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(PrestoTableList)
+	out := new(CSVFormatConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PrestoTableList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Condition) DeepCopyInto(out *Condition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
+func (in *Condition) DeepCopy() *Condition {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(Condition)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrestoTableStatus) DeepCopyInto(out *PrestoTableStatus) {
+func (in *FileDataSource) DeepCopyInto(out *FileDataSource) {
 	*out = *in
-	in.Parameters.DeepCopyInto(&out.Parameters)
-	in.Properties.DeepCopyInto(&out.Properties)
+	if in.CSV != nil {
+		in, out := &in.CSV, &out.CSV
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(CSVFormatConfig)
+			**out = **in
+		}
+	}
+	if in.Columns != nil {
+		in, out := &in.Columns, &out.Columns
+		*out = make([]FileDataSourceColumn, len(*in))
+		copy(*out, *in)
+	}
 	if in.Partitions != nil {
 		in, out := &in.Partitions, &out.Partitions
-		*out = make([]TablePartition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+		*out = make([]FileDataSourceColumn, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrestoTableStatus.
-func (in *PrestoTableStatus) DeepCopy() *PrestoTableStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileDataSource.
+func (in *FileDataSource) DeepCopy() *FileDataSource {
 	if in == nil {
 		return nil
 	}
-	out := new(PrestoTableStatus)
+	out := new(FileDataSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrometheusConnectionConfig) DeepCopyInto(out *PrometheusConnectionConfig) {
+func (in *FileDataSourceColumn) DeepCopyInto(out *FileDataSourceColumn) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusConnectionConfig.
-func (in *PrometheusConnectionConfig) DeepCopy() *PrometheusConnectionConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileDataSourceColumn.
+func (in *FileDataSourceColumn) DeepCopy() *FileDataSourceColumn {
 	if in == nil {
 		return nil
 	}
-	out := new(PrometheusConnectionConfig)
+	out := new(FileDataSourceColumn)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrometheusMetricImportStatus) DeepCopyInto(out *PrometheusMetricImportStatus) {
+func (in *GCSStorage) DeepCopyInto(out *GCSStorage) {
 	*out = *in
-	if in.LastImportTime != nil {
-		in, out := &in.LastImportTime, &out.LastImportTime
+	if in.ServiceAccountKeySecret != nil {
+		in, out := &in.ServiceAccountKeySecret, &out.ServiceAccountKeySecret
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = (*in).DeepCopy()
+			*out = new(v1.SecretKeySelector)
+			(*in).DeepCopyInto(*out)
 		}
 	}
-	if in.EarliestImportedMetricTime != nil {
-		in, out := &in.EarliestImportedMetricTime, &out.EarliestImportedMetricTime
-		if *in == nil {
-			*out = nil
-		} else {
-			*out = (*in).DeepCopy()
-		}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCSStorage.
+func (in *GCSStorage) DeepCopy() *GCSStorage {
+	if in == nil {
+		return nil
 	}
-	if in.NewestImportedMetricTime != nil {
-		in, out := &in.NewestImportedMetricTime, &out.NewestImportedMetricTime
+	out := new(GCSStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenQueryView) DeepCopyInto(out *GenQueryView) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenQueryView.
+func (in *GenQueryView) DeepCopy() *GenQueryView {
+	if in == nil {
+		return nil
+	}
+	out := new(GenQueryView)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HDFSStorage) DeepCopyInto(out *HDFSStorage) {
+	*out = *in
+	if in.ReplicationFactor != nil {
+		in, out := &in.ReplicationFactor, &out.ReplicationFactor
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = (*in).DeepCopy()
+			*out = new(int32)
+			**out = **in
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusMetricImportStatus.
-func (in *PrometheusMetricImportStatus) DeepCopy() *PrometheusMetricImportStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HDFSStorage.
+func (in *HDFSStorage) DeepCopy() *HDFSStorage {
 	if in == nil {
 		return nil
 	}
-	out := new(PrometheusMetricImportStatus)
+	out := new(HDFSStorage)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrometheusMetricsDataSource) DeepCopyInto(out *PrometheusMetricsDataSource) {
+func (in *HiveStorage) DeepCopyInto(out *HiveStorage) {
 	*out = *in
-	if in.QueryConfig != nil {
-		in, out := &in.QueryConfig, &out.QueryConfig
+	in.TableProperties.DeepCopyInto(&out.TableProperties)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HiveStorage.
+func (in *HiveStorage) DeepCopy() *HiveStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(HiveStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaDataSource) DeepCopyInto(out *KafkaDataSource) {
+	*out = *in
+	if in.Brokers != nil {
+		in, out := &in.Brokers, &out.Brokers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Columns != nil {
+		in, out := &in.Columns, &out.Columns
+		*out = make([]KafkaDataSourceColumn, len(*in))
+		copy(*out, *in)
+	}
+	if in.FlushInterval != nil {
+		in, out := &in.FlushInterval, &out.FlushInterval
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(PrometheusQueryConfig)
-			(*in).DeepCopyInto(*out)
+			*out = new(meta_v1.Duration)
+			**out = **in
 		}
 	}
 	if in.Storage != nil {
@@ -236,246 +320,265 @@ func (in *PrometheusMetricsDataSource) DeepCopyInto(out *PrometheusMetricsDataSo
 			(*in).DeepCopyInto(*out)
 		}
 	}
-	if in.PrometheusConfig != nil {
-		in, out := &in.PrometheusConfig, &out.PrometheusConfig
-		if *in == nil {
-			*out = nil
-		} else {
-			*out = new(PrometheusConnectionConfig)
-			**out = **in
-		}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaDataSource.
+func (in *KafkaDataSource) DeepCopy() *KafkaDataSource {
+	if in == nil {
+		return nil
 	}
+	out := new(KafkaDataSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaDataSourceColumn) DeepCopyInto(out *KafkaDataSourceColumn) {
+	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusMetricsDataSource.
-func (in *PrometheusMetricsDataSource) DeepCopy() *PrometheusMetricsDataSource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaDataSourceColumn.
+func (in *KafkaDataSourceColumn) DeepCopy() *KafkaDataSourceColumn {
 	if in == nil {
 		return nil
 	}
-	out := new(PrometheusMetricsDataSource)
+	out := new(KafkaDataSourceColumn)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrometheusQueryConfig) DeepCopyInto(out *PrometheusQueryConfig) {
+func (in *NamespaceMetadataDataSource) DeepCopyInto(out *NamespaceMetadataDataSource) {
 	*out = *in
-	if in.QueryInterval != nil {
-		in, out := &in.QueryInterval, &out.QueryInterval
-		if *in == nil {
-			*out = nil
-		} else {
-			*out = new(v1.Duration)
-			**out = **in
-		}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.StepSize != nil {
-		in, out := &in.StepSize, &out.StepSize
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(v1.Duration)
+			*out = new(meta_v1.Duration)
 			**out = **in
 		}
 	}
-	if in.ChunkSize != nil {
-		in, out := &in.ChunkSize, &out.ChunkSize
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(v1.Duration)
-			**out = **in
+			*out = new(StorageLocationRef)
+			(*in).DeepCopyInto(*out)
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusQueryConfig.
-func (in *PrometheusQueryConfig) DeepCopy() *PrometheusQueryConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceMetadataDataSource.
+func (in *NamespaceMetadataDataSource) DeepCopy() *NamespaceMetadataDataSource {
 	if in == nil {
 		return nil
 	}
-	out := new(PrometheusQueryConfig)
+	out := new(NamespaceMetadataDataSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Report) DeepCopyInto(out *Report) {
+func (in *NamespaceMetadataStatus) DeepCopyInto(out *NamespaceMetadataStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	if in.LastSnapshotTime != nil {
+		in, out := &in.LastSnapshotTime, &out.LastSnapshotTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Report.
-func (in *Report) DeepCopy() *Report {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceMetadataStatus.
+func (in *NamespaceMetadataStatus) DeepCopy() *NamespaceMetadataStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Report)
+	out := new(NamespaceMetadataStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Report) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportDataSource) DeepCopyInto(out *ReportDataSource) {
+func (in *NamespacePricingMarkup) DeepCopyInto(out *NamespacePricingMarkup) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Percent != nil {
+		in, out := &in.Percent, &out.Percent
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDataSource.
-func (in *ReportDataSource) DeepCopy() *ReportDataSource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacePricingMarkup.
+func (in *NamespacePricingMarkup) DeepCopy() *NamespacePricingMarkup {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportDataSource)
+	out := new(NamespacePricingMarkup)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ReportDataSource) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportDataSourceList) DeepCopyInto(out *ReportDataSourceList) {
+func (in *NodePricingRates) DeepCopyInto(out *NodePricingRates) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	out.ListMeta = in.ListMeta
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]*ReportDataSource, len(*in))
-		for i := range *in {
-			if (*in)[i] == nil {
-				(*out)[i] = nil
-			} else {
-				(*out)[i] = new(ReportDataSource)
-				(*in)[i].DeepCopyInto((*out)[i])
-			}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
+	in.Rates.DeepCopyInto(&out.Rates)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDataSourceList.
-func (in *ReportDataSourceList) DeepCopy() *ReportDataSourceList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePricingRates.
+func (in *NodePricingRates) DeepCopy() *NodePricingRates {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportDataSourceList)
+	out := new(NodePricingRates)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ReportDataSourceList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PartitionCompactionConfig) DeepCopyInto(out *PartitionCompactionConfig) {
+	*out = *in
+	if in.Delay != nil {
+		in, out := &in.Delay, &out.Delay
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
 	}
-	return nil
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartitionCompactionConfig.
+func (in *PartitionCompactionConfig) DeepCopy() *PartitionCompactionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PartitionCompactionConfig)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportDataSourceSpec) DeepCopyInto(out *ReportDataSourceSpec) {
+func (in *PartitionCompactionStatus) DeepCopyInto(out *PartitionCompactionStatus) {
 	*out = *in
-	if in.Promsum != nil {
-		in, out := &in.Promsum, &out.Promsum
+	if in.CompactedUntil != nil {
+		in, out := &in.CompactedUntil, &out.CompactedUntil
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(PrometheusMetricsDataSource)
-			(*in).DeepCopyInto(*out)
+			*out = (*in).DeepCopy()
 		}
 	}
-	if in.AWSBilling != nil {
-		in, out := &in.AWSBilling, &out.AWSBilling
+	if in.LastCompactionTime != nil {
+		in, out := &in.LastCompactionTime, &out.LastCompactionTime
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(AWSBillingDataSource)
-			(*in).DeepCopyInto(*out)
+			*out = (*in).DeepCopy()
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDataSourceSpec.
-func (in *ReportDataSourceSpec) DeepCopy() *ReportDataSourceSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartitionCompactionStatus.
+func (in *PartitionCompactionStatus) DeepCopy() *PartitionCompactionStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportDataSourceSpec)
+	out := new(PartitionCompactionStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportDataSourceStatus) DeepCopyInto(out *ReportDataSourceStatus) {
+func (in *PrestoSession) DeepCopyInto(out *PrestoSession) {
 	*out = *in
-	if in.PrometheusMetricImportStatus != nil {
-		in, out := &in.PrometheusMetricImportStatus, &out.PrometheusMetricImportStatus
-		if *in == nil {
-			*out = nil
-		} else {
-			*out = new(PrometheusMetricImportStatus)
-			(*in).DeepCopyInto(*out)
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDataSourceStatus.
-func (in *ReportDataSourceStatus) DeepCopy() *ReportDataSourceStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrestoSession.
+func (in *PrestoSession) DeepCopy() *PrestoSession {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportDataSourceStatus)
+	out := new(PrestoSession)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportGenerationQuery) DeepCopyInto(out *ReportGenerationQuery) {
+func (in *PrestoTable) DeepCopyInto(out *PrestoTable) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQuery.
-func (in *ReportGenerationQuery) DeepCopy() *ReportGenerationQuery {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrestoTable.
+func (in *PrestoTable) DeepCopy() *PrestoTable {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportGenerationQuery)
+	out := new(PrestoTable)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ReportGenerationQuery) DeepCopyObject() runtime.Object {
+func (in *PrestoTable) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -483,86 +586,147 @@ func (in *ReportGenerationQuery) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportGenerationQueryColumn) DeepCopyInto(out *ReportGenerationQueryColumn) {
+func (in *PrestoTableList) DeepCopyInto(out *PrestoTableList) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]*PrestoTable, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				(*out)[i] = new(PrestoTable)
+				(*in)[i].DeepCopyInto((*out)[i])
+			}
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryColumn.
-func (in *ReportGenerationQueryColumn) DeepCopy() *ReportGenerationQueryColumn {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrestoTableList.
+func (in *PrestoTableList) DeepCopy() *PrestoTableList {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportGenerationQueryColumn)
+	out := new(PrestoTableList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PrestoTableList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportGenerationQueryInputDefinition) DeepCopyInto(out *ReportGenerationQueryInputDefinition) {
+func (in *PrestoTableStatus) DeepCopyInto(out *PrestoTableStatus) {
 	*out = *in
+	in.Parameters.DeepCopyInto(&out.Parameters)
+	in.Properties.DeepCopyInto(&out.Properties)
+	if in.Partitions != nil {
+		in, out := &in.Partitions, &out.Partitions
+		*out = make([]TablePartition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryInputDefinition.
-func (in *ReportGenerationQueryInputDefinition) DeepCopy() *ReportGenerationQueryInputDefinition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrestoTableStatus.
+func (in *PrestoTableStatus) DeepCopy() *PrestoTableStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportGenerationQueryInputDefinition)
+	out := new(PrestoTableStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportGenerationQueryInputValue) DeepCopyInto(out *ReportGenerationQueryInputValue) {
+func (in *PricingMarkup) DeepCopyInto(out *PricingMarkup) {
 	*out = *in
+	if in.Percent != nil {
+		in, out := &in.Percent, &out.Percent
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]NamespacePricingMarkup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryInputValue.
-func (in *ReportGenerationQueryInputValue) DeepCopy() *ReportGenerationQueryInputValue {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PricingMarkup.
+func (in *PricingMarkup) DeepCopy() *PricingMarkup {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportGenerationQueryInputValue)
+	out := new(PricingMarkup)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in ReportGenerationQueryInputValues) DeepCopyInto(out *ReportGenerationQueryInputValues) {
-	{
-		in := &in
-		*out = make(ReportGenerationQueryInputValues, len(*in))
-		copy(*out, *in)
-		return
-	}
+func (in *PricingPolicy) DeepCopyInto(out *PricingPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryInputValues.
-func (in ReportGenerationQueryInputValues) DeepCopy() ReportGenerationQueryInputValues {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PricingPolicy.
+func (in *PricingPolicy) DeepCopy() *PricingPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportGenerationQueryInputValues)
+	out := new(PricingPolicy)
 	in.DeepCopyInto(out)
-	return *out
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PricingPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportGenerationQueryList) DeepCopyInto(out *ReportGenerationQueryList) {
+func (in *PricingPolicyList) DeepCopyInto(out *PricingPolicyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]*ReportGenerationQuery, len(*in))
+		*out = make([]*PricingPolicy, len(*in))
 		for i := range *in {
 			if (*in)[i] == nil {
 				(*out)[i] = nil
 			} else {
-				(*out)[i] = new(ReportGenerationQuery)
+				(*out)[i] = new(PricingPolicy)
 				(*in)[i].DeepCopyInto((*out)[i])
 			}
 		}
@@ -570,18 +734,18 @@ func (in *ReportGenerationQueryList) DeepCopyInto(out *ReportGenerationQueryList
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryList.
-func (in *ReportGenerationQueryList) DeepCopy() *ReportGenerationQueryList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PricingPolicyList.
+func (in *PricingPolicyList) DeepCopy() *PricingPolicyList {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportGenerationQueryList)
+	out := new(PricingPolicyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ReportGenerationQueryList) DeepCopyObject() runtime.Object {
+func (in *PricingPolicyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -589,53 +753,1057 @@ func (in *ReportGenerationQueryList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportGenerationQuerySpec) DeepCopyInto(out *ReportGenerationQuerySpec) {
+func (in *PricingPolicySpec) DeepCopyInto(out *PricingPolicySpec) {
 	*out = *in
-	if in.Columns != nil {
-		in, out := &in.Columns, &out.Columns
-		*out = make([]ReportGenerationQueryColumn, len(*in))
-		copy(*out, *in)
-	}
-	out.View = in.View
-	if in.ReportQueries != nil {
-		in, out := &in.ReportQueries, &out.ReportQueries
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.DynamicReportQueries != nil {
-		in, out := &in.DynamicReportQueries, &out.DynamicReportQueries
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.DataSources != nil {
-		in, out := &in.DataSources, &out.DataSources
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	in.Rates.DeepCopyInto(&out.Rates)
+	if in.NodeRates != nil {
+		in, out := &in.NodeRates, &out.NodeRates
+		*out = make([]NodePricingRates, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.Reports != nil {
-		in, out := &in.Reports, &out.Reports
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.Spot != nil {
+		in, out := &in.Spot, &out.Spot
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(SpotPricing)
+			(*in).DeepCopyInto(*out)
+		}
 	}
-	if in.ScheduledReports != nil {
-		in, out := &in.ScheduledReports, &out.ScheduledReports
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.StorageClassRates != nil {
+		in, out := &in.StorageClassRates, &out.StorageClassRates
+		*out = make([]StorageClassPricingRate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.Inputs != nil {
-		in, out := &in.Inputs, &out.Inputs
-		*out = make([]ReportGenerationQueryInputDefinition, len(*in))
-		copy(*out, *in)
+	if in.Markup != nil {
+		in, out := &in.Markup, &out.Markup
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PricingMarkup)
+			(*in).DeepCopyInto(*out)
+		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQuerySpec.
-func (in *ReportGenerationQuerySpec) DeepCopy() *ReportGenerationQuerySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PricingPolicySpec.
+func (in *PricingPolicySpec) DeepCopy() *PricingPolicySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportGenerationQuerySpec)
+	out := new(PricingPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PricingRates) DeepCopyInto(out *PricingRates) {
+	*out = *in
+	if in.CPUCoreHour != nil {
+		in, out := &in.CPUCoreHour, &out.CPUCoreHour
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	if in.MemoryGBHour != nil {
+		in, out := &in.MemoryGBHour, &out.MemoryGBHour
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	if in.StorageGBHour != nil {
+		in, out := &in.StorageGBHour, &out.StorageGBHour
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	if in.NodeHour != nil {
+		in, out := &in.NodeHour, &out.NodeHour
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	if in.GPUHour != nil {
+		in, out := &in.GPUHour, &out.GPUHour
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	if in.NetworkEgressGB != nil {
+		in, out := &in.NetworkEgressGB, &out.NetworkEgressGB
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	if in.LicenseCoreHour != nil {
+		in, out := &in.LicenseCoreHour, &out.LicenseCoreHour
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PricingRates.
+func (in *PricingRates) DeepCopy() *PricingRates {
+	if in == nil {
+		return nil
+	}
+	out := new(PricingRates)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusBackfillStatus) DeepCopyInto(out *PrometheusBackfillStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.EndTime.DeepCopyInto(&out.EndTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusBackfillStatus.
+func (in *PrometheusBackfillStatus) DeepCopy() *PrometheusBackfillStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusBackfillStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusConnectionConfig) DeepCopyInto(out *PrometheusConnectionConfig) {
+	*out = *in
+	if in.BearerTokenSecret != nil {
+		in, out := &in.BearerTokenSecret, &out.BearerTokenSecret
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.SecretKeySelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusConnectionConfig.
+func (in *PrometheusConnectionConfig) DeepCopy() *PrometheusConnectionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusConnectionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusMetricImportStatus) DeepCopyInto(out *PrometheusMetricImportStatus) {
+	*out = *in
+	if in.LastImportTime != nil {
+		in, out := &in.LastImportTime, &out.LastImportTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.EarliestImportedMetricTime != nil {
+		in, out := &in.EarliestImportedMetricTime, &out.EarliestImportedMetricTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.NewestImportedMetricTime != nil {
+		in, out := &in.NewestImportedMetricTime, &out.NewestImportedMetricTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.ImportCheckpoint != nil {
+		in, out := &in.ImportCheckpoint, &out.ImportCheckpoint
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.Backfill != nil {
+		in, out := &in.Backfill, &out.Backfill
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PrometheusBackfillStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusMetricImportStatus.
+func (in *PrometheusMetricImportStatus) DeepCopy() *PrometheusMetricImportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusMetricImportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusMetricsDataSource) DeepCopyInto(out *PrometheusMetricsDataSource) {
+	*out = *in
+	if in.QueryConfig != nil {
+		in, out := &in.QueryConfig, &out.QueryConfig
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PrometheusQueryConfig)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(StorageLocationRef)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.PrometheusConfig != nil {
+		in, out := &in.PrometheusConfig, &out.PrometheusConfig
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PrometheusConnectionConfig)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.RemoteWrite != nil {
+		in, out := &in.RemoteWrite, &out.RemoteWrite
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PrometheusRemoteWriteConfig)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Compaction != nil {
+		in, out := &in.Compaction, &out.Compaction
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PartitionCompactionConfig)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusMetricsDataSource.
+func (in *PrometheusMetricsDataSource) DeepCopy() *PrometheusMetricsDataSource {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusMetricsDataSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusQueryConfig) DeepCopyInto(out *PrometheusQueryConfig) {
+	*out = *in
+	if in.QueryInterval != nil {
+		in, out := &in.QueryInterval, &out.QueryInterval
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	if in.StepSize != nil {
+		in, out := &in.StepSize, &out.StepSize
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	if in.ChunkSize != nil {
+		in, out := &in.ChunkSize, &out.ChunkSize
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusQueryConfig.
+func (in *PrometheusQueryConfig) DeepCopy() *PrometheusQueryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusQueryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusRemoteWriteConfig) DeepCopyInto(out *PrometheusRemoteWriteConfig) {
+	*out = *in
+	if in.MatchLabels != nil {
+		in, out := &in.MatchLabels, &out.MatchLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.KeepLabels != nil {
+		in, out := &in.KeepLabels, &out.KeepLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusRemoteWriteConfig.
+func (in *PrometheusRemoteWriteConfig) DeepCopy() *PrometheusRemoteWriteConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusRemoteWriteConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Report) DeepCopyInto(out *Report) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Report.
+func (in *Report) DeepCopy() *Report {
+	if in == nil {
+		return nil
+	}
+	out := new(Report)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Report) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportAttempt) DeepCopyInto(out *ReportAttempt) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.FinishTime.DeepCopyInto(&out.FinishTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportAttempt.
+func (in *ReportAttempt) DeepCopy() *ReportAttempt {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportAttempt)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportChunksStatus) DeepCopyInto(out *ReportChunksStatus) {
+	*out = *in
+	if in.CompletedUntil != nil {
+		in, out := &in.CompletedUntil, &out.CompletedUntil
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.CompletedRows != nil {
+		in, out := &in.CompletedRows, &out.CompletedRows
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportChunksStatus.
+func (in *ReportChunksStatus) DeepCopy() *ReportChunksStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportChunksStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportDataSource) DeepCopyInto(out *ReportDataSource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDataSource.
+func (in *ReportDataSource) DeepCopy() *ReportDataSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportDataSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportDataSource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportDataSourceBackfill) DeepCopyInto(out *ReportDataSourceBackfill) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDataSourceBackfill.
+func (in *ReportDataSourceBackfill) DeepCopy() *ReportDataSourceBackfill {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportDataSourceBackfill)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportDataSourceList) DeepCopyInto(out *ReportDataSourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]*ReportDataSource, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				(*out)[i] = new(ReportDataSource)
+				(*in)[i].DeepCopyInto((*out)[i])
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDataSourceList.
+func (in *ReportDataSourceList) DeepCopy() *ReportDataSourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportDataSourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportDataSourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportDataSourceSpec) DeepCopyInto(out *ReportDataSourceSpec) {
+	*out = *in
+	if in.Promsum != nil {
+		in, out := &in.Promsum, &out.Promsum
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PrometheusMetricsDataSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.AWSBilling != nil {
+		in, out := &in.AWSBilling, &out.AWSBilling
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(AWSBillingDataSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.AzureCostManagement != nil {
+		in, out := &in.AzureCostManagement, &out.AzureCostManagement
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(AzureCostManagementDataSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(FileDataSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.SQL != nil {
+		in, out := &in.SQL, &out.SQL
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(SQLDataSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Kafka != nil {
+		in, out := &in.Kafka, &out.Kafka
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(KafkaDataSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.S3Inventory != nil {
+		in, out := &in.S3Inventory, &out.S3Inventory
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(S3InventoryDataSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.NamespaceMetadata != nil {
+		in, out := &in.NamespaceMetadata, &out.NamespaceMetadata
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NamespaceMetadataDataSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Backfill != nil {
+		in, out := &in.Backfill, &out.Backfill
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportDataSourceBackfill)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDataSourceSpec.
+func (in *ReportDataSourceSpec) DeepCopy() *ReportDataSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportDataSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportDataSourceStatus) DeepCopyInto(out *ReportDataSourceStatus) {
+	*out = *in
+	if in.PrometheusMetricImportStatus != nil {
+		in, out := &in.PrometheusMetricImportStatus, &out.PrometheusMetricImportStatus
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PrometheusMetricImportStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.SQLImportStatus != nil {
+		in, out := &in.SQLImportStatus, &out.SQLImportStatus
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(SQLImportStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.NamespaceMetadataStatus != nil {
+		in, out := &in.NamespaceMetadataStatus, &out.NamespaceMetadataStatus
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NamespaceMetadataStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.CompactionStatus != nil {
+		in, out := &in.CompactionStatus, &out.CompactionStatus
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PartitionCompactionStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.RetentionStatus != nil {
+		in, out := &in.RetentionStatus, &out.RetentionStatus
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RetentionStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDataSourceStatus.
+func (in *ReportDataSourceStatus) DeepCopy() *ReportDataSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportDataSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportDryRun) DeepCopyInto(out *ReportDryRun) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDryRun.
+func (in *ReportDryRun) DeepCopy() *ReportDryRun {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportDryRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportDryRunStatus) DeepCopyInto(out *ReportDryRunStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDryRunStatus.
+func (in *ReportDryRunStatus) DeepCopy() *ReportDryRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportDryRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQuery) DeepCopyInto(out *ReportGenerationQuery) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQuery.
+func (in *ReportGenerationQuery) DeepCopy() *ReportGenerationQuery {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQuery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportGenerationQuery) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQueryColumn) DeepCopyInto(out *ReportGenerationQueryColumn) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryColumn.
+func (in *ReportGenerationQueryColumn) DeepCopy() *ReportGenerationQueryColumn {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQueryColumn)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQueryInputDefinition) DeepCopyInto(out *ReportGenerationQueryInputDefinition) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(string)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryInputDefinition.
+func (in *ReportGenerationQueryInputDefinition) DeepCopy() *ReportGenerationQueryInputDefinition {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQueryInputDefinition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQueryInputValue) DeepCopyInto(out *ReportGenerationQueryInputValue) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryInputValue.
+func (in *ReportGenerationQueryInputValue) DeepCopy() *ReportGenerationQueryInputValue {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQueryInputValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in ReportGenerationQueryInputValues) DeepCopyInto(out *ReportGenerationQueryInputValues) {
+	{
+		in := &in
+		*out = make(ReportGenerationQueryInputValues, len(*in))
+		copy(*out, *in)
+		return
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryInputValues.
+func (in ReportGenerationQueryInputValues) DeepCopy() ReportGenerationQueryInputValues {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQueryInputValues)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQueryList) DeepCopyInto(out *ReportGenerationQueryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]*ReportGenerationQuery, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				(*out)[i] = new(ReportGenerationQuery)
+				(*in)[i].DeepCopyInto((*out)[i])
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryList.
+func (in *ReportGenerationQueryList) DeepCopy() *ReportGenerationQueryList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQueryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportGenerationQueryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQueryMaterialization) DeepCopyInto(out *ReportGenerationQueryMaterialization) {
+	*out = *in
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	if in.Start != nil {
+		in, out := &in.Start, &out.Start
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryMaterialization.
+func (in *ReportGenerationQueryMaterialization) DeepCopy() *ReportGenerationQueryMaterialization {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQueryMaterialization)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQueryMaterializedStatus) DeepCopyInto(out *ReportGenerationQueryMaterializedStatus) {
+	*out = *in
+	if in.MaterializedUntil != nil {
+		in, out := &in.MaterializedUntil, &out.MaterializedUntil
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.LastRefreshTime != nil {
+		in, out := &in.LastRefreshTime, &out.LastRefreshTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryMaterializedStatus.
+func (in *ReportGenerationQueryMaterializedStatus) DeepCopy() *ReportGenerationQueryMaterializedStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQueryMaterializedStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQueryRollup) DeepCopyInto(out *ReportGenerationQueryRollup) {
+	*out = *in
+	if in.Aggregations != nil {
+		in, out := &in.Aggregations, &out.Aggregations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryRollup.
+func (in *ReportGenerationQueryRollup) DeepCopy() *ReportGenerationQueryRollup {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQueryRollup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQuerySpec) DeepCopyInto(out *ReportGenerationQuerySpec) {
+	*out = *in
+	if in.Columns != nil {
+		in, out := &in.Columns, &out.Columns
+		*out = make([]ReportGenerationQueryColumn, len(*in))
+		copy(*out, *in)
+	}
+	out.View = in.View
+	if in.ReportQueries != nil {
+		in, out := &in.ReportQueries, &out.ReportQueries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DynamicReportQueries != nil {
+		in, out := &in.DynamicReportQueries, &out.DynamicReportQueries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DataSources != nil {
+		in, out := &in.DataSources, &out.DataSources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Reports != nil {
+		in, out := &in.Reports, &out.Reports
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ScheduledReports != nil {
+		in, out := &in.ScheduledReports, &out.ScheduledReports
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]ReportGenerationQueryInputDefinition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Session != nil {
+		in, out := &in.Session, &out.Session
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PrestoSession)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Rollup != nil {
+		in, out := &in.Rollup, &out.Rollup
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportGenerationQueryRollup)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Materialized != nil {
+		in, out := &in.Materialized, &out.Materialized
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportGenerationQueryMaterialization)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQuerySpec.
+func (in *ReportGenerationQuerySpec) DeepCopy() *ReportGenerationQuerySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQuerySpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -643,32 +1811,463 @@ func (in *ReportGenerationQuerySpec) DeepCopy() *ReportGenerationQuerySpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReportGenerationQueryStatus) DeepCopyInto(out *ReportGenerationQueryStatus) {
 	*out = *in
+	if in.UnresolvedDependencies != nil {
+		in, out := &in.UnresolvedDependencies, &out.UnresolvedDependencies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DependencyCycle != nil {
+		in, out := &in.DependencyCycle, &out.DependencyCycle
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Materialized != nil {
+		in, out := &in.Materialized, &out.Materialized
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportGenerationQueryMaterializedStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryStatus.
+func (in *ReportGenerationQueryStatus) DeepCopy() *ReportGenerationQueryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQueryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQueryVersion) DeepCopyInto(out *ReportGenerationQueryVersion) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryVersion.
+func (in *ReportGenerationQueryVersion) DeepCopy() *ReportGenerationQueryVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQueryVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportList) DeepCopyInto(out *ReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]*Report, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				(*out)[i] = new(Report)
+				(*in)[i].DeepCopyInto((*out)[i])
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportList.
+func (in *ReportList) DeepCopy() *ReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportProgress) DeepCopyInto(out *ReportProgress) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	out.Elapsed = in.Elapsed
+	if in.PercentComplete != nil {
+		in, out := &in.PercentComplete, &out.PercentComplete
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int)
+			**out = **in
+		}
+	}
+	if in.RowsWritten != nil {
+		in, out := &in.RowsWritten, &out.RowsWritten
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportProgress.
+func (in *ReportProgress) DeepCopy() *ReportProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportPrometheusQuery) DeepCopyInto(out *ReportPrometheusQuery) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportPrometheusQuery.
+func (in *ReportPrometheusQuery) DeepCopy() *ReportPrometheusQuery {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportPrometheusQuery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportPrometheusQuery) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportPrometheusQueryList) DeepCopyInto(out *ReportPrometheusQueryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]*ReportPrometheusQuery, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				(*out)[i] = new(ReportPrometheusQuery)
+				(*in)[i].DeepCopyInto((*out)[i])
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportPrometheusQueryList.
+func (in *ReportPrometheusQueryList) DeepCopy() *ReportPrometheusQueryList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportPrometheusQueryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportPrometheusQueryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportPrometheusQuerySpec) DeepCopyInto(out *ReportPrometheusQuerySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportPrometheusQuerySpec.
+func (in *ReportPrometheusQuerySpec) DeepCopy() *ReportPrometheusQuerySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportPrometheusQuerySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportRetryPolicy) DeepCopyInto(out *ReportRetryPolicy) {
+	*out = *in
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	if in.MaxBackoff != nil {
+		in, out := &in.MaxBackoff, &out.MaxBackoff
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportRetryPolicy.
+func (in *ReportRetryPolicy) DeepCopy() *ReportRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportSpec) DeepCopyInto(out *ReportSpec) {
+	*out = *in
+	if in.ReportingStart != nil {
+		in, out := &in.ReportingStart, &out.ReportingStart
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.ReportingEnd != nil {
+		in, out := &in.ReportingEnd, &out.ReportingEnd
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make(ReportGenerationQueryInputValues, len(*in))
+		copy(*out, *in)
+	}
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	if in.Output != nil {
+		in, out := &in.Output, &out.Output
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(StorageLocationRef)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.ChunkSize != nil {
+		in, out := &in.ChunkSize, &out.ChunkSize
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	if in.MaxRuntime != nil {
+		in, out := &in.MaxRuntime, &out.MaxRuntime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	if in.MaxResultRows != nil {
+		in, out := &in.MaxResultRows, &out.MaxResultRows
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportRetryPolicy)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportDryRun)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportSpec.
+func (in *ReportSpec) DeepCopy() *ReportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportStatus) DeepCopyInto(out *ReportStatus) {
+	*out = *in
+	if in.RowCount != nil {
+		in, out := &in.RowCount, &out.RowCount
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	if in.GenerationQueryVersions != nil {
+		in, out := &in.GenerationQueryVersions, &out.GenerationQueryVersions
+		*out = make([]ReportGenerationQueryVersion, len(*in))
+		copy(*out, *in)
+	}
+	if in.Chunks != nil {
+		in, out := &in.Chunks, &out.Chunks
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportChunksStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportProgress)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Attempts != nil {
+		in, out := &in.Attempts, &out.Attempts
+		*out = make([]ReportAttempt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportDryRunStatus)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportStatus.
+func (in *ReportStatus) DeepCopy() *ReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportingOperatorConfig) DeepCopyInto(out *ReportingOperatorConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryStatus.
-func (in *ReportGenerationQueryStatus) DeepCopy() *ReportGenerationQueryStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportingOperatorConfig.
+func (in *ReportingOperatorConfig) DeepCopy() *ReportingOperatorConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportGenerationQueryStatus)
+	out := new(ReportingOperatorConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportingOperatorConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportList) DeepCopyInto(out *ReportList) {
+func (in *ReportingOperatorConfigList) DeepCopyInto(out *ReportingOperatorConfigList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]*Report, len(*in))
+		*out = make([]*ReportingOperatorConfig, len(*in))
 		for i := range *in {
 			if (*in)[i] == nil {
 				(*out)[i] = nil
 			} else {
-				(*out)[i] = new(Report)
+				(*out)[i] = new(ReportingOperatorConfig)
 				(*in)[i].DeepCopyInto((*out)[i])
 			}
 		}
@@ -676,18 +2275,18 @@ func (in *ReportList) DeepCopyInto(out *ReportList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportList.
-func (in *ReportList) DeepCopy() *ReportList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportingOperatorConfigList.
+func (in *ReportingOperatorConfigList) DeepCopy() *ReportingOperatorConfigList {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportList)
+	out := new(ReportingOperatorConfigList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ReportList) DeepCopyObject() runtime.Object {
+func (in *ReportingOperatorConfigList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -695,121 +2294,330 @@ func (in *ReportList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportPrometheusQuery) DeepCopyInto(out *ReportPrometheusQuery) {
+func (in *ReportingOperatorConfigSpec) DeepCopyInto(out *ReportingOperatorConfigSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	if in.LogDMLQueries != nil {
+		in, out := &in.LogDMLQueries, &out.LogDMLQueries
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	if in.LogDDLQueries != nil {
+		in, out := &in.LogDDLQueries, &out.LogDDLQueries
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	if in.Promsum != nil {
+		in, out := &in.Promsum, &out.Promsum
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportingOperatorPromsumConfig)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.ReportDataSourceWorkers != nil {
+		in, out := &in.ReportDataSourceWorkers, &out.ReportDataSourceWorkers
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int)
+			**out = **in
+		}
+	}
+	if in.MaxConcurrentReports != nil {
+		in, out := &in.MaxConcurrentReports, &out.MaxConcurrentReports
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int)
+			**out = **in
+		}
+	}
+	if in.PrestoMaxConcurrentQueries != nil {
+		in, out := &in.PrestoMaxConcurrentQueries, &out.PrestoMaxConcurrentQueries
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int)
+			**out = **in
+		}
+	}
+	if in.ReportQueryTimeout != nil {
+		in, out := &in.ReportQueryTimeout, &out.ReportQueryTimeout
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportPrometheusQuery.
-func (in *ReportPrometheusQuery) DeepCopy() *ReportPrometheusQuery {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportingOperatorConfigSpec.
+func (in *ReportingOperatorConfigSpec) DeepCopy() *ReportingOperatorConfigSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportPrometheusQuery)
+	out := new(ReportingOperatorConfigSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ReportPrometheusQuery) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportingOperatorPromsumConfig) DeepCopyInto(out *ReportingOperatorPromsumConfig) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
 	}
-	return nil
+	if in.StepSize != nil {
+		in, out := &in.StepSize, &out.StepSize
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	if in.ChunkSize != nil {
+		in, out := &in.ChunkSize, &out.ChunkSize
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	if in.MaxQueryRangeDuration != nil {
+		in, out := &in.MaxQueryRangeDuration, &out.MaxQueryRangeDuration
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	if in.MaxBackfillImportDuration != nil {
+		in, out := &in.MaxBackfillImportDuration, &out.MaxBackfillImportDuration
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	if in.MaxConcurrentImportsPerEndpoint != nil {
+		in, out := &in.MaxConcurrentImportsPerEndpoint, &out.MaxConcurrentImportsPerEndpoint
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportingOperatorPromsumConfig.
+func (in *ReportingOperatorPromsumConfig) DeepCopy() *ReportingOperatorPromsumConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportingOperatorPromsumConfig)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportPrometheusQueryList) DeepCopyInto(out *ReportPrometheusQueryList) {
+func (in *RetentionStatus) DeepCopyInto(out *RetentionStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	out.ListMeta = in.ListMeta
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]*ReportPrometheusQuery, len(*in))
-		for i := range *in {
-			if (*in)[i] == nil {
-				(*out)[i] = nil
-			} else {
-				(*out)[i] = new(ReportPrometheusQuery)
-				(*in)[i].DeepCopyInto((*out)[i])
-			}
+	if in.PrunedUntil != nil {
+		in, out := &in.PrunedUntil, &out.PrunedUntil
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.LastPruneTime != nil {
+		in, out := &in.LastPruneTime, &out.LastPruneTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportPrometheusQueryList.
-func (in *ReportPrometheusQueryList) DeepCopy() *ReportPrometheusQueryList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionStatus.
+func (in *RetentionStatus) DeepCopy() *RetentionStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportPrometheusQueryList)
+	out := new(RetentionStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ReportPrometheusQueryList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3Bucket) DeepCopyInto(out *S3Bucket) {
+	*out = *in
+	if in.Endpoint != nil {
+		in, out := &in.Endpoint, &out.Endpoint
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(S3Endpoint)
+			**out = **in
+		}
 	}
-	return nil
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Bucket.
+func (in *S3Bucket) DeepCopy() *S3Bucket {
+	if in == nil {
+		return nil
+	}
+	out := new(S3Bucket)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportPrometheusQuerySpec) DeepCopyInto(out *ReportPrometheusQuerySpec) {
+func (in *S3Encryption) DeepCopyInto(out *S3Encryption) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Encryption.
+func (in *S3Encryption) DeepCopy() *S3Encryption {
+	if in == nil {
+		return nil
+	}
+	out := new(S3Encryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3Endpoint) DeepCopyInto(out *S3Endpoint) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Endpoint.
+func (in *S3Endpoint) DeepCopy() *S3Endpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(S3Endpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3InventoryDataSource) DeepCopyInto(out *S3InventoryDataSource) {
+	*out = *in
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(S3Bucket)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3InventoryDataSource.
+func (in *S3InventoryDataSource) DeepCopy() *S3InventoryDataSource {
+	if in == nil {
+		return nil
+	}
+	out := new(S3InventoryDataSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3Storage) DeepCopyInto(out *S3Storage) {
 	*out = *in
+	if in.Endpoint != nil {
+		in, out := &in.Endpoint, &out.Endpoint
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(S3Endpoint)
+			**out = **in
+		}
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(S3Encryption)
+			**out = **in
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportPrometheusQuerySpec.
-func (in *ReportPrometheusQuerySpec) DeepCopy() *ReportPrometheusQuerySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Storage.
+func (in *S3Storage) DeepCopy() *S3Storage {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportPrometheusQuerySpec)
+	out := new(S3Storage)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportSpec) DeepCopyInto(out *ReportSpec) {
+func (in *SQLDataSource) DeepCopyInto(out *SQLDataSource) {
 	*out = *in
-	if in.ReportingStart != nil {
-		in, out := &in.ReportingStart, &out.ReportingStart
-		if *in == nil {
-			*out = nil
-		} else {
-			*out = (*in).DeepCopy()
-		}
-	}
-	if in.ReportingEnd != nil {
-		in, out := &in.ReportingEnd, &out.ReportingEnd
+	if in.ConnectionSecret != nil {
+		in, out := &in.ConnectionSecret, &out.ConnectionSecret
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = (*in).DeepCopy()
+			*out = new(v1.SecretKeySelector)
+			(*in).DeepCopyInto(*out)
 		}
 	}
-	if in.Inputs != nil {
-		in, out := &in.Inputs, &out.Inputs
-		*out = make(ReportGenerationQueryInputValues, len(*in))
+	if in.Columns != nil {
+		in, out := &in.Columns, &out.Columns
+		*out = make([]SQLDataSourceColumn, len(*in))
 		copy(*out, *in)
 	}
-	if in.GracePeriod != nil {
-		in, out := &in.GracePeriod, &out.GracePeriod
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(v1.Duration)
+			*out = new(meta_v1.Duration)
 			**out = **in
 		}
 	}
-	if in.Output != nil {
-		in, out := &in.Output, &out.Output
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
 		if *in == nil {
 			*out = nil
 		} else {
@@ -820,44 +2628,68 @@ func (in *ReportSpec) DeepCopyInto(out *ReportSpec) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportSpec.
-func (in *ReportSpec) DeepCopy() *ReportSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLDataSource.
+func (in *SQLDataSource) DeepCopy() *SQLDataSource {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportSpec)
+	out := new(SQLDataSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportStatus) DeepCopyInto(out *ReportStatus) {
+func (in *SQLDataSourceColumn) DeepCopyInto(out *SQLDataSourceColumn) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportStatus.
-func (in *ReportStatus) DeepCopy() *ReportStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLDataSourceColumn.
+func (in *SQLDataSourceColumn) DeepCopy() *SQLDataSourceColumn {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportStatus)
+	out := new(SQLDataSourceColumn)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *S3Bucket) DeepCopyInto(out *S3Bucket) {
+func (in *SQLImportStatus) DeepCopyInto(out *SQLImportStatus) {
 	*out = *in
+	if in.LastImportTime != nil {
+		in, out := &in.LastImportTime, &out.LastImportTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Bucket.
-func (in *S3Bucket) DeepCopy() *S3Bucket {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLImportStatus.
+func (in *SQLImportStatus) DeepCopy() *SQLImportStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(S3Bucket)
+	out := new(SQLImportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SQSNotifications) DeepCopyInto(out *SQSNotifications) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQSNotifications.
+func (in *SQSNotifications) DeepCopy() *SQSNotifications {
+	if in == nil {
+		return nil
+	}
+	out := new(SQSNotifications)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -946,6 +2778,55 @@ func (in *ScheduledReportList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledReportMetrics) DeepCopyInto(out *ScheduledReportMetrics) {
+	*out = *in
+	if in.ValueColumns != nil {
+		in, out := &in.ValueColumns, &out.ValueColumns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelColumns != nil {
+		in, out := &in.LabelColumns, &out.LabelColumns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledReportMetrics.
+func (in *ScheduledReportMetrics) DeepCopy() *ScheduledReportMetrics {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledReportMetrics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledReportRun) DeepCopyInto(out *ScheduledReportRun) {
+	*out = *in
+	in.PeriodStart.DeepCopyInto(&out.PeriodStart)
+	in.PeriodEnd.DeepCopyInto(&out.PeriodEnd)
+	if in.GenerationQueryVersions != nil {
+		in, out := &in.GenerationQueryVersions, &out.GenerationQueryVersions
+		*out = make([]ReportGenerationQueryVersion, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledReportRun.
+func (in *ScheduledReportRun) DeepCopy() *ScheduledReportRun {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledReportRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScheduledReportSchedule) DeepCopyInto(out *ScheduledReportSchedule) {
 	*out = *in
@@ -1130,7 +3011,7 @@ func (in *ScheduledReportSpec) DeepCopyInto(out *ScheduledReportSpec) {
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(v1.Duration)
+			*out = new(meta_v1.Duration)
 			**out = **in
 		}
 	}
@@ -1148,6 +3029,33 @@ func (in *ScheduledReportSpec) DeepCopyInto(out *ScheduledReportSpec) {
 			(*in).DeepCopyInto(*out)
 		}
 	}
+	if in.MaxRuntime != nil {
+		in, out := &in.MaxRuntime, &out.MaxRuntime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	if in.MaxResultRows != nil {
+		in, out := &in.MaxResultRows, &out.MaxResultRows
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ScheduledReportMetrics)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -1179,6 +3087,22 @@ func (in *ScheduledReportStatus) DeepCopyInto(out *ScheduledReportStatus) {
 			*out = (*in).DeepCopy()
 		}
 	}
+	if in.RowCount != nil {
+		in, out := &in.RowCount, &out.RowCount
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	if in.RunHistory != nil {
+		in, out := &in.RunHistory, &out.RunHistory
+		*out = make([]ScheduledReportRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1192,12 +3116,128 @@ func (in *ScheduledReportStatus) DeepCopy() *ScheduledReportStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedVolumeStorage) DeepCopyInto(out *SharedVolumeStorage) {
+	*out = *in
+	if in.CapacityWarningThreshold != nil {
+		in, out := &in.CapacityWarningThreshold, &out.CapacityWarningThreshold
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeStorage.
+func (in *SharedVolumeStorage) DeepCopy() *SharedVolumeStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedVolumeStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpotPricing) DeepCopyInto(out *SpotPricing) {
+	*out = *in
+	if in.NodeSelectors != nil {
+		in, out := &in.NodeSelectors, &out.NodeSelectors
+		*out = make([]map[string]string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(map[string]string, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+		}
+	}
+	in.Rates.DeepCopyInto(&out.Rates)
+	if in.DiscountPercent != nil {
+		in, out := &in.DiscountPercent, &out.DiscountPercent
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpotPricing.
+func (in *SpotPricing) DeepCopy() *SpotPricing {
+	if in == nil {
+		return nil
+	}
+	out := new(SpotPricing)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageBudget) DeepCopyInto(out *StorageBudget) {
+	*out = *in
+	out.MaxSize = in.MaxSize.DeepCopy()
+	if in.ProjectionPeriod != nil {
+		in, out := &in.ProjectionPeriod, &out.ProjectionPeriod
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageBudget.
+func (in *StorageBudget) DeepCopy() *StorageBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageClassPricingRate) DeepCopyInto(out *StorageClassPricingRate) {
+	*out = *in
+	if in.StorageGBHour != nil {
+		in, out := &in.StorageGBHour, &out.StorageGBHour
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageClassPricingRate.
+func (in *StorageClassPricingRate) DeepCopy() *StorageClassPricingRate {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageClassPricingRate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageLocation) DeepCopyInto(out *StorageLocation) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -1294,6 +3334,60 @@ func (in *StorageLocationSpec) DeepCopyInto(out *StorageLocationSpec) {
 			(*in).DeepCopyInto(*out)
 		}
 	}
+	if in.Azure != nil {
+		in, out := &in.Azure, &out.Azure
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(AzureStorage)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.GCS != nil {
+		in, out := &in.GCS, &out.GCS
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(GCSStorage)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.HDFS != nil {
+		in, out := &in.HDFS, &out.HDFS
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(HDFSStorage)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.SharedVolume != nil {
+		in, out := &in.SharedVolume, &out.SharedVolume
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(SharedVolumeStorage)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(S3Storage)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Budget != nil {
+		in, out := &in.Budget, &out.Budget
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(StorageBudget)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -1307,6 +3401,30 @@ func (in *StorageLocationSpec) DeepCopy() *StorageLocationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageLocationStatus) DeepCopyInto(out *StorageLocationStatus) {
+	*out = *in
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageLocationStatus.
+func (in *StorageLocationStatus) DeepCopy() *StorageLocationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageLocationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TableParameters) DeepCopyInto(out *TableParameters) {
 	*out = *in
@@ -1366,6 +3484,13 @@ func (in *TableProperties) DeepCopyInto(out *TableProperties) {
 			(*out)[key] = val
 		}
 	}
+	if in.TableProperties != nil {
+		in, out := &in.TableProperties, &out.TableProperties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -0,0 +1,38 @@
+package v1alpha2
+
+import (
+	"k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition describes the state of a Report or ScheduledReport.
+type Condition struct {
+	// Type of the condition.
+	Type ConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status v1.ConditionStatus `json:"status"`
+	// Last time the condition was checked.
+	// +optional
+	LastUpdateTime meta.Time `json:"lastUpdateTime,omitempty"`
+	// Last time the condition transit from one status to another.
+	// +optional
+	LastTransitionTime meta.Time `json:"lastTransitionTime,omitempty"`
+	// (brief) reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Human readable message indicating details about last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+type ConditionType string
+
+const (
+	// ConditionRunning is True while a report is being generated, and False
+	// while it's waiting to be generated.
+	ConditionRunning ConditionType = "Running"
+	// ConditionFinished is True once a Report has been generated.
+	ConditionFinished ConditionType = "Finished"
+	// ConditionFailed is True when generating a report failed.
+	ConditionFailed ConditionType = "Failed"
+)
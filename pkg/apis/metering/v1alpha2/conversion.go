@@ -0,0 +1,349 @@
+package v1alpha2
+
+import (
+	"encoding/json"
+
+	"k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+// InputTypesAnnotation records the types of the inputs of a v1alpha2
+// object which aren't strings while it's stored as v1alpha1, where every
+// input is a string, so they're restored when it's converted back to
+// v1alpha2.
+const InputTypesAnnotation = GroupName + "/v1alpha2-input-types"
+
+func addConversionFuncs(scheme *runtime.Scheme) error {
+	return scheme.AddConversionFuncs(
+		Convert_v1alpha1_Report_To_v1alpha2_Report,
+		Convert_v1alpha2_Report_To_v1alpha1_Report,
+		Convert_v1alpha1_ScheduledReport_To_v1alpha2_ScheduledReport,
+		Convert_v1alpha2_ScheduledReport_To_v1alpha1_ScheduledReport,
+	)
+}
+
+func Convert_v1alpha1_Report_To_v1alpha2_Report(in *v1alpha1.Report, out *Report, s conversion.Scope) error {
+	in = in.DeepCopy()
+	out.ObjectMeta = in.ObjectMeta
+	out.Spec = ReportSpec{
+		ReportingStart:        in.Spec.ReportingStart,
+		ReportingEnd:          in.Spec.ReportingEnd,
+		Inputs:                inputsFromV1alpha1(&out.ObjectMeta, in.Spec.Inputs),
+		GenerationQueryName:   in.Spec.GenerationQueryName,
+		RunImmediately:        in.Spec.RunImmediately,
+		GracePeriod:           in.Spec.GracePeriod,
+		Output:                in.Spec.Output,
+		PricingPolicy:         in.Spec.PricingPolicy,
+		ReportingEndInputName: in.Spec.ReportingEndInputName,
+		ChunkSize:             in.Spec.ChunkSize,
+		MaxRuntime:            in.Spec.MaxRuntime,
+		MaxResultRows:         in.Spec.MaxResultRows,
+		RetryPolicy:           in.Spec.RetryPolicy,
+		DryRun:                in.Spec.DryRun,
+	}
+	out.Status = ReportStatus{
+		Conditions:              reportConditionsFromPhase(in.Status.Phase, in.Status.Reason, in.Status.Output),
+		TableName:               in.Status.TableName,
+		RowCount:                in.Status.RowCount,
+		GenerationQueryVersions: in.Status.GenerationQueryVersions,
+		Chunks:                  in.Status.Chunks,
+		Progress:                in.Status.Progress,
+		Attempts:                in.Status.Attempts,
+		FailedAttempts:          in.Status.FailedAttempts,
+		NextRetryTime:           in.Status.NextRetryTime,
+		DryRun:                  in.Status.DryRun,
+	}
+	return nil
+}
+
+func Convert_v1alpha2_Report_To_v1alpha1_Report(in *Report, out *v1alpha1.Report, s conversion.Scope) error {
+	in = in.DeepCopy()
+	out.ObjectMeta = in.ObjectMeta
+	out.Spec = v1alpha1.ReportSpec{
+		ReportingStart:        in.Spec.ReportingStart,
+		ReportingEnd:          in.Spec.ReportingEnd,
+		Inputs:                inputsToV1alpha1(&out.ObjectMeta, in.Spec.Inputs),
+		GenerationQueryName:   in.Spec.GenerationQueryName,
+		RunImmediately:        in.Spec.RunImmediately,
+		GracePeriod:           in.Spec.GracePeriod,
+		Output:                in.Spec.Output,
+		PricingPolicy:         in.Spec.PricingPolicy,
+		ReportingEndInputName: in.Spec.ReportingEndInputName,
+		ChunkSize:             in.Spec.ChunkSize,
+		MaxRuntime:            in.Spec.MaxRuntime,
+		MaxResultRows:         in.Spec.MaxResultRows,
+		RetryPolicy:           in.Spec.RetryPolicy,
+		DryRun:                in.Spec.DryRun,
+	}
+	phase, reason, output := reportPhaseFromConditions(in.Status.Conditions)
+	out.Status = v1alpha1.ReportStatus{
+		Phase:                   phase,
+		Reason:                  reason,
+		Output:                  output,
+		TableName:               in.Status.TableName,
+		RowCount:                in.Status.RowCount,
+		GenerationQueryVersions: in.Status.GenerationQueryVersions,
+		Chunks:                  in.Status.Chunks,
+		Progress:                in.Status.Progress,
+		Attempts:                in.Status.Attempts,
+		FailedAttempts:          in.Status.FailedAttempts,
+		NextRetryTime:           in.Status.NextRetryTime,
+		DryRun:                  in.Status.DryRun,
+	}
+	return nil
+}
+
+func Convert_v1alpha1_ScheduledReport_To_v1alpha2_ScheduledReport(in *v1alpha1.ScheduledReport, out *ScheduledReport, s conversion.Scope) error {
+	in = in.DeepCopy()
+	out.ObjectMeta = in.ObjectMeta
+	out.Spec = ScheduledReportSpec{
+		GenerationQueryName:   in.Spec.GenerationQueryName,
+		Schedule:              scheduleFromV1alpha1(in.Spec.Schedule),
+		ReportingStart:        in.Spec.ReportingStart,
+		ReportingEnd:          in.Spec.ReportingEnd,
+		GracePeriod:           in.Spec.GracePeriod,
+		OverwriteExistingData: in.Spec.OverwriteExistingData,
+		Inputs:                inputsFromV1alpha1(&out.ObjectMeta, in.Spec.Inputs),
+		Output:                in.Spec.Output,
+		PricingPolicy:         in.Spec.PricingPolicy,
+		MaxRuntime:            in.Spec.MaxRuntime,
+		MaxResultRows:         in.Spec.MaxResultRows,
+		Metrics:               in.Spec.Metrics,
+	}
+	var conditions []Condition
+	for _, cond := range in.Status.Conditions {
+		condType := ConditionType(cond.Type)
+		if cond.Type == v1alpha1.ScheduledReportFailure {
+			condType = ConditionFailed
+		}
+		conditions = append(conditions, Condition{
+			Type:               condType,
+			Status:             cond.Status,
+			LastUpdateTime:     cond.LastUpdateTime,
+			LastTransitionTime: cond.LastTransitionTime,
+			Reason:             cond.Reason,
+			Message:            cond.Message,
+		})
+	}
+	out.Status = ScheduledReportStatus{
+		Conditions:     conditions,
+		LastReportTime: in.Status.LastReportTime,
+		TableName:      in.Status.TableName,
+		RowCount:       in.Status.RowCount,
+		RunHistory:     in.Status.RunHistory,
+	}
+	return nil
+}
+
+func Convert_v1alpha2_ScheduledReport_To_v1alpha1_ScheduledReport(in *ScheduledReport, out *v1alpha1.ScheduledReport, s conversion.Scope) error {
+	in = in.DeepCopy()
+	out.ObjectMeta = in.ObjectMeta
+	out.Spec = v1alpha1.ScheduledReportSpec{
+		GenerationQueryName:   in.Spec.GenerationQueryName,
+		Schedule:              scheduleToV1alpha1(in.Spec.Schedule),
+		ReportingStart:        in.Spec.ReportingStart,
+		ReportingEnd:          in.Spec.ReportingEnd,
+		GracePeriod:           in.Spec.GracePeriod,
+		OverwriteExistingData: in.Spec.OverwriteExistingData,
+		Inputs:                inputsToV1alpha1(&out.ObjectMeta, in.Spec.Inputs),
+		Output:                in.Spec.Output,
+		PricingPolicy:         in.Spec.PricingPolicy,
+		MaxRuntime:            in.Spec.MaxRuntime,
+		MaxResultRows:         in.Spec.MaxResultRows,
+		Metrics:               in.Spec.Metrics,
+	}
+	var conditions []v1alpha1.ScheduledReportCondition
+	for _, cond := range in.Status.Conditions {
+		condType := v1alpha1.ScheduledReportConditionType(cond.Type)
+		if cond.Type == ConditionFailed {
+			condType = v1alpha1.ScheduledReportFailure
+		}
+		conditions = append(conditions, v1alpha1.ScheduledReportCondition{
+			Type:               condType,
+			Status:             cond.Status,
+			LastUpdateTime:     cond.LastUpdateTime,
+			LastTransitionTime: cond.LastTransitionTime,
+			Reason:             cond.Reason,
+			Message:            cond.Message,
+		})
+	}
+	out.Status = v1alpha1.ScheduledReportStatus{
+		Conditions:     conditions,
+		LastReportTime: in.Status.LastReportTime,
+		TableName:      in.Status.TableName,
+		RowCount:       in.Status.RowCount,
+		RunHistory:     in.Status.RunHistory,
+	}
+	return nil
+}
+
+// reportConditionsFromPhase returns the condition corresponding to the
+// phase of a v1alpha1 Report, which has it's reason and output.
+func reportConditionsFromPhase(phase v1alpha1.ReportPhase, reason, output string) []Condition {
+	cond := Condition{Reason: reason, Message: output}
+	switch phase {
+	case v1alpha1.ReportPhaseWaiting:
+		cond.Type, cond.Status = ConditionRunning, v1.ConditionFalse
+	case v1alpha1.ReportPhaseStarted:
+		cond.Type, cond.Status = ConditionRunning, v1.ConditionTrue
+	case v1alpha1.ReportPhaseFinished:
+		cond.Type, cond.Status = ConditionFinished, v1.ConditionTrue
+	case v1alpha1.ReportPhaseError:
+		cond.Type, cond.Status = ConditionFailed, v1.ConditionTrue
+	default:
+		// Reports without a phase haven't been processed yet, but may
+		// still have an output, such as after they're retried.
+		if reason == "" && output == "" {
+			return nil
+		}
+		cond.Type, cond.Status = ConditionRunning, v1.ConditionUnknown
+	}
+	return []Condition{cond}
+}
+
+// reportPhaseFromConditions returns the phase, reason and output of a
+// v1alpha1 Report from the conditions of a v1alpha2 Report. Failed takes
+// precedence over Finished, which takes precedence over Running.
+func reportPhaseFromConditions(conditions []Condition) (v1alpha1.ReportPhase, string, string) {
+	for _, match := range []struct {
+		condType ConditionType
+		status   v1.ConditionStatus
+		phase    v1alpha1.ReportPhase
+	}{
+		{ConditionFailed, v1.ConditionTrue, v1alpha1.ReportPhaseError},
+		{ConditionFinished, v1.ConditionTrue, v1alpha1.ReportPhaseFinished},
+		{ConditionRunning, v1.ConditionTrue, v1alpha1.ReportPhaseStarted},
+		{ConditionRunning, v1.ConditionFalse, v1alpha1.ReportPhaseWaiting},
+		{ConditionRunning, v1.ConditionUnknown, ""},
+	} {
+		for _, cond := range conditions {
+			if cond.Type == match.condType && cond.Status == match.status {
+				return match.phase, cond.Reason, cond.Message
+			}
+		}
+	}
+	return "", "", ""
+}
+
+// scheduleFromV1alpha1 returns the schedule selected by the period of a
+// v1alpha1 schedule. The other schedules are ignored in v1alpha1, so
+// they're dropped.
+func scheduleFromV1alpha1(in v1alpha1.ScheduledReportSchedule) ScheduledReportSchedule {
+	out := ScheduledReportSchedule{Timezone: in.Timezone}
+	switch in.Period {
+	case v1alpha1.ScheduledReportPeriodCron:
+		out.Cron = in.Cron
+		if out.Cron == nil {
+			out.Cron = &v1alpha1.ScheduledReportScheduleCron{}
+		}
+	case v1alpha1.ScheduledReportPeriodHourly:
+		out.Hourly = in.Hourly
+		if out.Hourly == nil {
+			out.Hourly = &v1alpha1.ScheduledReportScheduleHourly{}
+		}
+	case v1alpha1.ScheduledReportPeriodDaily:
+		out.Daily = in.Daily
+		if out.Daily == nil {
+			out.Daily = &v1alpha1.ScheduledReportScheduleDaily{}
+		}
+	case v1alpha1.ScheduledReportPeriodWeekly:
+		out.Weekly = in.Weekly
+		if out.Weekly == nil {
+			out.Weekly = &v1alpha1.ScheduledReportScheduleWeekly{}
+		}
+	case v1alpha1.ScheduledReportPeriodMonthly:
+		out.Monthly = in.Monthly
+		if out.Monthly == nil {
+			out.Monthly = &v1alpha1.ScheduledReportScheduleMonthly{}
+		}
+	default:
+		// the period is invalid, so keep every schedule to avoid losing
+		// any of them.
+		out.Cron, out.Hourly, out.Daily, out.Weekly, out.Monthly = in.Cron, in.Hourly, in.Daily, in.Weekly, in.Monthly
+	}
+	return out
+}
+
+// scheduleToV1alpha1 returns a v1alpha1 schedule whose period is the first
+// of the schedules which is set.
+func scheduleToV1alpha1(in ScheduledReportSchedule) v1alpha1.ScheduledReportSchedule {
+	out := v1alpha1.ScheduledReportSchedule{
+		Timezone: in.Timezone,
+		Cron:     in.Cron,
+		Hourly:   in.Hourly,
+		Daily:    in.Daily,
+		Weekly:   in.Weekly,
+		Monthly:  in.Monthly,
+	}
+	switch {
+	case in.Cron != nil:
+		out.Period = v1alpha1.ScheduledReportPeriodCron
+	case in.Hourly != nil:
+		out.Period = v1alpha1.ScheduledReportPeriodHourly
+	case in.Daily != nil:
+		out.Period = v1alpha1.ScheduledReportPeriodDaily
+	case in.Weekly != nil:
+		out.Period = v1alpha1.ScheduledReportPeriodWeekly
+	case in.Monthly != nil:
+		out.Period = v1alpha1.ScheduledReportPeriodMonthly
+	}
+	return out
+}
+
+// inputsFromV1alpha1 returns the v1alpha2 inputs of an object, restoring
+// the types recorded in it's InputTypesAnnotation, which is removed from
+// objectMeta.
+func inputsFromV1alpha1(objectMeta *meta.ObjectMeta, in v1alpha1.ReportGenerationQueryInputValues) []ReportInput {
+	var types map[string]ReportInputValueType
+	if annotation, ok := objectMeta.Annotations[InputTypesAnnotation]; ok {
+		// an invalid annotation only loses the types of the inputs
+		_ = json.Unmarshal([]byte(annotation), &types)
+		delete(objectMeta.Annotations, InputTypesAnnotation)
+		if len(objectMeta.Annotations) == 0 {
+			objectMeta.Annotations = nil
+		}
+	}
+	if in == nil {
+		return nil
+	}
+	out := make([]ReportInput, len(in))
+	for i, input := range in {
+		value, err := ParseInputValue(types[input.Name], input.Value)
+		if err != nil {
+			// the input was changed using v1alpha1, or has no recorded
+			// type.
+			value = NewStringInputValue(input.Value)
+		}
+		out[i] = ReportInput{Name: input.Name, Value: value}
+	}
+	return out
+}
+
+// inputsToV1alpha1 returns the v1alpha1 inputs of an object, recording the
+// types of the inputs which aren't strings in it's InputTypesAnnotation.
+func inputsToV1alpha1(objectMeta *meta.ObjectMeta, in []ReportInput) v1alpha1.ReportGenerationQueryInputValues {
+	delete(objectMeta.Annotations, InputTypesAnnotation)
+	if in == nil {
+		return nil
+	}
+	out := make(v1alpha1.ReportGenerationQueryInputValues, len(in))
+	types := make(map[string]ReportInputValueType)
+	for i, input := range in {
+		out[i] = v1alpha1.ReportGenerationQueryInputValue{Name: input.Name, Value: input.Value.Value}
+		if input.Value.Type != ReportInputValueString && input.Value.Type != "" {
+			types[input.Name] = input.Value.Type
+		}
+	}
+	if len(types) != 0 {
+		annotation, _ := json.Marshal(types)
+		if objectMeta.Annotations == nil {
+			objectMeta.Annotations = make(map[string]string)
+		}
+		objectMeta.Annotations[InputTypesAnnotation] = string(annotation)
+	}
+	return out
+}
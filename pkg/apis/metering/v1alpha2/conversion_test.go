@@ -0,0 +1,207 @@
+package v1alpha2
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestReportConversionRoundTrip(t *testing.T) {
+	start := meta.NewTime(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC))
+	end := meta.NewTime(time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC))
+	newReport := func(phase v1alpha1.ReportPhase, reason, output string) *v1alpha1.Report {
+		return &v1alpha1.Report{
+			ObjectMeta: meta.ObjectMeta{Name: "report", Namespace: "metering", Annotations: map[string]string{"a": "b"}},
+			Spec: v1alpha1.ReportSpec{
+				ReportingStart:      &start,
+				ReportingEnd:        &end,
+				GenerationQueryName: "namespace-cpu-request",
+				Inputs:              v1alpha1.ReportGenerationQueryInputValues{{Name: "Limit", Value: "10"}},
+				GracePeriod:         &meta.Duration{Duration: time.Hour},
+			},
+			Status: v1alpha1.ReportStatus{Phase: phase, Reason: reason, Output: output, TableName: "report_metering_report"},
+		}
+	}
+	tests := map[string]struct {
+		report    *v1alpha1.Report
+		condition *Condition
+	}{
+		"no phase": {
+			report: newReport("", "", ""),
+		},
+		"no phase with output": {
+			report:    newReport("", "", "query failed"),
+			condition: &Condition{Type: ConditionRunning, Status: v1.ConditionUnknown, Message: "query failed"},
+		},
+		"waiting": {
+			report:    newReport(v1alpha1.ReportPhaseWaiting, "", ""),
+			condition: &Condition{Type: ConditionRunning, Status: v1.ConditionFalse},
+		},
+		"started": {
+			report:    newReport(v1alpha1.ReportPhaseStarted, "", ""),
+			condition: &Condition{Type: ConditionRunning, Status: v1.ConditionTrue},
+		},
+		"finished": {
+			report:    newReport(v1alpha1.ReportPhaseFinished, "", ""),
+			condition: &Condition{Type: ConditionFinished, Status: v1.ConditionTrue},
+		},
+		"error": {
+			report:    newReport(v1alpha1.ReportPhaseError, "QueryTimeout", "query timed out"),
+			condition: &Condition{Type: ConditionFailed, Status: v1.ConditionTrue, Reason: "QueryTimeout", Message: "query timed out"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var converted Report
+			require.NoError(t, Convert_v1alpha1_Report_To_v1alpha2_Report(tt.report, &converted, nil))
+			if tt.condition == nil {
+				assert.Empty(t, converted.Status.Conditions)
+			} else {
+				assert.Equal(t, []Condition{*tt.condition}, converted.Status.Conditions)
+			}
+			assert.Equal(t, []ReportInput{{Name: "Limit", Value: NewStringInputValue("10")}}, converted.Spec.Inputs)
+
+			var roundTripped v1alpha1.Report
+			require.NoError(t, Convert_v1alpha2_Report_To_v1alpha1_Report(&converted, &roundTripped, nil))
+			assert.Equal(t, tt.report, &roundTripped)
+		})
+	}
+}
+
+func TestReportConversionKeepsInputTypes(t *testing.T) {
+	report := &Report{
+		ObjectMeta: meta.ObjectMeta{Name: "report", Namespace: "metering"},
+		Spec: ReportSpec{
+			GenerationQueryName: "namespace-cpu-request",
+			Inputs: []ReportInput{
+				{Name: "Namespace", Value: NewStringInputValue("default")},
+				{Name: "Limit", Value: ReportInputValue{Type: ReportInputValueNumber, Value: "10"}},
+				{Name: "Verbose", Value: ReportInputValue{Type: ReportInputValueBoolean, Value: "true"}},
+			},
+		},
+	}
+
+	var stored v1alpha1.Report
+	require.NoError(t, Convert_v1alpha2_Report_To_v1alpha1_Report(report, &stored, nil))
+	assert.Equal(t, v1alpha1.ReportGenerationQueryInputValues{
+		{Name: "Namespace", Value: "default"},
+		{Name: "Limit", Value: "10"},
+		{Name: "Verbose", Value: "true"},
+	}, stored.Spec.Inputs)
+	assert.JSONEq(t, `{"Limit": "number", "Verbose": "boolean"}`, stored.Annotations[InputTypesAnnotation])
+
+	var roundTripped Report
+	require.NoError(t, Convert_v1alpha1_Report_To_v1alpha2_Report(&stored, &roundTripped, nil))
+	assert.Equal(t, report, &roundTripped)
+
+	// changing an input to a value which doesn't match it's recorded type
+	// using v1alpha1 makes it a string
+	stored.Spec.Inputs[1].Value = "ten"
+	require.NoError(t, Convert_v1alpha1_Report_To_v1alpha2_Report(&stored, &roundTripped, nil))
+	assert.Equal(t, NewStringInputValue("ten"), roundTripped.Spec.Inputs[1].Value)
+}
+
+func TestScheduledReportConversion(t *testing.T) {
+	dayOfWeek := "friday"
+	report := &v1alpha1.ScheduledReport{
+		ObjectMeta: meta.ObjectMeta{Name: "weekly", Namespace: "metering"},
+		Spec: v1alpha1.ScheduledReportSpec{
+			GenerationQueryName: "namespace-cpu-request",
+			Schedule: v1alpha1.ScheduledReportSchedule{
+				Period:   v1alpha1.ScheduledReportPeriodWeekly,
+				Timezone: "America/New_York",
+				Weekly:   &v1alpha1.ScheduledReportScheduleWeekly{DayOfWeek: &dayOfWeek, Hour: 1},
+			},
+		},
+		Status: v1alpha1.ScheduledReportStatus{
+			Conditions: []v1alpha1.ScheduledReportCondition{
+				{Type: v1alpha1.ScheduledReportRunning, Status: v1.ConditionFalse, Reason: "ReportPeriodNotFinished"},
+				{Type: v1alpha1.ScheduledReportFailure, Status: v1.ConditionTrue, Reason: "QueryTimeout"},
+			},
+		},
+	}
+
+	var converted ScheduledReport
+	require.NoError(t, Convert_v1alpha1_ScheduledReport_To_v1alpha2_ScheduledReport(report, &converted, nil))
+	assert.Equal(t, ScheduledReportSchedule{
+		Timezone: "America/New_York",
+		Weekly:   &v1alpha1.ScheduledReportScheduleWeekly{DayOfWeek: &dayOfWeek, Hour: 1},
+	}, converted.Spec.Schedule)
+	assert.Equal(t, []Condition{
+		{Type: ConditionRunning, Status: v1.ConditionFalse, Reason: "ReportPeriodNotFinished"},
+		{Type: ConditionFailed, Status: v1.ConditionTrue, Reason: "QueryTimeout"},
+	}, converted.Status.Conditions)
+
+	var roundTripped v1alpha1.ScheduledReport
+	require.NoError(t, Convert_v1alpha2_ScheduledReport_To_v1alpha1_ScheduledReport(&converted, &roundTripped, nil))
+	assert.Equal(t, report, &roundTripped)
+}
+
+func TestScheduleFromV1alpha1(t *testing.T) {
+	tests := map[string]struct {
+		schedule v1alpha1.ScheduledReportSchedule
+		expected ScheduledReportSchedule
+	}{
+		"unset daily schedule": {
+			schedule: v1alpha1.ScheduledReportSchedule{Period: v1alpha1.ScheduledReportPeriodDaily},
+			expected: ScheduledReportSchedule{Daily: &v1alpha1.ScheduledReportScheduleDaily{}},
+		},
+		"schedules other than the period are dropped": {
+			schedule: v1alpha1.ScheduledReportSchedule{
+				Period: v1alpha1.ScheduledReportPeriodHourly,
+				Hourly: &v1alpha1.ScheduledReportScheduleHourly{Minute: 30},
+				Cron:   &v1alpha1.ScheduledReportScheduleCron{Expression: "0 * * * *"},
+			},
+			expected: ScheduledReportSchedule{Hourly: &v1alpha1.ScheduledReportScheduleHourly{Minute: 30}},
+		},
+		"invalid period": {
+			schedule: v1alpha1.ScheduledReportSchedule{
+				Period: "yearly",
+				Cron:   &v1alpha1.ScheduledReportScheduleCron{Expression: "0 * * * *"},
+			},
+			expected: ScheduledReportSchedule{Cron: &v1alpha1.ScheduledReportScheduleCron{Expression: "0 * * * *"}},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, scheduleFromV1alpha1(tt.schedule))
+		})
+	}
+}
+
+func TestReportInputValueJSON(t *testing.T) {
+	tests := map[string]struct {
+		json     string
+		expected ReportInputValue
+		invalid  bool
+	}{
+		"string":  {json: `"default"`, expected: NewStringInputValue("default")},
+		"integer": {json: `10`, expected: ReportInputValue{Type: ReportInputValueNumber, Value: "10"}},
+		"float":   {json: `0.5`, expected: ReportInputValue{Type: ReportInputValueNumber, Value: "0.5"}},
+		"boolean": {json: `false`, expected: ReportInputValue{Type: ReportInputValueBoolean, Value: "false"}},
+		"null":    {json: `null`, invalid: true},
+		"object":  {json: `{"value": 10}`, invalid: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var value ReportInputValue
+			err := json.Unmarshal([]byte(tt.json), &value)
+			if tt.invalid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+			encoded, err := json.Marshal(value)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.json, string(encoded))
+		})
+	}
+}
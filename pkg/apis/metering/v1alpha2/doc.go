@@ -0,0 +1,8 @@
+// +k8s:deepcopy-gen=package,register
+
+// Package v1alpha2 is the v1alpha2 version of the API, containing the
+// Report and ScheduledReport kinds. v1alpha1 remains the storage version,
+// and objects are converted between the versions by reporting-operator's
+// conversion webhook. Types which are unchanged from v1alpha1 are reused.
+// +groupName=metering.openshift.io
+package v1alpha2
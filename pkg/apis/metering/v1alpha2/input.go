@@ -0,0 +1,87 @@
+package v1alpha2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// ReportInput sets the value of one of the inputs of a
+// ReportGenerationQuery.
+type ReportInput struct {
+	Name  string           `json:"name"`
+	Value ReportInputValue `json:"value"`
+}
+
+// ReportInputValueType is the JSON type of a ReportInputValue.
+type ReportInputValueType string
+
+const (
+	ReportInputValueString  ReportInputValueType = "string"
+	ReportInputValueNumber  ReportInputValueType = "number"
+	ReportInputValueBoolean ReportInputValueType = "boolean"
+)
+
+// ReportInputValue is the value of an input, which can be a string, number
+// or boolean. Unlike in v1alpha1, where every value is a string, values
+// don't need to be quoted.
+type ReportInputValue struct {
+	Type ReportInputValueType
+	// Value is the string, or the JSON encoding of the number or boolean.
+	Value string
+}
+
+// NewStringInputValue returns a ReportInputValue containing the string s.
+func NewStringInputValue(s string) ReportInputValue {
+	return ReportInputValue{Type: ReportInputValueString, Value: s}
+}
+
+// ParseInputValue returns the ReportInputValue of type t encoded by s,
+// returning an error if s isn't a valid value of type t.
+func ParseInputValue(t ReportInputValueType, s string) (ReportInputValue, error) {
+	switch t {
+	case ReportInputValueString:
+	case ReportInputValueNumber:
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return ReportInputValue{}, fmt.Errorf("invalid number %q", s)
+		}
+	case ReportInputValueBoolean:
+		if s != "true" && s != "false" {
+			return ReportInputValue{}, fmt.Errorf("invalid boolean %q", s)
+		}
+	default:
+		return ReportInputValue{}, fmt.Errorf("unknown input value type %q", t)
+	}
+	return ReportInputValue{Type: t, Value: s}, nil
+}
+
+func (v ReportInputValue) MarshalJSON() ([]byte, error) {
+	if v.Type == ReportInputValueString || v.Type == "" {
+		return json.Marshal(v.Value)
+	}
+	if _, err := ParseInputValue(v.Type, v.Value); err != nil {
+		return nil, err
+	}
+	return []byte(v.Value), nil
+}
+
+func (v *ReportInputValue) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	switch value := value.(type) {
+	case string:
+		*v = NewStringInputValue(value)
+	case json.Number:
+		*v = ReportInputValue{Type: ReportInputValueNumber, Value: value.String()}
+	case bool:
+		*v = ReportInputValue{Type: ReportInputValueBoolean, Value: strconv.FormatBool(value)}
+	default:
+		return fmt.Errorf("input values must be a string, number or boolean, got %s", data)
+	}
+	return nil
+}
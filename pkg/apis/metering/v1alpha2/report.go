@@ -0,0 +1,116 @@
+package v1alpha2
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ReportList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+	Items         []*Report `json:"items"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Report struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ReportSpec   `json:"spec"`
+	Status ReportStatus `json:"status"`
+}
+
+type ReportSpec struct {
+	// ReportingStart is the beginning period of time that the report will
+	// be based on.
+	ReportingStart *meta.Time `json:"reportingStart,omitempty"`
+
+	// ReportingEnd is the end period of time that the report will be based
+	// on.
+	ReportingEnd *meta.Time `json:"reportingEnd,omitempty"`
+
+	// Inputs are the inputs to the ReportGenerationQuery.
+	Inputs []ReportInput `json:"inputs,omitempty"`
+
+	// GenerationQueryName is the name of the ReportGenerationQuery that this
+	// report should run
+	GenerationQueryName string `json:"generationQuery"`
+
+	// RunImmediately will run the report immediately, ignoring ReportingEnd and
+	// GracePeriod.
+	RunImmediately bool `json:"runImmediately,omitempty"`
+
+	// GracePeriod controls how long after `ReportingEnd` to wait until running
+	// the report
+	GracePeriod *meta.Duration `json:"gracePeriod,omitempty"`
+
+	// Output is the storage location where results are sent.
+	Output *v1alpha1.StorageLocationRef `json:"output,omitempty"`
+
+	// PricingPolicy is the name of the PricingPolicy in the report's
+	// namespace whose rates are available to it's query.
+	PricingPolicy string `json:"pricingPolicy,omitempty"`
+
+	// ReportingEndInputName allows overriding the default expected input name that maps to the ReportPeriodEnd
+	ReportingEndInputName string `json:"reportingEndInputName,omitempty"`
+
+	// ChunkSize splits the reporting period into chunks of this duration.
+	ChunkSize *meta.Duration `json:"chunkSize,omitempty"`
+
+	// MaxRuntime is how long each query generating the report can run
+	// before it's cancelled, failing the report.
+	MaxRuntime *meta.Duration `json:"maxRuntime,omitempty"`
+
+	// MaxResultRows is the maximum number of rows the report's query can
+	// return, or each chunk's query if ChunkSize is set.
+	MaxResultRows *int64 `json:"maxResultRows,omitempty"`
+
+	// RetryPolicy controls how many times, and how often, generating the
+	// report is retried.
+	RetryPolicy *v1alpha1.ReportRetryPolicy `json:"retryPolicy,omitempty"`
+
+	// DryRun renders the report's query and records the plan Presto would
+	// use to execute it in status.dryRun, instead of generating the report.
+	DryRun *v1alpha1.ReportDryRun `json:"dryRun,omitempty"`
+}
+
+type ReportStatus struct {
+	// Conditions replace the phase, reason and output of v1alpha1. The
+	// Running condition is False while the report is waiting to be
+	// generated, and True while it's being generated. The Finished and
+	// Failed conditions are True once the report has been generated, or
+	// has failed.
+	Conditions []Condition `json:"conditions,omitempty"`
+	TableName  string      `json:"tableName"`
+	// RowCount is the number of rows in the report's results, recorded when
+	// the report finishes.
+	RowCount *int64 `json:"rowCount,omitempty"`
+
+	// GenerationQueryVersions records the version of the
+	// ReportGenerationQuery and each of it's ReportGenerationQuery
+	// dependencies used to generate the report.
+	GenerationQueryVersions []v1alpha1.ReportGenerationQueryVersion `json:"generationQueryVersions,omitempty"`
+
+	// Chunks tracks the progress of a report with spec.chunkSize set.
+	Chunks *v1alpha1.ReportChunksStatus `json:"chunks,omitempty"`
+	// Progress is periodically updated while the report is being
+	// generated.
+	Progress *v1alpha1.ReportProgress `json:"progress,omitempty"`
+
+	// Attempts records the most recent attempts to generate the report.
+	Attempts []v1alpha1.ReportAttempt `json:"attempts,omitempty"`
+	// FailedAttempts is the number of attempts to generate the report
+	// which failed.
+	FailedAttempts int `json:"failedAttempts,omitempty"`
+	// NextRetryTime is when generating the report will next be attempted,
+	// after a failed attempt is retried.
+	NextRetryTime *meta.Time `json:"nextRetryTime,omitempty"`
+
+	// DryRun contains the rendered query and it's plan when spec.dryRun is
+	// set.
+	DryRun *v1alpha1.ReportDryRunStatus `json:"dryRun,omitempty"`
+}
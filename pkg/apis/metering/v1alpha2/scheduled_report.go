@@ -0,0 +1,103 @@
+package v1alpha2
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ScheduledReportList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+	Items         []*ScheduledReport `json:"items"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ScheduledReport struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScheduledReportSpec   `json:"spec"`
+	Status ScheduledReportStatus `json:"status"`
+}
+
+type ScheduledReportSpec struct {
+	// GenerationQueryName specifies the ReportGenerationQuery to execute when
+	// the report runs.
+	GenerationQueryName string `json:"generationQuery"`
+
+	// Schedule configures when the report runs.
+	Schedule ScheduledReportSchedule `json:"schedule"`
+
+	// ReportingStart specifies the time this ScheduledReport should start from
+	// instead of the current time.
+	ReportingStart *meta.Time `json:"reportingStart,omitempty"`
+
+	// ReportingEnd specifies the time this ScheduledReport should stop
+	// running.
+	ReportingEnd *meta.Time `json:"reportingEnd,omitempty"`
+
+	// GracePeriod controls how long after each period to wait until running
+	// the report
+	GracePeriod *meta.Duration `json:"gracePeriod,omitempty"`
+
+	// OverwriteExistingData controls whether or not to delete any existing
+	// data in the report table before the scheduled report runs.
+	OverwriteExistingData bool `json:"overwriteExistingData,omitempty"`
+
+	// Inputs are the inputs to the ReportGenerationQuery.
+	Inputs []ReportInput `json:"inputs,omitempty"`
+
+	// Output is the storage location where results are sent.
+	Output *v1alpha1.StorageLocationRef `json:"output,omitempty"`
+
+	// PricingPolicy is the name of the PricingPolicy in the report's
+	// namespace whose rates are available to it's query.
+	PricingPolicy string `json:"pricingPolicy,omitempty"`
+
+	// MaxRuntime is how long each run of the report's query can take before
+	// it's cancelled, failing the run.
+	MaxRuntime *meta.Duration `json:"maxRuntime,omitempty"`
+
+	// MaxResultRows is the maximum number of rows each run of the report's
+	// query can return.
+	MaxResultRows *int64 `json:"maxResultRows,omitempty"`
+
+	// Metrics exports the results of the most recent run as Prometheus
+	// gauges on reporting-operator's /metrics endpoint.
+	Metrics *v1alpha1.ScheduledReportMetrics `json:"metrics,omitempty"`
+}
+
+// ScheduledReportSchedule configures when a ScheduledReport runs. Exactly
+// one of cron, hourly, daily, weekly or monthly must be set, which replaces
+// the period field of v1alpha1.
+type ScheduledReportSchedule struct {
+	// Timezone is the name of the IANA time zone the schedule is
+	// evaluated in, such as America/New_York. Defaults to UTC.
+	Timezone string `json:"timezone,omitempty"`
+
+	Cron    *v1alpha1.ScheduledReportScheduleCron    `json:"cron,omitempty"`
+	Hourly  *v1alpha1.ScheduledReportScheduleHourly  `json:"hourly,omitempty"`
+	Daily   *v1alpha1.ScheduledReportScheduleDaily   `json:"daily,omitempty"`
+	Weekly  *v1alpha1.ScheduledReportScheduleWeekly  `json:"weekly,omitempty"`
+	Monthly *v1alpha1.ScheduledReportScheduleMonthly `json:"monthly,omitempty"`
+}
+
+type ScheduledReportStatus struct {
+	// Conditions contains the Running condition, which is True while the
+	// report is running, and the Failed condition, which replaces the
+	// Failure condition of v1alpha1.
+	Conditions     []Condition `json:"conditions,omitempty"`
+	LastReportTime *meta.Time  `json:"lastReportTime,omitempty"`
+	TableName      string      `json:"tableName"`
+	// RowCount is the number of rows in the report's results, recorded
+	// after each run.
+	RowCount *int64 `json:"rowCount,omitempty"`
+
+	// RunHistory contains the most recent successful runs of the
+	// ScheduledReport, ordered from newest to oldest.
+	RunHistory []v1alpha1.ScheduledReportRun `json:"runHistory,omitempty"`
+}
@@ -0,0 +1,564 @@
+// +build !ignore_autogenerated
+
+// Code generated by deepcopy-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	v1alpha1 "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Condition) DeepCopyInto(out *Condition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
+func (in *Condition) DeepCopy() *Condition {
+	if in == nil {
+		return nil
+	}
+	out := new(Condition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Report) DeepCopyInto(out *Report) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Report.
+func (in *Report) DeepCopy() *Report {
+	if in == nil {
+		return nil
+	}
+	out := new(Report)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Report) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportInput) DeepCopyInto(out *ReportInput) {
+	*out = *in
+	out.Value = in.Value
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportInput.
+func (in *ReportInput) DeepCopy() *ReportInput {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportInput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportInputValue) DeepCopyInto(out *ReportInputValue) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportInputValue.
+func (in *ReportInputValue) DeepCopy() *ReportInputValue {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportInputValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportList) DeepCopyInto(out *ReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]*Report, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				(*out)[i] = new(Report)
+				(*in)[i].DeepCopyInto((*out)[i])
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportList.
+func (in *ReportList) DeepCopy() *ReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportSpec) DeepCopyInto(out *ReportSpec) {
+	*out = *in
+	if in.ReportingStart != nil {
+		in, out := &in.ReportingStart, &out.ReportingStart
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.ReportingEnd != nil {
+		in, out := &in.ReportingEnd, &out.ReportingEnd
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]ReportInput, len(*in))
+		copy(*out, *in)
+	}
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	if in.Output != nil {
+		in, out := &in.Output, &out.Output
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1alpha1.StorageLocationRef)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.ChunkSize != nil {
+		in, out := &in.ChunkSize, &out.ChunkSize
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	if in.MaxRuntime != nil {
+		in, out := &in.MaxRuntime, &out.MaxRuntime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	if in.MaxResultRows != nil {
+		in, out := &in.MaxResultRows, &out.MaxResultRows
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1alpha1.ReportRetryPolicy)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1alpha1.ReportDryRun)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportSpec.
+func (in *ReportSpec) DeepCopy() *ReportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportStatus) DeepCopyInto(out *ReportStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RowCount != nil {
+		in, out := &in.RowCount, &out.RowCount
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	if in.GenerationQueryVersions != nil {
+		in, out := &in.GenerationQueryVersions, &out.GenerationQueryVersions
+		*out = make([]v1alpha1.ReportGenerationQueryVersion, len(*in))
+		copy(*out, *in)
+	}
+	if in.Chunks != nil {
+		in, out := &in.Chunks, &out.Chunks
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1alpha1.ReportChunksStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1alpha1.ReportProgress)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Attempts != nil {
+		in, out := &in.Attempts, &out.Attempts
+		*out = make([]v1alpha1.ReportAttempt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1alpha1.ReportDryRunStatus)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportStatus.
+func (in *ReportStatus) DeepCopy() *ReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledReport) DeepCopyInto(out *ScheduledReport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledReport.
+func (in *ScheduledReport) DeepCopy() *ScheduledReport {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScheduledReport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledReportList) DeepCopyInto(out *ScheduledReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]*ScheduledReport, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				(*out)[i] = new(ScheduledReport)
+				(*in)[i].DeepCopyInto((*out)[i])
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledReportList.
+func (in *ScheduledReportList) DeepCopy() *ScheduledReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScheduledReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledReportSchedule) DeepCopyInto(out *ScheduledReportSchedule) {
+	*out = *in
+	if in.Cron != nil {
+		in, out := &in.Cron, &out.Cron
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1alpha1.ScheduledReportScheduleCron)
+			**out = **in
+		}
+	}
+	if in.Hourly != nil {
+		in, out := &in.Hourly, &out.Hourly
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1alpha1.ScheduledReportScheduleHourly)
+			**out = **in
+		}
+	}
+	if in.Daily != nil {
+		in, out := &in.Daily, &out.Daily
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1alpha1.ScheduledReportScheduleDaily)
+			**out = **in
+		}
+	}
+	if in.Weekly != nil {
+		in, out := &in.Weekly, &out.Weekly
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1alpha1.ScheduledReportScheduleWeekly)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Monthly != nil {
+		in, out := &in.Monthly, &out.Monthly
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1alpha1.ScheduledReportScheduleMonthly)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledReportSchedule.
+func (in *ScheduledReportSchedule) DeepCopy() *ScheduledReportSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledReportSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledReportSpec) DeepCopyInto(out *ScheduledReportSpec) {
+	*out = *in
+	in.Schedule.DeepCopyInto(&out.Schedule)
+	if in.ReportingStart != nil {
+		in, out := &in.ReportingStart, &out.ReportingStart
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.ReportingEnd != nil {
+		in, out := &in.ReportingEnd, &out.ReportingEnd
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]ReportInput, len(*in))
+		copy(*out, *in)
+	}
+	if in.Output != nil {
+		in, out := &in.Output, &out.Output
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1alpha1.StorageLocationRef)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.MaxRuntime != nil {
+		in, out := &in.MaxRuntime, &out.MaxRuntime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	if in.MaxResultRows != nil {
+		in, out := &in.MaxResultRows, &out.MaxResultRows
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1alpha1.ScheduledReportMetrics)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledReportSpec.
+func (in *ScheduledReportSpec) DeepCopy() *ScheduledReportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledReportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledReportStatus) DeepCopyInto(out *ScheduledReportStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastReportTime != nil {
+		in, out := &in.LastReportTime, &out.LastReportTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.RowCount != nil {
+		in, out := &in.RowCount, &out.RowCount
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	if in.RunHistory != nil {
+		in, out := &in.RunHistory, &out.RunHistory
+		*out = make([]v1alpha1.ScheduledReportRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledReportStatus.
+func (in *ScheduledReportStatus) DeepCopy() *ScheduledReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
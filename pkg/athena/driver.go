@@ -0,0 +1,333 @@
+package athena
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/athena/athenaiface"
+)
+
+const (
+	// DriverName is the name the Athena driver is registered with
+	// database/sql as.
+	DriverName = "athena"
+
+	timestampFormat = "2006-01-02 15:04:05.999"
+	dateFormat      = "2006-01-02"
+
+	minPollInterval = 100 * time.Millisecond
+	maxPollInterval = 5 * time.Second
+)
+
+// ddlStatementRegex matches statements which Athena doesn't return a header
+// row for in their results.
+var ddlStatementRegex = regexp.MustCompile(`(?i)^\s*(CREATE|ALTER|DROP|MSCK|SHOW|DESCRIBE)\s`)
+
+func init() {
+	sql.Register(DriverName, &Driver{})
+}
+
+// Config configures the connection to Athena.
+type Config struct {
+	// Region is the AWS region Athena queries are run in.
+	Region string
+	// Database is the Glue catalog database used for unqualified table names.
+	Database string
+	// OutputLocation is the S3 URL query results are written to, for example
+	// s3://bucket/athena-results/.
+	OutputLocation string
+}
+
+// FormatDSN returns a DSN string which can be passed to sql.Open to connect
+// to Athena using the configuration.
+func (c Config) FormatDSN() string {
+	query := url.Values{
+		"region":          []string{c.Region},
+		"database":        []string{c.Database},
+		"output_location": []string{c.OutputLocation},
+	}
+	dsn := url.URL{
+		Scheme:   DriverName,
+		RawQuery: query.Encode(),
+	}
+	return dsn.String()
+}
+
+func parseDSN(dsn string) (Config, error) {
+	dsnURL, err := url.Parse(dsn)
+	if err != nil {
+		return Config{}, fmt.Errorf("athena: malformed dsn: %v", err)
+	}
+	query := dsnURL.Query()
+	cfg := Config{
+		Region:         query.Get("region"),
+		Database:       query.Get("database"),
+		OutputLocation: query.Get("output_location"),
+	}
+	if cfg.Database == "" {
+		return Config{}, errors.New("athena: dsn must set database")
+	}
+	if !strings.HasPrefix(cfg.OutputLocation, "s3://") {
+		return Config{}, fmt.Errorf("athena: dsn output_location must be an s3:// URL, got %q", cfg.OutputLocation)
+	}
+	return cfg, nil
+}
+
+// Driver implements driver.Driver, running queries using Athena.
+type Driver struct{}
+
+func (d *Driver) Open(dsn string) (driver.Conn, error) {
+	cfg, err := parseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	awsSession, err := session.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("athena: unable to create AWS session: %v", err)
+	}
+	awsConfig := aws.NewConfig()
+	if cfg.Region != "" {
+		awsConfig = awsConfig.WithRegion(cfg.Region)
+	}
+	return &conn{
+		athenaAPI: athena.New(awsSession, awsConfig),
+		cfg:       cfg,
+	}, nil
+}
+
+type conn struct {
+	athenaAPI athenaiface.AthenaAPI
+	cfg       Config
+}
+
+var (
+	_ driver.Conn           = &conn{}
+	_ driver.QueryerContext = &conn{}
+)
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("athena: prepared statements are not supported")
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, errors.New("athena: transactions are not supported")
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+// QueryContext runs the query using Athena, waiting for it to finish. If ctx
+// is cancelled before the query finishes, the query is stopped.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) != 0 {
+		return nil, errors.New("athena: query arguments are not supported")
+	}
+	startOutput, err := c.athenaAPI.StartQueryExecutionWithContext(ctx, &athena.StartQueryExecutionInput{
+		QueryString: aws.String(query),
+		QueryExecutionContext: &athena.QueryExecutionContext{
+			Database: aws.String(c.cfg.Database),
+		},
+		ResultConfiguration: &athena.ResultConfiguration{
+			OutputLocation: aws.String(c.cfg.OutputLocation),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("athena: unable to start query: %v", err)
+	}
+	queryExecutionID := aws.StringValue(startOutput.QueryExecutionId)
+
+	if err := c.waitForQuery(ctx, queryExecutionID); err != nil {
+		return nil, err
+	}
+	return &rows{
+		ctx:              ctx,
+		athenaAPI:        c.athenaAPI,
+		queryExecutionID: queryExecutionID,
+		skipHeaderRow:    !ddlStatementRegex.MatchString(query),
+	}, nil
+}
+
+// waitForQuery polls the state of the query until it's finished.
+func (c *conn) waitForQuery(ctx context.Context, queryExecutionID string) error {
+	pollInterval := minPollInterval
+	for {
+		output, err := c.athenaAPI.GetQueryExecutionWithContext(ctx, &athena.GetQueryExecutionInput{
+			QueryExecutionId: aws.String(queryExecutionID),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				c.stopQuery(queryExecutionID)
+				return ctx.Err()
+			}
+			return fmt.Errorf("athena: unable to get state of query %s: %v", queryExecutionID, err)
+		}
+		status := output.QueryExecution.Status
+		switch aws.StringValue(status.State) {
+		case athena.QueryExecutionStateSucceeded:
+			return nil
+		case athena.QueryExecutionStateFailed:
+			return fmt.Errorf("athena: query %s failed: %s", queryExecutionID, aws.StringValue(status.StateChangeReason))
+		case athena.QueryExecutionStateCancelled:
+			return fmt.Errorf("athena: query %s was cancelled", queryExecutionID)
+		}
+
+		select {
+		case <-ctx.Done():
+			c.stopQuery(queryExecutionID)
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+		pollInterval *= 2
+		if pollInterval > maxPollInterval {
+			pollInterval = maxPollInterval
+		}
+	}
+}
+
+// stopQuery stops the query. It's used when the context the query was started
+// with is done, so it uses a new context.
+func (c *conn) stopQuery(queryExecutionID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	c.athenaAPI.StopQueryExecutionWithContext(ctx, &athena.StopQueryExecutionInput{
+		QueryExecutionId: aws.String(queryExecutionID),
+	})
+}
+
+// rows implements driver.Rows, fetching the results of a finished query a
+// page at a time.
+type rows struct {
+	ctx              context.Context
+	athenaAPI        athenaiface.AthenaAPI
+	queryExecutionID string
+	// skipHeaderRow is true if the first row of the results contains the
+	// column names, which Athena does for the results of SELECT queries.
+	skipHeaderRow bool
+
+	columns    []*athena.ColumnInfo
+	page       []*athena.Row
+	nextToken  *string
+	fetched    bool
+	fetchErr   error
+	pageOffset int
+}
+
+var (
+	_ driver.Rows                           = &rows{}
+	_ driver.RowsColumnTypeDatabaseTypeName = &rows{}
+)
+
+func (r *rows) Columns() []string {
+	if err := r.fetchFirstPage(); err != nil {
+		return nil
+	}
+	names := make([]string, len(r.columns))
+	for i, col := range r.columns {
+		names[i] = aws.StringValue(col.Name)
+	}
+	return names
+}
+
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return aws.StringValue(r.columns[index].Type)
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if err := r.fetchFirstPage(); err != nil {
+		return err
+	}
+	for r.pageOffset >= len(r.page) {
+		if r.nextToken == nil {
+			return io.EOF
+		}
+		if err := r.fetchPage(); err != nil {
+			return err
+		}
+	}
+	row := r.page[r.pageOffset]
+	r.pageOffset++
+	for i := range dest {
+		var value *string
+		if i < len(row.Data) {
+			value = row.Data[i].VarCharValue
+		}
+		v, err := convertValue(aws.StringValue(r.columns[i].Type), value)
+		if err != nil {
+			return fmt.Errorf("athena: unable to convert column %s: %v", aws.StringValue(r.columns[i].Name), err)
+		}
+		dest[i] = v
+	}
+	return nil
+}
+
+func (r *rows) fetchFirstPage() error {
+	if r.fetched {
+		return r.fetchErr
+	}
+	r.fetched = true
+	if err := r.fetchPage(); err != nil {
+		r.fetchErr = err
+		return err
+	}
+	if r.skipHeaderRow && len(r.page) != 0 {
+		r.pageOffset++
+	}
+	return nil
+}
+
+func (r *rows) fetchPage() error {
+	output, err := r.athenaAPI.GetQueryResultsWithContext(r.ctx, &athena.GetQueryResultsInput{
+		QueryExecutionId: aws.String(r.queryExecutionID),
+		NextToken:        r.nextToken,
+	})
+	if err != nil {
+		return fmt.Errorf("athena: unable to get results of query %s: %v", r.queryExecutionID, err)
+	}
+	if output.ResultSet.ResultSetMetadata != nil {
+		r.columns = output.ResultSet.ResultSetMetadata.ColumnInfo
+	}
+	r.page = output.ResultSet.Rows
+	r.pageOffset = 0
+	r.nextToken = output.NextToken
+	return nil
+}
+
+// convertValue converts the string representation of value Athena returns
+// into the Go type used by the Presto driver for the same type.
+func convertValue(typeName string, value *string) (driver.Value, error) {
+	if value == nil {
+		return nil, nil
+	}
+	v := *value
+	switch typeName {
+	case "boolean":
+		return strconv.ParseBool(v)
+	case "tinyint", "smallint", "integer", "bigint":
+		return strconv.ParseInt(v, 10, 64)
+	case "float", "real", "double":
+		return strconv.ParseFloat(v, 64)
+	case "timestamp":
+		return time.Parse(timestampFormat, v)
+	case "date":
+		return time.Parse(dateFormat, v)
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,141 @@
+package athena
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/athena/athenaiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAthena returns the pages of results for every query, which succeed
+// once their state has been checked twice.
+type fakeAthena struct {
+	athenaiface.AthenaAPI
+
+	pages       []*athena.ResultSet
+	stateChecks int
+	stopped     bool
+}
+
+func (f *fakeAthena) StartQueryExecutionWithContext(ctx aws.Context, input *athena.StartQueryExecutionInput, opts ...request.Option) (*athena.StartQueryExecutionOutput, error) {
+	return &athena.StartQueryExecutionOutput{QueryExecutionId: aws.String("query-id")}, nil
+}
+
+func (f *fakeAthena) GetQueryExecutionWithContext(ctx aws.Context, input *athena.GetQueryExecutionInput, opts ...request.Option) (*athena.GetQueryExecutionOutput, error) {
+	f.stateChecks++
+	state := athena.QueryExecutionStateRunning
+	if f.stateChecks > 1 {
+		state = athena.QueryExecutionStateSucceeded
+	}
+	return &athena.GetQueryExecutionOutput{
+		QueryExecution: &athena.QueryExecution{
+			Status: &athena.QueryExecutionStatus{State: aws.String(state)},
+		},
+	}, nil
+}
+
+func (f *fakeAthena) StopQueryExecutionWithContext(ctx aws.Context, input *athena.StopQueryExecutionInput, opts ...request.Option) (*athena.StopQueryExecutionOutput, error) {
+	f.stopped = true
+	return &athena.StopQueryExecutionOutput{}, nil
+}
+
+func (f *fakeAthena) GetQueryResultsWithContext(ctx aws.Context, input *athena.GetQueryResultsInput, opts ...request.Option) (*athena.GetQueryResultsOutput, error) {
+	page := 0
+	if input.NextToken != nil {
+		page = 1
+	}
+	output := &athena.GetQueryResultsOutput{ResultSet: f.pages[page]}
+	if page+1 < len(f.pages) {
+		output.NextToken = aws.String("next")
+	}
+	return output, nil
+}
+
+func resultRow(values ...*string) *athena.Row {
+	row := &athena.Row{}
+	for _, v := range values {
+		row.Data = append(row.Data, &athena.Datum{VarCharValue: v})
+	}
+	return row
+}
+
+func TestQueryContext(t *testing.T) {
+	columns := &athena.ResultSetMetadata{ColumnInfo: []*athena.ColumnInfo{
+		{Name: aws.String("namespace"), Type: aws.String("varchar")},
+		{Name: aws.String("pod_usage_cpu_core_seconds"), Type: aws.String("double")},
+		{Name: aws.String("period_start"), Type: aws.String("timestamp")},
+	}}
+	fake := &fakeAthena{pages: []*athena.ResultSet{
+		{
+			ResultSetMetadata: columns,
+			Rows: []*athena.Row{
+				resultRow(aws.String("namespace"), aws.String("pod_usage_cpu_core_seconds"), aws.String("period_start")),
+				resultRow(aws.String("default"), aws.String("60.5"), aws.String("2018-01-01 00:00:00.000")),
+			},
+		},
+		{
+			ResultSetMetadata: columns,
+			Rows:              []*athena.Row{resultRow(aws.String("kube-system"), nil, aws.String("2018-01-01 01:00:00.000"))},
+		},
+	}}
+	c := &conn{athenaAPI: fake, cfg: Config{Database: "default", OutputLocation: "s3://bucket/results/"}}
+
+	rows, err := c.QueryContext(context.Background(), "SELECT * FROM pod_cpu_usage", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"namespace", "pod_usage_cpu_core_seconds", "period_start"}, rows.Columns())
+	assert.Equal(t, "double", rows.(driver.RowsColumnTypeDatabaseTypeName).ColumnTypeDatabaseTypeName(1))
+
+	var results [][]driver.Value
+	for {
+		dest := make([]driver.Value, 3)
+		err := rows.Next(dest)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		results = append(results, dest)
+	}
+	assert.Equal(t, [][]driver.Value{
+		{"default", 60.5, time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"kube-system", nil, time.Date(2018, 1, 1, 1, 0, 0, 0, time.UTC)},
+	}, results, "expected the header row to be skipped, and every page to be returned")
+	assert.Equal(t, 2, fake.stateChecks, "expected the query's state to be polled until it succeeded")
+}
+
+func TestQueryContextCancelled(t *testing.T) {
+	fake := &fakeAthena{stateChecks: -10}
+	c := &conn{athenaAPI: fake, cfg: Config{Database: "default", OutputLocation: "s3://bucket/results/"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := c.QueryContext(ctx, "SELECT 1", nil)
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, fake.stopped, "expected the query to be stopped")
+}
+
+func TestParseDSN(t *testing.T) {
+	cfg := Config{Region: "us-east-1", Database: "metering", OutputLocation: "s3://bucket/athena-results/"}
+	parsed, err := parseDSN(cfg.FormatDSN())
+	require.NoError(t, err)
+	assert.Equal(t, cfg, parsed)
+
+	_, err = parseDSN(Config{Database: "metering", OutputLocation: "/tmp/results"}.FormatDSN())
+	assert.Error(t, err, "expected the output location to be required to be in S3")
+}
+
+func TestS3Location(t *testing.T) {
+	location, err := S3Location("s3a://bucket/prefix/table/")
+	require.NoError(t, err)
+	assert.Equal(t, "s3://bucket/prefix/table/", location)
+
+	_, err = S3Location("hdfs://hdfs-namenode-proxy:8020/operator_metering/storage/")
+	assert.Error(t, err)
+}
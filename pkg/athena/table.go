@@ -0,0 +1,37 @@
+package athena
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/operator-framework/operator-metering/pkg/db"
+)
+
+// S3Location converts a Hive table location using the s3a:// or s3n://
+// schemes into the s3:// form Athena requires. Athena can only query data
+// stored in S3, so other locations return an error.
+func S3Location(location string) (string, error) {
+	locationURL, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("invalid table location %q: %v", location, err)
+	}
+	switch locationURL.Scheme {
+	case "s3", "s3a", "s3n":
+		locationURL.Scheme = "s3"
+		return locationURL.String(), nil
+	default:
+		return "", fmt.Errorf("table location %q is not in S3, Athena can only query tables stored in S3", location)
+	}
+}
+
+// ExecuteDropTable drops the table. Athena doesn't support PURGE, and tables
+// are always external, so the table's data isn't deleted.
+func ExecuteDropTable(ctx context.Context, queryer db.Queryer, tableName string, ignoreNotExists bool) error {
+	ifExists := ""
+	if ignoreNotExists {
+		ifExists = "IF EXISTS"
+	}
+	_, err := queryer.QueryContext(ctx, fmt.Sprintf("DROP TABLE %s `%s`", ifExists, tableName))
+	return err
+}
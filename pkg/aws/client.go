@@ -7,7 +7,6 @@ import (
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 )
@@ -36,11 +35,9 @@ type manifestRetriever struct {
 	bucket, prefix string
 }
 
-func NewManifestRetriever(region, bucket, prefix string) ManifestRetriever {
-	awsSession := session.Must(session.NewSession())
-	client := s3.New(awsSession, aws.NewConfig().WithRegion(region))
+func NewManifestRetriever(region, bucket, prefix string, endpoint S3Endpoint, roleARN string) ManifestRetriever {
 	return &manifestRetriever{
-		s3API:  client,
+		s3API:  newS3Client(region, endpoint, roleARN),
 		bucket: bucket,
 		prefix: prefix,
 	}
@@ -51,13 +48,7 @@ func NewManifestRetriever(region, bucket, prefix string) ManifestRetriever {
 // files that are within assemblyId subdirectories, as the top level manifest
 // points to the directory containing the most up to date billing report data.
 func (r *manifestRetriever) RetrieveManifests() ([]*Manifest, error) {
-	// ensure that there is a slash at end of location
-	prefix := r.prefix
-	if len(prefix) == 0 {
-		prefix = "/"
-	} else if prefix[len(prefix)-1] != '/' {
-		prefix += "/"
-	}
+	prefix := normalizePrefix(r.prefix)
 
 	var manifests []*Manifest
 	var manifestErr error
@@ -96,41 +87,54 @@ func (r *manifestRetriever) filterObjects(prefix string, objects []*s3.Object) [
 	var keys []string
 	for _, obj := range objects {
 		key := *obj.Key
-
-		// only look for manifest files
-		if !strings.HasSuffix(key, ManifestSuffix) {
-			continue
+		if isTopLevelManifest(prefix, key) {
+			keys = append(keys, key)
 		}
-
-		// We're looking for the top-level manifest for a given time range.
-		// These manifests are copies of manifests within the assemblyId
-		// directories, and are updated everytime a report for the given time
-		// period is run. We use these to determine the "most up to date" set
-		// of data.
-		// We're looking for manifests in the following format:
-		// <report-prefix>/<report-name>/YYYYMMDD-YYYYMMDD/<report-name>-Manifest.json
-		// We ignore the following manifests:
-		// <report-prefix>/<report-name>/YYYYMMDD-YYYYMMDD/<assemblyId>/<report-name>-Manifest.json
-
-		// Strip off <report-prefix>/<report-name>
-		trimmedPath := strings.TrimPrefix(key, prefix)
-		// manifestDir will be <YYYYMMDD-YYYYMMDD>/<assemblyId> or <YYYYMMDD-YYYYMMDD>
-		// The latter is what we're looking for (without the assemblyId subdir)
-		manifestDir := path.Dir(trimmedPath)
-		// assemblyDir will be empty if manifestDir is without an assemblyId
-		// subdirectory: <YYYYMMDD-YYYYMMDD>
-		assemblyDir, _ := path.Split(manifestDir)
-		// If there's another directory, it isn't the top-level manifest.
-		if assemblyDir != "" {
-			continue
-		}
-		// If we've gotten this far, then "key" is a top-level manifest that we
-		// care about.
-		keys = append(keys, key)
 	}
 	return keys
 }
 
+// normalizePrefix ensures that there is a slash at end of a report prefix.
+func normalizePrefix(prefix string) string {
+	if len(prefix) == 0 {
+		return "/"
+	} else if prefix[len(prefix)-1] != '/' {
+		return prefix + "/"
+	}
+	return prefix
+}
+
+// isTopLevelManifest returns true if key is the top-level manifest of a
+// billing period within prefix, which must be normalized using
+// normalizePrefix.
+func isTopLevelManifest(prefix, key string) bool {
+	// only look for manifest files
+	if !strings.HasSuffix(key, ManifestSuffix) {
+		return false
+	}
+
+	// We're looking for the top-level manifest for a given time range.
+	// These manifests are copies of manifests within the assemblyId
+	// directories, and are updated everytime a report for the given time
+	// period is run. We use these to determine the "most up to date" set
+	// of data.
+	// We're looking for manifests in the following format:
+	// <report-prefix>/<report-name>/YYYYMMDD-YYYYMMDD/<report-name>-Manifest.json
+	// We ignore the following manifests:
+	// <report-prefix>/<report-name>/YYYYMMDD-YYYYMMDD/<assemblyId>/<report-name>-Manifest.json
+
+	// Strip off <report-prefix>/<report-name>
+	trimmedPath := strings.TrimPrefix(key, prefix)
+	// manifestDir will be <YYYYMMDD-YYYYMMDD>/<assemblyId> or <YYYYMMDD-YYYYMMDD>
+	// The latter is what we're looking for (without the assemblyId subdir)
+	manifestDir := path.Dir(trimmedPath)
+	// assemblyDir will be empty if manifestDir is without an assemblyId
+	// subdirectory: <YYYYMMDD-YYYYMMDD>
+	assemblyDir, _ := path.Split(manifestDir)
+	// If there's another directory, it isn't the top-level manifest.
+	return assemblyDir == ""
+}
+
 // retrieveManifest retrieves a manifest from the given bucket and key.
 func retrieveManifest(client s3iface.S3API, bucket, key string) (*Manifest, error) {
 	obj, err := client.GetObject(&s3.GetObjectInput{
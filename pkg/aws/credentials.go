@@ -0,0 +1,132 @@
+package aws
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+const (
+	// roleARNEnvVar and webIdentityTokenFileEnvVar are set by the EKS pod
+	// identity webhook for pods using a ServiceAccount annotated with
+	// eks.amazonaws.com/role-arn (IAM roles for service accounts).
+	roleARNEnvVar              = "AWS_ROLE_ARN"
+	webIdentityTokenFileEnvVar = "AWS_WEB_IDENTITY_TOKEN_FILE"
+	roleSessionNameEnvVar      = "AWS_ROLE_SESSION_NAME"
+
+	defaultRoleSessionName = "reporting-operator"
+
+	// credentialsExpiryWindow is how long before temporary credentials
+	// expire they're refreshed, so requests in progress don't fail.
+	credentialsExpiryWindow = time.Minute
+)
+
+var (
+	baseSessionOnce sync.Once
+	baseSession     *session.Session
+
+	roleCredentialsMu sync.Mutex
+	roleCredentials   = make(map[string]*credentials.Credentials)
+)
+
+// newSession returns the session clients are created from. The session's
+// credentials are shared by every client, so temporary credentials are only
+// refreshed once they're about to expire.
+func newSession() *session.Session {
+	baseSessionOnce.Do(func() {
+		baseSession = session.Must(session.NewSession())
+		roleARN, tokenFile := os.Getenv(roleARNEnvVar), os.Getenv(webIdentityTokenFileEnvVar)
+		if roleARN != "" && tokenFile != "" {
+			// the SDK doesn't support web identity credentials, so they're
+			// used instead of the default credential chain when configured.
+			sessionName := os.Getenv(roleSessionNameEnvVar)
+			if sessionName == "" {
+				sessionName = defaultRoleSessionName
+			}
+			baseSession = baseSession.Copy(aws.NewConfig().WithCredentials(
+				credentials.NewCredentials(&webIdentityProvider{
+					stsAPI:          sts.New(baseSession),
+					roleARN:         roleARN,
+					tokenFile:       tokenFile,
+					roleSessionName: sessionName,
+				}),
+			))
+		}
+	})
+	return baseSession
+}
+
+// newConfig returns the config of a client for the region. If roleARN is
+// set, the client uses credentials for the role, assumed using the
+// session's credentials.
+func newConfig(region, roleARN string) *aws.Config {
+	config := aws.NewConfig().WithRegion(region)
+	if roleARN != "" {
+		config = config.WithCredentials(assumeRoleCredentials(roleARN))
+	}
+	return config
+}
+
+// assumeRoleCredentials returns the credentials of the role. Credentials
+// are cached for each role, and refreshed once they're about to expire.
+func assumeRoleCredentials(roleARN string) *credentials.Credentials {
+	roleCredentialsMu.Lock()
+	defer roleCredentialsMu.Unlock()
+	creds, ok := roleCredentials[roleARN]
+	if !ok {
+		creds = stscreds.NewCredentials(newSession(), roleARN, func(p *stscreds.AssumeRoleProvider) {
+			p.RoleSessionName = defaultRoleSessionName
+			p.ExpiryWindow = credentialsExpiryWindow
+		})
+		roleCredentials[roleARN] = creds
+	}
+	return creds
+}
+
+// webIdentityRoleAssumer is the subset of the STS API used by
+// webIdentityProvider.
+type webIdentityRoleAssumer interface {
+	AssumeRoleWithWebIdentity(*sts.AssumeRoleWithWebIdentityInput) (*sts.AssumeRoleWithWebIdentityOutput, error)
+}
+
+// webIdentityProvider retrieves credentials for a role by exchanging the
+// web identity token in tokenFile, which is rotated by Kubernetes, using
+// AssumeRoleWithWebIdentity.
+type webIdentityProvider struct {
+	credentials.Expiry
+
+	stsAPI          webIdentityRoleAssumer
+	roleARN         string
+	tokenFile       string
+	roleSessionName string
+}
+
+func (p *webIdentityProvider) Retrieve() (credentials.Value, error) {
+	token, err := ioutil.ReadFile(p.tokenFile)
+	if err != nil {
+		return credentials.Value{}, fmt.Errorf("could not read web identity token file %s: %v", p.tokenFile, err)
+	}
+	out, err := p.stsAPI.AssumeRoleWithWebIdentity(&sts.AssumeRoleWithWebIdentityInput{
+		RoleArn:          aws.String(p.roleARN),
+		RoleSessionName:  aws.String(p.roleSessionName),
+		WebIdentityToken: aws.String(string(token)),
+	})
+	if err != nil {
+		return credentials.Value{}, fmt.Errorf("could not assume role %s using web identity: %v", p.roleARN, err)
+	}
+	p.SetExpiration(aws.TimeValue(out.Credentials.Expiration), credentialsExpiryWindow)
+	return credentials.Value{
+		AccessKeyID:     aws.StringValue(out.Credentials.AccessKeyId),
+		SecretAccessKey: aws.StringValue(out.Credentials.SecretAccessKey),
+		SessionToken:    aws.StringValue(out.Credentials.SessionToken),
+		ProviderName:    "WebIdentityProvider",
+	}, nil
+}
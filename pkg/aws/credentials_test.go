@@ -0,0 +1,70 @@
+package aws
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWebIdentityRoleAssumer struct {
+	inputs     []*sts.AssumeRoleWithWebIdentityInput
+	expiration time.Time
+}
+
+func (f *fakeWebIdentityRoleAssumer) AssumeRoleWithWebIdentity(input *sts.AssumeRoleWithWebIdentityInput) (*sts.AssumeRoleWithWebIdentityOutput, error) {
+	f.inputs = append(f.inputs, input)
+	return &sts.AssumeRoleWithWebIdentityOutput{
+		Credentials: &sts.Credentials{
+			AccessKeyId:     aws.String("access-key"),
+			SecretAccessKey: aws.String("secret-key"),
+			SessionToken:    aws.String("session-token"),
+			Expiration:      aws.Time(f.expiration),
+		},
+	}, nil
+}
+
+func TestWebIdentityProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "web-identity")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("token-1"), 0600))
+
+	stsAPI := &fakeWebIdentityRoleAssumer{expiration: time.Now().Add(time.Hour)}
+	provider := &webIdentityProvider{
+		stsAPI:          stsAPI,
+		roleARN:         "arn:aws:iam::123456789012:role/metering",
+		tokenFile:       tokenFile,
+		roleSessionName: "reporting-operator",
+	}
+	creds := credentials.NewCredentials(provider)
+
+	value, err := creds.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "access-key", value.AccessKeyID)
+	assert.Equal(t, "session-token", value.SessionToken)
+	require.Len(t, stsAPI.inputs, 1)
+	assert.Equal(t, "token-1", aws.StringValue(stsAPI.inputs[0].WebIdentityToken))
+	assert.Equal(t, "arn:aws:iam::123456789012:role/metering", aws.StringValue(stsAPI.inputs[0].RoleArn))
+
+	// cached credentials are used until they're about to expire
+	_, err = creds.Get()
+	require.NoError(t, err)
+	assert.Len(t, stsAPI.inputs, 1)
+
+	// the rotated token is read when the credentials are refreshed
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("token-2"), 0600))
+	provider.SetExpiration(time.Now(), 0)
+	_, err = creds.Get()
+	require.NoError(t, err)
+	require.Len(t, stsAPI.inputs, 2)
+	assert.Equal(t, "token-2", aws.StringValue(stsAPI.inputs[1].WebIdentityToken))
+}
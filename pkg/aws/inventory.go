@@ -0,0 +1,139 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+const (
+	// InventoryManifestName is the name of the manifest written by each run
+	// of an S3 Inventory.
+	InventoryManifestName = "manifest.json"
+
+	// InventoryHiveDirectory is the directory of an S3 Inventory containing
+	// symlink files pointing at the data files of each run, partitioned by
+	// dt, for use with Hive's SymlinkTextInputFormat.
+	InventoryHiveDirectory = "hive"
+
+	// InventoryDateFormat is the layout of the directory names each run of
+	// an S3 Inventory writes it's manifest to.
+	InventoryDateFormat = "2006-01-02T15-04Z"
+)
+
+// InventoryManifest describes the files written by a single run of an S3
+// Inventory.
+type InventoryManifest struct {
+	SourceBucket      string                  `json:"sourceBucket"`
+	DestinationBucket string                  `json:"destinationBucket"`
+	Version           string                  `json:"version"`
+	CreationTimestamp string                  `json:"creationTimestamp"`
+	FileFormat        string                  `json:"fileFormat"`
+	FileSchema        string                  `json:"fileSchema"`
+	Files             []InventoryManifestFile `json:"files"`
+}
+
+type InventoryManifestFile struct {
+	Key         string `json:"key"`
+	Size        int64  `json:"size"`
+	MD5Checksum string `json:"MD5checksum"`
+}
+
+// Columns returns the names of the fields in each row of the inventory,
+// in the order they appear. Only the CSV format is supported.
+func (m InventoryManifest) Columns() ([]string, error) {
+	if !strings.EqualFold(m.FileFormat, "csv") {
+		return nil, fmt.Errorf("S3 Inventory of bucket %s has unsupported fileFormat %q, only CSV is supported", m.SourceBucket, m.FileFormat)
+	}
+	var columns []string
+	for _, field := range strings.Split(m.FileSchema, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			return nil, fmt.Errorf("S3 Inventory of bucket %s has invalid fileSchema %q", m.SourceBucket, m.FileSchema)
+		}
+		columns = append(columns, field)
+	}
+	return columns, nil
+}
+
+type InventoryManifestRetriever interface {
+	// RetrieveNewestManifest returns the manifest of the newest run of the
+	// inventory, or nil if it hasn't run yet.
+	RetrieveNewestManifest() (*InventoryManifest, error)
+}
+
+type inventoryManifestRetriever struct {
+	s3API          s3iface.S3API
+	bucket, prefix string
+}
+
+// NewInventoryManifestRetriever returns an InventoryManifestRetriever for
+// the S3 Inventory written to prefix within bucket. The prefix is the
+// inventory's destination prefix, followed by the source bucket's name and
+// the inventory's configuration ID. If roleARN is set, the role is assumed.
+func NewInventoryManifestRetriever(region, bucket, prefix string, endpoint S3Endpoint, roleARN string) InventoryManifestRetriever {
+	return &inventoryManifestRetriever{
+		s3API:  newS3Client(region, endpoint, roleARN),
+		bucket: bucket,
+		prefix: prefix,
+	}
+}
+
+func (r *inventoryManifestRetriever) RetrieveNewestManifest() (*InventoryManifest, error) {
+	prefix := strings.TrimPrefix(normalizePrefix(r.prefix), "/")
+
+	// Each run of an inventory writes it's manifest to
+	// <prefix>/YYYY-MM-DDTHH-MMZ/manifest.json, and the data files of every
+	// run to <prefix>/data/, so only list the directories within the prefix.
+	var runDirs []string
+	err := r.s3API.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket:    aws.String(r.bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+		MaxKeys:   aws.Int64(maxS3Keys),
+	}, func(out *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, commonPrefix := range out.CommonPrefixes {
+			runDir := path.Base(aws.StringValue(commonPrefix.Prefix))
+			if isInventoryRunDirectory(runDir) {
+				runDirs = append(runDirs, runDir)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list S3 Inventory runs in bucket %s: %v", r.bucket, err)
+	}
+	if len(runDirs) == 0 {
+		return nil, nil
+	}
+
+	// the directory names sort chronologically
+	sort.Strings(runDirs)
+	key := prefix + runDirs[len(runDirs)-1] + "/" + InventoryManifestName
+	obj, err := r.s3API.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't get S3 Inventory manifest from bucket '%s' with key '%s': %v", r.bucket, key, err)
+	}
+	defer obj.Body.Close()
+
+	var manifest InventoryManifest
+	if err := json.NewDecoder(obj.Body).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("can't decode S3 Inventory manifest from bucket '%s' with key '%s': %v", r.bucket, key, err)
+	}
+	return &manifest, nil
+}
+
+func isInventoryRunDirectory(name string) bool {
+	_, err := time.Parse(InventoryDateFormat, name)
+	return err == nil
+}
@@ -0,0 +1,72 @@
+package aws
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/aws/s3_test"
+)
+
+const inventoryManifestText = `{
+  "sourceBucket": "example-source-bucket",
+  "destinationBucket": "arn:aws:s3:::example-inventory-bucket",
+  "version": "2016-11-30",
+  "creationTimestamp": "1514944800000",
+  "fileFormat": "CSV",
+  "fileSchema": "Bucket, Key, Size, LastModifiedDate, StorageClass",
+  "files": [{
+    "key": "inventory/example-source-bucket/daily/data/ae1d2e5e-5ab6-4c3b-bd6e-e7a2e7b6c6b1.csv.gz",
+    "size": 2147483647,
+    "MD5checksum": "f11166069f1990abeb9c97ace9cdfabc"
+  }]
+}`
+
+func TestInventoryManifestRetriever(t *testing.T) {
+	mock := s3_test.NewMockS3()
+	mock.NewBucket("example-inventory-bucket")
+	retriever := &inventoryManifestRetriever{
+		s3API:  mock,
+		bucket: "example-inventory-bucket",
+		prefix: "inventory/example-source-bucket/daily",
+	}
+
+	manifest, err := retriever.RetrieveNewestManifest()
+	require.NoError(t, err)
+	assert.Nil(t, manifest, "expected no manifest before the inventory has run")
+
+	for key, body := range map[string]string{
+		"inventory/example-source-bucket/daily/2018-01-01T00-00Z/manifest.json":      `{"sourceBucket": "old"}`,
+		"inventory/example-source-bucket/daily/2018-01-02T00-00Z/manifest.json":      inventoryManifestText,
+		"inventory/example-source-bucket/daily/data/ae1d2e5e.csv.gz":                 "",
+		"inventory/example-source-bucket/daily/hive/dt=2018-01-02-00-00/symlink.txt": "",
+	} {
+		_, err := mock.PutObject(&s3.PutObjectInput{
+			Bucket: aws.String("example-inventory-bucket"),
+			Key:    aws.String(key),
+			Body:   bytes.NewReader([]byte(body)),
+		})
+		require.NoError(t, err)
+	}
+
+	manifest, err = retriever.RetrieveNewestManifest()
+	require.NoError(t, err)
+	require.NotNil(t, manifest)
+	assert.Equal(t, "example-source-bucket", manifest.SourceBucket)
+	require.Len(t, manifest.Files, 1)
+
+	columns, err := manifest.Columns()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bucket", "Key", "Size", "LastModifiedDate", "StorageClass"}, columns)
+}
+
+func TestInventoryManifestColumns(t *testing.T) {
+	_, err := InventoryManifest{FileFormat: "ORC", FileSchema: "Bucket, Key"}.Columns()
+	assert.Error(t, err, "expected only CSV inventories to be supported")
+	_, err = InventoryManifest{FileFormat: "CSV", FileSchema: "Bucket,,Key"}.Columns()
+	assert.Error(t, err, "expected empty field names to be rejected")
+}
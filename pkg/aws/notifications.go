@@ -0,0 +1,135 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+)
+
+const (
+	// maxSQSMessages is the maximum number of messages returned by a single
+	// SQS receive message API response.
+	maxSQSMessages = 10
+	// sqsWaitTimeSeconds is how long a receive message API call waits for
+	// a message to arrive before returning an empty response.
+	sqsWaitTimeSeconds = 20
+)
+
+// ManifestNotifier receives notifications of new usage report manifests.
+type ManifestNotifier interface {
+	// WaitForManifests blocks until at least one S3 event notification is
+	// received, ctx is cancelled, or the long poll times out. It returns
+	// true if any of the notifications received were for top-level
+	// manifests within the bucket and prefix. Every message received is
+	// removed from the queue, and found may be true even if an error is
+	// returned.
+	WaitForManifests(ctx context.Context) (found bool, err error)
+}
+
+type manifestNotifier struct {
+	sqsAPI                   sqsiface.SQSAPI
+	queueURL, bucket, prefix string
+}
+
+// NewManifestNotifier returns a ManifestNotifier which receives S3 event
+// notifications for the bucket from the SQS queue at queueURL. If roleARN is
+// set, the role is assumed.
+func NewManifestNotifier(region, queueURL, bucket, prefix, roleARN string) ManifestNotifier {
+	client := sqs.New(newSession(), newConfig(region, roleARN))
+	return &manifestNotifier{
+		sqsAPI:   client,
+		queueURL: queueURL,
+		bucket:   bucket,
+		prefix:   prefix,
+	}
+}
+
+func (n *manifestNotifier) WaitForManifests(ctx context.Context) (bool, error) {
+	out, err := n.sqsAPI.ReceiveMessageWithContext(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(n.queueURL),
+		MaxNumberOfMessages: aws.Int64(maxSQSMessages),
+		WaitTimeSeconds:     aws.Int64(sqsWaitTimeSeconds),
+	})
+	if err != nil {
+		return false, fmt.Errorf("could not receive messages from SQS queue %s: %v", n.queueURL, err)
+	}
+
+	found := false
+	var parseErr error
+	for _, msg := range out.Messages {
+		keys, err := parseS3EventNotification(aws.StringValue(msg.Body), n.bucket)
+		if err != nil {
+			// remove messages which aren't S3 event notifications, so they
+			// aren't received again.
+			parseErr = fmt.Errorf("could not parse message %s from SQS queue %s: %v", aws.StringValue(msg.MessageId), n.queueURL, err)
+		}
+		for _, key := range keys {
+			if strings.HasPrefix(key, n.prefix) && isTopLevelManifest(normalizePrefix(n.prefix), key) {
+				found = true
+			}
+		}
+
+		// the notification has been handled, so remove it from the queue
+		_, err = n.sqsAPI.DeleteMessageWithContext(ctx, &sqs.DeleteMessageInput{
+			QueueUrl:      aws.String(n.queueURL),
+			ReceiptHandle: msg.ReceiptHandle,
+		})
+		if err != nil {
+			return found, fmt.Errorf("could not delete message %s from SQS queue %s: %v", aws.StringValue(msg.MessageId), n.queueURL, err)
+		}
+	}
+	return found, parseErr
+}
+
+// s3EventNotification is the subset of an S3 event notification message
+// which is needed to determine which objects were created.
+type s3EventNotification struct {
+	Records []struct {
+		EventName string `json:"eventName"`
+		S3        struct {
+			Bucket struct {
+				Name string `json:"name"`
+			} `json:"bucket"`
+			Object struct {
+				Key string `json:"key"`
+			} `json:"object"`
+		} `json:"s3"`
+	} `json:"Records"`
+
+	// Message contains the S3 event notification when it was delivered to
+	// the queue by an SNS topic.
+	Message string `json:"Message"`
+}
+
+// parseS3EventNotification returns the keys of the objects created within
+// bucket in an S3 event notification message, which may be wrapped in an SNS
+// notification. The test event S3 sends when notifications are configured
+// has no records, so no keys are returned for it.
+func parseS3EventNotification(body, bucket string) ([]string, error) {
+	var notification s3EventNotification
+	if err := json.Unmarshal([]byte(body), &notification); err != nil {
+		return nil, err
+	}
+	if len(notification.Records) == 0 && notification.Message != "" {
+		return parseS3EventNotification(notification.Message, bucket)
+	}
+	var keys []string
+	for _, record := range notification.Records {
+		if !strings.HasPrefix(record.EventName, "ObjectCreated:") || record.S3.Bucket.Name != bucket {
+			continue
+		}
+		// object keys are URL encoded in event notifications
+		key, err := url.QueryUnescape(record.S3.Object.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid object key %q: %v", record.S3.Object.Key, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
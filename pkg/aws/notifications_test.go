@@ -0,0 +1,139 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	manifestCreatedEvent = `{"Records":[{"eventName":"ObjectCreated:Put","s3":{"bucket":{"name":"billing-bucket"},"object":{"key":"billing-path/sample-report/20170701-20170801/sample-report-Manifest.json"}}}]}`
+	reportCreatedEvent   = `{"Records":[{"eventName":"ObjectCreated:Put","s3":{"bucket":{"name":"billing-bucket"},"object":{"key":"billing-path/sample-report/20170701-20170801/ea74f90b/sample-report-1.csv.gz"}}}]}`
+	s3TestEvent          = `{"Service":"Amazon S3","Event":"s3:TestEvent","Bucket":"billing-bucket"}`
+)
+
+func TestParseS3EventNotification(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{
+			name:     "object created",
+			body:     manifestCreatedEvent,
+			expected: []string{"billing-path/sample-report/20170701-20170801/sample-report-Manifest.json"},
+		},
+		{
+			name:     "url encoded key",
+			body:     `{"Records":[{"eventName":"ObjectCreated:Put","s3":{"bucket":{"name":"billing-bucket"},"object":{"key":"billing+path/report%3D1.json"}}}]}`,
+			expected: []string{"billing path/report=1.json"},
+		},
+		{
+			name:     "object removed",
+			body:     `{"Records":[{"eventName":"ObjectRemoved:Delete","s3":{"bucket":{"name":"billing-bucket"},"object":{"key":"billing-path/report.json"}}}]}`,
+			expected: nil,
+		},
+		{
+			name:     "other bucket",
+			body:     `{"Records":[{"eventName":"ObjectCreated:Put","s3":{"bucket":{"name":"other-bucket"},"object":{"key":"billing-path/report.json"}}}]}`,
+			expected: nil,
+		},
+		{
+			name:     "test event",
+			body:     s3TestEvent,
+			expected: nil,
+		},
+		{
+			name:     "delivered by SNS",
+			body:     `{"Type":"Notification","Message":"{\"Records\":[{\"eventName\":\"ObjectCreated:Put\",\"s3\":{\"bucket\":{\"name\":\"billing-bucket\"},\"object\":{\"key\":\"billing-path/report.json\"}}}]}"}`,
+			expected: []string{"billing-path/report.json"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := parseS3EventNotification(tt.body, "billing-bucket")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, keys)
+		})
+	}
+}
+
+type fakeSQS struct {
+	sqsiface.SQSAPI
+	messages []*sqs.Message
+	deleted  []string
+}
+
+func (f *fakeSQS) ReceiveMessageWithContext(ctx aws.Context, input *sqs.ReceiveMessageInput, opts ...request.Option) (*sqs.ReceiveMessageOutput, error) {
+	return &sqs.ReceiveMessageOutput{Messages: f.messages}, nil
+}
+
+func (f *fakeSQS) DeleteMessageWithContext(ctx aws.Context, input *sqs.DeleteMessageInput, opts ...request.Option) (*sqs.DeleteMessageOutput, error) {
+	f.deleted = append(f.deleted, aws.StringValue(input.ReceiptHandle))
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func TestManifestNotifierWaitForManifests(t *testing.T) {
+	tests := []struct {
+		name          string
+		bodies        []string
+		expectedFound bool
+		expectedErr   bool
+	}{
+		{
+			name:          "top-level manifest",
+			bodies:        []string{s3TestEvent, manifestCreatedEvent},
+			expectedFound: true,
+		},
+		{
+			name:          "report data",
+			bodies:        []string{reportCreatedEvent},
+			expectedFound: false,
+		},
+		{
+			name:          "invalid message",
+			bodies:        []string{"not json", manifestCreatedEvent},
+			expectedFound: true,
+			expectedErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeSQS{}
+			var handles []string
+			for i, body := range tt.bodies {
+				handle := string(rune('a' + i))
+				handles = append(handles, handle)
+				fake.messages = append(fake.messages, &sqs.Message{
+					Body:          aws.String(body),
+					ReceiptHandle: aws.String(handle),
+				})
+			}
+			notifier := &manifestNotifier{
+				sqsAPI:   fake,
+				queueURL: "https://sqs.us-east-1.amazonaws.com/123456789012/billing",
+				bucket:   "billing-bucket",
+				prefix:   "billing-path/sample-report",
+			}
+
+			found, err := notifier.WaitForManifests(context.Background())
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedFound, found)
+			assert.Equal(t, handles, fake.deleted, "expected every message to be deleted")
+		})
+	}
+}
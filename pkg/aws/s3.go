@@ -0,0 +1,91 @@
+package aws
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// S3Endpoint configures the endpoint of the S3 API, allowing S3 compatible
+// object stores such as MinIO and Ceph's RADOS Gateway to be used instead of
+// AWS S3. The zero value uses AWS S3.
+type S3Endpoint struct {
+	// URL is the URL of the endpoint, such as https://minio.example.com:9000.
+	URL string
+	// ForcePathStyle addresses buckets using the path of requests, instead
+	// of a subdomain of the endpoint.
+	ForcePathStyle bool
+	// InsecureSkipVerify disables verifying the endpoint's TLS certificate.
+	InsecureSkipVerify bool
+}
+
+// newS3Client returns an S3 client for the region using the endpoint. If
+// region is empty, the default region is used, which is sufficient for most
+// S3 compatible object stores. If roleARN is set, the role is assumed.
+func newS3Client(region string, endpoint S3Endpoint, roleARN string) *s3.S3 {
+	if region == "" {
+		region = defaultS3Region
+	}
+	awsConfig := newConfig(region, roleARN)
+	if endpoint.URL != "" {
+		awsConfig = awsConfig.WithEndpoint(endpoint.URL)
+	}
+	if endpoint.ForcePathStyle {
+		awsConfig = awsConfig.WithS3ForcePathStyle(true)
+	}
+	if endpoint.InsecureSkipVerify {
+		awsConfig = awsConfig.WithHTTPClient(&http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		})
+	}
+	return s3.New(newSession(), awsConfig)
+}
+
+// CheckBucket returns an error if the bucket doesn't exist, or can't be
+// accessed using the credentials from the environment, or the role if
+// roleARN is set.
+func CheckBucket(region, bucket string, endpoint S3Endpoint, roleARN string) error {
+	_, err := newS3Client(region, endpoint, roleARN).HeadBucket(&s3.HeadBucketInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("could not access bucket %s: %v", bucket, err)
+	}
+	return nil
+}
+
+// CheckBucketEncryption returns an error if the default encryption of the
+// bucket doesn't use the sseAlgorithm, either s3.ServerSideEncryptionAes256
+// or s3.ServerSideEncryptionAwsKms, and when kmsKeyID is set, that KMS key.
+func CheckBucketEncryption(region, bucket string, endpoint S3Endpoint, roleARN, sseAlgorithm, kmsKeyID string) error {
+	out, err := newS3Client(region, endpoint, roleARN).GetBucketEncryption(&s3.GetBucketEncryptionInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "ServerSideEncryptionConfigurationNotFoundError" {
+			return fmt.Errorf("bucket %s has no default encryption configured", bucket)
+		}
+		return fmt.Errorf("could not get encryption of bucket %s: %v", bucket, err)
+	}
+	if out.ServerSideEncryptionConfiguration == nil {
+		return fmt.Errorf("bucket %s has no default encryption configured", bucket)
+	}
+	for _, rule := range out.ServerSideEncryptionConfiguration.Rules {
+		sse := rule.ApplyServerSideEncryptionByDefault
+		if sse == nil || aws.StringValue(sse.SSEAlgorithm) != sseAlgorithm {
+			continue
+		}
+		if kmsKeyID != "" && aws.StringValue(sse.KMSMasterKeyID) != kmsKeyID {
+			return fmt.Errorf("bucket %s is encrypted using KMS key %q by default, expected %q", bucket, aws.StringValue(sse.KMSMasterKeyID), kmsKeyID)
+		}
+		return nil
+	}
+	return fmt.Errorf("bucket %s isn't encrypted using %s by default", bucket, sseAlgorithm)
+}
@@ -0,0 +1,61 @@
+package aws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewS3Client(t *testing.T) {
+	client := newS3Client("", S3Endpoint{}, "")
+	assert.Equal(t, defaultS3Region, aws.StringValue(client.Config.Region))
+	assert.Nil(t, client.Config.Endpoint, "expected AWS S3 to be used")
+	assert.False(t, aws.BoolValue(client.Config.S3ForcePathStyle))
+
+	client = newS3Client("eu-west-1", S3Endpoint{URL: "https://minio.example.com:9000", ForcePathStyle: true, InsecureSkipVerify: true}, "")
+	assert.Equal(t, "eu-west-1", aws.StringValue(client.Config.Region))
+	assert.Equal(t, "https://minio.example.com:9000", aws.StringValue(client.Config.Endpoint))
+	assert.True(t, aws.BoolValue(client.Config.S3ForcePathStyle))
+	transport, ok := client.Config.HTTPClient.Transport.(*http.Transport)
+	require.True(t, ok, "expected an http.Transport, got %T", client.Config.HTTPClient.Transport)
+	assert.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+}
+
+func TestNewS3ClientEndpoint(t *testing.T) {
+	// an S3 compatible object store with a self-signed certificate, only
+	// serving the metering bucket using path-style requests
+	var requests []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.Host+r.URL.Path)
+		if r.URL.Path != "/metering" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := server.Listener.Addr().String()
+
+	headBucket := func(bucket string, endpoint S3Endpoint) error {
+		client := newS3Client("", endpoint, "")
+		client.Config.Credentials = credentials.NewStaticCredentials("access-key", "secret-key", "")
+		_, err := client.HeadBucket(&s3.HeadBucketInput{Bucket: aws.String(bucket)})
+		return err
+	}
+
+	err := headBucket("metering", S3Endpoint{URL: server.URL, ForcePathStyle: true, InsecureSkipVerify: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"HEAD " + host + "/metering"}, requests)
+
+	err = headBucket("missing", S3Endpoint{URL: server.URL, ForcePathStyle: true, InsecureSkipVerify: true})
+	assert.Error(t, err)
+
+	requests = nil
+	err = headBucket("metering", S3Endpoint{URL: server.URL, ForcePathStyle: true})
+	assert.Error(t, err, "expected the self-signed certificate to be rejected")
+	assert.Empty(t, requests)
+}
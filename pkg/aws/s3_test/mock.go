@@ -67,21 +67,50 @@ func (m *MockS3) GetObject(in *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
 }
 
 func (m *MockS3) ListObjectsV2(in *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	m.RLock()
+	defer m.RUnlock()
+
 	bucket, ok := m.buckets[*in.Bucket]
 	if !ok {
 		return nil, fmt.Errorf("bucket '%s' does not exist", *in.Bucket)
 	}
 
 	var objects []*s3.Object
+	var commonPrefixes []*s3.CommonPrefix
+	seenPrefixes := map[string]bool{}
 	for key := range bucket {
-		if strings.HasPrefix(key, *in.Prefix) {
-			objKey := key
-			obj := &s3.Object{Key: &objKey}
-			objects = append(objects, obj)
+		if !strings.HasPrefix(key, *in.Prefix) {
+			continue
+		}
+		// keys containing the delimiter after the prefix are rolled up
+		// into a common prefix
+		if in.Delimiter != nil && *in.Delimiter != "" {
+			rest := strings.TrimPrefix(key, *in.Prefix)
+			if i := strings.Index(rest, *in.Delimiter); i != -1 {
+				commonPrefix := *in.Prefix + rest[:i+len(*in.Delimiter)]
+				if !seenPrefixes[commonPrefix] {
+					seenPrefixes[commonPrefix] = true
+					commonPrefixes = append(commonPrefixes, &s3.CommonPrefix{Prefix: &commonPrefix})
+				}
+				continue
+			}
 		}
+		objKey := key
+		obj := &s3.Object{Key: &objKey}
+		objects = append(objects, obj)
 	}
 	out := new(s3.ListObjectsV2Output)
 	out.SetContents(objects)
+	out.SetCommonPrefixes(commonPrefixes)
 
 	return out, nil
 }
+
+func (m *MockS3) ListObjectsV2Pages(in *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool) error {
+	out, err := m.ListObjectsV2(in)
+	if err != nil {
+		return err
+	}
+	fn(out, true)
+	return nil
+}
@@ -0,0 +1,221 @@
+package azure
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// storageAPIVersion is the version of the Azure Storage REST API used.
+	storageAPIVersion = "2018-03-28"
+
+	// maxBlobResults is the maximum amount of blobs to be returned by a
+	// single list blobs API response.
+	maxBlobResults = 200
+)
+
+// BlobClient is a client for the Azure Blob Storage REST API, authenticating
+// with a storage account's access key.
+type BlobClient interface {
+	// ListBlobs returns every blob in the container with a name beginning
+	// with prefix.
+	ListBlobs(container, prefix string) ([]Blob, error)
+	// GetBlob returns the contents of a blob. If length is greater than
+	// zero, only the first length bytes are returned.
+	GetBlob(container, name string, length int64) (io.ReadCloser, error)
+	// CheckContainer returns an error if the container doesn't exist, or
+	// can't be accessed using the client's credentials.
+	CheckContainer(container string) error
+}
+
+// Blob is an object stored in a container.
+type Blob struct {
+	Name         string
+	LastModified time.Time
+}
+
+type blobClient struct {
+	httpClient *http.Client
+	account    string
+	key        []byte
+	// endpoint is the URL of the storage account's blob service.
+	endpoint string
+	now      func() time.Time
+}
+
+// NewBlobClient returns a BlobClient for the storage account, authenticating
+// with accountKey, which is base64 encoded as displayed in the Azure portal.
+func NewBlobClient(account, accountKey string) (BlobClient, error) {
+	key, err := base64.StdEncoding.DecodeString(accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access key for storage account %s: %v", account, err)
+	}
+	return &blobClient{
+		httpClient: http.DefaultClient,
+		account:    account,
+		key:        key,
+		endpoint:   fmt.Sprintf("https://%s.blob.core.windows.net", account),
+		now:        time.Now,
+	}, nil
+}
+
+type listBlobsResponse struct {
+	Blobs struct {
+		Blob []struct {
+			Name       string `xml:"Name"`
+			Properties struct {
+				LastModified string `xml:"Last-Modified"`
+			} `xml:"Properties"`
+		} `xml:"Blob"`
+	} `xml:"Blobs"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+func (c *blobClient) ListBlobs(container, prefix string) ([]Blob, error) {
+	var blobs []Blob
+	marker := ""
+	for {
+		query := url.Values{
+			"restype":    {"container"},
+			"comp":       {"list"},
+			"prefix":     {prefix},
+			"maxresults": {fmt.Sprintf("%d", maxBlobResults)},
+		}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		resp, err := c.do("GET", "/"+container, query, nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not list blobs in container %s: %v", container, err)
+		}
+		var list listBlobsResponse
+		err = xml.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not decode list of blobs in container %s: %v", container, err)
+		}
+		for _, blob := range list.Blobs.Blob {
+			lastModified, err := http.ParseTime(blob.Properties.LastModified)
+			if err != nil {
+				return nil, fmt.Errorf("invalid Last-Modified time %q of blob %s in container %s: %v", blob.Properties.LastModified, blob.Name, container, err)
+			}
+			blobs = append(blobs, Blob{Name: blob.Name, LastModified: lastModified})
+		}
+		if list.NextMarker == "" {
+			return blobs, nil
+		}
+		marker = list.NextMarker
+	}
+}
+
+func (c *blobClient) GetBlob(container, name string, length int64) (io.ReadCloser, error) {
+	header := make(http.Header)
+	if length > 0 {
+		header.Set("x-ms-range", fmt.Sprintf("bytes=0-%d", length-1))
+	}
+	resp, err := c.do("GET", "/"+container+"/"+name, nil, header)
+	if err != nil {
+		return nil, fmt.Errorf("could not get blob %s in container %s: %v", name, container, err)
+	}
+	return resp.Body, nil
+}
+
+func (c *blobClient) CheckContainer(container string) error {
+	resp, err := c.do("HEAD", "/"+container, url.Values{"restype": {"container"}}, nil)
+	if err != nil {
+		return fmt.Errorf("could not get properties of container %s: %v", container, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do performs a request against the blob service, returning an error if the
+// response status isn't successful.
+func (c *blobClient) do(method, path string, query url.Values, header http.Header) (*http.Response, error) {
+	u, err := url.Parse(c.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("x-ms-date", c.now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", storageAPIVersion)
+	req.Header.Set("Authorization", fmt.Sprintf("SharedKey %s:%s", c.account, c.signature(req)))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// signature returns the Shared Key signature of the request, as described in
+// https://docs.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key.
+func (c *blobClient) signature(req *http.Request) string {
+	// requests never have a body, so every standard header is empty
+	stringToSign := []string{
+		req.Method,
+		"", // Content-Encoding
+		"", // Content-Language
+		"", // Content-Length
+		"", // Content-MD5
+		"", // Content-Type
+		"", // Date
+		"", // If-Modified-Since
+		"", // If-Match
+		"", // If-None-Match
+		"", // If-Unmodified-Since
+		"", // Range
+	}
+
+	var msHeaders []string
+	for name := range req.Header {
+		if name := strings.ToLower(name); strings.HasPrefix(name, "x-ms-") {
+			msHeaders = append(msHeaders, name)
+		}
+	}
+	sort.Strings(msHeaders)
+	for _, name := range msHeaders {
+		stringToSign = append(stringToSign, name+":"+strings.TrimSpace(req.Header.Get(name)))
+	}
+
+	resource := "/" + c.account + req.URL.EscapedPath()
+	query := req.URL.Query()
+	var params []string
+	for name := range query {
+		params = append(params, name)
+	}
+	sort.Strings(params)
+	for _, name := range params {
+		values := query[name]
+		sort.Strings(values)
+		resource += "\n" + strings.ToLower(name) + ":" + strings.Join(values, ",")
+	}
+	stringToSign = append(stringToSign, resource)
+
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(strings.Join(stringToSign, "\n")))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,226 @@
+package azure
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// ManifestName is the name of the manifest written by each run of a
+	// Cost Management export.
+	ManifestName = "manifest.json"
+
+	// manifestDateLayout is the layout of the dates in a manifest's runInfo.
+	manifestDateLayout = "2006-01-02T15:04:05"
+
+	// headerReadLength is the number of bytes read from the start of an
+	// export to find it's header.
+	headerReadLength = 64 * 1024
+)
+
+// Manifest describes the files written by a single run of a Cost Management
+// export.
+type Manifest struct {
+	ManifestVersion string                 `json:"manifestVersion"`
+	ExportConfig    ManifestExportConfig   `json:"exportConfig"`
+	DeliveryConfig  ManifestDeliveryConfig `json:"deliveryConfig"`
+	RunInfo         ManifestRunInfo        `json:"runInfo"`
+	Blobs           []ManifestBlob         `json:"blobs"`
+}
+
+type ManifestExportConfig struct {
+	ExportName  string `json:"exportName"`
+	Type        string `json:"type"`
+	DataVersion string `json:"dataVersion"`
+}
+
+type ManifestDeliveryConfig struct {
+	FileFormat      string `json:"fileFormat"`
+	CompressionMode string `json:"compressionMode"`
+}
+
+type ManifestRunInfo struct {
+	RunID         string    `json:"runId"`
+	SubmittedTime time.Time `json:"submittedTime"`
+	StartDate     Date      `json:"startDate"`
+	EndDate       Date      `json:"endDate"`
+}
+
+type ManifestBlob struct {
+	BlobName     string `json:"blobName"`
+	ByteCount    int64  `json:"byteCount"`
+	DataRowCount int64  `json:"dataRowCount"`
+}
+
+// Date is a date in the format used by Cost Management export manifests.
+type Date struct {
+	time.Time
+}
+
+func (d *Date) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	t, err := time.Parse(manifestDateLayout, s)
+	if err != nil {
+		return err
+	}
+	d.Time = t.UTC()
+	return nil
+}
+
+// BillingPeriodStart returns the start of the period covered by the run.
+func (m Manifest) BillingPeriodStart() time.Time {
+	return m.RunInfo.StartDate.Time
+}
+
+// BillingPeriodEnd returns the end of the period covered by the run. Unlike
+// the manifest's endDate, which is the last day covered, the end is
+// exclusive.
+func (m Manifest) BillingPeriodEnd() time.Time {
+	return m.RunInfo.EndDate.Time.AddDate(0, 0, 1)
+}
+
+// DataDirectory returns the directory containing the run's data.
+func (m Manifest) DataDirectory() string {
+	if len(m.Blobs) == 0 {
+		return ""
+	}
+	return path.Dir(m.Blobs[0].BlobName)
+}
+
+type ManifestRetriever interface {
+	// RetrieveManifests returns the manifest of the newest run of the export
+	// for each billing period, ordered by the start of the billing period.
+	RetrieveManifests() ([]*Manifest, error)
+	// RetrieveColumns returns the names of the columns in the data written
+	// by the run.
+	RetrieveColumns(manifest *Manifest) ([]string, error)
+}
+
+type manifestRetriever struct {
+	client               BlobClient
+	container, directory string
+}
+
+// NewManifestRetriever returns a ManifestRetriever for the Cost Management
+// export written to the directory of the container.
+func NewManifestRetriever(client BlobClient, container, directory string) ManifestRetriever {
+	return &manifestRetriever{
+		client:    client,
+		container: container,
+		directory: directory,
+	}
+}
+
+func (r *manifestRetriever) RetrieveManifests() ([]*Manifest, error) {
+	// ensure that there is a slash at end of the directory
+	prefix := strings.TrimPrefix(r.directory, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	blobs, err := r.client.ListBlobs(r.container, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each run of an export writes a manifest in the following format:
+	// <directory>/YYYYMMDD-YYYYMMDD/<runId>/manifest.json
+	// Runs of a scheduled export write all data for the billing period
+	// to date, so only the newest run for a billing period is used.
+	newest := make(map[string]Blob)
+	for _, blob := range blobs {
+		runDir, name := path.Split(strings.TrimPrefix(blob.Name, prefix))
+		if name != ManifestName {
+			continue
+		}
+		billingPeriodDir, runID := path.Split(strings.TrimSuffix(runDir, "/"))
+		if runID == "" || billingPeriodDir == "" || strings.Count(billingPeriodDir, "/") != 1 {
+			continue
+		}
+		if existing, exists := newest[billingPeriodDir]; !exists || blob.LastModified.After(existing.LastModified) {
+			newest[billingPeriodDir] = blob
+		}
+	}
+
+	var manifests []*Manifest
+	for _, blob := range newest {
+		manifest, err := r.retrieveManifest(blob.Name)
+		if err != nil {
+			return nil, fmt.Errorf("can't get manifest from container '%s' with name '%s': %v", r.container, blob.Name, err)
+		}
+		if !strings.EqualFold(manifest.DeliveryConfig.FileFormat, "csv") {
+			return nil, fmt.Errorf("manifest '%s' in container '%s' has unsupported fileFormat %q, only Csv is supported", blob.Name, r.container, manifest.DeliveryConfig.FileFormat)
+		}
+		manifests = append(manifests, manifest)
+	}
+	sort.Slice(manifests, func(i, j int) bool {
+		return manifests[i].BillingPeriodStart().Before(manifests[j].BillingPeriodStart())
+	})
+	return manifests, nil
+}
+
+func (r *manifestRetriever) retrieveManifest(name string) (*Manifest, error) {
+	body, err := r.client.GetBlob(r.container, name, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(body).Decode(&manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+func (r *manifestRetriever) RetrieveColumns(manifest *Manifest) ([]string, error) {
+	if len(manifest.Blobs) == 0 {
+		return nil, fmt.Errorf("run %s of export %s has no data", manifest.RunInfo.RunID, manifest.ExportConfig.ExportName)
+	}
+	name := manifest.Blobs[0].BlobName
+	body, err := r.client.GetBlob(r.container, name, headerReadLength)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var reader io.Reader = body
+	if strings.EqualFold(manifest.DeliveryConfig.CompressionMode, "gzip") {
+		gzipReader, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("can't decompress blob '%s' in container '%s': %v", name, r.container, err)
+		}
+		reader = gzipReader
+	}
+	columns, err := readCSVHeader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("can't read header of blob '%s' in container '%s': %v", name, r.container, err)
+	}
+	return columns, nil
+}
+
+// readCSVHeader returns the column names in the first line of a CSV file.
+func readCSVHeader(r io.Reader) ([]string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return nil, err
+	}
+	// exports are written with a UTF-8 byte order mark
+	line = strings.TrimPrefix(line, "\ufeff")
+	columns, err := csv.NewReader(strings.NewReader(line)).Read()
+	if err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
@@ -0,0 +1,144 @@
+package azure
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const manifestTemplate = `{
+  "manifestVersion": "2024-04-01",
+  "exportConfig": {"exportName": "actual-cost", "type": "ActualCost", "dataVersion": "2021-10-01"},
+  "deliveryConfig": {"fileFormat": "Csv", "compressionMode": "%s"},
+  "runInfo": {
+    "runId": "%s",
+    "submittedTime": "2019-01-15T06:00:00.0000000Z",
+    "startDate": "2019-01-01T00:00:00",
+    "endDate": "2019-01-31T00:00:00"
+  },
+  "blobs": [{"blobName": "exports/actual-cost/20190101-20190131/%s/part_0_0001.csv", "byteCount": 100, "dataRowCount": 1}]
+}`
+
+type fakeBlobClient struct {
+	blobs map[string]Blob
+	data  map[string][]byte
+}
+
+func newFakeBlobClient() *fakeBlobClient {
+	return &fakeBlobClient{
+		blobs: make(map[string]Blob),
+		data:  make(map[string][]byte),
+	}
+}
+
+func (c *fakeBlobClient) put(name string, lastModified time.Time, data []byte) {
+	c.blobs[name] = Blob{Name: name, LastModified: lastModified}
+	c.data[name] = data
+}
+
+func (c *fakeBlobClient) ListBlobs(container, prefix string) ([]Blob, error) {
+	var blobs []Blob
+	for name, blob := range c.blobs {
+		if strings.HasPrefix(name, prefix) {
+			blobs = append(blobs, blob)
+		}
+	}
+	return blobs, nil
+}
+
+func (c *fakeBlobClient) GetBlob(container, name string, length int64) (io.ReadCloser, error) {
+	data, ok := c.data[name]
+	if !ok {
+		return nil, fmt.Errorf("blob %s not found", name)
+	}
+	if length > 0 && int64(len(data)) > length {
+		data = data[:length]
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (c *fakeBlobClient) CheckContainer(container string) error {
+	return nil
+}
+
+func TestManifestRetrieverRetrieveManifests(t *testing.T) {
+	client := newFakeBlobClient()
+	t0 := time.Date(2019, time.January, 15, 0, 0, 0, 0, time.UTC)
+	client.put("exports/actual-cost/20190101-20190131/run-1/manifest.json", t0, []byte(fmt.Sprintf(manifestTemplate, "None", "run-1", "run-1")))
+	client.put("exports/actual-cost/20190101-20190131/run-2/manifest.json", t0.Add(24*time.Hour), []byte(fmt.Sprintf(manifestTemplate, "None", "run-2", "run-2")))
+	client.put("exports/actual-cost/20190101-20190131/run-2/part_0_0001.csv", t0.Add(24*time.Hour), []byte("\ufeffInvoiceSectionName,\"Cost In USD\",Tags\nsection,1.5,\"{\"\"a\"\":\"\"b,c\"\"}\"\n"))
+	// manifests outside of a run directory are ignored
+	client.put("exports/actual-cost/manifest.json", t0, []byte("invalid"))
+
+	retriever := NewManifestRetriever(client, "billing", "/exports/actual-cost")
+	manifests, err := retriever.RetrieveManifests()
+	require.NoError(t, err)
+	require.Len(t, manifests, 1)
+
+	manifest := manifests[0]
+	assert.Equal(t, "run-2", manifest.RunInfo.RunID)
+	assert.Equal(t, "exports/actual-cost/20190101-20190131/run-2", manifest.DataDirectory())
+	assert.Equal(t, time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC), manifest.BillingPeriodStart())
+	assert.Equal(t, time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC), manifest.BillingPeriodEnd())
+
+	columns, err := retriever.RetrieveColumns(manifest)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"InvoiceSectionName", "Cost In USD", "Tags"}, columns)
+}
+
+func TestManifestRetrieverRetrieveColumnsGzip(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte("Date,Quantity\n2019-01-01,1\n"))
+	gz.Close()
+
+	client := newFakeBlobClient()
+	client.put("exports/actual-cost/20190101-20190131/run-1/part_0_0001.csv", time.Now(), buf.Bytes())
+	retriever := NewManifestRetriever(client, "billing", "exports/actual-cost")
+
+	columns, err := retriever.RetrieveColumns(&Manifest{
+		DeliveryConfig: ManifestDeliveryConfig{FileFormat: "Csv", CompressionMode: "Gzip"},
+		Blobs:          []ManifestBlob{{BlobName: "exports/actual-cost/20190101-20190131/run-1/part_0_0001.csv"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Date", "Quantity"}, columns)
+}
+
+func TestBlobClientListBlobs(t *testing.T) {
+	pages := map[string]string{
+		"": `<?xml version="1.0" encoding="utf-8"?>
+<EnumerationResults><Blobs><Blob><Name>exports/a.json</Name><Properties><Last-Modified>Tue, 15 Jan 2019 06:00:00 GMT</Last-Modified></Properties></Blob></Blobs><NextMarker>page-2</NextMarker></EnumerationResults>`,
+		"page-2": `<?xml version="1.0" encoding="utf-8"?>
+<EnumerationResults><Blobs><Blob><Name>exports/b.json</Name><Properties><Last-Modified>Wed, 16 Jan 2019 06:00:00 GMT</Last-Modified></Properties></Blob></Blobs><NextMarker /></EnumerationResults>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/billing", r.URL.Path)
+		assert.Equal(t, "list", r.URL.Query().Get("comp"))
+		assert.Equal(t, "exports/", r.URL.Query().Get("prefix"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey account:"))
+		assert.Equal(t, storageAPIVersion, r.Header.Get("x-ms-version"))
+		w.Write([]byte(pages[r.URL.Query().Get("marker")]))
+	}))
+	defer server.Close()
+
+	client, err := NewBlobClient("account", "a2V5")
+	require.NoError(t, err)
+	client.(*blobClient).endpoint = server.URL
+
+	blobs, err := client.ListBlobs("billing", "exports/")
+	require.NoError(t, err)
+	assert.Equal(t, []Blob{
+		{Name: "exports/a.json", LastModified: time.Date(2019, time.January, 15, 6, 0, 0, 0, time.UTC)},
+		{Name: "exports/b.json", LastModified: time.Date(2019, time.January, 16, 6, 0, 0, 0, time.UTC)},
+	}, blobs)
+}
@@ -0,0 +1,464 @@
+package bigquery
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	// DriverName is the name the BigQuery driver is registered with
+	// database/sql as.
+	DriverName = "bigquery"
+
+	defaultEndpoint = "https://www.googleapis.com/bigquery/v2"
+	bigQueryScope   = "https://www.googleapis.com/auth/bigquery"
+
+	// queryTimeout is how long BigQuery waits for a query to finish before
+	// responding to a request for it's results. Requests are repeated until
+	// the query finishes.
+	queryTimeout = 10 * time.Second
+
+	dateFormat = "2006-01-02"
+)
+
+func init() {
+	sql.Register(DriverName, &Driver{})
+}
+
+// Config configures the connection to BigQuery.
+type Config struct {
+	// ProjectID is the Google Cloud project queries are run and billed in.
+	ProjectID string
+	// Dataset is the dataset used for unqualified table names.
+	Dataset string
+	// Location is the location queries are run in, for example US or EU. If
+	// empty, it's determined by BigQuery from the tables used in the query.
+	Location string
+}
+
+// FormatDSN returns a DSN string which can be passed to sql.Open to connect
+// to BigQuery using the configuration.
+func (c Config) FormatDSN() string {
+	dsn := url.URL{
+		Scheme: DriverName,
+		Host:   c.ProjectID,
+		Path:   "/" + c.Dataset,
+	}
+	if c.Location != "" {
+		dsn.RawQuery = url.Values{"location": []string{c.Location}}.Encode()
+	}
+	return dsn.String()
+}
+
+func parseDSN(dsn string) (Config, error) {
+	dsnURL, err := url.Parse(dsn)
+	if err != nil {
+		return Config{}, fmt.Errorf("bigquery: malformed dsn: %v", err)
+	}
+	cfg := Config{
+		ProjectID: dsnURL.Host,
+		Dataset:   strings.Trim(dsnURL.Path, "/"),
+		Location:  dsnURL.Query().Get("location"),
+	}
+	if cfg.ProjectID == "" || cfg.Dataset == "" {
+		return Config{}, fmt.Errorf("bigquery: dsn must be in the form bigquery://project/dataset, got %q", dsn)
+	}
+	return cfg, nil
+}
+
+// Driver implements driver.Driver, running queries using BigQuery. It uses
+// Google Application Default Credentials to authenticate.
+type Driver struct{}
+
+func (d *Driver) Open(dsn string) (driver.Conn, error) {
+	cfg, err := parseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	client, err := google.DefaultClient(context.Background(), bigQueryScope)
+	if err != nil {
+		return nil, fmt.Errorf("bigquery: unable to get Google credentials: %v", err)
+	}
+	return &conn{
+		client:   client,
+		endpoint: defaultEndpoint,
+		cfg:      cfg,
+	}, nil
+}
+
+type conn struct {
+	client   *http.Client
+	endpoint string
+	cfg      Config
+}
+
+var (
+	_ driver.Conn           = &conn{}
+	_ driver.QueryerContext = &conn{}
+)
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("bigquery: prepared statements are not supported")
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, errors.New("bigquery: transactions are not supported")
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+type datasetReference struct {
+	ProjectID string `json:"projectId"`
+	DatasetID string `json:"datasetId"`
+}
+
+type jobReference struct {
+	JobID    string `json:"jobId"`
+	Location string `json:"location"`
+}
+
+type queryRequest struct {
+	Query          string            `json:"query"`
+	UseLegacySQL   bool              `json:"useLegacySql"`
+	DefaultDataset *datasetReference `json:"defaultDataset,omitempty"`
+	Location       string            `json:"location,omitempty"`
+	TimeoutMs      int64             `json:"timeoutMs,omitempty"`
+}
+
+type tableFieldSchema struct {
+	Name   string             `json:"name"`
+	Type   string             `json:"type"`
+	Mode   string             `json:"mode"`
+	Fields []tableFieldSchema `json:"fields"`
+}
+
+type tableSchema struct {
+	Fields []tableFieldSchema `json:"fields"`
+}
+
+type tableCell struct {
+	V interface{} `json:"v"`
+}
+
+type tableRow struct {
+	F []tableCell `json:"f"`
+}
+
+type errorProto struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// queryResponse is the response of both the jobs.query and
+// jobs.getQueryResults methods.
+type queryResponse struct {
+	JobReference jobReference `json:"jobReference"`
+	JobComplete  bool         `json:"jobComplete"`
+	Schema       *tableSchema `json:"schema"`
+	Rows         []tableRow   `json:"rows"`
+	PageToken    string       `json:"pageToken"`
+	Errors       []errorProto `json:"errors"`
+}
+
+type errorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// QueryContext runs the query using BigQuery, waiting for it to finish. If
+// ctx is cancelled before the query finishes, the query's job is cancelled.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) != 0 {
+		return nil, errors.New("bigquery: query arguments are not supported")
+	}
+	var resp queryResponse
+	err := c.do(ctx, "POST", c.projectURL("queries"), &queryRequest{
+		Query:        query,
+		UseLegacySQL: false,
+		DefaultDataset: &datasetReference{
+			ProjectID: c.cfg.ProjectID,
+			DatasetID: c.cfg.Dataset,
+		},
+		Location:  c.cfg.Location,
+		TimeoutMs: int64(queryTimeout / time.Millisecond),
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	job := resp.JobReference
+
+	for !resp.JobComplete {
+		if err := ctx.Err(); err != nil {
+			c.cancelJob(job)
+			return nil, err
+		}
+		resp, err = c.getQueryResults(ctx, job, "")
+		if err != nil {
+			if ctx.Err() != nil {
+				c.cancelJob(job)
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+	}
+	if len(resp.Errors) != 0 {
+		return nil, fmt.Errorf("bigquery: job %s failed: %s", job.JobID, resp.Errors[0].Message)
+	}
+
+	r := &rows{
+		ctx:  ctx,
+		conn: c,
+		job:  job,
+	}
+	r.setPage(resp)
+	return r, nil
+}
+
+func (c *conn) getQueryResults(ctx context.Context, job jobReference, pageToken string) (queryResponse, error) {
+	query := url.Values{
+		"timeoutMs": []string{strconv.FormatInt(int64(queryTimeout/time.Millisecond), 10)},
+	}
+	if job.Location != "" {
+		query.Set("location", job.Location)
+	}
+	if pageToken != "" {
+		query.Set("pageToken", pageToken)
+	}
+	var resp queryResponse
+	err := c.do(ctx, "GET", c.projectURL("queries", job.JobID)+"?"+query.Encode(), nil, &resp)
+	return resp, err
+}
+
+// cancelJob cancels the job. It's used when the context the query was
+// started with is done, so it uses a new context.
+func (c *conn) cancelJob(job jobReference) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cancelURL := c.projectURL("jobs", job.JobID, "cancel")
+	if job.Location != "" {
+		cancelURL += "?" + url.Values{"location": []string{job.Location}}.Encode()
+	}
+	c.do(ctx, "POST", cancelURL, nil, nil)
+}
+
+func (c *conn) projectURL(parts ...string) string {
+	escaped := make([]string, len(parts))
+	for i, part := range parts {
+		escaped[i] = url.PathEscape(part)
+	}
+	return fmt.Sprintf("%s/projects/%s/%s", c.endpoint, url.PathEscape(c.cfg.ProjectID), strings.Join(escaped, "/"))
+}
+
+// do makes a request to the BigQuery API, encoding body as the JSON request
+// body if it's non-nil, and decoding the response into out if it's non-nil.
+func (c *conn) do(ctx context.Context, method, url string, body, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return fmt.Errorf("bigquery: %v", err)
+	}
+	req = req.WithContext(ctx)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("bigquery: %v", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("bigquery: unable to read response: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var errResp errorResponse
+		if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Error.Message != "" {
+			return fmt.Errorf("bigquery: %s", errResp.Error.Message)
+		}
+		return fmt.Errorf("bigquery: unexpected response %s", resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("bigquery: unable to decode response: %v", err)
+	}
+	return nil
+}
+
+// rows implements driver.Rows, fetching the results of a finished query a
+// page at a time.
+type rows struct {
+	ctx  context.Context
+	conn *conn
+	job  jobReference
+
+	fields     []tableFieldSchema
+	page       []tableRow
+	pageToken  string
+	pageOffset int
+}
+
+var (
+	_ driver.Rows                           = &rows{}
+	_ driver.RowsColumnTypeDatabaseTypeName = &rows{}
+)
+
+func (r *rows) setPage(resp queryResponse) {
+	if resp.Schema != nil {
+		r.fields = resp.Schema.Fields
+	}
+	r.page = resp.Rows
+	r.pageToken = resp.PageToken
+	r.pageOffset = 0
+}
+
+func (r *rows) Columns() []string {
+	names := make([]string, len(r.fields))
+	for i, field := range r.fields {
+		names[i] = field.Name
+	}
+	return names
+}
+
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.fields[index].Type
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	for r.pageOffset >= len(r.page) {
+		if r.pageToken == "" {
+			return io.EOF
+		}
+		resp, err := r.conn.getQueryResults(r.ctx, r.job, r.pageToken)
+		if err != nil {
+			return err
+		}
+		r.setPage(resp)
+	}
+	row := r.page[r.pageOffset]
+	r.pageOffset++
+	for i := range dest {
+		var value interface{}
+		if i < len(row.F) {
+			value = row.F[i].V
+		}
+		v, err := convertValue(r.fields[i], value)
+		if err != nil {
+			return fmt.Errorf("bigquery: unable to convert column %s: %v", r.fields[i].Name, err)
+		}
+		dest[i] = v
+	}
+	return nil
+}
+
+// convertValue converts a value from the JSON representation BigQuery
+// returns, into the Go type used by the Presto driver for the same type.
+// Repeated fields are returned as slices, and records as maps.
+func convertValue(field tableFieldSchema, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if field.Mode == "REPEATED" {
+		cells, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an array, got %T", value)
+		}
+		elementField := field
+		elementField.Mode = ""
+		values := make([]interface{}, len(cells))
+		for i, cell := range cells {
+			// each element is wrapped in an object containing it as "v"
+			cellMap, ok := cell.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("expected an array element, got %T", cell)
+			}
+			v, err := convertValue(elementField, cellMap["v"])
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return values, nil
+	}
+	switch field.Type {
+	case "RECORD", "STRUCT":
+		record, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected a record, got %T", value)
+		}
+		cells, _ := record["f"].([]interface{})
+		values := make(map[string]interface{}, len(field.Fields))
+		for i, subField := range field.Fields {
+			var cellValue interface{}
+			if i < len(cells) {
+				if cellMap, ok := cells[i].(map[string]interface{}); ok {
+					cellValue = cellMap["v"]
+				}
+			}
+			v, err := convertValue(subField, cellValue)
+			if err != nil {
+				return nil, err
+			}
+			values[subField.Name] = v
+		}
+		return values, nil
+	}
+
+	// scalar values are always returned as strings
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a string, got %T", value)
+	}
+	switch field.Type {
+	case "BOOLEAN", "BOOL":
+		return strconv.ParseBool(s)
+	case "INTEGER", "INT64":
+		return strconv.ParseInt(s, 10, 64)
+	case "FLOAT", "FLOAT64":
+		return strconv.ParseFloat(s, 64)
+	case "TIMESTAMP":
+		// timestamps are the number of seconds since the epoch, in
+		// floating point
+		seconds, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+		whole, frac := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(math.Round(frac*1e6))*1e3).UTC(), nil
+	case "DATE":
+		return time.Parse(dateFormat, s)
+	default:
+		return s, nil
+	}
+}
@@ -0,0 +1,135 @@
+package bigquery
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/hive"
+)
+
+var testSchema = &tableSchema{Fields: []tableFieldSchema{
+	{Name: "namespace", Type: "STRING"},
+	{Name: "pod_request_cpu_core_seconds", Type: "FLOAT64"},
+	{Name: "period_start", Type: "TIMESTAMP"},
+	{Name: "labels", Type: "RECORD", Mode: "REPEATED", Fields: []tableFieldSchema{
+		{Name: "key", Type: "STRING"},
+		{Name: "value", Type: "STRING"},
+	}},
+}}
+
+func TestQueryContext(t *testing.T) {
+	var queryReq queryRequest
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+r.URL.Query().Get("pageToken"))
+		var resp queryResponse
+		resp.JobReference = jobReference{JobID: "job-id", Location: "US"}
+		switch {
+		case r.Method == "POST":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&queryReq))
+			// the job hasn't finished by the time the request times out
+		case r.URL.Query().Get("pageToken") == "":
+			resp.JobComplete = true
+			resp.Schema = testSchema
+			resp.PageToken = "page-2"
+			resp.Rows = []tableRow{{F: []tableCell{
+				{V: "default"},
+				{V: "60.5"},
+				{V: "1.5147648E9"},
+				{V: []interface{}{map[string]interface{}{"v": map[string]interface{}{"f": []interface{}{
+					map[string]interface{}{"v": "app"},
+					map[string]interface{}{"v": "web"},
+				}}}}},
+			}}}
+		default:
+			resp.JobComplete = true
+			resp.Schema = testSchema
+			resp.Rows = []tableRow{{F: []tableCell{{V: "kube-system"}, {V: nil}, {V: "1514768400"}, {V: []interface{}{}}}}}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	c := &conn{
+		client:   server.Client(),
+		endpoint: server.URL,
+		cfg:      Config{ProjectID: "my-project", Dataset: "metering"},
+	}
+	rows, err := c.QueryContext(context.Background(), "SELECT * FROM pod_cpu_usage", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM pod_cpu_usage", queryReq.Query)
+	assert.False(t, queryReq.UseLegacySQL)
+	assert.Equal(t, &datasetReference{ProjectID: "my-project", DatasetID: "metering"}, queryReq.DefaultDataset)
+	assert.Equal(t, []string{"namespace", "pod_request_cpu_core_seconds", "period_start", "labels"}, rows.Columns())
+
+	var results [][]driver.Value
+	for {
+		dest := make([]driver.Value, 4)
+		err := rows.Next(dest)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		results = append(results, dest)
+	}
+	assert.Equal(t, [][]driver.Value{
+		{"default", 60.5, time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC), []interface{}{map[string]interface{}{"key": "app", "value": "web"}}},
+		{"kube-system", nil, time.Date(2018, 1, 1, 1, 0, 0, 0, time.UTC), []interface{}{}},
+	}, results)
+	assert.Equal(t, []string{
+		"POST /projects/my-project/queries ",
+		"GET /projects/my-project/queries/job-id ",
+		"GET /projects/my-project/queries/job-id page-2",
+	}, requests, "expected the results to be polled until the job completed, and every page to be fetched")
+}
+
+func TestQueryContextError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"code": 400, "message": "Syntax error: Unexpected end of script at [1:7]"}}`))
+	}))
+	defer server.Close()
+
+	c := &conn{client: server.Client(), endpoint: server.URL, cfg: Config{ProjectID: "my-project", Dataset: "metering"}}
+	_, err := c.QueryContext(context.Background(), "SELECT", nil)
+	assert.EqualError(t, err, "bigquery: Syntax error: Unexpected end of script at [1:7]")
+}
+
+func TestParseDSN(t *testing.T) {
+	cfg := Config{ProjectID: "my-project", Dataset: "metering", Location: "EU"}
+	parsed, err := parseDSN(cfg.FormatDSN())
+	require.NoError(t, err)
+	assert.Equal(t, cfg, parsed)
+
+	_, err = parseDSN(Config{ProjectID: "my-project"}.FormatDSN())
+	assert.Error(t, err, "expected the dataset to be required")
+}
+
+func TestGenerateCreateTableSQL(t *testing.T) {
+	query, err := generateCreateTableSQL(hive.TableParameters{
+		Name: "report_default_namespace_cpu_request",
+		Columns: []hive.Column{
+			{Name: "namespace", Type: "string"},
+			{Name: "data_start", Type: "timestamp"},
+			{Name: "pod_request_cpu_core_seconds", Type: "double"},
+			{Name: "labels", Type: "map<string, string>"},
+			{Name: "ids", Type: "array<bigint>"},
+		},
+		Partitions:   []hive.Column{{Name: "dt", Type: "string"}},
+		IgnoreExists: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS `report_default_namespace_cpu_request` (`namespace` STRING, `data_start` TIMESTAMP, `pod_request_cpu_core_seconds` FLOAT64, `labels` ARRAY<STRUCT<key STRING, value STRING>>, `ids` ARRAY<INT64>, `dt` STRING)", query)
+
+	_, err = generateCreateTableSQL(hive.TableParameters{Name: "invalid", Columns: []hive.Column{{Name: "u", Type: "uniontype<int,string>"}}})
+	assert.Error(t, err)
+}
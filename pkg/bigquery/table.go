@@ -0,0 +1,160 @@
+package bigquery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/operator-metering/pkg/db"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+)
+
+// ColumnType converts a Hive column type into the equivalent BigQuery
+// standard SQL type. BigQuery has no map type, so maps are converted into
+// arrays of key/value structs.
+func ColumnType(hiveType string) (string, error) {
+	hiveType = strings.ToLower(strings.TrimSpace(hiveType))
+	switch {
+	case strings.HasPrefix(hiveType, "array<") && strings.HasSuffix(hiveType, ">"):
+		elementType, err := ColumnType(hiveType[len("array<") : len(hiveType)-1])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("ARRAY<%s>", elementType), nil
+	case strings.HasPrefix(hiveType, "map<") && strings.HasSuffix(hiveType, ">"):
+		typeParams := splitTypeParameters(hiveType[len("map<") : len(hiveType)-1])
+		if len(typeParams) != 2 {
+			return "", fmt.Errorf("invalid map type %q", hiveType)
+		}
+		keyType, err := ColumnType(typeParams[0])
+		if err != nil {
+			return "", err
+		}
+		valueType, err := ColumnType(typeParams[1])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("ARRAY<STRUCT<key %s, value %s>>", keyType, valueType), nil
+	case strings.HasPrefix(hiveType, "decimal"):
+		return "NUMERIC", nil
+	case strings.HasPrefix(hiveType, "varchar"), strings.HasPrefix(hiveType, "char"):
+		return "STRING", nil
+	}
+	switch hiveType {
+	case "string":
+		return "STRING", nil
+	case "tinyint", "smallint", "int", "integer", "bigint":
+		return "INT64", nil
+	case "float", "double", "real":
+		return "FLOAT64", nil
+	case "boolean":
+		return "BOOL", nil
+	case "timestamp":
+		return "TIMESTAMP", nil
+	case "date":
+		return "DATE", nil
+	case "binary":
+		return "BYTES", nil
+	default:
+		return "", fmt.Errorf("column type %q has no BigQuery equivalent", hiveType)
+	}
+}
+
+// splitTypeParameters splits the comma separated parameters of a generic
+// type, ignoring commas within nested types.
+func splitTypeParameters(params string) []string {
+	var split []string
+	depth, start := 0, 0
+	for i, c := range params {
+		switch c {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				split = append(split, params[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(split, params[start:])
+}
+
+// generateCreateTableSQL returns a BigQuery DDL statement creating the table.
+// BigQuery tables are stored by BigQuery, so the table's location and format
+// are ignored, and partition columns are created as regular columns.
+func generateCreateTableSQL(params hive.TableParameters) (string, error) {
+	columns := append(append([]hive.Column{}, params.Columns...), params.Partitions...)
+	columnsSQL := make([]string, len(columns))
+	for i, col := range columns {
+		colType, err := ColumnType(col.Type)
+		if err != nil {
+			return "", fmt.Errorf("invalid column %s: %v", col.Name, err)
+		}
+		columnsSQL[i] = fmt.Sprintf("`%s` %s", col.Name, colType)
+	}
+	ifNotExists := ""
+	if params.IgnoreExists {
+		ifNotExists = "IF NOT EXISTS"
+	}
+	return fmt.Sprintf("CREATE TABLE %s `%s` (%s)", ifNotExists, params.Name, strings.Join(columnsSQL, ", ")), nil
+}
+
+// ExecuteCreateTable creates the table in the connection's dataset.
+func ExecuteCreateTable(ctx context.Context, queryer db.Queryer, params hive.TableParameters) error {
+	query, err := generateCreateTableSQL(params)
+	if err != nil {
+		return err
+	}
+	_, err = queryer.QueryContext(ctx, query)
+	return err
+}
+
+// ExecuteDropTable drops the table, deleting it's data.
+func ExecuteDropTable(ctx context.Context, queryer db.Queryer, tableName string, ignoreNotExists bool) error {
+	ifExists := ""
+	if ignoreNotExists {
+		ifExists = "IF EXISTS"
+	}
+	_, err := queryer.QueryContext(ctx, fmt.Sprintf("DROP TABLE %s `%s`", ifExists, tableName))
+	return err
+}
+
+// ExecuteAddColumns adds columns to the table. BigQuery always adds columns
+// after the table's existing columns, including partition columns.
+func ExecuteAddColumns(ctx context.Context, queryer db.Queryer, tableName string, columns []hive.Column) error {
+	columnsSQL := make([]string, len(columns))
+	for i, col := range columns {
+		colType, err := ColumnType(col.Type)
+		if err != nil {
+			return fmt.Errorf("invalid column %s: %v", col.Name, err)
+		}
+		columnsSQL[i] = fmt.Sprintf("ADD COLUMN IF NOT EXISTS `%s` %s", col.Name, colType)
+	}
+	_, err := queryer.QueryContext(ctx, fmt.Sprintf("ALTER TABLE `%s` %s", tableName, strings.Join(columnsSQL, ", ")))
+	return err
+}
+
+// ExecuteDeleteWhere deletes the rows of the table where column is value.
+func ExecuteDeleteWhere(ctx context.Context, queryer db.Queryer, tableName, column, value string) error {
+	_, err := queryer.QueryContext(ctx, fmt.Sprintf("DELETE FROM `%s` WHERE `%s` = '%s'", tableName, column, value))
+	return err
+}
+
+// ExecuteGetTableSize returns the size in bytes of the data stored by the
+// table in the dataset.
+func ExecuteGetTableSize(ctx context.Context, queryer db.Queryer, dataset, tableName string) (int64, error) {
+	rows, err := queryer.QueryContext(ctx, fmt.Sprintf("SELECT size_bytes FROM `%s.__TABLES__` WHERE table_id = '%s'", dataset, tableName))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var size int64
+	if rows.Next() {
+		if err := rows.Scan(&size); err != nil {
+			return 0, err
+		}
+	}
+	return size, rows.Err()
+}
@@ -0,0 +1,360 @@
+package clickhouse
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DriverName is the name the ClickHouse driver is registered with
+	// database/sql as.
+	DriverName = "clickhouse"
+
+	dateFormat     = "2006-01-02"
+	dateTimeFormat = "2006-01-02 15:04:05"
+)
+
+func init() {
+	sql.Register(DriverName, &Driver{})
+}
+
+// Config configures the connection to ClickHouse.
+type Config struct {
+	// Address is the URL of ClickHouse's HTTP interface, for example
+	// http://clickhouse:8123.
+	Address string
+	// Database is the database used for unqualified table names.
+	Database string
+	Username string
+	Password string
+}
+
+// FormatDSN returns a DSN string which can be passed to sql.Open to connect
+// to ClickHouse using the configuration.
+func (c Config) FormatDSN() string {
+	dsn, err := url.Parse(c.Address)
+	if err != nil {
+		// parseDSN reports the error when the DSN is used
+		return c.Address
+	}
+	if c.Username != "" {
+		if c.Password != "" {
+			dsn.User = url.UserPassword(c.Username, c.Password)
+		} else {
+			dsn.User = url.User(c.Username)
+		}
+	}
+	dsn.Path = "/" + c.Database
+	return dsn.String()
+}
+
+func parseDSN(dsn string) (Config, error) {
+	dsnURL, err := url.Parse(dsn)
+	if err != nil {
+		return Config{}, fmt.Errorf("clickhouse: malformed dsn: %v", err)
+	}
+	if (dsnURL.Scheme != "http" && dsnURL.Scheme != "https") || dsnURL.Host == "" {
+		return Config{}, fmt.Errorf("clickhouse: dsn must be in the form http://[user[:password]@]host:port/database, got %q", dsn)
+	}
+	cfg := Config{
+		Address:  (&url.URL{Scheme: dsnURL.Scheme, Host: dsnURL.Host}).String(),
+		Database: strings.Trim(dsnURL.Path, "/"),
+	}
+	if dsnURL.User != nil {
+		cfg.Username = dsnURL.User.Username()
+		cfg.Password, _ = dsnURL.User.Password()
+	}
+	if cfg.Database == "" {
+		return Config{}, fmt.Errorf("clickhouse: dsn must contain a database, got %q", dsn)
+	}
+	return cfg, nil
+}
+
+// Driver implements driver.Driver, running queries using ClickHouse's HTTP
+// interface.
+type Driver struct{}
+
+func (d *Driver) Open(dsn string) (driver.Conn, error) {
+	cfg, err := parseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{
+		client: http.DefaultClient,
+		cfg:    cfg,
+	}, nil
+}
+
+type conn struct {
+	client *http.Client
+	cfg    Config
+}
+
+var (
+	_ driver.Conn           = &conn{}
+	_ driver.QueryerContext = &conn{}
+)
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("clickhouse: prepared statements are not supported")
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, errors.New("clickhouse: transactions are not supported")
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+type columnMeta struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// queryResponse is the JSONCompact format ClickHouse returns the results of
+// queries in.
+type queryResponse struct {
+	Meta []columnMeta    `json:"meta"`
+	Data [][]interface{} `json:"data"`
+}
+
+// QueryContext runs the query using ClickHouse, waiting for it to finish and
+// reading all of it's results. If ctx is cancelled before the query finishes,
+// the query is killed.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) != 0 {
+		return nil, errors.New("clickhouse: query arguments are not supported")
+	}
+	queryID, err := newQueryID()
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := c.do(ctx, queryID, query)
+	if err != nil {
+		if ctx.Err() != nil {
+			c.killQuery(queryID)
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	// statements which don't return results, such as INSERT and CREATE
+	// TABLE, have an empty response
+	var resp queryResponse
+	if len(bytes.TrimSpace(respBody)) != 0 {
+		decoder := json.NewDecoder(bytes.NewReader(respBody))
+		decoder.UseNumber()
+		if err := decoder.Decode(&resp); err != nil {
+			return nil, fmt.Errorf("clickhouse: unable to decode response: %v", err)
+		}
+	}
+	return &rows{columns: resp.Meta, data: resp.Data}, nil
+}
+
+// killQuery kills the query. It's used when the context the query was
+// started with is done, so it uses a new context.
+func (c *conn) killQuery(queryID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	killQueryID, err := newQueryID()
+	if err != nil {
+		return
+	}
+	c.do(ctx, killQueryID, fmt.Sprintf("KILL QUERY WHERE query_id = '%s' ASYNC", queryID))
+}
+
+// do sends the query to ClickHouse, returning the response body.
+func (c *conn) do(ctx context.Context, queryID, query string) ([]byte, error) {
+	params := url.Values{
+		"database": []string{c.cfg.Database},
+		"query_id": []string{queryID},
+		// queries without a FORMAT clause return their results as JSON,
+		// with 64 bit integers as numbers rather than strings.
+		"default_format": []string{"JSONCompact"},
+		"output_format_json_quote_64bit_integers": []string{"0"},
+	}
+	req, err := http.NewRequest("POST", c.cfg.Address+"/?"+params.Encode(), strings.NewReader(query))
+	if err != nil {
+		return nil, fmt.Errorf("clickhouse: %v", err)
+	}
+	req = req.WithContext(ctx)
+	if c.cfg.Username != "" {
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("clickhouse: %v", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("clickhouse: unable to read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// errors are returned as plain text
+		if msg := strings.TrimSpace(string(respBody)); msg != "" {
+			return nil, fmt.Errorf("clickhouse: %s", msg)
+		}
+		return nil, fmt.Errorf("clickhouse: unexpected response %s", resp.Status)
+	}
+	return respBody, nil
+}
+
+func newQueryID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("clickhouse: unable to generate query ID: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// rows implements driver.Rows, returning the results of a finished query.
+type rows struct {
+	columns []columnMeta
+	data    [][]interface{}
+	offset  int
+}
+
+var (
+	_ driver.Rows                           = &rows{}
+	_ driver.RowsColumnTypeDatabaseTypeName = &rows{}
+)
+
+func (r *rows) Columns() []string {
+	names := make([]string, len(r.columns))
+	for i, col := range r.columns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.columns[index].Type
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.offset >= len(r.data) {
+		return io.EOF
+	}
+	row := r.data[r.offset]
+	r.offset++
+	for i := range dest {
+		var value interface{}
+		if i < len(row) {
+			value = row[i]
+		}
+		v, err := convertValue(r.columns[i].Type, value)
+		if err != nil {
+			return fmt.Errorf("clickhouse: unable to convert column %s: %v", r.columns[i].Name, err)
+		}
+		dest[i] = v
+	}
+	return nil
+}
+
+// convertValue converts a value from the JSON representation ClickHouse
+// returns, into the Go type used by the Presto driver for the same type.
+// Arrays and tuples are returned as slices.
+func convertValue(colType string, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	colType, params := splitType(colType)
+	switch colType {
+	case "Nullable", "LowCardinality":
+		return convertValue(params, value)
+	case "Array":
+		elements, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an array, got %T", value)
+		}
+		values := make([]interface{}, len(elements))
+		for i, element := range elements {
+			v, err := convertValue(params, element)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return values, nil
+	case "Tuple":
+		elements, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected a tuple, got %T", value)
+		}
+		elementTypes := splitTypeParameters(params)
+		if len(elements) != len(elementTypes) {
+			return nil, fmt.Errorf("expected a tuple of %d elements, got %d", len(elementTypes), len(elements))
+		}
+		values := make([]interface{}, len(elements))
+		for i, element := range elements {
+			v, err := convertValue(elementTypes[i], element)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return values, nil
+	}
+
+	switch v := value.(type) {
+	case json.Number:
+		switch {
+		case strings.HasPrefix(colType, "Int"), strings.HasPrefix(colType, "UInt"):
+			return v.Int64()
+		default:
+			return v.Float64()
+		}
+	case string:
+		switch colType {
+		case "DateTime":
+			loc := time.UTC
+			if params != "" {
+				var err error
+				loc, err = time.LoadLocation(strings.Trim(params, "'"))
+				if err != nil {
+					return nil, err
+				}
+			}
+			t, err := time.ParseInLocation(dateTimeFormat, v, loc)
+			if err != nil {
+				return nil, err
+			}
+			return t.UTC(), nil
+		case "Date":
+			return time.Parse(dateFormat, v)
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+// splitType splits a type such as Nullable(String) into it's name and
+// parameters.
+func splitType(colType string) (name, params string) {
+	colType = strings.TrimSpace(colType)
+	open := strings.Index(colType, "(")
+	if open == -1 || !strings.HasSuffix(colType, ")") {
+		return colType, ""
+	}
+	return colType[:open], colType[open+1 : len(colType)-1]
+}
@@ -0,0 +1,127 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/hive"
+)
+
+func TestQueryContext(t *testing.T) {
+	var query string
+	var params map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		query = string(body)
+		params = r.URL.Query()
+		user, password, _ := r.BasicAuth()
+		assert.Equal(t, "metering", user)
+		assert.Equal(t, "secret", password)
+		w.Write([]byte(`{
+			"meta": [
+				{"name": "namespace", "type": "String"},
+				{"name": "pod_request_cpu_core_seconds", "type": "Nullable(Float64)"},
+				{"name": "period_start", "type": "DateTime('UTC')"},
+				{"name": "labels", "type": "Array(Tuple(String, String))"},
+				{"name": "pods", "type": "UInt64"}
+			],
+			"data": [
+				["default", 60.5, "2018-01-01 00:00:00", [["app", "web"]], 2],
+				["kube-system", null, "2018-01-01 01:00:00", [], 1]
+			],
+			"rows": 2
+		}`))
+	}))
+	defer server.Close()
+
+	c := &conn{client: server.Client(), cfg: Config{Address: server.URL, Database: "metering", Username: "metering", Password: "secret"}}
+	rows, err := c.QueryContext(context.Background(), "SELECT * FROM pod_cpu_usage", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM pod_cpu_usage", query)
+	assert.Equal(t, []string{"metering"}, params["database"])
+	assert.Equal(t, []string{"JSONCompact"}, params["default_format"])
+	assert.Equal(t, []string{"namespace", "pod_request_cpu_core_seconds", "period_start", "labels", "pods"}, rows.Columns())
+
+	var results [][]driver.Value
+	for {
+		dest := make([]driver.Value, 5)
+		err := rows.Next(dest)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		results = append(results, dest)
+	}
+	assert.Equal(t, [][]driver.Value{
+		{"default", 60.5, time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC), []interface{}{[]interface{}{"app", "web"}}, int64(2)},
+		{"kube-system", nil, time.Date(2018, 1, 1, 1, 0, 0, 0, time.UTC), []interface{}{}, int64(1)},
+	}, results)
+}
+
+func TestQueryContextNoResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	c := &conn{client: server.Client(), cfg: Config{Address: server.URL, Database: "metering"}}
+	rows, err := c.QueryContext(context.Background(), "INSERT INTO pod_cpu_usage VALUES ('default', 60.5)", nil)
+	require.NoError(t, err)
+	assert.Empty(t, rows.Columns())
+	assert.Equal(t, io.EOF, rows.Next(nil))
+}
+
+func TestQueryContextError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Code: 62, e.displayText() = DB::Exception: Syntax error: failed at position 7 (end of query)\n"))
+	}))
+	defer server.Close()
+
+	c := &conn{client: server.Client(), cfg: Config{Address: server.URL, Database: "metering"}}
+	_, err := c.QueryContext(context.Background(), "SELECT", nil)
+	assert.EqualError(t, err, "clickhouse: Code: 62, e.displayText() = DB::Exception: Syntax error: failed at position 7 (end of query)")
+}
+
+func TestParseDSN(t *testing.T) {
+	cfg := Config{Address: "https://clickhouse:8443", Database: "metering", Username: "metering", Password: "p@ss:word"}
+	parsed, err := parseDSN(cfg.FormatDSN())
+	require.NoError(t, err)
+	assert.Equal(t, cfg, parsed)
+
+	_, err = parseDSN(Config{Address: "http://clickhouse:8123"}.FormatDSN())
+	assert.Error(t, err, "expected the database to be required")
+	_, err = parseDSN(Config{Address: "clickhouse:9000", Database: "metering"}.FormatDSN())
+	assert.Error(t, err, "expected the address to be required to be a HTTP URL")
+}
+
+func TestGenerateCreateTableSQL(t *testing.T) {
+	query, err := generateCreateTableSQL(hive.TableParameters{
+		Name: "datasource_pod_request_cpu_cores",
+		Columns: []hive.Column{
+			{Name: "amount", Type: "double"},
+			{Name: "timestamp", Type: "timestamp"},
+			{Name: "timePrecision", Type: "double"},
+			{Name: "labels", Type: "map<string, string>"},
+			{Name: "cost", Type: "decimal(10, 2)"},
+		},
+		Partitions:   []hive.Column{{Name: "dt", Type: "string"}},
+		IgnoreExists: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS `datasource_pod_request_cpu_cores` (`amount` Nullable(Float64), `timestamp` Nullable(DateTime('UTC')), `timeprecision` Nullable(Float64), `labels` Array(Tuple(String, String)), `cost` Nullable(Decimal(10,2)), `dt` String) ENGINE = MergeTree() PARTITION BY (`dt`) ORDER BY tuple()", query)
+
+	_, err = generateCreateTableSQL(hive.TableParameters{Name: "invalid", Columns: []hive.Column{{Name: "u", Type: "uniontype<int,string>"}}})
+	assert.Error(t, err)
+}
+
+func TestQuoteString(t *testing.T) {
+	assert.Equal(t, `'it\'s a C:\\path'`, QuoteString(`it's a C:\path`))
+}
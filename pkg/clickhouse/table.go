@@ -0,0 +1,207 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/operator-metering/pkg/db"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+)
+
+// ColumnType converts a Hive column type into the equivalent ClickHouse type.
+// ClickHouse has no map type, so maps are converted into arrays of key/value
+// tuples.
+func ColumnType(hiveType string) (string, error) {
+	hiveType = strings.ToLower(strings.TrimSpace(hiveType))
+	switch {
+	case strings.HasPrefix(hiveType, "array<") && strings.HasSuffix(hiveType, ">"):
+		elementType, err := ColumnType(hiveType[len("array<") : len(hiveType)-1])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Array(%s)", elementType), nil
+	case strings.HasPrefix(hiveType, "map<") && strings.HasSuffix(hiveType, ">"):
+		typeParams := splitTypeParameters(hiveType[len("map<") : len(hiveType)-1])
+		if len(typeParams) != 2 {
+			return "", fmt.Errorf("invalid map type %q", hiveType)
+		}
+		keyType, err := ColumnType(typeParams[0])
+		if err != nil {
+			return "", err
+		}
+		valueType, err := ColumnType(typeParams[1])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Array(Tuple(%s, %s))", keyType, valueType), nil
+	case strings.HasPrefix(hiveType, "decimal"):
+		// Hive's default precision and scale are 10 and 0
+		if params := strings.TrimPrefix(hiveType, "decimal"); params != "" {
+			return "Decimal" + strings.Replace(params, " ", "", -1), nil
+		}
+		return "Decimal(10,0)", nil
+	case strings.HasPrefix(hiveType, "varchar"), strings.HasPrefix(hiveType, "char"):
+		return "String", nil
+	}
+	switch hiveType {
+	case "string", "binary":
+		return "String", nil
+	case "tinyint":
+		return "Int8", nil
+	case "smallint":
+		return "Int16", nil
+	case "int", "integer":
+		return "Int32", nil
+	case "bigint":
+		return "Int64", nil
+	case "float", "real":
+		return "Float32", nil
+	case "double":
+		return "Float64", nil
+	case "boolean":
+		return "UInt8", nil
+	case "timestamp":
+		return "DateTime('UTC')", nil
+	case "date":
+		return "Date", nil
+	default:
+		return "", fmt.Errorf("column type %q has no ClickHouse equivalent", hiveType)
+	}
+}
+
+// splitTypeParameters splits the comma separated parameters of a generic
+// type, ignoring commas within nested types.
+func splitTypeParameters(params string) []string {
+	var split []string
+	depth, start := 0, 0
+	for i, c := range params {
+		switch c {
+		case '<', '(':
+			depth++
+		case '>', ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				split = append(split, strings.TrimSpace(params[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(split, strings.TrimSpace(params[start:]))
+}
+
+// generateCreateTableSQL returns a ClickHouse DDL statement creating a
+// MergeTree table. ClickHouse stores the data of it's tables itself, so the
+// table's location and format are ignored. Partition columns are created as
+// regular columns which the table is partitioned by.
+//
+// Column names are lowercased, as Hive does, since queries expect the columns
+// of results to be lowercase. Columns other than partition columns and
+// arrays are nullable, as they are in Hive.
+func generateCreateTableSQL(params hive.TableParameters) (string, error) {
+	var columnsSQL, partitionsSQL []string
+	for _, col := range params.Columns {
+		colType, err := ColumnType(col.Type)
+		if err != nil {
+			return "", fmt.Errorf("invalid column %s: %v", col.Name, err)
+		}
+		if !strings.HasPrefix(colType, "Array(") {
+			colType = fmt.Sprintf("Nullable(%s)", colType)
+		}
+		columnsSQL = append(columnsSQL, fmt.Sprintf("`%s` %s", strings.ToLower(col.Name), colType))
+	}
+	for _, col := range params.Partitions {
+		colType, err := ColumnType(col.Type)
+		if err != nil {
+			return "", fmt.Errorf("invalid partition column %s: %v", col.Name, err)
+		}
+		name := fmt.Sprintf("`%s`", strings.ToLower(col.Name))
+		columnsSQL = append(columnsSQL, fmt.Sprintf("%s %s", name, colType))
+		partitionsSQL = append(partitionsSQL, name)
+	}
+
+	ifNotExists := ""
+	if params.IgnoreExists {
+		ifNotExists = "IF NOT EXISTS"
+	}
+	query := fmt.Sprintf("CREATE TABLE %s `%s` (%s) ENGINE = MergeTree()", ifNotExists, params.Name, strings.Join(columnsSQL, ", "))
+	if len(partitionsSQL) != 0 {
+		query += fmt.Sprintf(" PARTITION BY (%s)", strings.Join(partitionsSQL, ", "))
+	}
+	return query + " ORDER BY tuple()", nil
+}
+
+// ExecuteCreateTable creates the table in the connection's database.
+func ExecuteCreateTable(ctx context.Context, queryer db.Queryer, params hive.TableParameters) error {
+	query, err := generateCreateTableSQL(params)
+	if err != nil {
+		return err
+	}
+	_, err = queryer.QueryContext(ctx, query)
+	return err
+}
+
+// ExecuteDropTable drops the table, deleting it's data.
+func ExecuteDropTable(ctx context.Context, queryer db.Queryer, tableName string, ignoreNotExists bool) error {
+	ifExists := ""
+	if ignoreNotExists {
+		ifExists = "IF EXISTS"
+	}
+	_, err := queryer.QueryContext(ctx, fmt.Sprintf("DROP TABLE %s `%s`", ifExists, tableName))
+	return err
+}
+
+// ExecuteAddColumns adds nullable columns to the table after the column named
+// after, so they're positioned before any partition columns, which are
+// regular columns in ClickHouse.
+func ExecuteAddColumns(ctx context.Context, queryer db.Queryer, tableName string, columns []hive.Column, after string) error {
+	columnsSQL := make([]string, len(columns))
+	for i, col := range columns {
+		colType, err := ColumnType(col.Type)
+		if err != nil {
+			return fmt.Errorf("invalid column %s: %v", col.Name, err)
+		}
+		if !strings.HasPrefix(colType, "Array(") {
+			colType = fmt.Sprintf("Nullable(%s)", colType)
+		}
+		columnsSQL[i] = fmt.Sprintf("ADD COLUMN IF NOT EXISTS `%s` %s AFTER `%s`", strings.ToLower(col.Name), colType, strings.ToLower(after))
+		after = col.Name
+	}
+	_, err := queryer.QueryContext(ctx, fmt.Sprintf("ALTER TABLE `%s` %s", tableName, strings.Join(columnsSQL, ", ")))
+	return err
+}
+
+// ExecuteDropPartition deletes the rows of the partition of a table
+// partitioned by a single column. Dropping a partition which doesn't exist
+// does nothing.
+func ExecuteDropPartition(ctx context.Context, queryer db.Queryer, tableName, partitionValue string) error {
+	_, err := queryer.QueryContext(ctx, fmt.Sprintf("ALTER TABLE `%s` DROP PARTITION %s", tableName, QuoteString(partitionValue)))
+	return err
+}
+
+// ExecuteGetTableSize returns the size in bytes of the data stored by the
+// table in the connection's database.
+func ExecuteGetTableSize(ctx context.Context, queryer db.Queryer, tableName string) (int64, error) {
+	rows, err := queryer.QueryContext(ctx, fmt.Sprintf("SELECT sum(bytes_on_disk) FROM system.parts WHERE active AND database = currentDatabase() AND table = %s", QuoteString(tableName)))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var size int64
+	if rows.Next() {
+		if err := rows.Scan(&size); err != nil {
+			return 0, err
+		}
+	}
+	return size, rows.Err()
+}
+
+var stringLiteralReplacer = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// QuoteString returns a ClickHouse string literal of s. Backslashes are
+// escape characters in ClickHouse string literals, so they're escaped as
+// well as quotes.
+func QuoteString(s string) string {
+	return "'" + stringLiteralReplacer.Replace(s) + "'"
+}
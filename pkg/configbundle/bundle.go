@@ -0,0 +1,291 @@
+// Package configbundle exports the metering configuration of a cluster as a
+// single bundle, and imports bundles into another cluster, optionally
+// remapping the namespaces resources are created in.
+package configbundle
+
+import (
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbClientset "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned"
+)
+
+const (
+	BundleKind = "MeteringConfigBundle"
+
+	ReportDataSourceKind      = "ReportDataSource"
+	ReportGenerationQueryKind = "ReportGenerationQuery"
+	ScheduledReportKind       = "ScheduledReport"
+
+	ImportActionCreated = "Created"
+	ImportActionUpdated = "Updated"
+)
+
+// Bundle contains the ReportDataSources, ReportGenerationQueries and
+// ScheduledReports making up the metering configuration of a cluster. Only
+// the metadata and spec of each resource is included, so a bundle can be
+// imported into any cluster.
+type Bundle struct {
+	APIVersion              string                          `json:"apiVersion"`
+	Kind                    string                          `json:"kind"`
+	ReportDataSources       []cbTypes.ReportDataSource      `json:"reportDataSources,omitempty"`
+	ReportGenerationQueries []cbTypes.ReportGenerationQuery `json:"reportGenerationQueries,omitempty"`
+	ScheduledReports        []cbTypes.ScheduledReport       `json:"scheduledReports,omitempty"`
+}
+
+// ImportResult describes the change made to a single resource when
+// importing a bundle.
+type ImportResult struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Action    string `json:"action"`
+}
+
+// New returns a Bundle containing the resources, with their status and
+// cluster specific metadata removed.
+func New(dataSources []*cbTypes.ReportDataSource, queries []*cbTypes.ReportGenerationQuery, scheduledReports []*cbTypes.ScheduledReport) *Bundle {
+	b := &Bundle{
+		APIVersion: cbTypes.SchemeGroupVersion.String(),
+		Kind:       BundleKind,
+	}
+	for _, dataSource := range dataSources {
+		b.ReportDataSources = append(b.ReportDataSources, cbTypes.ReportDataSource{
+			TypeMeta:   typeMeta(ReportDataSourceKind),
+			ObjectMeta: objectMeta(dataSource.ObjectMeta),
+			Spec:       *dataSource.Spec.DeepCopy(),
+		})
+	}
+	for _, query := range queries {
+		b.ReportGenerationQueries = append(b.ReportGenerationQueries, cbTypes.ReportGenerationQuery{
+			TypeMeta:   typeMeta(ReportGenerationQueryKind),
+			ObjectMeta: objectMeta(query.ObjectMeta),
+			Spec:       *query.Spec.DeepCopy(),
+		})
+	}
+	for _, scheduledReport := range scheduledReports {
+		b.ScheduledReports = append(b.ScheduledReports, cbTypes.ScheduledReport{
+			TypeMeta:   typeMeta(ScheduledReportKind),
+			ObjectMeta: objectMeta(scheduledReport.ObjectMeta),
+			Spec:       *scheduledReport.Spec.DeepCopy(),
+		})
+	}
+	b.sort()
+	return b
+}
+
+// Validate returns an error if the bundle is not a metering configuration
+// bundle, or contains resources without a name or namespace.
+func (b *Bundle) Validate() error {
+	if b.Kind != BundleKind {
+		return fmt.Errorf("kind must be %s, got %q", BundleKind, b.Kind)
+	}
+	if b.APIVersion != cbTypes.SchemeGroupVersion.String() {
+		return fmt.Errorf("apiVersion must be %s, got %q", cbTypes.SchemeGroupVersion.String(), b.APIVersion)
+	}
+	for _, meta := range b.objectMetas() {
+		if meta.Name == "" || meta.Namespace == "" {
+			return fmt.Errorf("every resource must have a name and namespace, got %q in namespace %q", meta.Name, meta.Namespace)
+		}
+	}
+	return nil
+}
+
+// RemapNamespaces returns a copy of the bundle with the namespace of each
+// resource replaced by its value in mapping. Resources in namespaces not in
+// mapping keep their namespace.
+func (b *Bundle) RemapNamespaces(mapping map[string]string) *Bundle {
+	remapped := &Bundle{
+		APIVersion:              b.APIVersion,
+		Kind:                    b.Kind,
+		ReportDataSources:       make([]cbTypes.ReportDataSource, len(b.ReportDataSources)),
+		ReportGenerationQueries: make([]cbTypes.ReportGenerationQuery, len(b.ReportGenerationQueries)),
+		ScheduledReports:        make([]cbTypes.ScheduledReport, len(b.ScheduledReports)),
+	}
+	for i, dataSource := range b.ReportDataSources {
+		dataSource.DeepCopyInto(&remapped.ReportDataSources[i])
+	}
+	for i, query := range b.ReportGenerationQueries {
+		query.DeepCopyInto(&remapped.ReportGenerationQueries[i])
+	}
+	for i, scheduledReport := range b.ScheduledReports {
+		scheduledReport.DeepCopyInto(&remapped.ScheduledReports[i])
+	}
+	for _, meta := range remapped.objectMetas() {
+		if namespace, ok := mapping[meta.Namespace]; ok {
+			meta.Namespace = namespace
+		}
+	}
+	return remapped
+}
+
+// Namespaces returns the namespaces of the resources in the bundle.
+func (b *Bundle) Namespaces() []string {
+	seen := make(map[string]bool)
+	var namespaces []string
+	for _, meta := range b.objectMetas() {
+		if !seen[meta.Namespace] {
+			seen[meta.Namespace] = true
+			namespaces = append(namespaces, meta.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// Import creates each resource in the bundle, or updates it if it already
+// exists. ReportDataSources are imported first, followed by
+// ReportGenerationQueries and then ScheduledReports, so dependencies exist
+// before the resources using them.
+func Import(client cbClientset.Interface, b *Bundle) ([]ImportResult, error) {
+	var results []ImportResult
+	for _, dataSource := range b.ReportDataSources {
+		dataSource := dataSource
+		action, err := importReportDataSource(client, &dataSource)
+		if err != nil {
+			return results, fmt.Errorf("unable to import ReportDataSource %s/%s: %v", dataSource.Namespace, dataSource.Name, err)
+		}
+		results = append(results, ImportResult{Kind: ReportDataSourceKind, Namespace: dataSource.Namespace, Name: dataSource.Name, Action: action})
+	}
+	for _, query := range b.ReportGenerationQueries {
+		query := query
+		action, err := importReportGenerationQuery(client, &query)
+		if err != nil {
+			return results, fmt.Errorf("unable to import ReportGenerationQuery %s/%s: %v", query.Namespace, query.Name, err)
+		}
+		results = append(results, ImportResult{Kind: ReportGenerationQueryKind, Namespace: query.Namespace, Name: query.Name, Action: action})
+	}
+	for _, scheduledReport := range b.ScheduledReports {
+		scheduledReport := scheduledReport
+		action, err := importScheduledReport(client, &scheduledReport)
+		if err != nil {
+			return results, fmt.Errorf("unable to import ScheduledReport %s/%s: %v", scheduledReport.Namespace, scheduledReport.Name, err)
+		}
+		results = append(results, ImportResult{Kind: ScheduledReportKind, Namespace: scheduledReport.Namespace, Name: scheduledReport.Name, Action: action})
+	}
+	return results, nil
+}
+
+func importReportDataSource(client cbClientset.Interface, dataSource *cbTypes.ReportDataSource) (string, error) {
+	dataSources := client.MeteringV1alpha1().ReportDataSources(dataSource.Namespace)
+	existing, err := dataSources.Get(dataSource.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = dataSources.Create(dataSource)
+		return ImportActionCreated, err
+	}
+	if err != nil {
+		return "", err
+	}
+	existing = existing.DeepCopy()
+	existing.Labels = dataSource.Labels
+	existing.Annotations = dataSource.Annotations
+	existing.Spec = dataSource.Spec
+	_, err = dataSources.Update(existing)
+	return ImportActionUpdated, err
+}
+
+func importReportGenerationQuery(client cbClientset.Interface, query *cbTypes.ReportGenerationQuery) (string, error) {
+	queries := client.MeteringV1alpha1().ReportGenerationQueries(query.Namespace)
+	existing, err := queries.Get(query.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = queries.Create(query)
+		return ImportActionCreated, err
+	}
+	if err != nil {
+		return "", err
+	}
+	existing = existing.DeepCopy()
+	existing.Labels = query.Labels
+	existing.Annotations = query.Annotations
+	existing.Spec = query.Spec
+	_, err = queries.Update(existing)
+	return ImportActionUpdated, err
+}
+
+func importScheduledReport(client cbClientset.Interface, scheduledReport *cbTypes.ScheduledReport) (string, error) {
+	scheduledReports := client.MeteringV1alpha1().ScheduledReports(scheduledReport.Namespace)
+	existing, err := scheduledReports.Get(scheduledReport.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = scheduledReports.Create(scheduledReport)
+		return ImportActionCreated, err
+	}
+	if err != nil {
+		return "", err
+	}
+	existing = existing.DeepCopy()
+	existing.Labels = scheduledReport.Labels
+	existing.Annotations = scheduledReport.Annotations
+	existing.Spec = scheduledReport.Spec
+	_, err = scheduledReports.Update(existing)
+	return ImportActionUpdated, err
+}
+
+// objectMetas returns a pointer to the metadata of every resource in the
+// bundle.
+func (b *Bundle) objectMetas() []*metav1.ObjectMeta {
+	var metas []*metav1.ObjectMeta
+	for i := range b.ReportDataSources {
+		metas = append(metas, &b.ReportDataSources[i].ObjectMeta)
+	}
+	for i := range b.ReportGenerationQueries {
+		metas = append(metas, &b.ReportGenerationQueries[i].ObjectMeta)
+	}
+	for i := range b.ScheduledReports {
+		metas = append(metas, &b.ScheduledReports[i].ObjectMeta)
+	}
+	return metas
+}
+
+// sort orders the resources of each kind by namespace and name, so exporting
+// the same configuration always produces the same bundle.
+func (b *Bundle) sort() {
+	sort.Slice(b.ReportDataSources, func(i, j int) bool {
+		return lessObjectMeta(b.ReportDataSources[i].ObjectMeta, b.ReportDataSources[j].ObjectMeta)
+	})
+	sort.Slice(b.ReportGenerationQueries, func(i, j int) bool {
+		return lessObjectMeta(b.ReportGenerationQueries[i].ObjectMeta, b.ReportGenerationQueries[j].ObjectMeta)
+	})
+	sort.Slice(b.ScheduledReports, func(i, j int) bool {
+		return lessObjectMeta(b.ScheduledReports[i].ObjectMeta, b.ScheduledReports[j].ObjectMeta)
+	})
+}
+
+func lessObjectMeta(a, b metav1.ObjectMeta) bool {
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+func typeMeta(kind string) metav1.TypeMeta {
+	return metav1.TypeMeta{
+		APIVersion: cbTypes.SchemeGroupVersion.String(),
+		Kind:       kind,
+	}
+}
+
+// objectMeta returns the metadata of a resource, without any fields set by
+// the cluster it was created in.
+func objectMeta(meta metav1.ObjectMeta) metav1.ObjectMeta {
+	exported := metav1.ObjectMeta{
+		Name:      meta.Name,
+		Namespace: meta.Namespace,
+	}
+	if len(meta.Labels) != 0 {
+		exported.Labels = make(map[string]string, len(meta.Labels))
+		for k, v := range meta.Labels {
+			exported.Labels[k] = v
+		}
+	}
+	if len(meta.Annotations) != 0 {
+		exported.Annotations = make(map[string]string, len(meta.Annotations))
+		for k, v := range meta.Annotations {
+			exported.Annotations[k] = v
+		}
+	}
+	return exported
+}
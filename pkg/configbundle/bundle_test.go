@@ -0,0 +1,80 @@
+package configbundle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/fake"
+)
+
+func testQuery(namespace, name, query string) *cbTypes.ReportGenerationQuery {
+	return &cbTypes.ReportGenerationQuery{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       namespace,
+			ResourceVersion: "10",
+			UID:             "1234",
+			Labels:          map[string]string{"team": "a"},
+		},
+		Spec: cbTypes.ReportGenerationQuerySpec{
+			Query: query,
+		},
+		Status: cbTypes.ReportGenerationQueryStatus{
+			ViewName: "view_" + name,
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	bundle := New(nil, []*cbTypes.ReportGenerationQuery{
+		testQuery("staging", "b", "SELECT 2"),
+		testQuery("staging", "a", "SELECT 1"),
+	}, nil)
+
+	require.NoError(t, bundle.Validate())
+	require.Len(t, bundle.ReportGenerationQueries, 2)
+	query := bundle.ReportGenerationQueries[0]
+	assert.Equal(t, "a", query.Name, "expected resources to be sorted by name")
+	assert.Equal(t, ReportGenerationQueryKind, query.Kind)
+	assert.Equal(t, map[string]string{"team": "a"}, query.Labels)
+	assert.Empty(t, query.ResourceVersion, "expected cluster specific metadata to be removed")
+	assert.Empty(t, query.UID, "expected cluster specific metadata to be removed")
+	assert.Empty(t, query.Status.ViewName, "expected status to be removed")
+}
+
+func TestRemapNamespaces(t *testing.T) {
+	bundle := New(nil, []*cbTypes.ReportGenerationQuery{
+		testQuery("staging", "a", "SELECT 1"),
+		testQuery("other", "b", "SELECT 2"),
+	}, nil)
+
+	remapped := bundle.RemapNamespaces(map[string]string{"staging": "prod"})
+	assert.Equal(t, []string{"other", "prod"}, remapped.Namespaces())
+	assert.Equal(t, []string{"other", "staging"}, bundle.Namespaces(), "expected the original bundle to be unchanged")
+}
+
+func TestImport(t *testing.T) {
+	existing := testQuery("prod", "a", "SELECT 0")
+	client := fake.NewSimpleClientset(existing)
+
+	bundle := New(nil, []*cbTypes.ReportGenerationQuery{
+		testQuery("staging", "a", "SELECT 1"),
+		testQuery("staging", "b", "SELECT 2"),
+	}, nil).RemapNamespaces(map[string]string{"staging": "prod"})
+
+	results, err := Import(client, bundle)
+	require.NoError(t, err)
+	assert.Equal(t, []ImportResult{
+		{Kind: ReportGenerationQueryKind, Namespace: "prod", Name: "a", Action: ImportActionUpdated},
+		{Kind: ReportGenerationQueryKind, Namespace: "prod", Name: "b", Action: ImportActionCreated},
+	}, results)
+
+	updated, err := client.MeteringV1alpha1().ReportGenerationQueries("prod").Get("a", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT 1", updated.Spec.Query)
+	assert.Equal(t, "view_a", updated.Status.ViewName, "expected the status of existing resources to be kept")
+}
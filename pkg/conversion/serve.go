@@ -0,0 +1,103 @@
+package conversion
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// maxRequestSize limits the size of ConversionReviews, which can contain a
+// list of objects.
+const maxRequestSize = 30 * 1024 * 1024
+
+// Serve returns a http.HandlerFunc decoding the ConversionReview sent by
+// the API server, and responding with it's objects converted using the
+// conversion functions registered in scheme.
+func Serve(scheme *runtime.Scheme) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("unable to read request: %v", err), http.StatusBadRequest)
+			return
+		}
+		var review ConversionReview
+		if err := json.Unmarshal(body, &review); err != nil {
+			http.Error(w, fmt.Sprintf("unable to decode ConversionReview: %v", err), http.StatusBadRequest)
+			return
+		}
+		if review.Request == nil {
+			http.Error(w, "ConversionReview has no request", http.StatusBadRequest)
+			return
+		}
+
+		resp := convertObjects(scheme, review.Request)
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(ConversionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: APIVersion, Kind: Kind},
+			Response: resp,
+		})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("unable to encode ConversionReview: %v", err), http.StatusInternalServerError)
+		}
+	}
+}
+
+// convertObjects converts each object in req, failing the whole request if
+// any object can't be converted.
+func convertObjects(scheme *runtime.Scheme, req *ConversionRequest) *ConversionResponse {
+	resp := &ConversionResponse{UID: req.UID}
+	converted := make([]runtime.RawExtension, len(req.Objects))
+	for i, obj := range req.Objects {
+		raw, err := Convert(scheme, obj.Raw, req.DesiredAPIVersion)
+		if err != nil {
+			resp.Result = metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: err.Error(),
+			}
+			return resp
+		}
+		converted[i] = runtime.RawExtension{Raw: raw}
+	}
+	resp.ConvertedObjects = converted
+	resp.Result = metav1.Status{Status: metav1.StatusSuccess}
+	return resp
+}
+
+// Convert converts the JSON encoded object to desiredAPIVersion, using the
+// conversion functions registered in scheme, returning the JSON encoded
+// result.
+func Convert(scheme *runtime.Scheme, raw []byte, desiredAPIVersion string) ([]byte, error) {
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, fmt.Errorf("unable to decode object: %v", err)
+	}
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+	gv, err := schema.ParseGroupVersion(desiredAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+	gvk := typeMeta.GroupVersionKind()
+	obj, err := scheme.New(gvk)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert %s: %v", gvk, err)
+	}
+	if err := json.Unmarshal(raw, obj); err != nil {
+		return nil, fmt.Errorf("unable to decode %s: %v", gvk.Kind, err)
+	}
+	converted, err := scheme.ConvertToVersion(obj, gv)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert %s to %s: %v", gvk.Kind, desiredAPIVersion, err)
+	}
+	return json.Marshal(converted)
+}
@@ -0,0 +1,114 @@
+package conversion
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha2"
+)
+
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+	return scheme
+}
+
+func TestServe(t *testing.T) {
+	handler := Serve(newTestScheme(t))
+	review := ConversionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: APIVersion, Kind: Kind},
+		Request: &ConversionRequest{
+			UID:               "1234",
+			DesiredAPIVersion: "metering.openshift.io/v1alpha2",
+			Objects: []runtime.RawExtension{
+				{Raw: []byte(`{
+					"apiVersion": "metering.openshift.io/v1alpha1",
+					"kind": "ScheduledReport",
+					"metadata": {"name": "daily", "namespace": "metering"},
+					"spec": {
+						"generationQuery": "namespace-cpu-request",
+						"schedule": {"period": "daily", "daily": {"hour": 1}},
+						"inputs": [{"name": "Limit", "value": "10"}]
+					}
+				}`)},
+				{Raw: []byte(`{
+					"apiVersion": "metering.openshift.io/v1alpha2",
+					"kind": "Report",
+					"metadata": {"name": "report", "namespace": "metering"},
+					"spec": {"generationQuery": "namespace-cpu-request"}
+				}`)},
+			},
+		},
+	}
+	body, err := json.Marshal(review)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("POST", "/convert", strings.NewReader(string(body))))
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp ConversionReview
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	require.NotNil(t, resp.Response)
+	assert.Equal(t, "1234", string(resp.Response.UID))
+	require.Equal(t, metav1.StatusSuccess, resp.Response.Result.Status, resp.Response.Result.Message)
+	require.Len(t, resp.Response.ConvertedObjects, 2)
+
+	assert.JSONEq(t, `{
+		"apiVersion": "metering.openshift.io/v1alpha2",
+		"kind": "ScheduledReport",
+		"metadata": {"name": "daily", "namespace": "metering", "creationTimestamp": null},
+		"spec": {
+			"generationQuery": "namespace-cpu-request",
+			"schedule": {"daily": {"hour": 1}},
+			"inputs": [{"name": "Limit", "value": "10"}]
+		},
+		"status": {"tableName": ""}
+	}`, string(resp.Response.ConvertedObjects[0].Raw))
+	// objects already in the desired version are unchanged
+	assert.JSONEq(t, string(review.Request.Objects[1].Raw), string(resp.Response.ConvertedObjects[1].Raw))
+}
+
+func TestConvertErrors(t *testing.T) {
+	scheme := newTestScheme(t)
+	tests := map[string]string{
+		"invalid JSON":   `{`,
+		"unknown kind":   `{"apiVersion": "metering.openshift.io/v1alpha1", "kind": "Unknown"}`,
+		"invalid object": `{"apiVersion": "metering.openshift.io/v1alpha1", "kind": "Report", "spec": []}`,
+	}
+	for name, raw := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Convert(scheme, []byte(raw), "metering.openshift.io/v1alpha2")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestServeInvalidRequests(t *testing.T) {
+	handler := Serve(newTestScheme(t))
+	tests := map[string]struct {
+		method string
+		body   string
+		code   int
+	}{
+		"GET":             {method: "GET", code: http.StatusMethodNotAllowed},
+		"invalid JSON":    {method: "POST", body: "{", code: http.StatusBadRequest},
+		"missing request": {method: "POST", body: `{"kind": "ConversionReview"}`, code: http.StatusBadRequest},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(tt.method, "/convert", strings.NewReader(tt.body)))
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package conversion
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// The following types match the apiextensions.k8s.io/v1beta1
+// ConversionReview sent to CustomResourceDefinition conversion webhooks.
+
+const (
+	APIVersion = "apiextensions.k8s.io/v1beta1"
+	Kind       = "ConversionReview"
+)
+
+type ConversionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *ConversionRequest  `json:"request,omitempty"`
+	Response        *ConversionResponse `json:"response,omitempty"`
+}
+
+type ConversionRequest struct {
+	UID types.UID `json:"uid"`
+	// DesiredAPIVersion is the version Objects are converted to.
+	DesiredAPIVersion string                 `json:"desiredAPIVersion"`
+	Objects           []runtime.RawExtension `json:"objects"`
+}
+
+type ConversionResponse struct {
+	UID types.UID `json:"uid"`
+	// ConvertedObjects contains each of the request's Objects, in the same
+	// order, converted to DesiredAPIVersion.
+	ConvertedObjects []runtime.RawExtension `json:"convertedObjects"`
+	// Result is a Success status if every object was converted.
+	Result metav1.Status `json:"result"`
+}
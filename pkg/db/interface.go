@@ -1,25 +1,69 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
 type Queryer interface {
 	Query(query string, args ...interface{}) (*sql.Rows, error)
+	// QueryContext executes the query like Query, but cancels it if ctx is
+	// cancelled or it's deadline is exceeded before the query finishes.
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 	Close() error
 }
 
+// QueryLogging controls if the queries run by loggingQueryers are logged,
+// and can be changed while they're running.
+type QueryLogging struct {
+	enabled int32
+}
+
+func NewQueryLogging(enabled bool) *QueryLogging {
+	logging := &QueryLogging{}
+	logging.SetEnabled(enabled)
+	return logging
+}
+
+func (logging *QueryLogging) Enabled() bool {
+	return atomic.LoadInt32(&logging.enabled) == 1
+}
+
+func (logging *QueryLogging) SetEnabled(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&logging.enabled, value)
+}
+
+type loggerKey struct{}
+
+// WithLogger returns a context carrying logger, which the queries run using
+// the context are logged with, so they include the fields identifying what
+// they were run for.
+func WithLogger(ctx context.Context, logger log.FieldLogger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// lastQueryID is incremented to give each query logged an ID, which
+// identifies the log messages about it.
+var lastQueryID uint64
+
 type loggingQueryer struct {
 	queryer    Queryer
 	logger     log.FieldLogger
-	logQueries bool
+	logQueries *QueryLogging
 }
 
-func NewLoggingQueryer(queryer Queryer, logger log.FieldLogger, logQueries bool) *loggingQueryer {
+func NewLoggingQueryer(queryer Queryer, logger log.FieldLogger, logQueries *QueryLogging) *loggingQueryer {
 	return &loggingQueryer{
 		queryer:    queryer,
 		logger:     logger,
@@ -28,11 +72,46 @@ func NewLoggingQueryer(queryer Queryer, logger log.FieldLogger, logQueries bool)
 }
 
 func (loggingQueryer *loggingQueryer) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	if loggingQueryer.logQueries {
-		margs := argsString(args...)
-		loggingQueryer.logger.Debugf("QUERY: %s [%s]", query, margs)
+	logger := loggingQueryer.logQuery(loggingQueryer.logger, query, args...)
+	start := time.Now()
+	rows, err := loggingQueryer.queryer.Query(query, args...)
+	loggingQueryer.logQueryResult(logger, start, err)
+	return rows, err
+}
+
+func (loggingQueryer *loggingQueryer) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	logger := loggingQueryer.logger
+	if ctxLogger, ok := ctx.Value(loggerKey{}).(log.FieldLogger); ok {
+		logger = ctxLogger
+	}
+	logger = loggingQueryer.logQuery(logger, query, args...)
+	start := time.Now()
+	rows, err := loggingQueryer.queryer.QueryContext(ctx, query, args...)
+	loggingQueryer.logQueryResult(logger, start, err)
+	return rows, err
+}
+
+// logQuery logs the query if query logging is enabled, and returns a logger
+// including the query's ID, or nil if it isn't logged.
+func (loggingQueryer *loggingQueryer) logQuery(logger log.FieldLogger, query string, args ...interface{}) log.FieldLogger {
+	if !loggingQueryer.logQueries.Enabled() {
+		return nil
+	}
+	logger = logger.WithField("queryID", strconv.FormatUint(atomic.AddUint64(&lastQueryID, 1), 10))
+	margs := argsString(args...)
+	logger.Debugf("QUERY: %s [%s]", query, margs)
+	return logger
+}
+
+func (loggingQueryer *loggingQueryer) logQueryResult(logger log.FieldLogger, start time.Time, err error) {
+	if logger == nil {
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Debugf("query failed after %s", time.Since(start))
+		return
 	}
-	return loggingQueryer.queryer.Query(query, args...)
+	logger.Debugf("query returned results after %s", time.Since(start))
 }
 
 func (loggingQueryer *loggingQueryer) Close() error {
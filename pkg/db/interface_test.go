@@ -0,0 +1,56 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type erroringQueryer struct{}
+
+func (erroringQueryer) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errors.New("query failed")
+}
+
+func (erroringQueryer) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errors.New("query failed")
+}
+
+func (erroringQueryer) Close() error { return nil }
+
+func TestLoggingQueryerContextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New()
+	logger.Out = &buf
+	logger.Formatter = &log.JSONFormatter{DisableTimestamp: true}
+	logger.Level = log.DebugLevel
+
+	logging := NewQueryLogging(false)
+	queryer := NewLoggingQueryer(erroringQueryer{}, logger, logging)
+	ctx := WithLogger(context.Background(), logger.WithField("name", "test-report"))
+
+	_, err := queryer.QueryContext(ctx, "SELECT 1")
+	require.Error(t, err)
+	assert.Empty(t, buf.String(), "expected queries not to be logged when query logging is disabled")
+
+	logging.SetEnabled(true)
+	_, err = queryer.QueryContext(ctx, "SELECT 1")
+	require.Error(t, err)
+
+	decoder := json.NewDecoder(&buf)
+	var query, result map[string]interface{}
+	require.NoError(t, decoder.Decode(&query))
+	require.NoError(t, decoder.Decode(&result))
+	assert.Equal(t, "QUERY: SELECT 1 []", query["msg"])
+	assert.Equal(t, "test-report", query["name"])
+	assert.NotEmpty(t, query["queryID"])
+	assert.Equal(t, query["queryID"], result["queryID"], "expected the query and it's result to have the same queryID")
+	assert.Equal(t, "query failed", result["error"])
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/operator-framework/operator-metering/pkg/tracing"
+)
+
+// maxTracedStatementLength is the most of a query recorded in it's span, as
+// generated queries inserting metrics can be megabytes long.
+const maxTracedStatementLength = 4096
+
+type tracingQueryer struct {
+	queryer Queryer
+	system  string
+}
+
+// NewTracingQueryer returns a Queryer which records the queries run using
+// QueryContext in spans, if the context is part of a trace. system is the
+// name of the database, such as presto or hive, which the spans are named
+// after.
+func NewTracingQueryer(queryer Queryer, system string) *tracingQueryer {
+	return &tracingQueryer{
+		queryer: queryer,
+		system:  system,
+	}
+}
+
+func (tracingQueryer *tracingQueryer) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tracingQueryer.queryer.Query(query, args...)
+}
+
+// QueryContext runs the query in a span, which ends when the query returns
+// it's first results, rather than when the rows are read.
+func (tracingQueryer *tracingQueryer) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	statement := query
+	if len(statement) > maxTracedStatementLength {
+		statement = statement[:maxTracedStatementLength]
+	}
+	ctx, span := tracing.StartClientSpan(ctx, tracingQueryer.system+".query",
+		tracing.String("db.system", tracingQueryer.system),
+		tracing.String("db.statement", statement),
+	)
+	rows, err := tracingQueryer.queryer.QueryContext(ctx, query, args...)
+	span.SetError(err)
+	span.End()
+	return rows, err
+}
+
+func (tracingQueryer *tracingQueryer) Close() error {
+	return tracingQueryer.queryer.Close()
+}
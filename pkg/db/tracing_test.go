@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/tracing"
+)
+
+type recordingExporter struct {
+	spans []*tracing.Span
+}
+
+func (e *recordingExporter) ExportSpan(span *tracing.Span) {
+	e.spans = append(e.spans, span)
+}
+
+func TestTracingQueryer(t *testing.T) {
+	exporter := &recordingExporter{}
+	queryer := NewTracingQueryer(erroringQueryer{}, "presto")
+
+	_, err := queryer.QueryContext(context.Background(), "SELECT 1")
+	require.Error(t, err)
+	assert.Empty(t, exporter.spans, "expected queries which aren't part of a trace not to be traced")
+
+	ctx, root := tracing.NewTracer(exporter, 1).Start(context.Background(), "report.generate")
+	query := "SELECT " + strings.Repeat("1", maxTracedStatementLength)
+	_, err = queryer.QueryContext(ctx, query)
+	require.Error(t, err)
+	root.End()
+
+	require.Len(t, exporter.spans, 2)
+	span := exporter.spans[0]
+	assert.Equal(t, "presto.query", span.Name)
+	assert.Equal(t, tracing.SpanKindClient, span.Kind)
+	assert.Equal(t, root.SpanID, span.ParentSpanID)
+	assert.EqualError(t, span.Err(), "query failed")
+	assert.Equal(t, []tracing.Attribute{
+		tracing.String("db.system", "presto"),
+		tracing.String("db.statement", query[:maxTracedStatementLength]),
+	}, span.Attributes())
+}
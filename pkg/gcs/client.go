@@ -0,0 +1,70 @@
+package gcs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	defaultEndpoint = "https://www.googleapis.com/storage/v1"
+	// readOnlyScope is sufficient to check a bucket can be accessed, and
+	// avoids granting the reporting-operator's token write access.
+	readOnlyScope = "https://www.googleapis.com/auth/devstorage.read_only"
+)
+
+// Client is a client for the Google Cloud Storage JSON API.
+type Client interface {
+	// CheckBucket returns an error if the bucket doesn't exist, or can't be
+	// accessed using the client's credentials.
+	CheckBucket(bucket string) error
+}
+
+type client struct {
+	httpClient *http.Client
+	endpoint   string
+}
+
+// NewClient returns a Client authenticating using serviceAccountKey, the
+// JSON key of a service account. If serviceAccountKey is empty, Google
+// Application Default Credentials are used instead, which on GKE includes
+// the Google service account bound using workload identity.
+func NewClient(ctx context.Context, serviceAccountKey []byte) (Client, error) {
+	var httpClient *http.Client
+	if len(serviceAccountKey) == 0 {
+		var err error
+		httpClient, err = google.DefaultClient(ctx, readOnlyScope)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get Google credentials: %v", err)
+		}
+	} else {
+		creds, err := google.CredentialsFromJSON(ctx, serviceAccountKey, readOnlyScope)
+		if err != nil {
+			return nil, fmt.Errorf("invalid service account key: %v", err)
+		}
+		httpClient = oauth2.NewClient(ctx, creds.TokenSource)
+	}
+	return &client{
+		httpClient: httpClient,
+		endpoint:   defaultEndpoint,
+	}, nil
+}
+
+func (c *client) CheckBucket(bucket string) error {
+	resp, err := c.httpClient.Get(c.endpoint + "/b/" + url.PathEscape(bucket))
+	if err != nil {
+		return fmt.Errorf("could not get bucket %s: %v", bucket, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("could not get bucket %s: %s: %s", bucket, resp.Status, body)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakePricingPolicies implements PricingPolicyInterface
+type FakePricingPolicies struct {
+	Fake *FakeMeteringV1alpha1
+	ns   string
+}
+
+var pricingpoliciesResource = schema.GroupVersionResource{Group: "metering.openshift.io", Version: "v1alpha1", Resource: "pricingpolicies"}
+
+var pricingpoliciesKind = schema.GroupVersionKind{Group: "metering.openshift.io", Version: "v1alpha1", Kind: "PricingPolicy"}
+
+// Get takes name of the pricingPolicy, and returns the corresponding pricingPolicy object, and an error if there is any.
+func (c *FakePricingPolicies) Get(name string, options v1.GetOptions) (result *v1alpha1.PricingPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(pricingpoliciesResource, c.ns, name), &v1alpha1.PricingPolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.PricingPolicy), err
+}
+
+// List takes label and field selectors, and returns the list of PricingPolicies that match those selectors.
+func (c *FakePricingPolicies) List(opts v1.ListOptions) (result *v1alpha1.PricingPolicyList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(pricingpoliciesResource, pricingpoliciesKind, c.ns, opts), &v1alpha1.PricingPolicyList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.PricingPolicyList{}
+	for _, item := range obj.(*v1alpha1.PricingPolicyList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested pricingPolicies.
+func (c *FakePricingPolicies) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(pricingpoliciesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a pricingPolicy and creates it.  Returns the server's representation of the pricingPolicy, and an error, if there is any.
+func (c *FakePricingPolicies) Create(pricingPolicy *v1alpha1.PricingPolicy) (result *v1alpha1.PricingPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(pricingpoliciesResource, c.ns, pricingPolicy), &v1alpha1.PricingPolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.PricingPolicy), err
+}
+
+// Update takes the representation of a pricingPolicy and updates it. Returns the server's representation of the pricingPolicy, and an error, if there is any.
+func (c *FakePricingPolicies) Update(pricingPolicy *v1alpha1.PricingPolicy) (result *v1alpha1.PricingPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(pricingpoliciesResource, c.ns, pricingPolicy), &v1alpha1.PricingPolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.PricingPolicy), err
+}
+
+// Delete takes name of the pricingPolicy and deletes it. Returns an error if one occurs.
+func (c *FakePricingPolicies) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(pricingpoliciesResource, c.ns, name), &v1alpha1.PricingPolicy{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakePricingPolicies) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(pricingpoliciesResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.PricingPolicyList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched pricingPolicy.
+func (c *FakePricingPolicies) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.PricingPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(pricingpoliciesResource, c.ns, name, data, subresources...), &v1alpha1.PricingPolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.PricingPolicy), err
+}
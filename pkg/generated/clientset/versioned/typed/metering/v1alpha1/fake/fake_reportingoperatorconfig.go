@@ -0,0 +1,112 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeReportingOperatorConfigs implements ReportingOperatorConfigInterface
+type FakeReportingOperatorConfigs struct {
+	Fake *FakeMeteringV1alpha1
+	ns   string
+}
+
+var reportingoperatorconfigsResource = schema.GroupVersionResource{Group: "metering.openshift.io", Version: "v1alpha1", Resource: "reportingoperatorconfigs"}
+
+var reportingoperatorconfigsKind = schema.GroupVersionKind{Group: "metering.openshift.io", Version: "v1alpha1", Kind: "ReportingOperatorConfig"}
+
+// Get takes name of the reportingOperatorConfig, and returns the corresponding reportingOperatorConfig object, and an error if there is any.
+func (c *FakeReportingOperatorConfigs) Get(name string, options v1.GetOptions) (result *v1alpha1.ReportingOperatorConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(reportingoperatorconfigsResource, c.ns, name), &v1alpha1.ReportingOperatorConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ReportingOperatorConfig), err
+}
+
+// List takes label and field selectors, and returns the list of ReportingOperatorConfigs that match those selectors.
+func (c *FakeReportingOperatorConfigs) List(opts v1.ListOptions) (result *v1alpha1.ReportingOperatorConfigList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(reportingoperatorconfigsResource, reportingoperatorconfigsKind, c.ns, opts), &v1alpha1.ReportingOperatorConfigList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.ReportingOperatorConfigList{}
+	for _, item := range obj.(*v1alpha1.ReportingOperatorConfigList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested reportingOperatorConfigs.
+func (c *FakeReportingOperatorConfigs) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(reportingoperatorconfigsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a reportingOperatorConfig and creates it.  Returns the server's representation of the reportingOperatorConfig, and an error, if there is any.
+func (c *FakeReportingOperatorConfigs) Create(reportingOperatorConfig *v1alpha1.ReportingOperatorConfig) (result *v1alpha1.ReportingOperatorConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(reportingoperatorconfigsResource, c.ns, reportingOperatorConfig), &v1alpha1.ReportingOperatorConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ReportingOperatorConfig), err
+}
+
+// Update takes the representation of a reportingOperatorConfig and updates it. Returns the server's representation of the reportingOperatorConfig, and an error, if there is any.
+func (c *FakeReportingOperatorConfigs) Update(reportingOperatorConfig *v1alpha1.ReportingOperatorConfig) (result *v1alpha1.ReportingOperatorConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(reportingoperatorconfigsResource, c.ns, reportingOperatorConfig), &v1alpha1.ReportingOperatorConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ReportingOperatorConfig), err
+}
+
+// Delete takes name of the reportingOperatorConfig and deletes it. Returns an error if one occurs.
+func (c *FakeReportingOperatorConfigs) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(reportingoperatorconfigsResource, c.ns, name), &v1alpha1.ReportingOperatorConfig{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeReportingOperatorConfigs) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(reportingoperatorconfigsResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.ReportingOperatorConfigList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched reportingOperatorConfig.
+func (c *FakeReportingOperatorConfigs) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ReportingOperatorConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(reportingoperatorconfigsResource, c.ns, name, data, subresources...), &v1alpha1.ReportingOperatorConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ReportingOperatorConfig), err
+}
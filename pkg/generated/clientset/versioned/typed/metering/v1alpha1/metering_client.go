@@ -12,10 +12,12 @@ import (
 type MeteringV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	PrestoTablesGetter
+	PricingPoliciesGetter
 	ReportsGetter
 	ReportDataSourcesGetter
 	ReportGenerationQueriesGetter
 	ReportPrometheusQueriesGetter
+	ReportingOperatorConfigsGetter
 	ScheduledReportsGetter
 	StorageLocationsGetter
 }
@@ -29,6 +31,10 @@ func (c *MeteringV1alpha1Client) PrestoTables(namespace string) PrestoTableInter
 	return newPrestoTables(c, namespace)
 }
 
+func (c *MeteringV1alpha1Client) PricingPolicies(namespace string) PricingPolicyInterface {
+	return newPricingPolicies(c, namespace)
+}
+
 func (c *MeteringV1alpha1Client) Reports(namespace string) ReportInterface {
 	return newReports(c, namespace)
 }
@@ -45,6 +51,10 @@ func (c *MeteringV1alpha1Client) ReportPrometheusQueries(namespace string) Repor
 	return newReportPrometheusQueries(c, namespace)
 }
 
+func (c *MeteringV1alpha1Client) ReportingOperatorConfigs(namespace string) ReportingOperatorConfigInterface {
+	return newReportingOperatorConfigs(c, namespace)
+}
+
 func (c *MeteringV1alpha1Client) ScheduledReports(namespace string) ScheduledReportInterface {
 	return newScheduledReports(c, namespace)
 }
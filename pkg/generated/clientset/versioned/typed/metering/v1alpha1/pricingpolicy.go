@@ -0,0 +1,141 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	scheme "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// PricingPoliciesGetter has a method to return a PricingPolicyInterface.
+// A group's client should implement this interface.
+type PricingPoliciesGetter interface {
+	PricingPolicies(namespace string) PricingPolicyInterface
+}
+
+// PricingPolicyInterface has methods to work with PricingPolicy resources.
+type PricingPolicyInterface interface {
+	Create(*v1alpha1.PricingPolicy) (*v1alpha1.PricingPolicy, error)
+	Update(*v1alpha1.PricingPolicy) (*v1alpha1.PricingPolicy, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.PricingPolicy, error)
+	List(opts v1.ListOptions) (*v1alpha1.PricingPolicyList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.PricingPolicy, err error)
+	PricingPolicyExpansion
+}
+
+// pricingPolicies implements PricingPolicyInterface
+type pricingPolicies struct {
+	client rest.Interface
+	ns     string
+}
+
+// newPricingPolicies returns a PricingPolicies
+func newPricingPolicies(c *MeteringV1alpha1Client, namespace string) *pricingPolicies {
+	return &pricingPolicies{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the pricingPolicy, and returns the corresponding pricingPolicy object, and an error if there is any.
+func (c *pricingPolicies) Get(name string, options v1.GetOptions) (result *v1alpha1.PricingPolicy, err error) {
+	result = &v1alpha1.PricingPolicy{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("pricingpolicies").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of PricingPolicies that match those selectors.
+func (c *pricingPolicies) List(opts v1.ListOptions) (result *v1alpha1.PricingPolicyList, err error) {
+	result = &v1alpha1.PricingPolicyList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("pricingpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested pricingPolicies.
+func (c *pricingPolicies) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("pricingpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a pricingPolicy and creates it.  Returns the server's representation of the pricingPolicy, and an error, if there is any.
+func (c *pricingPolicies) Create(pricingPolicy *v1alpha1.PricingPolicy) (result *v1alpha1.PricingPolicy, err error) {
+	result = &v1alpha1.PricingPolicy{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("pricingpolicies").
+		Body(pricingPolicy).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a pricingPolicy and updates it. Returns the server's representation of the pricingPolicy, and an error, if there is any.
+func (c *pricingPolicies) Update(pricingPolicy *v1alpha1.PricingPolicy) (result *v1alpha1.PricingPolicy, err error) {
+	result = &v1alpha1.PricingPolicy{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("pricingpolicies").
+		Name(pricingPolicy.Name).
+		Body(pricingPolicy).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the pricingPolicy and deletes it. Returns an error if one occurs.
+func (c *pricingPolicies) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("pricingpolicies").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *pricingPolicies) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("pricingpolicies").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched pricingPolicy.
+func (c *pricingPolicies) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.PricingPolicy, err error) {
+	result = &v1alpha1.PricingPolicy{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("pricingpolicies").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,141 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	scheme "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// ReportingOperatorConfigsGetter has a method to return a ReportingOperatorConfigInterface.
+// A group's client should implement this interface.
+type ReportingOperatorConfigsGetter interface {
+	ReportingOperatorConfigs(namespace string) ReportingOperatorConfigInterface
+}
+
+// ReportingOperatorConfigInterface has methods to work with ReportingOperatorConfig resources.
+type ReportingOperatorConfigInterface interface {
+	Create(*v1alpha1.ReportingOperatorConfig) (*v1alpha1.ReportingOperatorConfig, error)
+	Update(*v1alpha1.ReportingOperatorConfig) (*v1alpha1.ReportingOperatorConfig, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.ReportingOperatorConfig, error)
+	List(opts v1.ListOptions) (*v1alpha1.ReportingOperatorConfigList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ReportingOperatorConfig, err error)
+	ReportingOperatorConfigExpansion
+}
+
+// reportingOperatorConfigs implements ReportingOperatorConfigInterface
+type reportingOperatorConfigs struct {
+	client rest.Interface
+	ns     string
+}
+
+// newReportingOperatorConfigs returns a ReportingOperatorConfigs
+func newReportingOperatorConfigs(c *MeteringV1alpha1Client, namespace string) *reportingOperatorConfigs {
+	return &reportingOperatorConfigs{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the reportingOperatorConfig, and returns the corresponding reportingOperatorConfig object, and an error if there is any.
+func (c *reportingOperatorConfigs) Get(name string, options v1.GetOptions) (result *v1alpha1.ReportingOperatorConfig, err error) {
+	result = &v1alpha1.ReportingOperatorConfig{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("reportingoperatorconfigs").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of ReportingOperatorConfigs that match those selectors.
+func (c *reportingOperatorConfigs) List(opts v1.ListOptions) (result *v1alpha1.ReportingOperatorConfigList, err error) {
+	result = &v1alpha1.ReportingOperatorConfigList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("reportingoperatorconfigs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested reportingOperatorConfigs.
+func (c *reportingOperatorConfigs) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("reportingoperatorconfigs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a reportingOperatorConfig and creates it.  Returns the server's representation of the reportingOperatorConfig, and an error, if there is any.
+func (c *reportingOperatorConfigs) Create(reportingOperatorConfig *v1alpha1.ReportingOperatorConfig) (result *v1alpha1.ReportingOperatorConfig, err error) {
+	result = &v1alpha1.ReportingOperatorConfig{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("reportingoperatorconfigs").
+		Body(reportingOperatorConfig).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a reportingOperatorConfig and updates it. Returns the server's representation of the reportingOperatorConfig, and an error, if there is any.
+func (c *reportingOperatorConfigs) Update(reportingOperatorConfig *v1alpha1.ReportingOperatorConfig) (result *v1alpha1.ReportingOperatorConfig, err error) {
+	result = &v1alpha1.ReportingOperatorConfig{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("reportingoperatorconfigs").
+		Name(reportingOperatorConfig.Name).
+		Body(reportingOperatorConfig).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the reportingOperatorConfig and deletes it. Returns an error if one occurs.
+func (c *reportingOperatorConfigs) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("reportingoperatorconfigs").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *reportingOperatorConfigs) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("reportingoperatorconfigs").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched reportingOperatorConfig.
+func (c *reportingOperatorConfigs) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ReportingOperatorConfig, err error) {
+	result = &v1alpha1.ReportingOperatorConfig{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("reportingoperatorconfigs").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
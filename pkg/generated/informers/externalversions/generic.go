@@ -41,6 +41,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=metering.openshift.io, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("prestotables"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Metering().V1alpha1().PrestoTables().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("pricingpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Metering().V1alpha1().PricingPolicies().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("reports"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Metering().V1alpha1().Reports().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("reportdatasources"):
@@ -49,6 +51,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Metering().V1alpha1().ReportGenerationQueries().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("reportprometheusqueries"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Metering().V1alpha1().ReportPrometheusQueries().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("reportingoperatorconfigs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Metering().V1alpha1().ReportingOperatorConfigs().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("scheduledreports"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Metering().V1alpha1().ScheduledReports().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("storagelocations"):
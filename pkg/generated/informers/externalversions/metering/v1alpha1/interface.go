@@ -12,6 +12,8 @@ import (
 type Interface interface {
 	// PrestoTables returns a PrestoTableInformer.
 	PrestoTables() PrestoTableInformer
+	// PricingPolicies returns a PricingPolicyInformer.
+	PricingPolicies() PricingPolicyInformer
 	// Reports returns a ReportInformer.
 	Reports() ReportInformer
 	// ReportDataSources returns a ReportDataSourceInformer.
@@ -20,6 +22,8 @@ type Interface interface {
 	ReportGenerationQueries() ReportGenerationQueryInformer
 	// ReportPrometheusQueries returns a ReportPrometheusQueryInformer.
 	ReportPrometheusQueries() ReportPrometheusQueryInformer
+	// ReportingOperatorConfigs returns a ReportingOperatorConfigInformer.
+	ReportingOperatorConfigs() ReportingOperatorConfigInformer
 	// ScheduledReports returns a ScheduledReportInformer.
 	ScheduledReports() ScheduledReportInformer
 	// StorageLocations returns a StorageLocationInformer.
@@ -42,6 +46,11 @@ func (v *version) PrestoTables() PrestoTableInformer {
 	return &prestoTableInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// PricingPolicies returns a PricingPolicyInformer.
+func (v *version) PricingPolicies() PricingPolicyInformer {
+	return &pricingPolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Reports returns a ReportInformer.
 func (v *version) Reports() ReportInformer {
 	return &reportInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -62,6 +71,11 @@ func (v *version) ReportPrometheusQueries() ReportPrometheusQueryInformer {
 	return &reportPrometheusQueryInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// ReportingOperatorConfigs returns a ReportingOperatorConfigInformer.
+func (v *version) ReportingOperatorConfigs() ReportingOperatorConfigInformer {
+	return &reportingOperatorConfigInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // ScheduledReports returns a ScheduledReportInformer.
 func (v *version) ScheduledReports() ScheduledReportInformer {
 	return &scheduledReportInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -0,0 +1,75 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+// This file was automatically generated by informer-gen
+
+package v1alpha1
+
+import (
+	time "time"
+
+	metering_v1alpha1 "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	versioned "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned"
+	internalinterfaces "github.com/operator-framework/operator-metering/pkg/generated/informers/externalversions/internalinterfaces"
+	v1alpha1 "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// PricingPolicyInformer provides access to a shared informer and lister for
+// PricingPolicies.
+type PricingPolicyInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1alpha1.PricingPolicyLister
+}
+
+type pricingPolicyInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewPricingPolicyInformer constructs a new informer for PricingPolicy type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewPricingPolicyInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredPricingPolicyInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredPricingPolicyInformer constructs a new informer for PricingPolicy type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredPricingPolicyInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.MeteringV1alpha1().PricingPolicies(namespace).List(options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.MeteringV1alpha1().PricingPolicies(namespace).Watch(options)
+			},
+		},
+		&metering_v1alpha1.PricingPolicy{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *pricingPolicyInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredPricingPolicyInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *pricingPolicyInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&metering_v1alpha1.PricingPolicy{}, f.defaultInformer)
+}
+
+func (f *pricingPolicyInformer) Lister() v1alpha1.PricingPolicyLister {
+	return v1alpha1.NewPricingPolicyLister(f.Informer().GetIndexer())
+}
@@ -0,0 +1,75 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+// This file was automatically generated by informer-gen
+
+package v1alpha1
+
+import (
+	time "time"
+
+	metering_v1alpha1 "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	versioned "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned"
+	internalinterfaces "github.com/operator-framework/operator-metering/pkg/generated/informers/externalversions/internalinterfaces"
+	v1alpha1 "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// ReportingOperatorConfigInformer provides access to a shared informer and lister for
+// ReportingOperatorConfigs.
+type ReportingOperatorConfigInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1alpha1.ReportingOperatorConfigLister
+}
+
+type reportingOperatorConfigInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewReportingOperatorConfigInformer constructs a new informer for ReportingOperatorConfig type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewReportingOperatorConfigInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredReportingOperatorConfigInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredReportingOperatorConfigInformer constructs a new informer for ReportingOperatorConfig type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredReportingOperatorConfigInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.MeteringV1alpha1().ReportingOperatorConfigs(namespace).List(options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.MeteringV1alpha1().ReportingOperatorConfigs(namespace).Watch(options)
+			},
+		},
+		&metering_v1alpha1.ReportingOperatorConfig{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *reportingOperatorConfigInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredReportingOperatorConfigInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *reportingOperatorConfigInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&metering_v1alpha1.ReportingOperatorConfig{}, f.defaultInformer)
+}
+
+func (f *reportingOperatorConfigInformer) Lister() v1alpha1.ReportingOperatorConfigLister {
+	return v1alpha1.NewReportingOperatorConfigLister(f.Informer().GetIndexer())
+}
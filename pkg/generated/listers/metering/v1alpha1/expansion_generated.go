@@ -12,6 +12,14 @@ type PrestoTableListerExpansion interface{}
 // PrestoTableNamespaceLister.
 type PrestoTableNamespaceListerExpansion interface{}
 
+// PricingPolicyListerExpansion allows custom methods to be added to
+// PricingPolicyLister.
+type PricingPolicyListerExpansion interface{}
+
+// PricingPolicyNamespaceListerExpansion allows custom methods to be added to
+// PricingPolicyNamespaceLister.
+type PricingPolicyNamespaceListerExpansion interface{}
+
 // ReportListerExpansion allows custom methods to be added to
 // ReportLister.
 type ReportListerExpansion interface{}
@@ -44,6 +52,14 @@ type ReportPrometheusQueryListerExpansion interface{}
 // ReportPrometheusQueryNamespaceLister.
 type ReportPrometheusQueryNamespaceListerExpansion interface{}
 
+// ReportingOperatorConfigListerExpansion allows custom methods to be added to
+// ReportingOperatorConfigLister.
+type ReportingOperatorConfigListerExpansion interface{}
+
+// ReportingOperatorConfigNamespaceListerExpansion allows custom methods to be added to
+// ReportingOperatorConfigNamespaceLister.
+type ReportingOperatorConfigNamespaceListerExpansion interface{}
+
 // ScheduledReportListerExpansion allows custom methods to be added to
 // ScheduledReportLister.
 type ScheduledReportListerExpansion interface{}
@@ -0,0 +1,80 @@
+// Code generated by lister-gen. DO NOT EDIT.
+
+// This file was automatically generated by lister-gen
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// PricingPolicyLister helps list PricingPolicies.
+type PricingPolicyLister interface {
+	// List lists all PricingPolicies in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.PricingPolicy, err error)
+	// PricingPolicies returns an object that can list and get PricingPolicies.
+	PricingPolicies(namespace string) PricingPolicyNamespaceLister
+	PricingPolicyListerExpansion
+}
+
+// pricingPolicyLister implements the PricingPolicyLister interface.
+type pricingPolicyLister struct {
+	indexer cache.Indexer
+}
+
+// NewPricingPolicyLister returns a new PricingPolicyLister.
+func NewPricingPolicyLister(indexer cache.Indexer) PricingPolicyLister {
+	return &pricingPolicyLister{indexer: indexer}
+}
+
+// List lists all PricingPolicies in the indexer.
+func (s *pricingPolicyLister) List(selector labels.Selector) (ret []*v1alpha1.PricingPolicy, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.PricingPolicy))
+	})
+	return ret, err
+}
+
+// PricingPolicies returns an object that can list and get PricingPolicies.
+func (s *pricingPolicyLister) PricingPolicies(namespace string) PricingPolicyNamespaceLister {
+	return pricingPolicyNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// PricingPolicyNamespaceLister helps list and get PricingPolicies.
+type PricingPolicyNamespaceLister interface {
+	// List lists all PricingPolicies in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.PricingPolicy, err error)
+	// Get retrieves the PricingPolicy from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.PricingPolicy, error)
+	PricingPolicyNamespaceListerExpansion
+}
+
+// pricingPolicyNamespaceLister implements the PricingPolicyNamespaceLister
+// interface.
+type pricingPolicyNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all PricingPolicies in the indexer for a given namespace.
+func (s pricingPolicyNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.PricingPolicy, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.PricingPolicy))
+	})
+	return ret, err
+}
+
+// Get retrieves the PricingPolicy from the indexer for a given namespace and name.
+func (s pricingPolicyNamespaceLister) Get(name string) (*v1alpha1.PricingPolicy, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("pricingpolicy"), name)
+	}
+	return obj.(*v1alpha1.PricingPolicy), nil
+}
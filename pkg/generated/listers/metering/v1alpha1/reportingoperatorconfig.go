@@ -0,0 +1,80 @@
+// Code generated by lister-gen. DO NOT EDIT.
+
+// This file was automatically generated by lister-gen
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ReportingOperatorConfigLister helps list ReportingOperatorConfigs.
+type ReportingOperatorConfigLister interface {
+	// List lists all ReportingOperatorConfigs in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.ReportingOperatorConfig, err error)
+	// ReportingOperatorConfigs returns an object that can list and get ReportingOperatorConfigs.
+	ReportingOperatorConfigs(namespace string) ReportingOperatorConfigNamespaceLister
+	ReportingOperatorConfigListerExpansion
+}
+
+// reportingOperatorConfigLister implements the ReportingOperatorConfigLister interface.
+type reportingOperatorConfigLister struct {
+	indexer cache.Indexer
+}
+
+// NewReportingOperatorConfigLister returns a new ReportingOperatorConfigLister.
+func NewReportingOperatorConfigLister(indexer cache.Indexer) ReportingOperatorConfigLister {
+	return &reportingOperatorConfigLister{indexer: indexer}
+}
+
+// List lists all ReportingOperatorConfigs in the indexer.
+func (s *reportingOperatorConfigLister) List(selector labels.Selector) (ret []*v1alpha1.ReportingOperatorConfig, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.ReportingOperatorConfig))
+	})
+	return ret, err
+}
+
+// ReportingOperatorConfigs returns an object that can list and get ReportingOperatorConfigs.
+func (s *reportingOperatorConfigLister) ReportingOperatorConfigs(namespace string) ReportingOperatorConfigNamespaceLister {
+	return reportingOperatorConfigNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// ReportingOperatorConfigNamespaceLister helps list and get ReportingOperatorConfigs.
+type ReportingOperatorConfigNamespaceLister interface {
+	// List lists all ReportingOperatorConfigs in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.ReportingOperatorConfig, err error)
+	// Get retrieves the ReportingOperatorConfig from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.ReportingOperatorConfig, error)
+	ReportingOperatorConfigNamespaceListerExpansion
+}
+
+// reportingOperatorConfigNamespaceLister implements the ReportingOperatorConfigNamespaceLister
+// interface.
+type reportingOperatorConfigNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all ReportingOperatorConfigs in the indexer for a given namespace.
+func (s reportingOperatorConfigNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.ReportingOperatorConfig, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.ReportingOperatorConfig))
+	})
+	return ret, err
+}
+
+// Get retrieves the ReportingOperatorConfig from the indexer for a given namespace and name.
+func (s reportingOperatorConfigNamespaceLister) Get(name string) (*v1alpha1.ReportingOperatorConfig, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("reportingoperatorconfig"), name)
+	}
+	return obj.(*v1alpha1.ReportingOperatorConfig), nil
+}
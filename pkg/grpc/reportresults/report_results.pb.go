@@ -0,0 +1,670 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: report_results.proto
+
+package reportresults
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import timestamp "github.com/golang/protobuf/ptypes/timestamp"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type StreamReportResultsRequest struct {
+	Namespace            string   `protobuf:"bytes,1,opt,name=namespace" json:"namespace,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	IncludeHidden        bool     `protobuf:"varint,3,opt,name=include_hidden,json=includeHidden" json:"include_hidden,omitempty"`
+	BatchSize            int32    `protobuf:"varint,4,opt,name=batch_size,json=batchSize" json:"batch_size,omitempty"`
+	IgnoreFailed         bool     `protobuf:"varint,5,opt,name=ignore_failed,json=ignoreFailed" json:"ignore_failed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamReportResultsRequest) Reset()         { *m = StreamReportResultsRequest{} }
+func (m *StreamReportResultsRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamReportResultsRequest) ProtoMessage()    {}
+func (*StreamReportResultsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_report_results_03d3e6dec69954c2, []int{0}
+}
+func (m *StreamReportResultsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamReportResultsRequest.Unmarshal(m, b)
+}
+func (m *StreamReportResultsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamReportResultsRequest.Marshal(b, m, deterministic)
+}
+func (dst *StreamReportResultsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamReportResultsRequest.Merge(dst, src)
+}
+func (m *StreamReportResultsRequest) XXX_Size() int {
+	return xxx_messageInfo_StreamReportResultsRequest.Size(m)
+}
+func (m *StreamReportResultsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamReportResultsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamReportResultsRequest proto.InternalMessageInfo
+
+func (m *StreamReportResultsRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *StreamReportResultsRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *StreamReportResultsRequest) GetIncludeHidden() bool {
+	if m != nil {
+		return m.IncludeHidden
+	}
+	return false
+}
+
+func (m *StreamReportResultsRequest) GetBatchSize() int32 {
+	if m != nil {
+		return m.BatchSize
+	}
+	return 0
+}
+
+func (m *StreamReportResultsRequest) GetIgnoreFailed() bool {
+	if m != nil {
+		return m.IgnoreFailed
+	}
+	return false
+}
+
+type ReportResultsChunk struct {
+	Columns              []*Column `protobuf:"bytes,1,rep,name=columns" json:"columns,omitempty"`
+	Rows                 []*Row    `protobuf:"bytes,2,rep,name=rows" json:"rows,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ReportResultsChunk) Reset()         { *m = ReportResultsChunk{} }
+func (m *ReportResultsChunk) String() string { return proto.CompactTextString(m) }
+func (*ReportResultsChunk) ProtoMessage()    {}
+func (*ReportResultsChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_report_results_03d3e6dec69954c2, []int{1}
+}
+func (m *ReportResultsChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReportResultsChunk.Unmarshal(m, b)
+}
+func (m *ReportResultsChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReportResultsChunk.Marshal(b, m, deterministic)
+}
+func (dst *ReportResultsChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReportResultsChunk.Merge(dst, src)
+}
+func (m *ReportResultsChunk) XXX_Size() int {
+	return xxx_messageInfo_ReportResultsChunk.Size(m)
+}
+func (m *ReportResultsChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReportResultsChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReportResultsChunk proto.InternalMessageInfo
+
+func (m *ReportResultsChunk) GetColumns() []*Column {
+	if m != nil {
+		return m.Columns
+	}
+	return nil
+}
+
+func (m *ReportResultsChunk) GetRows() []*Row {
+	if m != nil {
+		return m.Rows
+	}
+	return nil
+}
+
+type Column struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Type                 string   `protobuf:"bytes,2,opt,name=type" json:"type,omitempty"`
+	Unit                 string   `protobuf:"bytes,3,opt,name=unit" json:"unit,omitempty"`
+	TableHidden          bool     `protobuf:"varint,4,opt,name=table_hidden,json=tableHidden" json:"table_hidden,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Column) Reset()         { *m = Column{} }
+func (m *Column) String() string { return proto.CompactTextString(m) }
+func (*Column) ProtoMessage()    {}
+func (*Column) Descriptor() ([]byte, []int) {
+	return fileDescriptor_report_results_03d3e6dec69954c2, []int{2}
+}
+func (m *Column) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Column.Unmarshal(m, b)
+}
+func (m *Column) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Column.Marshal(b, m, deterministic)
+}
+func (dst *Column) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Column.Merge(dst, src)
+}
+func (m *Column) XXX_Size() int {
+	return xxx_messageInfo_Column.Size(m)
+}
+func (m *Column) XXX_DiscardUnknown() {
+	xxx_messageInfo_Column.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Column proto.InternalMessageInfo
+
+func (m *Column) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Column) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Column) GetUnit() string {
+	if m != nil {
+		return m.Unit
+	}
+	return ""
+}
+
+func (m *Column) GetTableHidden() bool {
+	if m != nil {
+		return m.TableHidden
+	}
+	return false
+}
+
+type Row struct {
+	Values               []*Value `protobuf:"bytes,1,rep,name=values" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Row) Reset()         { *m = Row{} }
+func (m *Row) String() string { return proto.CompactTextString(m) }
+func (*Row) ProtoMessage()    {}
+func (*Row) Descriptor() ([]byte, []int) {
+	return fileDescriptor_report_results_03d3e6dec69954c2, []int{3}
+}
+func (m *Row) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Row.Unmarshal(m, b)
+}
+func (m *Row) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Row.Marshal(b, m, deterministic)
+}
+func (dst *Row) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Row.Merge(dst, src)
+}
+func (m *Row) XXX_Size() int {
+	return xxx_messageInfo_Row.Size(m)
+}
+func (m *Row) XXX_DiscardUnknown() {
+	xxx_messageInfo_Row.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Row proto.InternalMessageInfo
+
+func (m *Row) GetValues() []*Value {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type Value struct {
+	// Types that are valid to be assigned to Kind:
+	//	*Value_StringValue
+	//	*Value_IntValue
+	//	*Value_DoubleValue
+	//	*Value_BoolValue
+	//	*Value_TimestampValue
+	Kind                 isValue_Kind `protobuf_oneof:"kind"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *Value) Reset()         { *m = Value{} }
+func (m *Value) String() string { return proto.CompactTextString(m) }
+func (*Value) ProtoMessage()    {}
+func (*Value) Descriptor() ([]byte, []int) {
+	return fileDescriptor_report_results_03d3e6dec69954c2, []int{4}
+}
+func (m *Value) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Value.Unmarshal(m, b)
+}
+func (m *Value) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Value.Marshal(b, m, deterministic)
+}
+func (dst *Value) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Value.Merge(dst, src)
+}
+func (m *Value) XXX_Size() int {
+	return xxx_messageInfo_Value.Size(m)
+}
+func (m *Value) XXX_DiscardUnknown() {
+	xxx_messageInfo_Value.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Value proto.InternalMessageInfo
+
+type isValue_Kind interface {
+	isValue_Kind()
+}
+
+type Value_StringValue struct {
+	StringValue string `protobuf:"bytes,1,opt,name=string_value,json=stringValue,oneof"`
+}
+type Value_IntValue struct {
+	IntValue int64 `protobuf:"varint,2,opt,name=int_value,json=intValue,oneof"`
+}
+type Value_DoubleValue struct {
+	DoubleValue float64 `protobuf:"fixed64,3,opt,name=double_value,json=doubleValue,oneof"`
+}
+type Value_BoolValue struct {
+	BoolValue bool `protobuf:"varint,4,opt,name=bool_value,json=boolValue,oneof"`
+}
+type Value_TimestampValue struct {
+	TimestampValue *timestamp.Timestamp `protobuf:"bytes,5,opt,name=timestamp_value,json=timestampValue,oneof"`
+}
+
+func (*Value_StringValue) isValue_Kind()    {}
+func (*Value_IntValue) isValue_Kind()       {}
+func (*Value_DoubleValue) isValue_Kind()    {}
+func (*Value_BoolValue) isValue_Kind()      {}
+func (*Value_TimestampValue) isValue_Kind() {}
+
+func (m *Value) GetKind() isValue_Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return nil
+}
+
+func (m *Value) GetStringValue() string {
+	if x, ok := m.GetKind().(*Value_StringValue); ok {
+		return x.StringValue
+	}
+	return ""
+}
+
+func (m *Value) GetIntValue() int64 {
+	if x, ok := m.GetKind().(*Value_IntValue); ok {
+		return x.IntValue
+	}
+	return 0
+}
+
+func (m *Value) GetDoubleValue() float64 {
+	if x, ok := m.GetKind().(*Value_DoubleValue); ok {
+		return x.DoubleValue
+	}
+	return 0
+}
+
+func (m *Value) GetBoolValue() bool {
+	if x, ok := m.GetKind().(*Value_BoolValue); ok {
+		return x.BoolValue
+	}
+	return false
+}
+
+func (m *Value) GetTimestampValue() *timestamp.Timestamp {
+	if x, ok := m.GetKind().(*Value_TimestampValue); ok {
+		return x.TimestampValue
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*Value) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _Value_OneofMarshaler, _Value_OneofUnmarshaler, _Value_OneofSizer, []interface{}{
+		(*Value_StringValue)(nil),
+		(*Value_IntValue)(nil),
+		(*Value_DoubleValue)(nil),
+		(*Value_BoolValue)(nil),
+		(*Value_TimestampValue)(nil),
+	}
+}
+
+func _Value_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*Value)
+	// kind
+	switch x := m.Kind.(type) {
+	case *Value_StringValue:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.StringValue)
+	case *Value_IntValue:
+		b.EncodeVarint(2<<3 | proto.WireVarint)
+		b.EncodeVarint(uint64(x.IntValue))
+	case *Value_DoubleValue:
+		b.EncodeVarint(3<<3 | proto.WireFixed64)
+		b.EncodeFixed64(math.Float64bits(x.DoubleValue))
+	case *Value_BoolValue:
+		t := uint64(0)
+		if x.BoolValue {
+			t = 1
+		}
+		b.EncodeVarint(4<<3 | proto.WireVarint)
+		b.EncodeVarint(t)
+	case *Value_TimestampValue:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.TimestampValue); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("Value.Kind has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _Value_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*Value)
+	switch tag {
+	case 1: // kind.string_value
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.Kind = &Value_StringValue{x}
+		return true, err
+	case 2: // kind.int_value
+		if wire != proto.WireVarint {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeVarint()
+		m.Kind = &Value_IntValue{int64(x)}
+		return true, err
+	case 3: // kind.double_value
+		if wire != proto.WireFixed64 {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeFixed64()
+		m.Kind = &Value_DoubleValue{math.Float64frombits(x)}
+		return true, err
+	case 4: // kind.bool_value
+		if wire != proto.WireVarint {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeVarint()
+		m.Kind = &Value_BoolValue{x != 0}
+		return true, err
+	case 5: // kind.timestamp_value
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(timestamp.Timestamp)
+		err := b.DecodeMessage(msg)
+		m.Kind = &Value_TimestampValue{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _Value_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*Value)
+	// kind
+	switch x := m.Kind.(type) {
+	case *Value_StringValue:
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(len(x.StringValue)))
+		n += len(x.StringValue)
+	case *Value_IntValue:
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(x.IntValue))
+	case *Value_DoubleValue:
+		n += 1 // tag and wire
+		n += 8
+	case *Value_BoolValue:
+		n += 1 // tag and wire
+		n += 1
+	case *Value_TimestampValue:
+		s := proto.Size(x.TimestampValue)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+func init() {
+	proto.RegisterType((*StreamReportResultsRequest)(nil), "metering.reportresults.v1.StreamReportResultsRequest")
+	proto.RegisterType((*ReportResultsChunk)(nil), "metering.reportresults.v1.ReportResultsChunk")
+	proto.RegisterType((*Column)(nil), "metering.reportresults.v1.Column")
+	proto.RegisterType((*Row)(nil), "metering.reportresults.v1.Row")
+	proto.RegisterType((*Value)(nil), "metering.reportresults.v1.Value")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for ReportResults service
+
+type ReportResultsClient interface {
+	StreamReportResults(ctx context.Context, in *StreamReportResultsRequest, opts ...grpc.CallOption) (ReportResults_StreamReportResultsClient, error)
+	StreamScheduledReportResults(ctx context.Context, in *StreamReportResultsRequest, opts ...grpc.CallOption) (ReportResults_StreamScheduledReportResultsClient, error)
+}
+
+type reportResultsClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewReportResultsClient(cc *grpc.ClientConn) ReportResultsClient {
+	return &reportResultsClient{cc}
+}
+
+func (c *reportResultsClient) StreamReportResults(ctx context.Context, in *StreamReportResultsRequest, opts ...grpc.CallOption) (ReportResults_StreamReportResultsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_ReportResults_serviceDesc.Streams[0], c.cc, "/metering.reportresults.v1.ReportResults/StreamReportResults", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &reportResultsStreamReportResultsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ReportResults_StreamReportResultsClient interface {
+	Recv() (*ReportResultsChunk, error)
+	grpc.ClientStream
+}
+
+type reportResultsStreamReportResultsClient struct {
+	grpc.ClientStream
+}
+
+func (x *reportResultsStreamReportResultsClient) Recv() (*ReportResultsChunk, error) {
+	m := new(ReportResultsChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *reportResultsClient) StreamScheduledReportResults(ctx context.Context, in *StreamReportResultsRequest, opts ...grpc.CallOption) (ReportResults_StreamScheduledReportResultsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_ReportResults_serviceDesc.Streams[1], c.cc, "/metering.reportresults.v1.ReportResults/StreamScheduledReportResults", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &reportResultsStreamScheduledReportResultsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ReportResults_StreamScheduledReportResultsClient interface {
+	Recv() (*ReportResultsChunk, error)
+	grpc.ClientStream
+}
+
+type reportResultsStreamScheduledReportResultsClient struct {
+	grpc.ClientStream
+}
+
+func (x *reportResultsStreamScheduledReportResultsClient) Recv() (*ReportResultsChunk, error) {
+	m := new(ReportResultsChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for ReportResults service
+
+type ReportResultsServer interface {
+	StreamReportResults(*StreamReportResultsRequest, ReportResults_StreamReportResultsServer) error
+	StreamScheduledReportResults(*StreamReportResultsRequest, ReportResults_StreamScheduledReportResultsServer) error
+}
+
+func RegisterReportResultsServer(s *grpc.Server, srv ReportResultsServer) {
+	s.RegisterService(&_ReportResults_serviceDesc, srv)
+}
+
+func _ReportResults_StreamReportResults_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamReportResultsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ReportResultsServer).StreamReportResults(m, &reportResultsStreamReportResultsServer{stream})
+}
+
+type ReportResults_StreamReportResultsServer interface {
+	Send(*ReportResultsChunk) error
+	grpc.ServerStream
+}
+
+type reportResultsStreamReportResultsServer struct {
+	grpc.ServerStream
+}
+
+func (x *reportResultsStreamReportResultsServer) Send(m *ReportResultsChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ReportResults_StreamScheduledReportResults_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamReportResultsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ReportResultsServer).StreamScheduledReportResults(m, &reportResultsStreamScheduledReportResultsServer{stream})
+}
+
+type ReportResults_StreamScheduledReportResultsServer interface {
+	Send(*ReportResultsChunk) error
+	grpc.ServerStream
+}
+
+type reportResultsStreamScheduledReportResultsServer struct {
+	grpc.ServerStream
+}
+
+func (x *reportResultsStreamScheduledReportResultsServer) Send(m *ReportResultsChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _ReportResults_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "metering.reportresults.v1.ReportResults",
+	HandlerType: (*ReportResultsServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamReportResults",
+			Handler:       _ReportResults_StreamReportResults_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamScheduledReportResults",
+			Handler:       _ReportResults_StreamScheduledReportResults_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "report_results.proto",
+}
+
+func init() {
+	proto.RegisterFile("report_results.proto", fileDescriptor_report_results_03d3e6dec69954c2)
+}
+
+var fileDescriptor_report_results_03d3e6dec69954c2 = []byte{
+	// 517 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x93, 0xc1, 0x6e, 0xd3, 0x4e,
+	0x10, 0xc6, 0xb3, 0x89, 0x93, 0x7f, 0x3d, 0x49, 0x5a, 0x69, 0xff, 0x1c, 0x4c, 0xd4, 0xd2, 0xd4,
+	0x15, 0x52, 0x2e, 0xb8, 0x10, 0x84, 0x84, 0xc4, 0x01, 0xa9, 0x15, 0x28, 0xe7, 0x0d, 0xe2, 0xc0,
+	0x25, 0x72, 0xe2, 0x69, 0xb2, 0xaa, 0xbd, 0x1b, 0xec, 0x75, 0x23, 0x7a, 0xe1, 0x84, 0xc4, 0x3b,
+	0xf0, 0x24, 0xbc, 0x0d, 0x8f, 0x82, 0x76, 0x36, 0x36, 0x44, 0xd0, 0x5c, 0xb9, 0x6d, 0xbe, 0xf9,
+	0x7d, 0xa3, 0x99, 0x2f, 0x63, 0x78, 0x90, 0xe3, 0x5a, 0xe7, 0x66, 0x96, 0x63, 0x51, 0xa6, 0xa6,
+	0x88, 0xd6, 0xb9, 0x36, 0x9a, 0x3f, 0xcc, 0xd0, 0x60, 0x2e, 0xd5, 0x32, 0x72, 0xe5, 0xaa, 0x7a,
+	0xfb, 0x6c, 0x70, 0xba, 0xd4, 0x7a, 0x99, 0xe2, 0x05, 0x81, 0xf3, 0xf2, 0xfa, 0xc2, 0xc8, 0x0c,
+	0x0b, 0x13, 0x67, 0x6b, 0xe7, 0x0d, 0xbf, 0x33, 0x18, 0x4c, 0x4d, 0x8e, 0x71, 0x26, 0xc8, 0x2b,
+	0x9c, 0x57, 0xe0, 0xc7, 0x12, 0x0b, 0xc3, 0x8f, 0xc1, 0x57, 0x71, 0x86, 0xc5, 0x3a, 0x5e, 0x60,
+	0xc0, 0x86, 0x6c, 0xe4, 0x8b, 0x5f, 0x02, 0xe7, 0xe0, 0xd9, 0x1f, 0x41, 0x93, 0x0a, 0xf4, 0xe6,
+	0x8f, 0xe1, 0x50, 0xaa, 0x45, 0x5a, 0x26, 0x38, 0x5b, 0xc9, 0x24, 0x41, 0x15, 0xb4, 0x86, 0x6c,
+	0x74, 0x20, 0xfa, 0x5b, 0x75, 0x42, 0x22, 0x3f, 0x01, 0x98, 0xc7, 0x66, 0xb1, 0x9a, 0x15, 0xf2,
+	0x0e, 0x03, 0x6f, 0xc8, 0x46, 0x6d, 0xe1, 0x93, 0x32, 0x95, 0x77, 0xc8, 0xcf, 0xa1, 0x2f, 0x97,
+	0x4a, 0xe7, 0x38, 0xbb, 0x8e, 0x65, 0x8a, 0x49, 0xd0, 0xa6, 0x26, 0x3d, 0x27, 0xbe, 0x25, 0x2d,
+	0xfc, 0xc2, 0x80, 0xef, 0x4c, 0x7d, 0xb5, 0x2a, 0xd5, 0x0d, 0x7f, 0x05, 0xff, 0x2d, 0x74, 0x5a,
+	0x66, 0xaa, 0x08, 0xd8, 0xb0, 0x35, 0xea, 0x8e, 0xcf, 0xa2, 0x7b, 0x03, 0x8a, 0xae, 0x88, 0x14,
+	0x95, 0x83, 0x8f, 0xc1, 0xcb, 0xf5, 0xa6, 0x08, 0x9a, 0xe4, 0x7c, 0xb4, 0xc7, 0x29, 0xf4, 0x46,
+	0x10, 0x1b, 0x2e, 0xa1, 0xe3, 0xda, 0xd4, 0x81, 0xb0, 0xdf, 0x02, 0xe1, 0xe0, 0x99, 0x4f, 0xeb,
+	0x3a, 0x24, 0xfb, 0xb6, 0x5a, 0xa9, 0xa4, 0xa1, 0x68, 0x7c, 0x41, 0x6f, 0x7e, 0x06, 0x3d, 0x13,
+	0xcf, 0xd3, 0x3a, 0x36, 0x8f, 0x36, 0xee, 0x92, 0xe6, 0x42, 0x0b, 0x5f, 0x43, 0x4b, 0xe8, 0x0d,
+	0x7f, 0x09, 0x9d, 0xdb, 0x38, 0x2d, 0xb1, 0xda, 0x6f, 0xb8, 0x67, 0xca, 0xf7, 0x16, 0x14, 0x5b,
+	0x3e, 0xfc, 0xc1, 0xa0, 0x4d, 0x0a, 0x3f, 0x87, 0x5e, 0x61, 0xac, 0x65, 0x46, 0x25, 0x37, 0xf1,
+	0xa4, 0x21, 0xba, 0x4e, 0x75, 0xd0, 0x09, 0xf8, 0x52, 0x99, 0x2d, 0x61, 0xe7, 0x6f, 0x4d, 0x1a,
+	0xe2, 0x40, 0x2a, 0x53, 0xf7, 0x48, 0x74, 0x69, 0x47, 0x76, 0x84, 0xdd, 0x86, 0xd9, 0x1e, 0x4e,
+	0x75, 0xd0, 0x29, 0xc0, 0x5c, 0xeb, 0x74, 0x8b, 0xd0, 0x52, 0x93, 0x86, 0xf0, 0xad, 0xe6, 0x80,
+	0x37, 0x70, 0x54, 0x1f, 0xe5, 0x96, 0xb2, 0x7f, 0x76, 0x77, 0x3c, 0x88, 0xdc, 0xf1, 0x46, 0xd5,
+	0xf1, 0x46, 0xef, 0x2a, 0x6e, 0xd2, 0x10, 0x87, 0xb5, 0x89, 0xda, 0x5c, 0x76, 0xc0, 0xbb, 0x91,
+	0x2a, 0x19, 0x7f, 0x6b, 0x42, 0x7f, 0xe7, 0x28, 0xf8, 0x67, 0xf8, 0xff, 0x2f, 0x17, 0xce, 0x5f,
+	0xec, 0x49, 0xed, 0xfe, 0x2f, 0x62, 0xf0, 0x64, 0xdf, 0x49, 0xfc, 0x71, 0x8c, 0x61, 0xe3, 0x29,
+	0xe3, 0x5f, 0x19, 0x1c, 0xbb, 0x8e, 0xd3, 0xc5, 0x0a, 0x93, 0x32, 0xc5, 0xe4, 0x1f, 0x8d, 0x72,
+	0x79, 0xf4, 0xa1, 0xbf, 0x83, 0xce, 0x3b, 0x14, 0xee, 0xf3, 0x9f, 0x03, 0x00, 0x44, 0x3a, 0x85,
+	0x32, 0x5a, 0x04, 0x00, 0x00,
+}
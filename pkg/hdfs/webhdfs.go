@@ -0,0 +1,162 @@
+package hdfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+)
+
+const (
+	// defaultUser is the user requests are made as. The HDFS deployed by the
+	// Metering chart has permissions disabled, and uses root as it's static
+	// HTTP user.
+	defaultUser = "root"
+
+	FileTypeFile      = "FILE"
+	FileTypeDirectory = "DIRECTORY"
+)
+
+// Client is a client for the WebHDFS REST API of a HDFS namenode.
+type Client interface {
+	// ListStatus returns the status of each file and directory within the
+	// directory dir.
+	ListStatus(dir string) ([]FileStatus, error)
+	// Mkdirs creates the directory and any missing parents with the
+	// permission. If the directory exists, nothing is done.
+	Mkdirs(dir string, permission os.FileMode) error
+	// SetReplication sets the replication factor of a file.
+	SetReplication(file string, replication int) error
+	// SetPermission sets the permission of a file or directory.
+	SetPermission(file string, permission os.FileMode) error
+}
+
+// FileStatus is the status of a file or directory.
+type FileStatus struct {
+	// Path is the full path of the file.
+	Path        string
+	Type        string
+	Length      int64
+	Replication int
+	Permission  os.FileMode
+}
+
+type client struct {
+	httpClient *http.Client
+	// endpoint is the URL of the namenode's HTTP server.
+	endpoint string
+}
+
+// NewClient returns a Client for the namenode with the HTTP server at
+// address, for example http://hdfs-namenode-web:9870.
+func NewClient(address string) Client {
+	return &client{
+		httpClient: http.DefaultClient,
+		endpoint:   address,
+	}
+}
+
+type listStatusResponse struct {
+	FileStatuses struct {
+		FileStatus []struct {
+			PathSuffix  string `json:"pathSuffix"`
+			Type        string `json:"type"`
+			Length      int64  `json:"length"`
+			Replication int    `json:"replication"`
+			Permission  string `json:"permission"`
+		} `json:"FileStatus"`
+	} `json:"FileStatuses"`
+}
+
+func (c *client) ListStatus(dir string) ([]FileStatus, error) {
+	resp, err := c.do("GET", dir, "LISTSTATUS", nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not list directory %s: %v", dir, err)
+	}
+	defer resp.Body.Close()
+	var list listStatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("could not decode status of directory %s: %v", dir, err)
+	}
+	statuses := make([]FileStatus, 0, len(list.FileStatuses.FileStatus))
+	for _, status := range list.FileStatuses.FileStatus {
+		permission, err := strconv.ParseUint(status.Permission, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid permission %q of %s: %v", status.Permission, status.PathSuffix, err)
+		}
+		statuses = append(statuses, FileStatus{
+			Path:        path.Join(dir, status.PathSuffix),
+			Type:        status.Type,
+			Length:      status.Length,
+			Replication: status.Replication,
+			Permission:  os.FileMode(permission),
+		})
+	}
+	return statuses, nil
+}
+
+func (c *client) Mkdirs(dir string, permission os.FileMode) error {
+	resp, err := c.do("PUT", dir, "MKDIRS", url.Values{"permission": {formatPermission(permission)}})
+	if err != nil {
+		return fmt.Errorf("could not create directory %s: %v", dir, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *client) SetReplication(file string, replication int) error {
+	resp, err := c.do("PUT", file, "SETREPLICATION", url.Values{"replication": {strconv.Itoa(replication)}})
+	if err != nil {
+		return fmt.Errorf("could not set replication of %s: %v", file, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *client) SetPermission(file string, permission os.FileMode) error {
+	resp, err := c.do("PUT", file, "SETPERMISSION", url.Values{"permission": {formatPermission(permission)}})
+	if err != nil {
+		return fmt.Errorf("could not set permission of %s: %v", file, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func formatPermission(permission os.FileMode) string {
+	return strconv.FormatUint(uint64(permission.Perm()), 8)
+}
+
+// do performs an operation on the path, returning an error if the response
+// status isn't successful.
+func (c *client) do(method, filePath, op string, query url.Values) (*http.Response, error) {
+	u, err := url.Parse(c.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/webhdfs/v1" + path.Join("/", filePath)
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("op", op)
+	query.Set("user.name", defaultUser)
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, body)
+	}
+	return resp, nil
+}
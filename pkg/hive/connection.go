@@ -2,10 +2,13 @@ package hive
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net"
 	"sync"
 	"syscall"
@@ -22,17 +25,80 @@ var (
 	ThriftVersion = hive.TProtocolVersion_HIVE_CLI_SERVICE_PROTOCOL_V8
 )
 
+// TLSConfig configures TLS for connections to a Hive server.
+type TLSConfig struct {
+	Enabled bool
+	// CAFile is a file containing the CA certificates used to verify the
+	// server's certificate. If empty, the system's CA certificates are used.
+	CAFile string
+	// ClientCertFile and ClientKeyFile are files containing the client
+	// certificate and private key used to authenticate to the server.
+	ClientCertFile string
+	ClientKeyFile  string
+	// ServerName is used to verify the server's certificate, if different
+	// from the hostname the server is connected to using.
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+func (cfg TLSConfig) Valid() error {
+	if (cfg.ClientCertFile == "") != (cfg.ClientKeyFile == "") {
+		return fmt.Errorf("Must set both the Hive client certificate and key, or neither")
+	}
+	return nil
+}
+
+// ClientConfig returns the tls.Config used to connect to a Hive server, or
+// nil if TLS isn't enabled.
+func (cfg TLSConfig) ClientConfig() (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if err := cfg.Valid(); err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CAFile != "" {
+		caData, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read Hive CA file: %v", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("no certificates found in Hive CA file %s", cfg.CAFile)
+		}
+	}
+	if cfg.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load Hive client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
 // Connection to a Hive server.
 type Connection struct {
 	client    *hive.TCLIServiceClient
-	transport *thrift.TSocket
+	transport thrift.TTransport
 	session   *hive.TSessionHandle
 	queryLock sync.Mutex
 }
 
-// Connect to a Hive cluster.
-func Connect(host string) (*Connection, error) {
-	transport, err := thrift.NewTSocket(host)
+// Connect to a Hive cluster. If tlsConfig is non-nil, the connection uses
+// TLS.
+func Connect(host string, tlsConfig *tls.Config) (*Connection, error) {
+	var transport thrift.TTransport
+	var err error
+	if tlsConfig != nil {
+		transport, err = thrift.NewTSSLSocket(host, tlsConfig)
+	} else {
+		transport, err = thrift.NewTSocket(host)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to '%s': %v", host, err)
 	}
@@ -65,15 +131,34 @@ func Connect(host string) (*Connection, error) {
 
 // Query a Hive server.
 func (c *Connection) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.QueryContext(context.Background(), query, args...)
+}
+
+// QueryContext queries a Hive server. Statements are executed synchronously,
+// so they can't be cancelled once started, but if ctx has a deadline, it's
+// used as the statement's timeout by Hive servers which support it.
+func (c *Connection) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
 	// Only perform one query at a time
 	c.queryLock.Lock()
 	defer c.queryLock.Unlock()
 
+	// the context may have been cancelled while waiting for the lock
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	req := hive.NewTExecuteStatementReq()
 	req.SessionHandle = c.session
 	req.Statement = query
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout := int64(time.Until(deadline).Seconds())
+		if timeout < 1 {
+			timeout = 1
+		}
+		req.QueryTimeout = timeout
+	}
 
-	resp, err := c.client.ExecuteStatement(context.Background(), req)
+	resp, err := c.client.ExecuteStatement(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -108,27 +193,41 @@ func (c *Connection) Close() error {
 // reconnect when a query fails due to a connection related error
 type reconnectingQueryer struct {
 	hiveHost    string
+	tlsConfig   *tls.Config
 	mu          sync.Mutex
 	conn        *Connection
 	logger      log.FieldLogger
 	maxRetries  int
 	connBackoff time.Duration
 	ctx         context.Context
+
+	queryTimeout time.Duration
 }
 
 // NewReconnectingQueryer returns a reconnectingQueryer that will not attempt
-// to reconnect once the ctx is cancelled.
-func NewReconnectingQueryer(ctx context.Context, logger log.FieldLogger, hiveHost string, connBackoff time.Duration, maxRetries int) *reconnectingQueryer {
+// to reconnect once the ctx is cancelled. If tlsConfig is non-nil, connections
+// use TLS. Connecting is attempted up to maxRetries times, waiting connBackoff
+// after the first attempt, and increasing the wait after each attempt. If
+// queryTimeout is non-zero, it's used as the timeout of queries.
+func NewReconnectingQueryer(ctx context.Context, logger log.FieldLogger, hiveHost string, tlsConfig *tls.Config, connBackoff time.Duration, maxRetries int, queryTimeout time.Duration) *reconnectingQueryer {
 	return &reconnectingQueryer{
-		hiveHost:    hiveHost,
-		logger:      logger,
-		connBackoff: connBackoff,
-		maxRetries:  maxRetries,
-		ctx:         ctx,
+		hiveHost:     hiveHost,
+		tlsConfig:    tlsConfig,
+		logger:       logger,
+		connBackoff:  connBackoff,
+		maxRetries:   maxRetries,
+		ctx:          ctx,
+		queryTimeout: queryTimeout,
 	}
 }
 
 func (q *reconnectingQueryer) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return q.QueryContext(context.Background(), query, args...)
+}
+
+func (q *reconnectingQueryer) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, cancel := q.withQueryTimeout(ctx)
+	defer cancel()
 	for retries := 0; retries < q.maxRetries; retries++ {
 		conn, err := q.getConnection(q.ctx)
 		if err != nil {
@@ -141,7 +240,7 @@ func (q *reconnectingQueryer) Query(query string, args ...interface{}) (*sql.Row
 			// getting it
 			return nil, err
 		}
-		rows, err := conn.Query(query)
+		rows, err := conn.QueryContext(ctx, query)
 		if err != nil {
 			if err == io.EOF || isErrBrokenPipe(err) {
 				q.logger.WithError(err).Debugf("error occurred while making query, attempting to create new connection and retry")
@@ -156,11 +255,20 @@ func (q *reconnectingQueryer) Query(query string, args ...interface{}) (*sql.Row
 		return rows, nil
 	}
 
-	// We've tries 3 times, so close any connection and return an error
+	// We've tried maxRetries times, so close any connection and return an error
 	q.Close()
 	return nil, fmt.Errorf("unable to create new hive connection after existing hive connection closed")
 }
 
+// withQueryTimeout returns a context for a query, which times out after the
+// queryTimeout if it's non-zero.
+func (q *reconnectingQueryer) withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if q.queryTimeout != 0 {
+		return context.WithTimeout(ctx, q.queryTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
 func (q *reconnectingQueryer) Close() error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
@@ -199,7 +307,7 @@ func (q *reconnectingQueryer) newConnection(ctx context.Context) (*Connection, e
 			return false, ctx.Err()
 		default:
 			var err error
-			conn, err = Connect(q.hiveHost)
+			conn, err = Connect(q.hiveHost, q.tlsConfig)
 			if err == nil {
 				return true, nil
 			} else {
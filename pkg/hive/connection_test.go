@@ -0,0 +1,100 @@
+package hive
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSConfigClientConfig(t *testing.T) {
+	tlsConfig, err := TLSConfig{CAFile: "/nonexistent"}.ClientConfig()
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig, "expected no TLS config when TLS is disabled")
+
+	tlsConfig, err = TLSConfig{Enabled: true, ServerName: "hive-server", InsecureSkipVerify: true}.ClientConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "hive-server", tlsConfig.ServerName)
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+	assert.Nil(t, tlsConfig.RootCAs)
+
+	_, err = TLSConfig{Enabled: true, ClientCertFile: "tls.crt"}.ClientConfig()
+	assert.Error(t, err, "expected the client key to be required with the client certificate")
+
+	caFile, err := ioutil.TempFile("", "hive-ca")
+	require.NoError(t, err)
+	defer os.Remove(caFile.Name())
+	caFile.WriteString("not a certificate")
+	caFile.Close()
+	_, err = TLSConfig{Enabled: true, CAFile: caFile.Name()}.ClientConfig()
+	assert.Error(t, err, "expected an error when the CA file contains no certificates")
+}
+
+// newClosingListener returns a listener closing every connection it accepts,
+// so connecting to Hive fails while opening a session, and the number of
+// connections accepted.
+func newClosingListener(t *testing.T) (net.Listener, *int32) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	var accepted int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&accepted, 1)
+			conn.Close()
+		}
+	}()
+	return listener, &accepted
+}
+
+func TestReconnectingQueryerConnRetries(t *testing.T) {
+	listener, accepted := newClosingListener(t)
+	defer listener.Close()
+
+	q := NewReconnectingQueryer(context.Background(), logrus.New(), listener.Addr().String(), nil, time.Millisecond, 3, 0)
+	_, err := q.getConnection(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(accepted), "expected connecting to be attempted HiveMaxConnRetries times")
+
+	// connecting isn't attempted once the ctx is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = q.getConnection(ctx)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(accepted))
+}
+
+func TestReconnectingQueryerQueryTimeout(t *testing.T) {
+	q := NewReconnectingQueryer(context.Background(), logrus.New(), "hive:10000", nil, time.Millisecond, 3, 0)
+	ctx, cancel := q.withQueryTimeout(context.Background())
+	_, hasDeadline := ctx.Deadline()
+	assert.False(t, hasDeadline, "expected queries not to time out by default")
+	cancel()
+	assert.Error(t, ctx.Err())
+
+	q = NewReconnectingQueryer(context.Background(), logrus.New(), "hive:10000", nil, time.Millisecond, 3, time.Hour)
+	ctx, cancel = q.withQueryTimeout(context.Background())
+	defer cancel()
+	deadline, hasDeadline := ctx.Deadline()
+	require.True(t, hasDeadline)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), deadline, time.Minute)
+
+	// a shorter deadline of the query's ctx takes precedence
+	parent, cancelParent := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelParent()
+	ctx, cancel = q.withQueryTimeout(parent)
+	defer cancel()
+	parentDeadline, _ := parent.Deadline()
+	deadline, _ = ctx.Deadline()
+	assert.Equal(t, parentDeadline, deadline)
+}
@@ -0,0 +1,369 @@
+package hive
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.apache.org/thrift.git/lib/go/thrift"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	metastore "github.com/operator-framework/operator-metering/pkg/hive/metastore_thrift"
+)
+
+const (
+	defaultDatabase = "default"
+
+	// metastoreSocketTimeout is how long to wait for the Metastore to
+	// respond. Dropping a table can delete a lot of data, so it's long.
+	metastoreSocketTimeout = 5 * time.Minute
+
+	lazySimpleSerde = "org.apache.hadoop.hive.serde2.lazy.LazySimpleSerDe"
+)
+
+// fileFormat are the classes used to read and write a table's files, and
+// it's default SerDe, for a file format used with STORED AS.
+type fileFormat struct {
+	inputFormat  string
+	outputFormat string
+	serde        string
+}
+
+var fileFormats = map[string]fileFormat{
+	"textfile": {
+		inputFormat:  "org.apache.hadoop.mapred.TextInputFormat",
+		outputFormat: "org.apache.hadoop.hive.ql.io.HiveIgnoreKeyTextOutputFormat",
+		serde:        lazySimpleSerde,
+	},
+	"sequencefile": {
+		inputFormat:  "org.apache.hadoop.mapred.SequenceFileInputFormat",
+		outputFormat: "org.apache.hadoop.hive.ql.io.HiveSequenceFileOutputFormat",
+		serde:        lazySimpleSerde,
+	},
+	"rcfile": {
+		inputFormat:  "org.apache.hadoop.hive.ql.io.RCFileInputFormat",
+		outputFormat: "org.apache.hadoop.hive.ql.io.RCFileOutputFormat",
+		serde:        "org.apache.hadoop.hive.serde2.columnar.LazyBinaryColumnarSerDe",
+	},
+	"orc": {
+		inputFormat:  "org.apache.hadoop.hive.ql.io.orc.OrcInputFormat",
+		outputFormat: "org.apache.hadoop.hive.ql.io.orc.OrcOutputFormat",
+		serde:        "org.apache.hadoop.hive.ql.io.orc.OrcSerde",
+	},
+	"parquet": {
+		inputFormat:  "org.apache.hadoop.hive.ql.io.parquet.MapredParquetInputFormat",
+		outputFormat: "org.apache.hadoop.hive.ql.io.parquet.MapredParquetOutputFormat",
+		serde:        "org.apache.hadoop.hive.ql.io.parquet.serde.ParquetHiveSerDe",
+	},
+}
+
+// MetastoreClient manages tables and partitions using the Hive Metastore's
+// Thrift API directly, instead of running DDL statements using HiveServer2.
+// A new connection is opened for each operation, so connections closed by the
+// Metastore never need to be detected and retried.
+type MetastoreClient struct {
+	host        string
+	logger      log.FieldLogger
+	connBackoff time.Duration
+	maxRetries  int
+	ctx         context.Context
+}
+
+// NewMetastoreClient returns a MetastoreClient connecting to the Metastore at
+// host. It will not attempt to connect once the ctx is cancelled.
+func NewMetastoreClient(ctx context.Context, logger log.FieldLogger, host string, connBackoff time.Duration, maxRetries int) *MetastoreClient {
+	return &MetastoreClient{
+		host:        host,
+		logger:      logger,
+		connBackoff: connBackoff,
+		maxRetries:  maxRetries,
+		ctx:         ctx,
+	}
+}
+
+// withClient connects to the Metastore, and calls f with a client using the
+// connection, closing the connection once f returns. Connecting is given up
+// once either ctx or the client's ctx is cancelled.
+func (c *MetastoreClient) withClient(ctx context.Context, f func(client *metastore.ThriftHiveMetastoreClient) error) error {
+	var transport *thrift.TSocket
+	backoff := wait.Backoff{
+		Duration: c.connBackoff,
+		Factor:   1.25,
+		Steps:    c.maxRetries,
+	}
+	cond := func() (bool, error) {
+		// check for cancellation
+		select {
+		case <-c.ctx.Done():
+			return false, c.ctx.Err()
+		case <-ctx.Done():
+			return false, ctx.Err()
+		default:
+		}
+		var err error
+		transport, err = thrift.NewTSocketTimeout(c.host, metastoreSocketTimeout)
+		if err == nil {
+			err = transport.Open()
+		}
+		if err != nil {
+			c.logger.WithError(err).Debugf("error encountered when connecting to the hive metastore, backing off and trying again")
+			return false, nil
+		}
+		return true, nil
+	}
+	if err := wait.ExponentialBackoff(backoff, cond); err != nil {
+		return fmt.Errorf("unable to connect to the hive metastore at %s: %v", c.host, err)
+	}
+	defer transport.Close()
+
+	client := metastore.NewThriftHiveMetastoreClientFactory(transport, thrift.NewTBinaryProtocolFactoryDefault())
+	return f(client)
+}
+
+// CreateTable creates the table, and if params.IgnoreExists is set, does
+// nothing if the table already exists.
+func (c *MetastoreClient) CreateTable(ctx context.Context, params TableParameters, properties TableProperties) error {
+	table, err := generateMetastoreTable(params, properties, time.Now())
+	if err != nil {
+		return err
+	}
+	return c.withClient(ctx, func(client *metastore.ThriftHiveMetastoreClient) error {
+		err := client.CreateTable(ctx, table)
+		if params.IgnoreExists && metastore.IsException(err, metastore.AlreadyExistsException) {
+			return nil
+		}
+		return err
+	})
+}
+
+// DropTable drops the table, deleting it's data without moving it to the
+// trash if the table isn't external.
+func (c *MetastoreClient) DropTable(ctx context.Context, tableName string, ignoreNotExists bool) error {
+	dbName, tableName := splitTableName(tableName)
+	return c.withClient(ctx, func(client *metastore.ThriftHiveMetastoreClient) error {
+		err := client.DropTableWithEnvironmentContext(ctx, dbName, tableName, true, &metastore.EnvironmentContext{
+			Properties: map[string]string{"ifPurge": "TRUE"},
+		})
+		if ignoreNotExists && metastore.IsException(err, metastore.NoSuchObjectException) {
+			return nil
+		}
+		return err
+	})
+}
+
+// SetTableExternal marks a table as external or managed.
+func (c *MetastoreClient) SetTableExternal(ctx context.Context, tableName string, external bool) error {
+	dbName, tableName := splitTableName(tableName)
+	return c.withClient(ctx, func(client *metastore.ThriftHiveMetastoreClient) error {
+		table, err := client.GetTable(ctx, dbName, tableName)
+		if err != nil {
+			return err
+		}
+		if table.Parameters == nil {
+			table.Parameters = make(map[string]string)
+		}
+		if external {
+			table.Parameters["EXTERNAL"] = "TRUE"
+			table.TableType = metastore.TableTypeExternal
+		} else {
+			table.Parameters["EXTERNAL"] = "FALSE"
+			table.TableType = metastore.TableTypeManaged
+		}
+		return client.AlterTable(ctx, dbName, tableName, table)
+	})
+}
+
+// AddColumns adds columns to the table after it's existing columns. Existing
+// partitions keep their columns, so rows stored in them have NULL values for
+// the new columns.
+func (c *MetastoreClient) AddColumns(ctx context.Context, tableName string, columns []Column) error {
+	dbName, tableName := splitTableName(tableName)
+	return c.withClient(ctx, func(client *metastore.ThriftHiveMetastoreClient) error {
+		table, err := client.GetTable(ctx, dbName, tableName)
+		if err != nil {
+			return err
+		}
+		if table.Sd == nil {
+			return fmt.Errorf("table %s.%s has no storage descriptor", dbName, tableName)
+		}
+		table.Sd.Cols = append(table.Sd.Cols, metastoreColumns(columns)...)
+		return client.AlterTable(ctx, dbName, tableName, table)
+	})
+}
+
+// GetTableSize returns the size in bytes of the data stored by the table,
+// using the totalSize statistic recorded when data is written to the table.
+// If the table has no statistics recorded, 0 is returned.
+func (c *MetastoreClient) GetTableSize(ctx context.Context, tableName string) (int64, error) {
+	dbName, tableName := splitTableName(tableName)
+	var size int64
+	err := c.withClient(ctx, func(client *metastore.ThriftHiveMetastoreClient) error {
+		table, err := client.GetTable(ctx, dbName, tableName)
+		if err != nil {
+			return err
+		}
+		size, _ = strconv.ParseInt(table.Parameters["totalSize"], 10, 64)
+		return nil
+	})
+	return size, err
+}
+
+// AddPartition adds the partition of the table with the values of each
+// partition column in spec, stored at location. If the partition already
+// exists, nothing is done.
+func (c *MetastoreClient) AddPartition(ctx context.Context, tableName string, spec map[string]string, location string) error {
+	dbName, tableName := splitTableName(tableName)
+	return c.withClient(ctx, func(client *metastore.ThriftHiveMetastoreClient) error {
+		table, err := client.GetTable(ctx, dbName, tableName)
+		if err != nil {
+			return err
+		}
+		values, err := partitionValues(table, spec)
+		if err != nil {
+			return err
+		}
+		if table.Sd == nil {
+			return fmt.Errorf("table %s has no storage descriptor", tableName)
+		}
+		// partitions are stored like the table, except for their location
+		sd := *table.Sd
+		sd.Location = location
+		_, err = client.AddPartition(ctx, &metastore.Partition{
+			Values:     values,
+			DbName:     dbName,
+			TableName:  tableName,
+			CreateTime: int32(time.Now().Unix()),
+			Sd:         &sd,
+			Parameters: map[string]string{},
+		})
+		if metastore.IsException(err, metastore.AlreadyExistsException) {
+			return nil
+		}
+		return err
+	})
+}
+
+// DropPartition drops the partition of the table with the values of each
+// partition column in spec. If the partition doesn't exist, nothing is done.
+func (c *MetastoreClient) DropPartition(ctx context.Context, tableName string, spec map[string]string) error {
+	dbName, tableName := splitTableName(tableName)
+	return c.withClient(ctx, func(client *metastore.ThriftHiveMetastoreClient) error {
+		table, err := client.GetTable(ctx, dbName, tableName)
+		if err != nil {
+			return err
+		}
+		values, err := partitionValues(table, spec)
+		if err != nil {
+			return err
+		}
+		_, err = client.DropPartition(ctx, dbName, tableName, values, true)
+		if metastore.IsException(err, metastore.NoSuchObjectException) {
+			return nil
+		}
+		return err
+	})
+}
+
+// partitionValues returns the values in spec, in the order of the table's
+// partition columns.
+func partitionValues(table *metastore.Table, spec map[string]string) ([]string, error) {
+	if len(spec) != len(table.PartitionKeys) {
+		return nil, fmt.Errorf("table %s has %d partition columns, got values for %d", table.TableName, len(table.PartitionKeys), len(spec))
+	}
+	values := make([]string, len(table.PartitionKeys))
+	for i, key := range table.PartitionKeys {
+		value, ok := spec[key.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing value for partition column %s of table %s", key.Name, table.TableName)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// splitTableName splits a table name which may be qualified by it's database
+// into the database and table name.
+func splitTableName(name string) (dbName, tableName string) {
+	if i := strings.Index(name, "."); i != -1 {
+		return name[:i], name[i+1:]
+	}
+	return defaultDatabase, name
+}
+
+// generateMetastoreTable returns the Metastore table created by the CREATE
+// TABLE statement generateCreateTableSQL returns for the same parameters and
+// properties.
+func generateMetastoreTable(params TableParameters, properties TableProperties, now time.Time) (*metastore.Table, error) {
+	dbName, tableName := splitTableName(params.Name)
+
+	format := fileFormats["textfile"]
+	if properties.InputFormat != "" && properties.OutputFormat != "" {
+		format = fileFormat{
+			inputFormat:  properties.InputFormat,
+			outputFormat: properties.OutputFormat,
+			serde:        lazySimpleSerde,
+		}
+	} else if properties.FileFormat != "" {
+		var ok bool
+		format, ok = fileFormats[strings.ToLower(properties.FileFormat)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported file format %q, the hive metastore can only be used to create tables with the file formats textfile, sequencefile, rcfile, orc, or parquet, or an input and output format", properties.FileFormat)
+		}
+	}
+	serde := format.serde
+	if properties.SerdeFormat != "" {
+		serde = properties.SerdeFormat
+	}
+	serdeParameters := map[string]string{"serialization.format": "1"}
+	for k, v := range properties.SerdeRowProperties {
+		serdeParameters[k] = v
+	}
+
+	tableType := metastore.TableTypeManaged
+	tableParameters := make(map[string]string)
+	for k, v := range properties.TableProperties {
+		tableParameters[k] = v
+	}
+	if properties.External {
+		tableType = metastore.TableTypeExternal
+		tableParameters["EXTERNAL"] = "TRUE"
+	}
+
+	return &metastore.Table{
+		TableName:  strings.ToLower(tableName),
+		DbName:     strings.ToLower(dbName),
+		CreateTime: int32(now.Unix()),
+		Sd: &metastore.StorageDescriptor{
+			Cols:         metastoreColumns(params.Columns),
+			Location:     properties.Location,
+			InputFormat:  format.inputFormat,
+			OutputFormat: format.outputFormat,
+			NumBuckets:   -1,
+			BucketCols:   []string{},
+			SerdeInfo: &metastore.SerDeInfo{
+				SerializationLib: serde,
+				Parameters:       serdeParameters,
+			},
+			Parameters: map[string]string{},
+		},
+		PartitionKeys: metastoreColumns(params.Partitions),
+		Parameters:    tableParameters,
+		TableType:     tableType,
+	}, nil
+}
+
+// metastoreColumns converts the columns into the form Hive stores them in the
+// Metastore, with lowercase names and types without whitespace.
+func metastoreColumns(columns []Column) []*metastore.FieldSchema {
+	fields := make([]*metastore.FieldSchema, len(columns))
+	for i, col := range columns {
+		fields[i] = &metastore.FieldSchema{
+			Name: strings.ToLower(col.Name),
+			Type: strings.ToLower(strings.Join(strings.Fields(col.Type), "")),
+		}
+	}
+	return fields
+}
@@ -0,0 +1,67 @@
+package hive
+
+import (
+	"testing"
+	"time"
+
+	"git.apache.org/thrift.git/lib/go/thrift"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metastore "github.com/operator-framework/operator-metering/pkg/hive/metastore_thrift"
+)
+
+func TestGenerateMetastoreTable(t *testing.T) {
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	params := TableParameters{
+		Name:       "metering.datasource_Pod_Request_CPU_Cores",
+		Columns:    []Column{{Name: "amount", Type: "double"}, {Name: "Labels", Type: "map<string, string>"}},
+		Partitions: []Column{{Name: "dt", Type: "string"}},
+	}
+
+	tbl, err := generateMetastoreTable(params, TableProperties{
+		Location:   "s3a://bucket/path",
+		FileFormat: "ORC",
+		External:   true,
+	}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "metering", tbl.DbName)
+	assert.Equal(t, "datasource_pod_request_cpu_cores", tbl.TableName)
+	assert.Equal(t, int32(now.Unix()), tbl.CreateTime)
+	assert.Equal(t, metastore.TableTypeExternal, tbl.TableType)
+	assert.Equal(t, map[string]string{"EXTERNAL": "TRUE"}, tbl.Parameters)
+	assert.Equal(t, "s3a://bucket/path", tbl.Sd.Location)
+	assert.Equal(t, fileFormats["orc"].inputFormat, tbl.Sd.InputFormat)
+	assert.Equal(t, fileFormats["orc"].serde, tbl.Sd.SerdeInfo.SerializationLib)
+	assert.Equal(t, []*metastore.FieldSchema{{Name: "amount", Type: "double"}, {Name: "labels", Type: "map<string,string>"}}, tbl.Sd.Cols)
+	assert.Equal(t, []*metastore.FieldSchema{{Name: "dt", Type: "string"}}, tbl.PartitionKeys)
+
+	tbl, err = generateMetastoreTable(TableParameters{Name: "managed"}, TableProperties{
+		SerdeFormat:        "org.apache.hadoop.hive.serde2.OpenCSVSerde",
+		SerdeRowProperties: map[string]string{"separatorChar": ","},
+	}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "default", tbl.DbName)
+	assert.Equal(t, metastore.TableTypeManaged, tbl.TableType)
+	assert.Equal(t, fileFormats["textfile"].inputFormat, tbl.Sd.InputFormat)
+	assert.Equal(t, "org.apache.hadoop.hive.serde2.OpenCSVSerde", tbl.Sd.SerdeInfo.SerializationLib)
+	assert.Equal(t, map[string]string{"serialization.format": "1", "separatorChar": ","}, tbl.Sd.SerdeInfo.Parameters)
+
+	_, err = generateMetastoreTable(params, TableProperties{FileFormat: "avro"}, now)
+	assert.Error(t, err)
+}
+
+func TestMetastoreTableRoundTrip(t *testing.T) {
+	tbl, err := generateMetastoreTable(TableParameters{
+		Name:       "datasource_pod_request_cpu_cores",
+		Columns:    []Column{{Name: "amount", Type: "double"}},
+		Partitions: []Column{{Name: "dt", Type: "string"}},
+	}, TableProperties{Location: "hdfs://hdfs-namenode-0:9820/operator_metering/storage/test", External: true}, time.Now())
+	require.NoError(t, err)
+
+	buf := thrift.NewTMemoryBuffer()
+	require.NoError(t, tbl.Write(thrift.NewTBinaryProtocolTransport(buf)))
+	read := &metastore.Table{}
+	require.NoError(t, read.Read(thrift.NewTBinaryProtocolTransport(buf)))
+	assert.Equal(t, tbl, read)
+}
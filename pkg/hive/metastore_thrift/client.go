@@ -0,0 +1,182 @@
+package metastore_thrift
+
+import (
+	"context"
+	"errors"
+
+	"git.apache.org/thrift.git/lib/go/thrift"
+)
+
+// args are the arguments of a method call.
+type args struct {
+	method string
+	fields []field
+}
+
+func (a *args) Write(oprot thrift.TProtocol) error {
+	return writeStruct(oprot, a.method+"_args", a.fields)
+}
+
+func (a *args) Read(iprot thrift.TProtocol) error {
+	return errors.New("reading arguments is not supported")
+}
+
+// result is the result of a method call. The success field of the result is
+// read using readSuccess, if it's set. Each exception field's ID is mapped to
+// the exception's name in exceptions.
+type result struct {
+	readSuccess func(iprot thrift.TProtocol) error
+	exceptions  map[int16]string
+
+	exception *Exception
+}
+
+func (r *result) Write(oprot thrift.TProtocol) error {
+	return errors.New("writing results is not supported")
+}
+
+func (r *result) Read(iprot thrift.TProtocol) error {
+	return readStruct(iprot, func(id int16, typ thrift.TType) (bool, error) {
+		if id == 0 && r.readSuccess != nil {
+			return true, r.readSuccess(iprot)
+		}
+		if name, ok := r.exceptions[id]; ok && typ == thrift.STRUCT {
+			r.exception = &Exception{Name: name}
+			return true, r.exception.Read(iprot)
+		}
+		return false, nil
+	})
+}
+
+// ThriftHiveMetastoreClient calls methods of the ThriftHiveMetastore
+// service. It is not safe for concurrent use.
+type ThriftHiveMetastoreClient struct {
+	c thrift.TClient
+}
+
+func NewThriftHiveMetastoreClientFactory(t thrift.TTransport, f thrift.TProtocolFactory) *ThriftHiveMetastoreClient {
+	return &ThriftHiveMetastoreClient{
+		c: thrift.NewTStandardClient(f.GetProtocol(t), f.GetProtocol(t)),
+	}
+}
+
+func (c *ThriftHiveMetastoreClient) call(ctx context.Context, a *args, r *result) error {
+	if err := c.c.Call(ctx, a.method, a, r); err != nil {
+		return err
+	}
+	if r.exception != nil {
+		return r.exception
+	}
+	return nil
+}
+
+func (c *ThriftHiveMetastoreClient) CreateTable(ctx context.Context, tbl *Table) error {
+	return c.call(ctx, &args{
+		method: "create_table",
+		fields: []field{structField(1, tbl)},
+	}, &result{
+		exceptions: map[int16]string{
+			1: AlreadyExistsException,
+			2: InvalidObjectException,
+			3: MetaException,
+			4: NoSuchObjectException,
+		},
+	})
+}
+
+func (c *ThriftHiveMetastoreClient) GetTable(ctx context.Context, dbName, tableName string) (*Table, error) {
+	tbl := &Table{}
+	var success bool
+	err := c.call(ctx, &args{
+		method: "get_table",
+		fields: []field{stringField(1, dbName), stringField(2, tableName)},
+	}, &result{
+		readSuccess: func(iprot thrift.TProtocol) error {
+			success = true
+			return tbl.Read(iprot)
+		},
+		exceptions: map[int16]string{
+			1: MetaException,
+			2: NoSuchObjectException,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !success {
+		return nil, thrift.NewTApplicationException(thrift.MISSING_RESULT, "get_table failed: unknown result")
+	}
+	return tbl, nil
+}
+
+func (c *ThriftHiveMetastoreClient) AlterTable(ctx context.Context, dbName, tableName string, newTbl *Table) error {
+	return c.call(ctx, &args{
+		method: "alter_table",
+		fields: []field{stringField(1, dbName), stringField(2, tableName), structField(3, newTbl)},
+	}, &result{
+		exceptions: map[int16]string{
+			1: InvalidOperationException,
+			2: MetaException,
+		},
+	})
+}
+
+func (c *ThriftHiveMetastoreClient) DropTableWithEnvironmentContext(ctx context.Context, dbName, tableName string, deleteData bool, environmentContext *EnvironmentContext) error {
+	return c.call(ctx, &args{
+		method: "drop_table_with_environment_context",
+		fields: []field{
+			stringField(1, dbName),
+			stringField(2, tableName),
+			boolField(3, deleteData),
+			structField(4, environmentContext),
+		},
+	}, &result{
+		exceptions: map[int16]string{
+			1: NoSuchObjectException,
+			2: MetaException,
+		},
+	})
+}
+
+func (c *ThriftHiveMetastoreClient) AddPartition(ctx context.Context, newPart *Partition) (*Partition, error) {
+	part := &Partition{}
+	err := c.call(ctx, &args{
+		method: "add_partition",
+		fields: []field{structField(1, newPart)},
+	}, &result{
+		readSuccess: part.Read,
+		exceptions: map[int16]string{
+			1: InvalidObjectException,
+			2: AlreadyExistsException,
+			3: MetaException,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return part, nil
+}
+
+func (c *ThriftHiveMetastoreClient) DropPartition(ctx context.Context, dbName, tableName string, partVals []string, deleteData bool) (bool, error) {
+	var dropped bool
+	err := c.call(ctx, &args{
+		method: "drop_partition",
+		fields: []field{
+			stringField(1, dbName),
+			stringField(2, tableName),
+			stringListField(3, partVals),
+			boolField(4, deleteData),
+		},
+	}, &result{
+		readSuccess: func(iprot thrift.TProtocol) error {
+			var err error
+			dropped, err = iprot.ReadBool()
+			return err
+		},
+		exceptions: map[int16]string{
+			1: NoSuchObjectException,
+			2: MetaException,
+		},
+	})
+	return dropped, err
+}
@@ -0,0 +1,549 @@
+// Package metastore_thrift implements the subset of the Hive Metastore Thrift
+// API, defined in hive_metastore.thrift, used to manage tables and
+// partitions. Only the fields of each struct which are used are implemented,
+// other fields are skipped when reading.
+package metastore_thrift
+
+import (
+	"fmt"
+
+	"git.apache.org/thrift.git/lib/go/thrift"
+)
+
+// field is a field of a struct, written using write.
+type field struct {
+	id    int16
+	typ   thrift.TType
+	write func(oprot thrift.TProtocol) error
+}
+
+func writeStruct(oprot thrift.TProtocol, name string, fields []field) error {
+	if err := oprot.WriteStructBegin(name); err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if err := oprot.WriteFieldBegin("", f.typ, f.id); err != nil {
+			return err
+		}
+		if err := f.write(oprot); err != nil {
+			return fmt.Errorf("%s field %d write error: %v", name, f.id, err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return err
+	}
+	return oprot.WriteStructEnd()
+}
+
+// readStruct reads a struct, calling readField with the ID and type of each
+// field. readField returns false if it didn't read the field, in which case
+// it's skipped.
+func readStruct(iprot thrift.TProtocol, readField func(id int16, typ thrift.TType) (bool, error)) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return err
+	}
+	for {
+		_, typ, id, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return err
+		}
+		if typ == thrift.STOP {
+			break
+		}
+		read, err := readField(id, typ)
+		if err != nil {
+			return fmt.Errorf("field %d read error: %v", id, err)
+		}
+		if !read {
+			if err := iprot.Skip(typ); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	return iprot.ReadStructEnd()
+}
+
+func stringField(id int16, v string) field {
+	return field{id: id, typ: thrift.STRING, write: func(oprot thrift.TProtocol) error {
+		return oprot.WriteString(v)
+	}}
+}
+
+func i32Field(id int16, v int32) field {
+	return field{id: id, typ: thrift.I32, write: func(oprot thrift.TProtocol) error {
+		return oprot.WriteI32(v)
+	}}
+}
+
+func boolField(id int16, v bool) field {
+	return field{id: id, typ: thrift.BOOL, write: func(oprot thrift.TProtocol) error {
+		return oprot.WriteBool(v)
+	}}
+}
+
+func structField(id int16, v thrift.TStruct) field {
+	return field{id: id, typ: thrift.STRUCT, write: v.Write}
+}
+
+func stringMapField(id int16, m map[string]string) field {
+	return field{id: id, typ: thrift.MAP, write: func(oprot thrift.TProtocol) error {
+		if err := oprot.WriteMapBegin(thrift.STRING, thrift.STRING, len(m)); err != nil {
+			return err
+		}
+		for k, v := range m {
+			if err := oprot.WriteString(k); err != nil {
+				return err
+			}
+			if err := oprot.WriteString(v); err != nil {
+				return err
+			}
+		}
+		return oprot.WriteMapEnd()
+	}}
+}
+
+func stringListField(id int16, l []string) field {
+	return field{id: id, typ: thrift.LIST, write: func(oprot thrift.TProtocol) error {
+		if err := oprot.WriteListBegin(thrift.STRING, len(l)); err != nil {
+			return err
+		}
+		for _, v := range l {
+			if err := oprot.WriteString(v); err != nil {
+				return err
+			}
+		}
+		return oprot.WriteListEnd()
+	}}
+}
+
+func structListField(id int16, l []thrift.TStruct) field {
+	return field{id: id, typ: thrift.LIST, write: func(oprot thrift.TProtocol) error {
+		if err := oprot.WriteListBegin(thrift.STRUCT, len(l)); err != nil {
+			return err
+		}
+		for _, v := range l {
+			if err := v.Write(oprot); err != nil {
+				return err
+			}
+		}
+		return oprot.WriteListEnd()
+	}}
+}
+
+func readStringMap(iprot thrift.TProtocol) (map[string]string, error) {
+	_, _, size, err := iprot.ReadMapBegin()
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]string, size)
+	for i := 0; i < size; i++ {
+		k, err := iprot.ReadString()
+		if err != nil {
+			return nil, err
+		}
+		v, err := iprot.ReadString()
+		if err != nil {
+			return nil, err
+		}
+		m[k] = v
+	}
+	return m, iprot.ReadMapEnd()
+}
+
+func readStringList(iprot thrift.TProtocol) ([]string, error) {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return nil, err
+	}
+	l := make([]string, size)
+	for i := range l {
+		if l[i], err = iprot.ReadString(); err != nil {
+			return nil, err
+		}
+	}
+	return l, iprot.ReadListEnd()
+}
+
+// readStructList reads a list of structs, using newElem to create each
+// element before it's read.
+func readStructList(iprot thrift.TProtocol, newElem func() thrift.TStruct) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < size; i++ {
+		if err := newElem().Read(iprot); err != nil {
+			return err
+		}
+	}
+	return iprot.ReadListEnd()
+}
+
+type FieldSchema struct {
+	Name    string
+	Type    string
+	Comment string
+}
+
+func (p *FieldSchema) Write(oprot thrift.TProtocol) error {
+	return writeStruct(oprot, "FieldSchema", []field{
+		stringField(1, p.Name),
+		stringField(2, p.Type),
+		stringField(3, p.Comment),
+	})
+}
+
+func (p *FieldSchema) Read(iprot thrift.TProtocol) error {
+	return readStruct(iprot, func(id int16, typ thrift.TType) (bool, error) {
+		var err error
+		switch {
+		case id == 1 && typ == thrift.STRING:
+			p.Name, err = iprot.ReadString()
+		case id == 2 && typ == thrift.STRING:
+			p.Type, err = iprot.ReadString()
+		case id == 3 && typ == thrift.STRING:
+			p.Comment, err = iprot.ReadString()
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+type SerDeInfo struct {
+	Name             string
+	SerializationLib string
+	Parameters       map[string]string
+}
+
+func (p *SerDeInfo) Write(oprot thrift.TProtocol) error {
+	return writeStruct(oprot, "SerDeInfo", []field{
+		stringField(1, p.Name),
+		stringField(2, p.SerializationLib),
+		stringMapField(3, p.Parameters),
+	})
+}
+
+func (p *SerDeInfo) Read(iprot thrift.TProtocol) error {
+	return readStruct(iprot, func(id int16, typ thrift.TType) (bool, error) {
+		var err error
+		switch {
+		case id == 1 && typ == thrift.STRING:
+			p.Name, err = iprot.ReadString()
+		case id == 2 && typ == thrift.STRING:
+			p.SerializationLib, err = iprot.ReadString()
+		case id == 3 && typ == thrift.MAP:
+			p.Parameters, err = readStringMap(iprot)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+type Order struct {
+	Col   string
+	Order int32
+}
+
+func (p *Order) Write(oprot thrift.TProtocol) error {
+	return writeStruct(oprot, "Order", []field{
+		stringField(1, p.Col),
+		i32Field(2, p.Order),
+	})
+}
+
+func (p *Order) Read(iprot thrift.TProtocol) error {
+	return readStruct(iprot, func(id int16, typ thrift.TType) (bool, error) {
+		var err error
+		switch {
+		case id == 1 && typ == thrift.STRING:
+			p.Col, err = iprot.ReadString()
+		case id == 2 && typ == thrift.I32:
+			p.Order, err = iprot.ReadI32()
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+type StorageDescriptor struct {
+	Cols         []*FieldSchema
+	Location     string
+	InputFormat  string
+	OutputFormat string
+	Compressed   bool
+	NumBuckets   int32
+	SerdeInfo    *SerDeInfo
+	BucketCols   []string
+	SortCols     []*Order
+	Parameters   map[string]string
+}
+
+func (p *StorageDescriptor) Write(oprot thrift.TProtocol) error {
+	cols := make([]thrift.TStruct, len(p.Cols))
+	for i, col := range p.Cols {
+		cols[i] = col
+	}
+	sortCols := make([]thrift.TStruct, len(p.SortCols))
+	for i, col := range p.SortCols {
+		sortCols[i] = col
+	}
+	serdeInfo := p.SerdeInfo
+	if serdeInfo == nil {
+		serdeInfo = &SerDeInfo{}
+	}
+	return writeStruct(oprot, "StorageDescriptor", []field{
+		structListField(1, cols),
+		stringField(2, p.Location),
+		stringField(3, p.InputFormat),
+		stringField(4, p.OutputFormat),
+		boolField(5, p.Compressed),
+		i32Field(6, p.NumBuckets),
+		structField(7, serdeInfo),
+		stringListField(8, p.BucketCols),
+		structListField(9, sortCols),
+		stringMapField(10, p.Parameters),
+	})
+}
+
+func (p *StorageDescriptor) Read(iprot thrift.TProtocol) error {
+	return readStruct(iprot, func(id int16, typ thrift.TType) (bool, error) {
+		var err error
+		switch {
+		case id == 1 && typ == thrift.LIST:
+			p.Cols = nil
+			err = readStructList(iprot, func() thrift.TStruct {
+				col := &FieldSchema{}
+				p.Cols = append(p.Cols, col)
+				return col
+			})
+		case id == 2 && typ == thrift.STRING:
+			p.Location, err = iprot.ReadString()
+		case id == 3 && typ == thrift.STRING:
+			p.InputFormat, err = iprot.ReadString()
+		case id == 4 && typ == thrift.STRING:
+			p.OutputFormat, err = iprot.ReadString()
+		case id == 5 && typ == thrift.BOOL:
+			p.Compressed, err = iprot.ReadBool()
+		case id == 6 && typ == thrift.I32:
+			p.NumBuckets, err = iprot.ReadI32()
+		case id == 7 && typ == thrift.STRUCT:
+			p.SerdeInfo = &SerDeInfo{}
+			err = p.SerdeInfo.Read(iprot)
+		case id == 8 && typ == thrift.LIST:
+			p.BucketCols, err = readStringList(iprot)
+		case id == 9 && typ == thrift.LIST:
+			p.SortCols = nil
+			err = readStructList(iprot, func() thrift.TStruct {
+				col := &Order{}
+				p.SortCols = append(p.SortCols, col)
+				return col
+			})
+		case id == 10 && typ == thrift.MAP:
+			p.Parameters, err = readStringMap(iprot)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+const (
+	TableTypeManaged  = "MANAGED_TABLE"
+	TableTypeExternal = "EXTERNAL_TABLE"
+)
+
+type Table struct {
+	TableName      string
+	DbName         string
+	Owner          string
+	CreateTime     int32
+	LastAccessTime int32
+	Retention      int32
+	Sd             *StorageDescriptor
+	PartitionKeys  []*FieldSchema
+	Parameters     map[string]string
+	TableType      string
+}
+
+func (p *Table) Write(oprot thrift.TProtocol) error {
+	partitionKeys := make([]thrift.TStruct, len(p.PartitionKeys))
+	for i, key := range p.PartitionKeys {
+		partitionKeys[i] = key
+	}
+	sd := p.Sd
+	if sd == nil {
+		sd = &StorageDescriptor{}
+	}
+	return writeStruct(oprot, "Table", []field{
+		stringField(1, p.TableName),
+		stringField(2, p.DbName),
+		stringField(3, p.Owner),
+		i32Field(4, p.CreateTime),
+		i32Field(5, p.LastAccessTime),
+		i32Field(6, p.Retention),
+		structField(7, sd),
+		structListField(8, partitionKeys),
+		stringMapField(9, p.Parameters),
+		stringField(12, p.TableType),
+	})
+}
+
+func (p *Table) Read(iprot thrift.TProtocol) error {
+	return readStruct(iprot, func(id int16, typ thrift.TType) (bool, error) {
+		var err error
+		switch {
+		case id == 1 && typ == thrift.STRING:
+			p.TableName, err = iprot.ReadString()
+		case id == 2 && typ == thrift.STRING:
+			p.DbName, err = iprot.ReadString()
+		case id == 3 && typ == thrift.STRING:
+			p.Owner, err = iprot.ReadString()
+		case id == 4 && typ == thrift.I32:
+			p.CreateTime, err = iprot.ReadI32()
+		case id == 5 && typ == thrift.I32:
+			p.LastAccessTime, err = iprot.ReadI32()
+		case id == 6 && typ == thrift.I32:
+			p.Retention, err = iprot.ReadI32()
+		case id == 7 && typ == thrift.STRUCT:
+			p.Sd = &StorageDescriptor{}
+			err = p.Sd.Read(iprot)
+		case id == 8 && typ == thrift.LIST:
+			p.PartitionKeys = nil
+			err = readStructList(iprot, func() thrift.TStruct {
+				key := &FieldSchema{}
+				p.PartitionKeys = append(p.PartitionKeys, key)
+				return key
+			})
+		case id == 9 && typ == thrift.MAP:
+			p.Parameters, err = readStringMap(iprot)
+		case id == 12 && typ == thrift.STRING:
+			p.TableType, err = iprot.ReadString()
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+type Partition struct {
+	Values         []string
+	DbName         string
+	TableName      string
+	CreateTime     int32
+	LastAccessTime int32
+	Sd             *StorageDescriptor
+	Parameters     map[string]string
+}
+
+func (p *Partition) Write(oprot thrift.TProtocol) error {
+	sd := p.Sd
+	if sd == nil {
+		sd = &StorageDescriptor{}
+	}
+	return writeStruct(oprot, "Partition", []field{
+		stringListField(1, p.Values),
+		stringField(2, p.DbName),
+		stringField(3, p.TableName),
+		i32Field(4, p.CreateTime),
+		i32Field(5, p.LastAccessTime),
+		structField(6, sd),
+		stringMapField(7, p.Parameters),
+	})
+}
+
+func (p *Partition) Read(iprot thrift.TProtocol) error {
+	return readStruct(iprot, func(id int16, typ thrift.TType) (bool, error) {
+		var err error
+		switch {
+		case id == 1 && typ == thrift.LIST:
+			p.Values, err = readStringList(iprot)
+		case id == 2 && typ == thrift.STRING:
+			p.DbName, err = iprot.ReadString()
+		case id == 3 && typ == thrift.STRING:
+			p.TableName, err = iprot.ReadString()
+		case id == 4 && typ == thrift.I32:
+			p.CreateTime, err = iprot.ReadI32()
+		case id == 5 && typ == thrift.I32:
+			p.LastAccessTime, err = iprot.ReadI32()
+		case id == 6 && typ == thrift.STRUCT:
+			p.Sd = &StorageDescriptor{}
+			err = p.Sd.Read(iprot)
+		case id == 7 && typ == thrift.MAP:
+			p.Parameters, err = readStringMap(iprot)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+type EnvironmentContext struct {
+	Properties map[string]string
+}
+
+func (p *EnvironmentContext) Write(oprot thrift.TProtocol) error {
+	return writeStruct(oprot, "EnvironmentContext", []field{
+		stringMapField(1, p.Properties),
+	})
+}
+
+func (p *EnvironmentContext) Read(iprot thrift.TProtocol) error {
+	return readStruct(iprot, func(id int16, typ thrift.TType) (bool, error) {
+		var err error
+		if id == 1 && typ == thrift.MAP {
+			p.Properties, err = readStringMap(iprot)
+			return true, err
+		}
+		return false, nil
+	})
+}
+
+// Exception names of the exceptions thrown by the Metastore.
+const (
+	AlreadyExistsException    = "AlreadyExistsException"
+	InvalidObjectException    = "InvalidObjectException"
+	InvalidOperationException = "InvalidOperationException"
+	MetaException             = "MetaException"
+	NoSuchObjectException     = "NoSuchObjectException"
+)
+
+// Exception is an exception thrown by a Metastore method. Every exception the
+// Metastore throws has only a message, so they're distinguished by Name.
+type Exception struct {
+	Name    string
+	Message string
+}
+
+func (e *Exception) Error() string {
+	return fmt.Sprintf("%s: %s", e.Name, e.Message)
+}
+
+func (e *Exception) Read(iprot thrift.TProtocol) error {
+	return readStruct(iprot, func(id int16, typ thrift.TType) (bool, error) {
+		var err error
+		if id == 1 && typ == thrift.STRING {
+			e.Message, err = iprot.ReadString()
+			return true, err
+		}
+		return false, nil
+	})
+}
+
+func (e *Exception) Write(oprot thrift.TProtocol) error {
+	return writeStruct(oprot, e.Name, []field{stringField(1, e.Message)})
+}
+
+// IsException returns true if err is an Exception with the name.
+func IsException(err error, name string) bool {
+	e, ok := err.(*Exception)
+	return ok && e.Name == name
+}
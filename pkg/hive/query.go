@@ -18,6 +18,59 @@ func generateDropTableSQL(name string, ignoreNotExists, purge bool) string {
 	return fmt.Sprintf("DROP TABLE %s %s %s", ifExists, name, purgeStr)
 }
 
+// generateSetTableExternalSQL returns a query which marks a table as external
+// or managed. Hive only removes the data of managed tables when they're
+// dropped, so this can be used to ensure an external table's data is deleted.
+func generateSetTableExternalSQL(name string, external bool) string {
+	externalStr := "FALSE"
+	if external {
+		externalStr = "TRUE"
+	}
+	return fmt.Sprintf("ALTER TABLE %s SET TBLPROPERTIES ('EXTERNAL'='%s')", name, externalStr)
+}
+
+// generateShowTablePropertySQL returns a query which returns the value of a
+// single table property.
+func generateShowTablePropertySQL(name, property string) string {
+	return fmt.Sprintf("SHOW TBLPROPERTIES %s('%s')", name, property)
+}
+
+// generateInsertIntoTableSQL returns a query which appends the results of
+// selectQuery to the table.
+func generateInsertIntoTableSQL(name, selectQuery string) string {
+	return fmt.Sprintf("INSERT INTO TABLE %s %s", name, selectQuery)
+}
+
+// generateInsertOverwritePartitionSQL returns a query which replaces the
+// contents of a single partition of the table with the results of
+// selectQuery.
+func generateInsertOverwritePartitionSQL(name, partitionColumn, partitionValue, selectQuery string) string {
+	return fmt.Sprintf("INSERT OVERWRITE TABLE %s PARTITION (`%s`='%s') %s", name, partitionColumn, partitionValue, selectQuery)
+}
+
+// generateDropPartitionSQL returns a query which drops a single partition of
+// the table.
+func generateDropPartitionSQL(name, partitionColumn, partitionValue string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP IF EXISTS PARTITION (`%s`='%s')", name, partitionColumn, partitionValue)
+}
+
+// generateAddColumnsSQL returns a query which adds columns to the table after
+// it's existing columns. If cascade is true, the columns are also added to the
+// metadata of the table's existing partitions.
+func generateAddColumnsSQL(name string, columns []Column, cascade bool) string {
+	query := fmt.Sprintf("ALTER TABLE %s ADD COLUMNS (%s)", name, generateColumnListSQL(columns))
+	if cascade {
+		query += " CASCADE"
+	}
+	return query
+}
+
+// generateRepairTableSQL returns a query which adds partitions of the table
+// which exist in it's location, but not the metastore.
+func generateRepairTableSQL(name string) string {
+	return fmt.Sprintf("MSCK REPAIR TABLE %s", name)
+}
+
 // generateCreateTableSQL returns a query for a CREATE statement which instantiates a new external Hive table.
 // If is external is set, an external Hive table will be used.
 func generateCreateTableSQL(params TableParameters, properties TableProperties) string {
@@ -39,24 +92,33 @@ func generateCreateTableSQL(params TableParameters, properties TableProperties)
 	}
 
 	serdeFormatStr := ""
-	if properties.SerdeFormat != "" && properties.SerdeRowProperties != nil {
-		serdeFormatStr = fmt.Sprintf("ROW FORMAT SERDE '%s' WITH SERDEPROPERTIES (%s)", properties.SerdeFormat, generateSerdeRowPropertiesSQL(properties.SerdeRowProperties))
+	if properties.SerdeFormat != "" {
+		serdeFormatStr = fmt.Sprintf("ROW FORMAT SERDE '%s'", properties.SerdeFormat)
+		if len(properties.SerdeRowProperties) != 0 {
+			serdeFormatStr += fmt.Sprintf(" WITH SERDEPROPERTIES (%s)", generateSerdeRowPropertiesSQL(properties.SerdeRowProperties))
+		}
 	}
 	location := ""
 	if properties.Location != "" {
 		location = fmt.Sprintf(`LOCATION "%s"`, properties.Location)
 	}
 	format := ""
-	if properties.FileFormat != "" {
+	if properties.InputFormat != "" && properties.OutputFormat != "" {
+		format = fmt.Sprintf("STORED AS INPUTFORMAT '%s' OUTPUTFORMAT '%s'", properties.InputFormat, properties.OutputFormat)
+	} else if properties.FileFormat != "" {
 		format = fmt.Sprintf("STORED AS %s", properties.FileFormat)
 	}
+	tblProperties := ""
+	if len(properties.TableProperties) != 0 {
+		tblProperties = fmt.Sprintf("TBLPROPERTIES (%s)", generateSerdeRowPropertiesSQL(properties.TableProperties))
+	}
 	return fmt.Sprintf(
 		`CREATE %s TABLE %s
 %s (%s) %s
-%s %s %s`,
+%s %s %s %s`,
 		tableType, ifNotExists,
 		params.Name, columnsStr, partitionedBy,
-		serdeFormatStr, format, location,
+		serdeFormatStr, format, location, tblProperties,
 	)
 }
 
@@ -0,0 +1,26 @@
+package hive
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateCreateTableSQLSerde(t *testing.T) {
+	params := TableParameters{Name: "billing", Columns: []Column{{Name: "account", Type: "string"}}}
+
+	query := generateCreateTableSQL(params, TableProperties{SerdeFormat: "org.apache.hive.hcatalog.data.JsonSerDe"})
+	assert.Contains(t, query, "ROW FORMAT SERDE 'org.apache.hive.hcatalog.data.JsonSerDe'")
+	assert.NotContains(t, query, "SERDEPROPERTIES", "expected a serde without properties to be used without SERDEPROPERTIES")
+
+	query = generateCreateTableSQL(params, TableProperties{
+		SerdeFormat:        "org.apache.hadoop.hive.serde2.OpenCSVSerde",
+		SerdeRowProperties: map[string]string{"separatorChar": "|"},
+	})
+	assert.Contains(t, query, "ROW FORMAT SERDE 'org.apache.hadoop.hive.serde2.OpenCSVSerde' WITH SERDEPROPERTIES (")
+	assert.Contains(t, query, "separatorChar")
+}
+
+func TestGenerateRepairTableSQL(t *testing.T) {
+	assert.Equal(t, "MSCK REPAIR TABLE datasource_billing", generateRepairTableSQL("datasource_billing"))
+}
@@ -1,8 +1,12 @@
 package hive
 
 import (
+	"context"
+	"fmt"
 	"net/url"
 	"path"
+	"strconv"
+	"strings"
 
 	"github.com/operator-framework/operator-metering/pkg/db"
 )
@@ -20,25 +24,160 @@ type TableParameters struct {
 }
 
 type TableProperties struct {
-	Location           string            `json:"location,omitempty"`
-	SerdeFormat        string            `json:"serdeFormat,omitempty"`
-	FileFormat         string            `json:"fileFormat,omitempty"`
+	Location    string `json:"location,omitempty"`
+	SerdeFormat string `json:"serdeFormat,omitempty"`
+	FileFormat  string `json:"fileFormat,omitempty"`
+	// InputFormat and OutputFormat are the Hadoop classes used to read and
+	// write the table's files. If set, they're used instead of FileFormat.
+	InputFormat        string            `json:"inputFormat,omitempty"`
+	OutputFormat       string            `json:"outputFormat,omitempty"`
 	SerdeRowProperties map[string]string `json:"serdeRowProperties,omitempty"`
 	External           bool              `json:"external,omitempty"`
+	// TableProperties are set using TBLPROPERTIES.
+	TableProperties map[string]string `json:"tableProperties,omitempty"`
 }
 
-func ExecuteCreateTable(queryer db.Queryer, params TableParameters, properties TableProperties) error {
+// compressionTableProperty is the table property configuring the compression
+// of each file format supporting it.
+var compressionTableProperty = map[string]string{
+	"orc":     "orc.compress",
+	"parquet": "parquet.compression",
+}
+
+// compressionCodecs maps the codecs tables can be compressed using to the
+// name of the codec for each file format.
+var compressionCodecs = map[string]map[string]string{
+	"none":   {"orc": "NONE", "parquet": "UNCOMPRESSED"},
+	"snappy": {"orc": "SNAPPY", "parquet": "SNAPPY"},
+	"zstd":   {"orc": "ZSTD", "parquet": "ZSTD"},
+	"gzip":   {"orc": "ZLIB", "parquet": "GZIP"},
+}
+
+// compressionCodecAliases are the format specific names of codecs which are
+// also accepted.
+var compressionCodecAliases = map[string]string{
+	"uncompressed": "none",
+	"zlib":         "gzip",
+}
+
+// NormalizeCompressionCodec returns the name of the codec, one of none,
+// snappy, zstd or gzip, or an error if the codec isn't supported.
+func NormalizeCompressionCodec(codec string) (string, error) {
+	normalized := strings.ToLower(codec)
+	if alias, ok := compressionCodecAliases[normalized]; ok {
+		normalized = alias
+	}
+	if _, ok := compressionCodecs[normalized]; !ok {
+		return "", fmt.Errorf("unsupported compression codec %q, must be one of none, snappy, zstd or gzip", codec)
+	}
+	return normalized, nil
+}
+
+// CompressionTableProperties returns the table properties causing files of a
+// table stored as fileFormat to be compressed using codec. If fileFormat is
+// empty, the table is stored in the Hive server's default format, so the
+// properties for both orc and parquet are returned.
+func CompressionTableProperties(fileFormat, codec string) (map[string]string, error) {
+	codec, err := NormalizeCompressionCodec(codec)
+	if err != nil {
+		return nil, err
+	}
+	fileFormat = strings.ToLower(fileFormat)
+	if fileFormat == "" {
+		return map[string]string{
+			compressionTableProperty["orc"]:     compressionCodecs[codec]["orc"],
+			compressionTableProperty["parquet"]: compressionCodecs[codec]["parquet"],
+		}, nil
+	}
+	property, ok := compressionTableProperty[fileFormat]
+	if !ok {
+		return nil, fmt.Errorf("compression can only be configured for tables stored as orc or parquet, got %s", fileFormat)
+	}
+	return map[string]string{property: compressionCodecs[codec][fileFormat]}, nil
+}
+
+func ExecuteCreateTable(ctx context.Context, queryer db.Queryer, params TableParameters, properties TableProperties) error {
 	query := generateCreateTableSQL(params, properties)
-	_, err := queryer.Query(query)
+	_, err := queryer.QueryContext(ctx, query)
 	return err
 }
 
-func ExecuteDropTable(queryer db.Queryer, tableName string, ignoreNotExists bool) error {
+func ExecuteDropTable(ctx context.Context, queryer db.Queryer, tableName string, ignoreNotExists bool) error {
 	query := generateDropTableSQL(tableName, ignoreNotExists, true)
-	_, err := queryer.Query(query)
+	_, err := queryer.QueryContext(ctx, query)
 	return err
 }
 
+func ExecuteSetTableExternal(ctx context.Context, queryer db.Queryer, tableName string, external bool) error {
+	query := generateSetTableExternalSQL(tableName, external)
+	_, err := queryer.QueryContext(ctx, query)
+	return err
+}
+
+// ExecuteInsertIntoTable appends the results of selectQuery to the table.
+func ExecuteInsertIntoTable(ctx context.Context, queryer db.Queryer, tableName, selectQuery string) error {
+	_, err := queryer.QueryContext(ctx, generateInsertIntoTableSQL(tableName, selectQuery))
+	return err
+}
+
+// ExecuteInsertOverwritePartition replaces the contents of the partition
+// where partitionColumn is partitionValue with the results of selectQuery.
+// If selectQuery returns no rows, the partition is emptied.
+func ExecuteInsertOverwritePartition(ctx context.Context, queryer db.Queryer, tableName, partitionColumn, partitionValue, selectQuery string) error {
+	_, err := queryer.QueryContext(ctx, generateInsertOverwritePartitionSQL(tableName, partitionColumn, partitionValue, selectQuery))
+	return err
+}
+
+// ExecuteDropPartition drops the partition where partitionColumn is
+// partitionValue. If the partition doesn't exist, nothing is done. The data of
+// external tables isn't deleted.
+func ExecuteDropPartition(ctx context.Context, queryer db.Queryer, tableName, partitionColumn, partitionValue string) error {
+	_, err := queryer.QueryContext(ctx, generateDropPartitionSQL(tableName, partitionColumn, partitionValue))
+	return err
+}
+
+// ExecuteAddColumns adds columns to the table after it's existing columns,
+// and to the table's existing partitions. Rows stored before the columns were
+// added have NULL values for them. If cascade is false, only the table is
+// altered, which is required by Athena.
+func ExecuteAddColumns(ctx context.Context, queryer db.Queryer, tableName string, columns []Column, cascade bool) error {
+	_, err := queryer.QueryContext(ctx, generateAddColumnsSQL(tableName, columns, cascade))
+	return err
+}
+
+// ExecuteRepairTable adds any partitions which exist in the table's location
+// in the <column>=<value> directory format, but not the metastore.
+func ExecuteRepairTable(ctx context.Context, queryer db.Queryer, tableName string) error {
+	_, err := queryer.QueryContext(ctx, generateRepairTableSQL(tableName))
+	return err
+}
+
+// ExecuteGetTableSize returns the size in bytes of the data stored by the
+// table, using the totalSize statistic recorded when data is written to the
+// table. If the table has no statistics recorded, 0 is returned.
+func ExecuteGetTableSize(ctx context.Context, queryer db.Queryer, tableName string) (int64, error) {
+	rows, err := queryer.QueryContext(ctx, generateShowTablePropertySQL(tableName, "totalSize"))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var value string
+	if rows.Next() {
+		if err := rows.Scan(&value); err != nil {
+			return 0, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	// if the property isn't set, Hive returns a message instead of a number
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, nil
+	}
+	return size, nil
+}
+
 // s3Location returns the HDFS path based on an S3 bucket and prefix.
 func S3Location(bucket, prefix string) (string, error) {
 	bucket = path.Join(bucket, prefix)
@@ -54,3 +193,20 @@ func S3Location(bucket, prefix string) (string, error) {
 	}
 	return locationURL.String(), nil
 }
+
+// AzureBlobLocation returns the HDFS path based on an Azure storage account,
+// container and directory.
+func AzureBlobLocation(account, container, directory string) (string, error) {
+	directory = path.Join("/", directory)
+	// Ensure the location has a trailing slash
+	if directory[len(directory)-1] != '/' {
+		directory = directory + "/"
+	}
+	location := fmt.Sprintf("wasbs://%s@%s.blob.core.windows.net%s", container, account, directory)
+
+	locationURL, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	return locationURL.String(), nil
+}
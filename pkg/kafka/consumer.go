@@ -0,0 +1,73 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+	// register the gzip codec so gzip compressed messages can be consumed
+	_ "github.com/segmentio/kafka-go/gzip"
+)
+
+// Message is a message consumed from a topic.
+type Message = kafka.Message
+
+// Consumer consumes messages from a topic as part of a consumer group.
+type Consumer interface {
+	// FetchBatch blocks until maxMessages have been fetched, maxWait has
+	// elapsed after the first message was fetched, or ctx is cancelled. The
+	// messages fetched are returned even if an error is returned.
+	FetchBatch(ctx context.Context, maxMessages int, maxWait time.Duration) ([]Message, error)
+	// Commit records that the messages have been processed, so they aren't
+	// consumed again by the consumer group.
+	Commit(ctx context.Context, msgs []Message) error
+	Close() error
+}
+
+type consumer struct {
+	reader *kafka.Reader
+}
+
+// NewConsumer returns a Consumer which consumes the topic from the brokers
+// as a member of the consumer group groupID.
+func NewConsumer(brokers []string, topic, groupID string) Consumer {
+	return &consumer{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers: brokers,
+			Topic:   topic,
+			GroupID: groupID,
+		}),
+	}
+}
+
+func (c *consumer) FetchBatch(ctx context.Context, maxMessages int, maxWait time.Duration) ([]Message, error) {
+	var msgs []Message
+	fetchCtx := ctx
+	for len(msgs) < maxMessages {
+		msg, err := c.reader.FetchMessage(fetchCtx)
+		if err != nil {
+			// the deadline for the batch being reached isn't an error
+			if fetchCtx != ctx && fetchCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+				return msgs, nil
+			}
+			return msgs, err
+		}
+		msgs = append(msgs, msg)
+		// wait for up to maxWait after the first message for the batch to
+		// fill up
+		if len(msgs) == 1 {
+			var cancel context.CancelFunc
+			fetchCtx, cancel = context.WithTimeout(ctx, maxWait)
+			defer cancel()
+		}
+	}
+	return msgs, nil
+}
+
+func (c *consumer) Commit(ctx context.Context, msgs []Message) error {
+	return c.reader.CommitMessages(ctx, msgs...)
+}
+
+func (c *consumer) Close() error {
+	return c.reader.Close()
+}
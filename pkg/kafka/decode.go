@@ -0,0 +1,167 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/linkedin/goavro"
+)
+
+// Decoder decodes the value of a message into a record.
+type Decoder interface {
+	Decode(value []byte) (map[string]interface{}, error)
+}
+
+type jsonDecoder struct{}
+
+// NewJSONDecoder returns a Decoder for messages containing a JSON object.
+// Numbers are decoded as json.Number to avoid losing precision.
+func NewJSONDecoder() Decoder {
+	return jsonDecoder{}
+}
+
+func (jsonDecoder) Decode(value []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(value))
+	dec.UseNumber()
+	var record map[string]interface{}
+	if err := dec.Decode(&record); err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, fmt.Errorf("message is not a JSON object")
+	}
+	return record, nil
+}
+
+type avroDecoder struct {
+	codec *goavro.Codec
+	// typeNames contains the names of the primitive and named types in the
+	// schema, which are used as the keys of decoded union values.
+	typeNames map[string]struct{}
+}
+
+// NewAvroDecoder returns a Decoder for messages containing a binary encoded
+// Avro record with the schema. Union values are replaced by the value of the
+// union.
+func NewAvroDecoder(schema string) (Decoder, error) {
+	codec, err := goavro.NewCodec(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Avro schema: %v", err)
+	}
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(schema), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid Avro schema: %v", err)
+	}
+	typeNames := map[string]struct{}{
+		"boolean": {}, "int": {}, "long": {}, "float": {}, "double": {}, "bytes": {}, "string": {},
+		"array": {}, "map": {},
+	}
+	collectAvroTypeNames(parsed, "", typeNames)
+	return &avroDecoder{codec: codec, typeNames: typeNames}, nil
+}
+
+func (d *avroDecoder) Decode(value []byte) (map[string]interface{}, error) {
+	native, remaining, err := d.codec.NativeFromBinary(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(remaining) != 0 {
+		return nil, fmt.Errorf("message has %d bytes remaining after decoding", len(remaining))
+	}
+	record, ok := d.unwrapUnions(native).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("message is not an Avro record")
+	}
+	return record, nil
+}
+
+// unwrapUnions replaces each union value, which goavro decodes as a map from
+// the name of the value's type to the value, with the value itself.
+func (d *avroDecoder) unwrapUnions(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if len(value) == 1 {
+			for typeName, unionValue := range value {
+				if _, isType := d.typeNames[typeName]; isType {
+					return d.unwrapUnions(unionValue)
+				}
+			}
+		}
+		for k, fieldValue := range value {
+			value[k] = d.unwrapUnions(fieldValue)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = d.unwrapUnions(item)
+		}
+	}
+	return v
+}
+
+// collectAvroTypeNames adds the full names of the records, enums and fixed
+// types defined in the schema to names.
+func collectAvroTypeNames(schema interface{}, namespace string, names map[string]struct{}) {
+	switch s := schema.(type) {
+	case []interface{}:
+		for _, item := range s {
+			collectAvroTypeNames(item, namespace, names)
+		}
+	case map[string]interface{}:
+		if ns, ok := s["namespace"].(string); ok {
+			namespace = ns
+		}
+		if name, ok := s["name"].(string); ok {
+			switch s["type"] {
+			case "record", "enum", "fixed":
+				if !strings.Contains(name, ".") && namespace != "" {
+					name = namespace + "." + name
+				}
+				names[name] = struct{}{}
+			}
+		}
+		if fields, ok := s["fields"].([]interface{}); ok {
+			for _, field := range fields {
+				if f, ok := field.(map[string]interface{}); ok {
+					collectAvroTypeNames(f["type"], namespace, names)
+				}
+			}
+		}
+		collectAvroTypeNames(s["items"], namespace, names)
+		collectAvroTypeNames(s["values"], namespace, names)
+		if t, ok := s["type"].(map[string]interface{}); ok {
+			collectAvroTypeNames(t, namespace, names)
+		}
+		if t, ok := s["type"].([]interface{}); ok {
+			collectAvroTypeNames(t, namespace, names)
+		}
+	}
+}
+
+// FieldValue returns the value of the field in the record. Fields of nested
+// records are separated by a ".". If the field doesn't exist nil is
+// returned. Objects and arrays are returned encoded as JSON.
+func FieldValue(record map[string]interface{}, field string) (interface{}, error) {
+	var value interface{} = record
+	for _, name := range strings.Split(field, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		value, ok = m[name]
+		if !ok {
+			return nil, nil
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}, []interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	}
+	return value, nil
+}
@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/linkedin/goavro"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAvroSchema = `{
+  "type": "record",
+  "name": "UsageEvent",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "customer", "type": "string"},
+    {"name": "quantity", "type": ["null", "double"]},
+    {"name": "resource", "type": ["null", {
+      "type": "record",
+      "name": "Resource",
+      "fields": [{"name": "id", "type": "string"}]
+    }]}
+  ]
+}`
+
+func TestJSONDecoder(t *testing.T) {
+	record, err := NewJSONDecoder().Decode([]byte(`{"customer": "acme", "quantity": 12345678901234567890, "resource": {"id": "r-1", "tags": ["a", "b"]}}`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		field    string
+		expected interface{}
+	}{
+		{field: "customer", expected: "acme"},
+		{field: "quantity", expected: json.Number("12345678901234567890")},
+		{field: "resource.id", expected: "r-1"},
+		{field: "resource.tags", expected: `["a","b"]`},
+		{field: "resource", expected: `{"id":"r-1","tags":["a","b"]}`},
+		{field: "missing", expected: nil},
+		{field: "customer.missing", expected: nil},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.field, func(t *testing.T) {
+			value, err := FieldValue(record, tt.field)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+
+	_, err = NewJSONDecoder().Decode([]byte(`[1, 2]`))
+	assert.Error(t, err)
+	_, err = NewJSONDecoder().Decode([]byte(`null`))
+	assert.Error(t, err)
+}
+
+func TestAvroDecoder(t *testing.T) {
+	codec, err := goavro.NewCodec(testAvroSchema)
+	require.NoError(t, err)
+	value, err := codec.BinaryFromNative(nil, map[string]interface{}{
+		"customer": "acme",
+		"quantity": goavro.Union("double", 1.5),
+		"resource": goavro.Union("com.example.Resource", map[string]interface{}{"id": "r-1"}),
+	})
+	require.NoError(t, err)
+
+	decoder, err := NewAvroDecoder(testAvroSchema)
+	require.NoError(t, err)
+	record, err := decoder.Decode(value)
+	require.NoError(t, err)
+
+	customer, err := FieldValue(record, "customer")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", customer)
+	quantity, err := FieldValue(record, "quantity")
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, quantity)
+	resourceID, err := FieldValue(record, "resource.id")
+	require.NoError(t, err)
+	assert.Equal(t, "r-1", resourceID)
+
+	_, err = NewAvroDecoder(`{"type": "unknown"}`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,168 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+)
+
+const (
+	APIV1ReportGenerationQueriesExecuteEndpoint = "/api/v1/reportgenerationqueries/execute"
+
+	// DefaultAdHocQueryMaxRows is the default limit on the number of rows
+	// an ad-hoc query can return.
+	DefaultAdHocQueryMaxRows = 10000
+)
+
+type ExecuteReportGenerationQueryRequest struct {
+	// Name is the name of the ReportGenerationQuery to execute.
+	Name string `json:"name"`
+	// Namespace is the namespace of the ReportGenerationQuery, defaulting to
+	// the namespace reporting-operator is running in.
+	Namespace string `json:"namespace,omitempty"`
+	// ReportingStart and ReportingEnd are the period the query is executed
+	// for, as if it were a Report's reportingStart and reportingEnd.
+	ReportingStart time.Time                                `json:"reportingStart"`
+	ReportingEnd   time.Time                                `json:"reportingEnd"`
+	Inputs         cbTypes.ReportGenerationQueryInputValues `json:"inputs,omitempty"`
+	// PricingPolicy is the name of the PricingPolicy whose rates are
+	// available to the query, defaulting to the namespace's default
+	// PricingPolicy.
+	PricingPolicy string `json:"pricingPolicy,omitempty"`
+}
+
+func (req *ExecuteReportGenerationQueryRequest) validate() error {
+	if req.Name == "" {
+		return fmt.Errorf("name must be set")
+	}
+	if req.ReportingStart.IsZero() || req.ReportingEnd.IsZero() {
+		return fmt.Errorf("reportingStart and reportingEnd must be set")
+	}
+	if !req.ReportingStart.Before(req.ReportingEnd) {
+		return fmt.Errorf("reportingStart must be before reportingEnd")
+	}
+	return nil
+}
+
+// executeReportGenerationQueryHandler executes a ReportGenerationQuery for a
+// reporting period and inputs supplied by the caller, returning it's results
+// in the same formats as the v2 reports API without creating a Report or
+// storing the results. Queries returning more than AdHocQueryMaxRows rows
+// are rejected, as they should be run as a Report instead.
+func (op *Reporting) executeReportGenerationQueryHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(op.logger, r, op.rand)
+	if r.Method != "POST" {
+		writeErrorResponse(logger, w, r, http.StatusMethodNotAllowed, "only POST is supported")
+		return
+	}
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	switch format {
+	case "":
+		format = "json"
+	case "json", "csv", "tab", "tabular", "html":
+	default:
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "format must be one of: csv, html, json or tabular")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	var req ExecuteReportGenerationQueryRequest
+	err := decoder.Decode(&req)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to decode request as JSON: %v", err)
+		return
+	}
+	if req.Namespace == "" {
+		req.Namespace = op.cfg.Namespace
+	}
+	w, audit := op.resultsAuditor.startHTTP(w, r, "ReportGenerationQuery", req.Namespace, req.Name, format)
+	defer audit.finish()
+	req.ReportingStart = req.ReportingStart.UTC()
+	req.ReportingEnd = req.ReportingEnd.UTC()
+	if err := req.validate(); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if !op.cfg.isWatchedNamespace(req.Namespace) {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "namespace %s is not watched by reporting-operator", req.Namespace)
+		return
+	}
+	if !op.resultsAuthorizer.authorizeRequest(logger, w, r, "get", resultsResourceReportGenerationQueries, req.Namespace) {
+		return
+	}
+	logger = logger.WithFields(log.Fields{
+		"reportGenerationQuery": req.Namespace + "/" + req.Name,
+		// set by the auth proxy to the authenticated user
+		"user": op.trustedProxies.forwardedUser(r),
+	})
+
+	genQuery, err := op.reportGenerationQueryLister.ReportGenerationQueries(req.Namespace).Get(req.Name)
+	if apierrors.IsNotFound(err) {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "ReportGenerationQuery %s does not exist", req.Name)
+		return
+	}
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to get ReportGenerationQuery %s: %v", req.Name, err)
+		return
+	}
+
+	queryDependencies, err := reporting.GetAndValidateGenerationQueryDependencies(
+		reporting.NewReportGenerationQueryListerGetter(op.reportGenerationQueryLister),
+		reporting.NewReportDataSourceListerGetter(op.reportDataSourceLister),
+		reporting.NewReportListerGetter(op.reportLister),
+		reporting.NewScheduledReportListerGetter(op.scheduledReportLister),
+		genQuery,
+		op.uninitialiedDependendenciesHandler(),
+	)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to execute ReportGenerationQuery %s, failed to validate dependencies: %v", genQuery.Name, err)
+		return
+	}
+
+	maxRows := op.cfg.AdHocQueryMaxRows
+	if maxRows <= 0 {
+		maxRows = DefaultAdHocQueryMaxRows
+	}
+	pricing, err := op.getPricingPolicy(req.Namespace, req.PricingPolicy)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to execute ReportGenerationQuery %s, invalid pricingPolicy: %v", genQuery.Name, err)
+		return
+	}
+
+	// the query is cancelled if the client disconnects
+	ctx, cancel := op.withReportQueryTimeout(r.Context())
+	defer cancel()
+	ctx = reporting.WithQueryPriority(ctx, reportQueryPriority(reporting.AdHocQueryClass, &req.ReportingStart, &req.ReportingEnd))
+	ctx = reporting.WithPricing(ctx, pricing)
+	// one more row than the limit is requested, to determine whether the
+	// results were truncated
+	results, err := op.reportGenerator.PreviewReport(
+		ctx,
+		&req.ReportingStart,
+		&req.ReportingEnd,
+		genQuery,
+		queryDependencies.DynamicReportGenerationQueries,
+		req.Inputs,
+		maxRows+1,
+	)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to execute ReportGenerationQuery %s: %v", genQuery.Name, err)
+		return
+	}
+	if len(results.Results) > maxRows {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "ReportGenerationQuery %s returned more than %d rows, use a shorter reporting period or create a Report", genQuery.Name, maxRows)
+		return
+	}
+	logger.Infof("executed ad-hoc query for period %s to %s, returning %d rows", req.ReportingStart, req.ReportingEnd, len(results.Results))
+	audit.setRows(len(results.Results))
+
+	writeResultsResponseV2(logger, true, format, genQuery.Spec.Columns, results.Results, op.cfg.Currency, w, r)
+}
@@ -0,0 +1,176 @@
+package operator
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockprestostore "github.com/operator-framework/operator-metering/pkg/operator/prestostore/mock"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func TestExecuteReportGenerationQueryHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reportResultsRepo := mockprestostore.NewMockReportResultsRepo(ctrl)
+	op, accessReviews := newTestReportQueryOperator(t, reportResultsRepo)
+	op.cfg.AdHocQueryMaxRows = 2
+
+	execute := func(method, format, token, body string) *httptest.ResponseRecorder {
+		url := APIV1ReportGenerationQueriesExecuteEndpoint
+		if format != "" {
+			url += "?format=" + format
+		}
+		r := httptest.NewRequest(method, url, strings.NewReader(body))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		op.executeReportGenerationQueryHandler(w, r)
+		return w
+	}
+
+	// the reporting period is converted to UTC, and one more row than the
+	// limit is requested to detect truncated results
+	query := "SELECT timestamp '2019-01-01 00:00:00.000', timestamp '2019-01-02 00:00:00.000', 'a'"
+	columns := []presto.Column{{Name: "team", Type: "varchar"}}
+	reportResultsRepo.EXPECT().PreviewReportResults(gomock.Any(), query, 3).Return(columns, []presto.Row{{"team": "a"}}, nil)
+	w := execute("POST", "", "alice-token", `{"name": "pod-cpu", "namespace": "team-a", "reportingStart": "2019-01-01T01:00:00+01:00", "reportingEnd": "2019-01-02T00:00:00Z", "inputs": [{"name": "Team", "value": "a"}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp GetReportResults
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, GetReportResults{Results: []ReportResultEntry{{Values: []ReportResultValues{{Name: "team", Value: "a"}}}}}, resp)
+	review := accessReviews.reviews[len(accessReviews.reviews)-1].ResourceAttributes
+	assert.Equal(t, "get", review.Verb)
+	assert.Equal(t, resultsResourceReportGenerationQueries, review.Resource)
+	assert.Equal(t, "team-a", review.Namespace)
+
+	reportResultsRepo.EXPECT().PreviewReportResults(gomock.Any(), query, 3).Return(columns, []presto.Row{{"team": "a"}}, nil)
+	w = execute("POST", "CSV", "alice-token", `{"name": "pod-cpu", "namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z", "inputs": [{"name": "Team", "value": "a"}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "team\na\n", w.Body.String())
+
+	// results over the limit are rejected rather than truncated
+	reportResultsRepo.EXPECT().PreviewReportResults(gomock.Any(), query, 3).Return(columns, []presto.Row{{"team": "a"}, {"team": "a"}, {"team": "a"}}, nil)
+	w = execute("POST", "", "alice-token", `{"name": "pod-cpu", "namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z", "inputs": [{"name": "Team", "value": "a"}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "returned more than 2 rows")
+
+	reportResultsRepo.EXPECT().PreviewReportResults(gomock.Any(), query, 3).Return(nil, nil, errors.New("Table not found"))
+	w = execute("POST", "", "alice-token", `{"name": "pod-cpu", "namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z", "inputs": [{"name": "Team", "value": "a"}]}`)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Table not found")
+
+	for _, tt := range []struct {
+		name, method, format, token, body string
+		expectedCode                      int
+	}{
+		{
+			name:         "only POST is supported",
+			method:       "GET",
+			token:        "alice-token",
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:         "unknown format",
+			method:       "POST",
+			format:       "xml",
+			token:        "alice-token",
+			body:         `{"name": "pod-cpu", "namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid JSON",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"name": `,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "missing name",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "missing reportingStart",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"name": "pod-cpu", "namespace": "team-a", "reportingEnd": "2019-01-02T00:00:00Z"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "missing reportingEnd",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"name": "pod-cpu", "namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "empty reporting period",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"name": "pod-cpu", "namespace": "team-a", "reportingStart": "2019-01-01T01:00:00+01:00", "reportingEnd": "2019-01-01T00:00:00Z"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "reportingStart after reportingEnd",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"name": "pod-cpu", "namespace": "team-a", "reportingStart": "2019-01-02T00:00:00Z", "reportingEnd": "2019-01-01T00:00:00Z"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "the namespace defaults to the operator's namespace, which isn't watched",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"name": "pod-cpu", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unauthenticated",
+			method:       "POST",
+			body:         `{"name": "pod-cpu", "namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z"}`,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "invalid token",
+			method:       "POST",
+			token:        "mallory-token",
+			body:         `{"name": "pod-cpu", "namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z"}`,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "unauthorized namespace",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"name": "pod-cpu", "namespace": "team-b", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z"}`,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "missing ReportGenerationQuery",
+			method:       "POST",
+			token:        "bob-token",
+			body:         `{"name": "pod-cpu", "namespace": "team-b", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z"}`,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "missing PricingPolicy",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"name": "pod-cpu", "namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z", "pricingPolicy": "missing"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	} {
+		w := execute(tt.method, tt.format, tt.token, tt.body)
+		assert.Equal(t, tt.expectedCode, w.Code, "unexpected status for %s: %s", tt.name, w.Body.String())
+	}
+}
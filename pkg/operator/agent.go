@@ -0,0 +1,185 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/transport"
+
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+// agentRequestTimeout is the maximum amount of time a request to the central
+// reporting-operator can take.
+const agentRequestTimeout = 2 * time.Minute
+
+// AgentConfig configures agent mode, in which reporting-operator doesn't use
+// Presto or Hive, and only collects Prometheus metrics, pushing them to the
+// ReportDataSource with the same name on a central reporting-operator which
+// stores them and generates reports.
+type AgentConfig struct {
+	// CentralURL is the URL of the central reporting-operator's HTTP API.
+	// Agent mode is enabled if it's set.
+	CentralURL string
+	// CentralNamespace is the namespace of the ReportDataSources on the
+	// central reporting-operator metrics are pushed to. Defaults to the
+	// namespace the central reporting-operator is running in.
+	CentralNamespace string
+
+	// BearerTokenFile is a file containing the bearer token used to
+	// authenticate against the central reporting-operator.
+	BearerTokenFile string
+	CAFile          string
+	SkipTLSVerify   bool
+}
+
+// Enabled returns true if reporting-operator is running as an agent.
+func (cfg *AgentConfig) Enabled() bool {
+	return cfg.CentralURL != ""
+}
+
+func (cfg *AgentConfig) Valid() error {
+	if !cfg.Enabled() {
+		return nil
+	}
+	u, err := url.Parse(cfg.CentralURL)
+	if err != nil {
+		return fmt.Errorf("invalid agent central URL: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("agent central URL must be an http or https URL, got %q", cfg.CentralURL)
+	}
+	return nil
+}
+
+// newCentralClient returns an HTTP client for the central
+// reporting-operator's API.
+func (cfg *AgentConfig) newCentralClient() (*http.Client, error) {
+	transportConfig := &transport.Config{
+		TLS: transport.TLSConfig{
+			CAFile:   cfg.CAFile,
+			Insecure: cfg.SkipTLSVerify,
+		},
+	}
+	if cfg.BearerTokenFile != "" {
+		token, err := ioutil.ReadFile(cfg.BearerTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read agent bearer token file: %v", err)
+		}
+		transportConfig.BearerToken = strings.TrimSpace(string(token))
+	}
+	roundTripper, err := transport.New(transportConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: roundTripper, Timeout: agentRequestTimeout}, nil
+}
+
+// centralPrometheusMetricsRepo is a prestostore.PrometheusMetricsRepo which
+// stores and gets the metrics of a ReportDataSource using the API of the
+// central reporting-operator. The tableName arguments are ignored, since the
+// central reporting-operator chooses the table using the ReportDataSource's
+// name.
+type centralPrometheusMetricsRepo struct {
+	client         *http.Client
+	centralURL     string
+	namespace      string
+	dataSourceName string
+}
+
+func newCentralPrometheusMetricsRepo(client *http.Client, centralURL, namespace, dataSourceName string) *centralPrometheusMetricsRepo {
+	return &centralPrometheusMetricsRepo{
+		client:         client,
+		centralURL:     strings.TrimSuffix(centralURL, "/"),
+		namespace:      namespace,
+		dataSourceName: dataSourceName,
+	}
+}
+
+func (r *centralPrometheusMetricsRepo) endpoint(action string, query url.Values) string {
+	if r.namespace != "" {
+		query.Set("namespace", r.namespace)
+	}
+	u := fmt.Sprintf("%s/api/v1/datasources/prometheus/%s/%s", r.centralURL, action, url.PathEscape(r.dataSourceName))
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// StorePrometheusMetrics pushes the metrics to the central
+// reporting-operator, which stores them using the partition granularity of
+// it's ReportDataSource, skipping any it already has.
+func (r *centralPrometheusMetricsRepo) StorePrometheusMetrics(ctx context.Context, tableName string, granularity prestostore.PartitionGranularity, metrics []*prestostore.PrometheusMetric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(StorePromsumDataRequest(metrics))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", r.endpoint("store", url.Values{}), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("unable to push metrics to the central reporting-operator: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return centralResponseError("push metrics to", resp)
+	}
+	return nil
+}
+
+func (r *centralPrometheusMetricsRepo) GetPrometheusMetrics(tableName string, start, end time.Time) ([]*prestostore.PrometheusMetric, error) {
+	query := url.Values{}
+	if !start.IsZero() {
+		query.Set("start", start.UTC().Format(time.RFC3339))
+	}
+	if !end.IsZero() {
+		query.Set("end", end.UTC().Format(time.RFC3339))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), agentRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequest("GET", r.endpoint("fetch", query), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("unable to get metrics from the central reporting-operator: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, centralResponseError("get metrics from", resp)
+	}
+	var metrics []*prestostore.PrometheusMetric
+	if err := json.NewDecoder(resp.Body).Decode(&metrics); err != nil {
+		return nil, fmt.Errorf("unable to decode metrics from the central reporting-operator: %v", err)
+	}
+	return metrics, nil
+}
+
+// GetLastTimestampForTable always returns nil, causing a new importer to
+// backfill. The central reporting-operator skips metrics it's already
+// stored, so nothing is duplicated, and importers resume from the import
+// checkpoint in the ReportDataSource's status once it's recorded.
+func (r *centralPrometheusMetricsRepo) GetLastTimestampForTable(tableName string) (*time.Time, error) {
+	return nil, nil
+}
+
+func centralResponseError(action string, resp *http.Response) error {
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("unable to %s the central reporting-operator, got status %d: %s", action, resp.StatusCode, strings.TrimSpace(string(msg)))
+}
@@ -0,0 +1,77 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+func TestCentralPrometheusMetricsRepo(t *testing.T) {
+	timestamp := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	metrics := []*prestostore.PrometheusMetric{
+		{
+			Labels:    map[string]string{"namespace": "payments"},
+			Amount:    2,
+			StepSize:  time.Minute,
+			Timestamp: timestamp,
+		},
+	}
+
+	var method, path, namespace, start string
+	var stored StorePromsumDataRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		namespace, start = r.FormValue("namespace"), r.FormValue("start")
+		switch method {
+		case "POST":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&stored))
+			writeResponseAsJSON(testLogger, w, http.StatusOK, struct{}{})
+		case "GET":
+			writeResponseAsJSON(testLogger, w, http.StatusOK, metrics)
+		}
+	}))
+	defer srv.Close()
+
+	repo := newCentralPrometheusMetricsRepo(srv.Client(), srv.URL+"/", "metering", "pod-request-cpu-cores")
+
+	err := repo.StorePrometheusMetrics(context.Background(), "ignored", prestostore.PartitionGranularityDaily, metrics)
+	require.NoError(t, err)
+	assert.Equal(t, "POST", method)
+	assert.Equal(t, "/api/v1/datasources/prometheus/store/pod-request-cpu-cores", path)
+	assert.Equal(t, "metering", namespace)
+	require.Len(t, stored, 1)
+	assert.Equal(t, metrics[0].Labels, stored[0].Labels)
+	assert.Equal(t, metrics[0].Amount, stored[0].Amount)
+
+	fetched, err := repo.GetPrometheusMetrics("ignored", timestamp, timestamp.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "GET", method)
+	assert.Equal(t, "/api/v1/datasources/prometheus/fetch/pod-request-cpu-cores", path)
+	assert.Equal(t, "2019-01-01T00:00:00Z", start)
+	require.Len(t, fetched, 1)
+	assert.True(t, metrics[0].Timestamp.Equal(fetched[0].Timestamp))
+
+	lastTimestamp, err := repo.GetLastTimestampForTable("ignored")
+	require.NoError(t, err)
+	assert.Nil(t, lastTimestamp, "expected the agent to rely on the central reporting-operator to skip duplicate metrics")
+}
+
+func TestCentralPrometheusMetricsRepoError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unable to get table for ReportDataSource missing", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	repo := newCentralPrometheusMetricsRepo(srv.Client(), srv.URL, "", "missing")
+	err := repo.StorePrometheusMetrics(context.Background(), "ignored", prestostore.PartitionGranularityDaily, []*prestostore.PrometheusMetric{{}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "got status 404: unable to get table for ReportDataSource missing")
+}
@@ -0,0 +1,258 @@
+package operator
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+	"github.com/juju/ratelimit"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+const (
+	// apiRateLimitMaxClients is the number of clients whose limits are
+	// tracked, after which the least recently seen client is forgotten.
+	apiRateLimitMaxClients = 10000
+
+	// The limits which can cause a request to be rejected.
+	apiRateLimitQPS                          = "qps"
+	apiRateLimitClientQPS                    = "client_qps"
+	apiRateLimitConcurrentDownloads          = "concurrent_downloads"
+	apiRateLimitConcurrentDownloadsPerClient = "client_concurrent_downloads"
+)
+
+var (
+	apiRateLimitedRequestsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: prometheusMetricNamespace,
+			Name:      "api_rate_limited_requests_total",
+			Help:      "Number of API requests rejected because of the configured rate limits, by the limit which was exceeded.",
+		},
+		[]string{"limit"},
+	)
+	apiDownloadsInProgressGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: prometheusMetricNamespace,
+			Name:      "api_downloads_in_progress",
+			Help:      "Number of API requests returning report results currently being handled.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(apiRateLimitedRequestsCounter)
+	prometheus.MustRegister(apiDownloadsInProgressGauge)
+}
+
+// APIRateLimitConfig configures the limits on requests to the HTTP API, so
+// clients polling it can't overload Presto. A value of 0 disables each
+// limit.
+type APIRateLimitConfig struct {
+	// QPS limits the number of requests per second accepted from all
+	// clients, and ClientQPS from each client, allowing bursts of up to the
+	// limit rounded up.
+	QPS       float64
+	ClientQPS float64
+	// MaxConcurrentDownloads limits the number of requests returning
+	// report results handled at once, and MaxConcurrentClientDownloads the
+	// number handled at once for each client.
+	MaxConcurrentDownloads       int
+	MaxConcurrentClientDownloads int
+}
+
+func (cfg *APIRateLimitConfig) Valid() error {
+	if cfg.QPS < 0 || cfg.ClientQPS < 0 {
+		return fmt.Errorf("API QPS limits must not be negative, got %v and %v per client", cfg.QPS, cfg.ClientQPS)
+	}
+	if cfg.MaxConcurrentDownloads < 0 || cfg.MaxConcurrentClientDownloads < 0 {
+		return fmt.Errorf("API concurrent download limits must not be negative, got %d and %d per client", cfg.MaxConcurrentDownloads, cfg.MaxConcurrentClientDownloads)
+	}
+	return nil
+}
+
+func (cfg *APIRateLimitConfig) enabled() bool {
+	return cfg.QPS > 0 || cfg.ClientQPS > 0 || cfg.MaxConcurrentDownloads > 0 || cfg.MaxConcurrentClientDownloads > 0
+}
+
+// apiRateLimiter rejects API requests exceeding the configured limits with
+// 429 Too Many Requests, rather than queueing them, so clients back off.
+type apiRateLimiter struct {
+	logger log.FieldLogger
+	clock  clock.Clock
+	cfg    APIRateLimitConfig
+	// authorizer identifies clients by their bearer token, and is nil when
+	// results access isn't authorized.
+	authorizer *resultsAuthorizer
+	// proxies are the proxies whose X-Forwarded-User header identifies
+	// clients.
+	proxies trustedProxies
+	// bucket is nil if the rate of requests from all clients isn't
+	// limited.
+	bucket *ratelimit.Bucket
+
+	mu        sync.Mutex
+	downloads int
+	// clients maps the key of each client to it's *apiClientLimits.
+	clients *simplelru.LRU
+}
+
+// apiClientLimits tracks the requests of a single client.
+type apiClientLimits struct {
+	// bucket is nil if the rate of requests from each client isn't
+	// limited.
+	bucket    *ratelimit.Bucket
+	downloads int
+}
+
+// newAPIRateLimiter returns an apiRateLimiter enforcing cfg, or nil if
+// every limit is disabled.
+func newAPIRateLimiter(logger log.FieldLogger, clock clock.Clock, cfg APIRateLimitConfig, authorizer *resultsAuthorizer, proxies trustedProxies) *apiRateLimiter {
+	if !cfg.enabled() {
+		return nil
+	}
+	// the size is constant and positive, so NewLRU can't fail
+	clients, _ := simplelru.NewLRU(apiRateLimitMaxClients, nil)
+	limiter := &apiRateLimiter{
+		logger:     logger.WithField("component", "api-rate-limiter"),
+		clock:      clock,
+		cfg:        cfg,
+		authorizer: authorizer,
+		proxies:    proxies,
+		clients:    clients,
+	}
+	if cfg.QPS > 0 {
+		limiter.bucket = ratelimit.NewBucketWithRateAndClock(cfg.QPS, int64(math.Ceil(cfg.QPS)), clock)
+	}
+	return limiter
+}
+
+// apiClient identifies the client making r, so each client is limited
+// separately. Behind the auth proxy every request comes from the same
+// address, so clients are identified by the user their bearer token was
+// authenticated as by an earlier request, or the user a trusted proxy
+// authenticated, before their address. Tokens which haven't been
+// authenticated yet aren't authenticated here, since requests are limited
+// before they're authenticated, and unverified tokens and headers aren't
+// used, since clients could escape their limits by changing them on each
+// request.
+func (l *apiRateLimiter) apiClient(r *http.Request) string {
+	if user := l.authorizer.authenticated(requestToken(r)); user != nil {
+		return "user:" + user.Username
+	}
+	if user := l.proxies.forwardedUser(r); user != "" {
+		return "user:" + user
+	}
+	return "address:" + remoteHost(r)
+}
+
+// client returns the limits of the client identified by key. l.mu must be
+// held.
+func (l *apiRateLimiter) client(key string) *apiClientLimits {
+	if client, exists := l.clients.Get(key); exists {
+		return client.(*apiClientLimits)
+	}
+	client := &apiClientLimits{}
+	if l.cfg.ClientQPS > 0 {
+		client.bucket = ratelimit.NewBucketWithRateAndClock(l.cfg.ClientQPS, int64(math.Ceil(l.cfg.ClientQPS)), l.clock)
+	}
+	l.clients.Add(key, client)
+	return client
+}
+
+// allowRequest returns the limit exceeded by a request from the client
+// identified by key, or an empty string if the request is allowed.
+func (l *apiRateLimiter) allowRequest(key string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	client := l.client(key)
+	// the client's limit is checked first, so a single client exceeding
+	// it's limit doesn't use up the tokens of other clients
+	if client.bucket != nil && client.bucket.TakeAvailable(1) == 0 {
+		return apiRateLimitClientQPS
+	}
+	if l.bucket != nil && l.bucket.TakeAvailable(1) == 0 {
+		return apiRateLimitQPS
+	}
+	return ""
+}
+
+// startDownload returns the limit exceeded by a download by the client
+// identified by key, or an empty string if the download can start, in
+// which case finishDownload must be called with the client returned once
+// it's done.
+func (l *apiRateLimiter) startDownload(key string) (*apiClientLimits, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	client := l.client(key)
+	if l.cfg.MaxConcurrentClientDownloads > 0 && client.downloads >= l.cfg.MaxConcurrentClientDownloads {
+		return nil, apiRateLimitConcurrentDownloadsPerClient
+	}
+	if l.cfg.MaxConcurrentDownloads > 0 && l.downloads >= l.cfg.MaxConcurrentDownloads {
+		return nil, apiRateLimitConcurrentDownloads
+	}
+	client.downloads++
+	l.downloads++
+	apiDownloadsInProgressGauge.Inc()
+	return client, ""
+}
+
+// finishDownload records that a download by client is done. client is
+// passed rather than looked up, since it may have been forgotten, and
+// replaced, while the download was in progress.
+func (l *apiRateLimiter) finishDownload(client *apiClientLimits) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	client.downloads--
+	l.downloads--
+	apiDownloadsInProgressGauge.Dec()
+}
+
+func (l *apiRateLimiter) reject(w http.ResponseWriter, r *http.Request, key, limit string) {
+	apiRateLimitedRequestsCounter.WithLabelValues(limit).Inc()
+	logger := l.logger.WithFields(log.Fields{
+		"method": r.Method,
+		"url":    r.URL.String(),
+		"client": key,
+		"limit":  limit,
+	})
+	logger.Debugf("rejecting request exceeding the API rate limits")
+	w.Header().Set("Retry-After", "1")
+	writeErrorResponse(logger, w, r, http.StatusTooManyRequests, "too many requests, exceeded the %s limit", limit)
+}
+
+// middleware limits the rate of requests to next.
+func (l *apiRateLimiter) middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := l.apiClient(r)
+		if limit := l.allowRequest(key); limit != "" {
+			l.reject(w, r, key, limit)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitDownloads limits the number of requests handled by next at once,
+// which should be a handler returning results queried from Presto.
+func (l *apiRateLimiter) limitDownloads(next http.HandlerFunc) http.HandlerFunc {
+	if l == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := l.apiClient(r)
+		client, limit := l.startDownload(key)
+		if limit != "" {
+			l.reject(w, r, key, limit)
+			return
+		}
+		defer l.finishDownload(client)
+		next(w, r)
+	}
+}
@@ -0,0 +1,217 @@
+package operator
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestAPIClient(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC))
+	authorizer, tokenReviews, _ := newTestResultsAuthorizer(fakeClock)
+	proxies, err := parseTrustedProxies([]string{"127.0.0.1"})
+	require.NoError(t, err)
+	// bob's token was authenticated by an earlier request, as was the
+	// invalid token
+	_, err = authorizer.authenticate("bob-token")
+	require.NoError(t, err)
+	_, err = authorizer.authenticate("made-up-token")
+	require.Equal(t, errInvalidBearerToken, err)
+	cfg := APIRateLimitConfig{ClientQPS: 1}
+	unauthorized := newAPIRateLimiter(testLogger, fakeClock, cfg, nil, proxies)
+	authorized := newAPIRateLimiter(testLogger, fakeClock, cfg, authorizer, proxies)
+
+	for _, tt := range []struct {
+		name                     string
+		remoteAddr               string
+		token, forwardedUser     string
+		unauthorized, authorized string
+	}{
+		{
+			name:         "clients are identified by their address",
+			remoteAddr:   "10.0.0.1:43210",
+			unauthorized: "address:10.0.0.1",
+			authorized:   "address:10.0.0.1",
+		},
+		{
+			name:          "forwarded users are ignored unless set by a trusted proxy",
+			remoteAddr:    "10.0.0.1:43210",
+			forwardedUser: "alice",
+			unauthorized:  "address:10.0.0.1",
+			authorized:    "address:10.0.0.1",
+		},
+		{
+			name:          "forwarded users set by a trusted proxy identify clients",
+			remoteAddr:    "127.0.0.1:43210",
+			forwardedUser: "alice",
+			unauthorized:  "user:alice",
+			authorized:    "user:alice",
+		},
+		{
+			name:         "tokens are only used once they're authenticated",
+			remoteAddr:   "10.0.0.1:43210",
+			token:        "bob-token",
+			unauthorized: "address:10.0.0.1",
+			authorized:   "user:bob",
+		},
+		{
+			name:         "tokens which haven't been authenticated are ignored",
+			remoteAddr:   "10.0.0.1:43210",
+			token:        "alice-token",
+			unauthorized: "address:10.0.0.1",
+			authorized:   "address:10.0.0.1",
+		},
+		{
+			name:          "authenticated tokens take precedence over forwarded users",
+			remoteAddr:    "127.0.0.1:43210",
+			token:         "bob-token",
+			forwardedUser: "alice",
+			unauthorized:  "user:alice",
+			authorized:    "user:bob",
+		},
+		{
+			name:         "invalid tokens are ignored",
+			remoteAddr:   "10.0.0.1:43210",
+			token:        "made-up-token",
+			unauthorized: "address:10.0.0.1",
+			authorized:   "address:10.0.0.1",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/reports", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			if tt.forwardedUser != "" {
+				r.Header.Set("X-Forwarded-User", tt.forwardedUser)
+			}
+			assert.Equal(t, tt.unauthorized, unauthorized.apiClient(r))
+			assert.Equal(t, tt.authorized, authorized.apiClient(r))
+		})
+	}
+	assert.Equal(t, 2, tokenReviews.created, "expected clients to be identified without authenticating their tokens")
+}
+
+// newProxiedRequest returns a request forwarded by the auth proxy for user.
+func newProxiedRequest(target, user string) *http.Request {
+	r := httptest.NewRequest("GET", target, nil)
+	r.RemoteAddr = "127.0.0.1:43210"
+	r.Header.Set("X-Forwarded-User", user)
+	return r
+}
+
+// testAuthProxy is the auth proxy, which runs in the same pod as
+// reporting-operator.
+var testAuthProxy = trustedProxies{{IP: net.IPv4(127, 0, 0, 1).To4(), Mask: net.CIDRMask(32, 32)}}
+
+func TestAPIRateLimiterQPS(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC))
+	limiter := newAPIRateLimiter(testLogger, fakeClock, APIRateLimitConfig{QPS: 3, ClientQPS: 2}, nil, testAuthProxy)
+	handler := limiter.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	do := func(user string) int {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newProxiedRequest("/api/v1/reports", user))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, do("alice"))
+	assert.Equal(t, http.StatusOK, do("alice"))
+	assert.Equal(t, http.StatusTooManyRequests, do("alice"), "expected alice to exceed the per-client limit")
+	assert.Equal(t, http.StatusOK, do("bob"))
+	assert.Equal(t, http.StatusTooManyRequests, do("carol"), "expected the global limit to be exceeded")
+
+	fakeClock.Step(time.Second)
+	assert.Equal(t, http.StatusOK, do("alice"))
+}
+
+func TestAPIRateLimiterConcurrentDownloads(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC))
+	limiter := newAPIRateLimiter(testLogger, fakeClock, APIRateLimitConfig{MaxConcurrentDownloads: 2, MaxConcurrentClientDownloads: 1}, nil, testAuthProxy)
+
+	// each download started by the handler below blocks until release is
+	// closed
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := limiter.limitDownloads(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+	do := func(user string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler(w, newProxiedRequest("/api/v2/reports/cpu/full?format=json", user))
+		return w
+	}
+
+	done := make(chan int, 2)
+	for _, user := range []string{"alice", "bob"} {
+		go func(user string) { done <- do(user).Code }(user)
+		<-started
+	}
+
+	w := do("alice")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "expected alice to exceed the per-client limit")
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), apiRateLimitConcurrentDownloadsPerClient)
+	w = do("carol")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "expected the global limit to be exceeded")
+	assert.Contains(t, w.Body.String(), apiRateLimitConcurrentDownloads)
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+	assert.Equal(t, http.StatusOK, <-done)
+	limiter.mu.Lock()
+	assert.Equal(t, 0, limiter.downloads)
+	limiter.mu.Unlock()
+
+	go func() { <-started }()
+	assert.Equal(t, http.StatusOK, do("carol").Code)
+}
+
+func TestAPIRateLimiterForgetsLeastRecentClients(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC))
+	limiter := newAPIRateLimiter(testLogger, fakeClock, APIRateLimitConfig{ClientQPS: 1}, nil, nil)
+	alice, limit := limiter.startDownload("alice")
+	require.Equal(t, "", limit)
+	for i := 0; i < apiRateLimitMaxClients; i++ {
+		limiter.allowRequest(fmt.Sprintf("client-%d", i))
+	}
+
+	limiter.mu.Lock()
+	assert.Equal(t, apiRateLimitMaxClients, limiter.clients.Len())
+	assert.False(t, limiter.clients.Contains("alice"), "expected alice to be forgotten, since alice was seen least recently")
+	assert.True(t, limiter.clients.Contains(fmt.Sprintf("client-%d", apiRateLimitMaxClients-1)))
+	limiter.mu.Unlock()
+
+	// a download finishing after it's client was forgotten doesn't affect
+	// the downloads of the client replacing it
+	replacement, limit := limiter.startDownload("alice")
+	require.Equal(t, "", limit)
+	limiter.finishDownload(alice)
+	assert.Equal(t, 1, replacement.downloads)
+	limiter.finishDownload(replacement)
+	limiter.mu.Lock()
+	assert.Equal(t, 0, limiter.downloads)
+	limiter.mu.Unlock()
+}
+
+func TestAPIRateLimiterDisabled(t *testing.T) {
+	assert.Nil(t, newAPIRateLimiter(testLogger, clock.RealClock{}, APIRateLimitConfig{}, nil, nil))
+	var limiter *apiRateLimiter
+	handler := limiter.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/reports", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
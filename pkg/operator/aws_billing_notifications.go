@@ -0,0 +1,102 @@
+package operator
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/aws"
+)
+
+// awsBillingNotificationRetryInterval is how long to wait before receiving
+// notifications again after failing to.
+const awsBillingNotificationRetryInterval = 30 * time.Second
+
+type awsBillingNotificationWatcher struct {
+	// source and notifications are the configuration the watcher was
+	// started with.
+	source        cbTypes.S3Bucket
+	notifications cbTypes.SQSNotifications
+	cancel        context.CancelFunc
+}
+
+// ensureAWSBillingNotificationWatcher starts receiving S3 event notifications
+// for the AWSBilling ReportDataSource if it has spec.awsBilling.notifications
+// configured, queuing the ReportDataSource whenever a new report manifest is
+// written, so it's partitions are updated immediately. Existing watchers are
+// restarted if the configuration changed, and stopped if notifications are
+// no longer configured.
+func (op *Reporting) ensureAWSBillingNotificationWatcher(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) {
+	key := importerKey(dataSource)
+	source := *dataSource.Spec.AWSBilling.Source
+	notifications := dataSource.Spec.AWSBilling.Notifications
+
+	op.awsBillingNotificationWatchersMu.Lock()
+	defer op.awsBillingNotificationWatchersMu.Unlock()
+	watcher, exists := op.awsBillingNotificationWatchers[key]
+	if exists {
+		if notifications != nil && watcher.source == source && watcher.notifications == *notifications {
+			return
+		}
+		logger.Infof("stopping S3 event notification watcher for SQS queue %s", watcher.notifications.QueueURL)
+		watcher.cancel()
+		delete(op.awsBillingNotificationWatchers, key)
+	}
+	if notifications == nil {
+		return
+	}
+
+	region := notifications.Region
+	if region == "" {
+		region = source.Region
+	}
+	notifier := aws.NewManifestNotifier(region, notifications.QueueURL, source.Bucket, source.Prefix, source.RoleARN)
+	ctx, cancel := context.WithCancel(context.Background())
+	op.awsBillingNotificationWatchers[key] = &awsBillingNotificationWatcher{
+		source:        source,
+		notifications: *notifications,
+		cancel:        cancel,
+	}
+	logger = logger.WithField("queueURL", notifications.QueueURL)
+	logger.Infof("starting S3 event notification watcher for SQS queue %s", notifications.QueueURL)
+	go op.watchAWSBillingNotifications(ctx, logger, notifier, key)
+}
+
+// stopAWSBillingNotificationWatcher stops the S3 event notification watcher
+// for the ReportDataSource, if it has one.
+func (op *Reporting) stopAWSBillingNotificationWatcher(dataSource *cbTypes.ReportDataSource) {
+	key := importerKey(dataSource)
+	op.awsBillingNotificationWatchersMu.Lock()
+	defer op.awsBillingNotificationWatchersMu.Unlock()
+	if watcher, exists := op.awsBillingNotificationWatchers[key]; exists {
+		watcher.cancel()
+		delete(op.awsBillingNotificationWatchers, key)
+	}
+}
+
+func (op *Reporting) watchAWSBillingNotifications(ctx context.Context, logger log.FieldLogger, notifier aws.ManifestNotifier, key string) {
+	for {
+		found, err := notifier.WaitForManifests(ctx)
+		if ctx.Err() != nil {
+			logger.Infof("S3 event notification watcher stopped")
+			return
+		}
+		if found {
+			logger.Infof("new AWS billing report manifest written, queuing ReportDataSource %s to update partitions", key)
+			op.reportDataSourceQueue.Add(key)
+		}
+		if err != nil {
+			retryInterval := wait.Jitter(awsBillingNotificationRetryInterval, 0.5)
+			logger.WithError(err).Errorf("error receiving S3 event notifications, retrying in %s", retryInterval)
+			select {
+			case <-time.After(retryInterval):
+			case <-ctx.Done():
+				logger.Infof("S3 event notification watcher stopped")
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package operator
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/azure"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+var azureCostManagementReportDatasourcePartitionsGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: prometheusMetricNamespace,
+		Name:      "azure_cost_management_reportdatasource_partitions",
+		Help:      "Current number of partitions in a AzureCostManagement ReportDataSource table.",
+	},
+	[]string{"reportdatasource", "table_name"},
+)
+
+func init() {
+	prometheus.MustRegister(azureCostManagementReportDatasourcePartitionsGauge)
+}
+
+func (op *Reporting) handleAzureCostManagementDataSource(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	source := dataSource.Spec.AzureCostManagement.Source
+	if source == nil {
+		return reasonErrorf(nil, cbutil.ValidationErrorReason, "ReportDataSource %q: improperly configured datasource, source is empty", dataSource.Name)
+	}
+	if source.StorageAccount == "" || source.Container == "" {
+		return reasonErrorf(nil, cbutil.ValidationErrorReason, "ReportDataSource %q: improperly configured datasource, source.storageAccount and source.container are required", dataSource.Name)
+	}
+	if source.AccountKeySecret == nil {
+		return reasonErrorf(nil, cbutil.ValidationErrorReason, "ReportDataSource %q: improperly configured datasource, source.accountKeySecret is empty", dataSource.Name)
+	}
+
+	if dataSource.Status.TableName != "" {
+		logger.Infof("existing AzureCostManagement ReportDataSource discovered, tableName: %s", dataSource.Status.TableName)
+	} else {
+		logger.Infof("new AzureCostManagement ReportDataSource discovered")
+	}
+
+	secret, err := op.kubeClient.Secrets(dataSource.Namespace).Get(source.AccountKeySecret.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to get storage account key Secret %s for ReportDataSource %s: %v", source.AccountKeySecret.Name, dataSource.Name, err)
+	}
+	accountKey, ok := secret.Data[source.AccountKeySecret.Key]
+	if !ok {
+		return reasonErrorf(nil, cbutil.ValidationErrorReason, "storage account key Secret %s for ReportDataSource %s has no key %s", secret.Name, dataSource.Name, source.AccountKeySecret.Key)
+	}
+	client, err := azure.NewBlobClient(source.StorageAccount, string(accountKey))
+	if err != nil {
+		return reasonErrorf(err, cbutil.ValidationErrorReason, "ReportDataSource %q: %v", dataSource.Name, err)
+	}
+
+	manifestRetriever := azure.NewManifestRetriever(client, source.Container, source.Directory)
+	manifests, err := manifestRetriever.RetrieveManifests()
+	if err != nil {
+		return err
+	}
+
+	if len(manifests) == 0 {
+		logger.Warnf("ReportDataSource %q has no export manifests in it's container, the export has likely not run yet", dataSource.Name)
+		op.enqueueReportDataSourceAfter(dataSource, partitionUpdateInterval)
+		return nil
+	}
+
+	if dataSource.Status.TableName == "" {
+		tableName := op.dataSourceTableName(dataSource)
+		// use the columns of the newest export, since the columns exported
+		// can change between versions of the export's dataset.
+		columns, err := manifestRetriever.RetrieveColumns(manifests[len(manifests)-1])
+		if err != nil {
+			return err
+		}
+		logger.Debugf("creating AzureCostManagement DataSource table %s pointing to storage account %s container %s in directory %s", tableName, source.StorageAccount, source.Container, source.Directory)
+		err = op.createAzureCostManagementTable(logger, dataSource, tableName, source, columns)
+		if err != nil {
+			return err
+		}
+
+		logger.Debugf("successfully created AzureCostManagement DataSource table %s pointing to storage account %s container %s in directory %s", tableName, source.StorageAccount, source.Container, source.Directory)
+		dataSource, err = op.updateDataSourceTableName(logger, dataSource, tableName)
+		if err != nil {
+			return err
+		}
+	}
+
+	gauge := azureCostManagementReportDatasourcePartitionsGauge.WithLabelValues(dataSource.Name, dataSource.Status.TableName)
+	prestoTableResourceName := reportingutil.PrestoTableResourceNameFromKind("ReportDataSource", dataSource.Name)
+	prestoTable, err := op.prestoTableLister.PrestoTables(dataSource.Namespace).Get(prestoTableResourceName)
+	if err != nil {
+		// if not found, try for the uncached copy
+		if apierrors.IsNotFound(err) {
+			prestoTable, err = op.meteringClient.MeteringV1alpha1().PrestoTables(dataSource.Namespace).Get(prestoTableResourceName, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+		} else {
+			return err
+		}
+	}
+
+	logger.Infof("updating partitions for presto table %s", prestoTable.Name)
+	desiredPartitions, err := getAzureCostManagementDesiredPartitions(source, manifests)
+	if err != nil {
+		return err
+	}
+	err = op.updateBillingPeriodPartitions(logger, gauge, prestoTable, desiredPartitions)
+	if err != nil {
+		return fmt.Errorf("error updating Azure Cost Management partitions for ReportDataSource %s: %v", dataSource.Name, err)
+	}
+
+	nextUpdate := op.clock.Now().Add(partitionUpdateInterval).UTC()
+	logger.Infof("queuing AzureCostManagement ReportDataSource %s to update partitions again in %s at %s", dataSource.Name, partitionUpdateInterval, nextUpdate)
+	op.enqueueReportDataSourceAfter(dataSource, partitionUpdateInterval)
+	return nil
+}
+
+// createAzureCostManagementTable instantiates a new external Hive table for
+// Azure Cost Management exports stored in Azure Blob Storage.
+func (op *Reporting) createAzureCostManagementTable(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource, tableName string, source *cbTypes.AzureBlobContainer, columnNames []string) error {
+	location, err := hive.AzureBlobLocation(source.StorageAccount, source.Container, source.Directory)
+	if err != nil {
+		return err
+	}
+
+	// OpenCSVSerde only supports string columns, so values must be cast
+	// when queried.
+	columns := make([]hive.Column, 0, len(columnNames))
+	seen := make(map[string]struct{})
+	for _, columnName := range columnNames {
+		name := reportingutil.SanetizeAzureColumnForHive(columnName)
+		if _, exists := seen[name]; exists {
+			return fmt.Errorf("export has multiple columns named %s", name)
+		}
+		seen[name] = struct{}{}
+		columns = append(columns, hive.Column{Name: name, Type: "string"})
+	}
+
+	params := hive.TableParameters{
+		Name:         tableName,
+		Columns:      columns,
+		Partitions:   reportingutil.AzureCostManagementHivePartitions,
+		IgnoreExists: true,
+	}
+	properties := hive.TableProperties{
+		Location:           location,
+		FileFormat:         "textfile",
+		SerdeFormat:        reportingutil.AzureCostManagementHiveSerde,
+		SerdeRowProperties: reportingutil.AzureCostManagementHiveSerdeProps,
+		TableProperties:    reportingutil.AzureCostManagementHiveTableProps,
+		External:           true,
+	}
+	return op.createTableWith(op.workCtx, logger, dataSource, cbTypes.SchemeGroupVersion.WithKind("ReportDataSource"), params, properties)
+}
+
+func getAzureCostManagementDesiredPartitions(source *cbTypes.AzureBlobContainer, manifests []*azure.Manifest) ([]cbTypes.TablePartition, error) {
+	desiredPartitions := make([]cbTypes.TablePartition, 0)
+	// Manifests of the newest run of each billing period have a one-to-one
+	// correlation with hive partitions
+	for _, manifest := range manifests {
+		dataDirectory := manifest.DataDirectory()
+		if dataDirectory == "" {
+			continue
+		}
+		location, err := hive.AzureBlobLocation(source.StorageAccount, source.Container, dataDirectory)
+		if err != nil {
+			return nil, err
+		}
+
+		desiredPartitions = append(desiredPartitions, cbTypes.TablePartition{
+			Location: location,
+			PartitionSpec: presto.PartitionSpec{
+				"start": reportingutil.BillingPeriodTimestamp(manifest.BillingPeriodStart()),
+				"end":   reportingutil.BillingPeriodTimestamp(manifest.BillingPeriodEnd()),
+			},
+		})
+	}
+	return desiredPartitions, nil
+}
@@ -0,0 +1,156 @@
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const (
+	DefaultPartitionCompactionInterval = time.Hour
+
+	defaultPartitionCompactionDelay = time.Hour
+	// maxCompactedPartitionsPerCheck limits how many partitions of each
+	// table are compacted per check, so enabling compaction for a table with
+	// a lot of existing data doesn't occupy Hive for hours.
+	maxCompactedPartitionsPerCheck = 24
+)
+
+var (
+	compactedPartitionsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: prometheusMetricNamespace,
+			Name:      "reportdatasource_compacted_partitions_total",
+			Help:      "Number of partitions of a ReportDataSource's table which have been compacted.",
+		},
+		[]string{"reportdatasource", "table_name"},
+	)
+	failedPartitionCompactionsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: prometheusMetricNamespace,
+			Name:      "reportdatasource_failed_partition_compactions_total",
+			Help:      "Number of partitions of a ReportDataSource's table which failed to be compacted.",
+		},
+		[]string{"reportdatasource", "table_name"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(compactedPartitionsCounter)
+	prometheus.MustRegister(failedPartitionCompactionsCounter)
+}
+
+// runPartitionCompaction periodically compacts the partitions of
+// ReportDataSources with compaction enabled until stopCh is closed.
+func (op *Reporting) runPartitionCompaction(stopCh <-chan struct{}) {
+	wait.Until(op.compactPartitions, op.cfg.PartitionCompactionInterval, stopCh)
+}
+
+func (op *Reporting) compactPartitions() {
+	logger := op.logger.WithField("component", "partitionCompaction")
+	dataSources, err := op.reportDataSourceLister.List(labels.Everything())
+	if err != nil {
+		logger.WithError(err).Errorf("unable to list ReportDataSources")
+		return
+	}
+	for _, dataSource := range dataSources {
+		if dataSource.Spec.Promsum == nil || dataSource.Spec.Promsum.Compaction == nil || dataSource.Status.TableName == "" || !op.cfg.isWatchedNamespace(dataSource.Namespace) {
+			continue
+		}
+		err := op.compactDataSourcePartitions(newObjectLogger(logger, "ReportDataSource", dataSource), dataSource)
+		if err != nil {
+			logger.WithError(err).Errorf("unable to compact partitions of ReportDataSource %s", dataSource.Name)
+		}
+	}
+}
+
+// compactDataSourcePartitions rewrites each complete partition of the
+// ReportDataSource's table which hasn't been compacted yet. Rewriting a
+// partition with INSERT OVERWRITE causes Hive to combine it's small files
+// into a small number of large ones, and the new files replace the old ones
+// once they've all been written, so queries never see a partially compacted
+// partition.
+func (op *Reporting) compactDataSourcePartitions(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	tableName := dataSource.Status.TableName
+	granularity := dataSourcePartitionGranularity(dataSource)
+	partitionStarts := partitionsToCompact(dataSource, op.clock.Now())
+	if len(partitionStarts) == 0 {
+		return nil
+	}
+
+	var compactedUntil time.Time
+	var compactErr error
+	for _, start := range partitionStarts {
+		dt := granularity.Partition(start)
+		logger.Debugf("compacting partition dt=%s of table %s", dt, tableName)
+		query := fmt.Sprintf("SELECT amount, `timestamp`, timeprecision, labels, cluster_id FROM %s WHERE dt = '%s'", tableName, dt)
+		if err := op.tableManager.InsertOverwritePartition(op.workCtx, tableName, "dt", dt, query); err != nil {
+			failedPartitionCompactionsCounter.WithLabelValues(dataSource.Name, tableName).Inc()
+			compactErr = fmt.Errorf("unable to compact partition dt=%s of table %s: %v", dt, tableName, err)
+			break
+		}
+		compactedPartitionsCounter.WithLabelValues(dataSource.Name, tableName).Inc()
+		compactedUntil = granularity.NextPartitionStart(start)
+	}
+	if compactedUntil.IsZero() {
+		return compactErr
+	}
+	logger.Infof("compacted partitions of table %s until %s", tableName, compactedUntil)
+
+	// record the progress made, even if a partition failed to be compacted
+	dataSource = dataSource.DeepCopy()
+	now := metav1.NewTime(op.clock.Now())
+	until := metav1.NewTime(compactedUntil)
+	dataSource.Status.CompactionStatus = &cbTypes.PartitionCompactionStatus{
+		CompactedUntil:     &until,
+		LastCompactionTime: &now,
+	}
+	if _, err := op.meteringClient.MeteringV1alpha1().ReportDataSources(dataSource.Namespace).Update(dataSource); err != nil {
+		return fmt.Errorf("unable to update ReportDataSource %s compaction status: %v", dataSource.Name, err)
+	}
+	return compactErr
+}
+
+// partitionsToCompact returns the start of each partition of the
+// ReportDataSource's table which hasn't been compacted yet, and ended at
+// least the compaction delay before now, oldest first. Compaction begins
+// with the partition containing the earliest metric imported, or when the
+// ReportDataSource was created if nothing has been imported by polling
+// Prometheus, and skips partitions which have been pruned.
+func partitionsToCompact(dataSource *cbTypes.ReportDataSource, now time.Time) []time.Time {
+	granularity := dataSourcePartitionGranularity(dataSource)
+	delay := defaultPartitionCompactionDelay
+	if compaction := dataSource.Spec.Promsum.Compaction; compaction != nil && compaction.Delay != nil {
+		delay = compaction.Delay.Duration
+	}
+
+	start := dataSource.CreationTimestamp.Time
+	status := dataSource.Status
+	switch {
+	case status.CompactionStatus != nil && status.CompactionStatus.CompactedUntil != nil:
+		start = status.CompactionStatus.CompactedUntil.Time
+	case status.PrometheusMetricImportStatus != nil && status.PrometheusMetricImportStatus.EarliestImportedMetricTime != nil:
+		start = status.PrometheusMetricImportStatus.EarliestImportedMetricTime.Time
+	}
+	// don't recreate partitions dropped because of the retention
+	if status.RetentionStatus != nil && status.RetentionStatus.PrunedUntil != nil && status.RetentionStatus.PrunedUntil.Time.After(start) {
+		start = status.RetentionStatus.PrunedUntil.Time
+	}
+
+	cutoff := now.Add(-delay)
+	var partitionStarts []time.Time
+	for t := granularity.PartitionStart(start); !granularity.NextPartitionStart(t).After(cutoff); t = granularity.NextPartitionStart(t) {
+		if len(partitionStarts) == maxCompactedPartitionsPerCheck {
+			break
+		}
+		partitionStarts = append(partitionStarts, t)
+	}
+	return partitionStarts
+}
@@ -0,0 +1,94 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+func TestPartitionsToCompact(t *testing.T) {
+	created := time.Date(2019, time.January, 1, 6, 0, 0, 0, time.UTC)
+	now := time.Date(2019, time.January, 3, 0, 30, 0, 0, time.UTC)
+	earliest := metav1.NewTime(time.Date(2018, time.December, 31, 12, 0, 0, 0, time.UTC))
+	compactedUntil := metav1.NewTime(time.Date(2019, time.January, 2, 0, 0, 0, 0, time.UTC))
+	day := func(d int) time.Time {
+		return time.Date(2019, time.January, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name        string
+		granularity prestostore.PartitionGranularity
+		delay       time.Duration
+		status      cbTypes.ReportDataSourceStatus
+		expected    []time.Time
+	}{
+		{
+			name:     "starts from when the ReportDataSource was created",
+			expected: []time.Time{day(1)},
+		},
+		{
+			name:     "a short delay includes the previous day",
+			delay:    time.Minute,
+			expected: []time.Time{day(1), day(2)},
+		},
+		{
+			name: "starts from the earliest imported metric",
+			status: cbTypes.ReportDataSourceStatus{
+				PrometheusMetricImportStatus: &cbTypes.PrometheusMetricImportStatus{EarliestImportedMetricTime: &earliest},
+			},
+			expected: []time.Time{day(1).AddDate(0, 0, -1), day(1)},
+		},
+		{
+			name: "continues from the last partition compacted",
+			status: cbTypes.ReportDataSourceStatus{
+				PrometheusMetricImportStatus: &cbTypes.PrometheusMetricImportStatus{EarliestImportedMetricTime: &earliest},
+				CompactionStatus:             &cbTypes.PartitionCompactionStatus{CompactedUntil: &compactedUntil},
+			},
+			delay:    time.Minute,
+			expected: []time.Time{day(2)},
+		},
+		{
+			name:        "weekly partitions aren't complete until the end of the week",
+			granularity: prestostore.PartitionGranularityWeekly,
+			expected:    nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			status.PartitionGranularity = string(tt.granularity)
+			compaction := &cbTypes.PartitionCompactionConfig{}
+			if tt.delay != 0 {
+				compaction.Delay = &metav1.Duration{Duration: tt.delay}
+			}
+			dataSource := &cbTypes.ReportDataSource{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+				Spec: cbTypes.ReportDataSourceSpec{
+					Promsum: &cbTypes.PrometheusMetricsDataSource{Compaction: compaction},
+				},
+				Status: status,
+			}
+			assert.Equal(t, tt.expected, partitionsToCompact(dataSource, now))
+		})
+	}
+}
+
+func TestPartitionsToCompactLimit(t *testing.T) {
+	created := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	dataSource := &cbTypes.ReportDataSource{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+		Spec: cbTypes.ReportDataSourceSpec{
+			Promsum: &cbTypes.PrometheusMetricsDataSource{Compaction: &cbTypes.PartitionCompactionConfig{}},
+		},
+		Status: cbTypes.ReportDataSourceStatus{PartitionGranularity: string(prestostore.PartitionGranularityHourly)},
+	}
+	partitions := partitionsToCompact(dataSource, created.AddDate(0, 0, 7))
+	assert.Len(t, partitions, maxCompactedPartitionsPerCheck)
+	assert.Equal(t, created, partitions[0])
+}
@@ -0,0 +1,193 @@
+package operator
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/configbundle"
+)
+
+type ImportConfigBundleRequest struct {
+	Bundle *configbundle.Bundle `json:"bundle"`
+	// NamespaceMapping maps the namespaces of resources in the bundle to the
+	// namespaces they should be imported into.
+	NamespaceMapping map[string]string `json:"namespaceMapping,omitempty"`
+}
+
+type ImportConfigBundleResponse struct {
+	Results []configbundle.ImportResult `json:"results"`
+}
+
+// configBundleResources are the resources a bundle can contain.
+var configBundleResources = []string{
+	resultsResourceReportDataSources,
+	resultsResourceReportGenerationQueries,
+	resultsResourceScheduledReports,
+}
+
+type configBundleResourceAccess struct {
+	resource  string
+	namespace string
+}
+
+// configBundleAccess returns each resource and namespace of the resources in
+// the bundle.
+func configBundleAccess(bundle *configbundle.Bundle) []configBundleResourceAccess {
+	seen := make(map[configBundleResourceAccess]bool)
+	var accesses []configBundleResourceAccess
+	add := func(resource, namespace string) {
+		access := configBundleResourceAccess{resource: resource, namespace: namespace}
+		if !seen[access] {
+			seen[access] = true
+			accesses = append(accesses, access)
+		}
+	}
+	for _, dataSource := range bundle.ReportDataSources {
+		add(resultsResourceReportDataSources, dataSource.Namespace)
+	}
+	for _, query := range bundle.ReportGenerationQueries {
+		add(resultsResourceReportGenerationQueries, query.Namespace)
+	}
+	for _, scheduledReport := range bundle.ScheduledReports {
+		add(resultsResourceScheduledReports, scheduledReport.Namespace)
+	}
+	return accesses
+}
+
+// exportConfigBundleHandler returns the ReportDataSources,
+// ReportGenerationQueries and ScheduledReports in the watched namespaces as a
+// single bundle. The namespace query parameter limits the bundle to a single
+// namespace.
+func (op *Reporting) exportConfigBundleHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(op.logger, r, op.rand)
+	if r.Method != "GET" {
+		writeErrorResponse(logger, w, r, http.StatusMethodNotAllowed, "only GET is supported")
+		return
+	}
+
+	namespace := r.URL.Query().Get("namespace")
+	if namespace != "" && !op.cfg.isWatchedNamespace(namespace) {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "namespace %s is not watched by reporting-operator", namespace)
+		return
+	}
+	if namespace != "" {
+		for _, resource := range configBundleResources {
+			if !op.resultsAuthorizer.authorizeRequest(logger, w, r, "get", resource, namespace) {
+				return
+			}
+		}
+	}
+	// without a namespace, the bundle only contains the resources the
+	// caller can get
+	canGet, ok := op.resultsAuthorizer.requestFilter(logger, w, r)
+	if !ok {
+		return
+	}
+	include := func(resource, ns string) bool {
+		if namespace != "" && ns != namespace {
+			return false
+		}
+		return op.cfg.isWatchedNamespace(ns) && canGet(resource, ns)
+	}
+
+	bundle, err := op.exportConfigBundle(include)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to export configuration: %v", err)
+		return
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, bundle)
+}
+
+// exportConfigBundle returns a bundle of the resources for which include
+// returns true.
+func (op *Reporting) exportConfigBundle(include func(resource, namespace string) bool) (*configbundle.Bundle, error) {
+	allDataSources, err := op.reportDataSourceLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	allQueries, err := op.reportGenerationQueryLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	allScheduledReports, err := op.scheduledReportLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	var dataSources []*cbTypes.ReportDataSource
+	for _, dataSource := range allDataSources {
+		if include(resultsResourceReportDataSources, dataSource.Namespace) {
+			dataSources = append(dataSources, dataSource)
+		}
+	}
+	var queries []*cbTypes.ReportGenerationQuery
+	for _, query := range allQueries {
+		if include(resultsResourceReportGenerationQueries, query.Namespace) {
+			queries = append(queries, query)
+		}
+	}
+	var scheduledReports []*cbTypes.ScheduledReport
+	for _, scheduledReport := range allScheduledReports {
+		if include(resultsResourceScheduledReports, scheduledReport.Namespace) {
+			scheduledReports = append(scheduledReports, scheduledReport)
+		}
+	}
+	return configbundle.New(dataSources, queries, scheduledReports), nil
+}
+
+// importConfigBundleHandler creates or updates the resources in a bundle,
+// after remapping their namespaces.
+func (op *Reporting) importConfigBundleHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(op.logger, r, op.rand)
+	if r.Method != "POST" {
+		writeErrorResponse(logger, w, r, http.StatusMethodNotAllowed, "only POST is supported")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	var req ImportConfigBundleRequest
+	err := decoder.Decode(&req)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to decode request as JSON: %v", err)
+		return
+	}
+	if req.Bundle == nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "bundle must be set")
+		return
+	}
+	if err := req.Bundle.Validate(); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "invalid bundle: %v", err)
+		return
+	}
+
+	bundle := req.Bundle.RemapNamespaces(req.NamespaceMapping)
+	for _, namespace := range bundle.Namespaces() {
+		if !op.cfg.isWatchedNamespace(namespace) {
+			writeErrorResponse(logger, w, r, http.StatusBadRequest, "namespace %s is not watched by reporting-operator", namespace)
+			return
+		}
+	}
+	// importing creates each resource, or updates it if it already exists
+	for _, access := range configBundleAccess(bundle) {
+		for _, verb := range []string{"create", "update"} {
+			if !op.resultsAuthorizer.authorizeRequest(logger, w, r, verb, access.resource, access.namespace) {
+				return
+			}
+		}
+	}
+
+	results, err := configbundle.Import(op.meteringClient, bundle)
+	for _, result := range results {
+		logger.Infof("%s %s %s/%s", result.Action, result.Kind, result.Namespace, result.Name)
+	}
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "import failed after %d resources: %v", len(results), err)
+		return
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, ImportConfigBundleResponse{
+		Results: results,
+	})
+}
@@ -1,7 +1,6 @@
 package operator
 
 import (
-	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -14,11 +13,14 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
 	"github.com/operator-framework/operator-metering/pkg/aws"
+	"github.com/operator-framework/operator-metering/pkg/db"
 	"github.com/operator-framework/operator-metering/pkg/hive"
 	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
 	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
 	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/tracing"
 	"github.com/operator-framework/operator-metering/pkg/util/slice"
 )
 
@@ -37,8 +39,12 @@ var (
 		{Name: "timestamp", Type: "timestamp"},
 		{Name: "timePrecision", Type: "double"},
 		{Name: "labels", Type: "map<string, string>"},
+		promsumClusterIDColumn,
 	}
-	promsumHivePartitions = []hive.Column{
+	// promsumClusterIDColumn is added to the tables of ReportDataSources
+	// created before it existed by addPromsumClusterIDColumn.
+	promsumClusterIDColumn = hive.Column{Name: "cluster_id", Type: "string"}
+	promsumHivePartitions  = []hive.Column{
 		{Name: "dt", Type: "string"},
 	}
 
@@ -57,10 +63,9 @@ func init() {
 }
 
 func (op *Reporting) runReportDataSourceWorker() {
-	logger := op.logger.WithField("component", "reportDataSourceWorker")
+	logger := op.logger.WithField(logFieldController, "reportdatasource")
 	logger.Infof("ReportDataSource worker started")
-	const maxRequeues = 20
-	for op.processResource(logger, op.syncReportDataSource, "ReportDataSource", op.reportDataSourceQueue, maxRequeues) {
+	for op.processResource(logger, op.syncReportDataSource, "ReportDataSource", op.reportDataSourceQueue, op.cfg.MaxRetriesConfig.ReportDataSource) {
 	}
 }
 
@@ -71,7 +76,6 @@ func (op *Reporting) syncReportDataSource(logger log.FieldLogger, key string) er
 		return nil
 	}
 
-	logger = logger.WithField("ReportDataSource", name)
 	reportDataSource, err := op.reportDataSourceLister.ReportDataSources(namespace).Get(name)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
@@ -81,26 +85,66 @@ func (op *Reporting) syncReportDataSource(logger log.FieldLogger, key string) er
 		return err
 	}
 
-	if reportDataSource.DeletionTimestamp != nil {
+	// Deep-copy otherwise we are mutating our cache
+	ds := reportDataSource.DeepCopy()
+
+	if ds.DeletionTimestamp != nil {
+		// ReportDataSources created before finalizers were enabled, or whose
+		// finalizer was removed by someone else, aren't cleaned up
+		if !slice.ContainsString(ds.Finalizers, reportDataSourceFinalizer, nil) {
+			logger.Infof("ReportDataSource is marked for deletion without the %s finalizer, skipping cleanup", reportDataSourceFinalizer)
+			return nil
+		}
 		logger.Infof("ReportDataSource is marked for deletion, performing cleanup")
-		_, err = op.removeReportDataSourceFinalizer(reportDataSource)
+		err = op.cleanupReportDataSource(logger, ds)
+		if err != nil {
+			return err
+		}
+		_, err = op.removeReportDataSourceFinalizer(ds)
 		return err
 	}
 
-	// Deep-copy otherwise we are mutating our cache
-	ds := reportDataSource.DeepCopy()
 	return op.handleReportDataSource(logger, ds)
 }
 
 func (op *Reporting) handleReportDataSource(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
 	var err error
+	if op.cfg.EnableFinalizers && reportDataSourceNeedsFinalizer(dataSource) {
+		dataSource, err = op.addReportDataSourceFinalizer(dataSource)
+		if err != nil {
+			return err
+		}
+	}
+
+	if op.cfg.AgentConfig.Enabled() {
+		// agents only collect Prometheus metrics, the central
+		// reporting-operator handles every other type of ReportDataSource.
+		if dataSource.Spec.Promsum == nil {
+			logger.Debugf("running as an agent, skipping ReportDataSource %s which isn't a Prometheus ReportDataSource", dataSource.Name)
+			return nil
+		}
+		return op.handlePrometheusMetricsDataSource(logger, dataSource)
+	}
+
 	switch {
 	case dataSource.Spec.Promsum != nil:
 		err = op.handlePrometheusMetricsDataSource(logger, dataSource)
 	case dataSource.Spec.AWSBilling != nil:
 		err = op.handleAWSBillingDataSource(logger, dataSource)
+	case dataSource.Spec.AzureCostManagement != nil:
+		err = op.handleAzureCostManagementDataSource(logger, dataSource)
+	case dataSource.Spec.File != nil:
+		err = op.handleFileDataSource(logger, dataSource)
+	case dataSource.Spec.SQL != nil:
+		err = op.handleSQLDataSource(logger, dataSource)
+	case dataSource.Spec.Kafka != nil:
+		err = op.handleKafkaDataSource(logger, dataSource)
+	case dataSource.Spec.S3Inventory != nil:
+		err = op.handleS3InventoryDataSource(logger, dataSource)
+	case dataSource.Spec.NamespaceMetadata != nil:
+		err = op.handleNamespaceMetadataDataSource(logger, dataSource)
 	default:
-		err = fmt.Errorf("ReportDataSource %s: improperly configured missing promsum or awsBilling configuration", dataSource.Name)
+		err = reasonErrorf(nil, cbutil.ValidationErrorReason, "ReportDataSource %s: improperly configured missing promsum, awsBilling, azureCostManagement, file, sql, kafka, s3Inventory or namespaceMetadata configuration", dataSource.Name)
 	}
 	if err != nil {
 		return err
@@ -118,25 +162,30 @@ func (op *Reporting) handlePrometheusMetricsDataSource(logger log.FieldLogger, d
 		return fmt.Errorf("%s is not a Promsum ReportDataSource", dataSource.Name)
 	}
 
-	if op.cfg.EnableFinalizers && reportDataSourceNeedsFinalizer(dataSource) {
-		var err error
-		dataSource, err = op.addReportDataSourceFinalizer(dataSource)
-		if err != nil {
+	switch {
+	case op.cfg.AgentConfig.Enabled():
+		// the table is created by the central reporting-operator, which
+		// stores the metrics pushed for it's ReportDataSource of the same
+		// name.
+	case dataSource.Status.TableName != "":
+		logger.Infof("existing Prometheus ReportDataSource discovered, tableName: %s", dataSource.Status.TableName)
+		if err := op.addPromsumClusterIDColumn(logger, dataSource); err != nil {
 			return err
 		}
-	}
-
-	if dataSource.Status.TableName != "" {
-		logger.Infof("existing Prometheus ReportDataSource discovered, tableName: %s", dataSource.Status.TableName)
-	} else {
+	default:
 		logger.Infof("new Prometheus ReportDataSource discovered")
+		granularity := prestostore.PartitionGranularity(dataSource.Spec.Promsum.PartitionGranularity)
+		if err := granularity.Validate(); err != nil {
+			return fmt.Errorf("invalid ReportDataSource %s: %v", dataSource.Name, err)
+		}
 		storage := dataSource.Spec.Promsum.Storage
-		tableName := reportingutil.DataSourceTableName(dataSource.Name)
-		err := op.createTableForStorage(logger, dataSource, cbTypes.SchemeGroupVersion.WithKind("ReportDataSource"), storage, tableName, promsumHiveColumns, promsumHivePartitions)
+		tableName := op.dataSourceTableName(dataSource)
+		err := op.createTableForStorage(op.workCtx, logger, dataSource, cbTypes.SchemeGroupVersion.WithKind("ReportDataSource"), storage, tableName, promsumHiveColumns, promsumHivePartitions)
 		if err != nil {
 			return err
 		}
 
+		dataSource.Status.PartitionGranularity = string(granularity)
 		dataSource, err = op.updateDataSourceTableName(logger, dataSource, tableName)
 		if err != nil {
 			logger.WithError(err).Errorf("failed to update ReportDataSource TableName field %q", tableName)
@@ -156,9 +205,26 @@ func (op *Reporting) handlePrometheusMetricsDataSource(logger log.FieldLogger, d
 		return nil
 	}
 
+	if dataSource.Spec.Promsum.RemoteWrite != nil {
+		logger.Debugf("ReportDataSource is populated by the Prometheus remote-write endpoint, skipping periodic import")
+		return nil
+	}
+
+	if dataSource.Spec.Priority == cbTypes.ReportDataSourcePriorityLow && !op.cfg.AgentConfig.Enabled() {
+		storageLocation, err := op.overBudgetStorageLocation(dataSource)
+		if err != nil {
+			return fmt.Errorf("unable to determine if ReportDataSource %s storage is over budget: %v", dataSource.Name, err)
+		}
+		if storageLocation != "" {
+			logger.Warnf("StorageLocation %s is projected to exceed it's storage budget, pausing collection for low priority ReportDataSource", storageLocation)
+			op.enqueueReportDataSourceAfter(dataSource, op.cfg.StorageBudgetCheckInterval)
+			return nil
+		}
+	}
+
 	dataSourceName := dataSource.Name
 	queryName := dataSource.Spec.Promsum.Query
-	tableName := reportingutil.DataSourceTableName(dataSourceName)
+	tableName := op.dataSourceTableName(dataSource)
 
 	reportPromQuery, err := op.reportPrometheusQueryLister.ReportPrometheusQueries(dataSource.Namespace).Get(queryName)
 	if err != nil {
@@ -166,9 +232,8 @@ func (op *Reporting) handlePrometheusMetricsDataSource(logger log.FieldLogger, d
 	}
 
 	dataSourceLogger := logger.WithFields(log.Fields{
-		"queryName":        queryName,
-		"reportDataSource": dataSourceName,
-		"tableName":        tableName,
+		"queryName": queryName,
+		"tableName": tableName,
 	})
 
 	importerCfg := op.newPromImporterCfg(dataSource, reportPromQuery)
@@ -177,7 +242,7 @@ func (op *Reporting) handlePrometheusMetricsDataSource(logger log.FieldLogger, d
 	importer, err := func() (*prestostore.PrometheusImporter, error) {
 		op.importersMu.Lock()
 		defer op.importersMu.Unlock()
-		importer, exists := op.importers[dataSourceName]
+		importer, exists := op.importers[importerKey(dataSource)]
 		if exists {
 			dataSourceLogger.Debugf("ReportDataSource %s already has an importer, updating configuration", dataSourceName)
 			importer.UpdateConfig(importerCfg)
@@ -188,25 +253,44 @@ func (op *Reporting) handlePrometheusMetricsDataSource(logger log.FieldLogger, d
 		if err != nil {
 			return nil, err
 		}
-		op.importers[dataSourceName] = importer
+		// resume from where the previous importer for this ReportDataSource
+		// left off, if it recorded a checkpoint.
+		if status := dataSource.Status.PrometheusMetricImportStatus; status != nil && status.ImportCheckpoint != nil {
+			dataSourceLogger.Infof("resuming import from checkpoint %s", status.ImportCheckpoint.Time)
+			importer.SetLastTimestamp(status.ImportCheckpoint.Time)
+		}
+		op.importers[importerKey(dataSource)] = importer
 		return importer, nil
 	}()
 	if err != nil {
 		return err
 	}
 
+	importCtx, span := op.tracer.Start(db.WithLogger(op.workCtx, dataSourceLogger), "prometheus.import",
+		tracing.String("metering.key", dataSource.Namespace+"/"+dataSource.Name),
+		tracing.String("db.table", tableName),
+	)
+	defer span.End()
+	release, err := op.acquirePrometheusImportSlot(importCtx, dataSource)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
 	importTime := op.clock.Now().UTC()
-	results, err := importer.ImportFromLastTimestamp(context.Background(), allowIncompleteChunks)
+	results, err := importer.ImportFromLastTimestamp(importCtx, allowIncompleteChunks)
+	release()
+	span.SetError(err)
 	if err != nil {
-		return fmt.Errorf("ImportFromLastTimestamp errored: %v", err)
+		return reasonErrorf(err, cbutil.PrestoErrorReason, "ImportFromLastTimestamp errored: %v", err)
 	}
 	numResultsImported := len(results.ProcessedTimeRanges)
 
 	// default to importing at the configured import interval
 	importDelay := op.getQueryIntervalForReportDataSource(dataSource)
 
-	var earliestImportedMetricTime, newestImportedMetricTime *metav1.Time
+	var earliestImportedMetricTime, newestImportedMetricTime, importCheckpoint *metav1.Time
 	if dataSource.Status.PrometheusMetricImportStatus != nil {
+		importCheckpoint = dataSource.Status.PrometheusMetricImportStatus.ImportCheckpoint
 		if dataSource.Status.PrometheusMetricImportStatus.EarliestImportedMetricTime != nil {
 			earliestImportedMetricTime = dataSource.Status.PrometheusMetricImportStatus.EarliestImportedMetricTime
 		}
@@ -233,6 +317,8 @@ func (op *Reporting) handlePrometheusMetricsDataSource(logger log.FieldLogger, d
 		}
 
 		lastTS := lastTimeRange.End
+		checkpoint := metav1.NewTime(lastTS)
+		importCheckpoint = &checkpoint
 		if newestImportedMetricTime == nil || newestImportedMetricTime.Time.Before(lastTS) {
 			newestImportedMetricTime = &metav1.Time{lastTS}
 		}
@@ -250,16 +336,29 @@ func (op *Reporting) handlePrometheusMetricsDataSource(logger log.FieldLogger, d
 		}
 	}
 
+	var backfillStatus *cbTypes.PrometheusBackfillStatus
+	if backfillStart := backfillStartTime(dataSource); backfillStart != nil {
+		backfillStatus = newPrometheusBackfillStatus(*backfillStart, dataSource.CreationTimestamp.Time.UTC(), newestImportedMetricTime)
+		if !backfillStatus.Completed {
+			logger.Infof("backfilling Prometheus ReportDataSource %s from %s, %d%% complete", dataSourceName, backfillStatus.StartTime.Time, backfillStatus.PercentComplete)
+		}
+	}
+
 	// Update the status to indicate where we are in the metric import process
 	dataSource.Status.PrometheusMetricImportStatus = &cbTypes.PrometheusMetricImportStatus{
 		EarliestImportedMetricTime: earliestImportedMetricTime,
 		NewestImportedMetricTime:   newestImportedMetricTime,
+		ImportCheckpoint:           importCheckpoint,
 		LastImportTime:             &metav1.Time{importTime},
+		Backfill:                   backfillStatus,
 	}
 	dataSource, err = op.meteringClient.MeteringV1alpha1().ReportDataSources(dataSource.Namespace).Update(dataSource)
 	if err != nil {
 		return fmt.Errorf("unable to update ReportDataSource %s PrometheusMetricImportStatus: %v", dataSourceName, err)
 	}
+	if newestImportedMetricTime != nil {
+		prometheusReportDatasourceImportLagCollector.set(dataSource, tableName, newestImportedMetricTime.Time)
+	}
 
 	nextImport := op.clock.Now().Add(importDelay).UTC()
 	logger.Infof("queuing Prometheus ReportDataSource %s to importing data again in %s at %s", dataSourceName, importDelay, nextImport)
@@ -267,10 +366,50 @@ func (op *Reporting) handlePrometheusMetricsDataSource(logger log.FieldLogger, d
 	return nil
 }
 
+// addPromsumClusterIDColumn adds the cluster_id column to the table of a
+// Prometheus ReportDataSource created before the column existed, using the
+// columns recorded in it's PrestoTable. Metrics stored before the column was
+// added have a NULL cluster_id.
+func (op *Reporting) addPromsumClusterIDColumn(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	prestoTableResourceName := reportingutil.PrestoTableResourceNameFromKind("ReportDataSource", dataSource.Name)
+	prestoTable, err := op.prestoTableLister.PrestoTables(dataSource.Namespace).Get(prestoTableResourceName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// tables created before PrestoTables existed can't be
+			// inspected, so they're left as they are.
+			logger.Warnf("no PrestoTable %s exists for ReportDataSource %s, unable to check if it's table has a cluster_id column", prestoTableResourceName, dataSource.Name)
+			return nil
+		}
+		return err
+	}
+	params := hive.TableParameters(prestoTable.Status.Parameters)
+	for _, col := range params.Columns {
+		if strings.EqualFold(col.Name, promsumClusterIDColumn.Name) {
+			return nil
+		}
+	}
+
+	logger.Infof("adding %s column to table %s", promsumClusterIDColumn.Name, params.Name)
+	if err := op.tableManager.AddColumns(op.workCtx, params, []hive.Column{promsumClusterIDColumn}); err != nil {
+		return reasonErrorf(err, cbutil.HiveErrorReason, "unable to add %s column to table %s: %v", promsumClusterIDColumn.Name, params.Name, err)
+	}
+
+	prestoTable = prestoTable.DeepCopy()
+	prestoTable.Status.Parameters.Columns = append(prestoTable.Status.Parameters.Columns, promsumClusterIDColumn)
+	_, err = op.meteringClient.MeteringV1alpha1().PrestoTables(prestoTable.Namespace).Update(prestoTable)
+	if err != nil {
+		return fmt.Errorf("unable to update PrestoTable %s columns: %v", prestoTable.Name, err)
+	}
+	return nil
+}
+
 func (op *Reporting) handleAWSBillingDataSource(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
 	source := dataSource.Spec.AWSBilling.Source
 	if source == nil {
-		return fmt.Errorf("ReportDataSource %q: improperly configured datasource, source is empty", dataSource.Name)
+		return reasonErrorf(nil, cbutil.ValidationErrorReason, "ReportDataSource %q: improperly configured datasource, source is empty", dataSource.Name)
+	}
+	if notifications := dataSource.Spec.AWSBilling.Notifications; notifications != nil && notifications.QueueURL == "" {
+		return reasonErrorf(nil, cbutil.ValidationErrorReason, "ReportDataSource %q: improperly configured datasource, notifications.queueURL is empty", dataSource.Name)
 	}
 
 	if dataSource.Status.TableName != "" {
@@ -279,7 +418,12 @@ func (op *Reporting) handleAWSBillingDataSource(logger log.FieldLogger, dataSour
 		logger.Infof("new AWSBilling ReportDataSource discovered")
 	}
 
-	manifestRetriever := aws.NewManifestRetriever(source.Region, source.Bucket, source.Prefix)
+	// start watching for notifications before looking for manifests, so the
+	// first manifest is discovered as soon as it's written. Manifests are
+	// still discovered by polling, in case notifications are missed.
+	op.ensureAWSBillingNotificationWatcher(logger, dataSource)
+
+	manifestRetriever := aws.NewManifestRetriever(source.Region, source.Bucket, source.Prefix, s3Endpoint(source.Endpoint), source.RoleARN)
 
 	manifests, err := manifestRetriever.RetrieveManifests()
 	if err != nil {
@@ -292,7 +436,7 @@ func (op *Reporting) handleAWSBillingDataSource(logger log.FieldLogger, dataSour
 	}
 
 	if dataSource.Status.TableName == "" {
-		tableName := reportingutil.DataSourceTableName(dataSource.Name)
+		tableName := op.dataSourceTableName(dataSource)
 		logger.Debugf("creating AWS Billing DataSource table %s pointing to s3 bucket %s at prefix %s", tableName, source.Bucket, source.Prefix)
 		err = op.createAWSUsageTable(logger, dataSource, tableName, source.Bucket, source.Prefix, manifests)
 		if err != nil {
@@ -341,14 +485,19 @@ func (op *Reporting) updateAWSBillingPartitions(logger log.FieldLogger, partitio
 		return nil
 	}
 
-	// Compare the manifests list and existing partitions, deleting stale
-	// partitions and creating missing partitions
-	currentPartitions := prestoTable.Status.Partitions
 	desiredPartitions, err := getDesiredPartitions(source.Bucket, manifests)
 	if err != nil {
 		return err
 	}
+	return op.updateBillingPeriodPartitions(logger, partitionsGauge, prestoTable, desiredPartitions)
+}
 
+// updateBillingPeriodPartitions compares the desired billing period
+// partitions with the PrestoTable's existing partitions, deleting stale
+// partitions and creating missing partitions.
+func (op *Reporting) updateBillingPeriodPartitions(logger log.FieldLogger, partitionsGauge prometheus.Gauge, prestoTable *cbTypes.PrestoTable, desiredPartitions []cbTypes.TablePartition) error {
+	var err error
+	currentPartitions := prestoTable.Status.Partitions
 	changes := getPartitionChanges(currentPartitions, desiredPartitions)
 
 	currentPartitionsList := make([]string, len(currentPartitions))
@@ -388,7 +537,7 @@ func (op *Reporting) updateAWSBillingPartitions(logger log.FieldLogger, partitio
 		start := p.PartitionSpec["start"]
 		end := p.PartitionSpec["end"]
 		logger.Warnf("Deleting partition from presto table %q with range %s-%s", tableName, start, end)
-		err = op.awsTablePartitionManager.DropPartition(tableName, start, end)
+		err = op.awsTablePartitionManager.DropPartition(op.workCtx, tableName, start, end)
 		if err != nil {
 			logger.WithError(err).Errorf("failed to drop partition in table %s for range %s-%s", tableName, start, end)
 			return err
@@ -401,7 +550,7 @@ func (op *Reporting) updateAWSBillingPartitions(logger log.FieldLogger, partitio
 		end := p.PartitionSpec["end"]
 		// This partition doesn't exist in hive. Create it.
 		logger.Debugf("Adding partition to presto table %q with range %s-%s", tableName, start, end)
-		err = op.awsTablePartitionManager.AddPartition(tableName, start, end, p.Location)
+		err = op.awsTablePartitionManager.AddPartition(op.workCtx, tableName, start, end, p.Location)
 		if err != nil {
 			logger.WithError(err).Errorf("failed to add partition in table %s for range %s-%s at location %s", prestoTable.Status.Parameters.Name, p.PartitionSpec["start"], p.PartitionSpec["end"], p.Location)
 			return err
@@ -505,7 +654,7 @@ func (op *Reporting) updateDataSourceTableName(logger log.FieldLogger, dataSourc
 func (op *Reporting) addReportDataSourceFinalizer(ds *cbTypes.ReportDataSource) (*cbTypes.ReportDataSource, error) {
 	ds.Finalizers = append(ds.Finalizers, reportDataSourceFinalizer)
 	newReportDataSource, err := op.meteringClient.MeteringV1alpha1().ReportDataSources(ds.Namespace).Update(ds)
-	logger := op.logger.WithField("ReportDataSource", ds.Name)
+	logger := newObjectLogger(op.logger, "ReportDataSource", ds)
 	if err != nil {
 		logger.WithError(err).Errorf("error adding %s finalizer to ReportDataSource: %s/%s", reportDataSourceFinalizer, ds.Namespace, ds.Name)
 		return nil, err
@@ -514,13 +663,65 @@ func (op *Reporting) addReportDataSourceFinalizer(ds *cbTypes.ReportDataSource)
 	return newReportDataSource, nil
 }
 
+// importerKey returns the key of the ReportDataSource's PrometheusImporter
+// within op.importers.
+func importerKey(dataSource *cbTypes.ReportDataSource) string {
+	return dataSource.Namespace + "/" + dataSource.Name
+}
+
+// cleanupReportDataSource stops any collection for the ReportDataSource and
+// handles it's table according to the ReportDataSource's deletionPolicy.
+func (op *Reporting) cleanupReportDataSource(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	op.importersMu.Lock()
+	delete(op.importers, importerKey(dataSource))
+	op.importersMu.Unlock()
+	prometheusReportDatasourceImportLagCollector.delete(dataSource)
+	op.stopAWSBillingNotificationWatcher(dataSource)
+	op.stopKafkaConsumer(dataSource)
+
+	tableName := dataSource.Status.TableName
+	if tableName == "" {
+		logger.Debugf("ReportDataSource has no table, nothing to cleanup")
+		return nil
+	}
+	logger = logger.WithField("tableName", tableName)
+
+	switch dataSource.Spec.DeletionPolicy {
+	case cbTypes.ReportDataSourceDeletionPolicyDelete:
+		// AWSBilling, AzureCostManagement, File and S3Inventory tables point
+		// at a bucket or container we don't own, so we only drop the table and leave the
+		// data alone.
+		if dataSource.Spec.Promsum != nil || dataSource.Spec.SQL != nil || dataSource.Spec.Kafka != nil || dataSource.Spec.NamespaceMetadata != nil {
+			logger.Infof("marking table %s as managed to purge its data when dropped", tableName)
+			err := op.tableManager.SetTableExternal(op.workCtx, tableName, false)
+			if err != nil {
+				return reasonErrorf(err, cbutil.HiveErrorReason, "unable to mark table %s as managed for ReportDataSource %s: %v", tableName, dataSource.Name, err)
+			}
+		}
+		logger.Infof("dropping table %s", tableName)
+		err := op.tableManager.DropTable(op.workCtx, tableName, true)
+		if err != nil {
+			return reasonErrorf(err, cbutil.HiveErrorReason, "unable to drop table %s for ReportDataSource %s: %v", tableName, dataSource.Name, err)
+		}
+		logger.Infof("successfully dropped table %s", tableName)
+	default:
+		logger.Warnf("unknown deletionPolicy %q, retaining table %s", dataSource.Spec.DeletionPolicy, tableName)
+		fallthrough
+	case cbTypes.ReportDataSourceDeletionPolicyRetain, "":
+		// The PrestoTable is owned by the ReportDataSource and would drop the
+		// table when garbage collected, so orphan it to retain the table.
+		return op.orphanPrestoTable(logger, dataSource.Namespace, reportingutil.PrestoTableResourceNameFromKind("ReportDataSource", dataSource.Name), dataSource.UID)
+	}
+	return nil
+}
+
 func (op *Reporting) removeReportDataSourceFinalizer(ds *cbTypes.ReportDataSource) (*cbTypes.ReportDataSource, error) {
 	if !slice.ContainsString(ds.ObjectMeta.Finalizers, reportDataSourceFinalizer, nil) {
 		return ds, nil
 	}
 	ds.Finalizers = slice.RemoveString(ds.Finalizers, reportDataSourceFinalizer, nil)
 	newReportDataSource, err := op.meteringClient.MeteringV1alpha1().ReportDataSources(ds.Namespace).Update(ds)
-	logger := op.logger.WithField("ReportDataSource", ds.Name)
+	logger := newObjectLogger(op.logger, "ReportDataSource", ds)
 	if err != nil {
 		logger.WithError(err).Errorf("error removing %s finalizer from ReportDataSource: %s/%s", reportDataSourceFinalizer, ds.Namespace, ds.Name)
 		return nil, err
@@ -0,0 +1,120 @@
+package operator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/fake"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
+)
+
+func TestSyncDeletedReportDataSource(t *testing.T) {
+	deleted := metav1.NewTime(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC))
+	promsum := cbTypes.ReportDataSourceSpec{Promsum: &cbTypes.PrometheusMetricsDataSource{Query: "pod-cpu"}}
+	awsBilling := cbTypes.ReportDataSourceSpec{AWSBilling: &cbTypes.AWSBillingDataSource{}}
+	for _, tt := range []struct {
+		name       string
+		spec       cbTypes.ReportDataSourceSpec
+		policy     cbTypes.ReportDataSourceDeletionPolicy
+		finalizers []string
+		dropped    []string
+		managed    []string
+		orphaned   bool
+	}{
+		{
+			name:       "Delete drops the table and purges the data of tables we own",
+			spec:       promsum,
+			policy:     cbTypes.ReportDataSourceDeletionPolicyDelete,
+			finalizers: []string{"example.com/other", reportDataSourceFinalizer},
+			dropped:    []string{"datasource_default_cpu"},
+			managed:    []string{"datasource_default_cpu"},
+		},
+		{
+			name:       "Delete only drops the table of data we don't own",
+			spec:       awsBilling,
+			policy:     cbTypes.ReportDataSourceDeletionPolicyDelete,
+			finalizers: []string{"example.com/other", reportDataSourceFinalizer},
+			dropped:    []string{"datasource_default_cpu"},
+		},
+		{
+			name:       "Retain orphans the PrestoTable",
+			spec:       promsum,
+			policy:     cbTypes.ReportDataSourceDeletionPolicyRetain,
+			finalizers: []string{"example.com/other", reportDataSourceFinalizer},
+			orphaned:   true,
+		},
+		{
+			name:       "no deletionPolicy retains the table",
+			spec:       promsum,
+			finalizers: []string{"example.com/other", reportDataSourceFinalizer},
+			orphaned:   true,
+		},
+		{
+			name:       "an unknown deletionPolicy retains the table",
+			spec:       promsum,
+			policy:     "Purge",
+			finalizers: []string{"example.com/other", reportDataSourceFinalizer},
+			orphaned:   true,
+		},
+		{
+			name:       "without the finalizer, nothing is cleaned up",
+			spec:       promsum,
+			policy:     cbTypes.ReportDataSourceDeletionPolicyDelete,
+			finalizers: []string{"example.com/other"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dataSource := &cbTypes.ReportDataSource{
+				ObjectMeta: metav1.ObjectMeta{Name: "cpu", Namespace: "default", UID: "datasource-uid", DeletionTimestamp: &deleted, Finalizers: tt.finalizers},
+				Spec:       tt.spec,
+				Status:     cbTypes.ReportDataSourceStatus{TableName: "datasource_default_cpu"},
+			}
+			dataSource.Spec.DeletionPolicy = tt.policy
+			otherOwner := metav1.OwnerReference{Kind: "Report", Name: "other", UID: "report-uid"}
+			prestoTable := &cbTypes.PrestoTable{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            reportingutil.PrestoTableResourceNameFromKind("ReportDataSource", "cpu"),
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{otherOwner, {Kind: "ReportDataSource", Name: "cpu", UID: "datasource-uid"}},
+				},
+			}
+			client := fake.NewSimpleClientset(dataSource.DeepCopy(), prestoTable.DeepCopy())
+			dataSources := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+			require.NoError(t, dataSources.Add(dataSource))
+			prestoTables := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+			require.NoError(t, prestoTables.Add(prestoTable))
+			tableManager := &fakeTableManager{}
+			op := &Reporting{
+				logger:                 logrus.New(),
+				workCtx:                context.Background(),
+				meteringClient:         client,
+				reportDataSourceLister: listers.NewReportDataSourceLister(dataSources),
+				prestoTableLister:      listers.NewPrestoTableLister(prestoTables),
+				tableManager:           tableManager,
+			}
+
+			require.NoError(t, op.syncReportDataSource(op.logger, "default/cpu"))
+			assert.Equal(t, tt.dropped, tableManager.droppedTables)
+			assert.Equal(t, tt.managed, tableManager.managedTables)
+			updatedTable, err := client.MeteringV1alpha1().PrestoTables("default").Get(prestoTable.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+			if tt.orphaned {
+				assert.Equal(t, []metav1.OwnerReference{otherOwner}, updatedTable.OwnerReferences, "expected only the ReportDataSource's ownerReference to be removed")
+			} else {
+				assert.Equal(t, prestoTable.OwnerReferences, updatedTable.OwnerReferences)
+			}
+			updated, err := client.MeteringV1alpha1().ReportDataSources("default").Get("cpu", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"example.com/other"}, updated.Finalizers, "expected only reporting-operator's finalizer to be removed")
+		})
+	}
+}
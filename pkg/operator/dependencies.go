@@ -0,0 +1,179 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	prom "github.com/prometheus/client_golang/api/prometheus/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/operator-framework/operator-metering/pkg/db"
+)
+
+const (
+	// The dependencies reported by the /healthz and /readyz endpoints.
+	DependencyInformers  = "informers"
+	DependencyPresto     = "presto"
+	DependencyHive       = "hive"
+	DependencyPrometheus = "prometheus"
+
+	// dependencyCheckCacheDuration is how long the result of checking a
+	// dependency is reused for, so frequent probes don't query them every
+	// time.
+	dependencyCheckCacheDuration = 10 * time.Second
+	// prometheusCheckTimeout is how long checking Prometheus can take
+	// before it's considered unavailable.
+	prometheusCheckTimeout = 10 * time.Second
+)
+
+var errDependencyNotChecked = errors.New("waiting for reporting-operator to initialize")
+
+// DependencyStatus is the result of checking one of the dependencies of
+// reporting-operator.
+type DependencyStatus struct {
+	Name    string `json:"name"`
+	Ready   bool   `json:"ready"`
+	Message string `json:"message,omitempty"`
+	// Pending is true if the dependency hasn't been set up yet, because
+	// reporting-operator is still starting.
+	Pending bool `json:"pending,omitempty"`
+	// Liveness is true if the dependency failing fails /healthz as well as
+	// /readyz, because restarting reporting-operator may fix it.
+	Liveness bool `json:"liveness,omitempty"`
+}
+
+type dependencyCheck struct {
+	name     string
+	liveness bool
+
+	mu        sync.Mutex
+	check     func() error
+	checkTime time.Time
+	err       error
+}
+
+// dependencyChecker checks the dependencies of reporting-operator, which
+// are added when it starts, and checked once they're set up.
+type dependencyChecker struct {
+	clock clock.Clock
+
+	mu     sync.Mutex
+	checks []*dependencyCheck
+}
+
+func newDependencyChecker(clock clock.Clock) *dependencyChecker {
+	return &dependencyChecker{clock: clock}
+}
+
+// add adds a dependency, which is reported as pending until its check is
+// set.
+func (c *dependencyChecker) add(name string, liveness bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, &dependencyCheck{name: name, liveness: liveness})
+}
+
+// setCheck sets the function checking the named dependency, which returns
+// an error describing why the dependency isn't ready.
+func (c *dependencyChecker) setCheck(name string, check func() error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, dep := range c.checks {
+		if dep.name == name {
+			dep.mu.Lock()
+			dep.check = check
+			dep.checkTime = time.Time{}
+			dep.mu.Unlock()
+			return
+		}
+	}
+}
+
+// statuses checks each dependency concurrently, re-using the result of the
+// previous check for up to dependencyCheckCacheDuration.
+func (c *dependencyChecker) statuses() []DependencyStatus {
+	c.mu.Lock()
+	checks := make([]*dependencyCheck, len(c.checks))
+	copy(checks, c.checks)
+	c.mu.Unlock()
+
+	statuses := make([]DependencyStatus, len(checks))
+	var wg sync.WaitGroup
+	for i, dep := range checks {
+		wg.Add(1)
+		go func(i int, dep *dependencyCheck) {
+			defer wg.Done()
+			statuses[i] = dep.status(c.clock.Now())
+		}(i, dep)
+	}
+	wg.Wait()
+	return statuses
+}
+
+func (dep *dependencyCheck) status(now time.Time) DependencyStatus {
+	dep.mu.Lock()
+	defer dep.mu.Unlock()
+	status := DependencyStatus{Name: dep.name, Liveness: dep.liveness}
+	if dep.check == nil {
+		status.Pending = true
+		status.Message = errDependencyNotChecked.Error()
+		return status
+	}
+	if dep.checkTime.IsZero() || now.Sub(dep.checkTime) >= dependencyCheckCacheDuration {
+		dep.err = dep.check()
+		dep.checkTime = now
+	}
+	status.Ready = dep.err == nil
+	if dep.err != nil {
+		status.Message = dep.err.Error()
+	}
+	return status
+}
+
+// checkPrometheus checks Prometheus can be queried, using a query which
+// doesn't touch any series.
+func checkPrometheus(promConn prom.API, now time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), prometheusCheckTimeout)
+	defer cancel()
+	if _, err := promConn.Query(ctx, "vector(1)", now); err != nil {
+		return fmt.Errorf("cannot query Prometheus: %v", err)
+	}
+	return nil
+}
+
+// checkHive checks Hive can be queried.
+func checkHive(hiveQueryer db.Queryer) error {
+	rows, err := hiveQueryer.Query("SHOW DATABASES")
+	if err != nil {
+		return fmt.Errorf("cannot query Hive: %v", err)
+	}
+	return rows.Close()
+}
+
+// apiRouterHandler serves requests using the API router once it's set, so
+// the HTTP server can be started before the API is set up.
+type apiRouterHandler struct {
+	mu     sync.RWMutex
+	router http.Handler
+}
+
+func (h *apiRouterHandler) set(router http.Handler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.router = router
+}
+
+func (h *apiRouterHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	router := h.router
+	h.mu.RUnlock()
+	if router == nil {
+		http.NotFound(w, r)
+		return
+	}
+	router.ServeHTTP(w, r)
+}
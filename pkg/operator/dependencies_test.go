@@ -0,0 +1,83 @@
+package operator
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestDependencyHandlers(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC))
+	op := &Reporting{
+		logger:       logrus.New(),
+		rand:         testRand,
+		dependencies: newDependencyChecker(fakeClock),
+	}
+	op.dependencies.add(DependencyInformers, false)
+	op.dependencies.add(DependencyPresto, true)
+	op.dependencies.add(DependencyPrometheus, false)
+
+	do := func(handler http.HandlerFunc, path string) (int, DependenciesResponse) {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest("GET", path, nil))
+		var resp DependenciesResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return w.Code, resp
+	}
+
+	// while starting, reporting-operator isn't ready, but is healthy
+	code, resp := do(op.readyzHandler, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "not ready", resp.Status)
+	require.Len(t, resp.Dependencies, 3)
+	assert.Equal(t, DependencyStatus{Name: DependencyInformers, Message: errDependencyNotChecked.Error(), Pending: true}, resp.Dependencies[0])
+	code, _ = do(op.healthzHandler, "/healthz")
+	assert.Equal(t, http.StatusOK, code)
+
+	prestoChecks := 0
+	var prestoErr error
+	op.dependencies.setCheck(DependencyInformers, func() error { return nil })
+	op.dependencies.setCheck(DependencyPresto, func() error {
+		prestoChecks++
+		return prestoErr
+	})
+	op.dependencies.setCheck(DependencyPrometheus, func() error { return errors.New("cannot query Prometheus") })
+
+	// Prometheus being unavailable makes reporting-operator not ready, but
+	// restarting wouldn't fix it
+	code, resp = do(op.readyzHandler, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, []DependencyStatus{
+		{Name: DependencyInformers, Ready: true},
+		{Name: DependencyPresto, Ready: true, Liveness: true},
+		{Name: DependencyPrometheus, Message: "cannot query Prometheus"},
+	}, resp.Dependencies)
+	code, resp = do(op.healthzHandler, "/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", resp.Status)
+	assert.Equal(t, 1, prestoChecks, "expected the result of checking Presto to be reused")
+
+	op.dependencies.setCheck(DependencyPrometheus, func() error { return nil })
+	code, resp = do(op.readyzHandler, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", resp.Status)
+
+	prestoErr = errors.New("cannot read from PrestoDB")
+	code, _ = do(op.healthzHandler, "/healthz")
+	assert.Equal(t, http.StatusOK, code, "expected the cached Presto check to be used")
+
+	fakeClock.Step(dependencyCheckCacheDuration)
+	code, resp = do(op.healthzHandler, "/healthz")
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.Equal(t, "not healthy", resp.Status)
+	assert.Equal(t, DependencyStatus{Name: DependencyPresto, Message: "cannot read from PrestoDB", Liveness: true}, resp.Dependencies[1])
+	assert.Equal(t, 2, prestoChecks)
+}
@@ -0,0 +1,125 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+)
+
+var syncErrorsCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "metering",
+		Name:      "sync_errors_total",
+		Help:      "Number of errors syncing resources by kind and failure reason.",
+	},
+	[]string{"kind", "reason"},
+)
+
+func init() {
+	prometheus.MustRegister(syncErrorsCounter)
+}
+
+// reasonError associates a failure reason from the cbutil package with an
+// error.
+type reasonError struct {
+	reason string
+	err    error
+}
+
+func (e *reasonError) Error() string {
+	return e.err.Error()
+}
+
+// reasonErrorf returns an error formatted like fmt.Errorf which has the
+// failure reason of cause, or defaultReason if cause's reason is unknown.
+func reasonErrorf(cause error, defaultReason string, format string, args ...interface{}) error {
+	return &reasonError{reason: classifyError(cause, defaultReason), err: fmt.Errorf(format, args...)}
+}
+
+// classifyError returns the failure reason of err, or defaultReason if err's
+// reason is unknown.
+func classifyError(err error, defaultReason string) string {
+	reason := errorReason(err)
+	if reason == cbutil.UnknownErrorReason {
+		return defaultReason
+	}
+	return reason
+}
+
+// errorReason classifies err into one of the failure reasons in the cbutil
+// package.
+func errorReason(err error) string {
+	if err == nil {
+		return cbutil.UnknownErrorReason
+	}
+	switch e := err.(type) {
+	case *reasonError:
+		return e.reason
+	case *prestostore.PrometheusQueryError:
+		return cbutil.PrometheusErrorReason
+	case *prestostore.PrestoStoreError:
+		return cbutil.PrestoErrorReason
+	case net.Error:
+		if e.Timeout() {
+			return cbutil.TimeoutReason
+		}
+	}
+	switch {
+	case err == context.DeadlineExceeded, apierrors.IsTimeout(err), apierrors.IsServerTimeout(err):
+		return cbutil.TimeoutReason
+	case reporting.IsExecutionLimitExceeded(err):
+		return cbutil.ExecutionLimitExceededReason
+	case reporting.IsDependencyMissing(err), apierrors.IsNotFound(err):
+		return cbutil.DependencyMissingReason
+	case apierrors.IsInvalid(err):
+		return cbutil.ValidationErrorReason
+	}
+	return cbutil.UnknownErrorReason
+}
+
+// recordSyncError records an error syncing the resource identified by key in
+// our metrics, and as an Event on the resource.
+func (op *Reporting) recordSyncError(objType, key string, err error) {
+	reason := errorReason(err)
+	syncErrorsCounter.WithLabelValues(objType, reason).Inc()
+
+	// eventRecorder is setup once we've started running
+	if op.eventRecorder == nil {
+		return
+	}
+	obj, getErr := op.getObjectForKey(objType, key)
+	if getErr != nil || obj == nil {
+		return
+	}
+	op.eventRecorder.Event(obj, v1.EventTypeWarning, reason, err.Error())
+}
+
+func (op *Reporting) getObjectForKey(objType, key string) (runtime.Object, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil, err
+	}
+	switch objType {
+	case "Report":
+		return op.reportLister.Reports(namespace).Get(name)
+	case "ScheduledReport":
+		return op.scheduledReportLister.ScheduledReports(namespace).Get(name)
+	case "ReportDataSource":
+		return op.reportDataSourceLister.ReportDataSources(namespace).Get(name)
+	case "ReportGenerationQuery":
+		return op.reportGenerationQueryLister.ReportGenerationQueries(namespace).Get(name)
+	case "PrestoTable":
+		return op.prestoTableLister.PrestoTables(namespace).Get(name)
+	}
+	return nil, nil
+}
@@ -0,0 +1,52 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+)
+
+func TestErrorReason(t *testing.T) {
+	notFoundErr := apierrors.NewNotFound(schema.GroupResource{Resource: "reportdatasources"}, "foo")
+	tests := map[string]struct {
+		err            error
+		expectedReason string
+	}{
+		"unclassified error": {
+			err:            fmt.Errorf("some error"),
+			expectedReason: cbutil.UnknownErrorReason,
+		},
+		"not found error": {
+			err:            notFoundErr,
+			expectedReason: cbutil.DependencyMissingReason,
+		},
+		"context deadline exceeded": {
+			err:            context.DeadlineExceeded,
+			expectedReason: cbutil.TimeoutReason,
+		},
+		"reason error uses default reason for unclassified cause": {
+			err:            reasonErrorf(fmt.Errorf("some error"), cbutil.HiveErrorReason, "wrapped"),
+			expectedReason: cbutil.HiveErrorReason,
+		},
+		"reason error preserves reason of cause": {
+			err:            reasonErrorf(notFoundErr, cbutil.ValidationErrorReason, "wrapped: %v", notFoundErr),
+			expectedReason: cbutil.DependencyMissingReason,
+		},
+		"nested reason errors preserve the innermost reason": {
+			err:            reasonErrorf(reasonErrorf(nil, cbutil.StorageErrorReason, "inner"), cbutil.HiveErrorReason, "outer"),
+			expectedReason: cbutil.StorageErrorReason,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expectedReason, errorReason(test.err))
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package operator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
+)
+
+func (op *Reporting) handleFileDataSource(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	source := dataSource.Spec.File
+	if err := validateFileDataSource(source); err != nil {
+		return reasonErrorf(err, cbutil.ValidationErrorReason, "ReportDataSource %q: improperly configured datasource, %v", dataSource.Name, err)
+	}
+
+	if dataSource.Status.TableName != "" {
+		logger.Infof("existing File ReportDataSource discovered, tableName: %s", dataSource.Status.TableName)
+	} else {
+		logger.Infof("new File ReportDataSource discovered")
+		tableName := op.dataSourceTableName(dataSource)
+		logger.Debugf("creating File DataSource table %s pointing to %s", tableName, source.Location)
+		err := op.createFileTable(logger, dataSource, tableName, source)
+		if err != nil {
+			return err
+		}
+
+		logger.Debugf("successfully created File DataSource table %s pointing to %s", tableName, source.Location)
+		dataSource, err = op.updateDataSourceTableName(logger, dataSource, tableName)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(source.Partitions) == 0 {
+		return nil
+	}
+
+	logger.Infof("discovering new partitions for table %s", dataSource.Status.TableName)
+	err := op.tableManager.RepairTable(op.workCtx, dataSource.Status.TableName)
+	if err != nil {
+		return reasonErrorf(err, cbutil.HiveErrorReason, "unable to update partitions of table %s for ReportDataSource %s: %v", dataSource.Status.TableName, dataSource.Name, err)
+	}
+
+	nextUpdate := op.clock.Now().Add(partitionUpdateInterval).UTC()
+	logger.Infof("queuing File ReportDataSource %s to update partitions again in %s at %s", dataSource.Name, partitionUpdateInterval, nextUpdate)
+	op.enqueueReportDataSourceAfter(dataSource, partitionUpdateInterval)
+	return nil
+}
+
+func validateFileDataSource(source *cbTypes.FileDataSource) error {
+	if source.Location == "" {
+		return fmt.Errorf("location is empty")
+	}
+	switch source.Format {
+	case cbTypes.FileDataSourceFormatCSV:
+		if csv := source.CSV; csv != nil {
+			for field, value := range map[string]string{"separator": csv.Separator, "quote": csv.Quote, "escape": csv.Escape} {
+				if len([]rune(value)) > 1 {
+					return fmt.Errorf("csv.%s must be a single character", field)
+				}
+			}
+			if csv.SkipHeaderLines < 0 {
+				return fmt.Errorf("csv.skipHeaderLines cannot be negative")
+			}
+		}
+	case cbTypes.FileDataSourceFormatJSON:
+		if source.CSV != nil {
+			return fmt.Errorf("csv cannot be set when format is %s", source.Format)
+		}
+	default:
+		return fmt.Errorf("format must be %s or %s, got %q", cbTypes.FileDataSourceFormatCSV, cbTypes.FileDataSourceFormatJSON, source.Format)
+	}
+	if len(source.Columns) == 0 {
+		return fmt.Errorf("columns is empty")
+	}
+
+	seen := make(map[string]struct{})
+	for _, column := range append(append([]cbTypes.FileDataSourceColumn{}, source.Columns...), source.Partitions...) {
+		if column.Name == "" {
+			return fmt.Errorf("column names cannot be empty")
+		}
+		name := strings.ToLower(column.Name)
+		if _, exists := seen[name]; exists {
+			return fmt.Errorf("multiple columns named %s", column.Name)
+		}
+		seen[name] = struct{}{}
+	}
+	// OpenCSVSerde reads every column as a string, the partition columns
+	// come from directory names and can be any type.
+	if source.Format == cbTypes.FileDataSourceFormatCSV {
+		for _, column := range source.Columns {
+			if column.Type != "" && !strings.EqualFold(column.Type, "string") {
+				return fmt.Errorf("column %s has type %s, but columns of CSV files must be strings", column.Name, column.Type)
+			}
+		}
+	}
+	return nil
+}
+
+// createFileTable instantiates a new external Hive table for the files in
+// a File ReportDataSource's location.
+func (op *Reporting) createFileTable(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource, tableName string, source *cbTypes.FileDataSource) error {
+	params := hive.TableParameters{
+		Name:         tableName,
+		Columns:      fileDataSourceHiveColumns(source.Columns),
+		Partitions:   fileDataSourceHiveColumns(source.Partitions),
+		IgnoreExists: true,
+	}
+	properties := hive.TableProperties{
+		Location:   source.Location,
+		FileFormat: "textfile",
+		External:   true,
+	}
+
+	switch source.Format {
+	case cbTypes.FileDataSourceFormatCSV:
+		csv := source.CSV
+		if csv == nil {
+			csv = &cbTypes.CSVFormatConfig{}
+		}
+		properties.SerdeFormat = reportingutil.CSVHiveSerde
+		properties.SerdeRowProperties = map[string]string{
+			"separatorChar": stringOrDefault(csv.Separator, ","),
+			"quoteChar":     stringOrDefault(csv.Quote, `"`),
+			"escapeChar":    stringOrDefault(csv.Escape, "\\"),
+		}
+		if csv.SkipHeaderLines > 0 {
+			properties.TableProperties = map[string]string{
+				"skip.header.line.count": strconv.Itoa(csv.SkipHeaderLines),
+			}
+		}
+	case cbTypes.FileDataSourceFormatJSON:
+		properties.SerdeFormat = reportingutil.JSONHiveSerde
+	}
+	// use the location as is, rather than appending the table name, since
+	// the files are stored directly within it.
+	return op.createTableAndCR(op.workCtx, logger, dataSource, cbTypes.SchemeGroupVersion.WithKind("ReportDataSource"), params, properties)
+}
+
+func fileDataSourceHiveColumns(columns []cbTypes.FileDataSourceColumn) []hive.Column {
+	if len(columns) == 0 {
+		return nil
+	}
+	hiveColumns := make([]hive.Column, len(columns))
+	for i, column := range columns {
+		hiveColumns[i] = hive.Column{
+			Name: column.Name,
+			Type: stringOrDefault(column.Type, "string"),
+		}
+	}
+	return hiveColumns
+}
+
+func stringOrDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,258 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/util/workqueue"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/fake"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
+)
+
+// fakeDelayingQueue records the delay of the keys added using AddAfter.
+// Calling any other method panics.
+type fakeDelayingQueue struct {
+	workqueue.RateLimitingInterface
+	addedAfter map[string]time.Duration
+}
+
+func (q *fakeDelayingQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.addedAfter[item.(string)] = duration
+}
+
+func TestValidateFileDataSource(t *testing.T) {
+	columns := []cbTypes.FileDataSourceColumn{{Name: "account"}, {Name: "cost", Type: "string"}}
+	tests := map[string]struct {
+		source      cbTypes.FileDataSource
+		expectedErr bool
+	}{
+		"CSV": {
+			source: cbTypes.FileDataSource{Location: "s3a://bucket/billing/", Format: cbTypes.FileDataSourceFormatCSV, Columns: columns},
+		},
+		"CSV with options and partitions": {
+			source: cbTypes.FileDataSource{
+				Location:   "s3a://bucket/billing/",
+				Format:     cbTypes.FileDataSourceFormatCSV,
+				CSV:        &cbTypes.CSVFormatConfig{Separator: "|", Quote: "'", Escape: "\\", SkipHeaderLines: 1},
+				Columns:    columns,
+				Partitions: []cbTypes.FileDataSourceColumn{{Name: "dt", Type: "date"}},
+			},
+		},
+		"JSON with typed columns": {
+			source: cbTypes.FileDataSource{Location: "s3a://bucket/billing/", Format: cbTypes.FileDataSourceFormatJSON, Columns: []cbTypes.FileDataSourceColumn{{Name: "cost", Type: "double"}}},
+		},
+		"missing location": {
+			source:      cbTypes.FileDataSource{Format: cbTypes.FileDataSourceFormatCSV, Columns: columns},
+			expectedErr: true,
+		},
+		"unknown format": {
+			source:      cbTypes.FileDataSource{Location: "s3a://bucket/billing/", Format: "Parquet", Columns: columns},
+			expectedErr: true,
+		},
+		"multiple character separator": {
+			source:      cbTypes.FileDataSource{Location: "s3a://bucket/billing/", Format: cbTypes.FileDataSourceFormatCSV, CSV: &cbTypes.CSVFormatConfig{Separator: "||"}, Columns: columns},
+			expectedErr: true,
+		},
+		"negative skipHeaderLines": {
+			source:      cbTypes.FileDataSource{Location: "s3a://bucket/billing/", Format: cbTypes.FileDataSourceFormatCSV, CSV: &cbTypes.CSVFormatConfig{SkipHeaderLines: -1}, Columns: columns},
+			expectedErr: true,
+		},
+		"CSV options with JSON": {
+			source:      cbTypes.FileDataSource{Location: "s3a://bucket/billing/", Format: cbTypes.FileDataSourceFormatJSON, CSV: &cbTypes.CSVFormatConfig{}, Columns: columns},
+			expectedErr: true,
+		},
+		"no columns": {
+			source:      cbTypes.FileDataSource{Location: "s3a://bucket/billing/", Format: cbTypes.FileDataSourceFormatCSV},
+			expectedErr: true,
+		},
+		"unnamed column": {
+			source:      cbTypes.FileDataSource{Location: "s3a://bucket/billing/", Format: cbTypes.FileDataSourceFormatCSV, Columns: []cbTypes.FileDataSourceColumn{{Type: "string"}}},
+			expectedErr: true,
+		},
+		"partition with the same name as a column, ignoring case": {
+			source:      cbTypes.FileDataSource{Location: "s3a://bucket/billing/", Format: cbTypes.FileDataSourceFormatCSV, Columns: columns, Partitions: []cbTypes.FileDataSourceColumn{{Name: "Account"}}},
+			expectedErr: true,
+		},
+		"typed CSV column": {
+			source:      cbTypes.FileDataSource{Location: "s3a://bucket/billing/", Format: cbTypes.FileDataSourceFormatCSV, Columns: []cbTypes.FileDataSourceColumn{{Name: "cost", Type: "double"}}},
+			expectedErr: true,
+		},
+	}
+	for name, tt := range tests {
+		err := validateFileDataSource(&tt.source)
+		if tt.expectedErr {
+			assert.Error(t, err, name)
+		} else {
+			assert.NoError(t, err, name)
+		}
+	}
+}
+
+func TestHandleFileDataSource(t *testing.T) {
+	tableName := reportingutil.DataSourceTableName("", "billing")
+	for _, tt := range []struct {
+		name               string
+		source             cbTypes.FileDataSource
+		existingTableName  string
+		repairErr          error
+		expectedTables     []hive.TableParameters
+		expectedProperties []hive.TableProperties
+		expectedRepaired   []string
+		expectedQueued     map[string]time.Duration
+		expectedErr        bool
+	}{
+		{
+			name: "CSV files are read using the CSV serde with it's defaults",
+			source: cbTypes.FileDataSource{
+				Location: "s3a://bucket/billing/",
+				Format:   cbTypes.FileDataSourceFormatCSV,
+				Columns:  []cbTypes.FileDataSourceColumn{{Name: "account"}, {Name: "cost", Type: "string"}},
+			},
+			expectedTables: []hive.TableParameters{{
+				Name:         tableName,
+				Columns:      []hive.Column{{Name: "account", Type: "string"}, {Name: "cost", Type: "string"}},
+				IgnoreExists: true,
+			}},
+			expectedProperties: []hive.TableProperties{{
+				Location:           "s3a://bucket/billing/",
+				FileFormat:         "textfile",
+				External:           true,
+				SerdeFormat:        reportingutil.CSVHiveSerde,
+				SerdeRowProperties: map[string]string{"separatorChar": ",", "quoteChar": `"`, "escapeChar": "\\"},
+			}},
+			expectedQueued: map[string]time.Duration{},
+		},
+		{
+			name: "CSV options and partitions",
+			source: cbTypes.FileDataSource{
+				Location:   "s3a://bucket/billing/",
+				Format:     cbTypes.FileDataSourceFormatCSV,
+				CSV:        &cbTypes.CSVFormatConfig{Separator: "|", SkipHeaderLines: 1},
+				Columns:    []cbTypes.FileDataSourceColumn{{Name: "account"}},
+				Partitions: []cbTypes.FileDataSourceColumn{{Name: "dt", Type: "date"}, {Name: "region"}},
+			},
+			expectedTables: []hive.TableParameters{{
+				Name:         tableName,
+				Columns:      []hive.Column{{Name: "account", Type: "string"}},
+				Partitions:   []hive.Column{{Name: "dt", Type: "date"}, {Name: "region", Type: "string"}},
+				IgnoreExists: true,
+			}},
+			expectedProperties: []hive.TableProperties{{
+				Location:           "s3a://bucket/billing/",
+				FileFormat:         "textfile",
+				External:           true,
+				SerdeFormat:        reportingutil.CSVHiveSerde,
+				SerdeRowProperties: map[string]string{"separatorChar": "|", "quoteChar": `"`, "escapeChar": "\\"},
+				TableProperties:    map[string]string{"skip.header.line.count": "1"},
+			}},
+			expectedRepaired: []string{tableName},
+			expectedQueued:   map[string]time.Duration{"default/billing": partitionUpdateInterval},
+		},
+		{
+			name: "JSON files are read using the JSON serde with the column types",
+			source: cbTypes.FileDataSource{
+				Location: "s3a://bucket/billing/",
+				Format:   cbTypes.FileDataSourceFormatJSON,
+				Columns:  []cbTypes.FileDataSourceColumn{{Name: "account"}, {Name: "cost", Type: "double"}},
+			},
+			expectedTables: []hive.TableParameters{{
+				Name:         tableName,
+				Columns:      []hive.Column{{Name: "account", Type: "string"}, {Name: "cost", Type: "double"}},
+				IgnoreExists: true,
+			}},
+			expectedProperties: []hive.TableProperties{{
+				Location:    "s3a://bucket/billing/",
+				FileFormat:  "textfile",
+				External:    true,
+				SerdeFormat: reportingutil.JSONHiveSerde,
+			}},
+			expectedQueued: map[string]time.Duration{},
+		},
+		{
+			name: "partitions of an existing table are refreshed",
+			source: cbTypes.FileDataSource{
+				Location:   "s3a://bucket/billing/",
+				Format:     cbTypes.FileDataSourceFormatJSON,
+				Columns:    []cbTypes.FileDataSourceColumn{{Name: "account"}},
+				Partitions: []cbTypes.FileDataSourceColumn{{Name: "dt"}},
+			},
+			existingTableName: "existing_billing",
+			expectedRepaired:  []string{"existing_billing"},
+			expectedQueued:    map[string]time.Duration{"default/billing": partitionUpdateInterval},
+		},
+		{
+			name: "partitions which can't be refreshed are retried",
+			source: cbTypes.FileDataSource{
+				Location:   "s3a://bucket/billing/",
+				Format:     cbTypes.FileDataSourceFormatJSON,
+				Columns:    []cbTypes.FileDataSourceColumn{{Name: "account"}},
+				Partitions: []cbTypes.FileDataSourceColumn{{Name: "dt"}},
+			},
+			existingTableName: "existing_billing",
+			repairErr:         errors.New("connection refused"),
+			expectedRepaired:  []string{"existing_billing"},
+			expectedQueued:    map[string]time.Duration{},
+			expectedErr:       true,
+		},
+		{
+			name: "invalid",
+			source: cbTypes.FileDataSource{
+				Format:  cbTypes.FileDataSourceFormatJSON,
+				Columns: []cbTypes.FileDataSourceColumn{{Name: "account"}},
+			},
+			expectedQueued: map[string]time.Duration{},
+			expectedErr:    true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dataSource := &cbTypes.ReportDataSource{
+				ObjectMeta: metav1.ObjectMeta{Name: "billing", Namespace: "default", UID: "datasource-uid"},
+				Spec:       cbTypes.ReportDataSourceSpec{File: &tt.source},
+				Status:     cbTypes.ReportDataSourceStatus{TableName: tt.existingTableName},
+			}
+			client := fake.NewSimpleClientset(dataSource.DeepCopy())
+			tableManager := &fakeTableManager{repairErr: tt.repairErr}
+			queue := &fakeDelayingQueue{addedAfter: make(map[string]time.Duration)}
+			op := &Reporting{
+				logger:                logrus.New(),
+				clock:                 clock.NewFakeClock(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)),
+				workCtx:               context.Background(),
+				meteringClient:        client,
+				tableManager:          tableManager,
+				reportDataSourceQueue: queue,
+			}
+
+			err := op.handleFileDataSource(op.logger, dataSource)
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedTables, tableManager.createdTables)
+			assert.Equal(t, tt.expectedProperties, tableManager.createdTableProperties)
+			assert.Equal(t, tt.expectedRepaired, tableManager.repairedTables)
+			assert.Equal(t, tt.expectedQueued, queue.addedAfter)
+
+			if len(tt.expectedTables) == 0 {
+				return
+			}
+			updated, err := client.MeteringV1alpha1().ReportDataSources("default").Get("billing", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tableName, updated.Status.TableName)
+			prestoTable, err := client.MeteringV1alpha1().PrestoTables("default").Get(reportingutil.PrestoTableResourceNameFromKind("ReportDataSource", "billing"), metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, cbTypes.TableParameters(tt.expectedTables[0]), prestoTable.Status.Parameters)
+			assert.Equal(t, "s3a://bucket/billing/", prestoTable.Status.Properties.Location, "expected the files to be read from the location as is")
+		})
+	}
+}
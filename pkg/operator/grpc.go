@@ -0,0 +1,318 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/grpc/reportresults"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	GRPCAPIAddress = ":8083"
+
+	defaultGRPCBatchSize = 1000
+	maxGRPCBatchSize     = 10000
+	// grpcMaxConcurrentStreams limits how many results are streamed at
+	// once by each client connection.
+	grpcMaxConcurrentStreams = 16
+)
+
+// reportResultsGRPCServer implements the ReportResults gRPC service, which
+// streams the results of Reports and ScheduledReports in batches of typed
+// rows. Sending a batch blocks while the client's HTTP/2 flow control window
+// is full, so results are only sent as fast as the client reads them.
+type reportResultsGRPCServer struct {
+	logger log.FieldLogger
+
+	reportResultsGetter prestostore.ReportResultsGetter
+	// resultsCache is shared with the HTTP API, and is nil when caching is
+	// disabled.
+	resultsCache *reportResultsCache
+	// authorizer is shared with the HTTP API, and is nil when every caller
+	// can read every result.
+	authorizer *resultsAuthorizer
+	// auditor is shared with the HTTP API, and is nil when auditing is
+	// disabled.
+	auditor *resultsAuditor
+
+	namespace                   string
+	reportLister                listers.ReportLister
+	scheduledReportLister       listers.ScheduledReportLister
+	reportGenerationQueryLister listers.ReportGenerationQueryLister
+	prestoTableLister           listers.PrestoTableLister
+}
+
+func newGRPCServer(
+	logger log.FieldLogger,
+	tlsConfig TLSConfig,
+	reportResultsGetter prestostore.ReportResultsGetter,
+	resultsCache *reportResultsCache,
+	authorizer *resultsAuthorizer,
+	auditor *resultsAuditor,
+	namespace string,
+	reportLister listers.ReportLister,
+	scheduledReportLister listers.ScheduledReportLister,
+	reportGenerationQueryLister listers.ReportGenerationQueryLister,
+	prestoTableLister listers.PrestoTableLister,
+) (*grpc.Server, error) {
+	opts := []grpc.ServerOption{grpc.MaxConcurrentStreams(grpcMaxConcurrentStreams)}
+	if tlsConfig.UseTLS {
+		creds, err := credentials.NewServerTLSFromFile(tlsConfig.TLSCert, tlsConfig.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load gRPC API TLS certificate: %v", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	server := grpc.NewServer(opts...)
+	reportresults.RegisterReportResultsServer(server, &reportResultsGRPCServer{
+		logger:                      logger.WithField("component", "grpc-api"),
+		reportResultsGetter:         reportResultsGetter,
+		resultsCache:                resultsCache,
+		authorizer:                  authorizer,
+		auditor:                     auditor,
+		namespace:                   namespace,
+		reportLister:                reportLister,
+		scheduledReportLister:       scheduledReportLister,
+		reportGenerationQueryLister: reportGenerationQueryLister,
+		prestoTableLister:           prestoTableLister,
+	})
+	return server, nil
+}
+
+func (srv *reportResultsGRPCServer) requestLogger(method string, req *reportresults.StreamReportResultsRequest) (log.FieldLogger, string) {
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = srv.namespace
+	}
+	return srv.logger.WithFields(log.Fields{
+		"method":    method,
+		"namespace": namespace,
+		"name":      req.Name,
+	}), namespace
+}
+
+func (srv *reportResultsGRPCServer) StreamReportResults(req *reportresults.StreamReportResultsRequest, stream reportresults.ReportResults_StreamReportResultsServer) (err error) {
+	logger, namespace := srv.requestLogger("StreamReportResults", req)
+	audit := srv.auditor.grpcEvent(stream.Context(), "Report", namespace, req.Name)
+	defer func() { audit.finishGRPC(err) }()
+	if req.Name == "" {
+		return status.Error(codes.InvalidArgument, "name is required")
+	}
+	if err := srv.authorizeResults(stream.Context(), resultsResourceReports, namespace); err != nil {
+		return err
+	}
+	report, err := srv.reportLister.Reports(namespace).Get(req.Name)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return status.Errorf(codes.NotFound, "error getting report: %v", err)
+		}
+		return status.Errorf(codes.Internal, "error getting report: %v", err)
+	}
+	switch report.Status.Phase {
+	case api.ReportPhaseError:
+		return status.Errorf(codes.FailedPrecondition, "the report encountered an error: %s", report.Status.Output)
+	case api.ReportPhaseFinished:
+	default:
+		return status.Error(codes.Unavailable, ErrReportIsRunning.Error())
+	}
+	return srv.streamResults(logger, stream, audit, req, "Report", report.ObjectMeta, report.Spec.GenerationQueryName)
+}
+
+func (srv *reportResultsGRPCServer) StreamScheduledReportResults(req *reportresults.StreamReportResultsRequest, stream reportresults.ReportResults_StreamScheduledReportResultsServer) (err error) {
+	logger, namespace := srv.requestLogger("StreamScheduledReportResults", req)
+	audit := srv.auditor.grpcEvent(stream.Context(), "ScheduledReport", namespace, req.Name)
+	defer func() { audit.finishGRPC(err) }()
+	if req.Name == "" {
+		return status.Error(codes.InvalidArgument, "name is required")
+	}
+	if err := srv.authorizeResults(stream.Context(), resultsResourceScheduledReports, namespace); err != nil {
+		return err
+	}
+	report, err := srv.scheduledReportLister.ScheduledReports(namespace).Get(req.Name)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return status.Errorf(codes.NotFound, "error getting scheduledReport: %v", err)
+		}
+		return status.Errorf(codes.Internal, "error getting scheduledReport: %v", err)
+	}
+	if !req.IgnoreFailed {
+		if cond := cbutil.GetScheduledReportCondition(report.Status, api.ScheduledReportFailure); cond != nil && cond.Status == v1.ConditionTrue {
+			return status.Errorf(codes.FailedPrecondition, "scheduledReport is is failed state, reason: %s, message: %s", cond.Reason, cond.Message)
+		}
+	}
+	return srv.streamResults(logger, stream, audit, req, "ScheduledReport", report.ObjectMeta, report.Spec.GenerationQueryName)
+}
+
+// authorizeResults returns an error if the caller can't read the results of
+// resource in namespace. Callers are identified by the bearer token in the
+// authorization metadata of their request.
+func (srv *reportResultsGRPCServer) authorizeResults(ctx context.Context, resource, namespace string) error {
+	if srv.authorizer == nil {
+		return nil
+	}
+	allowed, err := srv.authorizer.allowed(grpcRequestToken(ctx), "get", resource, namespace)
+	switch {
+	case err == errNoBearerToken || err == errInvalidBearerToken:
+		return status.Error(codes.Unauthenticated, err.Error())
+	case err != nil:
+		return status.Error(codes.Internal, err.Error())
+	case !allowed:
+		return status.Errorf(codes.PermissionDenied, "not allowed to get %s in namespace %s", resource, namespace)
+	}
+	return nil
+}
+
+// grpcRequestToken returns the bearer token in the authorization metadata
+// of the request.
+func grpcRequestToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get("authorization"); len(values) != 0 {
+		return bearerToken(values[0])
+	}
+	return ""
+}
+
+type reportResultsChunkSender interface {
+	Context() context.Context
+	Send(*reportresults.ReportResultsChunk) error
+}
+
+// streamResults sends the results of a Report or ScheduledReport in chunks
+// of up to the request's batch size.
+func (srv *reportResultsGRPCServer) streamResults(logger log.FieldLogger, stream reportResultsChunkSender, audit *resultsAuditEvent, req *reportresults.StreamReportResultsRequest, kind string, meta metav1.ObjectMeta, generationQueryName string) error {
+	batchSize := int(req.BatchSize)
+	switch {
+	case batchSize < 0 || batchSize > maxGRPCBatchSize:
+		return status.Errorf(codes.InvalidArgument, "batch_size must be between 0 and %d", maxGRPCBatchSize)
+	case batchSize == 0:
+		batchSize = defaultGRPCBatchSize
+	}
+
+	reportQuery, err := srv.reportGenerationQueryLister.ReportGenerationQueries(meta.Namespace).Get(generationQueryName)
+	if err != nil {
+		return status.Errorf(codes.Internal, "error getting ReportGenerationQuery: %v", err)
+	}
+	prestoTable, err := srv.prestoTableLister.PrestoTables(meta.Namespace).Get(reportingutil.PrestoTableResourceNameFromKind(kind, meta.Name))
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return status.Errorf(codes.Unavailable, "%s is not processed yet", kind)
+		}
+		return status.Errorf(codes.Internal, "error getting presto table: %v", err)
+	}
+
+	queryPrestoColumns, err := reportingutil.GeneratePrestoColumns(reportQuery)
+	if err != nil {
+		return status.Errorf(codes.Internal, "error converting columns: %v", err)
+	}
+	prestoColumns, err := reportingutil.HiveColumnsToPrestoColumns(prestoTable.Status.Parameters.Columns)
+	if err != nil {
+		return status.Errorf(codes.Internal, "error converting columns: %v", err)
+	}
+	if !reflect.DeepEqual(queryPrestoColumns, prestoColumns) {
+		logger.Warnf("report columns and table columns don't match, ReportGenerationQuery was likely updated after the report ran")
+	}
+
+	results, err := getReportResults(stream.Context(), srv.reportResultsGetter, srv.resultsCache, kind, meta.Namespace, meta.Name, meta.ResourceVersion, prestoTable.Status.Parameters.Name, prestoColumns)
+	if err != nil {
+		logger.WithError(err).Errorf("failed to perform presto query")
+		return status.Errorf(codes.Internal, "failed to perform presto query (see operator logs for more details): %v", err)
+	}
+	if len(results) > 0 && len(prestoColumns) != len(results[0]) {
+		logger.Errorf("report results schema doesn't match expected schema, got %d columns, expected %d", len(results[0]), len(prestoColumns))
+		return status.Error(codes.Internal, "report results schema doesn't match expected schema")
+	}
+
+	var columns []api.ReportGenerationQueryColumn
+	chunk := &reportresults.ReportResultsChunk{}
+	for _, column := range reportQuery.Spec.Columns {
+		if column.TableHidden && !req.IncludeHidden {
+			continue
+		}
+		columns = append(columns, column)
+		chunk.Columns = append(chunk.Columns, &reportresults.Column{
+			Name:        column.Name,
+			Type:        column.Type,
+			Unit:        column.Unit,
+			TableHidden: column.TableHidden,
+		})
+	}
+
+	audit.setRows(len(results))
+	// the first chunk is always sent, so the columns are returned even if
+	// there are no results
+	for i := 0; i == 0 || i < len(results); i += batchSize {
+		end := i + batchSize
+		if end > len(results) {
+			end = len(results)
+		}
+		for _, row := range results[i:end] {
+			chunk.Rows = append(chunk.Rows, convertRowToGRPC(columns, row))
+		}
+		if err := stream.Send(chunk); err != nil {
+			logger.WithError(err).Warnf("failed sending results after %d rows", i)
+			return err
+		}
+		chunk = &reportresults.ReportResultsChunk{}
+	}
+	logger.Debugf("sent %d rows", len(results))
+	return nil
+}
+
+func convertRowToGRPC(columns []api.ReportGenerationQueryColumn, row presto.Row) *reportresults.Row {
+	values := make([]*reportresults.Value, len(columns))
+	for i, column := range columns {
+		values[i] = convertValueToGRPC(row[column.Name])
+	}
+	return &reportresults.Row{Values: values}
+}
+
+func convertValueToGRPC(value interface{}) *reportresults.Value {
+	switch v := value.(type) {
+	case nil:
+		return &reportresults.Value{}
+	case string:
+		return &reportresults.Value{Kind: &reportresults.Value_StringValue{StringValue: v}}
+	case bool:
+		return &reportresults.Value{Kind: &reportresults.Value_BoolValue{BoolValue: v}}
+	case int:
+		return &reportresults.Value{Kind: &reportresults.Value_IntValue{IntValue: int64(v)}}
+	case int32:
+		return &reportresults.Value{Kind: &reportresults.Value_IntValue{IntValue: int64(v)}}
+	case int64:
+		return &reportresults.Value{Kind: &reportresults.Value_IntValue{IntValue: v}}
+	case float32:
+		return &reportresults.Value{Kind: &reportresults.Value_DoubleValue{DoubleValue: float64(v)}}
+	case float64:
+		return &reportresults.Value{Kind: &reportresults.Value_DoubleValue{DoubleValue: v}}
+	case time.Time:
+		ts, err := ptypes.TimestampProto(v)
+		if err == nil {
+			return &reportresults.Value{Kind: &reportresults.Value_TimestampValue{TimestampValue: ts}}
+		}
+	}
+	// values of other types, such as maps and arrays, are returned as
+	// strings
+	return &reportresults.Value{Kind: &reportresults.Value_StringValue{StringValue: fmt.Sprint(value)}}
+}
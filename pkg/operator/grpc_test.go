@@ -0,0 +1,44 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/grpc/reportresults"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func TestConvertRowToGRPC(t *testing.T) {
+	columns := []api.ReportGenerationQueryColumn{
+		{Name: "namespace", Type: "varchar"},
+		{Name: "pod_count", Type: "bigint"},
+		{Name: "cpu_core_seconds", Type: "double"},
+		{Name: "period_start", Type: "timestamp"},
+		{Name: "is_node", Type: "boolean"},
+		{Name: "labels", Type: "map(varchar, varchar)"},
+		{Name: "missing", Type: "varchar"},
+	}
+	row := presto.Row{
+		"namespace":        "default",
+		"pod_count":        int64(3),
+		"cpu_core_seconds": 2412.5,
+		"period_start":     time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+		"is_node":          false,
+		"labels":           map[string]interface{}{"app": "metering"},
+		"missing":          nil,
+		"ignored":          "not in columns",
+	}
+	assert.Equal(t, &reportresults.Row{Values: []*reportresults.Value{
+		{Kind: &reportresults.Value_StringValue{StringValue: "default"}},
+		{Kind: &reportresults.Value_IntValue{IntValue: 3}},
+		{Kind: &reportresults.Value_DoubleValue{DoubleValue: 2412.5}},
+		{Kind: &reportresults.Value_TimestampValue{TimestampValue: &timestamp.Timestamp{Seconds: 1546300800}}},
+		{Kind: &reportresults.Value_BoolValue{BoolValue: false}},
+		{Kind: &reportresults.Value_StringValue{StringValue: "map[app:metering]"}},
+		{},
+	}}, convertRowToGRPC(columns, row))
+}
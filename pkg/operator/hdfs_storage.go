@@ -0,0 +1,190 @@
+package operator
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hdfs"
+)
+
+const (
+	DefaultHDFSStorageCheckInterval = 10 * time.Minute
+
+	// defaultHDFSNamenode and defaultWebHDFSAddress are the addresses of the
+	// namenode of the HDFS deployed by Metering.
+	defaultHDFSNamenode   = "hdfs-namenode-proxy:9820"
+	defaultWebHDFSAddress = "http://hdfs-namenode-web:9870"
+)
+
+var hdfsStorageUpdatedFilesCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: prometheusMetricNamespace,
+		Name:      "storagelocation_hdfs_updated_files_total",
+		Help:      "Number of files and directories stored in a HDFS StorageLocation which had their replication factor or permission updated.",
+	},
+	[]string{"namespace", "storagelocation"},
+)
+
+func init() {
+	prometheus.MustRegister(hdfsStorageUpdatedFilesCounter)
+}
+
+// hdfsStorageLocation returns the hdfs:// URL of the HDFSStorage's base path.
+func hdfsStorageLocation(hdfsStorage *cbTypes.HDFSStorage) (string, error) {
+	if hdfsStorage.BasePath == "" {
+		return "", fmt.Errorf("spec.hdfs.basePath must be set")
+	}
+	namenode := hdfsStorage.Namenode
+	if namenode == "" {
+		namenode = defaultHDFSNamenode
+	}
+	return fmt.Sprintf("hdfs://%s%s", namenode, path.Join("/", hdfsStorage.BasePath)), nil
+}
+
+// hdfsHiveStorage returns the HiveStorage equivalent to the HDFSStorage.
+func hdfsHiveStorage(hdfsStorage *cbTypes.HDFSStorage) (*cbTypes.HiveStorage, error) {
+	location, err := hdfsStorageLocation(hdfsStorage)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := hdfsStorageUmask(hdfsStorage); err != nil {
+		return nil, err
+	}
+	if hdfsStorage.ReplicationFactor != nil && *hdfsStorage.ReplicationFactor < 1 {
+		return nil, fmt.Errorf("spec.hdfs.replicationFactor must be at least 1, got %d", *hdfsStorage.ReplicationFactor)
+	}
+	return &cbTypes.HiveStorage{
+		TableProperties: cbTypes.TableProperties{Location: location},
+		FileFormat:      hdfsStorage.FileFormat,
+		Compression:     hdfsStorage.Compression,
+	}, nil
+}
+
+// hdfsStorageUmask returns the HDFSStorage's umask, or 0 if it's unset.
+func hdfsStorageUmask(hdfsStorage *cbTypes.HDFSStorage) (os.FileMode, error) {
+	if hdfsStorage.Umask == "" {
+		return 0, nil
+	}
+	umask, err := strconv.ParseUint(hdfsStorage.Umask, 8, 32)
+	if err != nil || umask > 0777 {
+		return 0, fmt.Errorf("invalid spec.hdfs.umask %q, must be an octal umask such as 027", hdfsStorage.Umask)
+	}
+	return os.FileMode(umask), nil
+}
+
+func newWebHDFSClient(hdfsStorage *cbTypes.HDFSStorage) hdfs.Client {
+	address := hdfsStorage.WebHDFSAddress
+	if address == "" {
+		address = defaultWebHDFSAddress
+	}
+	return hdfs.NewClient(address)
+}
+
+// createHDFSStorageBasePath creates the HDFSStorage's base path if it doesn't
+// exist, so tables are created within a directory with the umask applied.
+func createHDFSStorageBasePath(hdfsStorage *cbTypes.HDFSStorage) error {
+	umask, err := hdfsStorageUmask(hdfsStorage)
+	if err != nil {
+		return err
+	}
+	return newWebHDFSClient(hdfsStorage).Mkdirs(hdfsStorage.BasePath, 0777&^umask)
+}
+
+// runHDFSStorageCheck periodically applies the replication factor and umask
+// of HDFS StorageLocations to the files stored in them until stopCh is
+// closed.
+func (op *Reporting) runHDFSStorageCheck(stopCh <-chan struct{}) {
+	wait.Until(op.checkHDFSStorageLocations, op.cfg.HDFSStorageCheckInterval, stopCh)
+}
+
+func (op *Reporting) checkHDFSStorageLocations() {
+	logger := op.logger.WithField("component", "hdfsStorageCheck")
+	storageLocations, err := op.storageLocationLister.List(labels.Everything())
+	if err != nil {
+		logger.WithError(err).Errorf("unable to list StorageLocations")
+		return
+	}
+	for _, storageLocation := range storageLocations {
+		hdfsStorage := storageLocation.Spec.HDFS
+		if hdfsStorage == nil || (hdfsStorage.ReplicationFactor == nil && hdfsStorage.Umask == "") || !op.cfg.isWatchedNamespace(storageLocation.Namespace) {
+			continue
+		}
+		umask, err := hdfsStorageUmask(hdfsStorage)
+		if err != nil {
+			logger.WithError(err).Errorf("invalid HDFS configuration for StorageLocation %s", storageLocation.Name)
+			continue
+		}
+		var replication int
+		if hdfsStorage.ReplicationFactor != nil {
+			replication = int(*hdfsStorage.ReplicationFactor)
+		}
+		updated, err := applyHDFSStorageSettings(newWebHDFSClient(hdfsStorage), hdfsStorage.BasePath, replication, umask)
+		hdfsStorageUpdatedFilesCounter.WithLabelValues(storageLocation.Namespace, storageLocation.Name).Add(float64(updated))
+		if err != nil {
+			logger.WithError(err).Errorf("unable to apply HDFS settings of StorageLocation %s", storageLocation.Name)
+			continue
+		}
+		if updated > 0 {
+			logger.WithFields(log.Fields{"storageLocation": storageLocation.Name}).Infof("updated the replication factor or permission of %d files in %s", updated, hdfsStorage.BasePath)
+		}
+	}
+}
+
+// applyHDFSStorageSettings walks the files and directories within basePath,
+// setting the replication factor of each file to replication, and removing
+// umask from the permission of each file and directory. Files are written by
+// Presto and Hive using their own HDFS configuration, so the settings can
+// only be applied after the files have been written. A replication of 0
+// leaves replication factors unchanged. The number of files and directories
+// updated is returned.
+func applyHDFSStorageSettings(client hdfs.Client, basePath string, replication int, umask os.FileMode) (int, error) {
+	updated := 0
+	dirs := []string{basePath}
+	for len(dirs) > 0 {
+		dir := dirs[len(dirs)-1]
+		dirs = dirs[:len(dirs)-1]
+		statuses, err := client.ListStatus(dir)
+		if err != nil {
+			return updated, err
+		}
+		for _, status := range statuses {
+			// Hive and Presto write to hidden staging directories, which
+			// are removed once the write finishes
+			if strings.HasPrefix(path.Base(status.Path), ".") {
+				continue
+			}
+			changed := false
+			if status.Permission&umask != 0 {
+				if err := client.SetPermission(status.Path, status.Permission&^umask); err != nil {
+					return updated, err
+				}
+				changed = true
+			}
+			switch status.Type {
+			case hdfs.FileTypeDirectory:
+				dirs = append(dirs, status.Path)
+			case hdfs.FileTypeFile:
+				if replication > 0 && status.Replication != replication {
+					if err := client.SetReplication(status.Path, replication); err != nil {
+						return updated, err
+					}
+					changed = true
+				}
+			}
+			if changed {
+				updated++
+			}
+		}
+	}
+	return updated, nil
+}
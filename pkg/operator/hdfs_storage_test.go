@@ -0,0 +1,105 @@
+package operator
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hdfs"
+)
+
+type fakeHDFSClient struct {
+	dirs         map[string][]hdfs.FileStatus
+	replications map[string]int
+	permissions  map[string]os.FileMode
+}
+
+func (c *fakeHDFSClient) ListStatus(dir string) ([]hdfs.FileStatus, error) {
+	return c.dirs[dir], nil
+}
+
+func (c *fakeHDFSClient) Mkdirs(dir string, permission os.FileMode) error {
+	return nil
+}
+
+func (c *fakeHDFSClient) SetReplication(file string, replication int) error {
+	c.replications[file] = replication
+	return nil
+}
+
+func (c *fakeHDFSClient) SetPermission(file string, permission os.FileMode) error {
+	c.permissions[file] = permission
+	return nil
+}
+
+func TestApplyHDFSStorageSettings(t *testing.T) {
+	client := &fakeHDFSClient{
+		dirs: map[string][]hdfs.FileStatus{
+			"/metering": {
+				{Path: "/metering/table_a", Type: hdfs.FileTypeDirectory, Permission: 0755},
+				{Path: "/metering/.hive-staging", Type: hdfs.FileTypeDirectory, Permission: 0777},
+			},
+			"/metering/table_a": {
+				{Path: "/metering/table_a/dt=2019-01-01", Type: hdfs.FileTypeDirectory, Permission: 0750},
+				{Path: "/metering/table_a/file_1", Type: hdfs.FileTypeFile, Replication: 3, Permission: 0644},
+			},
+			"/metering/table_a/dt=2019-01-01": {
+				{Path: "/metering/table_a/dt=2019-01-01/file_2", Type: hdfs.FileTypeFile, Replication: 1, Permission: 0640},
+			},
+			"/metering/.hive-staging": {
+				{Path: "/metering/.hive-staging/file_3", Type: hdfs.FileTypeFile, Replication: 3, Permission: 0666},
+			},
+		},
+		replications: make(map[string]int),
+		permissions:  make(map[string]os.FileMode),
+	}
+
+	updated, err := applyHDFSStorageSettings(client, "/metering", 1, 0027)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated)
+	assert.Equal(t, map[string]int{"/metering/table_a/file_1": 1}, client.replications)
+	assert.Equal(t, map[string]os.FileMode{
+		"/metering/table_a":        0750,
+		"/metering/table_a/file_1": 0640,
+	}, client.permissions)
+}
+
+func TestHDFSHiveStorage(t *testing.T) {
+	replicationFactor := int32(1)
+	tests := map[string]struct {
+		storage     cbTypes.HDFSStorage
+		expected    cbTypes.HiveStorage
+		expectError bool
+	}{
+		"default namenode": {
+			storage:  cbTypes.HDFSStorage{BasePath: "operator_metering/storage/", ReplicationFactor: &replicationFactor, Umask: "027", FileFormat: "orc"},
+			expected: cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{Location: "hdfs://hdfs-namenode-proxy:9820/operator_metering/storage"}, FileFormat: "orc"},
+		},
+		"namenode": {
+			storage:  cbTypes.HDFSStorage{Namenode: "namenode:8020", BasePath: "/metering"},
+			expected: cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{Location: "hdfs://namenode:8020/metering"}},
+		},
+		"no base path": {
+			storage:     cbTypes.HDFSStorage{},
+			expectError: true,
+		},
+		"invalid umask": {
+			storage:     cbTypes.HDFSStorage{BasePath: "/metering", Umask: "099"},
+			expectError: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			hiveStorage, err := hdfsHiveStorage(&test.storage)
+			if test.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, *hiveStorage)
+		})
+	}
+}
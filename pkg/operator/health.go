@@ -14,21 +14,10 @@ type statusResponse struct {
 // until the checks succeed.
 func (op *Reporting) readinessHandler(w http.ResponseWriter, r *http.Request) {
 	logger := newRequestLogger(op.logger, r, op.rand)
-	if !op.isInitialized() {
-		logger.Debugf("not ready: operator is not yet initialized")
-		writeResponseAsJSON(logger, w, http.StatusInternalServerError,
-			statusResponse{
-				Status:  "not ready",
-				Details: "not initialized",
-			})
-		return
-	}
-	if !op.testReadFromPrestoFunc() {
-		writeResponseAsJSON(logger, w, http.StatusInternalServerError,
-			statusResponse{
-				Status:  "not ready",
-				Details: "cannot read from PrestoDB",
-			})
+	// queue depth is excluded, since a saturated operator should continue
+	// to receive traffic that isn't for the API
+	if reason, message := op.readiness(false); reason != "" {
+		op.writeNotReadyResponse(w, r, reason, message)
 		return
 	}
 
@@ -39,6 +28,14 @@ func (op *Reporting) readinessHandler(w http.ResponseWriter, r *http.Request) {
 // fails, the process will be restarted.
 func (op *Reporting) healthinessHandler(w http.ResponseWriter, r *http.Request) {
 	logger := newRequestLogger(op.logger, r, op.rand)
+	if !op.isInitialized() {
+		writeResponseAsJSON(logger, w, http.StatusInternalServerError,
+			statusResponse{
+				Status:  "not healthy",
+				Details: "not initialized",
+			})
+		return
+	}
 	if !op.testWriteToPrestoFunc() {
 		writeResponseAsJSON(logger, w, http.StatusInternalServerError,
 			statusResponse{
@@ -49,3 +46,40 @@ func (op *Reporting) healthinessHandler(w http.ResponseWriter, r *http.Request)
 	}
 	writeResponseAsJSON(logger, w, http.StatusOK, statusResponse{Status: "ok"})
 }
+
+// DependenciesResponse is the response of the /healthz and /readyz
+// endpoints.
+type DependenciesResponse struct {
+	Status       string             `json:"status"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+// readyzHandler reports the state of each dependency of reporting-operator,
+// failing unless all of them are ready.
+func (op *Reporting) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(op.logger, r, op.rand)
+	statuses := op.dependencies.statuses()
+	for _, status := range statuses {
+		if !status.Ready {
+			writeResponseAsJSON(logger, w, http.StatusServiceUnavailable, DependenciesResponse{Status: "not ready", Dependencies: statuses})
+			return
+		}
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, DependenciesResponse{Status: "ok", Dependencies: statuses})
+}
+
+// healthzHandler reports the state of each dependency of reporting-operator
+// like readyzHandler, but only fails if a dependency which restarting
+// reporting-operator may fix has been checked and isn't ready, so
+// dependencies which are down or still starting don't cause restarts.
+func (op *Reporting) healthzHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(op.logger, r, op.rand)
+	statuses := op.dependencies.statuses()
+	for _, status := range statuses {
+		if status.Liveness && !status.Ready && !status.Pending {
+			writeResponseAsJSON(logger, w, http.StatusInternalServerError, DependenciesResponse{Status: "not healthy", Dependencies: statuses})
+			return
+		}
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, DependenciesResponse{Status: "ok", Dependencies: statuses})
+}
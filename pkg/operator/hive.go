@@ -1,6 +1,7 @@
 package operator
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"path"
@@ -11,13 +12,14 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
 	"github.com/operator-framework/operator-metering/pkg/hive"
 )
 
-func (op *Reporting) createTableForStorage(logger log.FieldLogger, obj metav1.Object, gvk schema.GroupVersionKind, storage *cbTypes.StorageLocationRef, tableName string, columns, partitions []hive.Column) error {
-	tableProperties, err := op.getHiveTableProperties(logger, storage, gvk.Kind)
+func (op *Reporting) createTableForStorage(ctx context.Context, logger log.FieldLogger, obj metav1.Object, gvk schema.GroupVersionKind, storage *cbTypes.StorageLocationRef, tableName string, columns, partitions []hive.Column) error {
+	tableProperties, err := op.getHiveTableProperties(logger, obj.GetNamespace(), storage, gvk.Kind)
 	if err != nil {
-		return fmt.Errorf("storage incorrectly configured for %s %s, err: %v", gvk, obj.GetName(), err)
+		return reasonErrorf(err, cbutil.StorageErrorReason, "storage incorrectly configured for %s %s, err: %v", gvk, obj.GetName(), err)
 	}
 	tableParams := hive.TableParameters{
 		Name:         tableName,
@@ -25,13 +27,13 @@ func (op *Reporting) createTableForStorage(logger log.FieldLogger, obj metav1.Ob
 		Partitions:   partitions,
 		IgnoreExists: true,
 	}
-	return op.createTableWith(logger, obj, gvk, tableParams, *tableProperties)
+	return op.createTableWith(ctx, logger, obj, gvk, tableParams, *tableProperties)
 }
 
-func (op *Reporting) createTableForStorageNoCR(logger log.FieldLogger, storage *cbTypes.StorageLocationRef, tableName string, columns []hive.Column) error {
-	tableProperties, err := op.getHiveTableProperties(logger, storage, tableName)
+func (op *Reporting) createTableForStorageNoCR(ctx context.Context, logger log.FieldLogger, storage *cbTypes.StorageLocationRef, tableName string, columns []hive.Column) error {
+	tableProperties, err := op.getHiveTableProperties(logger, "", storage, tableName)
 	if err != nil {
-		return fmt.Errorf("storage incorrectly configured for %s, err: %v", tableName, err)
+		return reasonErrorf(err, cbutil.StorageErrorReason, "storage incorrectly configured for %s, err: %v", tableName, err)
 	}
 	tableParams := hive.TableParameters{
 		Name:         tableName,
@@ -42,19 +44,19 @@ func (op *Reporting) createTableForStorageNoCR(logger log.FieldLogger, storage *
 	if err != nil {
 		return err
 	}
-	return op.createTable(logger, tableParams, newTableProperties)
+	return op.createTable(ctx, logger, tableParams, newTableProperties)
 }
 
-func (op *Reporting) createTableWith(logger log.FieldLogger, obj metav1.Object, gvk schema.GroupVersionKind, params hive.TableParameters, properties hive.TableProperties) error {
+func (op *Reporting) createTableWith(ctx context.Context, logger log.FieldLogger, obj metav1.Object, gvk schema.GroupVersionKind, params hive.TableParameters, properties hive.TableProperties) error {
 	newTableProperties, err := addTableNameToLocation(properties, params.Name)
 	if err != nil {
 		return err
 	}
-	return op.createTableAndCR(logger, obj, gvk, params, newTableProperties)
+	return op.createTableAndCR(ctx, logger, obj, gvk, params, newTableProperties)
 }
 
-func (op *Reporting) createTableAndCR(logger log.FieldLogger, obj metav1.Object, gvk schema.GroupVersionKind, params hive.TableParameters, properties hive.TableProperties) error {
-	err := op.createTable(logger, params, properties)
+func (op *Reporting) createTableAndCR(ctx context.Context, logger log.FieldLogger, obj metav1.Object, gvk schema.GroupVersionKind, params hive.TableParameters, properties hive.TableProperties) error {
+	err := op.createTable(ctx, logger, params, properties)
 	if err != nil {
 		return err
 	}
@@ -69,11 +71,11 @@ func (op *Reporting) createTableAndCR(logger log.FieldLogger, obj metav1.Object,
 	return nil
 }
 
-func (op *Reporting) createTable(logger log.FieldLogger, params hive.TableParameters, properties hive.TableProperties) error {
+func (op *Reporting) createTable(ctx context.Context, logger log.FieldLogger, params hive.TableParameters, properties hive.TableProperties) error {
 	logger.Debugf("Creating table %s with Hive Storage %#v", params.Name, properties)
-	err := op.tableManager.CreateTable(params, properties)
+	err := op.tableManager.CreateTable(ctx, params, properties)
 	if err != nil {
-		return fmt.Errorf("couldn't create table: %v", err)
+		return reasonErrorf(err, cbutil.HiveErrorReason, "couldn't create table: %v", err)
 	}
 	logger.Debugf("successfully created table %s", params.Name)
 	return nil
@@ -26,6 +26,7 @@ import (
 	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
 	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
 	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
 	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
 	"github.com/operator-framework/operator-metering/pkg/presto"
 	"github.com/operator-framework/operator-metering/pkg/util/chiprometheus"
@@ -48,12 +49,26 @@ type server struct {
 
 	prometheusMetricsRepo prestostore.PrometheusMetricsRepo
 	reportResultsGetter   prestostore.ReportResultsGetter
+	// resultsCache caches the results returned by reportResultsGetter. It's
+	// nil when caching is disabled.
+	resultsCache *reportResultsCache
+	// authorizer restricts results to the namespaces the caller has access
+	// to. It's nil when every caller can read every result.
+	authorizer *resultsAuthorizer
+	// auditor records access to results. It's nil when auditing is
+	// disabled.
+	auditor *resultsAuditor
+	// queryTexts holds the text of ReportGenerationQuery versions to diff.
+	// It's nil when the text isn't available.
+	queryTexts *reporting.GenerationQueryTexts
 
 	namespace                    string
+	currency                     reporting.Currency
 	reportLister                 listers.ReportLister
 	scheduledReportLister        listers.ScheduledReportLister
 	reportGenerationQuerieLister listers.ReportGenerationQueryLister
 	prestoTableLister            listers.PrestoTableLister
+	reportDataSourceLister       listers.ReportDataSourceLister
 }
 
 type requestLogger struct {
@@ -64,6 +79,24 @@ func (l *requestLogger) Print(v ...interface{}) {
 	l.FieldLogger.Info(v...)
 }
 
+// routerOptions are the optional dependencies of the API router, each of
+// which is disabled when nil.
+type routerOptions struct {
+	// resultsCache caches the results returned by reportResultsGetter.
+	resultsCache *reportResultsCache
+	// authorizer restricts results to the namespaces the caller has access
+	// to.
+	authorizer *resultsAuthorizer
+	// auditor records access to results.
+	auditor *resultsAuditor
+	// rateLimiter limits the rate of requests and the number of downloads
+	// handled at once.
+	rateLimiter *apiRateLimiter
+	// queryTexts holds the text of ReportGenerationQuery versions for the
+	// query diff API.
+	queryTexts *reporting.GenerationQueryTexts
+}
+
 func newRouter(
 	logger log.FieldLogger,
 	rand *rand.Rand,
@@ -71,16 +104,21 @@ func newRouter(
 	reportResultsGetter prestostore.ReportResultsGetter,
 	collectorFunc prometheusImporterFunc,
 	namespace string,
+	currency reporting.Currency,
 	reportLister listers.ReportLister,
 	scheduledReportLister listers.ScheduledReportLister,
 	reportGenerationQuerieLister listers.ReportGenerationQueryLister,
 	prestoTableLister listers.PrestoTableLister,
+	reportDataSourceLister listers.ReportDataSourceLister,
+	opts routerOptions,
 ) chi.Router {
+	rateLimiter := opts.rateLimiter
 	router := chi.NewRouter()
 	logger = logger.WithField("component", "api")
 	requestLogger := middleware.RequestLogger(&middleware.DefaultLogFormatter{Logger: &requestLogger{logger}})
 	router.Use(requestLogger)
 	router.Use(prometheusMiddleware)
+	router.Use(rateLimiter.middleware)
 
 	srv := &server{
 		logger:                       logger,
@@ -88,24 +126,40 @@ func newRouter(
 		collectorFunc:                collectorFunc,
 		prometheusMetricsRepo:        prometheusMetricsRepo,
 		reportResultsGetter:          reportResultsGetter,
+		resultsCache:                 opts.resultsCache,
+		authorizer:                   opts.authorizer,
+		auditor:                      opts.auditor,
+		queryTexts:                   opts.queryTexts,
 		namespace:                    namespace,
+		currency:                     currency,
 		reportLister:                 reportLister,
 		scheduledReportLister:        scheduledReportLister,
 		reportGenerationQuerieLister: reportGenerationQuerieLister,
 		prestoTableLister:            prestoTableLister,
+		reportDataSourceLister:       reportDataSourceLister,
 	}
 
-	router.HandleFunc(APIV1ReportsGetEndpoint, srv.getReportHandler)
-	router.HandleFunc("/api/v2/reports/{name}/full", srv.getReportV2FullHandler)
-	router.HandleFunc("/api/v2/reports/{name}/table", srv.getReportV2TableHandler)
+	router.HandleFunc(APIOpenAPISpecEndpoint, srv.openAPISpecHandler)
+	router.HandleFunc("/api/v1/reports", srv.listReportsHandler)
+	router.HandleFunc("/api/v1/scheduledreports", srv.listScheduledReportsHandler)
+	router.HandleFunc(APIV1ReportsGetEndpoint, rateLimiter.limitDownloads(srv.getReportHandler))
+	router.HandleFunc("/api/v2/reports/{name}/full", rateLimiter.limitDownloads(srv.getReportV2FullHandler))
+	router.HandleFunc("/api/v2/reports/{name}/table", rateLimiter.limitDownloads(srv.getReportV2TableHandler))
 	// The following two routes handle returning a 400 when the name parameter is missing, rather than having a 404 returned.
 	router.HandleFunc("/api/v2/reports//full", srv.getReportV2NameMissingHandler)
 	router.HandleFunc("/api/v2/reports//table", srv.getReportV2NameMissingHandler)
-	router.HandleFunc("/api/v1/scheduledreports/get", srv.getScheduledReportHandler)
+	router.HandleFunc("/api/v1/scheduledreports/get", rateLimiter.limitDownloads(srv.getScheduledReportHandler))
 	router.HandleFunc("/api/v1/reports/run", srv.runReportHandler)
+	router.HandleFunc("/api/v1/reports/querydiff", srv.reportQueryDiffHandler)
+	router.HandleFunc("/api/v1/scheduledreports/querydiff", srv.scheduledReportQueryDiffHandler)
 	router.HandleFunc("/api/v1/datasources/prometheus/collect", srv.collectPromsumDataHandler)
 	router.HandleFunc("/api/v1/datasources/prometheus/store/{datasourceName}", srv.storePromsumDataHandler)
-	router.HandleFunc("/api/v1/datasources/prometheus/fetch/{datasourceName}", srv.fetchPromsumDataHandler)
+	router.HandleFunc("/api/v1/datasources/prometheus/fetch/{datasourceName}", rateLimiter.limitDownloads(srv.fetchPromsumDataHandler))
+	router.HandleFunc(APIGrafanaPrefix, srv.grafanaTestHandler)
+	router.HandleFunc(APIGrafanaPrefix+"/", srv.grafanaTestHandler)
+	router.HandleFunc(APIGrafanaPrefix+"/search", srv.grafanaSearchHandler)
+	router.HandleFunc(APIGrafanaPrefix+"/query", rateLimiter.limitDownloads(srv.grafanaQueryHandler))
+	router.HandleFunc(APIGrafanaPrefix+"/annotations", srv.grafanaAnnotationsHandler)
 
 	return router
 }
@@ -127,10 +181,10 @@ func (srv *server) validateGetReportReq(logger log.FieldLogger, requiredQueryPar
 	}
 	format := r.Form["format"][0]
 	switch format {
-	case "json", "csv", "tab", "tabular":
+	case "json", "csv", "tab", "tabular", "html":
 		return true
 	}
-	writeErrorResponse(logger, w, r, http.StatusBadRequest, "format must be one of: csv, json or tabular")
+	writeErrorResponse(logger, w, r, http.StatusBadRequest, "format must be one of: csv, html, json or tabular")
 	return false
 }
 
@@ -139,7 +193,7 @@ func (srv *server) getReportHandler(w http.ResponseWriter, r *http.Request) {
 	if !srv.validateGetReportReq(logger, []string{"name", "format"}, w, r) {
 		return
 	}
-	srv.getReport(logger, r.Form["name"][0], r.Form["format"][0], false, true, w, r)
+	srv.getReport(logger, srv.requestNamespace(r), r.Form["name"][0], r.Form["format"][0], false, true, w, r)
 }
 
 func (srv *server) getReportV2FullHandler(w http.ResponseWriter, r *http.Request) {
@@ -148,7 +202,7 @@ func (srv *server) getReportV2FullHandler(w http.ResponseWriter, r *http.Request
 	if !srv.validateGetReportReq(logger, []string{"format"}, w, r) {
 		return
 	}
-	srv.getReport(logger, name, r.Form["format"][0], true, true, w, r)
+	srv.getReport(logger, srv.requestNamespace(r), name, r.Form["format"][0], true, true, w, r)
 }
 
 func (srv *server) getReportV2TableHandler(w http.ResponseWriter, r *http.Request) {
@@ -157,7 +211,7 @@ func (srv *server) getReportV2TableHandler(w http.ResponseWriter, r *http.Reques
 	if !srv.validateGetReportReq(logger, []string{"format"}, w, r) {
 		return
 	}
-	srv.getReport(logger, name, r.Form["format"][0], true, false, w, r)
+	srv.getReport(logger, srv.requestNamespace(r), name, r.Form["format"][0], true, false, w, r)
 }
 
 func (srv *server) getReportV2NameMissingHandler(w http.ResponseWriter, r *http.Request) {
@@ -173,7 +227,7 @@ func (srv *server) getScheduledReportHandler(w http.ResponseWriter, r *http.Requ
 	if !srv.validateGetReportReq(logger, []string{"name", "format"}, w, r) {
 		return
 	}
-	srv.getScheduledReport(logger, r.Form["name"][0], r.Form["format"][0], w, r)
+	srv.getScheduledReport(logger, srv.requestNamespace(r), r.Form["name"][0], r.Form["format"][0], w, r)
 }
 
 func (srv *server) runReportHandler(w http.ResponseWriter, r *http.Request) {
@@ -193,9 +247,21 @@ func (srv *server) runReportHandler(w http.ResponseWriter, r *http.Request) {
 		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
 		return
 	}
+	if !srv.authorizer.authorizeRequest(logger, w, r, "get", resultsResourceReportGenerationQueries, srv.requestNamespace(r)) {
+		return
+	}
 	srv.runReport(logger, vals["query"][0], vals["start"][0], vals["end"][0], w)
 }
 
+// requestNamespace returns the namespace specified in the request's query
+// parameters, defaulting to the namespace the operator is running in.
+func (srv *server) requestNamespace(r *http.Request) string {
+	if namespace := r.FormValue("namespace"); namespace != "" {
+		return namespace
+	}
+	return srv.namespace
+}
+
 func checkForFields(fields []string, vals url.Values) error {
 	var missingFields []string
 	for _, f := range fields {
@@ -209,9 +275,14 @@ func checkForFields(fields []string, vals url.Values) error {
 	return nil
 }
 
-func (srv *server) getScheduledReport(logger log.FieldLogger, name, format string, w http.ResponseWriter, r *http.Request) {
+func (srv *server) getScheduledReport(logger log.FieldLogger, namespace, name, format string, w http.ResponseWriter, r *http.Request) {
+	w, audit := srv.auditor.startHTTP(w, r, "ScheduledReport", namespace, name, format)
+	defer audit.finish()
+	if !srv.authorizeResults(logger, w, r, resultsResourceScheduledReports, namespace) {
+		return
+	}
 	// Get the scheduledReport to make sure it's isn't failed
-	report, err := srv.scheduledReportLister.ScheduledReports(srv.namespace).Get(name)
+	report, err := srv.scheduledReportLister.ScheduledReports(namespace).Get(name)
 	if err != nil {
 		logger.WithError(err).Errorf("error getting scheduledReport: %v", err)
 		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error getting scheduledReport: %v", err)
@@ -265,8 +336,8 @@ func (srv *server) getScheduledReport(logger log.FieldLogger, name, format strin
 		logger.Debugf("mismatched columns, PrestoTable columns: %v, ReportGenerationQuery columns: %v", prestoColumns, queryPrestoColumns)
 	}
 
-	tableName := reportingutil.ScheduledReportTableName(name)
-	results, err := srv.reportResultsGetter.GetReportResults(tableName, prestoColumns)
+	tableName := prestoTable.Status.Parameters.Name
+	results, err := getReportResults(r.Context(), srv.reportResultsGetter, srv.resultsCache, "ScheduledReport", report.Namespace, report.Name, report.ResourceVersion, tableName, prestoColumns)
 	if err != nil {
 		logger.WithError(err).Errorf("failed to perform presto query")
 		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "failed to perform presto query (see operator logs for more details): %v", err)
@@ -279,11 +350,17 @@ func (srv *server) getScheduledReport(logger log.FieldLogger, name, format strin
 		return
 	}
 
+	audit.setRows(len(results))
 	writeResultsResponseV1(logger, format, reportQuery.Spec.Columns, results, w, r)
 }
-func (srv *server) getReport(logger log.FieldLogger, name, format string, useNewFormat bool, full bool, w http.ResponseWriter, r *http.Request) {
+func (srv *server) getReport(logger log.FieldLogger, namespace, name, format string, useNewFormat bool, full bool, w http.ResponseWriter, r *http.Request) {
+	w, audit := srv.auditor.startHTTP(w, r, "Report", namespace, name, format)
+	defer audit.finish()
+	if !srv.authorizeResults(logger, w, r, resultsResourceReports, namespace) {
+		return
+	}
 	// Get the current report to make sure it's in a finished state
-	report, err := srv.reportLister.Reports(srv.namespace).Get(name)
+	report, err := srv.reportLister.Reports(namespace).Get(name)
 	if err != nil {
 		code := http.StatusInternalServerError
 		if k8serrors.IsNotFound(err) {
@@ -349,8 +426,8 @@ func (srv *server) getReport(logger log.FieldLogger, name, format string, useNew
 		logger.Debugf("mismatched columns, PrestoTable columns: %v, ReportGenerationQuery columns: %v", prestoColumns, queryPrestoColumns)
 	}
 
-	tableName := reportingutil.ReportTableName(name)
-	results, err := srv.reportResultsGetter.GetReportResults(tableName, prestoColumns)
+	tableName := prestoTable.Status.Parameters.Name
+	results, err := getReportResults(r.Context(), srv.reportResultsGetter, srv.resultsCache, "Report", report.Namespace, report.Name, report.ResourceVersion, tableName, prestoColumns)
 	if err != nil {
 		logger.WithError(err).Errorf("failed to perform presto query")
 		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "failed to perform presto query (see operator logs for more details): %v", err)
@@ -363,13 +440,28 @@ func (srv *server) getReport(logger log.FieldLogger, name, format string, useNew
 		return
 	}
 
+	audit.setRows(len(results))
 	if useNewFormat {
-		writeResultsResponseV2(logger, full, format, reportQuery.Spec.Columns, results, w, r)
+		writeResultsResponseV2(logger, full, format, reportQuery.Spec.Columns, results, srv.currency, w, r)
 	} else {
 		writeResultsResponseV1(logger, format, reportQuery.Spec.Columns, results, w, r)
 	}
 }
 
+// getReportResults returns the results stored in the table of a finished
+// Report or ScheduledReport, using the results cache if possible.
+func getReportResults(ctx context.Context, getter prestostore.ReportResultsGetter, cache *reportResultsCache, kind, namespace, name, resourceVersion, tableName string, columns []presto.Column) ([]presto.Row, error) {
+	if results, ok := cache.get(kind, namespace, name, resourceVersion, tableName); ok {
+		return results, nil
+	}
+	results, err := getter.GetReportResults(ctx, tableName, columns)
+	if err != nil {
+		return nil, err
+	}
+	cache.add(kind, namespace, name, resourceVersion, tableName, results)
+	return results, nil
+}
+
 func writeResultsResponseAsCSV(logger log.FieldLogger, columns []api.ReportGenerationQueryColumn, results []presto.Row, w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/csv")
 	err := writeResultsAsCSV(columns, results, w, ',')
@@ -404,23 +496,10 @@ func writeResultsAsCSV(columns []api.ReportGenerationQueryColumn, results []pres
 			if !ok {
 				return fmt.Errorf("report results schema doesn't match expected schema, unexpected key: %q", key)
 			}
-			switch v := val.(type) {
-			case string:
-				vals[i] = v
-			case []byte:
-				vals[i] = string(v)
-			case uint, uint8, uint16, uint32, uint64, int, int8, int16, int32, int64:
-				vals[i] = fmt.Sprintf("%d", v)
-			case float32, float64, complex64, complex128:
-				vals[i] = fmt.Sprintf("%f", v)
-			case bool:
-				vals[i] = fmt.Sprintf("%t", v)
-			case time.Time:
-				vals[i] = v.String()
-			case nil:
-				vals[i] = ""
-			default:
-				return fmt.Errorf("error marshalling csv: unknown type %t for value %v", val, val)
+			var err error
+			vals[i], err = formatResultValue(val)
+			if err != nil {
+				return fmt.Errorf("error marshalling csv: %v", err)
 			}
 		}
 		err := csvWriter.Write(vals)
@@ -433,6 +512,29 @@ func writeResultsAsCSV(columns []api.ReportGenerationQueryColumn, results []pres
 	return csvWriter.Error()
 }
 
+// formatResultValue returns the string representation of a value in a
+// report's results, used by the CSV, tabular and HTML formats.
+func formatResultValue(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case uint, uint8, uint16, uint32, uint64, int, int8, int16, int32, int64:
+		return fmt.Sprintf("%d", v), nil
+	case float32, float64, complex64, complex128:
+		return fmt.Sprintf("%f", v), nil
+	case bool:
+		return fmt.Sprintf("%t", v), nil
+	case time.Time:
+		return v.String(), nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("unknown type %T for value %v", val, val)
+	}
+}
+
 func writeResultsResponseAsTabular(logger log.FieldLogger, columns []api.ReportGenerationQueryColumn, results []presto.Row, w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain")
 	var padding int = 2
@@ -477,6 +579,8 @@ func writeResultsResponse(logger log.FieldLogger, format string, columns []api.R
 		writeResultsResponseAsCSV(logger, columns, results, w, r)
 	case "tab", "tabular":
 		writeResultsResponseAsTabular(logger, columns, results, w, r)
+	case "html":
+		writeResultsResponseAsHTML(logger, columns, results, w, r)
 	}
 }
 
@@ -493,10 +597,12 @@ type ReportResultValues struct {
 	Value       interface{} `json:"value"`
 	TableHidden bool        `json:"tableHidden"`
 	Unit        string      `json:"unit,omitempty"`
+	// Currency is the currency code of columns whose unit is currency.
+	Currency string `json:"currency,omitempty"`
 }
 
 // convertsToGetReportResults converts Rows returned from `presto.ExecuteSelect` into a GetReportResults
-func convertsToGetReportResults(input []presto.Row, columns []api.ReportGenerationQueryColumn) GetReportResults {
+func convertsToGetReportResults(input []presto.Row, columns []api.ReportGenerationQueryColumn, currency reporting.Currency) GetReportResults {
 	results := GetReportResults{}
 	columnsMap := make(map[string]api.ReportGenerationQueryColumn)
 	for _, column := range columns {
@@ -511,6 +617,9 @@ func convertsToGetReportResults(input []presto.Row, columns []api.ReportGenerati
 				TableHidden: columnsMap[columnName].TableHidden,
 				Unit:        columnsMap[columnName].Unit,
 			}
+			if resultsValue.Unit == reporting.CurrencyColumnUnit {
+				resultsValue.Currency = currency.OrDefault().Code
+			}
 			valSlice.Values = append(valSlice.Values, resultsValue)
 		}
 		results.Results = append(results.Results, valSlice)
@@ -545,9 +654,9 @@ func writeResultsResponseV1(logger log.FieldLogger, format string, columns []api
 	writeResultsResponse(logger, format, filteredColumns, results, w, r)
 }
 
-func writeResultsResponseV2(logger log.FieldLogger, full bool, format string, columns []api.ReportGenerationQueryColumn, results []presto.Row, w http.ResponseWriter, r *http.Request) {
+func writeResultsResponseV2(logger log.FieldLogger, full bool, format string, columns []api.ReportGenerationQueryColumn, results []presto.Row, currency reporting.Currency, w http.ResponseWriter, r *http.Request) {
 	format = strings.ToLower(format)
-	isTableFormat := format == "csv" || format == "tab" || format == "tabular"
+	isTableFormat := format == "csv" || format == "tab" || format == "tabular" || format == "html"
 	columnsMap := make(map[string]api.ReportGenerationQueryColumn)
 	var filteredColumns []api.ReportGenerationQueryColumn
 
@@ -578,10 +687,23 @@ func writeResultsResponseV2(logger log.FieldLogger, full bool, format string, co
 	}
 
 	if format == "json" {
-		writeResponseAsJSON(logger, w, http.StatusOK, convertsToGetReportResults(results, filteredColumns))
+		writeResponseAsJSON(logger, w, http.StatusOK, convertsToGetReportResults(results, filteredColumns, currency))
 		return
 	}
-	writeResultsResponse(logger, format, filteredColumns, results, w, r)
+	writeResultsResponse(logger, format, currencyColumnUnits(filteredColumns, currency), results, w, r)
+}
+
+// currencyColumnUnits returns a copy of columns with the unit of cost
+// columns replaced by the currency code, for formats which display units.
+func currencyColumnUnits(columns []api.ReportGenerationQueryColumn, currency reporting.Currency) []api.ReportGenerationQueryColumn {
+	converted := make([]api.ReportGenerationQueryColumn, len(columns))
+	for i, column := range columns {
+		if column.Unit == reporting.CurrencyColumnUnit {
+			column.Unit = currency.OrDefault().Code
+		}
+		converted[i] = column
+	}
+	return converted
 }
 
 func (srv *server) runReport(logger log.FieldLogger, query, start, end string, w http.ResponseWriter) {
@@ -600,6 +722,10 @@ type CollectPromsumDataResponse struct {
 
 func (srv *server) collectPromsumDataHandler(w http.ResponseWriter, r *http.Request) {
 	logger := newRequestLogger(srv.logger, r, srv.rand)
+	// data is collected for ReportDataSources in any namespace
+	if !srv.authorizer.authorizeRequest(logger, w, r, "update", resultsResourceReportDataSources, "") {
+		return
+	}
 
 	decoder := json.NewDecoder(r.Body)
 	var req CollectPromsumDataRequest
@@ -631,6 +757,9 @@ func (srv *server) storePromsumDataHandler(w http.ResponseWriter, r *http.Reques
 	logger := newRequestLogger(srv.logger, r, srv.rand)
 
 	name := chi.URLParam(r, "datasourceName")
+	if !srv.authorizer.authorizeRequest(logger, w, r, "update", resultsResourceReportDataSources, srv.requestNamespace(r)) {
+		return
+	}
 
 	decoder := json.NewDecoder(r.Body)
 	var req StorePromsumDataRequest
@@ -640,7 +769,18 @@ func (srv *server) storePromsumDataHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err = srv.prometheusMetricsRepo.StorePrometheusMetrics(context.Background(), reportingutil.DataSourceTableName(name), []*prestostore.PrometheusMetric(req))
+	tableName, err := srv.getDataSourceTableName(srv.requestNamespace(r), name)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "unable to get table for ReportDataSource %s: %v", name, err)
+		return
+	}
+	dataSource, err := srv.reportDataSourceLister.ReportDataSources(srv.requestNamespace(r)).Get(name)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "unable to get ReportDataSource %s: %v", name, err)
+		return
+	}
+
+	err = srv.prometheusMetricsRepo.StorePrometheusMetrics(context.Background(), tableName, dataSourcePartitionGranularity(dataSource), []*prestostore.PrometheusMetric(req))
 	if err != nil {
 		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to store promsum metrics: %v", err)
 		return
@@ -659,7 +799,17 @@ func (srv *server) fetchPromsumDataHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	datasourceTable := reportingutil.DataSourceTableName(name)
+	namespace := srv.requestNamespace(r)
+	w, audit := srv.auditor.startHTTP(w, r, "ReportDataSource", namespace, name, "json")
+	defer audit.finish()
+	if !srv.authorizeResults(logger, w, r, resultsResourceReportDataSources, namespace) {
+		return
+	}
+	datasourceTable, err := srv.getDataSourceTableName(namespace, name)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "unable to get table for ReportDataSource %s: %v", name, err)
+		return
+	}
 	start := r.Form.Get("start")
 	end := r.Form.Get("end")
 	var startTime, endTime time.Time
@@ -683,5 +833,160 @@ func (srv *server) fetchPromsumDataHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	audit.setRows(len(results))
 	writeResponseAsJSON(logger, w, http.StatusOK, results)
 }
+
+// getDataSourceTableName returns the name of the table for the
+// ReportDataSource using it's PrestoTable.
+func (srv *server) getDataSourceTableName(namespace, name string) (string, error) {
+	prestoTable, err := srv.prestoTableLister.PrestoTables(namespace).Get(reportingutil.PrestoTableResourceNameFromKind("reportdatasource", name))
+	if err != nil {
+		return "", err
+	}
+	return prestoTable.Status.Parameters.Name, nil
+}
+
+type QueryDiffResponse struct {
+	From    string                          `json:"from"`
+	To      string                          `json:"to"`
+	Queries []reporting.GenerationQueryDiff `json:"queries"`
+}
+
+// reportQueryDiffHandler compares the ReportGenerationQueries used to
+// generate two Reports.
+func (srv *server) reportQueryDiffHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if r.Method != "GET" {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "Not found")
+		return
+	}
+	err := r.ParseForm()
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "couldn't parse URL query params: %v", err)
+		return
+	}
+	err = checkForFields([]string{"from", "to"}, r.Form)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	namespace := srv.requestNamespace(r)
+	if !srv.authorizeResults(logger, w, r, resultsResourceReports, namespace) {
+		return
+	}
+	var versions [2][]api.ReportGenerationQueryVersion
+	for i, name := range []string{r.Form.Get("from"), r.Form.Get("to")} {
+		report, err := srv.reportLister.Reports(namespace).Get(name)
+		if err != nil {
+			code := http.StatusInternalServerError
+			if k8serrors.IsNotFound(err) {
+				code = http.StatusNotFound
+			}
+			writeErrorResponse(logger, w, r, code, "error getting report: %v", err)
+			return
+		}
+		versions[i] = report.Status.GenerationQueryVersions
+	}
+
+	srv.addCurrentQueryTexts(namespace, versions)
+	diffs, err := reporting.DiffGenerationQueryVersions(versions[0], versions[1], srv.queryTexts)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error comparing queries: %v", err)
+		return
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, QueryDiffResponse{
+		From:    r.Form.Get("from"),
+		To:      r.Form.Get("to"),
+		Queries: diffs,
+	})
+}
+
+// addCurrentQueryTexts records the text of the current version of each
+// ReportGenerationQuery in versions, so the latest version of a query can be
+// diffed even if no report has used it since reporting-operator started.
+func (srv *server) addCurrentQueryTexts(namespace string, versions [2][]api.ReportGenerationQueryVersion) {
+	if srv.queryTexts == nil {
+		return
+	}
+	for _, runVersions := range versions {
+		for _, version := range runVersions {
+			query, err := srv.reportGenerationQuerieLister.ReportGenerationQueries(namespace).Get(version.Name)
+			if err == nil {
+				srv.queryTexts.Add(query)
+			}
+		}
+	}
+}
+
+// scheduledReportQueryDiffHandler compares the ReportGenerationQueries used
+// by two runs of a ScheduledReport. Runs are specified by their index in
+// status.runHistory, where 0 is the most recent run, and default to
+// comparing the previous run to the most recent run.
+func (srv *server) scheduledReportQueryDiffHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if r.Method != "GET" {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "Not found")
+		return
+	}
+	err := r.ParseForm()
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "couldn't parse URL query params: %v", err)
+		return
+	}
+	err = checkForFields([]string{"name"}, r.Form)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	runs := [2]int{1, 0}
+	for i, param := range []string{"from", "to"} {
+		if val := r.Form.Get(param); val != "" {
+			runs[i], err = strconv.Atoi(val)
+			if err != nil || runs[i] < 0 {
+				writeErrorResponse(logger, w, r, http.StatusBadRequest, "%s must be a non-negative integer", param)
+				return
+			}
+		}
+	}
+
+	namespace := srv.requestNamespace(r)
+	if !srv.authorizeResults(logger, w, r, resultsResourceScheduledReports, namespace) {
+		return
+	}
+	report, err := srv.scheduledReportLister.ScheduledReports(namespace).Get(r.Form.Get("name"))
+	if err != nil {
+		code := http.StatusInternalServerError
+		if k8serrors.IsNotFound(err) {
+			code = http.StatusNotFound
+		}
+		writeErrorResponse(logger, w, r, code, "error getting scheduledReport: %v", err)
+		return
+	}
+
+	var versions [2][]api.ReportGenerationQueryVersion
+	var periods [2]string
+	for i, run := range runs {
+		if run >= len(report.Status.RunHistory) {
+			writeErrorResponse(logger, w, r, http.StatusNotFound, "scheduledReport only has %d runs in it's history, run %d does not exist", len(report.Status.RunHistory), run)
+			return
+		}
+		history := report.Status.RunHistory[run]
+		versions[i] = history.GenerationQueryVersions
+		periods[i] = fmt.Sprintf("%s-%s", history.PeriodStart.Format(time.RFC3339), history.PeriodEnd.Format(time.RFC3339))
+	}
+
+	srv.addCurrentQueryTexts(namespace, versions)
+	diffs, err := reporting.DiffGenerationQueryVersions(versions[0], versions[1], srv.queryTexts)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error comparing queries: %v", err)
+		return
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, QueryDiffResponse{
+		From:    periods[0],
+		To:      periods[1],
+		Queries: diffs,
+	})
+}
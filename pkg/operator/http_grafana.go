@@ -0,0 +1,608 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+
+	api "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// APIGrafanaPrefix is the URL Grafana's SimpleJSON (or JSON) datasource
+// plugin should be configured with.
+const APIGrafanaPrefix = "/api/v1/grafana"
+
+// Grafana targets identify what a panel is querying, and have the form
+// <kind>/<namespace>/<name>, for example report/metering/namespace-cpu-request.
+const (
+	grafanaTargetReport          = "report"
+	grafanaTargetScheduledReport = "scheduledreport"
+	grafanaTargetDataSource      = "datasource"
+)
+
+type grafanaTarget struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func parseGrafanaTarget(target string) (grafanaTarget, error) {
+	parts := strings.Split(target, "/")
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return grafanaTarget{}, fmt.Errorf("invalid target %q, expected <kind>/<namespace>/<name>", target)
+	}
+	switch parts[0] {
+	case grafanaTargetReport, grafanaTargetScheduledReport, grafanaTargetDataSource:
+	default:
+		return grafanaTarget{}, fmt.Errorf("invalid target %q, kind must be one of: %s, %s or %s", target, grafanaTargetDataSource, grafanaTargetReport, grafanaTargetScheduledReport)
+	}
+	return grafanaTarget{Kind: parts[0], Namespace: parts[1], Name: parts[2]}, nil
+}
+
+func (t grafanaTarget) String() string {
+	return t.Kind + "/" + t.Namespace + "/" + t.Name
+}
+
+// kind returns the kind of resource the target queries.
+func (t grafanaTarget) kind() string {
+	switch t.Kind {
+	case grafanaTargetReport:
+		return "Report"
+	case grafanaTargetScheduledReport:
+		return "ScheduledReport"
+	default:
+		return "ReportDataSource"
+	}
+}
+
+// resource returns the resource the caller needs access to in the target's
+// namespace to query it.
+func (t grafanaTarget) resource() string {
+	switch t.Kind {
+	case grafanaTargetReport:
+		return resultsResourceReports
+	case grafanaTargetScheduledReport:
+		return resultsResourceScheduledReports
+	default:
+		return resultsResourceReportDataSources
+	}
+}
+
+type grafanaRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+func (r grafanaRange) contains(t time.Time) bool {
+	return !t.Before(r.From) && !t.After(r.To)
+}
+
+type GrafanaSearchRequest struct {
+	Target string `json:"target"`
+}
+
+type GrafanaQueryRequest struct {
+	Range   grafanaRange `json:"range"`
+	Targets []struct {
+		Target string `json:"target"`
+		// Type is either timeserie (the default) or table.
+		Type string `json:"type"`
+	} `json:"targets"`
+}
+
+type GrafanaAnnotationsRequest struct {
+	Range      grafanaRange `json:"range"`
+	Annotation struct {
+		Name string `json:"name"`
+		// Query optionally restricts the annotations returned to the runs of
+		// a single report or scheduled report target.
+		Query string `json:"query"`
+	} `json:"annotation"`
+}
+
+type grafanaTimeSeries struct {
+	Target string `json:"target"`
+	// Datapoints are pairs of values and timestamps in milliseconds.
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+type grafanaTable struct {
+	Type    string               `json:"type"`
+	Columns []grafanaTableColumn `json:"columns"`
+	Rows    [][]interface{}      `json:"rows"`
+}
+
+type grafanaTableColumn struct {
+	Text string `json:"text"`
+	Type string `json:"type,omitempty"`
+}
+
+type grafanaAnnotation struct {
+	Annotation interface{} `json:"annotation"`
+	Time       int64       `json:"time"`
+	Title      string      `json:"title"`
+	Text       string      `json:"text,omitempty"`
+	Tags       []string    `json:"tags,omitempty"`
+}
+
+func grafanaTimestamp(t time.Time) float64 {
+	return float64(t.UnixNano() / int64(time.Millisecond))
+}
+
+// grafanaTestHandler is requested by Grafana when testing the datasource.
+func (srv *server) grafanaTestHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (srv *server) grafanaSearchHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	var req GrafanaSearchRequest
+	if !decodeGrafanaRequest(logger, w, r, &req) {
+		return
+	}
+	canRead, ok := srv.resultsFilter(logger, w, r)
+	if !ok {
+		return
+	}
+
+	var targets []string
+	reports, err := srv.reportLister.List(labels.Everything())
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error listing reports: %v", err)
+		return
+	}
+	for _, report := range reports {
+		if canRead(resultsResourceReports, report.Namespace) {
+			targets = append(targets, grafanaTarget{grafanaTargetReport, report.Namespace, report.Name}.String())
+		}
+	}
+	scheduledReports, err := srv.scheduledReportLister.List(labels.Everything())
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error listing scheduledReports: %v", err)
+		return
+	}
+	for _, report := range scheduledReports {
+		if canRead(resultsResourceScheduledReports, report.Namespace) {
+			targets = append(targets, grafanaTarget{grafanaTargetScheduledReport, report.Namespace, report.Name}.String())
+		}
+	}
+	dataSources, err := srv.reportDataSourceLister.List(labels.Everything())
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error listing reportDataSources: %v", err)
+		return
+	}
+	for _, dataSource := range dataSources {
+		// only Prometheus metrics can be queried over a time range
+		if dataSource.Spec.Promsum != nil && canRead(resultsResourceReportDataSources, dataSource.Namespace) {
+			targets = append(targets, grafanaTarget{grafanaTargetDataSource, dataSource.Namespace, dataSource.Name}.String())
+		}
+	}
+
+	matching := make([]string, 0, len(targets))
+	for _, target := range targets {
+		if strings.Contains(target, req.Target) {
+			matching = append(matching, target)
+		}
+	}
+	sort.Strings(matching)
+	writeResponseAsJSON(logger, w, http.StatusOK, matching)
+}
+
+func (srv *server) grafanaQueryHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	var req GrafanaQueryRequest
+	if !decodeGrafanaRequest(logger, w, r, &req) {
+		return
+	}
+
+	// every target queried is recorded with the status of the response
+	response := &statusRecorder{ResponseWriter: w}
+	w = response
+	var audits []*resultsAuditEvent
+	defer func() {
+		for _, audit := range audits {
+			audit.finish()
+		}
+	}()
+
+	resp := make([]interface{}, 0, len(req.Targets))
+	for _, queryTarget := range req.Targets {
+		target, err := parseGrafanaTarget(queryTarget.Target)
+		if err != nil {
+			writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+			return
+		}
+		asTable := queryTarget.Type == "table"
+		audit := srv.auditor.httpEvent(response, r, target.kind(), target.Namespace, target.Name, "grafana")
+		if audit != nil {
+			audits = append(audits, audit)
+		}
+		if !srv.authorizeResults(logger, w, r, target.resource(), target.Namespace) {
+			return
+		}
+
+		if target.Kind == grafanaTargetDataSource {
+			metrics, err := srv.getGrafanaDataSourceMetrics(target, req.Range)
+			if err != nil {
+				writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to query %s: %v", target, err)
+				return
+			}
+			audit.setRows(len(metrics.timestamps))
+			if asTable {
+				resp = append(resp, metrics.table())
+			} else {
+				for _, series := range metrics.timeSeries(target.String()) {
+					resp = append(resp, series)
+				}
+			}
+			continue
+		}
+
+		columns, results, err := srv.getGrafanaReportResults(r.Context(), target)
+		if err != nil {
+			writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to query %s: %v", target, err)
+			return
+		}
+		audit.setRows(len(results))
+		if asTable {
+			resp = append(resp, reportResultsGrafanaTable(columns, results))
+			continue
+		}
+		series, err := reportResultsGrafanaTimeSeries(target.String(), columns, results, req.Range)
+		if err != nil {
+			writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to query %s: %v", target, err)
+			return
+		}
+		for _, s := range series {
+			resp = append(resp, s)
+		}
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, resp)
+}
+
+// grafanaAnnotationsHandler returns an annotation for the end of the
+// reporting period of each finished Report and of each run of a
+// ScheduledReport.
+func (srv *server) grafanaAnnotationsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	var req GrafanaAnnotationsRequest
+	if !decodeGrafanaRequest(logger, w, r, &req) {
+		return
+	}
+	var only *grafanaTarget
+	if req.Annotation.Query != "" {
+		target, err := parseGrafanaTarget(req.Annotation.Query)
+		if err != nil {
+			writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+			return
+		}
+		only = &target
+	}
+	canRead, ok := srv.resultsFilter(logger, w, r)
+	if !ok {
+		return
+	}
+	matches := func(target grafanaTarget) bool {
+		return (only == nil || *only == target) && canRead(target.resource(), target.Namespace)
+	}
+
+	annotations := []grafanaAnnotation{}
+	reports, err := srv.reportLister.List(labels.Everything())
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error listing reports: %v", err)
+		return
+	}
+	for _, report := range reports {
+		target := grafanaTarget{grafanaTargetReport, report.Namespace, report.Name}
+		if !matches(target) || report.Status.Phase != api.ReportPhaseFinished || report.Spec.ReportingEnd == nil {
+			continue
+		}
+		if end := report.Spec.ReportingEnd.Time; req.Range.contains(end) {
+			annotations = append(annotations, grafanaAnnotation{
+				Annotation: req.Annotation,
+				Time:       int64(grafanaTimestamp(end)),
+				Title:      fmt.Sprintf("Report %s finished", report.Name),
+				Tags:       []string{target.Kind, target.Namespace},
+			})
+		}
+	}
+	scheduledReports, err := srv.scheduledReportLister.List(labels.Everything())
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error listing scheduledReports: %v", err)
+		return
+	}
+	for _, report := range scheduledReports {
+		target := grafanaTarget{grafanaTargetScheduledReport, report.Namespace, report.Name}
+		if !matches(target) {
+			continue
+		}
+		for _, run := range report.Status.RunHistory {
+			if !req.Range.contains(run.PeriodEnd.Time) {
+				continue
+			}
+			annotations = append(annotations, grafanaAnnotation{
+				Annotation: req.Annotation,
+				Time:       int64(grafanaTimestamp(run.PeriodEnd.Time)),
+				Title:      fmt.Sprintf("ScheduledReport %s ran", report.Name),
+				Text:       fmt.Sprintf("Reporting period %s to %s", run.PeriodStart.UTC().Format(time.RFC3339), run.PeriodEnd.UTC().Format(time.RFC3339)),
+				Tags:       []string{target.Kind, target.Namespace},
+			})
+		}
+	}
+	sort.Slice(annotations, func(i, j int) bool {
+		return annotations[i].Time < annotations[j].Time
+	})
+	writeResponseAsJSON(logger, w, http.StatusOK, annotations)
+}
+
+func decodeGrafanaRequest(logger log.FieldLogger, w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if r.Method != "POST" {
+		writeErrorResponse(logger, w, r, http.StatusMethodNotAllowed, "only POST is supported")
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to decode request as JSON: %v", err)
+		return false
+	}
+	return true
+}
+
+// getGrafanaReportResults returns the results of a finished Report, or of
+// a ScheduledReport which has run at least once.
+func (srv *server) getGrafanaReportResults(ctx context.Context, target grafanaTarget) ([]api.ReportGenerationQueryColumn, []presto.Row, error) {
+	var kind, queryName, resourceVersion string
+	switch target.Kind {
+	case grafanaTargetReport:
+		report, err := srv.reportLister.Reports(target.Namespace).Get(target.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if report.Status.Phase != api.ReportPhaseFinished {
+			return nil, nil, ErrReportIsRunning
+		}
+		kind, queryName, resourceVersion = "Report", report.Spec.GenerationQueryName, report.ResourceVersion
+	case grafanaTargetScheduledReport:
+		report, err := srv.scheduledReportLister.ScheduledReports(target.Namespace).Get(target.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(report.Status.RunHistory) == 0 {
+			return nil, nil, fmt.Errorf("the scheduledReport has not run yet")
+		}
+		kind, queryName, resourceVersion = "ScheduledReport", report.Spec.GenerationQueryName, report.ResourceVersion
+	}
+
+	reportQuery, err := srv.reportGenerationQuerieLister.ReportGenerationQueries(target.Namespace).Get(queryName)
+	if err != nil {
+		return nil, nil, err
+	}
+	prestoTable, err := srv.prestoTableLister.PrestoTables(target.Namespace).Get(reportingutil.PrestoTableResourceNameFromKind(target.Kind, target.Name))
+	if err != nil {
+		return nil, nil, err
+	}
+	prestoColumns, err := reportingutil.HiveColumnsToPrestoColumns(prestoTable.Status.Parameters.Columns)
+	if err != nil {
+		return nil, nil, err
+	}
+	results, err := getReportResults(ctx, srv.reportResultsGetter, srv.resultsCache, kind, target.Namespace, target.Name, resourceVersion, prestoTable.Status.Parameters.Name, prestoColumns)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reportQuery.Spec.Columns, results, nil
+}
+
+func reportResultsGrafanaTable(columns []api.ReportGenerationQueryColumn, results []presto.Row) grafanaTable {
+	table := grafanaTable{
+		Type:    "table",
+		Columns: make([]grafanaTableColumn, len(columns)),
+		Rows:    make([][]interface{}, len(results)),
+	}
+	for i, column := range columns {
+		table.Columns[i] = grafanaTableColumn{Text: column.Name}
+		switch {
+		case column.Type == "timestamp":
+			table.Columns[i].Type = "time"
+		case isNumericColumnType(column.Type):
+			table.Columns[i].Type = "number"
+		default:
+			table.Columns[i].Type = "string"
+		}
+	}
+	for i, row := range results {
+		table.Rows[i] = make([]interface{}, len(columns))
+		for j, column := range columns {
+			val := row[column.Name]
+			if t, ok := val.(time.Time); ok {
+				val = grafanaTimestamp(t)
+			}
+			table.Rows[i][j] = val
+		}
+	}
+	return table
+}
+
+// reportResultsGrafanaTimeSeries converts report results into a time series
+// for each numeric column, using the first timestamp column as the time of
+// each row. Rows are split into separate series by the values of their
+// string columns, so a report containing a row per namespace returns a
+// series per namespace.
+func reportResultsGrafanaTimeSeries(target string, columns []api.ReportGenerationQueryColumn, results []presto.Row, timeRange grafanaRange) ([]grafanaTimeSeries, error) {
+	timestampColumn := ""
+	var valueColumns, labelColumns []string
+	for _, column := range columns {
+		switch {
+		case column.Type == "timestamp":
+			if timestampColumn == "" {
+				timestampColumn = column.Name
+			}
+		case isNumericColumnType(column.Type):
+			valueColumns = append(valueColumns, column.Name)
+		case column.Type == "string" || column.Type == "varchar":
+			labelColumns = append(labelColumns, column.Name)
+		}
+	}
+	if timestampColumn == "" {
+		return nil, fmt.Errorf("results have no timestamp column, use a table panel instead")
+	}
+
+	seriesByName := make(map[string]*grafanaTimeSeries)
+	for _, row := range results {
+		ts, ok := row[timestampColumn].(time.Time)
+		if !ok || !timeRange.contains(ts) {
+			continue
+		}
+		rowLabels := make(map[string]string, len(labelColumns))
+		for _, column := range labelColumns {
+			rowLabels[column], _ = row[column].(string)
+		}
+		for _, column := range valueColumns {
+			value, ok := numericResultValue(row[column])
+			if !ok {
+				continue
+			}
+			name := grafanaSeriesName(target+" "+column, rowLabels)
+			series, exists := seriesByName[name]
+			if !exists {
+				series = &grafanaTimeSeries{Target: name}
+				seriesByName[name] = series
+			}
+			series.Datapoints = append(series.Datapoints, [2]float64{value, grafanaTimestamp(ts)})
+		}
+	}
+	return sortedGrafanaTimeSeries(seriesByName), nil
+}
+
+// grafanaDataSourceMetrics are the Prometheus metrics stored by a
+// ReportDataSource within a time range.
+type grafanaDataSourceMetrics struct {
+	timestamps []time.Time
+	amounts    []float64
+	labels     []map[string]string
+}
+
+func (srv *server) getGrafanaDataSourceMetrics(target grafanaTarget, timeRange grafanaRange) (*grafanaDataSourceMetrics, error) {
+	dataSource, err := srv.reportDataSourceLister.ReportDataSources(target.Namespace).Get(target.Name)
+	if err != nil {
+		return nil, err
+	}
+	if dataSource.Spec.Promsum == nil {
+		return nil, fmt.Errorf("only Prometheus metric ReportDataSources can be queried")
+	}
+	if dataSource.Status.TableName == "" {
+		return nil, fmt.Errorf("the reportDataSource's table has not been created yet")
+	}
+	metrics, err := srv.prometheusMetricsRepo.GetPrometheusMetrics(dataSource.Status.TableName, timeRange.From, timeRange.To)
+	if err != nil {
+		return nil, err
+	}
+	result := &grafanaDataSourceMetrics{}
+	for _, metric := range metrics {
+		result.timestamps = append(result.timestamps, metric.Timestamp)
+		result.amounts = append(result.amounts, metric.Amount)
+		result.labels = append(result.labels, metric.Labels)
+	}
+	return result, nil
+}
+
+func (m *grafanaDataSourceMetrics) timeSeries(target string) []grafanaTimeSeries {
+	seriesByName := make(map[string]*grafanaTimeSeries)
+	for i := range m.timestamps {
+		name := grafanaSeriesName(target, m.labels[i])
+		series, exists := seriesByName[name]
+		if !exists {
+			series = &grafanaTimeSeries{Target: name}
+			seriesByName[name] = series
+		}
+		series.Datapoints = append(series.Datapoints, [2]float64{m.amounts[i], grafanaTimestamp(m.timestamps[i])})
+	}
+	return sortedGrafanaTimeSeries(seriesByName)
+}
+
+func (m *grafanaDataSourceMetrics) table() grafanaTable {
+	table := grafanaTable{
+		Type: "table",
+		Columns: []grafanaTableColumn{
+			{Text: "timestamp", Type: "time"},
+			{Text: "labels", Type: "string"},
+			{Text: "amount", Type: "number"},
+		},
+		Rows: make([][]interface{}, len(m.timestamps)),
+	}
+	for i := range m.timestamps {
+		table.Rows[i] = []interface{}{grafanaTimestamp(m.timestamps[i]), grafanaSeriesName("", m.labels[i]), m.amounts[i]}
+	}
+	return table
+}
+
+// grafanaSeriesName formats a series name in the same way as Prometheus,
+// for example name{namespace="default"}.
+func grafanaSeriesName(name string, seriesLabels map[string]string) string {
+	if len(seriesLabels) == 0 {
+		return name
+	}
+	keys := make([]string, 0, len(seriesLabels))
+	for key := range seriesLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = fmt.Sprintf("%s=%q", key, seriesLabels[key])
+	}
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+func sortedGrafanaTimeSeries(seriesByName map[string]*grafanaTimeSeries) []grafanaTimeSeries {
+	series := make([]grafanaTimeSeries, 0, len(seriesByName))
+	for _, s := range seriesByName {
+		sort.Slice(s.Datapoints, func(i, j int) bool {
+			return s.Datapoints[i][1] < s.Datapoints[j][1]
+		})
+		series = append(series, *s)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Target < series[j].Target
+	})
+	return series
+}
+
+// numericResultValue converts a numeric value in a report's results to a
+// float64.
+func numericResultValue(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
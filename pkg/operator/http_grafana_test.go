@@ -0,0 +1,56 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func TestParseGrafanaTarget(t *testing.T) {
+	target, err := parseGrafanaTarget("scheduledreport/metering/daily-cpu")
+	require.NoError(t, err)
+	assert.Equal(t, grafanaTarget{Kind: "scheduledreport", Namespace: "metering", Name: "daily-cpu"}, target)
+	assert.Equal(t, "scheduledreport/metering/daily-cpu", target.String())
+
+	for _, invalid := range []string{"", "report/daily-cpu", "report//daily-cpu", "table/metering/daily-cpu"} {
+		_, err := parseGrafanaTarget(invalid)
+		assert.Error(t, err, "expected target %q to be invalid", invalid)
+	}
+}
+
+func TestReportResultsGrafanaTimeSeries(t *testing.T) {
+	day1 := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	columns := []api.ReportGenerationQueryColumn{
+		{Name: "period_start", Type: "timestamp"},
+		{Name: "namespace", Type: "varchar"},
+		{Name: "cpu_core_seconds", Type: "double"},
+	}
+	results := []presto.Row{
+		{"period_start": day2, "namespace": "team-a", "cpu_core_seconds": 20.0},
+		{"period_start": day1, "namespace": "team-a", "cpu_core_seconds": 10.0},
+		{"period_start": day1, "namespace": "team-b", "cpu_core_seconds": 5.0},
+		{"period_start": day1.AddDate(0, 0, -1), "namespace": "team-b", "cpu_core_seconds": 1.0},
+	}
+
+	series, err := reportResultsGrafanaTimeSeries("report/metering/cpu", columns, results, grafanaRange{From: day1, To: day2})
+	require.NoError(t, err)
+	assert.Equal(t, []grafanaTimeSeries{
+		{
+			Target:     `report/metering/cpu cpu_core_seconds{namespace="team-a"}`,
+			Datapoints: [][2]float64{{10, grafanaTimestamp(day1)}, {20, grafanaTimestamp(day2)}},
+		},
+		{
+			Target:     `report/metering/cpu cpu_core_seconds{namespace="team-b"}`,
+			Datapoints: [][2]float64{{5, grafanaTimestamp(day1)}},
+		},
+	}, series)
+
+	_, err = reportResultsGrafanaTimeSeries("report/metering/cpu", columns[1:], nil, grafanaRange{From: day1, To: day2})
+	assert.Error(t, err, "expected an error when the results have no timestamp column")
+}
@@ -0,0 +1,152 @@
+package operator
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+
+	api "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// resultsHTMLTemplate renders report results as a standalone page, which
+// can be shared as a link and viewed in a browser. Clicking a column's
+// header sorts the table by that column, numerically if every value in the
+// column is a number.
+var resultsHTMLTemplate = template.Must(template.New("results").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #333; }
+h1 { font-size: 1.5em; font-weight: normal; }
+table { border-collapse: collapse; font-size: 0.9em; }
+th, td { padding: 0.4em 0.8em; border-bottom: 1px solid #ddd; text-align: left; white-space: nowrap; }
+th { background: #f5f5f5; cursor: pointer; user-select: none; }
+th .unit { color: #888; font-weight: normal; }
+th.asc::after { content: " \25B2"; }
+th.desc::after { content: " \25BC"; }
+tr:hover td { background: #fafafa; }
+td.number { text-align: right; font-variant-numeric: tabular-nums; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<p>{{ len .Rows }} rows</p>
+<table id="results">
+<thead>
+<tr>{{ range .Columns }}<th>{{ .Name }}{{ if .Unit }} <span class="unit">({{ .Unit }})</span>{{ end }}</th>{{ end }}</tr>
+</thead>
+<tbody>
+{{- range .Rows }}
+<tr>{{ range . }}<td{{ if .Number }} class="number"{{ end }}>{{ .Value }}</td>{{ end }}</tr>
+{{- end }}
+</tbody>
+</table>
+<script>
+(function() {
+  var table = document.getElementById("results");
+  var headers = table.tHead.rows[0].cells;
+  for (var i = 0; i < headers.length; i++) {
+    headers[i].addEventListener("click", sortBy.bind(null, i));
+  }
+  function sortBy(column) {
+    var header = headers[column];
+    var ascending = !header.classList.contains("asc");
+    for (var i = 0; i < headers.length; i++) {
+      headers[i].classList.remove("asc", "desc");
+    }
+    header.classList.add(ascending ? "asc" : "desc");
+    var body = table.tBodies[0];
+    var rows = Array.prototype.slice.call(body.rows);
+    var numeric = rows.every(function(row) {
+      var text = row.cells[column].textContent;
+      return text === "" || !isNaN(Number(text));
+    });
+    rows.sort(function(a, b) {
+      var x = a.cells[column].textContent, y = b.cells[column].textContent;
+      var result = numeric ? Number(x) - Number(y) : x.localeCompare(y);
+      return ascending ? result : -result;
+    });
+    rows.forEach(function(row) { body.appendChild(row); });
+  }
+})();
+</script>
+</body>
+</html>
+`))
+
+type resultsHTMLPage struct {
+	Title   string
+	Columns []api.ReportGenerationQueryColumn
+	Rows    [][]resultsHTMLValue
+}
+
+type resultsHTMLValue struct {
+	Value  string
+	Number bool
+}
+
+func writeResultsResponseAsHTML(logger log.FieldLogger, columns []api.ReportGenerationQueryColumn, results []presto.Row, w http.ResponseWriter, r *http.Request) {
+	page := resultsHTMLPage{
+		Title:   resultsHTMLTitle(r),
+		Columns: columns,
+		Rows:    make([][]resultsHTMLValue, len(results)),
+	}
+	for i, row := range results {
+		page.Rows[i] = make([]resultsHTMLValue, len(columns))
+		for j, column := range columns {
+			val, ok := row[column.Name]
+			if !ok {
+				writeErrorResponse(logger, w, r, http.StatusInternalServerError, "report results schema doesn't match expected schema, unexpected key: %q", column.Name)
+				return
+			}
+			str, err := formatResultValue(val)
+			if err != nil {
+				writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error rendering html: %v", err)
+				return
+			}
+			page.Rows[i][j] = resultsHTMLValue{Value: str, Number: isNumericResultValue(val)}
+		}
+	}
+
+	// render into a buffer first, so errors can still be returned as JSON
+	var buf bytes.Buffer
+	if err := resultsHTMLTemplate.Execute(&buf, page); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error rendering html: %v", err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := buf.WriteTo(w); err != nil {
+		logger.WithError(err).Error("failed writing HTTP response")
+	}
+}
+
+// resultsHTMLTitle returns the name of the report being requested, from the
+// URL of either the v1 or v2 API.
+func resultsHTMLTitle(r *http.Request) string {
+	var name string
+	if rctx, ok := r.Context().Value(chi.RouteCtxKey).(*chi.Context); ok {
+		name = rctx.URLParam("name")
+	}
+	if name == "" {
+		name = r.FormValue("name")
+	}
+	if name == "" {
+		return "Report results"
+	}
+	return name
+}
+
+func isNumericResultValue(val interface{}) bool {
+	switch val.(type) {
+	case uint, uint8, uint16, uint32, uint64, int, int8, int16, int32, int64, float32, float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package operator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func TestWriteResultsResponseAsHTML(t *testing.T) {
+	columns := []api.ReportGenerationQueryColumn{
+		{Name: "namespace", Unit: "kubernetes_namespace"},
+		{Name: "pod_request_cpu_core_seconds", Unit: "cpu_core_seconds"},
+	}
+	results := []presto.Row{
+		{"namespace": "<script>", "pod_request_cpu_core_seconds": 2412.5},
+	}
+	req := httptest.NewRequest("GET", "/api/v1/reports/get?name=namespace-cpu-request&format=html", nil)
+	rec := httptest.NewRecorder()
+	writeResultsResponseAsHTML(logrus.New(), columns, results, rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	assert.Contains(t, body, "<title>namespace-cpu-request</title>")
+	assert.Contains(t, body, `<th>namespace <span class="unit">(kubernetes_namespace)</span></th>`)
+	assert.Contains(t, body, `<td>&lt;script&gt;</td><td class="number">2412.500000</td>`)
+
+	rec = httptest.NewRecorder()
+	writeResultsResponseAsHTML(logrus.New(), columns, []presto.Row{{"namespace": "default"}}, rec, req)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code, "expected an error when a column is missing from the results")
+}
@@ -0,0 +1,169 @@
+package operator
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	api "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+)
+
+const (
+	// ScheduledReport phases returned by the list API, derived from the
+	// ScheduledReport's conditions.
+	scheduledReportPhaseRunning  = "Running"
+	scheduledReportPhaseFinished = "Finished"
+	scheduledReportPhaseFailed   = "Failed"
+)
+
+var listResultFormats = []string{"json", "csv", "tabular", "html"}
+
+type ListReportsResponse struct {
+	Reports []ReportSummary `json:"reports"`
+}
+
+// ReportSummary describes a Report or ScheduledReport, and where it's
+// results can be downloaded from.
+type ReportSummary struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Phase is the phase of a Report, or for a ScheduledReport, either
+	// Running, Finished or Failed.
+	Phase string `json:"phase"`
+	// PeriodStart and PeriodEnd are the period the report covers. For a
+	// ScheduledReport, PeriodEnd is the end of the most recent run.
+	PeriodStart *time.Time `json:"periodStart,omitempty"`
+	PeriodEnd   *time.Time `json:"periodEnd,omitempty"`
+	RowCount    *int64     `json:"rowCount,omitempty"`
+	// ResultURLs maps each output format to the URL of the report's
+	// results, relative to the API's address. It's only set once results
+	// are available.
+	ResultURLs map[string]string `json:"resultURLs,omitempty"`
+}
+
+func (srv *server) listReportsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if r.Method != "GET" {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "Not found")
+		return
+	}
+	namespace := srv.requestNamespace(r)
+	if !srv.authorizeResults(logger, w, r, resultsResourceReports, namespace) {
+		return
+	}
+	reports, err := srv.reportLister.Reports(namespace).List(labels.Everything())
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error listing reports: %v", err)
+		return
+	}
+	summaries := make([]ReportSummary, 0, len(reports))
+	for _, report := range reports {
+		summaries = append(summaries, reportSummary(report))
+	}
+	sortReportSummaries(summaries)
+	writeResponseAsJSON(logger, w, http.StatusOK, ListReportsResponse{Reports: summaries})
+}
+
+func (srv *server) listScheduledReportsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if r.Method != "GET" {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "Not found")
+		return
+	}
+	namespace := srv.requestNamespace(r)
+	if !srv.authorizeResults(logger, w, r, resultsResourceScheduledReports, namespace) {
+		return
+	}
+	reports, err := srv.scheduledReportLister.ScheduledReports(namespace).List(labels.Everything())
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error listing scheduledReports: %v", err)
+		return
+	}
+	summaries := make([]ReportSummary, 0, len(reports))
+	for _, report := range reports {
+		summaries = append(summaries, scheduledReportSummary(report))
+	}
+	sortReportSummaries(summaries)
+	writeResponseAsJSON(logger, w, http.StatusOK, ListReportsResponse{Reports: summaries})
+}
+
+func reportSummary(report *api.Report) ReportSummary {
+	summary := ReportSummary{
+		Name:      report.Name,
+		Namespace: report.Namespace,
+		Phase:     string(report.Status.Phase),
+		RowCount:  report.Status.RowCount,
+	}
+	if report.Spec.ReportingStart != nil {
+		summary.PeriodStart = &report.Spec.ReportingStart.Time
+	}
+	if report.Spec.ReportingEnd != nil {
+		summary.PeriodEnd = &report.Spec.ReportingEnd.Time
+	}
+	if report.Status.Phase == api.ReportPhaseFinished {
+		summary.ResultURLs = resultURLs(func(format string) string {
+			return fmt.Sprintf("%s/%s/full?%s", APIV2Reports, url.PathEscape(report.Name), url.Values{
+				"namespace": {report.Namespace},
+				"format":    {format},
+			}.Encode())
+		})
+	}
+	return summary
+}
+
+func scheduledReportSummary(report *api.ScheduledReport) ReportSummary {
+	summary := ReportSummary{
+		Name:      report.Name,
+		Namespace: report.Namespace,
+		RowCount:  report.Status.RowCount,
+	}
+	if cond := cbutil.GetScheduledReportCondition(report.Status, api.ScheduledReportFailure); cond != nil && cond.Status == v1.ConditionTrue {
+		summary.Phase = scheduledReportPhaseFailed
+	} else if cond := cbutil.GetScheduledReportCondition(report.Status, api.ScheduledReportRunning); cond != nil {
+		if cond.Status == v1.ConditionFalse && cond.Reason == cbutil.ReportPeriodFinishedReason {
+			summary.Phase = scheduledReportPhaseFinished
+		} else {
+			summary.Phase = scheduledReportPhaseRunning
+		}
+	}
+	if report.Spec.ReportingStart != nil {
+		summary.PeriodStart = &report.Spec.ReportingStart.Time
+	}
+	if report.Status.LastReportTime != nil {
+		summary.PeriodEnd = &report.Status.LastReportTime.Time
+	}
+	// results are available once the first run has finished
+	if len(report.Status.RunHistory) != 0 {
+		summary.ResultURLs = resultURLs(func(format string) string {
+			return "/api/v1/scheduledreports/get?" + url.Values{
+				"name":      {report.Name},
+				"namespace": {report.Namespace},
+				"format":    {format},
+			}.Encode()
+		})
+	}
+	return summary
+}
+
+func resultURLs(formatURL func(format string) string) map[string]string {
+	urls := make(map[string]string, len(listResultFormats))
+	for _, format := range listResultFormats {
+		urls[format] = formatURL(format)
+	}
+	return urls
+}
+
+func sortReportSummaries(summaries []ReportSummary) {
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Namespace != summaries[j].Namespace {
+			return summaries[i].Namespace < summaries[j].Namespace
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+}
@@ -0,0 +1,72 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+)
+
+func TestReportSummary(t *testing.T) {
+	start := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	rowCount := int64(42)
+
+	report := &api.Report{
+		ObjectMeta: metav1.ObjectMeta{Name: "cpu usage", Namespace: "metering"},
+		Spec: api.ReportSpec{
+			ReportingStart: &metav1.Time{Time: start},
+			ReportingEnd:   &metav1.Time{Time: end},
+		},
+		Status: api.ReportStatus{Phase: api.ReportPhaseFinished, RowCount: &rowCount},
+	}
+	assert.Equal(t, ReportSummary{
+		Name:        "cpu usage",
+		Namespace:   "metering",
+		Phase:       "Finished",
+		PeriodStart: &start,
+		PeriodEnd:   &end,
+		RowCount:    &rowCount,
+		ResultURLs: map[string]string{
+			"json":    "/api/v2/reports/cpu%20usage/full?format=json&namespace=metering",
+			"csv":     "/api/v2/reports/cpu%20usage/full?format=csv&namespace=metering",
+			"tabular": "/api/v2/reports/cpu%20usage/full?format=tabular&namespace=metering",
+			"html":    "/api/v2/reports/cpu%20usage/full?format=html&namespace=metering",
+		},
+	}, reportSummary(report))
+
+	report.Status = api.ReportStatus{Phase: api.ReportPhaseStarted}
+	assert.Nil(t, reportSummary(report).ResultURLs, "expected no result URLs for a running report")
+}
+
+func TestScheduledReportSummary(t *testing.T) {
+	start := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	lastReportTime := start.AddDate(0, 0, 1)
+	report := &api.ScheduledReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "daily", Namespace: "metering"},
+		Spec:       api.ScheduledReportSpec{ReportingStart: &metav1.Time{Time: start}},
+		Status: api.ScheduledReportStatus{
+			Conditions: []api.ScheduledReportCondition{
+				*cbutil.NewScheduledReportCondition(api.ScheduledReportRunning, v1.ConditionTrue, cbutil.ScheduledReason, ""),
+			},
+			LastReportTime: &metav1.Time{Time: lastReportTime},
+			RunHistory:     []api.ScheduledReportRun{{PeriodStart: metav1.Time{Time: start}, PeriodEnd: metav1.Time{Time: lastReportTime}}},
+		},
+	}
+	summary := scheduledReportSummary(report)
+	assert.Equal(t, "Running", summary.Phase)
+	assert.Equal(t, &start, summary.PeriodStart)
+	assert.Equal(t, &lastReportTime, summary.PeriodEnd)
+	assert.Equal(t, "/api/v1/scheduledreports/get?format=csv&name=daily&namespace=metering", summary.ResultURLs["csv"])
+
+	cbutil.SetScheduledReportCondition(&report.Status, *cbutil.NewScheduledReportCondition(api.ScheduledReportRunning, v1.ConditionFalse, cbutil.ReportPeriodFinishedReason, ""))
+	assert.Equal(t, "Finished", scheduledReportSummary(report).Phase)
+
+	cbutil.SetScheduledReportCondition(&report.Status, *cbutil.NewScheduledReportCondition(api.ScheduledReportFailure, v1.ConditionTrue, cbutil.PrestoErrorReason, ""))
+	assert.Equal(t, "Failed", scheduledReportSummary(report).Phase)
+}
@@ -0,0 +1,127 @@
+package operator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+)
+
+func newQueryDiffQuery(resourceVersion, query string) *cbTypes.ReportGenerationQuery {
+	return &cbTypes.ReportGenerationQuery{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-cpu", Namespace: "default", ResourceVersion: resourceVersion},
+		Spec:       cbTypes.ReportGenerationQuerySpec{Query: query},
+	}
+}
+
+func TestQueryDiffHandlers(t *testing.T) {
+	texts, err := reporting.NewGenerationQueryTexts(10)
+	require.NoError(t, err)
+	v1 := reporting.GetGenerationQueryVersions(newQueryDiffQuery("1", "SELECT a\n"), nil, texts)
+	v2 := reporting.GetGenerationQueryVersions(newQueryDiffQuery("2", "SELECT b\n"), nil, texts)
+	// the current version of the query hasn't been used by a report since
+	// reporting-operator started, so only the lister has it's text
+	current := newQueryDiffQuery("3", "SELECT c\n")
+	v3 := reporting.GetGenerationQueryVersions(current, nil, nil)
+	// the text of versions used before reporting-operator started isn't held
+	v0 := reporting.GetGenerationQueryVersions(newQueryDiffQuery("0", "SELECT z\n"), nil, nil)
+
+	reports := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	for name, versions := range map[string][]cbTypes.ReportGenerationQueryVersion{"v0": v0, "v1": v1, "v2": v2, "v3": v3} {
+		require.NoError(t, reports.Add(&cbTypes.Report{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     cbTypes.ReportStatus{GenerationQueryVersions: versions},
+		}))
+	}
+	scheduledReports := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	period := func(day int) metav1.Time {
+		return metav1.NewTime(time.Date(2019, time.January, day, 0, 0, 0, 0, time.UTC))
+	}
+	require.NoError(t, scheduledReports.Add(&cbTypes.ScheduledReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "daily", Namespace: "default"},
+		Status: cbTypes.ScheduledReportStatus{RunHistory: []cbTypes.ScheduledReportRun{
+			{PeriodStart: period(3), PeriodEnd: period(4), GenerationQueryVersions: v2},
+			{PeriodStart: period(2), PeriodEnd: period(3), GenerationQueryVersions: v1},
+			{PeriodStart: period(1), PeriodEnd: period(2), GenerationQueryVersions: v1},
+		}},
+	}))
+	queries := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, queries.Add(current))
+
+	router := newRouter(testLogger, testRand, nil, nil, noopPrometheusImporterFunc, "default", reporting.DefaultCurrency,
+		listers.NewReportLister(reports), listers.NewScheduledReportLister(scheduledReports), listers.NewReportGenerationQueryLister(queries), nil, nil,
+		routerOptions{queryTexts: texts},
+	)
+	get := func(path string) (int, QueryDiffResponse) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		var resp QueryDiffResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w.Code, resp
+	}
+
+	code, resp := get("/api/v1/reports/querydiff?from=v1&to=v2")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "v1", resp.From)
+	assert.Equal(t, "v2", resp.To)
+	require.Len(t, resp.Queries, 1)
+	assert.True(t, resp.Queries[0].Changed)
+	assert.Contains(t, resp.Queries[0].Diff, "-SELECT a\n+SELECT b\n")
+
+	// the text of the current version is read from the lister
+	code, resp = get("/api/v1/reports/querydiff?from=v2&to=v3")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Queries, 1)
+	assert.Contains(t, resp.Queries[0].Diff, "-SELECT b\n+SELECT c\n")
+
+	code, resp = get("/api/v1/reports/querydiff?from=v0&to=v1")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Queries, 1)
+	assert.True(t, resp.Queries[0].Changed)
+	assert.True(t, resp.Queries[0].DiffUnavailable)
+	assert.Empty(t, resp.Queries[0].Diff)
+
+	code, resp = get("/api/v1/reports/querydiff?from=v1&to=v1")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Queries, 1)
+	assert.False(t, resp.Queries[0].Changed)
+
+	// runs default to comparing the previous run to the most recent run
+	code, resp = get("/api/v1/scheduledreports/querydiff?name=daily")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "2019-01-02T00:00:00Z-2019-01-03T00:00:00Z", resp.From)
+	assert.Equal(t, "2019-01-03T00:00:00Z-2019-01-04T00:00:00Z", resp.To)
+	require.Len(t, resp.Queries, 1)
+	assert.Contains(t, resp.Queries[0].Diff, "-SELECT a\n+SELECT b\n")
+
+	code, resp = get("/api/v1/scheduledreports/querydiff?name=daily&from=2&to=1")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Queries, 1)
+	assert.False(t, resp.Queries[0].Changed)
+
+	for path, expectedCode := range map[string]int{
+		"/api/v1/reports/querydiff?from=v1":                           http.StatusBadRequest,
+		"/api/v1/reports/querydiff?from=v1&to=missing":                http.StatusNotFound,
+		"/api/v1/scheduledreports/querydiff":                          http.StatusBadRequest,
+		"/api/v1/scheduledreports/querydiff?name=daily&from=-1":       http.StatusBadRequest,
+		"/api/v1/scheduledreports/querydiff?name=daily&to=latest":     http.StatusBadRequest,
+		"/api/v1/scheduledreports/querydiff?name=daily&from=3":        http.StatusNotFound,
+		"/api/v1/scheduledreports/querydiff?name=missing":             http.StatusNotFound,
+		"/api/v1/scheduledreports/querydiff?name=daily&namespace=foo": http.StatusNotFound,
+	} {
+		code, _ := get(path)
+		assert.Equal(t, expectedCode, code, "unexpected status for %s", path)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
 	"github.com/operator-framework/operator-metering/pkg/hive"
 	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
 	"github.com/operator-framework/operator-metering/pkg/presto"
 	"github.com/operator-framework/operator-metering/test/testhelpers"
 )
@@ -42,7 +43,7 @@ type fakePrometheusMetricsRepo struct {
 	err     error
 }
 
-func (f *fakePrometheusMetricsRepo) StorePrometheusMetrics(ctx context.Context, tableName string, metrics []*prestostore.PrometheusMetric) error {
+func (f *fakePrometheusMetricsRepo) StorePrometheusMetrics(ctx context.Context, tableName string, granularity prestostore.PartitionGranularity, metrics []*prestostore.PrometheusMetric) error {
 	if f.err != nil {
 		return f.err
 	}
@@ -77,7 +78,7 @@ type fakeReportResultsGetter struct {
 	err     error
 }
 
-func (f *fakeReportResultsGetter) GetReportResults(tableName string, columns []presto.Column) ([]presto.Row, error) {
+func (f *fakeReportResultsGetter) GetReportResults(ctx context.Context, tableName string, columns []presto.Column) ([]presto.Row, error) {
 	return f.results, f.err
 }
 
@@ -274,11 +275,13 @@ func TestAPIV1ReportsGet(t *testing.T) {
 			scheduledReportIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
 			reportGenerationQueryIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
 			prestoTableIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+			reportDataSourceIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
 
 			reportLister := listers.NewReportLister(reportIndexer)
 			scheduledReportLister := listers.NewScheduledReportLister(scheduledReportIndexer)
 			reportGenerationQueryLister := listers.NewReportGenerationQueryLister(reportGenerationQueryIndexer)
 			prestoTableLister := listers.NewPrestoTableLister(prestoTableIndexer)
+			reportDataSourceLister := listers.NewReportDataSourceLister(reportDataSourceIndexer)
 
 			// add our test report if one is specified
 			if tt.report != nil {
@@ -293,8 +296,8 @@ func TestAPIV1ReportsGet(t *testing.T) {
 			}
 
 			// setup a test server suitable for making API calls against
-			router := newRouter(testLogger, testRand, tt.prometheusMetricsRepo, tt.reportResultsGetter, noopPrometheusImporterFunc, namespace,
-				reportLister, scheduledReportLister, reportGenerationQueryLister, prestoTableLister,
+			router := newRouter(testLogger, testRand, tt.prometheusMetricsRepo, tt.reportResultsGetter, noopPrometheusImporterFunc, namespace, reporting.DefaultCurrency,
+				reportLister, scheduledReportLister, reportGenerationQueryLister, prestoTableLister, reportDataSourceLister, routerOptions{},
 			)
 			server := httptest.NewServer(router)
 			defer server.Close()
@@ -523,7 +526,7 @@ func TestAPIV2ReportsFull(t *testing.T) {
 			report:                testhelpers.NewReport(testReportName, namespace, testQueryName, reportStart, reportEnd, v1alpha1.ReportStatus{Phase: v1alpha1.ReportPhaseFinished}),
 			apiPath:               apiReportV2URLFull(testReportName) + "?format=doesntexist",
 			expectedStatusCode:    http.StatusBadRequest,
-			expectedAPIError:      "format must be one of: csv, json or tabular",
+			expectedAPIError:      "format must be one of: csv, html, json or tabular",
 			reportResultsGetter:   &fakeReportResultsGetter{},
 			prometheusMetricsRepo: &fakePrometheusMetricsRepo{},
 		},
@@ -580,11 +583,13 @@ func TestAPIV2ReportsFull(t *testing.T) {
 			scheduledReportIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
 			reportGenerationQueryIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
 			prestoTableIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+			reportDataSourceIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
 
 			reportLister := listers.NewReportLister(reportIndexer)
 			scheduledReportLister := listers.NewScheduledReportLister(scheduledReportIndexer)
 			reportGenerationQueryLister := listers.NewReportGenerationQueryLister(reportGenerationQueryIndexer)
 			prestoTableLister := listers.NewPrestoTableLister(prestoTableIndexer)
+			reportDataSourceLister := listers.NewReportDataSourceLister(reportDataSourceIndexer)
 
 			// add our test report if one is specified
 			if tt.report != nil {
@@ -599,8 +604,8 @@ func TestAPIV2ReportsFull(t *testing.T) {
 			}
 
 			// setup a test server suitable for making API calls against
-			router := newRouter(testLogger, testRand, tt.prometheusMetricsRepo, tt.reportResultsGetter, noopPrometheusImporterFunc, namespace,
-				reportLister, scheduledReportLister, reportGenerationQueryLister, prestoTableLister,
+			router := newRouter(testLogger, testRand, tt.prometheusMetricsRepo, tt.reportResultsGetter, noopPrometheusImporterFunc, namespace, reporting.DefaultCurrency,
+				reportLister, scheduledReportLister, reportGenerationQueryLister, prestoTableLister, reportDataSourceLister, routerOptions{},
 			)
 			server := httptest.NewServer(router)
 			defer server.Close()
@@ -814,7 +819,7 @@ func TestAPIV2ReportsTable(t *testing.T) {
 			report:                testhelpers.NewReport(testReportName, namespace, testQueryName, reportStart, reportEnd, v1alpha1.ReportStatus{Phase: v1alpha1.ReportPhaseFinished}),
 			apiPath:               apiReportV2URLTable(testReportName) + "?format=doesntexist",
 			expectedStatusCode:    http.StatusBadRequest,
-			expectedAPIError:      "format must be one of: csv, json or tabular",
+			expectedAPIError:      "format must be one of: csv, html, json or tabular",
 			reportResultsGetter:   &fakeReportResultsGetter{},
 			prometheusMetricsRepo: &fakePrometheusMetricsRepo{},
 		},
@@ -871,11 +876,13 @@ func TestAPIV2ReportsTable(t *testing.T) {
 			scheduledReportIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
 			reportGenerationQueryIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
 			prestoTableIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+			reportDataSourceIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
 
 			reportLister := listers.NewReportLister(reportIndexer)
 			scheduledReportLister := listers.NewScheduledReportLister(scheduledReportIndexer)
 			reportGenerationQueryLister := listers.NewReportGenerationQueryLister(reportGenerationQueryIndexer)
 			prestoTableLister := listers.NewPrestoTableLister(prestoTableIndexer)
+			reportDataSourceLister := listers.NewReportDataSourceLister(reportDataSourceIndexer)
 
 			// add our test report if one is specified
 			if tt.report != nil {
@@ -890,8 +897,8 @@ func TestAPIV2ReportsTable(t *testing.T) {
 			}
 
 			// setup a test server suitable for making API calls against
-			router := newRouter(testLogger, testRand, tt.prometheusMetricsRepo, tt.reportResultsGetter, noopPrometheusImporterFunc, namespace,
-				reportLister, scheduledReportLister, reportGenerationQueryLister, prestoTableLister,
+			router := newRouter(testLogger, testRand, tt.prometheusMetricsRepo, tt.reportResultsGetter, noopPrometheusImporterFunc, namespace, reporting.DefaultCurrency,
+				reportLister, scheduledReportLister, reportGenerationQueryLister, prestoTableLister, reportDataSourceLister, routerOptions{},
 			)
 			server := httptest.NewServer(router)
 			defer server.Close()
@@ -927,3 +934,27 @@ func TestAPIV2ReportsTable(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertsToGetReportResultsCurrency(t *testing.T) {
+	columns := []v1alpha1.ReportGenerationQueryColumn{
+		{Name: "namespace", Type: "string", Unit: "kubernetes_namespace"},
+		{Name: "cpu_cost", Type: "double", Unit: reporting.CurrencyColumnUnit},
+	}
+	rows := []presto.Row{{"namespace": "default", "cpu_cost": 1.5}}
+
+	results := convertsToGetReportResults(rows, columns, reporting.Currency{Code: "EUR", Precision: 2})
+	require.Len(t, results.Results, 1)
+	for _, value := range results.Results[0].Values {
+		switch value.Name {
+		case "namespace":
+			assert.Empty(t, value.Currency, "expected non-cost columns to have no currency")
+		case "cpu_cost":
+			assert.Equal(t, "EUR", value.Currency)
+		}
+	}
+
+	displayed := currencyColumnUnits(columns, reporting.Currency{})
+	assert.Equal(t, "kubernetes_namespace", displayed[0].Unit)
+	assert.Equal(t, reporting.DefaultCurrencyCode, displayed[1].Unit, "expected the default currency to be displayed when none is configured")
+	assert.Equal(t, reporting.CurrencyColumnUnit, columns[1].Unit, "expected the columns to be left unmodified")
+}
@@ -0,0 +1,76 @@
+package operator
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+var prometheusReportDatasourceImportLagCollector = newImportLagCollector(time.Now)
+
+func init() {
+	prometheus.MustRegister(prometheusReportDatasourceImportLagCollector)
+}
+
+type importLag struct {
+	labelValues              []string
+	newestImportedMetricTime time.Time
+}
+
+// importLagCollector exposes how far behind the current time the newest
+// metric imported for each Prometheus ReportDataSource is. The lag is
+// computed when scraped, so it keeps increasing if imports stop.
+type importLagCollector struct {
+	desc *prometheus.Desc
+	now  func() time.Time
+
+	mu   sync.Mutex
+	lags map[string]importLag
+}
+
+func newImportLagCollector(now func() time.Time) *importLagCollector {
+	return &importLagCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(prometheusMetricNamespace, "", "prometheus_reportdatasource_import_lag_seconds"),
+			"Number of seconds between now and the newest metric imported for a Prometheus ReportDataSource.",
+			prometheusReportDatasourceLabels,
+			nil,
+		),
+		now:  now,
+		lags: make(map[string]importLag),
+	}
+}
+
+func (c *importLagCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *importLagCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for _, lag := range c.lags {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, now.Sub(lag.newestImportedMetricTime).Seconds(), lag.labelValues...)
+	}
+}
+
+// set records the timestamp of the newest metric imported for the
+// ReportDataSource.
+func (c *importLagCollector) set(dataSource *cbTypes.ReportDataSource, tableName string, newestImportedMetricTime time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lags[importerKey(dataSource)] = importLag{
+		labelValues:              []string{dataSource.Name, dataSource.Spec.Promsum.Query, tableName},
+		newestImportedMetricTime: newestImportedMetricTime,
+	}
+}
+
+// delete stops exposing the lag of the ReportDataSource.
+func (c *importLagCollector) delete(dataSource *cbTypes.ReportDataSource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.lags, importerKey(dataSource))
+}
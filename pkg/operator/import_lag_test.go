@@ -0,0 +1,63 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestImportLagCollector(t *testing.T) {
+	now := time.Date(2019, time.January, 1, 12, 0, 0, 0, time.UTC)
+	collector := newImportLagCollector(func() time.Time { return now })
+	dataSource := &cbTypes.ReportDataSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-request-cpu-cores", Namespace: "metering"},
+		Spec: cbTypes.ReportDataSourceSpec{
+			Promsum: &cbTypes.PrometheusMetricsDataSource{Query: "pod-request-cpu-cores"},
+		},
+	}
+
+	collector.set(dataSource, "datasource_pod_request_cpu_cores", now.Add(-10*time.Minute))
+	metrics := collectMetrics(collector)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, 600.0, metrics[0].GetGauge().GetValue())
+	labels := make(map[string]string)
+	for _, label := range metrics[0].GetLabel() {
+		labels[label.GetName()] = label.GetValue()
+	}
+	assert.Equal(t, map[string]string{
+		"reportdatasource":      "pod-request-cpu-cores",
+		"reportprometheusquery": "pod-request-cpu-cores",
+		"table_name":            "datasource_pod_request_cpu_cores",
+	}, labels)
+
+	// the lag keeps growing until newer metrics are imported
+	now = now.Add(5 * time.Minute)
+	metrics = collectMetrics(collector)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, 900.0, metrics[0].GetGauge().GetValue())
+
+	collector.delete(dataSource)
+	assert.Empty(t, collectMetrics(collector))
+}
+
+func collectMetrics(collector prometheus.Collector) []*dto.Metric {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		collector.Collect(ch)
+		close(ch)
+	}()
+	var metrics []*dto.Metric
+	for metric := range ch {
+		m := &dto.Metric{}
+		metric.Write(m)
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
@@ -0,0 +1,341 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/kafka"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	defaultKafkaMaxBatchSize   = 1000
+	defaultKafkaFlushInterval  = time.Minute
+	kafkaConsumerRetryInterval = 30 * time.Second
+)
+
+var (
+	kafkaReportDatasourceMessagesStoredCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: prometheusMetricNamespace,
+			Name:      "kafka_reportdatasource_messages_stored_total",
+			Help:      "Number of messages consumed from Kafka and stored in a Kafka ReportDataSource table.",
+		},
+		[]string{"reportdatasource", "table_name"},
+	)
+	kafkaReportDatasourceInvalidMessagesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: prometheusMetricNamespace,
+			Name:      "kafka_reportdatasource_invalid_messages_total",
+			Help:      "Number of messages consumed from Kafka which couldn't be decoded, and were skipped.",
+		},
+		[]string{"reportdatasource", "table_name"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(kafkaReportDatasourceMessagesStoredCounter)
+	prometheus.MustRegister(kafkaReportDatasourceInvalidMessagesCounter)
+}
+
+type kafkaConsumer struct {
+	// spec and tableName are the configuration the consumer was started
+	// with.
+	spec      cbTypes.KafkaDataSource
+	tableName string
+	cancel    context.CancelFunc
+}
+
+func (op *Reporting) handleKafkaDataSource(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	source := dataSource.Spec.Kafka
+	hiveColumns, _, err := kafkaDataSourceColumns(source)
+	if err != nil {
+		return reasonErrorf(err, cbutil.ValidationErrorReason, "ReportDataSource %q: improperly configured datasource, %v", dataSource.Name, err)
+	}
+	if _, err := newKafkaDecoder(source); err != nil {
+		return reasonErrorf(err, cbutil.ValidationErrorReason, "ReportDataSource %q: improperly configured datasource, %v", dataSource.Name, err)
+	}
+
+	if dataSource.Status.TableName != "" {
+		logger.Infof("existing Kafka ReportDataSource discovered, tableName: %s", dataSource.Status.TableName)
+	} else {
+		logger.Infof("new Kafka ReportDataSource discovered")
+		tableName := op.dataSourceTableName(dataSource)
+		err := op.createTableForStorage(op.workCtx, logger, dataSource, cbTypes.SchemeGroupVersion.WithKind("ReportDataSource"), source.Storage, tableName, hiveColumns, promsumHivePartitions)
+		if err != nil {
+			return err
+		}
+
+		dataSource, err = op.updateDataSourceTableName(logger, dataSource, tableName)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to update ReportDataSource TableName field %q", tableName)
+			return err
+		}
+	}
+
+	return op.ensureKafkaConsumer(logger, dataSource)
+}
+
+// kafkaDataSourceColumns validates the Kafka ReportDataSource and returns the
+// Hive columns used to create it's table, and the Presto columns used to
+// insert rows, which include the dt partition column.
+func kafkaDataSourceColumns(source *cbTypes.KafkaDataSource) ([]hive.Column, []presto.Column, error) {
+	if len(source.Brokers) == 0 {
+		return nil, nil, fmt.Errorf("brokers is empty")
+	}
+	if source.Topic == "" {
+		return nil, nil, fmt.Errorf("topic is empty")
+	}
+	if source.MaxBatchSize < 0 {
+		return nil, nil, fmt.Errorf("maxBatchSize cannot be negative")
+	}
+	if source.FlushInterval != nil && source.FlushInterval.Duration <= 0 {
+		return nil, nil, fmt.Errorf("flushInterval must be positive")
+	}
+	if len(source.Columns) == 0 {
+		return nil, nil, fmt.Errorf("columns is empty")
+	}
+
+	hiveColumns := make([]hive.Column, len(source.Columns))
+	prestoColumns := make([]presto.Column, len(source.Columns), len(source.Columns)+1)
+	seen := map[string]struct{}{"dt": {}}
+	for i, column := range source.Columns {
+		if column.Name == "" {
+			return nil, nil, fmt.Errorf("column names cannot be empty")
+		}
+		name := strings.ToLower(column.Name)
+		if _, exists := seen[name]; exists {
+			return nil, nil, fmt.Errorf("multiple columns named %s, dt is reserved for the partition column", column.Name)
+		}
+		seen[name] = struct{}{}
+
+		hiveColumn, prestoColumn, err := importedColumn(column.Name, column.Type)
+		if err != nil {
+			return nil, nil, err
+		}
+		hiveColumns[i] = hiveColumn
+		prestoColumns[i] = prestoColumn
+	}
+	prestoColumns = append(prestoColumns, presto.Column{Name: "dt", Type: "varchar"})
+	return hiveColumns, prestoColumns, nil
+}
+
+func newKafkaDecoder(source *cbTypes.KafkaDataSource) (kafka.Decoder, error) {
+	switch source.Format {
+	case cbTypes.KafkaMessageFormatJSON:
+		return kafka.NewJSONDecoder(), nil
+	case cbTypes.KafkaMessageFormatAvro:
+		if source.AvroSchema == "" {
+			return nil, fmt.Errorf("avroSchema is required when format is %s", source.Format)
+		}
+		return kafka.NewAvroDecoder(source.AvroSchema)
+	default:
+		return nil, fmt.Errorf("format must be %s or %s, got %q", cbTypes.KafkaMessageFormatJSON, cbTypes.KafkaMessageFormatAvro, source.Format)
+	}
+}
+
+// ensureKafkaConsumer starts consuming the Kafka ReportDataSource's topic if
+// it isn't already being consumed, restarting the consumer if the
+// configuration changed.
+func (op *Reporting) ensureKafkaConsumer(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	key := importerKey(dataSource)
+	source := dataSource.Spec.Kafka
+	tableName := dataSource.Status.TableName
+
+	op.kafkaConsumersMu.Lock()
+	defer op.kafkaConsumersMu.Unlock()
+	if existing, exists := op.kafkaConsumers[key]; exists {
+		if existing.tableName == tableName && reflect.DeepEqual(existing.spec, *source) {
+			return nil
+		}
+		logger.Infof("configuration changed, restarting Kafka consumer for topic %s", existing.spec.Topic)
+		existing.cancel()
+		delete(op.kafkaConsumers, key)
+	}
+
+	_, columns, err := kafkaDataSourceColumns(source)
+	if err != nil {
+		return err
+	}
+	decoder, err := newKafkaDecoder(source)
+	if err != nil {
+		return err
+	}
+	groupID := source.GroupID
+	if groupID == "" {
+		groupID = fmt.Sprintf("metering-%s-%s", dataSource.Namespace, dataSource.Name)
+	}
+	maxBatchSize := source.MaxBatchSize
+	if maxBatchSize == 0 {
+		maxBatchSize = defaultKafkaMaxBatchSize
+	}
+	flushInterval := defaultKafkaFlushInterval
+	if source.FlushInterval != nil {
+		flushInterval = source.FlushInterval.Duration
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	op.kafkaConsumers[key] = &kafkaConsumer{
+		spec:      *source.DeepCopy(),
+		tableName: tableName,
+		cancel:    cancel,
+	}
+	logger = logger.WithFields(log.Fields{"topic": source.Topic, "groupID": groupID, "tableName": tableName})
+	logger.Infof("starting Kafka consumer for topic %s", source.Topic)
+	c := &kafkaTableWriter{
+		logger:         logger,
+		consumer:       kafka.NewConsumer(source.Brokers, source.Topic, groupID),
+		decoder:        decoder,
+		repo:           op.sqlRowsRepo,
+		tableName:      tableName,
+		columns:        columns,
+		fields:         kafkaDataSourceFields(source),
+		maxBatchSize:   maxBatchSize,
+		flushInterval:  flushInterval,
+		storedCounter:  kafkaReportDatasourceMessagesStoredCounter.WithLabelValues(dataSource.Name, tableName),
+		invalidCounter: kafkaReportDatasourceInvalidMessagesCounter.WithLabelValues(dataSource.Name, tableName),
+	}
+	go c.run(ctx)
+	return nil
+}
+
+// stopKafkaConsumer stops consuming the ReportDataSource's topic, if it's
+// being consumed.
+func (op *Reporting) stopKafkaConsumer(dataSource *cbTypes.ReportDataSource) {
+	key := importerKey(dataSource)
+	op.kafkaConsumersMu.Lock()
+	defer op.kafkaConsumersMu.Unlock()
+	if consumer, exists := op.kafkaConsumers[key]; exists {
+		consumer.cancel()
+		delete(op.kafkaConsumers, key)
+	}
+}
+
+func kafkaDataSourceFields(source *cbTypes.KafkaDataSource) []string {
+	fields := make([]string, len(source.Columns))
+	for i, column := range source.Columns {
+		fields[i] = column.Field
+		if fields[i] == "" {
+			fields[i] = column.Name
+		}
+	}
+	return fields
+}
+
+// kafkaTableWriter consumes messages from a topic and stores them in a
+// table. Messages are only committed after they've been stored, so messages
+// are stored at least once.
+type kafkaTableWriter struct {
+	logger         log.FieldLogger
+	consumer       kafka.Consumer
+	decoder        kafka.Decoder
+	repo           prestostore.SQLRowsRepo
+	tableName      string
+	columns        []presto.Column
+	fields         []string
+	maxBatchSize   int
+	flushInterval  time.Duration
+	storedCounter  prometheus.Counter
+	invalidCounter prometheus.Counter
+}
+
+func (w *kafkaTableWriter) run(ctx context.Context) {
+	defer w.consumer.Close()
+	for {
+		msgs, err := w.consumer.FetchBatch(ctx, w.maxBatchSize, w.flushInterval)
+		if ctx.Err() != nil {
+			w.logger.Infof("Kafka consumer stopped")
+			return
+		}
+		if err != nil {
+			w.logger.WithError(err).Errorf("error consuming messages from Kafka")
+		}
+		if len(msgs) != 0 {
+			// retry storing the batch until it succeeds, since the
+			// messages can't be fetched again without restarting the
+			// consumer.
+			for {
+				err = w.store(ctx, msgs)
+				if err == nil || ctx.Err() != nil {
+					break
+				}
+				w.logger.WithError(err).Errorf("error storing %d messages from Kafka in table %s", len(msgs), w.tableName)
+				if !w.wait(ctx) {
+					return
+				}
+			}
+			continue
+		}
+		if err != nil && !w.wait(ctx) {
+			return
+		}
+	}
+}
+
+// wait waits before retrying after an error, returning false if the
+// consumer was stopped.
+func (w *kafkaTableWriter) wait(ctx context.Context) bool {
+	retryInterval := wait.Jitter(kafkaConsumerRetryInterval, 0.5)
+	w.logger.Infof("retrying in %s", retryInterval)
+	select {
+	case <-time.After(retryInterval):
+		return true
+	case <-ctx.Done():
+		w.logger.Infof("Kafka consumer stopped")
+		return false
+	}
+}
+
+func (w *kafkaTableWriter) store(ctx context.Context, msgs []kafka.Message) error {
+	rows := make([][]interface{}, 0, len(msgs))
+	for _, msg := range msgs {
+		row, err := w.messageRow(msg)
+		if err != nil {
+			w.logger.WithError(err).Warnf("skipping invalid message at partition %d offset %d", msg.Partition, msg.Offset)
+			w.invalidCounter.Inc()
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	if err := w.repo.StoreRows(ctx, w.tableName, w.columns, rows); err != nil {
+		return err
+	}
+	w.storedCounter.Add(float64(len(rows)))
+	if err := w.consumer.Commit(ctx, msgs); err != nil {
+		// the messages were stored, so they'll be stored again if
+		// they're consumed again.
+		w.logger.WithError(err).Errorf("unable to commit %d messages stored in table %s, they may be stored again", len(msgs), w.tableName)
+	}
+	w.logger.Debugf("stored %d messages in table %s", len(rows), w.tableName)
+	return nil
+}
+
+// messageRow decodes the message into a row of the table, partitioned by the
+// day of the message's timestamp.
+func (w *kafkaTableWriter) messageRow(msg kafka.Message) ([]interface{}, error) {
+	record, err := w.decoder.Decode(msg.Value)
+	if err != nil {
+		return nil, err
+	}
+	row := make([]interface{}, len(w.fields)+1)
+	for i, field := range w.fields {
+		row[i], err = kafka.FieldValue(record, field)
+		if err != nil {
+			return nil, err
+		}
+	}
+	row[len(w.fields)] = prestostore.PrometheusMetricTimestampPartition(msg.Time)
+	return row, nil
+}
@@ -0,0 +1,64 @@
+package operator
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// runStandbyCheck periodically exercises our Presto and Hive connections
+// until becameLeaderCh or stopCh are closed. This keeps the connections warm
+// while we're a standby so failing over doesn't require re-establishing them.
+// Hive is only checked when the query backend uses it.
+func (op *Reporting) runStandbyCheck(becameLeaderCh, stopCh <-chan struct{}) {
+	interval := op.cfg.StandbyCheckInterval
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-becameLeaderCh:
+			return
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			op.logger.Debugf("running standby connection check")
+			if !op.testReadFromPrestoFunc() {
+				op.logger.Warnf("standby connection check failed, unable to read from Presto")
+			}
+			if op.testHiveFunc != nil {
+				if err := op.testHiveFunc(); err != nil {
+					op.logger.WithError(err).Warnf("standby connection check failed, unable to query Hive")
+				}
+			}
+		}
+	}
+}
+
+// releaseLeaderLease expires the leader election lease if we currently hold
+// it, allowing another replica to acquire it on their next retry instead of
+// waiting for the full lease duration.
+func (op *Reporting) releaseLeaderLease(rl resourcelock.Interface) {
+	record, err := rl.Get()
+	if err != nil {
+		op.logger.WithError(err).Warnf("unable to get leader election record, not releasing lease")
+		return
+	}
+	if record.HolderIdentity != rl.Identity() {
+		return
+	}
+	now := metav1.Now()
+	record.HolderIdentity = ""
+	record.LeaseDurationSeconds = 1
+	record.RenewTime = now
+	record.AcquireTime = now
+	err = rl.Update(*record)
+	if err != nil {
+		op.logger.WithError(err).Warnf("unable to release leader election lease")
+		return
+	}
+	op.logger.Infof("released leader election lease")
+}
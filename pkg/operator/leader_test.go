@@ -0,0 +1,39 @@
+package operator
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunStandbyCheck(t *testing.T) {
+	var prestoChecks, hiveChecks int32
+	op := &Reporting{
+		logger: logrus.New(),
+		cfg:    Config{StandbyCheckInterval: time.Millisecond},
+		testReadFromPrestoFunc: func() bool {
+			atomic.AddInt32(&prestoChecks, 1)
+			return true
+		},
+		testHiveFunc: func() error {
+			atomic.AddInt32(&hiveChecks, 1)
+			return nil
+		},
+	}
+
+	becameLeaderCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		op.runStandbyCheck(becameLeaderCh, make(chan struct{}))
+		close(done)
+	}()
+	for atomic.LoadInt32(&hiveChecks) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	close(becameLeaderCh)
+	<-done
+	assert.True(t, atomic.LoadInt32(&prestoChecks) >= 2, "expected Presto to be checked along with Hive")
+}
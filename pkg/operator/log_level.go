@@ -0,0 +1,88 @@
+package operator
+
+import (
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// LogLevelRequest changes the log level and query logging of a running
+// reporting-operator. Fields which aren't set are left unchanged.
+type LogLevelRequest struct {
+	Level         string `json:"level,omitempty"`
+	LogDMLQueries *bool  `json:"logDMLQueries,omitempty"`
+	LogDDLQueries *bool  `json:"logDDLQueries,omitempty"`
+}
+
+type LogLevelResponse struct {
+	Level         string `json:"level"`
+	LogDMLQueries bool   `json:"logDMLQueries"`
+	LogDDLQueries bool   `json:"logDDLQueries"`
+}
+
+// logLevelHandler returns the current log level and query logging on GET,
+// and changes them on PUT. Changes last until reporting-operator restarts
+// or the ReportingOperatorConfig changes.
+func (op *Reporting) logLevelHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(op.logger, r, op.rand)
+	if op.logLevelLogger == nil {
+		writeErrorResponse(logger, w, r, http.StatusNotImplemented, "the log level of this logger can't be changed")
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+	case "PUT":
+		var req LogLevelRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to decode request as JSON: %v", err)
+			return
+		}
+		var level log.Level
+		if req.Level != "" {
+			var err error
+			level, err = log.ParseLevel(req.Level)
+			if err != nil {
+				writeErrorResponse(logger, w, r, http.StatusBadRequest, "invalid level: %v", err)
+				return
+			}
+		}
+
+		// changes are made under cfgMu so they aren't interleaved with the
+		// ReportingOperatorConfig being applied.
+		op.cfgMu.Lock()
+		if req.Level != "" {
+			op.setLogLevelLocked(level)
+		}
+		if req.LogDMLQueries != nil {
+			op.logDMLQueries.SetEnabled(*req.LogDMLQueries)
+		}
+		if req.LogDDLQueries != nil {
+			op.logDDLQueries.SetEnabled(*req.LogDDLQueries)
+		}
+		op.cfgMu.Unlock()
+	default:
+		writeErrorResponse(logger, w, r, http.StatusMethodNotAllowed, "only GET and PUT are supported")
+		return
+	}
+
+	op.cfgMu.RLock()
+	resp := LogLevelResponse{
+		Level:         op.logLevel.String(),
+		LogDMLQueries: op.logDMLQueries.Enabled(),
+		LogDDLQueries: op.logDDLQueries.Enabled(),
+	}
+	op.cfgMu.RUnlock()
+	if r.Method == "PUT" {
+		logger.Infof("log level set to %s, logDMLQueries: %t, logDDLQueries: %t", resp.Level, resp.LogDMLQueries, resp.LogDDLQueries)
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, resp)
+}
+
+// setLogLevelLocked sets the level of the operator's logger. op.cfgMu must
+// be held.
+func (op *Reporting) setLogLevelLocked(level log.Level) {
+	op.logLevel = level
+	op.logLevelLogger.SetLevel(level)
+}
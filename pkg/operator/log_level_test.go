@@ -0,0 +1,53 @@
+package operator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/db"
+)
+
+func TestLogLevelHandler(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.InfoLevel)
+	op := &Reporting{
+		logger:         logger,
+		rand:           testRand,
+		logLevelLogger: logger,
+		logLevel:       logrus.InfoLevel,
+		logDMLQueries:  db.NewQueryLogging(false),
+		logDDLQueries:  db.NewQueryLogging(true),
+	}
+
+	do := func(method, body string) (int, LogLevelResponse) {
+		w := httptest.NewRecorder()
+		op.logLevelHandler(w, httptest.NewRequest(method, "/debug/loglevel", strings.NewReader(body)))
+		var resp LogLevelResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		}
+		return w.Code, resp
+	}
+
+	code, resp := do("GET", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, LogLevelResponse{Level: "info", LogDMLQueries: false, LogDDLQueries: true}, resp)
+
+	code, resp = do("PUT", `{"level": "debug", "logDMLQueries": true}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, LogLevelResponse{Level: "debug", LogDMLQueries: true, LogDDLQueries: true}, resp)
+	assert.Equal(t, logrus.DebugLevel, logger.Level)
+	assert.True(t, op.logDMLQueries.Enabled())
+
+	code, _ = do("PUT", `{"level": "loud"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do("POST", `{}`)
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+}
@@ -0,0 +1,141 @@
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
+)
+
+const defaultMaterializedRefreshInterval = time.Hour
+
+func materializedRefreshInterval(materialized *cbTypes.ReportGenerationQueryMaterialization) time.Duration {
+	if materialized.RefreshInterval != nil && materialized.RefreshInterval.Duration > 0 {
+		return materialized.RefreshInterval.Duration
+	}
+	return defaultMaterializedRefreshInterval
+}
+
+// materializedPeriod returns the period of results which should be added to
+// the materialized table of the ReportGenerationQuery. Results are only
+// added up to the newest metric imported by all of the query's Prometheus
+// ReportDataSources, so a period is never materialized before it's data has
+// arrived. ok is false if there's nothing to materialize yet.
+func materializedPeriod(generationQuery *cbTypes.ReportGenerationQuery, dataSources []*cbTypes.ReportDataSource, now time.Time) (start, end time.Time, ok bool) {
+	materialized := generationQuery.Spec.Materialized
+	interval := materializedRefreshInterval(materialized)
+
+	end = now
+	var earliest *time.Time
+	for _, ds := range dataSources {
+		if ds.Spec.Promsum == nil {
+			continue
+		}
+		importStatus := ds.Status.PrometheusMetricImportStatus
+		if importStatus == nil || importStatus.NewestImportedMetricTime == nil || importStatus.EarliestImportedMetricTime == nil {
+			return start, end, false
+		}
+		if importStatus.NewestImportedMetricTime.Time.Before(end) {
+			end = importStatus.NewestImportedMetricTime.Time
+		}
+		if earliest == nil || importStatus.EarliestImportedMetricTime.Time.Before(*earliest) {
+			earliest = &importStatus.EarliestImportedMetricTime.Time
+		}
+	}
+	end = end.UTC().Truncate(interval)
+
+	switch {
+	case generationQuery.Status.Materialized != nil && generationQuery.Status.Materialized.MaterializedUntil != nil:
+		start = generationQuery.Status.Materialized.MaterializedUntil.Time
+	case materialized.Start != nil:
+		start = materialized.Start.Time
+	case earliest != nil:
+		start = *earliest
+	default:
+		start = generationQuery.CreationTimestamp.Time
+	}
+	start = start.UTC().Truncate(interval)
+	return start, end, end.After(start)
+}
+
+// refreshMaterializedQuery creates the materialized table of the
+// ReportGenerationQuery if it doesn't exist, and runs the query for each
+// refresh interval since the table was last refreshed, appending the results.
+// It requeues the ReportGenerationQuery to be refreshed again after the
+// refresh interval.
+func (op *Reporting) refreshMaterializedQuery(logger log.FieldLogger, generationQuery *cbTypes.ReportGenerationQuery, queryDependencies *reporting.ReportGenerationQueryDependencies) error {
+	if !generationQuery.Spec.View.Disabled {
+		err := fmt.Errorf("spec.view.disabled must be true when spec.materialized is set, as the query is run for each refresh interval")
+		return reasonErrorf(err, cbutil.ValidationErrorReason, "unable to validate ReportGenerationQuery %s: %v", generationQuery.Name, err)
+	}
+	interval := materializedRefreshInterval(generationQuery.Spec.Materialized)
+
+	statusChanged := false
+	if generationQuery.Status.Materialized == nil {
+		tableName := reportingutil.MaterializedQueryTableName(op.tableNamespace(generationQuery.Namespace), generationQuery.Name)
+		logger.Infof("creating materialized table %s", tableName)
+		columns := reportingutil.GenerateHiveColumns(generationQuery)
+		err := op.createTableForStorage(op.workCtx, logger, generationQuery, cbTypes.SchemeGroupVersion.WithKind("ReportGenerationQuery"), nil, tableName, columns, nil)
+		if err != nil {
+			return reasonErrorf(err, cbutil.HiveErrorReason, "unable to create materialized table %s for ReportGenerationQuery %s: %v", tableName, generationQuery.Name, err)
+		}
+		generationQuery.Status.Materialized = &cbTypes.ReportGenerationQueryMaterializedStatus{TableName: tableName}
+		statusChanged = true
+	}
+
+	pricing, err := op.getPricingPolicy(generationQuery.Namespace, "")
+	if err != nil {
+		return reasonErrorf(err, cbutil.ValidationErrorReason, "unable to get the default PricingPolicy for ReportGenerationQuery %s: %v", generationQuery.Name, err)
+	}
+
+	start, end, ok := materializedPeriod(generationQuery, queryDependencies.ReportDataSources, op.clock.Now())
+	var genErr error
+	if ok {
+		ctx, cancel := op.withReportQueryTimeout(op.workCtx)
+		defer cancel()
+		// other queries read the materialized table, so it's refreshed
+		// with the same priority as ScheduledReports
+		ctx = reporting.WithQueryPriority(ctx, reporting.QueryPriority{Class: reporting.ScheduledReportQueryClass, Period: interval})
+		ctx = reporting.WithPricing(ctx, pricing)
+		for periodStart := start; periodStart.Before(end); periodStart = periodStart.Add(interval) {
+			periodStart := periodStart
+			periodEnd := periodStart.Add(interval)
+			logger.Debugf("materializing results from %s to %s", periodStart, periodEnd)
+			genErr = op.reportGenerator.GenerateReport(ctx, generationQuery.Status.Materialized.TableName, &periodStart, &periodEnd, generationQuery, queryDependencies.DynamicReportGenerationQueries, nil, false)
+			if genErr != nil {
+				break
+			}
+			generationQuery.Status.Materialized.MaterializedUntil = &metav1.Time{Time: periodEnd}
+			generationQuery.Status.Materialized.LastRefreshTime = &metav1.Time{Time: op.clock.Now().UTC()}
+			statusChanged = true
+		}
+	} else {
+		logger.Debugf("no new data to materialize")
+	}
+
+	// record any progress made, even if a later period failed
+	if statusChanged {
+		newQuery, err := op.meteringClient.MeteringV1alpha1().ReportGenerationQueries(generationQuery.Namespace).Update(generationQuery)
+		if err != nil {
+			return fmt.Errorf("failed to update ReportGenerationQuery %s status.materialized: %v", generationQuery.Name, err)
+		}
+		*generationQuery = *newQuery
+	}
+	if genErr != nil {
+		return reasonErrorf(genErr, cbutil.PrestoErrorReason, "unable to materialize ReportGenerationQuery %s: %v", generationQuery.Name, genErr)
+	}
+
+	key, err := cache.MetaNamespaceKeyFunc(generationQuery)
+	if err != nil {
+		return err
+	}
+	op.reportGenerationQueryQueue.AddAfter(key, interval)
+	return nil
+}
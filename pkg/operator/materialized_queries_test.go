@@ -0,0 +1,83 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestMaterializedPeriod(t *testing.T) {
+	hour := func(h int) time.Time {
+		return time.Date(2019, time.January, 1, h, 0, 0, 0, time.UTC)
+	}
+	newDataSource := func(earliest, newest time.Time) *cbTypes.ReportDataSource {
+		return &cbTypes.ReportDataSource{
+			Spec: cbTypes.ReportDataSourceSpec{Promsum: &cbTypes.PrometheusMetricsDataSource{}},
+			Status: cbTypes.ReportDataSourceStatus{
+				PrometheusMetricImportStatus: &cbTypes.PrometheusMetricImportStatus{
+					EarliestImportedMetricTime: &metav1.Time{Time: earliest},
+					NewestImportedMetricTime:   &metav1.Time{Time: newest},
+				},
+			},
+		}
+	}
+	now := hour(12)
+	materializedUntil := metav1.NewTime(hour(6))
+
+	tests := []struct {
+		name          string
+		status        *cbTypes.ReportGenerationQueryMaterializedStatus
+		dataSources   []*cbTypes.ReportDataSource
+		expectedStart time.Time
+		expectedEnd   time.Time
+		expectedOK    bool
+	}{
+		{
+			name:          "starts from the earliest metric and stops at the oldest newest metric",
+			dataSources:   []*cbTypes.ReportDataSource{newDataSource(hour(2).Add(30*time.Minute), hour(10).Add(30*time.Minute)), newDataSource(hour(1), hour(11))},
+			expectedStart: hour(1),
+			expectedEnd:   hour(10),
+			expectedOK:    true,
+		},
+		{
+			name:          "continues from where it was materialized until",
+			status:        &cbTypes.ReportGenerationQueryMaterializedStatus{MaterializedUntil: &materializedUntil},
+			dataSources:   []*cbTypes.ReportDataSource{newDataSource(hour(1), hour(11))},
+			expectedStart: hour(6),
+			expectedEnd:   hour(11),
+			expectedOK:    true,
+		},
+		{
+			name:        "waits for metrics to be imported",
+			dataSources: []*cbTypes.ReportDataSource{{Spec: cbTypes.ReportDataSourceSpec{Promsum: &cbTypes.PrometheusMetricsDataSource{}}}},
+		},
+		{
+			name:          "nothing new to materialize",
+			status:        &cbTypes.ReportGenerationQueryMaterializedStatus{MaterializedUntil: &materializedUntil},
+			dataSources:   []*cbTypes.ReportDataSource{newDataSource(hour(1), hour(6).Add(59*time.Minute))},
+			expectedStart: hour(6),
+			expectedEnd:   hour(6),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			query := &cbTypes.ReportGenerationQuery{
+				Spec: cbTypes.ReportGenerationQuerySpec{
+					Materialized: &cbTypes.ReportGenerationQueryMaterialization{},
+				},
+				Status: cbTypes.ReportGenerationQueryStatus{Materialized: tt.status},
+			}
+			start, end, ok := materializedPeriod(query, tt.dataSources, now)
+			assert.Equal(t, tt.expectedOK, ok)
+			if tt.expectedOK || !tt.expectedStart.IsZero() {
+				assert.Equal(t, tt.expectedStart, start)
+				assert.Equal(t, tt.expectedEnd, end)
+			}
+		})
+	}
+}
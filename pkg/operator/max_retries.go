@@ -0,0 +1,135 @@
+package operator
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+)
+
+const (
+	// The default number of times each kind of resource is retried after
+	// failing to be processed, before reporting-operator gives up.
+	DefaultReportMaxRetries                = 5
+	DefaultScheduledReportMaxRetries       = 5
+	DefaultReportDataSourceMaxRetries      = 20
+	DefaultReportGenerationQueryMaxRetries = 10
+	DefaultPrestoTableMaxRetries           = 10
+
+	// retriesExhaustedEventReason is the reason of the Event recorded on a
+	// resource when reporting-operator gives up retrying it.
+	retriesExhaustedEventReason = "RetriesExhausted"
+)
+
+// MaxRetriesConfig configures how many times each kind of resource is
+// retried after failing to be processed. Once a resource has been retried
+// this many times, it's marked as failed in it's status.
+type MaxRetriesConfig struct {
+	Report                int
+	ScheduledReport       int
+	ReportDataSource      int
+	ReportGenerationQuery int
+	PrestoTable           int
+}
+
+func (cfg *MaxRetriesConfig) Valid() error {
+	for kind, retries := range map[string]int{
+		"Report":                cfg.Report,
+		"ScheduledReport":       cfg.ScheduledReport,
+		"ReportDataSource":      cfg.ReportDataSource,
+		"ReportGenerationQuery": cfg.ReportGenerationQuery,
+		"PrestoTable":           cfg.PrestoTable,
+	} {
+		if retries < 0 {
+			return fmt.Errorf("%s max retries must not be negative, got %d", kind, retries)
+		}
+	}
+	return nil
+}
+
+// recordRetriesExhausted marks the resource identified by key as failed,
+// after it's failed to be processed maxRetries times, so users can find
+// resources reporting-operator has stopped retrying.
+func (op *Reporting) recordRetriesExhausted(logger log.FieldLogger, objType, key string, maxRetries int, syncErr error) {
+	obj, err := op.getObjectForKey(objType, key)
+	if err != nil || obj == nil {
+		return
+	}
+	reason := errorReason(syncErr)
+	message := fmt.Sprintf("giving up after %d retries: %v", maxRetries, syncErr)
+	if op.eventRecorder != nil {
+		op.eventRecorder.Event(obj, v1.EventTypeWarning, retriesExhaustedEventReason, message)
+	}
+
+	failed := *cbutil.NewCondition(cbTypes.ConditionFailed, v1.ConditionTrue, reason, message)
+	client := op.meteringClient.MeteringV1alpha1()
+	switch obj := obj.(type) {
+	case *cbTypes.Report:
+		op.setReportError(logger, obj.DeepCopy(), reason, fmt.Errorf("%s", message), "giving up on Report %s", key)
+		return
+	case *cbTypes.ScheduledReport:
+		report := obj.DeepCopy()
+		cbutil.SetScheduledReportCondition(&report.Status, *cbutil.NewScheduledReportCondition(cbTypes.ScheduledReportFailure, v1.ConditionTrue, reason, message))
+		_, err = client.ScheduledReports(report.Namespace).Update(report)
+	case *cbTypes.ReportDataSource:
+		dataSource := obj.DeepCopy()
+		dataSource.Status.Conditions = cbutil.SetCondition(dataSource.Status.Conditions, failed)
+		_, err = client.ReportDataSources(dataSource.Namespace).Update(dataSource)
+	case *cbTypes.ReportGenerationQuery:
+		query := obj.DeepCopy()
+		query.Status.Conditions = cbutil.SetCondition(query.Status.Conditions, failed)
+		_, err = client.ReportGenerationQueries(query.Namespace).Update(query)
+	case *cbTypes.PrestoTable:
+		table := obj.DeepCopy()
+		table.Status.Conditions = cbutil.SetCondition(table.Status.Conditions, failed)
+		_, err = client.PrestoTables(table.Namespace).Update(table)
+	}
+	if err != nil {
+		logger.WithError(err).Errorf("unable to mark %s %s as failed", objType, key)
+	}
+}
+
+// clearRetriesExhausted removes the Failed condition added by
+// recordRetriesExhausted once the resource identified by key has been
+// processed successfully. Reports and ScheduledReports clear their own
+// failures.
+func (op *Reporting) clearRetriesExhausted(logger log.FieldLogger, objType, key string) {
+	obj, err := op.getObjectForKey(objType, key)
+	if err != nil || obj == nil {
+		return
+	}
+	client := op.meteringClient.MeteringV1alpha1()
+	switch obj := obj.(type) {
+	case *cbTypes.ReportDataSource:
+		if cbutil.GetCondition(obj.Status.Conditions, cbTypes.ConditionFailed) == nil {
+			return
+		}
+		dataSource := obj.DeepCopy()
+		dataSource.Status.Conditions = cbutil.RemoveCondition(dataSource.Status.Conditions, cbTypes.ConditionFailed)
+		_, err = client.ReportDataSources(dataSource.Namespace).Update(dataSource)
+	case *cbTypes.ReportGenerationQuery:
+		if cbutil.GetCondition(obj.Status.Conditions, cbTypes.ConditionFailed) == nil {
+			return
+		}
+		query := obj.DeepCopy()
+		query.Status.Conditions = cbutil.RemoveCondition(query.Status.Conditions, cbTypes.ConditionFailed)
+		_, err = client.ReportGenerationQueries(query.Namespace).Update(query)
+	case *cbTypes.PrestoTable:
+		if cbutil.GetCondition(obj.Status.Conditions, cbTypes.ConditionFailed) == nil {
+			return
+		}
+		table := obj.DeepCopy()
+		table.Status.Conditions = cbutil.RemoveCondition(table.Status.Conditions, cbTypes.ConditionFailed)
+		_, err = client.PrestoTables(table.Namespace).Update(table)
+	default:
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Errorf("unable to remove the Failed condition from %s %s", objType, key)
+		return
+	}
+	logger.Infof("removed the Failed condition from %s %s", objType, key)
+}
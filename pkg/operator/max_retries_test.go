@@ -0,0 +1,68 @@
+package operator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/fake"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+)
+
+func TestHandleErrRetriesExhausted(t *testing.T) {
+	dataSource := &cbTypes.ReportDataSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+	}
+	client := fake.NewSimpleClientset(dataSource.DeepCopy())
+	indexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, indexer.Add(dataSource))
+	recorder := record.NewFakeRecorder(10)
+	op := &Reporting{
+		logger:                 logrus.New(),
+		meteringClient:         client,
+		reportDataSourceLister: listers.NewReportDataSourceLister(indexer),
+		eventRecorder:          recorder,
+	}
+	queue := workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, time.Millisecond))
+	defer queue.ShutDown()
+
+	getDataSource := func() *cbTypes.ReportDataSource {
+		ds, err := client.MeteringV1alpha1().ReportDataSources("default").Get("test", metav1.GetOptions{})
+		require.NoError(t, err)
+		return ds
+	}
+
+	const maxRetries = 1
+	syncErr := errors.New("cannot create table")
+	op.handleErr(op.logger, syncErr, "ReportDataSource", "default/test", queue, maxRetries)
+	assert.Equal(t, 1, queue.NumRequeues("default/test"))
+	assert.Empty(t, getDataSource().Status.Conditions, "expected the ReportDataSource not to be marked as failed while it's being retried")
+	<-recorder.Events
+
+	op.handleErr(op.logger, syncErr, "ReportDataSource", "default/test", queue, maxRetries)
+	assert.Equal(t, 0, queue.NumRequeues("default/test"), "expected the ReportDataSource to be dropped from the queue")
+	<-recorder.Events
+	assert.Equal(t, "Warning RetriesExhausted giving up after 1 retries: cannot create table", <-recorder.Events)
+
+	failed := cbutil.GetCondition(getDataSource().Status.Conditions, cbTypes.ConditionFailed)
+	require.NotNil(t, failed)
+	assert.Equal(t, v1.ConditionTrue, failed.Status)
+	assert.Equal(t, cbutil.UnknownErrorReason, failed.Reason)
+	assert.Equal(t, "giving up after 1 retries: cannot create table", failed.Message)
+
+	// once it's processed successfully, the condition is removed
+	require.NoError(t, indexer.Update(getDataSource()))
+	op.handleErr(op.logger, nil, "ReportDataSource", "default/test", queue, maxRetries)
+	assert.Empty(t, getDataSource().Status.Conditions)
+}
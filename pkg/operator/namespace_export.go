@@ -0,0 +1,307 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/aws"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	NamespaceExportFormatCSV     = "csv"
+	NamespaceExportFormatParquet = "parquet"
+
+	// namespaceLabel is the Prometheus label identifying the namespace a
+	// metric belongs to.
+	namespaceLabel = "namespace"
+)
+
+var namespaceExportLocationSchemes = []string{"s3a", "s3", "hdfs"}
+
+type ExportNamespaceDataRequest struct {
+	Namespace string    `json:"namespace"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	// Format is the format to write the exported rows in, either csv or
+	// parquet.
+	Format string `json:"format"`
+	// Location is the URL of the directory to write the exported rows to,
+	// eg: s3a://bucket/offboarding/team-a. Each ReportDataSource is written
+	// to a sub-directory named after its table.
+	Location string `json:"location"`
+	// Encryption is the server-side encryption the bucket of an s3a:// or
+	// s3:// location must apply by default. If it doesn't, nothing is
+	// exported.
+	Encryption *cbTypes.S3Encryption `json:"encryption,omitempty"`
+	// Region is the region of the location's bucket, used when checking
+	// it's encryption.
+	Region string `json:"region,omitempty"`
+	// Purge controls whether the exported rows are deleted once every
+	// ReportDataSource has been exported successfully.
+	Purge bool `json:"purge"`
+}
+
+type ExportNamespaceDataResponse struct {
+	Results []*namespaceExportResult `json:"results"`
+}
+
+type namespaceExportResult struct {
+	ReportDataSource string `json:"reportDataSource"`
+	Namespace        string `json:"namespace"`
+	TableName        string `json:"tableName"`
+	Location         string `json:"location"`
+	Purged           bool   `json:"purged"`
+
+	granularity prestostore.PartitionGranularity
+}
+
+func (req *ExportNamespaceDataRequest) validate() error {
+	if req.Namespace == "" {
+		return fmt.Errorf("namespace must be set")
+	}
+	if errs := validation.IsDNS1123Label(req.Namespace); len(errs) != 0 {
+		return fmt.Errorf("invalid namespace %q: %s", req.Namespace, strings.Join(errs, ", "))
+	}
+	if req.StartTime.IsZero() || req.EndTime.IsZero() {
+		return fmt.Errorf("startTime and endTime must be set")
+	}
+	if !req.StartTime.Before(req.EndTime) {
+		return fmt.Errorf("startTime must be before endTime")
+	}
+	switch req.Format {
+	case NamespaceExportFormatCSV, NamespaceExportFormatParquet:
+	default:
+		return fmt.Errorf("format must be one of: %s or %s", NamespaceExportFormatCSV, NamespaceExportFormatParquet)
+	}
+	locationURL, err := url.Parse(req.Location)
+	if err != nil {
+		return fmt.Errorf("invalid location %q: %v", req.Location, err)
+	}
+	validScheme := false
+	for _, scheme := range namespaceExportLocationSchemes {
+		if locationURL.Scheme == scheme {
+			validScheme = true
+			break
+		}
+	}
+	if !validScheme {
+		return fmt.Errorf("location must be a URL with one of the following schemes: %s", strings.Join(namespaceExportLocationSchemes, ", "))
+	}
+	if strings.ContainsAny(req.Location, `"'`) {
+		return fmt.Errorf("location cannot contain quotes")
+	}
+	if req.Encryption != nil {
+		if locationURL.Scheme != "s3a" && locationURL.Scheme != "s3" {
+			return fmt.Errorf("encryption can only be set when location is an s3a:// or s3:// URL")
+		}
+		if _, err := s3EncryptionAlgorithm(req.Encryption); err != nil {
+			return fmt.Errorf("invalid encryption: %v", err)
+		}
+	}
+	return nil
+}
+
+// exportNamespaceDataHandler exports the rows collected by each Prometheus
+// ReportDataSource for a namespace within a time range, and optionally
+// purges them afterwards.
+func (op *Reporting) exportNamespaceDataHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(op.logger, r, op.rand)
+	if r.Method != "POST" {
+		writeErrorResponse(logger, w, r, http.StatusMethodNotAllowed, "only POST is supported")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	var req ExportNamespaceDataRequest
+	err := decoder.Decode(&req)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to decode request as JSON: %v", err)
+		return
+	}
+	req.StartTime = req.StartTime.UTC()
+	req.EndTime = req.EndTime.UTC()
+	if err := req.validate(); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if !op.resultsAuthorizer.authorizeRequest(logger, w, r, "get", resultsResourceReportDataSources, req.Namespace) {
+		return
+	}
+	if req.Purge && !op.resultsAuthorizer.authorizeRequest(logger, w, r, "update", resultsResourceReportDataSources, req.Namespace) {
+		return
+	}
+
+	results, err := op.exportNamespaceData(logger, req)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to export data for namespace %s: %v", req.Namespace, err)
+		return
+	}
+
+	writeResponseAsJSON(logger, w, http.StatusOK, ExportNamespaceDataResponse{
+		Results: results,
+	})
+}
+
+func (op *Reporting) exportNamespaceData(logger log.FieldLogger, req ExportNamespaceDataRequest) ([]*namespaceExportResult, error) {
+	logger = logger.WithFields(log.Fields{
+		"exportNamespace": req.Namespace,
+		"startTime":       req.StartTime,
+		"endTime":         req.EndTime,
+	})
+
+	if req.Encryption != nil {
+		// validate ensures the location is an S3 URL
+		locationURL, _ := url.Parse(req.Location)
+		if err := checkS3Encryption(req.Region, locationURL.Host, aws.S3Endpoint{}, "", req.Encryption); err != nil {
+			return nil, err
+		}
+	}
+
+	dataSources, err := op.reportDataSourceLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*namespaceExportResult
+	for _, dataSource := range dataSources {
+		if dataSource.Spec.Promsum == nil || dataSource.Status.TableName == "" || !op.cfg.isWatchedNamespace(dataSource.Namespace) {
+			continue
+		}
+		tableName := dataSource.Status.TableName
+		location := namespaceExportLocation(req.Location, tableName)
+		logger.Infof("exporting rows from ReportDataSource %s/%s table %s to %s", dataSource.Namespace, dataSource.Name, tableName, location)
+
+		err := op.exportNamespaceDataFromTable(tableName, location, req)
+		if err != nil {
+			return nil, fmt.Errorf("unable to export ReportDataSource %s/%s: %v", dataSource.Namespace, dataSource.Name, err)
+		}
+		results = append(results, &namespaceExportResult{
+			ReportDataSource: dataSource.Name,
+			Namespace:        dataSource.Namespace,
+			TableName:        tableName,
+			Location:         location,
+			granularity:      dataSourcePartitionGranularity(dataSource),
+		})
+	}
+
+	if !req.Purge {
+		return results, nil
+	}
+
+	// only purge once everything has been exported, so a failed export never
+	// results in data being lost.
+	for _, result := range results {
+		logger.Infof("purging exported rows from table %s", result.TableName)
+		err := op.purgeNamespaceDataFromTable(result.TableName, result.granularity, req)
+		if err != nil {
+			return nil, fmt.Errorf("unable to purge ReportDataSource %s/%s: %v", result.Namespace, result.ReportDataSource, err)
+		}
+		result.Purged = true
+	}
+	return results, nil
+}
+
+// exportNamespaceDataFromTable copies the rows matching the request into an
+// external table stored at location. The external table is dropped
+// afterwards, which leaves the files written to location intact.
+func (op *Reporting) exportNamespaceDataFromTable(tableName, location string, req ExportNamespaceDataRequest) error {
+	exportTableName := fmt.Sprintf("%s_export_%d", tableName, op.clock.Now().Unix())
+	params := hive.TableParameters{
+		Name:    exportTableName,
+		Columns: append(append([]hive.Column{}, promsumHiveColumns...), promsumHivePartitions...),
+	}
+	properties := namespaceExportTableProperties(req.Format, location)
+	if err := op.tableManager.CreateTable(op.workCtx, params, properties); err != nil {
+		return fmt.Errorf("unable to create export table %s: %v", exportTableName, err)
+	}
+	defer func() {
+		if err := op.tableManager.DropTable(op.workCtx, exportTableName, true); err != nil {
+			op.logger.WithError(err).Warnf("unable to drop export table %s", exportTableName)
+		}
+	}()
+
+	query := fmt.Sprintf("SELECT amount, `timestamp`, timeprecision, labels, cluster_id, dt FROM %s WHERE %s AND %s",
+		tableName, namespaceExportPartitionFilterSQL(req.StartTime, req.EndTime), namespaceDataFilterSQL(req))
+	if err := op.tableManager.InsertIntoTable(op.workCtx, exportTableName, query); err != nil {
+		return fmt.Errorf("unable to write rows to export table %s: %v", exportTableName, err)
+	}
+	return nil
+}
+
+// purgeNamespaceDataFromTable removes the rows matching the request from the
+// table. Hive tables can't have individual rows deleted, so each partition
+// within the time range is rewritten without them.
+func (op *Reporting) purgeNamespaceDataFromTable(tableName string, granularity prestostore.PartitionGranularity, req ExportNamespaceDataRequest) error {
+	for _, dt := range namespaceExportPartitions(granularity, req.StartTime, req.EndTime) {
+		query := fmt.Sprintf("SELECT amount, `timestamp`, timeprecision, labels, cluster_id FROM %s WHERE dt = '%s' AND NOT (%s)",
+			tableName, dt, namespaceDataFilterSQL(req))
+		if err := op.tableManager.InsertOverwritePartition(op.workCtx, tableName, "dt", dt, query); err != nil {
+			return fmt.Errorf("unable to rewrite partition dt=%s: %v", dt, err)
+		}
+	}
+	return nil
+}
+
+// namespaceDataFilterSQL returns a Hive boolean expression matching the rows
+// for the requested namespace and time range. It never evaluates to NULL, so
+// it can safely be negated.
+func namespaceDataFilterSQL(req ExportNamespaceDataRequest) string {
+	return fmt.Sprintf("(coalesce(labels['%s'], '') = '%s' AND `timestamp` >= timestamp '%s' AND `timestamp` < timestamp '%s')",
+		namespaceLabel, req.Namespace,
+		req.StartTime.Format(presto.TimestampFormat), req.EndTime.Format(presto.TimestampFormat),
+	)
+}
+
+// namespaceExportPartitionFilterSQL returns a Hive boolean expression
+// limiting a query to the partitions which may contain rows between start
+// and end, regardless of the table's partition granularity.
+func namespaceExportPartitionFilterSQL(start, end time.Time) string {
+	return fmt.Sprintf("dt >= '%s' AND dt <= '%s'",
+		prestostore.PrometheusMetricPartitionRangeStart(start),
+		prestostore.PrometheusMetricPartitionRangeEnd(end),
+	)
+}
+
+// namespaceExportPartitions returns the dt partition values of a table
+// partitioned using granularity which may contain rows between start and
+// end. The end time is exclusive, so a range ending at midnight doesn't
+// include the following day.
+func namespaceExportPartitions(granularity prestostore.PartitionGranularity, start, end time.Time) []string {
+	return granularity.Partitions(start, end)
+}
+
+func namespaceExportLocation(location, tableName string) string {
+	return strings.TrimSuffix(location, "/") + "/" + tableName + "/"
+}
+
+func namespaceExportTableProperties(format, location string) hive.TableProperties {
+	properties := hive.TableProperties{
+		Location: location,
+		External: true,
+	}
+	switch format {
+	case NamespaceExportFormatParquet:
+		properties.FileFormat = "parquet"
+	case NamespaceExportFormatCSV:
+		properties.FileFormat = "textfile"
+		properties.SerdeFormat = "org.apache.hadoop.hive.serde2.lazy.LazySimpleSerDe"
+		properties.SerdeRowProperties = map[string]string{
+			"field.delim":      ",",
+			"collection.delim": "|",
+			"mapkey.delim":     "=",
+		}
+	}
+	return properties
+}
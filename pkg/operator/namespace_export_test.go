@@ -0,0 +1,122 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+func TestNamespaceExportPartitions(t *testing.T) {
+	tests := []struct {
+		name        string
+		granularity prestostore.PartitionGranularity
+		start       time.Time
+		end         time.Time
+		expected    []string
+	}{
+		{
+			name:     "within a single day",
+			start:    time.Date(2019, time.January, 1, 1, 0, 0, 0, time.UTC),
+			end:      time.Date(2019, time.January, 1, 5, 0, 0, 0, time.UTC),
+			expected: []string{"2019-01-01"},
+		},
+		{
+			name:     "ending at midnight excludes the following day",
+			start:    time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+			end:      time.Date(2019, time.January, 3, 0, 0, 0, 0, time.UTC),
+			expected: []string{"2019-01-01", "2019-01-02"},
+		},
+		{
+			name:     "partial days at both ends",
+			start:    time.Date(2019, time.January, 31, 23, 0, 0, 0, time.UTC),
+			end:      time.Date(2019, time.February, 2, 1, 0, 0, 0, time.UTC),
+			expected: []string{"2019-01-31", "2019-02-01", "2019-02-02"},
+		},
+		{
+			name:        "hourly",
+			granularity: prestostore.PartitionGranularityHourly,
+			start:       time.Date(2019, time.January, 1, 22, 30, 0, 0, time.UTC),
+			end:         time.Date(2019, time.January, 2, 1, 0, 0, 0, time.UTC),
+			expected:    []string{"2019-01-01T22", "2019-01-01T23", "2019-01-02T00"},
+		},
+		{
+			name:        "weekly",
+			granularity: prestostore.PartitionGranularityWeekly,
+			start:       time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+			end:         time.Date(2019, time.January, 14, 0, 0, 0, 0, time.UTC),
+			expected:    []string{"2018-12-31", "2019-01-07"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, namespaceExportPartitions(tt.granularity, tt.start, tt.end))
+		})
+	}
+}
+
+func TestExportNamespaceDataRequestValidate(t *testing.T) {
+	start := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	tests := []struct {
+		name      string
+		req       ExportNamespaceDataRequest
+		expectErr bool
+	}{
+		{
+			name:      "valid",
+			req:       ExportNamespaceDataRequest{Namespace: "team-a", StartTime: start, EndTime: end, Format: "parquet", Location: "s3a://bucket/export"},
+			expectErr: false,
+		},
+		{
+			name:      "invalid namespace",
+			req:       ExportNamespaceDataRequest{Namespace: "team-a' OR '1'='1", StartTime: start, EndTime: end, Format: "csv", Location: "s3a://bucket/export"},
+			expectErr: true,
+		},
+		{
+			name:      "start after end",
+			req:       ExportNamespaceDataRequest{Namespace: "team-a", StartTime: end, EndTime: start, Format: "csv", Location: "s3a://bucket/export"},
+			expectErr: true,
+		},
+		{
+			name:      "unknown format",
+			req:       ExportNamespaceDataRequest{Namespace: "team-a", StartTime: start, EndTime: end, Format: "orc", Location: "s3a://bucket/export"},
+			expectErr: true,
+		},
+		{
+			name:      "kms encryption",
+			req:       ExportNamespaceDataRequest{Namespace: "team-a", StartTime: start, EndTime: end, Format: "parquet", Location: "s3a://bucket/export", Encryption: &cbTypes.S3Encryption{Type: cbTypes.S3EncryptionTypeSSEKMS, KMSKeyID: "arn:aws:kms:us-east-1:123456789012:key/example"}},
+			expectErr: false,
+		},
+		{
+			name:      "kms encryption without key",
+			req:       ExportNamespaceDataRequest{Namespace: "team-a", StartTime: start, EndTime: end, Format: "parquet", Location: "s3a://bucket/export", Encryption: &cbTypes.S3Encryption{Type: cbTypes.S3EncryptionTypeSSEKMS}},
+			expectErr: true,
+		},
+		{
+			name:      "encryption of hdfs location",
+			req:       ExportNamespaceDataRequest{Namespace: "team-a", StartTime: start, EndTime: end, Format: "parquet", Location: "hdfs://hdfs-namenode-proxy:9820/export", Encryption: &cbTypes.S3Encryption{Type: cbTypes.S3EncryptionTypeSSES3}},
+			expectErr: true,
+		},
+		{
+			name:      "unsupported location",
+			req:       ExportNamespaceDataRequest{Namespace: "team-a", StartTime: start, EndTime: end, Format: "csv", Location: "/tmp/export"},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.validate()
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	defaultNamespaceMetadataRefreshInterval = time.Hour
+	// namespaceMetadataStoreTimeout is the maximum amount of time spent
+	// storing a snapshot of namespace metadata.
+	namespaceMetadataStoreTimeout = 5 * time.Minute
+)
+
+var (
+	namespaceMetadataHiveColumns = []hive.Column{
+		{Name: "timestamp", Type: "timestamp"},
+		{Name: "namespace", Type: "string"},
+		{Name: "labels", Type: "string"},
+		{Name: "annotations", Type: "string"},
+	}
+	namespaceMetadataPrestoColumns = []presto.Column{
+		{Name: "timestamp", Type: "timestamp"},
+		{Name: "namespace", Type: "varchar"},
+		{Name: "labels", Type: "varchar"},
+		{Name: "annotations", Type: "varchar"},
+	}
+)
+
+func (op *Reporting) handleNamespaceMetadataDataSource(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	source := dataSource.Spec.NamespaceMetadata
+	if source.RefreshInterval != nil && source.RefreshInterval.Duration <= 0 {
+		return reasonErrorf(nil, cbutil.ValidationErrorReason, "ReportDataSource %q: improperly configured datasource, refreshInterval must be positive", dataSource.Name)
+	}
+
+	if dataSource.Status.TableName != "" {
+		logger.Infof("existing namespaceMetadata ReportDataSource discovered, tableName: %s", dataSource.Status.TableName)
+	} else {
+		logger.Infof("new namespaceMetadata ReportDataSource discovered")
+		tableName := op.dataSourceTableName(dataSource)
+		err := op.createTableForStorage(op.workCtx, logger, dataSource, cbTypes.SchemeGroupVersion.WithKind("ReportDataSource"), source.Storage, tableName, namespaceMetadataHiveColumns, nil)
+		if err != nil {
+			return err
+		}
+
+		dataSource, err = op.updateDataSourceTableName(logger, dataSource, tableName)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to update ReportDataSource TableName field %q", tableName)
+			return err
+		}
+	}
+
+	refreshInterval := defaultNamespaceMetadataRefreshInterval
+	if source.RefreshInterval != nil {
+		refreshInterval = source.RefreshInterval.Duration
+	}
+
+	now := op.clock.Now().UTC()
+	if status := dataSource.Status.NamespaceMetadataStatus; status != nil && status.LastSnapshotTime != nil {
+		nextSnapshot := status.LastSnapshotTime.Add(refreshInterval)
+		if now.Before(nextSnapshot) {
+			logger.Debugf("namespaceMetadata ReportDataSource %s was last snapshotted at %s, queuing to snapshot again at %s", dataSource.Name, status.LastSnapshotTime.Time, nextSnapshot)
+			op.enqueueReportDataSourceAfter(dataSource, nextSnapshot.Sub(now))
+			return nil
+		}
+	}
+
+	namespaces, err := op.kubeClient.Namespaces().List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list namespaces for ReportDataSource %s: %v", dataSource.Name, err)
+	}
+	rows, err := namespaceMetadataRows(namespaces.Items, source, now)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), namespaceMetadataStoreTimeout)
+	defer cancel()
+
+	err = op.sqlRowsRepo.StoreRows(ctx, dataSource.Status.TableName, namespaceMetadataPrestoColumns, rows)
+	if err != nil {
+		return reasonErrorf(err, cbutil.PrestoErrorReason, "unable to store namespace metadata for ReportDataSource %s: %v", dataSource.Name, err)
+	}
+	logger.Infof("stored metadata of %d namespaces in table %s", len(rows), dataSource.Status.TableName)
+
+	lastSnapshotTime := metav1.NewTime(now)
+	dataSource.Status.NamespaceMetadataStatus = &cbTypes.NamespaceMetadataStatus{
+		LastSnapshotTime: &lastSnapshotTime,
+		Namespaces:       int64(len(rows)),
+	}
+	dataSource, err = op.meteringClient.MeteringV1alpha1().ReportDataSources(dataSource.Namespace).Update(dataSource)
+	if err != nil {
+		return fmt.Errorf("unable to update ReportDataSource %s NamespaceMetadataStatus: %v", dataSource.Name, err)
+	}
+
+	nextSnapshot := op.clock.Now().Add(refreshInterval).UTC()
+	logger.Infof("queuing namespaceMetadata ReportDataSource %s to snapshot again in %s at %s", dataSource.Name, refreshInterval, nextSnapshot)
+	op.enqueueReportDataSourceAfter(dataSource, refreshInterval)
+	return nil
+}
+
+// namespaceMetadataRows returns a row for each namespace containing the
+// labels and annotations configured in source, encoded as JSON objects.
+func namespaceMetadataRows(namespaces []corev1.Namespace, source *cbTypes.NamespaceMetadataDataSource, timestamp time.Time) ([][]interface{}, error) {
+	rows := make([][]interface{}, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		labels, err := json.Marshal(selectKeys(namespace.Labels, source.Labels))
+		if err != nil {
+			return nil, err
+		}
+		annotations, err := json.Marshal(selectKeys(namespace.Annotations, source.Annotations))
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, []interface{}{timestamp, namespace.Name, string(labels), string(annotations)})
+	}
+	return rows, nil
+}
+
+// selectKeys returns the entries of m whose key is in keys. If keys is
+// empty every entry is returned.
+func selectKeys(m map[string]string, keys []string) map[string]string {
+	selected := make(map[string]string)
+	if len(keys) == 0 {
+		for k, v := range m {
+			selected[k] = v
+		}
+		return selected
+	}
+	for _, k := range keys {
+		if v, ok := m[k]; ok {
+			selected[k] = v
+		}
+	}
+	return selected
+}
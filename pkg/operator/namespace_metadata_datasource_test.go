@@ -0,0 +1,64 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestNamespaceMetadataRows(t *testing.T) {
+	timestamp := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	namespaces := []corev1.Namespace{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "payments",
+				Labels:      map[string]string{"environment": "production", "team": "payments"},
+				Annotations: map[string]string{"cost-center": "1234", "owner": "alice"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		source   cbTypes.NamespaceMetadataDataSource
+		expected [][]interface{}
+	}{
+		{
+			name: "every label and annotation",
+			expected: [][]interface{}{
+				{timestamp, "payments", `{"environment":"production","team":"payments"}`, `{"cost-center":"1234","owner":"alice"}`},
+				{timestamp, "default", `{}`, `{}`},
+			},
+		},
+		{
+			name: "selected labels and annotations",
+			source: cbTypes.NamespaceMetadataDataSource{
+				Labels:      []string{"environment", "missing"},
+				Annotations: []string{"cost-center"},
+			},
+			expected: [][]interface{}{
+				{timestamp, "payments", `{"environment":"production"}`, `{"cost-center":"1234"}`},
+				{timestamp, "default", `{}`, `{}`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := namespaceMetadataRows(namespaces, &tt.source, timestamp)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rows)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package operator
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
+)
+
+// multiNamespace returns true if the operator is configured to watch
+// resources outside of the namespace it's running in.
+func (cfg *Config) multiNamespace() bool {
+	return cfg.AllNamespaces || len(cfg.TargetNamespaces) != 0
+}
+
+// watchNamespace returns the namespace our informers should watch.
+func (cfg *Config) watchNamespace() string {
+	if cfg.multiNamespace() {
+		return metav1.NamespaceAll
+	}
+	return cfg.Namespace
+}
+
+// isWatchedNamespace returns true if resources in the namespace should be
+// processed by the operator.
+func (cfg *Config) isWatchedNamespace(namespace string) bool {
+	if cfg.AllNamespaces {
+		return true
+	}
+	if len(cfg.TargetNamespaces) == 0 {
+		return namespace == cfg.Namespace
+	}
+	for _, ns := range cfg.TargetNamespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// inWatchedNamespace is a cache.FilteringResourceEventHandler FilterFunc
+// which drops events for resources outside of our watched namespaces.
+func (op *Reporting) inWatchedNamespace(obj interface{}) bool {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		op.logger.WithError(err).Errorf("couldn't get object metadata for %#v", obj)
+		return false
+	}
+	return op.cfg.isWatchedNamespace(accessor.GetNamespace())
+}
+
+// tableNamespace returns the namespace to use when generating table and view
+// names for resources in the namespace. When watching multiple namespaces,
+// table names include the namespace to isolate resources with the same name
+// in different namespaces.
+func (op *Reporting) tableNamespace(namespace string) string {
+	if op.cfg.multiNamespace() {
+		return namespace
+	}
+	return ""
+}
+
+// dataSourceTableName returns the name of the table for the ReportDataSource,
+// preferring the table name already recorded in it's status.
+func (op *Reporting) dataSourceTableName(dataSource *cbTypes.ReportDataSource) string {
+	if dataSource.Status.TableName != "" {
+		return dataSource.Status.TableName
+	}
+	return reportingutil.DataSourceTableName(op.tableNamespace(dataSource.Namespace), dataSource.Name)
+}
+
+// getClusterUID returns the UID of the kube-system namespace, which is
+// created with the cluster and never deleted, so it identifies the cluster.
+func (op *Reporting) getClusterUID() (string, error) {
+	ns, err := op.kubeClient.Namespaces().Get(metav1.NamespaceSystem, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	return string(ns.UID), nil
+}
@@ -0,0 +1,249 @@
+package operator
+
+import (
+	"net/http"
+)
+
+const APIOpenAPISpecEndpoint = "/api/openapi.json"
+
+// OpenAPISpec is the OpenAPI 3 document describing the report results API.
+// It's served at APIOpenAPISpecEndpoint, and printed by the
+// reporting-operator's openapi command, which is used to generate clients.
+// Keep it in sync with the handlers in http.go and http_list.go, and the Go
+// client in pkg/apiclient.
+const OpenAPISpec = `{
+  "openapi": "3.0.0",
+  "info": {
+    "title": "reporting-operator results API",
+    "description": "Lists Reports and ScheduledReports, and downloads their results.",
+    "version": "v1"
+  },
+  "paths": {
+    "/api/v1/reports": {
+      "get": {
+        "operationId": "listReports",
+        "summary": "Lists the Reports in a namespace.",
+        "parameters": [
+          {"$ref": "#/components/parameters/namespace"}
+        ],
+        "responses": {
+          "200": {
+            "description": "The Reports in the namespace.",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ListReportsResponse"}}}
+          },
+          "default": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/api/v1/scheduledreports": {
+      "get": {
+        "operationId": "listScheduledReports",
+        "summary": "Lists the ScheduledReports in a namespace.",
+        "parameters": [
+          {"$ref": "#/components/parameters/namespace"}
+        ],
+        "responses": {
+          "200": {
+            "description": "The ScheduledReports in the namespace.",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ListReportsResponse"}}}
+          },
+          "default": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/api/v1/reports/get": {
+      "get": {
+        "operationId": "getReportResultsV1",
+        "summary": "Returns the results of a finished Report, with hidden columns removed.",
+        "parameters": [
+          {"$ref": "#/components/parameters/name"},
+          {"$ref": "#/components/parameters/namespace"},
+          {"$ref": "#/components/parameters/format"}
+        ],
+        "responses": {
+          "200": {"$ref": "#/components/responses/ResultsV1"},
+          "202": {"$ref": "#/components/responses/NotReady"},
+          "default": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/api/v1/scheduledreports/get": {
+      "get": {
+        "operationId": "getScheduledReportResults",
+        "summary": "Returns the results of every run of a ScheduledReport, with hidden columns removed.",
+        "parameters": [
+          {"$ref": "#/components/parameters/name"},
+          {"$ref": "#/components/parameters/namespace"},
+          {"$ref": "#/components/parameters/format"},
+          {
+            "name": "ignore_failed",
+            "in": "query",
+            "description": "Returns the results even if the ScheduledReport's most recent run failed.",
+            "schema": {"type": "boolean"}
+          }
+        ],
+        "responses": {
+          "200": {"$ref": "#/components/responses/ResultsV1"},
+          "202": {"$ref": "#/components/responses/NotReady"},
+          "default": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/api/v2/reports/{name}/full": {
+      "get": {
+        "operationId": "getReportResultsFull",
+        "summary": "Returns the results of a finished Report, including hidden columns and the unit of each value.",
+        "parameters": [
+          {"$ref": "#/components/parameters/pathName"},
+          {"$ref": "#/components/parameters/namespace"},
+          {"$ref": "#/components/parameters/format"}
+        ],
+        "responses": {
+          "200": {"$ref": "#/components/responses/ResultsV2"},
+          "202": {"$ref": "#/components/responses/NotReady"},
+          "default": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/api/v2/reports/{name}/table": {
+      "get": {
+        "operationId": "getReportResultsTable",
+        "summary": "Returns the results of a finished Report, without hidden columns, including the unit of each value.",
+        "parameters": [
+          {"$ref": "#/components/parameters/pathName"},
+          {"$ref": "#/components/parameters/namespace"},
+          {"$ref": "#/components/parameters/format"}
+        ],
+        "responses": {
+          "200": {"$ref": "#/components/responses/ResultsV2"},
+          "202": {"$ref": "#/components/responses/NotReady"},
+          "default": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "name": {
+        "name": "name",
+        "in": "query",
+        "required": true,
+        "description": "The name of the report.",
+        "schema": {"type": "string"}
+      },
+      "pathName": {
+        "name": "name",
+        "in": "path",
+        "required": true,
+        "description": "The name of the report.",
+        "schema": {"type": "string"}
+      },
+      "namespace": {
+        "name": "namespace",
+        "in": "query",
+        "description": "The namespace of the report. Defaults to the namespace reporting-operator runs in.",
+        "schema": {"type": "string"}
+      },
+      "format": {
+        "name": "format",
+        "in": "query",
+        "required": true,
+        "description": "The format of the results.",
+        "schema": {"type": "string", "enum": ["json", "csv", "tab", "tabular", "html"]}
+      }
+    },
+    "responses": {
+      "ResultsV1": {
+        "description": "The results, with a JSON object for each row when format is json.",
+        "content": {
+          "application/json": {"schema": {"type": "array", "items": {"type": "object", "additionalProperties": true}}},
+          "text/csv": {"schema": {"type": "string"}},
+          "text/plain": {"schema": {"type": "string"}},
+          "text/html": {"schema": {"type": "string"}}
+        }
+      },
+      "ResultsV2": {
+        "description": "The results.",
+        "content": {
+          "application/json": {"schema": {"$ref": "#/components/schemas/GetReportResults"}},
+          "text/csv": {"schema": {"type": "string"}},
+          "text/plain": {"schema": {"type": "string"}},
+          "text/html": {"schema": {"type": "string"}}
+        }
+      },
+      "NotReady": {
+        "description": "The report is still running, or hasn't been processed yet.",
+        "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}
+      },
+      "Error": {
+        "description": "The request failed.",
+        "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}
+      }
+    },
+    "schemas": {
+      "Error": {
+        "type": "object",
+        "properties": {
+          "error": {"type": "string"}
+        }
+      },
+      "ListReportsResponse": {
+        "type": "object",
+        "properties": {
+          "reports": {"type": "array", "items": {"$ref": "#/components/schemas/ReportSummary"}}
+        }
+      },
+      "ReportSummary": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "namespace": {"type": "string"},
+          "phase": {"type": "string", "description": "The phase of a Report, or for a ScheduledReport, either Running, Finished or Failed."},
+          "periodStart": {"type": "string", "format": "date-time"},
+          "periodEnd": {"type": "string", "format": "date-time"},
+          "rowCount": {"type": "integer", "format": "int64"},
+          "resultURLs": {
+            "type": "object",
+            "description": "The URL of the results in each format, relative to the API's address. Only present once results are available.",
+            "additionalProperties": {"type": "string"}
+          }
+        }
+      },
+      "GetReportResults": {
+        "type": "object",
+        "properties": {
+          "results": {"type": "array", "items": {"$ref": "#/components/schemas/ReportResultEntry"}}
+        }
+      },
+      "ReportResultEntry": {
+        "type": "object",
+        "properties": {
+          "values": {"type": "array", "items": {"$ref": "#/components/schemas/ReportResultValue"}}
+        }
+      },
+      "ReportResultValue": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "value": {},
+          "tableHidden": {"type": "boolean"},
+          "unit": {"type": "string"}
+        }
+      }
+    }
+  }
+}
+`
+
+func (srv *server) openAPISpecHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if r.Method != "GET" {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "Not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(OpenAPISpec)); err != nil {
+		logger.WithError(err).Error("failed writing HTTP response")
+	}
+}
@@ -0,0 +1,34 @@
+package operator
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+)
+
+func TestOpenAPISpecPaths(t *testing.T) {
+	var spec struct {
+		Paths map[string]map[string]interface{} `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(OpenAPISpec), &spec), "expected OpenAPISpec to be valid JSON")
+	require.NotEmpty(t, spec.Paths)
+
+	router := newRouter(testLogger, testRand, nil, nil, noopPrometheusImporterFunc, "default", reporting.DefaultCurrency, nil, nil, nil, nil, nil, routerOptions{})
+	routes := make(map[string]bool)
+	err := chi.Walk(router, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		routes[route] = true
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.True(t, routes[APIOpenAPISpecEndpoint], "expected the OpenAPI document to be served")
+	for path := range spec.Paths {
+		assert.True(t, routes[path], "expected path %s of the OpenAPI document to be routed", path)
+	}
+}
@@ -2,24 +2,31 @@ package operator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
+	"net"
 	"net/http"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/go-chi/chi"
 	_ "github.com/prestodb/presto-go-client/presto"
 	promapi "github.com/prometheus/client_golang/api"
 	prom "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
-	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes/scheme"
+	authenticationv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
 	"k8s.io/client-go/rest"
@@ -32,31 +39,48 @@ import (
 	"k8s.io/client-go/util/workqueue"
 
 	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/athena"
+	"github.com/operator-framework/operator-metering/pkg/bigquery"
+	"github.com/operator-framework/operator-metering/pkg/clickhouse"
 	"github.com/operator-framework/operator-metering/pkg/db"
 	cbClientset "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned"
+	cbScheme "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/scheme"
 	factory "github.com/operator-framework/operator-metering/pkg/generated/informers/externalversions"
 	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
 	"github.com/operator-framework/operator-metering/pkg/hive"
 	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
 	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
 	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/tracing"
 	_ "github.com/operator-framework/operator-metering/pkg/util/reflector/prometheus" // for prometheus metric registration
-	_ "github.com/operator-framework/operator-metering/pkg/util/workqueue/prometheus" // for prometheus metric registration
+	workqueueprometheus "github.com/operator-framework/operator-metering/pkg/util/workqueue/prometheus"
 )
 
 const (
-	connBackoff         = time.Second * 15
-	maxConnRetries      = 3
-	defaultResyncPeriod = time.Minute * 15
+	connBackoff    = time.Second * 15
+	maxConnRetries = 3
 
-	serviceServingCAFile = "/var/run/secrets/kubernetes.io/serviceaccount/service-ca.crt"
-	prestoUsername       = "reporting-operator"
+	serviceServingCAFile    = "/var/run/secrets/kubernetes.io/serviceaccount/service-ca.crt"
+	serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	prestoUsername          = "reporting-operator"
+
+	// redactedValue replaces credentials in logged configuration.
+	redactedValue = "<redacted>"
 
 	DefaultPrometheusQueryInterval                       = time.Minute * 5  // Query Prometheus every 5 minutes
 	DefaultPrometheusQueryStepSize                       = time.Minute      // Query data from Prometheus at a 60 second resolution (one data point per minute max)
 	DefaultPrometheusQueryChunkSize                      = 5 * time.Minute  // the default value for how much data we will insert into Presto per Prometheus query.
 	DefaultPrometheusDataSourceMaxQueryRangeDuration     = 10 * time.Minute // how much data we will query from Prometheus at once
 	DefaultPrometheusDataSourceMaxBackfillImportDuration = 2 * time.Hour    // how far we will query for backlogged data.
+
+	DefaultReportDataSourceWorkers = 4
+
+	DefaultHiveConnBackoff    = connBackoff
+	DefaultHiveMaxConnRetries = maxConnRetries
+
+	DefaultLeaderRetryPeriod    = 2 * time.Second
+	DefaultStandbyCheckInterval = time.Minute
+	DefaultShutdownDrainTimeout = 20 * time.Second
 )
 
 type TLSConfig struct {
@@ -81,6 +105,69 @@ type PrometheusConfig struct {
 	Address       string
 	SkipTLSVerify bool
 	BearerToken   string
+	// BearerTokenFile is a file containing the bearer token, used when
+	// BearerToken is empty.
+	BearerTokenFile string
+	// UseServiceAccountToken authenticates using the token of the service
+	// account the operator is running as.
+	UseServiceAccountToken bool
+
+	BasicAuthUsername string
+	BasicAuthPassword string
+
+	CAFile         string
+	ClientCertFile string
+	ClientKeyFile  string
+	TLSServerName  string
+
+	// QueryQPS limits the number of queries per second sent to each
+	// Prometheus instance. 0 disables the limit.
+	QueryQPS float64
+	// MaxInFlightQueries limits the number of queries running at once
+	// against each Prometheus instance. 0 disables the limit.
+	MaxInFlightQueries int
+}
+
+func (cfg *PrometheusConfig) Valid() error {
+	hasToken := cfg.BearerToken != "" || cfg.BearerTokenFile != "" || cfg.UseServiceAccountToken
+	if cfg.BasicAuthUsername != "" && hasToken {
+		return fmt.Errorf("Prometheus basic auth and bearer token authentication cannot both be configured")
+	}
+	if (cfg.ClientCertFile == "") != (cfg.ClientKeyFile == "") {
+		return fmt.Errorf("Must set both the Prometheus client certificate and key, or neither")
+	}
+	return nil
+}
+
+// redacted returns a copy of cfg with it's credentials replaced, so it can be
+// logged.
+func (cfg PrometheusConfig) redacted() PrometheusConfig {
+	if cfg.BearerToken != "" {
+		cfg.BearerToken = redactedValue
+	}
+	if cfg.BasicAuthPassword != "" {
+		cfg.BasicAuthPassword = redactedValue
+	}
+	return cfg
+}
+
+// bearerToken returns the configured bearer token, reading it from a file if
+// necessary.
+func (cfg *PrometheusConfig) bearerToken() (string, error) {
+	tokenFile := cfg.BearerTokenFile
+	switch {
+	case cfg.BearerToken != "":
+		return cfg.BearerToken, nil
+	case tokenFile == "" && cfg.UseServiceAccountToken:
+		tokenFile = serviceAccountTokenFile
+	case tokenFile == "":
+		return "", nil
+	}
+	token, err := ioutil.ReadFile(tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("unable to read Prometheus bearer token file: %v", err)
+	}
+	return strings.TrimSpace(string(token)), nil
 }
 
 type Config struct {
@@ -88,10 +175,88 @@ type Config struct {
 	Namespace  string
 	Kubeconfig string
 
+	// AllNamespaces causes the operator to process resources in all
+	// namespaces instead of only Namespace.
+	AllNamespaces bool
+	// TargetNamespaces is a list of namespaces to process resources in. If
+	// set, Namespace is only used for leader election and events.
+	TargetNamespaces []string
+
+	// QueryBackend is the name of the query backend used to run queries and
+	// manage tables. When it's not QueryBackendPresto, Presto and Hive aren't
+	// used, and the query backend's config configures it's connection.
+	QueryBackend     string
+	AthenaConfig     athena.Config
+	BigQueryConfig   bigquery.Config
+	ClickHouseConfig clickhouse.Config
+
+	// HiveMetastoreHost is the hostname:port of the Hive Metastore's Thrift
+	// API. If set, tables and partitions are managed using the Metastore
+	// directly instead of HiveServer2.
+	HiveMetastoreHost string
+
+	// HiveTLSConfig configures TLS for connections to HiveServer2.
+	HiveTLSConfig hive.TLSConfig
+	// HiveConnBackoff is how long to wait after the first failed attempt to
+	// connect to HiveServer2 or the Hive Metastore, and HiveMaxConnRetries is
+	// how many attempts are made. HiveMaxConnRetries also limits how many
+	// times queries failing due to closed connections are retried.
+	HiveConnBackoff    time.Duration
+	HiveMaxConnRetries int
+	// HiveQueryTimeout, if non-zero, is the timeout of queries run using
+	// HiveServer2.
+	HiveQueryTimeout time.Duration
+
+	// TableCompression, if set, is the codec used to compress the files of
+	// datasource and report tables, one of none, snappy, zstd or gzip. It's
+	// used for tables whose StorageLocation doesn't configure compression.
+	TableCompression string
+
+	// Currency is the currency costs are reported in, which is available to
+	// queries and included in the metadata of cost columns returned by the
+	// API.
+	Currency reporting.Currency
+
+	// ClusterID identifies the cluster in the cluster_id column of the data
+	// collected, so data from multiple clusters can be combined. Defaults to
+	// the UID of the kube-system namespace.
+	ClusterID string
+
 	HiveHost         string
 	PrestoHost       string
+	PrestoDialect    presto.Dialect
 	DisablePromsum   bool
 	EnableFinalizers bool
+	// EnableRemoteWrite enables the Prometheus remote-write endpoint, which
+	// stores samples for ReportDataSources with remoteWrite configured.
+	EnableRemoteWrite bool
+	// EnableGRPCAPI enables the gRPC API on GRPCAPIAddress, which streams
+	// the results of Reports and ScheduledReports.
+	EnableGRPCAPI bool
+	// AuthorizeResultsAccess restricts the results returned by the HTTP and
+	// gRPC APIs to the namespaces the caller is allowed to get Reports,
+	// ScheduledReports and ReportDataSources in. It requires watching
+	// multiple namespaces.
+	AuthorizeResultsAccess bool
+	// AuditLogPath is the file each access to report results is recorded
+	// in, or "-" to write them to stdout. Access isn't recorded if it's
+	// empty.
+	AuditLogPath string
+	// TrustedProxies are the IP addresses and CIDR ranges of the proxies in
+	// front of the HTTP API, such as the auth proxy, whose X-Forwarded-User
+	// and X-Forwarded-For headers are trusted.
+	TrustedProxies []string
+	// APIRateLimits configures the limits on requests to the HTTP API.
+	APIRateLimits APIRateLimitConfig
+	// EnableWebhooks serves the admission webhooks validating Reports and
+	// defaulting ScheduledReports on WebhookAddress, using
+	// WebhookTLSConfig.
+	EnableWebhooks bool
+	// ScheduledReportDefaults are the defaults set on new ScheduledReports
+	// by the defaulting webhook.
+	ScheduledReportDefaults ScheduledReportDefaultsConfig
+
+	PurgeDeletedReportData bool
 
 	PrestoMaxQueryLength int
 
@@ -103,10 +268,101 @@ type Config struct {
 	PrometheusDataSourceMaxBackfillImportDuration time.Duration
 	PrometheusDataSourceGlobalImportFromTime      *time.Time
 
-	LeaderLeaseDuration time.Duration
+	// ReportDataSourceWorkers is the number of ReportDataSources processed,
+	// and Prometheus imports run, concurrently.
+	ReportDataSourceWorkers int
+	// PrometheusMaxConcurrentImportsPerEndpoint limits the number of imports
+	// running concurrently against each Prometheus instance. 0 disables the
+	// limit.
+	PrometheusMaxConcurrentImportsPerEndpoint int
+
+	LeaderLeaseDuration  time.Duration
+	LeaderRenewDeadline  time.Duration
+	LeaderRetryPeriod    time.Duration
+	StandbyCheckInterval time.Duration
+
+	StorageBudgetCheckInterval time.Duration
+
+	// PartitionCompactionInterval is how often the partitions of
+	// ReportDataSources with compaction enabled are compacted. 0 disables
+	// compaction.
+	PartitionCompactionInterval time.Duration
+
+	// RetentionCheckInterval is how often the partitions of ReportDataSources
+	// older than their retention are pruned. 0 disables pruning.
+	RetentionCheckInterval time.Duration
+
+	// HDFSStorageCheckInterval is how often the replication factor and umask
+	// of HDFS StorageLocations are applied to the files stored in them. 0
+	// disables applying them.
+	HDFSStorageCheckInterval time.Duration
+
+	// ReportQueryTimeout is how long the queries generating a Report or
+	// ScheduledReport can run before they're cancelled. 0 disables the
+	// timeout.
+	ReportQueryTimeout time.Duration
+
+	// ShutdownDrainTimeout is how long in-flight work, such as generating a
+	// report, has to finish once the operator begins shutting down, before
+	// it's queries are cancelled. 0 cancels them immediately.
+	ShutdownDrainTimeout time.Duration
+
+	// PrestoMaxConcurrentQueries is the number of report queries run
+	// against Presto at once. Queries waiting to run are started in order of
+	// priority. 0 disables the limit.
+	PrestoMaxConcurrentQueries int
+
+	// MaxConcurrentReports is the number of Reports and ScheduledReports
+	// generated at once. Others wait in their work queues until one
+	// finishes. 0 disables the limit.
+	MaxConcurrentReports int
+
+	// APIMaxQueueDepth is the number of items waiting in the work queues
+	// above which API requests are rejected. 0 disables the limit.
+	APIMaxQueueDepth int
+
+	// ReportResultsCacheMaxRows is the total number of rows of report
+	// results the API caches, and ReportResultsCacheTTL is how long results
+	// are cached for. Setting either to 0 disables caching.
+	ReportResultsCacheMaxRows int
+	ReportResultsCacheTTL     time.Duration
+
+	// AdHocQueryMaxRows is the maximum number of rows a ReportGenerationQuery
+	// executed using the ad-hoc query API can return.
+	AdHocQueryMaxRows int
+
+	// PushgatewayURL is the URL of a Prometheus Pushgateway the results of
+	// ScheduledReports with spec.metrics set are pushed to after each run,
+	// grouped under the PushgatewayJob job. Pushing is disabled if unset.
+	PushgatewayURL string
+	PushgatewayJob string
+
+	// OperatorConfigName is the name of the ReportingOperatorConfig in
+	// Namespace which overrides this configuration.
+	OperatorConfigName string
+
+	// AgentConfig, if it's CentralURL is set, runs reporting-operator as an
+	// agent pushing the Prometheus metrics it collects to a central
+	// reporting-operator.
+	AgentConfig AgentConfig
+
+	// TracingConfig configures exporting traces of report generation and
+	// Prometheus imports.
+	TracingConfig TracingConfig
+
+	// ResyncPeriod is how often the informers resync, adding every resource
+	// to it's work queue again. 0 disables resyncing.
+	ResyncPeriod time.Duration
+	// QueueRateLimiterConfig configures the rate limiter of each work
+	// queue.
+	QueueRateLimiterConfig RateLimiterConfig
+	// MaxRetriesConfig configures how many times each kind of resource is
+	// retried before it's marked as failed.
+	MaxRetriesConfig MaxRetriesConfig
 
 	APITLSConfig     TLSConfig
 	MetricsTLSConfig TLSConfig
+	WebhookTLSConfig TLSConfig
 	PrometheusConfig PrometheusConfig
 }
 
@@ -114,6 +370,28 @@ type Reporting struct {
 	cfg        Config
 	kubeConfig *rest.Config
 
+	// cfgMu protects the fields of cfg the ReportingOperatorConfig can change
+	// while the operator is running, which are read using config().
+	cfgMu sync.RWMutex
+	// flagCfg is the configuration the operator was started with, which the
+	// ReportingOperatorConfig overrides.
+	flagCfg              Config
+	operatorConfigLoaded bool
+	// operatorConfigRestartCh is closed when the ReportingOperatorConfig
+	// changes a field which requires the operator to restart.
+	operatorConfigRestartCh   chan struct{}
+	operatorConfigRestartOnce sync.Once
+	// logLevelLogger is the logger whose level the ReportingOperatorConfig
+	// sets, and defaultLogLevel is it's level when the operator started.
+	// logLevel is it's current level.
+	logLevelLogger  *log.Logger
+	defaultLogLevel log.Level
+	logLevel        log.Level
+	// logDMLQueries and logDDLQueries control if the queries run are
+	// logged, and can be changed while the operator is running.
+	logDMLQueries *db.QueryLogging
+	logDDLQueries *db.QueryLogging
+
 	meteringClient cbClientset.Interface
 	kubeClient     corev1.CoreV1Interface
 
@@ -126,6 +404,9 @@ type Reporting struct {
 	reportPrometheusQueryLister listers.ReportPrometheusQueryLister
 	scheduledReportLister       listers.ScheduledReportLister
 	storageLocationLister       listers.StorageLocationLister
+	pricingPolicyLister         listers.PricingPolicyLister
+
+	reportingOperatorConfigLister listers.ReportingOperatorConfigLister
 
 	queueList                  []workqueue.RateLimitingInterface
 	reportQueue                workqueue.RateLimitingInterface
@@ -136,7 +417,9 @@ type Reporting struct {
 
 	reportResultsRepo     prestostore.ReportResultsRepo
 	prometheusMetricsRepo prestostore.PrometheusMetricsRepo
+	sqlRowsRepo           prestostore.SQLRowsRepo
 	reportGenerator       reporting.ReportGenerator
+	queryQueue            *reporting.QueryQueue
 
 	prestoViewCreator        PrestoViewCreator
 	tableManager             reporting.TableManager
@@ -144,9 +427,70 @@ type Reporting struct {
 
 	testWriteToPrestoFunc  func() bool
 	testReadFromPrestoFunc func() bool
+	// testHiveFunc is nil unless Hive is used by the query backend.
+	testHiveFunc func() error
 
 	promConn prom.API
 
+	// agentClient is used to push metrics to the central reporting-operator
+	// when running as an agent.
+	agentClient *http.Client
+
+	// tracer starts the traces of report generation and Prometheus imports,
+	// and spanExporter sends their spans. Both are nil if tracing is
+	// disabled.
+	tracer       *tracing.Tracer
+	spanExporter *tracing.OTLPExporter
+
+	// prometheusConns contains connections to the Prometheus instances
+	// ReportDataSources are configured to use instead of promConn.
+	prometheusConnsMu sync.Mutex
+	prometheusConns   map[string]*prometheusConn
+	// prometheusCredentialsSecrets caches the Secrets ReportDataSources can
+	// authenticate to Prometheus with, and is populated by
+	// prometheusCredentialsSecretInformer.
+	prometheusCredentialsSecretInformer cache.SharedIndexInformer
+	prometheusCredentialsSecrets        cache.Indexer
+
+	// prometheusImportSemaphores limits the number of imports running against
+	// each Prometheus URL.
+	prometheusImportSemaphoresMu sync.Mutex
+	prometheusImportSemaphores   map[string]chan struct{}
+
+	// reportSemaphore limits the number of Reports and ScheduledReports
+	// generated at once, if MaxConcurrentReports is set.
+	reportSemaphore chan struct{}
+
+	// prometheusRateLimiters limits the queries sent to each Prometheus URL.
+	// The value is nil if no limits are configured.
+	prometheusRateLimitersMu sync.Mutex
+	prometheusRateLimiters   map[string]*prometheusRateLimiter
+
+	// awsBillingNotificationWatchers contains the watchers receiving S3
+	// event notifications for AWSBilling ReportDataSources, keyed by
+	// namespace/name.
+	awsBillingNotificationWatchersMu sync.Mutex
+	awsBillingNotificationWatchers   map[string]*awsBillingNotificationWatcher
+
+	// kafkaConsumers contains the consumers storing messages from Kafka in
+	// the tables of Kafka ReportDataSources, keyed by namespace/name.
+	kafkaConsumersMu sync.Mutex
+	kafkaConsumers   map[string]*kafkaConsumer
+
+	// shutdownCtx is cancelled when the operator begins shutting down, to
+	// stop waiting to start new work.
+	shutdownCtx context.Context
+	// workCtx is the parent of the contexts used by in-flight work, such as
+	// queries. It's cancelled once ShutdownDrainTimeout has elapsed after
+	// the operator begins shutting down, or once Run returns.
+	workCtx context.Context
+
+	// reportQueryCancels contains the functions cancelling the queries
+	// running for each Report and ScheduledReport, keyed by
+	// kind/namespace/name.
+	reportQueryCancelsMu sync.Mutex
+	reportQueryCancels   map[string]context.CancelFunc
+
 	clock clock.Clock
 	rand  *rand.Rand
 
@@ -155,8 +499,36 @@ type Reporting struct {
 	initializedMu sync.Mutex
 	initialized   bool
 
+	// dependencies are the dependencies reported by /healthz and /readyz.
+	dependencies *dependencyChecker
+
+	prestoReadinessMu        sync.Mutex
+	prestoReadinessCheckTime time.Time
+	prestoReadinessReadable  bool
+	prestoReadinessChecking  bool
+
 	importersMu sync.Mutex
 	importers   map[string]*prestostore.PrometheusImporter
+
+	eventRecorder record.EventRecorder
+
+	// resultsAuthorizer is nil unless cfg.AuthorizeResultsAccess is set.
+	resultsAuthorizer *resultsAuthorizer
+	// resultsAuditor is nil unless cfg.AuditLogPath is set.
+	resultsAuditor *resultsAuditor
+	// trustedProxies is parsed from cfg.TrustedProxies.
+	trustedProxies trustedProxies
+	// apiRateLimiter is nil unless one of cfg.APIRateLimits is set.
+	apiRateLimiter *apiRateLimiter
+	// generationQueryTexts holds the text of the ReportGenerationQuery
+	// versions recently used by reports, for the query diff API.
+	generationQueryTexts *reporting.GenerationQueryTexts
+
+	storageBudgetsMu    sync.Mutex
+	storageUsageSamples map[string][]storageUsageSample
+	// overBudgetLocations maps the namespace/name of StorageLocations
+	// projected to exceed their budget to their location.
+	overBudgetLocations map[string]string
 }
 
 func New(logger log.FieldLogger, cfg Config) (*Reporting, error) {
@@ -166,8 +538,66 @@ func New(logger log.FieldLogger, cfg Config) (*Reporting, error) {
 	if err := cfg.MetricsTLSConfig.Valid(); err != nil {
 		return nil, err
 	}
+	if err := cfg.PrometheusConfig.Valid(); err != nil {
+		return nil, err
+	}
+	if err := cfg.HiveTLSConfig.Valid(); err != nil {
+		return nil, err
+	}
+	if err := cfg.AgentConfig.Valid(); err != nil {
+		return nil, err
+	}
+	if err := cfg.TracingConfig.Valid(); err != nil {
+		return nil, err
+	}
+	if err := cfg.QueueRateLimiterConfig.Valid(); err != nil {
+		return nil, err
+	}
+	if err := cfg.MaxRetriesConfig.Valid(); err != nil {
+		return nil, err
+	}
+	if err := cfg.ScheduledReportDefaults.Valid(); err != nil {
+		return nil, err
+	}
+	if err := cfg.APIRateLimits.Valid(); err != nil {
+		return nil, err
+	}
+	if cfg.EnableWebhooks && (cfg.WebhookTLSConfig.TLSCert == "" || cfg.WebhookTLSConfig.TLSKey == "") {
+		return nil, fmt.Errorf("webhooks are served using TLS, so a webhook TLS certificate and private key must be set when webhooks are enabled")
+	}
+	if cfg.AuthorizeResultsAccess && !cfg.multiNamespace() {
+		return nil, fmt.Errorf("authorizing access to results requires watching multiple namespaces")
+	}
+	trustedProxies, err := parseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TrustedProxies: %v", err)
+	}
+	if cfg.ResyncPeriod < 0 {
+		return nil, fmt.Errorf("resync period must not be negative, got %s", cfg.ResyncPeriod)
+	}
+	if err := validateHiveConfig(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.TableCompression != "" {
+		codec, err := hive.NormalizeCompressionCodec(cfg.TableCompression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TableCompression: %v", err)
+		}
+		cfg.TableCompression = codec
+	}
+	if err := reporting.ValidateCurrency(cfg.Currency); err != nil {
+		return nil, fmt.Errorf("invalid Currency: %v", err)
+	}
+	if cfg.ReportDataSourceWorkers < 1 {
+		return nil, fmt.Errorf("ReportDataSourceWorkers must be at least 1, got %d", cfg.ReportDataSourceWorkers)
+	}
+	if err := validateQueryBackend(cfg); err != nil {
+		return nil, err
+	}
 
-	logger.Debugf("config: %s", spew.Sprintf("%+v", cfg))
+	loggedCfg := cfg
+	loggedCfg.PrometheusConfig = cfg.PrometheusConfig.redacted()
+	logger.Debugf("config: %s", spew.Sprintf("%+v", loggedCfg))
 
 	configOverrides := &clientcmd.ConfigOverrides{}
 	var clientConfig clientcmd.ClientConfig
@@ -182,7 +612,6 @@ func New(logger log.FieldLogger, cfg Config) (*Reporting, error) {
 		clientConfig = clientcmd.NewDefaultClientConfig(*apiCfg, configOverrides)
 	}
 
-	var err error
 	kubeConfig, err := clientConfig.ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("Unable to get Kubernetes client config: %v", err)
@@ -203,6 +632,27 @@ func New(logger log.FieldLogger, cfg Config) (*Reporting, error) {
 	clock := clock.RealClock{}
 	rand := rand.New(rand.NewSource(clock.Now().Unix()))
 	op := newReportingOperator(logger, clock, rand, cfg, kubeConfig, kubeClient, meteringClient)
+	op.trustedProxies = trustedProxies
+
+	if cfg.AuthorizeResultsAccess {
+		authenticationClient, err := authenticationv1client.NewForConfig(kubeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to create Kubernetes authentication client: %v", err)
+		}
+		authorizationClient, err := authorizationv1client.NewForConfig(kubeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to create Kubernetes authorization client: %v", err)
+		}
+		op.resultsAuthorizer = newResultsAuthorizer(authenticationClient.TokenReviews(), authorizationClient.SubjectAccessReviews(), clock)
+	}
+	op.apiRateLimiter = newAPIRateLimiter(op.logger, clock, cfg.APIRateLimits, op.resultsAuthorizer, op.trustedProxies)
+	if cfg.AuditLogPath != "" {
+		auditLog, err := openAuditLog(cfg.AuditLogPath)
+		if err != nil {
+			return nil, err
+		}
+		op.resultsAuditor = newResultsAuditor(auditLog, op.resultsAuthorizer, op.trustedProxies)
+	}
 
 	return op, nil
 }
@@ -217,7 +667,7 @@ func newReportingOperator(
 	meteringClient cbClientset.Interface,
 ) *Reporting {
 
-	informerFactory := factory.NewFilteredSharedInformerFactory(meteringClient, defaultResyncPeriod, cfg.Namespace, nil)
+	informerFactory := factory.NewFilteredSharedInformerFactory(meteringClient, cfg.ResyncPeriod, cfg.watchNamespace(), nil)
 
 	prestoTableInformer := informerFactory.Metering().V1alpha1().PrestoTables()
 	reportInformer := informerFactory.Metering().V1alpha1().Reports()
@@ -226,12 +676,14 @@ func newReportingOperator(
 	reportPrometheusQueryInformer := informerFactory.Metering().V1alpha1().ReportPrometheusQueries()
 	scheduledReportInformer := informerFactory.Metering().V1alpha1().ScheduledReports()
 	storageLocationInformer := informerFactory.Metering().V1alpha1().StorageLocations()
+	pricingPolicyInformer := informerFactory.Metering().V1alpha1().PricingPolicies()
+	reportingOperatorConfigInformer := informerFactory.Metering().V1alpha1().ReportingOperatorConfigs()
 
-	reportQueue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "reports")
-	scheduledReportQueue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "scheduledreports")
-	reportDataSourceQueue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "reportdatasources")
-	reportGenerationQueryQueue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "reportgenerationqueries")
-	prestoTableQueue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "prestotables")
+	reportQueue := workqueueprometheus.NewNamedRateLimitingQueue(cfg.QueueRateLimiterConfig.newRateLimiter(), "reports")
+	scheduledReportQueue := workqueueprometheus.NewNamedRateLimitingQueue(cfg.QueueRateLimiterConfig.newRateLimiter(), "scheduledreports")
+	reportDataSourceQueue := workqueueprometheus.NewNamedRateLimitingQueue(cfg.QueueRateLimiterConfig.newRateLimiter(), "reportdatasources")
+	reportGenerationQueryQueue := workqueueprometheus.NewNamedRateLimitingQueue(cfg.QueueRateLimiterConfig.newRateLimiter(), "reportgenerationqueries")
+	prestoTableQueue := workqueueprometheus.NewNamedRateLimitingQueue(cfg.QueueRateLimiterConfig.newRateLimiter(), "prestotables")
 
 	queueList := []workqueue.RateLimitingInterface{
 		reportQueue,
@@ -241,9 +693,16 @@ func newReportingOperator(
 		prestoTableQueue,
 	}
 
+	tracer, spanExporter := newTracer(logger, cfg.TracingConfig)
+
+	prometheusCredentialsSecretInformer := newPrometheusCredentialsSecretInformer(kubeClient, cfg.watchNamespace(), cfg.ResyncPeriod)
+
+	// the size is constant and positive, so NewGenerationQueryTexts can't fail
+	generationQueryTexts, _ := reporting.NewGenerationQueryTexts(generationQueryTextsMaxEntries)
 	op := &Reporting{
 		logger:         logger,
 		cfg:            cfg,
+		flagCfg:        cfg,
 		kubeConfig:     kubeConfig,
 		meteringClient: meteringClient,
 		kubeClient:     kubeClient,
@@ -257,6 +716,9 @@ func newReportingOperator(
 		reportPrometheusQueryLister: reportPrometheusQueryInformer.Lister(),
 		scheduledReportLister:       scheduledReportInformer.Lister(),
 		storageLocationLister:       storageLocationInformer.Lister(),
+		pricingPolicyLister:         pricingPolicyInformer.Lister(),
+
+		reportingOperatorConfigLister: reportingOperatorConfigInformer.Lister(),
 
 		queueList:                  queueList,
 		reportQueue:                reportQueue,
@@ -265,47 +727,118 @@ func newReportingOperator(
 		reportGenerationQueryQueue: reportGenerationQueryQueue,
 		prestoTableQueue:           prestoTableQueue,
 
+		tracer:       tracer,
+		spanExporter: spanExporter,
+
 		rand:      rand,
 		clock:     clock,
 		importers: make(map[string]*prestostore.PrometheusImporter),
+
+		prometheusConns:                     make(map[string]*prometheusConn),
+		prometheusCredentialsSecretInformer: prometheusCredentialsSecretInformer,
+		prometheusCredentialsSecrets:        prometheusCredentialsSecretInformer.GetIndexer(),
+
+		prometheusImportSemaphores: make(map[string]chan struct{}),
+		prometheusRateLimiters:     make(map[string]*prometheusRateLimiter),
+
+		awsBillingNotificationWatchers: make(map[string]*awsBillingNotificationWatcher),
+		kafkaConsumers:                 make(map[string]*kafkaConsumer),
+
+		shutdownCtx:        context.Background(),
+		workCtx:            context.Background(),
+		reportQueryCancels: make(map[string]context.CancelFunc),
+
+		storageUsageSamples: make(map[string][]storageUsageSample),
+		overBudgetLocations: make(map[string]string),
+
+		generationQueryTexts: generationQueryTexts,
+
+		operatorConfigRestartCh: make(chan struct{}),
+
+		logDMLQueries: db.NewQueryLogging(cfg.LogDMLQueries),
+		logDDLQueries: db.NewQueryLogging(cfg.LogDDLQueries),
+	}
+	op.dependencies = newDependencyChecker(clock)
+	op.dependencies.add(DependencyInformers, false)
+	if !cfg.AgentConfig.Enabled() {
+		op.dependencies.add(DependencyPresto, true)
+		if cfg.QueryBackend == QueryBackendPresto {
+			op.dependencies.add(DependencyHive, false)
+		}
+	}
+	op.dependencies.add(DependencyPrometheus, false)
+	if cfg.MaxConcurrentReports > 0 {
+		op.reportSemaphore = make(chan struct{}, cfg.MaxConcurrentReports)
+	}
+	if entry, ok := logger.(*log.Entry); ok {
+		op.logLevelLogger = entry.Logger
+		op.defaultLogLevel = entry.Logger.Level
+		op.logLevel = entry.Logger.Level
 	}
 
-	reportInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    op.addReport,
-		UpdateFunc: op.updateReport,
-		DeleteFunc: op.deleteReport,
+	reportInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: op.inWatchedNamespace,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    op.addReport,
+			UpdateFunc: op.updateReport,
+			DeleteFunc: op.deleteReport,
+		},
 	})
 
-	scheduledReportInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    op.addScheduledReport,
-		UpdateFunc: op.updateScheduledReport,
-		DeleteFunc: op.deleteScheduledReport,
+	scheduledReportInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: op.inWatchedNamespace,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    op.addScheduledReport,
+			UpdateFunc: op.updateScheduledReport,
+			DeleteFunc: op.deleteScheduledReport,
+		},
 	})
 
-	reportDataSourceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    op.addReportDataSource,
-		UpdateFunc: op.updateReportDataSource,
-		DeleteFunc: op.deleteReportDataSource,
+	reportDataSourceInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: op.inWatchedNamespace,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    op.addReportDataSource,
+			UpdateFunc: op.updateReportDataSource,
+			DeleteFunc: op.deleteReportDataSource,
+		},
 	})
 
-	reportGenerationQueryInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    op.addReportGenerationQuery,
-		UpdateFunc: op.updateReportGenerationQuery,
+	reportGenerationQueryInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: op.inWatchedNamespace,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    op.addReportGenerationQuery,
+			UpdateFunc: op.updateReportGenerationQuery,
+		},
 	})
 
-	prestoTableInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    op.addPrestoTable,
-		UpdateFunc: op.updatePrestoTable,
-		DeleteFunc: op.deletePrestoTable,
+	prestoTableInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: op.inWatchedNamespace,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    op.addPrestoTable,
+			UpdateFunc: op.updatePrestoTable,
+			DeleteFunc: op.deletePrestoTable,
+		},
+	})
+
+	reportingOperatorConfigInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: op.isOperatorConfig,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    op.addOperatorConfig,
+			UpdateFunc: op.updateOperatorConfig,
+			DeleteFunc: op.deleteOperatorConfig,
+		},
 	})
 
 	return op
 }
 
-func (op *Reporting) Run(stopCh <-chan struct{}) error {
+// Run runs the operator until ctx is cancelled. In-flight work is given
+// ShutdownDrainTimeout to finish before it's queries are cancelled.
+func (op *Reporting) Run(ctx context.Context) error {
+	stopCh := ctx.Done()
 	var wg sync.WaitGroup
 	// buffered big enough to hold the errs of each server we start.
-	srvErrChan := make(chan error, 3)
+	srvErrChan := make(chan error, 5)
 
 	op.logger.Info("starting Metering operator")
 
@@ -313,7 +846,7 @@ func (op *Reporting) Run(stopCh <-chan struct{}) error {
 		Addr:    ":8082",
 		Handler: promhttp.Handler(),
 	}
-	pprofServer := newPprofServer()
+	pprofServer := newPprofServer(op.logLevelHandler)
 
 	// start these servers at the beginning some pprof and metrics are
 	// available before the reporting operator is ready
@@ -340,57 +873,65 @@ func (op *Reporting) Run(stopCh <-chan struct{}) error {
 		srvErrChan <- fmt.Errorf("pprof server error: %v", srvErr)
 	}()
 
-	go op.informerFactory.Start(stopCh)
-
-	shutdownCtx, cancel := context.WithCancel(context.Background())
-	// wait for stopChn to be closed, then cancel our context
-	go func() {
-		<-stopCh
-		cancel()
-	}()
+	// spans are exported until the workers have stopped, so the spans of
+	// work cancelled by shutting down are sent too.
+	spanExporterStopCh := make(chan struct{})
+	spanExporterDone := make(chan struct{})
+	if op.spanExporter != nil {
+		go func() {
+			defer close(spanExporterDone)
+			op.spanExporter.Run(spanExporterStopCh)
+		}()
+	} else {
+		close(spanExporterDone)
+	}
 
-	op.logger.Infof("setting up DB connections")
+	// the HTTP server is started before the caches sync, so /healthz and
+	// /readyz can report what reporting-operator is waiting for. API
+	// requests are rejected until the API router is set.
+	apiHandler := &apiRouterHandler{}
+	httpMux := http.NewServeMux()
+	httpMux.HandleFunc("/healthz", op.healthzHandler)
+	httpMux.HandleFunc("/readyz", op.readyzHandler)
+	httpMux.HandleFunc("/ready", op.readinessHandler)
+	httpMux.HandleFunc("/healthy", op.healthinessHandler)
+	httpMux.Handle("/", apiHandler)
 
-	var (
-		prestoQueryer db.Queryer
-		hiveQueryer   db.Queryer
-	)
+	httpServer := &http.Server{
+		Addr:    ":8080",
+		Handler: op.apiReadinessHandler(httpMux),
+	}
 
-	// Use errgroup to setup both hive and presto connections
-	// at the sametime, waiting for both to be ready before continuing.
-	// if either errors, we return the first error
-	var g errgroup.Group
-	g.Go(func() error {
-		var err error
-		connStr := fmt.Sprintf("http://%s@%s?catalog=hive&schema=default", prestoUsername, op.cfg.PrestoHost)
-		prestoConn, err := presto.NewPrestoConnWithRetry(shutdownCtx, op.logger, connStr, connBackoff, maxConnRetries)
-		if err != nil {
-			return err
-		}
-		prestoQueryer = db.NewLoggingQueryer(prestoConn, op.logger, op.cfg.LogDMLQueries)
-		return nil
-	})
-	g.Go(func() error {
-		var err error
-		reconnectingHiveQueryer := hive.NewReconnectingQueryer(shutdownCtx, op.logger, op.cfg.HiveHost, connBackoff, maxConnRetries)
-		if err != nil {
-			return err
+	op.logger.Infof("starting HTTP server")
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var srvErr error
+		if op.cfg.APITLSConfig.UseTLS {
+			op.logger.Infof("HTTP API server listening with TLS on 127.0.0.1:8080")
+			srvErr = httpServer.ListenAndServeTLS(op.cfg.APITLSConfig.TLSCert, op.cfg.APITLSConfig.TLSKey)
+		} else {
+			op.logger.Infof("HTTP API server listening on 127.0.0.1:8080")
+			srvErr = httpServer.ListenAndServe()
 		}
-		hiveQueryer = db.NewLoggingQueryer(reconnectingHiveQueryer, op.logger, op.cfg.LogDDLQueries)
-		return nil
-	})
-	err := g.Wait()
-	if err != nil {
-		return err
-	}
+		op.logger.WithError(srvErr).Info("HTTP API server exited")
+		srvErrChan <- fmt.Errorf("HTTP API server error: %v", srvErr)
+	}()
 
-	defer prestoQueryer.Close()
-	defer hiveQueryer.Close()
+	go op.informerFactory.Start(stopCh)
+	go op.prometheusCredentialsSecretInformer.Run(stopCh)
 
-	op.promConn, err = op.newPrometheusConnFromURL(op.cfg.PrometheusConfig.Address)
-	if err != nil {
-		return err
-	}
+	shutdownCtx, cancelShutdown := context.WithCancel(context.Background())
+	defer cancelShutdown()
+	op.shutdownCtx = shutdownCtx
+	workCtx, cancelWork := context.WithCancel(context.Background())
+	defer cancelWork()
+	op.workCtx = workCtx
+
+	// until the workers could have started, there's nothing to drain, so
+	// stopping cancels everything immediately.
+	startingWorkersCh := make(chan struct{})
+	go cancelBeforeWorkersStart(stopCh, startingWorkersCh, cancelShutdown, cancelWork)
 
 	op.logger.Info("waiting for caches to sync")
 	for t, synced := range op.informerFactory.WaitForCacheSync(stopCh) {
@@ -398,75 +939,170 @@ func (op *Reporting) Run(stopCh <-chan struct{}) error {
 			return fmt.Errorf("cache for %s not synced in time", t)
 		}
 	}
-
-	var prestoQueryBufferPool *sync.Pool
-	if op.cfg.PrestoMaxQueryLength > 0 {
-		bufferPool := prestostore.NewBufferPool(op.cfg.PrestoMaxQueryLength)
-		prestoQueryBufferPool = &bufferPool
+	if !cache.WaitForCacheSync(stopCh, op.prometheusCredentialsSecretInformer.HasSynced) {
+		return fmt.Errorf("cache for Prometheus credentials Secrets not synced in time")
 	}
-	op.reportResultsRepo = prestostore.NewReportResultsRepo(prestoQueryer)
-	op.reportGenerator = reporting.NewReportGenerator(op.logger, op.reportResultsRepo)
-	op.prometheusMetricsRepo = prestostore.NewPrometheusMetricsRepo(prestoQueryer, prestoQueryBufferPool)
-	op.prestoViewCreator = &prestoViewCreator{queryer: prestoQueryer}
+	op.dependencies.setCheck(DependencyInformers, func() error { return nil })
 
-	hiveTableManager := reporting.NewHiveTableManager(hiveQueryer)
-	op.tableManager = hiveTableManager
-	op.awsTablePartitionManager = hiveTableManager
+	op.loadOperatorConfig()
 
-	tableProperties, err := op.getHiveTableProperties(op.logger, nil, "health_check")
+	var err error
+	op.promConn, err = op.newPrometheusConnFromURL(op.cfg.PrometheusConfig.Address, "")
 	if err != nil {
-		return fmt.Errorf("no default storage configured, unable to setup health checker: %v", err)
+		return err
 	}
+	promConn := op.promConn
+	op.dependencies.setCheck(DependencyPrometheus, func() error {
+		return checkPrometheus(promConn, op.clock.Now())
+	})
 
-	prestoHealthChecker := reporting.NewPrestoHealthChecker(op.logger, prestoQueryer, hiveTableManager, *tableProperties)
-	op.testWriteToPrestoFunc = func() bool {
-		return prestoHealthChecker.TestWriteToPrestoSingleFlight()
-	}
-	op.testReadFromPrestoFunc = func() bool {
-		return prestoHealthChecker.TestReadFromPrestoSingleFlight()
+	if op.cfg.ClusterID == "" {
+		op.cfg.ClusterID, err = op.getClusterUID()
+		if err != nil {
+			return fmt.Errorf("unable to determine the cluster ID, set it using --cluster-id: %v", err)
+		}
 	}
+	op.logger.Infof("using cluster ID %s", op.cfg.ClusterID)
 
-	op.logger.Infof("starting HTTP server")
-	apiRouter := newRouter(
-		op.logger, op.rand, op.prometheusMetricsRepo, op.reportResultsRepo, op.importPrometheusForTimeRange, op.cfg.Namespace,
-		op.reportLister, op.scheduledReportLister, op.reportGenerationQueryLister, op.prestoTableLister,
+	var (
+		apiRouter    chi.Router
+		resultsCache *reportResultsCache
 	)
-	apiRouter.HandleFunc("/ready", op.readinessHandler)
-	apiRouter.HandleFunc("/healthy", op.healthinessHandler)
+	if op.cfg.AgentConfig.Enabled() {
+		op.logger.Infof("running as an agent, pushing Prometheus metrics to the reporting-operator at %s", op.cfg.AgentConfig.CentralURL)
+		op.agentClient, err = op.cfg.AgentConfig.newCentralClient()
+		if err != nil {
+			return err
+		}
+		// agents don't use Presto, and failing to push metrics to the
+		// central reporting-operator is retried by the ReportDataSource
+		// workers, so the agent is always considered healthy.
+		op.testWriteToPrestoFunc = func() bool { return true }
+		op.testReadFromPrestoFunc = func() bool { return true }
 
-	httpServer := &http.Server{
-		Addr:    ":8080",
-		Handler: apiRouter,
+		apiRouter = chi.NewRouter()
+	} else {
+		op.logger.Infof("setting up DB connections")
+
+		backend, err := queryBackends[op.cfg.QueryBackend](op, workCtx)
+		if err != nil {
+			return err
+		}
+		defer backend.Close()
+		prestoQueryer := backend.queryer
+
+		var prestoQueryBufferPool *sync.Pool
+		if op.cfg.PrestoMaxQueryLength > 0 {
+			bufferPool := prestostore.NewBufferPool(op.cfg.PrestoMaxQueryLength)
+			prestoQueryBufferPool = &bufferPool
+		}
+		op.reportResultsRepo = backend.reportResultsRepo
+		op.reportGenerator = reporting.NewReportGenerator(op.logger, op.reportResultsRepo, op.cfg.multiNamespace(), op.queryQueue, op.cfg.Currency, op.cfg.ClusterID)
+		op.prometheusMetricsRepo = prestostore.NewPrometheusMetricsRepo(prestoQueryer, backend.sqlDialect, prestoQueryBufferPool)
+		op.sqlRowsRepo = prestostore.NewSQLRowsRepo(prestoQueryer, backend.sqlDialect, prestoQueryBufferPool)
+		op.prestoViewCreator = &prestoViewCreator{queryer: prestoQueryer}
+
+		tableProperties, err := op.getHiveTableProperties(op.logger, "", nil, "health_check")
+		if err != nil {
+			return fmt.Errorf("no default storage configured, unable to setup health checker: %v", err)
+		}
+
+		op.tableManager = backend.tableManager
+		op.awsTablePartitionManager = backend.awsTablePartitionManager
+
+		prestoHealthChecker := reporting.NewPrestoHealthChecker(op.logger, prestoQueryer, backend.tableManager, *tableProperties, backend.healthCheckQueries)
+		op.testWriteToPrestoFunc = func() bool {
+			return prestoHealthChecker.TestWriteToPrestoSingleFlight()
+		}
+		op.testReadFromPrestoFunc = func() bool {
+			return prestoHealthChecker.TestReadFromPrestoSingleFlight()
+		}
+		op.dependencies.setCheck(DependencyPresto, func() error {
+			if !prestoHealthChecker.TestReadFromPrestoSingleFlight() {
+				return errors.New("cannot read from PrestoDB")
+			}
+			return nil
+		})
+		if op.cfg.QueryBackend == QueryBackendPresto {
+			hiveQueryer := backend.ddlQueryer
+			op.testHiveFunc = func() error {
+				return checkHive(hiveQueryer)
+			}
+			op.dependencies.setCheck(DependencyHive, op.testHiveFunc)
+		}
+
+		// the results cache is shared by the HTTP and gRPC APIs
+		resultsCache = newReportResultsCache(op.clock, op.cfg.ReportResultsCacheMaxRows, op.cfg.ReportResultsCacheTTL)
+
+		apiRouter = newRouter(
+			op.logger, op.rand, op.prometheusMetricsRepo, op.reportResultsRepo,
+			op.importPrometheusForTimeRange, op.cfg.Namespace, op.cfg.Currency,
+			op.reportLister, op.scheduledReportLister, op.reportGenerationQueryLister, op.prestoTableLister, op.reportDataSourceLister,
+			routerOptions{
+				resultsCache: resultsCache,
+				authorizer:   op.resultsAuthorizer,
+				auditor:      op.resultsAuditor,
+				rateLimiter:  op.apiRateLimiter,
+				queryTexts:   op.generationQueryTexts,
+			},
+		)
+		op.addAPIRoutes(apiRouter)
 	}
 
-	// start the HTTP API server
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		var srvErr error
-		if op.cfg.APITLSConfig.UseTLS {
-			op.logger.Infof("HTTP API server listening with TLS on 127.0.0.1:8080")
-			srvErr = httpServer.ListenAndServeTLS(op.cfg.APITLSConfig.TLSCert, op.cfg.APITLSConfig.TLSKey)
-		} else {
-			op.logger.Infof("HTTP API server listening on 127.0.0.1:8080")
-			srvErr = httpServer.ListenAndServe()
+	apiHandler.set(apiRouter)
+
+	var grpcServer *grpc.Server
+	if op.cfg.EnableGRPCAPI && !op.cfg.AgentConfig.Enabled() {
+		grpcServer, err = newGRPCServer(
+			op.logger, op.cfg.APITLSConfig, op.reportResultsRepo, resultsCache, op.resultsAuthorizer, op.resultsAuditor, op.cfg.Namespace,
+			op.reportLister, op.scheduledReportLister, op.reportGenerationQueryLister, op.prestoTableLister,
+		)
+		if err != nil {
+			return err
 		}
-		op.logger.WithError(srvErr).Info("HTTP API server exited")
-		srvErrChan <- fmt.Errorf("HTTP API server error: %v", srvErr)
-	}()
+		listener, err := net.Listen("tcp", GRPCAPIAddress)
+		if err != nil {
+			return fmt.Errorf("unable to listen on %s for the gRPC API: %v", GRPCAPIAddress, err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			op.logger.Infof("gRPC API server listening on 127.0.0.1%s", GRPCAPIAddress)
+			srvErr := grpcServer.Serve(listener)
+			op.logger.WithError(srvErr).Info("gRPC API server exited")
+			if srvErr != nil {
+				srvErrChan <- fmt.Errorf("gRPC API server error: %v", srvErr)
+			}
+		}()
+	}
+
+	var webhookServer *http.Server
+	if op.cfg.EnableWebhooks && !op.cfg.AgentConfig.Enabled() {
+		webhookServer = op.newWebhookServer()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			op.logger.Infof("webhook server listening with TLS on 127.0.0.1%s", WebhookAddress)
+			srvErr := webhookServer.ListenAndServeTLS(op.cfg.WebhookTLSConfig.TLSCert, op.cfg.WebhookTLSConfig.TLSKey)
+			op.logger.WithError(srvErr).Info("webhook server exited")
+			srvErrChan <- fmt.Errorf("webhook server error: %v", srvErr)
+		}()
+	}
 
-	// Poll until we can write to presto
-	op.logger.Info("testing ability to write to Presto")
-	err = wait.PollUntil(time.Second*5, func() (bool, error) {
-		if op.testWriteToPrestoFunc() {
-			return true, nil
+	if !op.cfg.AgentConfig.Enabled() {
+		// Poll until we can write to presto
+		op.logger.Info("testing ability to write to Presto")
+		err = wait.PollUntil(time.Second*5, func() (bool, error) {
+			if op.testWriteToPrestoFunc() {
+				return true, nil
+			}
+			return false, nil
+		}, stopCh)
+		if err != nil {
+			return err
 		}
-		return false, nil
-	}, stopCh)
-	if err != nil {
-		return err
+		op.logger.Info("writes to Presto are succeeding")
 	}
-	op.logger.Info("writes to Presto are succeeding")
 
 	op.logger.Info("basic initialization completed")
 	op.setInitialized()
@@ -475,6 +1111,7 @@ func (op *Reporting) Run(stopCh <-chan struct{}) error {
 	eventBroadcaster.StartLogging(op.logger.Infof)
 	eventBroadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: op.kubeClient.Events(op.cfg.Namespace)})
 	eventRecorder := eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: op.cfg.Hostname})
+	op.eventRecorder = eventBroadcaster.NewRecorder(cbScheme.Scheme, v1.EventSource{Component: "reporting-operator"})
 
 	rl, err := resourcelock.New(resourcelock.ConfigMapsResourceLock,
 		op.cfg.Namespace, "reporting-operator-leader-lease", op.kubeClient,
@@ -488,17 +1125,28 @@ func (op *Reporting) Run(stopCh <-chan struct{}) error {
 
 	stopWorkersCh := make(chan struct{})
 	lostLeaderCh := make(chan struct{})
+	becameLeaderCh := make(chan struct{})
+
+	renewDeadline := op.cfg.LeaderRenewDeadline
+	if renewDeadline == 0 {
+		renewDeadline = op.cfg.LeaderLeaseDuration / 2
+	}
+	retryPeriod := op.cfg.LeaderRetryPeriod
+	if retryPeriod == 0 {
+		retryPeriod = DefaultLeaderRetryPeriod
+	}
 
 	leader, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
 		Lock:          rl,
 		LeaseDuration: op.cfg.LeaderLeaseDuration,
-		RenewDeadline: op.cfg.LeaderLeaseDuration / 2,
-		RetryPeriod:   2 * time.Second,
+		RenewDeadline: renewDeadline,
+		RetryPeriod:   retryPeriod,
 		Callbacks: leaderelection.LeaderCallbacks{
 			OnStartedLeading: func(leaderStopCh <-chan struct{}) {
 				op.logger.Infof("became leader")
+				close(becameLeaderCh)
 				op.logger.Info("starting Metering workers")
-				op.startWorkers(wg, stopWorkersCh)
+				op.startWorkers(&wg, stopWorkersCh)
 				op.logger.Infof("Metering workers started, watching for reports...")
 			},
 			OnStoppedLeading: func() {
@@ -511,7 +1159,12 @@ func (op *Reporting) Run(stopCh <-chan struct{}) error {
 		return fmt.Errorf("error creating leader elector: %v", err)
 	}
 
+	// while we're waiting to become leader, keep our connections to Presto
+	// and Hive warm so we can begin processing as soon as we're promoted.
+	go op.runStandbyCheck(becameLeaderCh, stopCh)
+
 	op.logger.Infof("starting leader election")
+	close(startingWorkersCh)
 	go leader.Run()
 
 	// wait for an shutdown signal to begin shutdown.
@@ -526,17 +1179,20 @@ func (op *Reporting) Run(stopCh <-chan struct{}) error {
 	case err := <-srvErrChan:
 		op.logger.WithError(err).Error("server process failed, shutting down Metering operator")
 		return fmt.Errorf("server process failed, err: %v", err)
+	case <-op.operatorConfigRestartCh:
+		op.logger.Info("restarting Metering operator to apply ReportingOperatorConfig changes")
 	}
 
 	// if we stop being leader or get a shutdown signal, stop the workers
 	op.logger.Infof("stopping workers and collectors")
+	cancelShutdown()
 	close(stopWorkersCh)
 
 	// stop our running http servers
 	wg.Add(3)
 	go func() {
 		op.logger.Infof("stopping HTTP API server")
-		err := httpServer.Shutdown(context.TODO())
+		err := httpServer.Shutdown(workCtx)
 		if err != nil {
 			op.logger.WithError(err).Warnf("got an error shutting down HTTP API server")
 		}
@@ -544,15 +1200,34 @@ func (op *Reporting) Run(stopCh <-chan struct{}) error {
 	}()
 	go func() {
 		op.logger.Infof("stopping Prometheus metrics server")
-		err := promServer.Shutdown(context.TODO())
+		err := promServer.Shutdown(workCtx)
 		if err != nil {
 			op.logger.WithError(err).Warnf("got an error shutting down Prometheus metrics server")
 		}
 		wg.Done()
 	}()
+	if webhookServer != nil {
+		wg.Add(1)
+		go func() {
+			op.logger.Infof("stopping webhook server")
+			err := webhookServer.Shutdown(workCtx)
+			if err != nil {
+				op.logger.WithError(err).Warnf("got an error shutting down webhook server")
+			}
+			wg.Done()
+		}()
+	}
+	if grpcServer != nil {
+		wg.Add(1)
+		go func() {
+			op.logger.Infof("stopping gRPC API server")
+			grpcServer.GracefulStop()
+			wg.Done()
+		}()
+	}
 	go func() {
 		op.logger.Infof("stopping pprof server")
-		err := pprofServer.Shutdown(context.TODO())
+		err := pprofServer.Shutdown(workCtx)
 		if err != nil {
 			op.logger.WithError(err).Warnf("got an error shutting down pprof server")
 		}
@@ -563,13 +1238,53 @@ func (op *Reporting) Run(stopCh <-chan struct{}) error {
 	// shutdown
 	go op.shutdownQueues()
 
-	// wait for our workers to stop
-	wg.Wait()
+	// wait for our workers to finish their in-flight work, cancelling it's
+	// queries if that takes longer than the drain timeout.
+	op.drainWorkers(&wg, cancelWork)
+	cancelWork()
 	op.logger.Info("Metering workers and collectors stopped")
+
+	close(spanExporterStopCh)
+	<-spanExporterDone
+
+	// our workers are stopped, so give up the lease so a standby replica
+	// can take over without waiting for the lease to expire.
+	op.releaseLeaderLease(rl)
 	return nil
 }
 
-func (op *Reporting) newPrometheusConnFromURL(url string) (prom.API, error) {
+// cancelBeforeWorkersStart calls each of cancels if stopCh is closed before
+// startingWorkersCh.
+func cancelBeforeWorkersStart(stopCh, startingWorkersCh <-chan struct{}, cancels ...context.CancelFunc) {
+	select {
+	case <-stopCh:
+		for _, cancel := range cancels {
+			cancel()
+		}
+	case <-startingWorkersCh:
+	}
+}
+
+// drainWorkers waits for wg, calling cancelWork if that takes longer than
+// the ShutdownDrainTimeout.
+func (op *Reporting) drainWorkers(wg *sync.WaitGroup, cancelWork context.CancelFunc) {
+	workersStopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(workersStopped)
+	}()
+	select {
+	case <-workersStopped:
+	case <-op.clock.After(op.cfg.ShutdownDrainTimeout):
+		op.logger.Warnf("workers did not stop within the shutdown drain timeout of %s, cancelling in-flight queries", op.cfg.ShutdownDrainTimeout)
+		cancelWork()
+		<-workersStopped
+	}
+}
+
+// newPrometheusConnFromURL returns a connection to the Prometheus at url. If
+// bearerToken is empty, the globally configured credentials are used.
+func (op *Reporting) newPrometheusConnFromURL(url, bearerToken string) (prom.API, error) {
 	kubeTransportConfig, err := op.kubeConfig.TransportConfig()
 	if err != nil {
 		return nil, err
@@ -583,13 +1298,41 @@ func (op *Reporting) newPrometheusConnFromURL(url string) (prom.API, error) {
 		op.logger.Infof("using %s as CA for Prometheus", serviceServingCAFile)
 	}
 
-	if op.cfg.PrometheusConfig.SkipTLSVerify {
-		transportConfig.TLS.Insecure = op.cfg.PrometheusConfig.SkipTLSVerify
+	promCfg := op.cfg.PrometheusConfig
+	if promCfg.CAFile != "" {
+		transportConfig.TLS.CAData = nil
+		transportConfig.TLS.CAFile = promCfg.CAFile
+	}
+	if promCfg.ClientCertFile != "" {
+		transportConfig.TLS.CertData = nil
+		transportConfig.TLS.KeyData = nil
+		transportConfig.TLS.CertFile = promCfg.ClientCertFile
+		transportConfig.TLS.KeyFile = promCfg.ClientKeyFile
+	}
+	if promCfg.TLSServerName != "" {
+		transportConfig.TLS.ServerName = promCfg.TLSServerName
+	}
+	if promCfg.SkipTLSVerify {
+		transportConfig.TLS.Insecure = promCfg.SkipTLSVerify
 		transportConfig.TLS.CAData = nil
 		transportConfig.TLS.CAFile = ""
 	}
-	if op.cfg.PrometheusConfig.BearerToken != "" {
-		transportConfig.BearerToken = op.cfg.PrometheusConfig.BearerToken
+
+	if bearerToken == "" {
+		bearerToken, err = promCfg.bearerToken()
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case bearerToken != "":
+		transportConfig.BearerToken = bearerToken
+		transportConfig.Username = ""
+		transportConfig.Password = ""
+	case promCfg.BasicAuthUsername != "":
+		transportConfig.Username = promCfg.BasicAuthUsername
+		transportConfig.Password = promCfg.BasicAuthPassword
+		transportConfig.BearerToken = ""
 	}
 
 	roundTripper, err := transport.New(&transportConfig)
@@ -603,7 +1346,12 @@ func (op *Reporting) newPrometheusConnFromURL(url string) (prom.API, error) {
 	})
 }
 
-func (op *Reporting) startWorkers(wg sync.WaitGroup, stopCh <-chan struct{}) {
+func (op *Reporting) startWorkers(wg *sync.WaitGroup, stopCh <-chan struct{}) {
+	if op.cfg.AgentConfig.Enabled() {
+		op.startReportDataSourceWorkers(wg, stopCh)
+		return
+	}
+
 	wg.Add(1)
 	go func() {
 		op.logger.Infof("starting PrestoTable worker")
@@ -612,25 +1360,59 @@ func (op *Reporting) startWorkers(wg sync.WaitGroup, stopCh <-chan struct{}) {
 		op.logger.Infof("PrestoTable worker stopped")
 	}()
 
-	// We have a lot of ReportDataSources and we need to run more workers to
-	// make sure we collect data quickly
-	threadiness := 4
-	for i := 0; i < threadiness; i++ {
-		i := i
+	if op.cfg.StorageBudgetCheckInterval > 0 {
+		wg.Add(1)
+		go func() {
+			op.logger.Infof("starting storage budget checker")
+			op.runStorageBudgetCheck(stopCh)
+			wg.Done()
+			op.logger.Infof("storage budget checker stopped")
+		}()
+	}
 
+	// compacting partitions relies on INSERT OVERWRITE being atomic, which
+	// only Hive guarantees.
+	if op.cfg.PartitionCompactionInterval > 0 && op.cfg.QueryBackend == QueryBackendPresto {
 		wg.Add(1)
 		go func() {
-			op.logger.Infof("starting ReportDataSource worker #%d", i)
-			wait.Until(op.runReportDataSourceWorker, time.Second, stopCh)
+			op.logger.Infof("starting partition compaction")
+			op.runPartitionCompaction(stopCh)
 			wg.Done()
-			op.logger.Infof("ReportDataSource worker #%d stopped", i)
+			op.logger.Infof("partition compaction stopped")
+		}()
+	}
+
+	// Athena can't delete the data of a partition
+	if op.cfg.RetentionCheckInterval > 0 && op.cfg.QueryBackend != QueryBackendAthena {
+		wg.Add(1)
+		go func() {
+			op.logger.Infof("starting retention checker")
+			op.runRetentionCheck(stopCh)
+			wg.Done()
+			op.logger.Infof("retention checker stopped")
 		}()
 	}
 
+	if op.cfg.HDFSStorageCheckInterval > 0 {
+		wg.Add(1)
+		go func() {
+			op.logger.Infof("starting HDFS storage checker")
+			op.runHDFSStorageCheck(stopCh)
+			wg.Done()
+			op.logger.Infof("HDFS storage checker stopped")
+		}()
+	}
+
+	op.startReportDataSourceWorkers(wg, stopCh)
+
 	// Reports and ScheduledReports we want to limit the number running
 	// concurrently, and ReportGenerationQueries don't need many workers, so
-	// these resources get less workers.
-	threadiness = 2
+	// these resources get less workers, unless more reports are allowed to
+	// run at once.
+	threadiness := 2
+	if op.cfg.MaxConcurrentReports > threadiness {
+		threadiness = op.cfg.MaxConcurrentReports
+	}
 	for i := 0; i < threadiness; i++ {
 		i := i
 
@@ -660,12 +1442,41 @@ func (op *Reporting) startWorkers(wg sync.WaitGroup, stopCh <-chan struct{}) {
 	}
 }
 
+func (op *Reporting) startReportDataSourceWorkers(wg *sync.WaitGroup, stopCh <-chan struct{}) {
+	// We have a lot of ReportDataSources and we need to run more workers to
+	// make sure we collect data quickly
+	for i := 0; i < op.cfg.ReportDataSourceWorkers; i++ {
+		i := i
+
+		wg.Add(1)
+		go func() {
+			op.logger.Infof("starting ReportDataSource worker #%d", i)
+			wait.Until(op.runReportDataSourceWorker, time.Second, stopCh)
+			wg.Done()
+			op.logger.Infof("ReportDataSource worker #%d stopped", i)
+		}()
+	}
+}
+
 func (op *Reporting) setInitialized() {
 	op.initializedMu.Lock()
 	op.initialized = true
 	op.initializedMu.Unlock()
 }
 
+// addAPIRoutes adds the API endpoints implemented by Reporting, rather than
+// the API server, to router.
+func (op *Reporting) addAPIRoutes(router chi.Router) {
+	router.HandleFunc("/api/v1/namespaces/export", op.exportNamespaceDataHandler)
+	router.HandleFunc("/api/v1/reportgenerationqueries/preview", op.apiRateLimiter.limitDownloads(op.previewReportGenerationQueryHandler))
+	router.HandleFunc(APIV1ReportGenerationQueriesExecuteEndpoint, op.apiRateLimiter.limitDownloads(op.executeReportGenerationQueryHandler))
+	router.HandleFunc("/api/v1/config/export", op.exportConfigBundleHandler)
+	router.HandleFunc("/api/v1/config/import", op.importConfigBundleHandler)
+	if op.cfg.EnableRemoteWrite {
+		router.HandleFunc("/api/v1/prometheus/write", op.remoteWriteHandler)
+	}
+}
+
 func (op *Reporting) isInitialized() bool {
 	op.initializedMu.Lock()
 	initialized := op.initialized
@@ -674,10 +1485,11 @@ func (op *Reporting) isInitialized() bool {
 }
 
 func (op *Reporting) getDefaultReportGracePeriod() time.Duration {
-	if op.cfg.PrometheusQueryConfig.QueryInterval.Duration > op.cfg.PrometheusQueryConfig.ChunkSize.Duration {
-		return op.cfg.PrometheusQueryConfig.QueryInterval.Duration
+	queryConfig := op.config().PrometheusQueryConfig
+	if queryConfig.QueryInterval.Duration > queryConfig.ChunkSize.Duration {
+		return queryConfig.QueryInterval.Duration
 	} else {
-		return op.cfg.PrometheusQueryConfig.ChunkSize.Duration
+		return queryConfig.ChunkSize.Duration
 	}
 }
 
@@ -686,5 +1498,5 @@ func (op *Reporting) newPrometheusConn(promConfig promapi.Config) (prom.API, err
 	if err != nil {
 		return nil, fmt.Errorf("can't connect to prometheus: %v", err)
 	}
-	return prom.NewAPI(client), nil
+	return op.rateLimitPrometheusAPI(promConfig.Address, prom.NewAPI(client)), nil
 }
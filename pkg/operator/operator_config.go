@@ -0,0 +1,306 @@
+package operator
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+)
+
+const DefaultOperatorConfigName = "reporting-operator"
+
+// overlayOperatorConfig returns cfg with the fields set in the
+// ReportingOperatorConfig's spec overriding it. cfg isn't modified.
+func overlayOperatorConfig(cfg Config, spec *cbTypes.ReportingOperatorConfigSpec) (Config, error) {
+	if spec == nil {
+		return cfg, nil
+	}
+	if spec.LogLevel != "" {
+		if _, err := log.ParseLevel(spec.LogLevel); err != nil {
+			return cfg, fmt.Errorf("invalid logLevel: %v", err)
+		}
+	}
+	if spec.LogDMLQueries != nil {
+		cfg.LogDMLQueries = *spec.LogDMLQueries
+	}
+	if spec.LogDDLQueries != nil {
+		cfg.LogDDLQueries = *spec.LogDDLQueries
+	}
+
+	if promsum := spec.Promsum; promsum != nil {
+		// the pointers are shared with the config being overlaid, so they're
+		// replaced rather than modified.
+		queryConfig := cfg.PrometheusQueryConfig
+		durations := []struct {
+			name  string
+			value *meta.Duration
+			dest  **meta.Duration
+		}{
+			{"promsum.interval", promsum.Interval, &queryConfig.QueryInterval},
+			{"promsum.stepSize", promsum.StepSize, &queryConfig.StepSize},
+			{"promsum.chunkSize", promsum.ChunkSize, &queryConfig.ChunkSize},
+		}
+		for _, d := range durations {
+			if d.value == nil {
+				continue
+			}
+			if d.value.Duration <= 0 {
+				return cfg, fmt.Errorf("%s must be greater than 0, got %s", d.name, d.value.Duration)
+			}
+			*d.dest = &meta.Duration{Duration: d.value.Duration}
+		}
+		cfg.PrometheusQueryConfig = queryConfig
+
+		if promsum.MaxQueryRangeDuration != nil {
+			if promsum.MaxQueryRangeDuration.Duration <= 0 {
+				return cfg, fmt.Errorf("promsum.maxQueryRangeDuration must be greater than 0, got %s", promsum.MaxQueryRangeDuration.Duration)
+			}
+			cfg.PrometheusDataSourceMaxQueryRangeDuration = promsum.MaxQueryRangeDuration.Duration
+		}
+		if promsum.MaxBackfillImportDuration != nil {
+			if promsum.MaxBackfillImportDuration.Duration < 0 {
+				return cfg, fmt.Errorf("promsum.maxBackfillImportDuration cannot be negative, got %s", promsum.MaxBackfillImportDuration.Duration)
+			}
+			cfg.PrometheusDataSourceMaxBackfillImportDuration = promsum.MaxBackfillImportDuration.Duration
+		}
+		if promsum.MaxConcurrentImportsPerEndpoint != nil {
+			if *promsum.MaxConcurrentImportsPerEndpoint < 0 {
+				return cfg, fmt.Errorf("promsum.maxConcurrentImportsPerEndpoint cannot be negative, got %d", *promsum.MaxConcurrentImportsPerEndpoint)
+			}
+			cfg.PrometheusMaxConcurrentImportsPerEndpoint = *promsum.MaxConcurrentImportsPerEndpoint
+		}
+	}
+
+	if spec.ReportDataSourceWorkers != nil {
+		if *spec.ReportDataSourceWorkers < 1 {
+			return cfg, fmt.Errorf("reportDataSourceWorkers must be at least 1, got %d", *spec.ReportDataSourceWorkers)
+		}
+		cfg.ReportDataSourceWorkers = *spec.ReportDataSourceWorkers
+	}
+	if spec.MaxConcurrentReports != nil {
+		if *spec.MaxConcurrentReports < 0 {
+			return cfg, fmt.Errorf("maxConcurrentReports cannot be negative, got %d", *spec.MaxConcurrentReports)
+		}
+		cfg.MaxConcurrentReports = *spec.MaxConcurrentReports
+	}
+	if spec.PrestoMaxConcurrentQueries != nil {
+		if *spec.PrestoMaxConcurrentQueries < 0 {
+			return cfg, fmt.Errorf("prestoMaxConcurrentQueries cannot be negative, got %d", *spec.PrestoMaxConcurrentQueries)
+		}
+		cfg.PrestoMaxConcurrentQueries = *spec.PrestoMaxConcurrentQueries
+	}
+	if spec.ReportQueryTimeout != nil {
+		if spec.ReportQueryTimeout.Duration < 0 {
+			return cfg, fmt.Errorf("reportQueryTimeout cannot be negative, got %s", spec.ReportQueryTimeout.Duration)
+		}
+		cfg.ReportQueryTimeout = spec.ReportQueryTimeout.Duration
+	}
+
+	if spec.PrestoHost != "" {
+		cfg.PrestoHost = spec.PrestoHost
+	}
+	if spec.HiveHost != "" {
+		cfg.HiveHost = spec.HiveHost
+	}
+	if spec.HiveMetastoreHost != "" {
+		cfg.HiveMetastoreHost = spec.HiveMetastoreHost
+	}
+	if spec.PrometheusURL != "" {
+		cfg.PrometheusConfig.Address = spec.PrometheusURL
+	}
+	return cfg, nil
+}
+
+// restartRequiredChanges returns the names of the fields which differ
+// between old and new that are only used when reporting-operator starts.
+func restartRequiredChanges(old, new Config) []string {
+	var changed []string
+	fields := []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"reportDataSourceWorkers", old.ReportDataSourceWorkers, new.ReportDataSourceWorkers},
+		{"maxConcurrentReports", old.MaxConcurrentReports, new.MaxConcurrentReports},
+		{"prestoHost", old.PrestoHost, new.PrestoHost},
+		{"hiveHost", old.HiveHost, new.HiveHost},
+		{"hiveMetastoreHost", old.HiveMetastoreHost, new.HiveMetastoreHost},
+		{"prometheusURL", old.PrometheusConfig.Address, new.PrometheusConfig.Address},
+	}
+	for _, f := range fields {
+		if f.old != f.new {
+			changed = append(changed, f.name)
+		}
+	}
+	return changed
+}
+
+// config returns a copy of the operator's configuration. The fields which
+// can be changed by the ReportingOperatorConfig while the operator is
+// running must be read using it.
+func (op *Reporting) config() Config {
+	op.cfgMu.RLock()
+	defer op.cfgMu.RUnlock()
+	return op.cfg
+}
+
+// getOperatorConfigSpec returns the spec of the ReportingOperatorConfig in
+// the operator's namespace, or nil if it doesn't exist.
+func (op *Reporting) getOperatorConfigSpec() (*cbTypes.ReportingOperatorConfigSpec, error) {
+	operatorConfig, err := op.reportingOperatorConfigLister.ReportingOperatorConfigs(op.cfg.Namespace).Get(op.cfg.OperatorConfigName)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &operatorConfig.Spec, nil
+}
+
+// loadOperatorConfig applies the ReportingOperatorConfig to the operator's
+// configuration when it starts, and begins applying changes to it. An
+// invalid ReportingOperatorConfig is logged and ignored.
+func (op *Reporting) loadOperatorConfig() {
+	cfg := op.flagCfg
+	spec, err := op.getOperatorConfigSpec()
+	if err == nil {
+		cfg, err = overlayOperatorConfig(op.flagCfg, spec)
+	}
+	if err != nil {
+		op.logger.WithError(err).Errorf("unable to apply ReportingOperatorConfig %s, using the command line configuration", op.cfg.OperatorConfigName)
+		cfg, spec = op.flagCfg, nil
+	}
+
+	op.cfgMu.Lock()
+	defer op.cfgMu.Unlock()
+	op.cfg.ReportDataSourceWorkers = cfg.ReportDataSourceWorkers
+	op.cfg.MaxConcurrentReports = cfg.MaxConcurrentReports
+	op.cfg.PrestoHost = cfg.PrestoHost
+	op.cfg.HiveHost = cfg.HiveHost
+	op.cfg.HiveMetastoreHost = cfg.HiveMetastoreHost
+	op.cfg.PrometheusConfig.Address = cfg.PrometheusConfig.Address
+
+	op.reportSemaphore = nil
+	if cfg.MaxConcurrentReports > 0 {
+		op.reportSemaphore = make(chan struct{}, cfg.MaxConcurrentReports)
+	}
+	op.queryQueue = reporting.NewQueryQueue(cfg.PrestoMaxConcurrentQueries)
+	op.applyHotConfigLocked(cfg, spec)
+	op.operatorConfigLoaded = true
+}
+
+// applyHotConfigLocked applies the fields of cfg which can change while the
+// operator is running. op.cfgMu must be held.
+func (op *Reporting) applyHotConfigLocked(cfg Config, spec *cbTypes.ReportingOperatorConfigSpec) {
+	op.cfg.PrometheusQueryConfig = cfg.PrometheusQueryConfig
+	op.cfg.PrometheusDataSourceMaxQueryRangeDuration = cfg.PrometheusDataSourceMaxQueryRangeDuration
+	op.cfg.PrometheusDataSourceMaxBackfillImportDuration = cfg.PrometheusDataSourceMaxBackfillImportDuration
+	op.cfg.ReportQueryTimeout = cfg.ReportQueryTimeout
+	op.cfg.LogDMLQueries = cfg.LogDMLQueries
+	op.cfg.LogDDLQueries = cfg.LogDDLQueries
+	op.logDMLQueries.SetEnabled(cfg.LogDMLQueries)
+	op.logDDLQueries.SetEnabled(cfg.LogDDLQueries)
+
+	if op.cfg.PrometheusMaxConcurrentImportsPerEndpoint != cfg.PrometheusMaxConcurrentImportsPerEndpoint {
+		op.cfg.PrometheusMaxConcurrentImportsPerEndpoint = cfg.PrometheusMaxConcurrentImportsPerEndpoint
+		// imports which are running release their slot in the semaphore
+		// they acquired it from, new imports use the new limit.
+		op.prometheusImportSemaphoresMu.Lock()
+		op.prometheusImportSemaphores = make(map[string]chan struct{})
+		op.prometheusImportSemaphoresMu.Unlock()
+	}
+	if op.cfg.PrestoMaxConcurrentQueries != cfg.PrestoMaxConcurrentQueries {
+		op.cfg.PrestoMaxConcurrentQueries = cfg.PrestoMaxConcurrentQueries
+		op.queryQueue.SetMaxConcurrent(cfg.PrestoMaxConcurrentQueries)
+	}
+
+	if op.logLevelLogger != nil {
+		level := op.defaultLogLevel
+		if spec != nil && spec.LogLevel != "" {
+			// the level is validated by overlayOperatorConfig
+			level, _ = log.ParseLevel(spec.LogLevel)
+		}
+		op.setLogLevelLocked(level)
+	}
+}
+
+// handleOperatorConfigChange applies changes to the ReportingOperatorConfig.
+// spec is nil if it was deleted, restoring the command line configuration.
+// If a field only used when reporting-operator starts changed, the
+// operator is restarted.
+func (op *Reporting) handleOperatorConfigChange(spec *cbTypes.ReportingOperatorConfigSpec) {
+	cfg, err := overlayOperatorConfig(op.flagCfg, spec)
+	if err != nil {
+		op.logger.WithError(err).Errorf("unable to apply ReportingOperatorConfig %s, keeping the current configuration", op.cfg.OperatorConfigName)
+		return
+	}
+
+	op.cfgMu.Lock()
+	// changes made before the operator starts are applied by
+	// loadOperatorConfig.
+	if !op.operatorConfigLoaded {
+		op.cfgMu.Unlock()
+		return
+	}
+	intervalChanged := op.cfg.PrometheusQueryConfig.QueryInterval.Duration != cfg.PrometheusQueryConfig.QueryInterval.Duration
+	op.applyHotConfigLocked(cfg, spec)
+	changed := restartRequiredChanges(op.cfg, cfg)
+	op.cfgMu.Unlock()
+	op.logger.Infof("applied ReportingOperatorConfig %s", op.cfg.OperatorConfigName)
+
+	if len(changed) != 0 {
+		op.logger.Infof("ReportingOperatorConfig %s changed %v, which requires a restart", op.cfg.OperatorConfigName, changed)
+		op.operatorConfigRestartOnce.Do(func() {
+			close(op.operatorConfigRestartCh)
+		})
+		return
+	}
+
+	// Prometheus ReportDataSources are requeued after the interval they
+	// last ran with, so they're requeued now to use the new interval.
+	if intervalChanged {
+		dataSources, err := op.reportDataSourceLister.List(labels.Everything())
+		if err != nil {
+			op.logger.WithError(err).Errorf("unable to list ReportDataSources to apply the new promsum interval")
+			return
+		}
+		for _, dataSource := range dataSources {
+			if dataSource.Spec.Promsum != nil {
+				op.enqueueReportDataSource(dataSource)
+			}
+		}
+	}
+}
+
+func (op *Reporting) isOperatorConfig(obj interface{}) bool {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return false
+	}
+	return key == op.cfg.Namespace+"/"+op.cfg.OperatorConfigName
+}
+
+func (op *Reporting) addOperatorConfig(obj interface{}) {
+	operatorConfig := obj.(*cbTypes.ReportingOperatorConfig)
+	op.handleOperatorConfigChange(&operatorConfig.Spec)
+}
+
+func (op *Reporting) updateOperatorConfig(prev, cur interface{}) {
+	prevOperatorConfig := prev.(*cbTypes.ReportingOperatorConfig)
+	curOperatorConfig := cur.(*cbTypes.ReportingOperatorConfig)
+	// ignore resyncs
+	if prevOperatorConfig.ResourceVersion == curOperatorConfig.ResourceVersion {
+		return
+	}
+	op.handleOperatorConfigChange(&curOperatorConfig.Spec)
+}
+
+func (op *Reporting) deleteOperatorConfig(obj interface{}) {
+	op.logger.Infof("ReportingOperatorConfig %s deleted, restoring the command line configuration", op.cfg.OperatorConfigName)
+	op.handleOperatorConfigChange(nil)
+}
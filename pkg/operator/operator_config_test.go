@@ -0,0 +1,118 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/db"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+)
+
+func newTestOperatorConfigFlags() Config {
+	return Config{
+		PrestoHost:              "presto:8080",
+		ReportDataSourceWorkers: 4,
+		ReportQueryTimeout:      time.Hour,
+		PrometheusQueryConfig: cbTypes.PrometheusQueryConfig{
+			QueryInterval: &meta.Duration{Duration: 5 * time.Minute},
+			StepSize:      &meta.Duration{Duration: time.Minute},
+			ChunkSize:     &meta.Duration{Duration: 5 * time.Minute},
+		},
+		PrometheusDataSourceMaxQueryRangeDuration: 10 * time.Minute,
+	}
+}
+
+func TestOverlayOperatorConfig(t *testing.T) {
+	flags := newTestOperatorConfigFlags()
+	workers := 8
+	queries := 2
+
+	cfg, err := overlayOperatorConfig(flags, &cbTypes.ReportingOperatorConfigSpec{
+		Promsum: &cbTypes.ReportingOperatorPromsumConfig{
+			Interval: &meta.Duration{Duration: time.Minute},
+		},
+		ReportDataSourceWorkers:    &workers,
+		PrestoMaxConcurrentQueries: &queries,
+		PrestoHost:                 "presto.example.com:8080",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, cfg.PrometheusQueryConfig.QueryInterval.Duration)
+	assert.Equal(t, time.Minute, cfg.PrometheusQueryConfig.StepSize.Duration, "expected unset fields to use the flags")
+	assert.Equal(t, 8, cfg.ReportDataSourceWorkers)
+	assert.Equal(t, 2, cfg.PrestoMaxConcurrentQueries)
+	assert.Equal(t, time.Hour, cfg.ReportQueryTimeout)
+	assert.Equal(t, "presto.example.com:8080", cfg.PrestoHost)
+	assert.Equal(t, 5*time.Minute, flags.PrometheusQueryConfig.QueryInterval.Duration, "expected the flags not to be modified")
+
+	assert.Equal(t, []string{"reportDataSourceWorkers", "prestoHost"}, restartRequiredChanges(flags, cfg))
+
+	cfg, err = overlayOperatorConfig(flags, nil)
+	require.NoError(t, err)
+	assert.Empty(t, restartRequiredChanges(flags, cfg))
+
+	invalid := []*cbTypes.ReportingOperatorConfigSpec{
+		{LogLevel: "loud"},
+		{Promsum: &cbTypes.ReportingOperatorPromsumConfig{ChunkSize: &meta.Duration{}}},
+		{ReportDataSourceWorkers: new(int)},
+		{ReportQueryTimeout: &meta.Duration{Duration: -time.Second}},
+	}
+	for _, spec := range invalid {
+		_, err := overlayOperatorConfig(flags, spec)
+		assert.Error(t, err, "expected %+v to be invalid", spec)
+	}
+}
+
+func TestHandleOperatorConfigChange(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.InfoLevel)
+	flags := newTestOperatorConfigFlags()
+	op := &Reporting{
+		cfg:                        flags,
+		flagCfg:                    flags,
+		logger:                     logger,
+		logLevelLogger:             logger,
+		defaultLogLevel:            logrus.InfoLevel,
+		queryQueue:                 reporting.NewQueryQueue(0),
+		prometheusImportSemaphores: make(map[string]chan struct{}),
+		operatorConfigLoaded:       true,
+		operatorConfigRestartCh:    make(chan struct{}),
+		logDMLQueries:              db.NewQueryLogging(false),
+		logDDLQueries:              db.NewQueryLogging(false),
+	}
+
+	timeout := &meta.Duration{Duration: 10 * time.Minute}
+	logDMLQueries := true
+	op.handleOperatorConfigChange(&cbTypes.ReportingOperatorConfigSpec{
+		LogLevel:           "debug",
+		LogDMLQueries:      &logDMLQueries,
+		ReportQueryTimeout: timeout,
+	})
+	assert.Equal(t, 10*time.Minute, op.config().ReportQueryTimeout)
+	assert.Equal(t, logrus.DebugLevel, logger.Level)
+	assert.True(t, op.logDMLQueries.Enabled())
+	select {
+	case <-op.operatorConfigRestartCh:
+		t.Fatal("expected changing the report query timeout not to restart the operator")
+	default:
+	}
+
+	// deleting the ReportingOperatorConfig restores the flags
+	op.handleOperatorConfigChange(nil)
+	assert.Equal(t, time.Hour, op.config().ReportQueryTimeout)
+	assert.Equal(t, logrus.InfoLevel, logger.Level)
+	assert.False(t, op.logDMLQueries.Enabled())
+
+	op.handleOperatorConfigChange(&cbTypes.ReportingOperatorConfigSpec{HiveHost: "hive.example.com:10000"})
+	select {
+	case <-op.operatorConfigRestartCh:
+	default:
+		t.Fatal("expected changing the Hive host to restart the operator")
+	}
+	assert.Equal(t, "", op.config().HiveHost, "expected the Hive host to be applied by the restart")
+}
@@ -0,0 +1,102 @@
+package operator
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestCancelBeforeWorkersStart(t *testing.T) {
+	// stopping before the workers start cancels everything immediately
+	stopCh := make(chan struct{})
+	shutdownCtx, cancelShutdown := context.WithCancel(context.Background())
+	workCtx, cancelWork := context.WithCancel(context.Background())
+	close(stopCh)
+	cancelBeforeWorkersStart(stopCh, make(chan struct{}), cancelShutdown, cancelWork)
+	assert.Error(t, shutdownCtx.Err())
+	assert.Error(t, workCtx.Err())
+
+	// once the workers are starting, stopping is left to draining them
+	stopCh = make(chan struct{})
+	startingWorkersCh := make(chan struct{})
+	workCtx, cancelWork = context.WithCancel(context.Background())
+	defer cancelWork()
+	close(startingWorkersCh)
+	cancelBeforeWorkersStart(stopCh, startingWorkersCh, cancelWork)
+	close(stopCh)
+	assert.NoError(t, workCtx.Err())
+}
+
+func TestDrainWorkers(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		finishAfter     time.Duration
+		expectCancelled bool
+	}{
+		{
+			name:        "workers finishing within the drain timeout aren't cancelled",
+			finishAfter: 10 * time.Second,
+		},
+		{
+			name:            "workers still running after the drain timeout are cancelled",
+			expectCancelled: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClock := clock.NewFakeClock(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC))
+			op := &Reporting{
+				logger: logrus.New(),
+				clock:  fakeClock,
+				cfg:    Config{ShutdownDrainTimeout: 20 * time.Second},
+			}
+			workCtx, cancelWork := context.WithCancel(context.Background())
+			defer cancelWork()
+
+			// the worker blocks until it's work is cancelled, or it finishes
+			finishCh := make(chan struct{})
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				select {
+				case <-workCtx.Done():
+				case <-finishCh:
+				}
+			}()
+
+			drained := make(chan struct{})
+			go func() {
+				op.drainWorkers(&wg, cancelWork)
+				close(drained)
+			}()
+			waitForTimer(fakeClock)
+			select {
+			case <-drained:
+				t.Fatal("expected draining to wait for the worker")
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			if tt.finishAfter != 0 {
+				fakeClock.Step(tt.finishAfter)
+				close(finishCh)
+			} else {
+				fakeClock.Step(op.cfg.ShutdownDrainTimeout)
+			}
+			select {
+			case <-drained:
+			case <-time.After(time.Second):
+				t.Fatal("expected draining to finish once the worker stopped")
+			}
+			if tt.expectCancelled {
+				assert.Error(t, workCtx.Err(), "expected the in-flight work to be cancelled")
+			} else {
+				assert.NoError(t, workCtx.Err(), "expected the in-flight work not to be cancelled")
+			}
+		})
+	}
+}
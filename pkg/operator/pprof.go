@@ -5,7 +5,9 @@ import (
 	"net/http/pprof"
 )
 
-func newPprofServer() *http.Server {
+// newPprofServer returns the server for the pprof endpoints, and the
+// endpoint changing the log level, which only listens on localhost.
+func newPprofServer(logLevelHandler http.HandlerFunc) *http.Server {
 	pprofMux := http.NewServeMux()
 
 	pprofMux.HandleFunc("/debug/pprof/", pprof.Index)
@@ -13,6 +15,7 @@ func newPprofServer() *http.Server {
 	pprofMux.HandleFunc("/debug/pprof/profile", pprof.Profile)
 	pprofMux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	pprofMux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	pprofMux.HandleFunc("/debug/loglevel", logLevelHandler)
 
 	return &http.Server{
 		Addr:    "127.0.0.1:6060",
@@ -0,0 +1,197 @@
+package prestostore
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/operator-framework/operator-metering/pkg/clickhouse"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// SQLDialect generates the SQL used to insert and read rows, which differs
+// between the query backends data is stored using.
+type SQLDialect interface {
+	// StringLiteral returns a string literal of s.
+	StringLiteral(s string) string
+	// FormatTimestamp formats t as a string which can be cast to a
+	// timestamp.
+	FormatTimestamp(t time.Time) string
+	// TimestampLiteral returns a timestamp literal of t.
+	TimestampLiteral(t time.Time) string
+	// MapLiteral returns a literal of a map<string, string> column's value.
+	MapLiteral(m map[string]string) string
+	// CastLiteral returns an expression casting the string literal to the
+	// column type.
+	CastLiteral(literal, columnType string) string
+	// ParseMap converts the value of a map<string, string> column returned
+	// by a query into a map.
+	ParseMap(value interface{}) (map[string]string, error)
+	// GetRowsQuery returns a query selecting the columns of every row in
+	// the table, ordered by the columns.
+	GetRowsQuery(tableName string, columns []presto.Column) string
+	// GetRowsWhereQuery returns a query selecting the columns of the rows
+	// in the table matching the condition, ordered by the columns.
+	GetRowsWhereQuery(tableName string, columns []presto.Column, condition string) string
+	// DeleteAllQuery returns a statement deleting every row in the table.
+	DeleteAllQuery(tableName string) string
+}
+
+var (
+	// PrestoSQLDialect is the dialect of Presto, which is also used by
+	// Athena.
+	PrestoSQLDialect SQLDialect = prestoSQLDialect{}
+	// ClickHouseSQLDialect is the dialect of ClickHouse, which stores
+	// map<string, string> columns as arrays of key/value tuples.
+	ClickHouseSQLDialect SQLDialect = clickHouseSQLDialect{}
+)
+
+type prestoSQLDialect struct{}
+
+func (prestoSQLDialect) StringLiteral(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+func (prestoSQLDialect) FormatTimestamp(t time.Time) string {
+	return t.UTC().Format(presto.TimestampFormat)
+}
+
+func (d prestoSQLDialect) TimestampLiteral(t time.Time) string {
+	return fmt.Sprintf("timestamp '%s'", d.FormatTimestamp(t))
+}
+
+// MapLiteral creates the map from an array of keys and an array of values,
+// as recommended by the Presto documentation.
+func (d prestoSQLDialect) MapLiteral(m map[string]string) string {
+	var keys []string
+	var vals []string
+	for k, v := range m {
+		keys = append(keys, d.StringLiteral(k))
+		vals = append(vals, d.StringLiteral(v))
+	}
+	return fmt.Sprintf("map(ARRAY[%s],ARRAY[%s])", strings.Join(keys, ","), strings.Join(vals, ","))
+}
+
+func (prestoSQLDialect) CastLiteral(literal, columnType string) string {
+	if strings.ToLower(columnType) == "varchar" {
+		return literal
+	}
+	return fmt.Sprintf("CAST(%s AS %s)", literal, columnType)
+}
+
+func (prestoSQLDialect) ParseMap(value interface{}) (map[string]string, error) {
+	values, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a map, got %T", value)
+	}
+	m := make(map[string]string, len(values))
+	for key, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid value for key %s, valueType: %T, value: %+v", key, v, v)
+		}
+		m[key] = s
+	}
+	return m, nil
+}
+
+func (prestoSQLDialect) GetRowsQuery(tableName string, columns []presto.Column) string {
+	return presto.GenerateGetRowsSQL(tableName, columns)
+}
+
+func (prestoSQLDialect) GetRowsWhereQuery(tableName string, columns []presto.Column, condition string) string {
+	return fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY %s", presto.GenerateQuotedColumnsListSQL(columns), tableName, condition, presto.GenerateOrderBySQL(columns))
+}
+
+func (prestoSQLDialect) DeleteAllQuery(tableName string) string {
+	return fmt.Sprintf("DELETE FROM %s", tableName)
+}
+
+type clickHouseSQLDialect struct{}
+
+func (clickHouseSQLDialect) StringLiteral(s string) string {
+	return clickhouse.QuoteString(s)
+}
+
+// FormatTimestamp formats t without fractional seconds, as ClickHouse
+// DateTime columns have a precision of a second.
+func (clickHouseSQLDialect) FormatTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+// TimestampLiteral returns a string literal, which ClickHouse converts when
+// it's inserted into or compared with a DateTime column. Unlike a function
+// call, it can be parsed by ClickHouse's fast path for inserting values.
+func (d clickHouseSQLDialect) TimestampLiteral(t time.Time) string {
+	return d.StringLiteral(d.FormatTimestamp(t))
+}
+
+func (d clickHouseSQLDialect) MapLiteral(m map[string]string) string {
+	var entries []string
+	for k, v := range m {
+		entries = append(entries, fmt.Sprintf("(%s,%s)", d.StringLiteral(k), d.StringLiteral(v)))
+	}
+	return "[" + strings.Join(entries, ",") + "]"
+}
+
+// CastLiteral casts the literal to the ClickHouse equivalent of the column
+// type. If the column type has no equivalent, the literal is returned as is.
+func (clickHouseSQLDialect) CastLiteral(literal, columnType string) string {
+	colType, err := clickhouse.ColumnType(columnType)
+	if err != nil || colType == "String" {
+		return literal
+	}
+	return fmt.Sprintf("CAST(%s AS %s)", literal, colType)
+}
+
+func (clickHouseSQLDialect) ParseMap(value interface{}) (map[string]string, error) {
+	entries, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an array of key/value tuples, got %T", value)
+	}
+	m := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		kv, ok := entry.([]interface{})
+		if !ok || len(kv) != 2 {
+			return nil, fmt.Errorf("expected a key/value tuple, got %+v", entry)
+		}
+		key, keyOK := kv[0].(string)
+		val, valOK := kv[1].(string)
+		if !keyOK || !valOK {
+			return nil, fmt.Errorf("invalid key/value tuple %+v", entry)
+		}
+		m[key] = val
+	}
+	return m, nil
+}
+
+// GetRowsQuery lowercases the column names, as ClickHouse column names are
+// case sensitive, and tables are created with lowercase column names. Arrays,
+// which maps are stored as, are excluded from the ORDER BY.
+func (d clickHouseSQLDialect) GetRowsQuery(tableName string, columns []presto.Column) string {
+	return d.GetRowsWhereQuery(tableName, columns, "")
+}
+
+func (clickHouseSQLDialect) GetRowsWhereQuery(tableName string, columns []presto.Column, condition string) string {
+	var columnNames, orderBy []string
+	for _, col := range columns {
+		quoted := "`" + strings.ToLower(col.Name) + "`"
+		columnNames = append(columnNames, quoted)
+		colType := strings.ToLower(col.Type)
+		if !strings.Contains(colType, "map") && !strings.Contains(colType, "array") {
+			orderBy = append(orderBy, quoted)
+		}
+	}
+	query := fmt.Sprintf("SELECT %s FROM `%s`", strings.Join(columnNames, ","), tableName)
+	if condition != "" {
+		query += " WHERE " + condition
+	}
+	if len(orderBy) != 0 {
+		query += fmt.Sprintf(" ORDER BY %s ASC", strings.Join(orderBy, ", "))
+	}
+	return query
+}
+
+func (clickHouseSQLDialect) DeleteAllQuery(tableName string) string {
+	return fmt.Sprintf("TRUNCATE TABLE `%s`", tableName)
+}
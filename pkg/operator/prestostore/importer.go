@@ -47,6 +47,10 @@ type PrometheusImporter struct {
 
 	// lastTimestamp is the lastTimestamp stored for this PrometheusImporter
 	lastTimestamp *time.Time
+	// lastTimestampStored is true when lastTimestamp is known to be the
+	// newest timestamp in the table, rather than a checkpoint which the
+	// table may have been written past
+	lastTimestampStored bool
 }
 
 type Config struct {
@@ -58,6 +62,10 @@ type Config struct {
 	MaxQueryRangeDuration     time.Duration
 	ImportFromTime            *time.Time
 	MaxBackfillImportDuration time.Duration
+	PartitionGranularity      PartitionGranularity
+	// ClusterID is stored with each metric imported, identifying the
+	// cluster it was collected from.
+	ClusterID string
 }
 
 func NewPrometheusImporter(logger logrus.FieldLogger, promConn prom.API, prometheusMetricsRepo PrometheusMetricsRepo, clock clock.Clock, cfg Config, collectors ImporterMetricsCollectors) *PrometheusImporter {
@@ -88,6 +96,15 @@ func (importer *PrometheusImporter) UpdateConfig(cfg Config) {
 	importer.importLock.Unlock()
 }
 
+// SetLastTimestamp sets the timestamp the next import continues from, such as
+// a checkpoint recorded before reporting-operator restarted.
+func (importer *PrometheusImporter) SetLastTimestamp(lastTimestamp time.Time) {
+	importer.importLock.Lock()
+	importer.lastTimestamp = &lastTimestamp
+	importer.lastTimestampStored = false
+	importer.importLock.Unlock()
+}
+
 // ImportFromLastTimestamp executes a Presto query from the last time range it
 // queried and stores the results in a Presto table.
 // The importer will track the last time series it retrieved and will query
@@ -116,6 +133,7 @@ func (importer *PrometheusImporter) ImportFromLastTimestamp(ctx context.Context,
 			importer.logger.WithError(err).Errorf("unable to get last timestamp for table %s", cfg.PrestoTableName)
 			return nil, err
 		}
+		importer.lastTimestampStored = true
 	}
 
 	var startTime time.Time
@@ -150,12 +168,23 @@ func (importer *PrometheusImporter) ImportFromLastTimestamp(ctx context.Context,
 		endTime = startTime.Add(cfg.MaxQueryRangeDuration)
 	}
 
+	// when the table doesn't contain metrics after the last timestamp, only
+	// metrics overlapping it need to be checked for duplicates when stored
+	if importer.lastTimestampStored {
+		var lastStoredTimestamp time.Time
+		if importer.lastTimestamp != nil {
+			lastStoredTimestamp = *importer.lastTimestamp
+		}
+		ctx = WithLastStoredTimestamp(ctx, lastStoredTimestamp)
+	}
+
 	importResults, err := ImportFromTimeRange(importer.logger, importer.clock, importer.promConn, importer.prometheusMetricsRepo, importer.metricsCollectors, ctx, startTime, endTime, cfg, allowIncompleteChunks)
 	if err != nil {
 		importer.logger.WithError(err).Error("error collecting metrics")
 		// at this point we cannot be sure what is in Presto and what
 		// isn't, so reset our importer.lastTimestamp
 		importer.lastTimestamp = nil
+		importer.lastTimestampStored = false
 		return &importResults, err
 	}
 
@@ -166,7 +195,7 @@ func (importer *PrometheusImporter) ImportFromLastTimestamp(ctx context.Context,
 	return &importResults, nil
 }
 
-func promMatrixToPrometheusMetrics(timeRange prom.Range, matrix model.Matrix) []*PrometheusMetric {
+func promMatrixToPrometheusMetrics(timeRange prom.Range, matrix model.Matrix, clusterID string) []*PrometheusMetric {
 	var metrics []*PrometheusMetric
 	// iterate over segments of contiguous billing metrics
 	for _, sampleStream := range matrix {
@@ -181,6 +210,7 @@ func promMatrixToPrometheusMetrics(timeRange prom.Range, matrix model.Matrix) []
 				Amount:    float64(value.Value),
 				StepSize:  timeRange.Step,
 				Timestamp: value.Timestamp.Time().UTC(),
+				ClusterID: clusterID,
 			}
 			metrics = append(metrics, metric)
 		}
@@ -5,6 +5,7 @@
 package mockprestostore
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	presto "github.com/operator-framework/operator-metering/pkg/presto"
 	reflect "reflect"
@@ -33,39 +34,79 @@ func (m *MockReportResultsRepo) EXPECT() *MockReportResultsRepoMockRecorder {
 	return m.recorder
 }
 
+// CountReportResults mocks base method
+func (m *MockReportResultsRepo) CountReportResults(arg0 context.Context, arg1 string) (int64, error) {
+	ret := m.ctrl.Call(m, "CountReportResults", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountReportResults indicates an expected call of CountReportResults
+func (mr *MockReportResultsRepoMockRecorder) CountReportResults(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountReportResults", reflect.TypeOf((*MockReportResultsRepo)(nil).CountReportResults), arg0, arg1)
+}
+
 // DeleteReportResults mocks base method
-func (m *MockReportResultsRepo) DeleteReportResults(arg0 string) error {
-	ret := m.ctrl.Call(m, "DeleteReportResults", arg0)
+func (m *MockReportResultsRepo) DeleteReportResults(arg0 context.Context, arg1 string) error {
+	ret := m.ctrl.Call(m, "DeleteReportResults", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteReportResults indicates an expected call of DeleteReportResults
-func (mr *MockReportResultsRepoMockRecorder) DeleteReportResults(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReportResults", reflect.TypeOf((*MockReportResultsRepo)(nil).DeleteReportResults), arg0)
+func (mr *MockReportResultsRepoMockRecorder) DeleteReportResults(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReportResults", reflect.TypeOf((*MockReportResultsRepo)(nil).DeleteReportResults), arg0, arg1)
+}
+
+// ExplainReportResults mocks base method
+func (m *MockReportResultsRepo) ExplainReportResults(arg0 context.Context, arg1 string, arg2 bool) (string, error) {
+	ret := m.ctrl.Call(m, "ExplainReportResults", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExplainReportResults indicates an expected call of ExplainReportResults
+func (mr *MockReportResultsRepoMockRecorder) ExplainReportResults(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExplainReportResults", reflect.TypeOf((*MockReportResultsRepo)(nil).ExplainReportResults), arg0, arg1, arg2)
 }
 
 // GetReportResults mocks base method
-func (m *MockReportResultsRepo) GetReportResults(arg0 string, arg1 []presto.Column) ([]presto.Row, error) {
-	ret := m.ctrl.Call(m, "GetReportResults", arg0, arg1)
+func (m *MockReportResultsRepo) GetReportResults(arg0 context.Context, arg1 string, arg2 []presto.Column) ([]presto.Row, error) {
+	ret := m.ctrl.Call(m, "GetReportResults", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]presto.Row)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetReportResults indicates an expected call of GetReportResults
-func (mr *MockReportResultsRepoMockRecorder) GetReportResults(arg0, arg1 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportResults", reflect.TypeOf((*MockReportResultsRepo)(nil).GetReportResults), arg0, arg1)
+func (mr *MockReportResultsRepoMockRecorder) GetReportResults(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportResults", reflect.TypeOf((*MockReportResultsRepo)(nil).GetReportResults), arg0, arg1, arg2)
+}
+
+// PreviewReportResults mocks base method
+func (m *MockReportResultsRepo) PreviewReportResults(arg0 context.Context, arg1 string, arg2 int) ([]presto.Column, []presto.Row, error) {
+	ret := m.ctrl.Call(m, "PreviewReportResults", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]presto.Column)
+	ret1, _ := ret[1].([]presto.Row)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PreviewReportResults indicates an expected call of PreviewReportResults
+func (mr *MockReportResultsRepoMockRecorder) PreviewReportResults(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewReportResults", reflect.TypeOf((*MockReportResultsRepo)(nil).PreviewReportResults), arg0, arg1, arg2)
 }
 
 // StoreReportResults mocks base method
-func (m *MockReportResultsRepo) StoreReportResults(arg0, arg1 string) error {
-	ret := m.ctrl.Call(m, "StoreReportResults", arg0, arg1)
+func (m *MockReportResultsRepo) StoreReportResults(arg0 context.Context, arg1, arg2 string) error {
+	ret := m.ctrl.Call(m, "StoreReportResults", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // StoreReportResults indicates an expected call of StoreReportResults
-func (mr *MockReportResultsRepoMockRecorder) StoreReportResults(arg0, arg1 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreReportResults", reflect.TypeOf((*MockReportResultsRepo)(nil).StoreReportResults), arg0, arg1)
+func (mr *MockReportResultsRepoMockRecorder) StoreReportResults(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreReportResults", reflect.TypeOf((*MockReportResultsRepo)(nil).StoreReportResults), arg0, arg1, arg2)
 }
@@ -0,0 +1,104 @@
+package prestostore
+
+import (
+	"fmt"
+	"time"
+)
+
+// PartitionGranularity is the period of time covered by each dt partition of
+// a table storing Prometheus metrics.
+type PartitionGranularity string
+
+const (
+	PartitionGranularityHourly PartitionGranularity = "hourly"
+	PartitionGranularityDaily  PartitionGranularity = "daily"
+	PartitionGranularityWeekly PartitionGranularity = "weekly"
+
+	// PrometheusMetricTimestampPartitionFormat is the format of the dt
+	// partitions of daily and weekly partitioned tables. Weekly partitions
+	// are named after the Monday they begin on.
+	PrometheusMetricTimestampPartitionFormat = "2006-01-02"
+	// PrometheusMetricHourlyTimestampPartitionFormat is the format of the dt
+	// partitions of hourly partitioned tables. Each partition sorts after
+	// the daily partition of the same day.
+	PrometheusMetricHourlyTimestampPartitionFormat = "2006-01-02T15"
+)
+
+// Validate returns an error if the granularity isn't supported. An empty
+// granularity is daily.
+func (g PartitionGranularity) Validate() error {
+	switch g {
+	case "", PartitionGranularityHourly, PartitionGranularityDaily, PartitionGranularityWeekly:
+		return nil
+	default:
+		return fmt.Errorf("invalid partition granularity %q, must be one of %s, %s or %s", g, PartitionGranularityHourly, PartitionGranularityDaily, PartitionGranularityWeekly)
+	}
+}
+
+// PartitionStart returns the start of the partition containing t.
+func (g PartitionGranularity) PartitionStart(t time.Time) time.Time {
+	t = t.UTC()
+	switch g {
+	case PartitionGranularityHourly:
+		return t.Truncate(time.Hour)
+	case PartitionGranularityWeekly:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		// time.Weekday starts on Sunday, weeks start on Monday
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// NextPartitionStart returns the start of the partition after the one
+// containing t.
+func (g PartitionGranularity) NextPartitionStart(t time.Time) time.Time {
+	start := g.PartitionStart(t)
+	switch g {
+	case PartitionGranularityHourly:
+		return start.Add(time.Hour)
+	case PartitionGranularityWeekly:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Partition returns the dt partition containing t.
+func (g PartitionGranularity) Partition(t time.Time) string {
+	if g == PartitionGranularityHourly {
+		return g.PartitionStart(t).Format(PrometheusMetricHourlyTimestampPartitionFormat)
+	}
+	return g.PartitionStart(t).Format(PrometheusMetricTimestampPartitionFormat)
+}
+
+// Partitions returns the dt partitions which may contain rows between start,
+// inclusive, and end, exclusive.
+func (g PartitionGranularity) Partitions(start, end time.Time) []string {
+	var partitions []string
+	for t := g.PartitionStart(start); t.Before(end) || len(partitions) == 0; t = g.NextPartitionStart(t) {
+		partitions = append(partitions, g.Partition(t))
+	}
+	return partitions
+}
+
+// PrometheusMetricTimestampPartition returns the daily dt partition
+// containing t.
+func PrometheusMetricTimestampPartition(t time.Time) string {
+	return PartitionGranularityDaily.Partition(t)
+}
+
+// PrometheusMetricPartitionRangeStart and PrometheusMetricPartitionRangeEnd
+// return the bounds of a filter such as "dt >= start AND dt <= end", which
+// includes every partition which may contain rows between start and end,
+// regardless of the table's partition granularity. This allows tables to be
+// queried without knowing their granularity, and tables to contain
+// partitions of different granularities.
+func PrometheusMetricPartitionRangeStart(start time.Time) string {
+	return PartitionGranularityWeekly.Partition(start)
+}
+
+func PrometheusMetricPartitionRangeEnd(end time.Time) string {
+	lastHour := PartitionGranularityDaily.PartitionStart(end).Add(23 * time.Hour)
+	return PartitionGranularityHourly.Partition(lastHour)
+}
@@ -0,0 +1,37 @@
+package prestostore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartitionGranularityPartition(t *testing.T) {
+	// a Sunday
+	ts := time.Date(2019, time.January, 6, 23, 30, 0, 0, time.UTC)
+	assert.Equal(t, "2019-01-06T23", PartitionGranularityHourly.Partition(ts))
+	assert.Equal(t, "2019-01-06", PartitionGranularityDaily.Partition(ts))
+	assert.Equal(t, "2019-01-06", PartitionGranularity("").Partition(ts))
+	assert.Equal(t, "2018-12-31", PartitionGranularityWeekly.Partition(ts))
+	assert.Equal(t, "2019-01-07", PartitionGranularityWeekly.Partition(ts.Add(time.Hour)))
+
+	assert.NoError(t, PartitionGranularityWeekly.Validate())
+	assert.NoError(t, PartitionGranularity("").Validate())
+	assert.Error(t, PartitionGranularity("monthly").Validate())
+}
+
+func TestPrometheusMetricPartitionRange(t *testing.T) {
+	start := time.Date(2019, time.January, 2, 12, 0, 0, 0, time.UTC)
+	end := time.Date(2019, time.January, 3, 6, 0, 0, 0, time.UTC)
+	rangeStart := PrometheusMetricPartitionRangeStart(start)
+	rangeEnd := PrometheusMetricPartitionRangeEnd(end)
+
+	for _, granularity := range []PartitionGranularity{PartitionGranularityHourly, PartitionGranularityDaily, PartitionGranularityWeekly} {
+		for _, dt := range granularity.Partitions(start, end.Add(time.Nanosecond)) {
+			assert.True(t, dt >= rangeStart && dt <= rangeEnd, "expected %s partition %s to be between %s and %s", granularity, dt, rangeStart, rangeEnd)
+		}
+	}
+	assert.True(t, PartitionGranularityDaily.Partition(end.AddDate(0, 0, 1)) > rangeEnd)
+	assert.True(t, PartitionGranularityHourly.Partition(end.AddDate(0, 0, 1)) > rangeEnd)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -27,6 +28,7 @@ var (
 		{Name: "timestamp", Type: "timestamp"},
 		{Name: "timePrecision", Type: "double"},
 		{Name: "labels", Type: "map(varchar, varchar)"},
+		{Name: "cluster_id", Type: "varchar"},
 	}
 )
 
@@ -40,7 +42,7 @@ func NewBufferPool(capacity int) sync.Pool {
 }
 
 type PrometheusMetricsStorer interface {
-	StorePrometheusMetrics(ctx context.Context, tableName string, metrics []*PrometheusMetric) error
+	StorePrometheusMetrics(ctx context.Context, tableName string, granularity PartitionGranularity, metrics []*PrometheusMetric) error
 }
 
 type PrometheusMetricsGetter interface {
@@ -59,28 +61,78 @@ type PrometheusMetricsRepo interface {
 
 type prometheusMetricRepo struct {
 	queryer         db.Queryer
+	dialect         SQLDialect
 	queryBufferPool sync.Pool
 }
 
-func NewPrometheusMetricsRepo(queryer db.Queryer, queryBufferPool *sync.Pool) *prometheusMetricRepo {
+func NewPrometheusMetricsRepo(queryer db.Queryer, dialect SQLDialect, queryBufferPool *sync.Pool) *prometheusMetricRepo {
 	if queryBufferPool == nil {
 		queryBufferPool = &defaultQueryBufferPool
 	}
 	return &prometheusMetricRepo{
 		queryer:         queryer,
+		dialect:         dialect,
 		queryBufferPool: *queryBufferPool,
 	}
 }
 
-func (r *prometheusMetricRepo) StorePrometheusMetrics(ctx context.Context, tableName string, metrics []*PrometheusMetric) error {
+type lastStoredTimestampContextKey struct{}
+
+// WithLastStoredTimestamp returns a context whose metrics are stored into a
+// table known not to contain metrics after lastStoredTimestamp, such as the
+// lastTimestamp of a PrometheusImporter, so StorePrometheusMetrics only
+// checks the metrics up to it for duplicates. A zero lastStoredTimestamp
+// means the table doesn't contain any metrics.
+func WithLastStoredTimestamp(ctx context.Context, lastStoredTimestamp time.Time) context.Context {
+	return context.WithValue(ctx, lastStoredTimestampContextKey{}, lastStoredTimestamp)
+}
+
+// storedPrometheusMetricsTimeRange returns the time range of the metrics
+// which may already be stored, and false if none of them can be.
+func storedPrometheusMetricsTimeRange(ctx context.Context, metrics []*PrometheusMetric) (start, end time.Time, ok bool) {
+	if len(metrics) == 0 {
+		return start, end, false
+	}
+	start, end = prometheusMetricsTimeRange(metrics)
+	if lastStoredTimestamp, known := ctx.Value(lastStoredTimestampContextKey{}).(time.Time); known {
+		if lastStoredTimestamp.Before(start) {
+			return start, end, false
+		}
+		if lastStoredTimestamp.Before(end) {
+			end = lastStoredTimestamp
+		}
+	}
+	return start, end, true
+}
+
+// StorePrometheusMetrics stores the metrics into the table, which is
+// partitioned using granularity, skipping any which have already been
+// stored, so importing the same time range more than once doesn't result in
+// duplicated rows. If ctx is from WithLastStoredTimestamp, only the metrics
+// up to it's timestamp are checked.
+func (r *prometheusMetricRepo) StorePrometheusMetrics(ctx context.Context, tableName string, granularity PartitionGranularity, metrics []*PrometheusMetric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+	if start, end, ok := storedPrometheusMetricsTimeRange(ctx, metrics); ok {
+		stored, err := getStoredPrometheusMetricKeys(ctx, r.queryer, r.dialect, tableName, start, end)
+		if err != nil {
+			return fmt.Errorf("failed to get metrics already stored in table %s: %v", tableName, err)
+		}
+		metrics = DeduplicatePrometheusMetrics(metrics, stored)
+		if len(metrics) == 0 {
+			return nil
+		}
+	}
+
 	queryBuf := r.queryBufferPool.Get().(*bytes.Buffer)
 	queryBuf.Reset()
 	defer r.queryBufferPool.Put(queryBuf)
-	return StorePrometheusMetricsWithBuffer(queryBuf, ctx, r.queryer, tableName, metrics)
+	return StorePrometheusMetricsWithBuffer(queryBuf, ctx, r.queryer, r.dialect, tableName, granularity, metrics)
 }
 
 func (r *prometheusMetricRepo) GetPrometheusMetrics(tableName string, start, end time.Time) ([]*PrometheusMetric, error) {
-	return GetPrometheusMetrics(r.queryer, tableName, start, end)
+	return GetPrometheusMetrics(r.queryer, r.dialect, tableName, start, end)
 }
 
 func (r *prometheusMetricRepo) GetLastTimestampForTable(tableName string) (*time.Time, error) {
@@ -91,13 +143,16 @@ func (r *prometheusMetricRepo) GetLastTimestampForTable(tableName string) (*time
 				ORDER BY "timestamp" DESC
 				LIMIT 1`, tableName)
 
-	results, err := presto.ExecuteSelect(r.queryer, getLastTimestampQuery)
+	results, err := presto.ExecuteSelect(context.Background(), r.queryer, getLastTimestampQuery)
 	if err != nil {
 		return nil, fmt.Errorf("error getting last timestamp for table %s, maybe table doesn't exist yet? %v", tableName, err)
 	}
 
 	if len(results) != 0 {
-		ts := results[0]["timestamp"].(time.Time)
+		ts, ok := results[0]["timestamp"].(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("invalid timestamp %v, expected a timestamp, got %T", results[0]["timestamp"], results[0]["timestamp"])
+		}
 		return &ts, nil
 	}
 	return nil, nil
@@ -109,11 +164,14 @@ type PrometheusMetric struct {
 	Amount    float64           `json:"amount"`
 	StepSize  time.Duration     `json:"stepSize"`
 	Timestamp time.Time         `json:"timestamp"`
+	// ClusterID identifies the cluster the metric was collected from.
+	ClusterID string `json:"clusterID,omitempty"`
 }
 
 // storePrometheusMetricsWithBuffer handles storing Prometheus metrics into the
-// specified Presto table.
-func StorePrometheusMetricsWithBuffer(queryBuf *bytes.Buffer, ctx context.Context, queryer db.Queryer, tableName string, metrics []*PrometheusMetric) error {
+// specified table, generating the values inserted using the dialect, and the
+// dt partition of each metric using granularity.
+func StorePrometheusMetricsWithBuffer(queryBuf *bytes.Buffer, ctx context.Context, queryer db.Queryer, dialect SQLDialect, tableName string, granularity PartitionGranularity, metrics []*PrometheusMetric) error {
 	bufferCapacity := queryBuf.Cap()
 
 	insertStatementLength := len(presto.FormatInsertQuery(tableName, ""))
@@ -122,7 +180,7 @@ func StorePrometheusMetricsWithBuffer(queryBuf *bytes.Buffer, ctx context.Contex
 	queryCap := bufferCapacity - insertStatementLength
 
 	for _, metric := range metrics {
-		metricValue := generatePrometheusMetricSQLValues(metric)
+		metricValue := generatePrometheusMetricSQLValues(dialect, granularity, metric)
 
 		select {
 		case <-ctx.Done():
@@ -149,7 +207,7 @@ func StorePrometheusMetricsWithBuffer(queryBuf *bytes.Buffer, ctx context.Contex
 		// if writing the current metricValue to the buffer would exceed the
 		// bufferCapacity, perform the insert query, and reset the buffer
 		if newBufferSize > queryCap {
-			err := presto.InsertInto(queryer, tableName, queryBuf.String())
+			err := presto.InsertInto(ctx, queryer, tableName, queryBuf.String())
 			if err != nil {
 				return fmt.Errorf("failed to store metrics into presto: %v", err)
 			}
@@ -160,7 +218,7 @@ func StorePrometheusMetricsWithBuffer(queryBuf *bytes.Buffer, ctx context.Contex
 	}
 	// if the buffer has unwritten values, perform the final insert
 	if queryBuf.Len() != 0 {
-		err := presto.InsertInto(queryer, tableName, queryBuf.String())
+		err := presto.InsertInto(ctx, queryer, tableName, queryBuf.String())
 		if err != nil {
 			return fmt.Errorf("failed to store metrics into presto: %v", err)
 		}
@@ -169,76 +227,161 @@ func StorePrometheusMetricsWithBuffer(queryBuf *bytes.Buffer, ctx context.Contex
 }
 
 // generatePrometheusMetricSQLValues turns a PrometheusMetric into a SQL literal
-// suited for INSERT statements. To insert maps, we crete an array of keys and
-// values as recommended by Presto documentation.
+// suited for INSERT statements.
 //
 // The schema is as follows:
 // column "amount" type: "double"
 // column "timestamp" type: "timestamp"
 // column "timePrecision" type: "double"
 // column "labels" type: "map<string, string>"
+// column "cluster_id" type: "string"
 // the following columns are partition columns:
 // column "dt" type: "string"
-func generatePrometheusMetricSQLValues(metric *PrometheusMetric) string {
-	var keys []string
-	var vals []string
-	for k, v := range metric.Labels {
-		keys = append(keys, "'"+k+"'")
-		vals = append(vals, "'"+v+"'")
-	}
-	keyString := "ARRAY[" + strings.Join(keys, ",") + "]"
-	valString := "ARRAY[" + strings.Join(vals, ",") + "]"
-	dt := PrometheusMetricTimestampPartition(metric.Timestamp)
-	return fmt.Sprintf("(%f,timestamp '%s',%f,map(%s,%s),'%s')",
-		metric.Amount, metric.Timestamp.Format(presto.TimestampFormat), metric.StepSize.Seconds(), keyString, valString, dt,
+func generatePrometheusMetricSQLValues(dialect SQLDialect, granularity PartitionGranularity, metric *PrometheusMetric) string {
+	dt := granularity.Partition(metric.Timestamp)
+	return fmt.Sprintf("(%f,%s,%f,%s,%s,%s)",
+		metric.Amount, dialect.TimestampLiteral(metric.Timestamp), metric.StepSize.Seconds(), dialect.MapLiteral(metric.Labels), dialect.StringLiteral(metric.ClusterID), dialect.StringLiteral(dt),
 	)
 }
 
-const PrometheusMetricTimestampPartitionFormat = "2006-01-02"
+// PrometheusMetricKey returns a string uniquely identifying a metric by its
+// timestamp, labels and the cluster it was collected from.
+func PrometheusMetricKey(timestamp time.Time, labels map[string]string, clusterID string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var key strings.Builder
+	key.WriteString(timestamp.UTC().Format(time.RFC3339Nano))
+	key.WriteString("\x00" + clusterID)
+	for _, name := range names {
+		// label names and values can't contain NUL bytes, so they're used to
+		// separate them unambiguously.
+		key.WriteString("\x00" + name + "\x00" + labels[name])
+	}
+	return key.String()
+}
+
+// DeduplicatePrometheusMetrics returns the metrics whose keys aren't in
+// stored, also removing duplicates within metrics.
+func DeduplicatePrometheusMetrics(metrics []*PrometheusMetric, stored map[string]struct{}) []*PrometheusMetric {
+	seen := make(map[string]struct{}, len(metrics))
+	deduplicated := make([]*PrometheusMetric, 0, len(metrics))
+	for _, metric := range metrics {
+		key := PrometheusMetricKey(metric.Timestamp, metric.Labels, metric.ClusterID)
+		if _, exists := stored[key]; exists {
+			continue
+		}
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+		deduplicated = append(deduplicated, metric)
+	}
+	return deduplicated
+}
+
+// prometheusMetricsTimeRange returns the earliest and latest timestamps of
+// the metrics.
+func prometheusMetricsTimeRange(metrics []*PrometheusMetric) (start, end time.Time) {
+	for i, metric := range metrics {
+		if i == 0 || metric.Timestamp.Before(start) {
+			start = metric.Timestamp
+		}
+		if i == 0 || metric.Timestamp.After(end) {
+			end = metric.Timestamp
+		}
+	}
+	return start, end
+}
 
-func PrometheusMetricTimestampPartition(t time.Time) string {
-	return t.UTC().Format(PrometheusMetricTimestampPartitionFormat)
+// getStoredPrometheusMetricKeys returns the PrometheusMetricKey of each
+// metric stored in the table with a timestamp between start and end,
+// inclusive. Only the partitions which may contain the time range are
+// scanned. Metrics stored before the cluster_id column was added have an empty
+// cluster ID.
+func getStoredPrometheusMetricKeys(ctx context.Context, queryer db.Queryer, dialect SQLDialect, tableName string, start, end time.Time) (map[string]struct{}, error) {
+	query := fmt.Sprintf(`SELECT "timestamp", labels, cluster_id FROM %s WHERE dt >= %s AND dt <= %s AND "timestamp" >= %s AND "timestamp" <= %s`,
+		tableName,
+		dialect.StringLiteral(PrometheusMetricPartitionRangeStart(start)), dialect.StringLiteral(PrometheusMetricPartitionRangeEnd(end)),
+		dialect.TimestampLiteral(start), dialect.TimestampLiteral(end),
+	)
+	rows, err := presto.ExecuteSelect(ctx, queryer, query)
+	if err != nil {
+		return nil, err
+	}
+	return storedPrometheusMetricKeys(dialect, rows)
 }
 
-func GetPrometheusMetrics(queryer db.Queryer, tableName string, start, end time.Time) ([]*PrometheusMetric, error) {
-	whereClause := ""
+// storedPrometheusMetricKeys returns the PrometheusMetricKey of each row
+// selected by getStoredPrometheusMetricKeys.
+func storedPrometheusMetricKeys(dialect SQLDialect, rows []presto.Row) (map[string]struct{}, error) {
+	keys := make(map[string]struct{}, len(rows))
+	for _, row := range rows {
+		timestamp, ok := row["timestamp"].(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("invalid timestamp %v, expected a timestamp, got %T", row["timestamp"], row["timestamp"])
+		}
+		labels, err := dialect.ParseMap(row["labels"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid labels: %v", err)
+		}
+		clusterID, _ := row["cluster_id"].(string)
+		keys[PrometheusMetricKey(timestamp, labels, clusterID)] = struct{}{}
+	}
+	return keys, nil
+}
+
+// getPrometheusMetricsQuery returns a query selecting the metrics stored in
+// the table with a timestamp between start and end, inclusive, scanning only
+// the partitions which may contain them. A zero start or end leaves that end
+// of the time range unbounded.
+func getPrometheusMetricsQuery(dialect SQLDialect, tableName string, start, end time.Time) string {
+	var conditions []string
 	if !start.IsZero() {
-		whereClause += fmt.Sprintf(`WHERE "timestamp" >= timestamp '%s' `, start.Format(presto.TimestampFormat))
+		conditions = append(conditions,
+			fmt.Sprintf("dt >= %s", dialect.StringLiteral(PrometheusMetricPartitionRangeStart(start))),
+			fmt.Sprintf(`"timestamp" >= %s`, dialect.TimestampLiteral(start)),
+		)
 	}
 	if !end.IsZero() {
-		if !start.IsZero() {
-			whereClause += " AND "
-		} else {
-			whereClause += " WHERE "
-		}
-		whereClause += fmt.Sprintf(`"timestamp" <= timestamp '%s'`, end.Format(presto.TimestampFormat))
+		conditions = append(conditions,
+			fmt.Sprintf("dt <= %s", dialect.StringLiteral(PrometheusMetricPartitionRangeEnd(end))),
+			fmt.Sprintf(`"timestamp" <= %s`, dialect.TimestampLiteral(end)),
+		)
+	}
+	if len(conditions) == 0 {
+		return dialect.GetRowsQuery(tableName, promsumColumns)
 	}
+	return dialect.GetRowsWhereQuery(tableName, promsumColumns, strings.Join(conditions, " AND "))
+}
 
-	rows, err := presto.GetRows(queryer, tableName, promsumColumns)
+// GetPrometheusMetrics returns the metrics stored in the table with a
+// timestamp between start and end, inclusive.
+func GetPrometheusMetrics(queryer db.Queryer, dialect SQLDialect, tableName string, start, end time.Time) ([]*PrometheusMetric, error) {
+	rows, err := presto.ExecuteSelect(context.Background(), queryer, getPrometheusMetricsQuery(dialect, tableName, start, end))
 	if err != nil {
 		return nil, err
 	}
 
 	results := make([]*PrometheusMetric, len(rows))
 	for i, row := range rows {
-		rowLabels := row["labels"].(map[string]interface{})
 		rowAmount := row["amount"].(float64)
 		rowTimePrecision := row["timeprecision"].(float64)
 		rowTimestamp := row["timestamp"].(time.Time)
+		rowClusterID, _ := row["cluster_id"].(string)
 
-		labels := make(map[string]string)
-		for key, value := range rowLabels {
-			var ok bool
-			labels[key], ok = value.(string)
-			if !ok {
-				return nil, fmt.Errorf("invalid label %s, valueType: %T, value: %+v", key, value, value)
-			}
+		labels, err := dialect.ParseMap(row["labels"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid labels: %v", err)
 		}
 		metric := &PrometheusMetric{
 			Labels:    labels,
 			Amount:    rowAmount,
 			StepSize:  time.Duration(rowTimePrecision) * time.Second,
 			Timestamp: rowTimestamp,
+			ClusterID: rowClusterID,
 		}
 		results[i] = metric
 	}
@@ -0,0 +1,232 @@
+package prestostore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func TestDeduplicatePrometheusMetrics(t *testing.T) {
+	t1 := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+	metricA1 := &PrometheusMetric{Timestamp: t1, Labels: map[string]string{"pod": "a"}, Amount: 1}
+	metricB1 := &PrometheusMetric{Timestamp: t1, Labels: map[string]string{"pod": "b"}, Amount: 1}
+	metricA2 := &PrometheusMetric{Timestamp: t2, Labels: map[string]string{"pod": "a"}, Amount: 1}
+
+	tests := []struct {
+		name     string
+		metrics  []*PrometheusMetric
+		stored   []*PrometheusMetric
+		expected []*PrometheusMetric
+	}{
+		{
+			name:     "nothing stored",
+			metrics:  []*PrometheusMetric{metricA1, metricB1, metricA2},
+			expected: []*PrometheusMetric{metricA1, metricB1, metricA2},
+		},
+		{
+			name:     "re-importing a stored window stores nothing",
+			metrics:  []*PrometheusMetric{metricA1, metricB1},
+			stored:   []*PrometheusMetric{metricA1, metricB1},
+			expected: []*PrometheusMetric{},
+		},
+		{
+			name:     "overlapping window only stores new metrics",
+			metrics:  []*PrometheusMetric{metricA1, metricB1, metricA2},
+			stored:   []*PrometheusMetric{metricA1},
+			expected: []*PrometheusMetric{metricB1, metricA2},
+		},
+		{
+			name:     "duplicates within the metrics are removed",
+			metrics:  []*PrometheusMetric{metricA1, metricA1, {Timestamp: t1.In(time.FixedZone("test", 3600)), Labels: map[string]string{"pod": "a"}}},
+			expected: []*PrometheusMetric{metricA1},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stored := make(map[string]struct{})
+			for _, metric := range tt.stored {
+				stored[PrometheusMetricKey(metric.Timestamp, metric.Labels, metric.ClusterID)] = struct{}{}
+			}
+			assert.Equal(t, tt.expected, DeduplicatePrometheusMetrics(tt.metrics, stored))
+		})
+	}
+}
+
+func TestPrometheusMetricKey(t *testing.T) {
+	ts := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t,
+		PrometheusMetricKey(ts, map[string]string{"a": "1", "b": "2"}, ""),
+		PrometheusMetricKey(ts, map[string]string{"b": "2", "a": "1"}, ""),
+		"expected label order not to matter",
+	)
+	assert.NotEqual(t,
+		PrometheusMetricKey(ts, map[string]string{"a": "1b"}, ""),
+		PrometheusMetricKey(ts, map[string]string{"a": "1", "b": ""}, ""),
+		"expected label names and values to be separated unambiguously",
+	)
+	assert.NotEqual(t,
+		PrometheusMetricKey(ts, map[string]string{"a": "1"}, "cluster-a"),
+		PrometheusMetricKey(ts, map[string]string{"a": "1"}, "cluster-b"),
+		"expected metrics from different clusters to be distinct",
+	)
+}
+
+func TestGeneratePrometheusMetricSQLValues(t *testing.T) {
+	metric := &PrometheusMetric{
+		Labels:    map[string]string{"pod": "it's-a-pod"},
+		Amount:    1.5,
+		StepSize:  time.Minute,
+		Timestamp: time.Date(2019, time.January, 1, 12, 30, 0, 0, time.UTC),
+		ClusterID: "cluster-a",
+	}
+	tests := []struct {
+		dialect     SQLDialect
+		granularity PartitionGranularity
+		expected    string
+	}{
+		{
+			dialect:  PrestoSQLDialect,
+			expected: `(1.500000,timestamp '2019-01-01 12:30:00.000',60.000000,map(ARRAY['pod'],ARRAY['it''s-a-pod']),'cluster-a','2019-01-01')`,
+		},
+		{
+			dialect:     ClickHouseSQLDialect,
+			granularity: PartitionGranularityDaily,
+			expected:    `(1.500000,'2019-01-01 12:30:00',60.000000,[('pod','it\'s-a-pod')],'cluster-a','2019-01-01')`,
+		},
+		{
+			dialect:     PrestoSQLDialect,
+			granularity: PartitionGranularityHourly,
+			expected:    `(1.500000,timestamp '2019-01-01 12:30:00.000',60.000000,map(ARRAY['pod'],ARRAY['it''s-a-pod']),'cluster-a','2019-01-01T12')`,
+		},
+		{
+			dialect:     PrestoSQLDialect,
+			granularity: PartitionGranularityWeekly,
+			expected:    `(1.500000,timestamp '2019-01-01 12:30:00.000',60.000000,map(ARRAY['pod'],ARRAY['it''s-a-pod']),'cluster-a','2018-12-31')`,
+		},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, generatePrometheusMetricSQLValues(tt.dialect, tt.granularity, metric))
+	}
+}
+
+func TestGetPrometheusMetricsQuery(t *testing.T) {
+	start := time.Date(2019, time.January, 2, 3, 0, 0, 0, time.UTC)
+	end := time.Date(2019, time.January, 2, 4, 0, 0, 0, time.UTC)
+	columns := `"amount","timestamp","timePrecision","labels","cluster_id"`
+	orderBy := `"amount", "timestamp", "timePrecision", map_entries("labels"), "cluster_id" ASC`
+
+	// only the partitions which may contain the time range are scanned
+	assert.Equal(t,
+		`SELECT `+columns+` FROM datasource_pod_cpu WHERE dt >= '`+PrometheusMetricPartitionRangeStart(start)+`' AND "timestamp" >= timestamp '2019-01-02 03:00:00.000' AND dt <= '`+PrometheusMetricPartitionRangeEnd(end)+`' AND "timestamp" <= timestamp '2019-01-02 04:00:00.000' ORDER BY `+orderBy,
+		getPrometheusMetricsQuery(PrestoSQLDialect, "datasource_pod_cpu", start, end),
+	)
+	assert.Equal(t,
+		`SELECT `+columns+` FROM datasource_pod_cpu WHERE dt <= '`+PrometheusMetricPartitionRangeEnd(end)+`' AND "timestamp" <= timestamp '2019-01-02 04:00:00.000' ORDER BY `+orderBy,
+		getPrometheusMetricsQuery(PrestoSQLDialect, "datasource_pod_cpu", time.Time{}, end),
+	)
+	assert.Equal(t, PrestoSQLDialect.GetRowsQuery("datasource_pod_cpu", promsumColumns), getPrometheusMetricsQuery(PrestoSQLDialect, "datasource_pod_cpu", time.Time{}, time.Time{}))
+	assert.Contains(t, getPrometheusMetricsQuery(ClickHouseSQLDialect, "datasource_pod_cpu", start, end), "FROM `datasource_pod_cpu` WHERE dt >= ")
+}
+
+func TestStoredPrometheusMetricsTimeRange(t *testing.T) {
+	t1 := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+	t3 := t2.Add(time.Minute)
+	metrics := []*PrometheusMetric{{Timestamp: t2}, {Timestamp: t1}, {Timestamp: t3}}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		metrics       []*PrometheusMetric
+		expectedStart time.Time
+		expectedEnd   time.Time
+		expectedOK    bool
+	}{
+		{
+			name:       "no metrics",
+			ctx:        context.Background(),
+			expectedOK: false,
+		},
+		{
+			name:          "without a last stored timestamp all metrics are checked",
+			ctx:           context.Background(),
+			metrics:       metrics,
+			expectedStart: t1,
+			expectedEnd:   t3,
+			expectedOK:    true,
+		},
+		{
+			name:          "only metrics up to the last stored timestamp are checked",
+			ctx:           WithLastStoredTimestamp(context.Background(), t2),
+			metrics:       metrics,
+			expectedStart: t1,
+			expectedEnd:   t2,
+			expectedOK:    true,
+		},
+		{
+			name:          "last stored timestamp after the metrics",
+			ctx:           WithLastStoredTimestamp(context.Background(), t3.Add(time.Hour)),
+			metrics:       metrics,
+			expectedStart: t1,
+			expectedEnd:   t3,
+			expectedOK:    true,
+		},
+		{
+			name:          "last stored timestamp before the metrics",
+			ctx:           WithLastStoredTimestamp(context.Background(), t1.Add(-time.Minute)),
+			metrics:       metrics,
+			expectedStart: t1,
+			expectedEnd:   t3,
+			expectedOK:    false,
+		},
+		{
+			name:          "empty table",
+			ctx:           WithLastStoredTimestamp(context.Background(), time.Time{}),
+			metrics:       metrics,
+			expectedStart: t1,
+			expectedEnd:   t3,
+			expectedOK:    false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := storedPrometheusMetricsTimeRange(tt.ctx, tt.metrics)
+			assert.Equal(t, tt.expectedOK, ok)
+			if tt.expectedOK {
+				assert.Equal(t, tt.expectedStart, start)
+				assert.Equal(t, tt.expectedEnd, end)
+			}
+		})
+	}
+}
+
+func TestStoredPrometheusMetricKeys(t *testing.T) {
+	ts := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	keys, err := storedPrometheusMetricKeys(PrestoSQLDialect, []presto.Row{
+		{"timestamp": ts, "labels": map[string]interface{}{"pod": "a"}, "cluster_id": "cluster-a"},
+		{"timestamp": ts, "labels": map[string]interface{}{"pod": "b"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{
+		PrometheusMetricKey(ts, map[string]string{"pod": "a"}, "cluster-a"): {},
+		PrometheusMetricKey(ts, map[string]string{"pod": "b"}, ""):          {},
+	}, keys)
+
+	_, err = storedPrometheusMetricKeys(PrestoSQLDialect, []presto.Row{
+		{"timestamp": "2019-01-01 00:00:00.000", "labels": map[string]interface{}{"pod": "a"}},
+	})
+	assert.Error(t, err, "expected an error instead of a panic for an invalid timestamp")
+
+	_, err = storedPrometheusMetricKeys(PrestoSQLDialect, []presto.Row{
+		{"timestamp": ts, "labels": "pod=a"},
+	})
+	assert.Error(t, err)
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/operator-framework/operator-metering/pkg/tracing"
 )
 
 type PrometheusImportResults struct {
@@ -89,14 +91,21 @@ func ImportFromTimeRange(logger logrus.FieldLogger, clock clock.Clock, promConn
 		promLogger.Debugf("querying Prometheus using range %s to %s", timeRange.Start, timeRange.End)
 
 		queryStart := clock.Now()
-		pVal, err := promConn.QueryRange(ctx, cfg.PrometheusQuery, timeRange)
+		queryCtx, span := tracing.StartClientSpan(ctx, "prometheus.queryRange",
+			tracing.String("prometheus.query", cfg.PrometheusQuery),
+			tracing.String("prometheus.start", promQueryBegin.Format(time.RFC3339)),
+			tracing.String("prometheus.end", promQueryEnd.Format(time.RFC3339)),
+		)
+		pVal, err := promConn.QueryRange(queryCtx, cfg.PrometheusQuery, timeRange)
+		span.SetError(err)
+		span.End()
 		queryDuration := clock.Since(queryStart)
 		metricsCollectors.PrometheusQueryDurationHistogram.Observe(float64(queryDuration.Seconds()))
 		metricsCollectors.TotalPrometheusQueriesCounter.Inc()
 		if err != nil {
 			metricsCollectors.FailedImportsCounter.Inc()
 			metricsCollectors.FailedPrometheusQueriesCounter.Inc()
-			return importResults, fmt.Errorf("failed to perform Prometheus query: %v", err)
+			return importResults, &PrometheusQueryError{err: fmt.Errorf("failed to perform Prometheus query: %v", err)}
 		}
 
 		matrix, ok := pVal.(model.Matrix)
@@ -104,7 +113,7 @@ func ImportFromTimeRange(logger logrus.FieldLogger, clock clock.Clock, promConn
 			return importResults, fmt.Errorf("expected a matrix in response to query, got a %v", pVal.Type())
 		}
 
-		metrics := promMatrixToPrometheusMetrics(timeRange, matrix)
+		metrics := promMatrixToPrometheusMetrics(timeRange, matrix, cfg.ClusterID)
 		numMetrics := len(metrics)
 		metricsCollectors.MetricsScrapedCounter.Add(float64(numMetrics))
 
@@ -127,14 +136,20 @@ func ImportFromTimeRange(logger logrus.FieldLogger, clock clock.Clock, promConn
 
 			metricsCollectors.TotalPrometheusQueriesCounter.Inc()
 			prestoStoreBegin := clock.Now()
-			err := prometheusMetricsStorer.StorePrometheusMetrics(ctx, cfg.PrestoTableName, metrics)
+			storeCtx, span := tracing.StartSpan(ctx, "prometheus.storeMetrics",
+				tracing.String("db.table", cfg.PrestoTableName),
+				tracing.Int64("prometheus.metrics", int64(numMetrics)),
+			)
+			err := prometheusMetricsStorer.StorePrometheusMetrics(storeCtx, cfg.PrestoTableName, cfg.PartitionGranularity, metrics)
+			span.SetError(err)
+			span.End()
 			prestoStoreDuration := clock.Since(prestoStoreBegin)
 			metricsCollectors.PrestoStoreDurationHistogram.Observe(float64(prestoStoreDuration.Seconds()))
 			if err != nil {
 				metricsCollectors.FailedImportsCounter.Inc()
 				metricsCollectors.FailedPrestoStoresCounter.Inc()
-				return importResults, fmt.Errorf("failed to store Prometheus metrics into table %s for the range %v to %v: %v",
-					cfg.PrestoTableName, promQueryBegin, promQueryEnd, err)
+				return importResults, &PrestoStoreError{err: fmt.Errorf("failed to store Prometheus metrics into table %s for the range %v to %v: %v",
+					cfg.PrestoTableName, promQueryBegin, promQueryEnd, err)}
 			}
 			importResults.Metrics = metrics
 			logger.Debugf("stored %d metrics for time range %s to %s into Presto table %s (took %s)", numMetrics, promQueryBegin, promQueryEnd, cfg.PrestoTableName, prestoStoreDuration)
@@ -155,6 +170,26 @@ func ImportFromTimeRange(logger logrus.FieldLogger, clock clock.Clock, promConn
 	return importResults, nil
 }
 
+// PrometheusQueryError is returned by ImportFromTimeRange when querying
+// Prometheus fails.
+type PrometheusQueryError struct {
+	err error
+}
+
+func (e *PrometheusQueryError) Error() string {
+	return e.err.Error()
+}
+
+// PrestoStoreError is returned by ImportFromTimeRange when storing metrics
+// into Presto fails.
+type PrestoStoreError struct {
+	err error
+}
+
+func (e *PrestoStoreError) Error() string {
+	return e.err.Error()
+}
+
 func getTimeRangesChunked(beginTime, endTime time.Time, chunkSize, stepSize time.Duration, maxTimeRanges int64, allowIncompleteChunks bool) []prom.Range {
 	chunkStart := truncateToSecond(beginTime)
 	chunkEnd := truncateToSecond(chunkStart.Add(chunkSize))
@@ -1,26 +1,44 @@
 package prestostore
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/operator-framework/operator-metering/pkg/db"
 	"github.com/operator-framework/operator-metering/pkg/presto"
 )
 
 type ReportResultsGetter interface {
-	GetReportResults(tableName string, columns []presto.Column) ([]presto.Row, error)
+	GetReportResults(ctx context.Context, tableName string, columns []presto.Column) ([]presto.Row, error)
 }
 
 type ReportResultsStorer interface {
-	StoreReportResults(tableName, query string) error
+	StoreReportResults(ctx context.Context, tableName, query string) error
 }
 
 type ReportsResultsDeleter interface {
-	DeleteReportResults(tableName string) error
+	DeleteReportResults(ctx context.Context, tableName string) error
+}
+
+type ReportResultsCounter interface {
+	CountReportResults(ctx context.Context, tableName string) (int64, error)
+}
+
+type ReportResultsPreviewer interface {
+	PreviewReportResults(ctx context.Context, query string, limit int) ([]presto.Column, []presto.Row, error)
+}
+
+type ReportResultsExplainer interface {
+	ExplainReportResults(ctx context.Context, query string, analyze bool) (string, error)
 }
 
 type ReportResultsRepo interface {
 	ReportResultsGetter
 	ReportResultsStorer
 	ReportsResultsDeleter
+	ReportResultsPreviewer
+	ReportResultsCounter
+	ReportResultsExplainer
 }
 
 type reportResultsRepo struct {
@@ -31,14 +49,47 @@ func NewReportResultsRepo(queryer db.Queryer) *reportResultsRepo {
 	return &reportResultsRepo{queryer: queryer}
 }
 
-func (r *reportResultsRepo) GetReportResults(tableName string, columns []presto.Column) ([]presto.Row, error) {
-	return presto.GetRows(r.queryer, tableName, columns)
+func (r *reportResultsRepo) GetReportResults(ctx context.Context, tableName string, columns []presto.Column) ([]presto.Row, error) {
+	return presto.GetRows(ctx, r.queryer, tableName, columns)
+}
+
+func (r *reportResultsRepo) StoreReportResults(ctx context.Context, tableName, query string) error {
+	return presto.InsertInto(ctx, r.queryer, tableName, query)
+}
+
+func (r *reportResultsRepo) DeleteReportResults(ctx context.Context, tableName string) error {
+	return presto.DeleteFrom(ctx, r.queryer, tableName)
+}
+
+// PreviewReportResults executes query returning at most limit rows, along
+// with the name and type of each column in the results.
+func (r *reportResultsRepo) PreviewReportResults(ctx context.Context, query string, limit int) ([]presto.Column, []presto.Row, error) {
+	return presto.ExecuteSelectWithColumns(ctx, r.queryer, fmt.Sprintf("SELECT * FROM (%s) LIMIT %d", query, limit))
+}
+
+// ExplainReportResults returns the plan for executing query without storing
+// it's results. If analyze is true, the query is executed to measure it's
+// cost.
+func (r *reportResultsRepo) ExplainReportResults(ctx context.Context, query string, analyze bool) (string, error) {
+	return presto.Explain(ctx, r.queryer, query, analyze)
 }
 
-func (r *reportResultsRepo) StoreReportResults(tableName, query string) error {
-	return presto.InsertInto(r.queryer, tableName, query)
+// CountReportResults returns the number of rows in the table.
+func (r *reportResultsRepo) CountReportResults(ctx context.Context, tableName string) (int64, error) {
+	return countRows(ctx, r.queryer, fmt.Sprintf("SELECT count(*) FROM %s", tableName))
 }
 
-func (r *reportResultsRepo) DeleteReportResults(tableName string) error {
-	return presto.DeleteFrom(r.queryer, tableName)
+func countRows(ctx context.Context, queryer db.Queryer, query string) (int64, error) {
+	rows, err := queryer.QueryContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var count int64
+	if rows.Next() {
+		if err := rows.Scan(&count); err != nil {
+			return 0, err
+		}
+	}
+	return count, rows.Err()
 }
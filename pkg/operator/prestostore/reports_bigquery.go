@@ -0,0 +1,51 @@
+package prestostore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/operator-metering/pkg/db"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// bigQueryReportResultsRepo implements ReportResultsRepo using BigQuery's
+// standard SQL dialect, which quotes identifiers using backticks, and
+// requires DELETE statements to have a WHERE clause.
+type bigQueryReportResultsRepo struct {
+	reportResultsRepo
+}
+
+func NewBigQueryReportResultsRepo(queryer db.Queryer) *bigQueryReportResultsRepo {
+	return &bigQueryReportResultsRepo{reportResultsRepo{queryer: queryer}}
+}
+
+func (r *bigQueryReportResultsRepo) GetReportResults(ctx context.Context, tableName string, columns []presto.Column) ([]presto.Row, error) {
+	var columnNames, orderBy []string
+	for _, col := range columns {
+		quoted := "`" + col.Name + "`"
+		columnNames = append(columnNames, quoted)
+		// arrays, which maps are stored as, can't be ordered by
+		colType := strings.ToLower(col.Type)
+		if !strings.Contains(colType, "map") && !strings.Contains(colType, "array") {
+			orderBy = append(orderBy, quoted)
+		}
+	}
+	query := fmt.Sprintf("SELECT %s FROM `%s`", strings.Join(columnNames, ","), tableName)
+	if len(orderBy) != 0 {
+		query += fmt.Sprintf(" ORDER BY %s ASC", strings.Join(orderBy, ", "))
+	}
+	return presto.ExecuteSelect(ctx, r.queryer, query)
+}
+
+func (r *bigQueryReportResultsRepo) DeleteReportResults(ctx context.Context, tableName string) error {
+	rows, err := r.queryer.QueryContext(ctx, fmt.Sprintf("DELETE FROM `%s` WHERE true", tableName))
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+func (r *bigQueryReportResultsRepo) CountReportResults(ctx context.Context, tableName string) (int64, error) {
+	return countRows(ctx, r.queryer, fmt.Sprintf("SELECT count(*) FROM `%s`", tableName))
+}
@@ -0,0 +1,30 @@
+package prestostore
+
+import (
+	"context"
+
+	"github.com/operator-framework/operator-metering/pkg/db"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// clickHouseReportResultsRepo implements ReportResultsRepo using ClickHouse,
+// which doesn't support ordering by map columns, or DELETE statements.
+type clickHouseReportResultsRepo struct {
+	reportResultsRepo
+}
+
+func NewClickHouseReportResultsRepo(queryer db.Queryer) *clickHouseReportResultsRepo {
+	return &clickHouseReportResultsRepo{reportResultsRepo{queryer: queryer}}
+}
+
+func (r *clickHouseReportResultsRepo) GetReportResults(ctx context.Context, tableName string, columns []presto.Column) ([]presto.Row, error) {
+	return presto.ExecuteSelect(ctx, r.queryer, ClickHouseSQLDialect.GetRowsQuery(tableName, columns))
+}
+
+func (r *clickHouseReportResultsRepo) DeleteReportResults(ctx context.Context, tableName string) error {
+	rows, err := r.queryer.QueryContext(ctx, ClickHouseSQLDialect.DeleteAllQuery(tableName))
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
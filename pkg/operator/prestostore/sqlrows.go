@@ -0,0 +1,136 @@
+package prestostore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/operator-framework/operator-metering/pkg/db"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// SQLRowsRepo stores rows of values from external sources, such as SQL
+// databases and Kafka topics. Each row must contain a value for each column,
+// in the same order.
+type SQLRowsRepo interface {
+	// StoreRows inserts the rows into the table.
+	StoreRows(ctx context.Context, tableName string, columns []presto.Column, rows [][]interface{}) error
+	// ReplaceRows deletes every row in the table and inserts the rows.
+	ReplaceRows(ctx context.Context, tableName string, columns []presto.Column, rows [][]interface{}) error
+}
+
+type sqlRowsRepo struct {
+	queryer         db.Queryer
+	dialect         SQLDialect
+	queryBufferPool *sync.Pool
+}
+
+func NewSQLRowsRepo(queryer db.Queryer, dialect SQLDialect, queryBufferPool *sync.Pool) *sqlRowsRepo {
+	if queryBufferPool == nil {
+		queryBufferPool = &defaultQueryBufferPool
+	}
+	return &sqlRowsRepo{
+		queryer:         queryer,
+		dialect:         dialect,
+		queryBufferPool: queryBufferPool,
+	}
+}
+
+func (r *sqlRowsRepo) ReplaceRows(ctx context.Context, tableName string, columns []presto.Column, rows [][]interface{}) error {
+	_, err := r.queryer.QueryContext(ctx, r.dialect.DeleteAllQuery(tableName))
+	if err != nil {
+		return fmt.Errorf("failed to delete existing rows from table %s: %v", tableName, err)
+	}
+	return r.StoreRows(ctx, tableName, columns, rows)
+}
+
+func (r *sqlRowsRepo) StoreRows(ctx context.Context, tableName string, columns []presto.Column, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	queryBuf := r.queryBufferPool.Get().(*bytes.Buffer)
+	queryBuf.Reset()
+	defer r.queryBufferPool.Put(queryBuf)
+	return StoreSQLRowsWithBuffer(queryBuf, ctx, r.queryer, r.dialect, tableName, columns, rows)
+}
+
+// StoreSQLRowsWithBuffer inserts the rows into the table, splitting them
+// into multiple insert queries to keep each query within the capacity of the
+// buffer. The values inserted are generated using the dialect.
+func StoreSQLRowsWithBuffer(queryBuf *bytes.Buffer, ctx context.Context, queryer db.Queryer, dialect SQLDialect, tableName string, columns []presto.Column, rows [][]interface{}) error {
+	// calculate the queryCap with the "INSERT INTO $table_name VALUES"
+	// portion accounted for
+	queryCap := queryBuf.Cap() - len(presto.FormatInsertQuery(tableName, "VALUES "))
+
+	for _, row := range rows {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// continue processing if context isn't cancelled.
+		}
+
+		rowValue, err := generateSQLRowValues(dialect, columns, row)
+		if err != nil {
+			return err
+		}
+
+		// if adding this row would exceed the capacity, insert the rows
+		// buffered so far first.
+		if queryBuf.Len() != 0 && queryBuf.Len()+len(rowValue)+1 > queryCap {
+			err := presto.InsertInto(ctx, queryer, tableName, queryBuf.String())
+			if err != nil {
+				return fmt.Errorf("failed to store rows into presto: %v", err)
+			}
+			queryBuf.Reset()
+		}
+
+		if queryBuf.Len() == 0 {
+			queryBuf.WriteString("VALUES ")
+		} else {
+			queryBuf.WriteString(",")
+		}
+		queryBuf.WriteString(rowValue)
+	}
+	// if the buffer has unwritten values, perform the final insert
+	if queryBuf.Len() != 0 {
+		err := presto.InsertInto(ctx, queryer, tableName, queryBuf.String())
+		if err != nil {
+			return fmt.Errorf("failed to store rows into presto: %v", err)
+		}
+	}
+	return nil
+}
+
+// generateSQLRowValues turns a row into a SQL literal suited for INSERT
+// statements. Values are cast from strings to the type of their column, so
+// any value the database can cast to the column's type can be inserted.
+func generateSQLRowValues(dialect SQLDialect, columns []presto.Column, row []interface{}) (string, error) {
+	if len(row) != len(columns) {
+		return "", fmt.Errorf("row has %d values, expected %d columns", len(row), len(columns))
+	}
+	values := make([]string, len(row))
+	for i, value := range row {
+		if value == nil {
+			values[i] = "NULL"
+			continue
+		}
+		var s string
+		switch v := value.(type) {
+		case []byte:
+			s = string(v)
+		case string:
+			s = v
+		case time.Time:
+			s = dialect.FormatTimestamp(v)
+		default:
+			s = fmt.Sprintf("%v", v)
+		}
+		values[i] = dialect.CastLiteral(dialect.StringLiteral(s), columns[i].Type)
+	}
+	return "(" + strings.Join(values, ",") + ")", nil
+}
@@ -0,0 +1,49 @@
+package prestostore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func TestGenerateSQLRowValues(t *testing.T) {
+	columns := []presto.Column{
+		{Name: "name", Type: "varchar"},
+		{Name: "cost", Type: "decimal(10,2)"},
+		{Name: "count", Type: "bigint"},
+		{Name: "updated", Type: "timestamp"},
+	}
+
+	tests := []struct {
+		name     string
+		row      []interface{}
+		expected string
+	}{
+		{
+			name:     "values are cast to their column type",
+			row:      []interface{}{"m4.large", []byte("0.10"), int64(2), time.Date(2019, time.January, 1, 12, 30, 0, 0, time.UTC)},
+			expected: `('m4.large',CAST('0.10' AS decimal(10,2)),CAST('2' AS bigint),CAST('2019-01-01 12:30:00.000' AS timestamp))`,
+		},
+		{
+			name:     "quotes are escaped",
+			row:      []interface{}{"it's", "1", "1", nil},
+			expected: `('it''s',CAST('1' AS decimal(10,2)),CAST('1' AS bigint),NULL)`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := generateSQLRowValues(PrestoSQLDialect, columns, tt.row)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, values)
+		})
+	}
+
+	_, err := generateSQLRowValues(PrestoSQLDialect, columns, []interface{}{"too few"})
+	assert.Error(t, err)
+}
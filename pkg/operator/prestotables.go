@@ -1,12 +1,14 @@
 package operator
 
 import (
+	"fmt"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
 
 	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
@@ -21,11 +23,11 @@ const (
 )
 
 func dataSourceNameToPrestoTableName(name string) string {
-	return strings.Replace(reportingutil.DataSourceTableName(name), "_", "-", -1)
+	return strings.Replace(reportingutil.DataSourceTableName("", name), "_", "-", -1)
 }
 
 func (op *Reporting) runPrestoTableWorker(stopCh <-chan struct{}) {
-	logger := op.logger.WithField("component", "prestoTableWorker")
+	logger := op.logger.WithField(logFieldController, "prestotable")
 	logger.Infof("PrestoTable worker started")
 
 	for op.processPrestoTable(logger) {
@@ -43,9 +45,11 @@ func (op *Reporting) processPrestoTable(logger log.FieldLogger) bool {
 
 	logger = logger.WithFields(newLogIdentifier(op.rand))
 	if key, ok := op.getKeyFromQueueObj(logger, "PrestoTable", obj, op.prestoTableQueue); ok {
+		logger = newResourceLogger(logger, "PrestoTable", key)
+		syncStart := op.clock.Now()
 		err := op.syncPrestoTable(logger, key)
-		const maxRequeues = 10
-		op.handleErr(logger, err, "PrestoTable", key, op.prestoTableQueue, maxRequeues)
+		observeSync("PrestoTable", op.clock.Since(syncStart), err)
+		op.handleErr(logger, err, "PrestoTable", key, op.prestoTableQueue, op.cfg.MaxRetriesConfig.PrestoTable)
 	}
 	return true
 }
@@ -57,8 +61,6 @@ func (op *Reporting) syncPrestoTable(logger log.FieldLogger, key string) error {
 		return nil
 	}
 
-	logger = logger.WithField("PrestoTable", name)
-
 	prestoTableLister := op.prestoTableLister
 	prestoTable, err := prestoTableLister.PrestoTables(namespace).Get(name)
 	if err != nil {
@@ -140,9 +142,12 @@ func (op *Reporting) createPrestoTableCR(obj metav1.Object, gvk schema.GroupVers
 			Properties: cbTypes.TableProperties(hive.TableProperties{
 				Location:           properties.Location,
 				FileFormat:         properties.FileFormat,
+				InputFormat:        properties.InputFormat,
+				OutputFormat:       properties.OutputFormat,
 				SerdeFormat:        properties.SerdeFormat,
 				SerdeRowProperties: properties.SerdeRowProperties,
 				External:           properties.External,
+				TableProperties:    properties.TableProperties,
 			}),
 		},
 	}
@@ -157,7 +162,7 @@ func (op *Reporting) createPrestoTableCR(obj metav1.Object, gvk schema.GroupVers
 func (op *Reporting) addPrestoTableFinalizer(prestoTable *cbTypes.PrestoTable) (*cbTypes.PrestoTable, error) {
 	prestoTable.Finalizers = append(prestoTable.Finalizers, prestoTableFinalizer)
 	newPrestoTable, err := op.meteringClient.MeteringV1alpha1().PrestoTables(prestoTable.Namespace).Update(prestoTable)
-	logger := op.logger.WithField("PrestoTable", prestoTable.Name)
+	logger := newObjectLogger(op.logger, "PrestoTable", prestoTable)
 	if err != nil {
 		logger.WithError(err).Errorf("error adding %s finalizer to PrestoTable: %s/%s", prestoTableFinalizer, prestoTable.Namespace, prestoTable.Name)
 		return nil, err
@@ -172,7 +177,7 @@ func (op *Reporting) removePrestoTableFinalizer(prestoTable *cbTypes.PrestoTable
 	}
 	prestoTable.Finalizers = slice.RemoveString(prestoTable.Finalizers, prestoTableFinalizer, nil)
 	newPrestoTable, err := op.meteringClient.MeteringV1alpha1().PrestoTables(prestoTable.Namespace).Update(prestoTable)
-	logger := op.logger.WithField("prestoTable", prestoTable.Name)
+	logger := newObjectLogger(op.logger, "PrestoTable", prestoTable)
 	if err != nil {
 		logger.WithError(err).Errorf("error removing %s finalizer from PrestoTable: %s/%s", prestoTableFinalizer, prestoTable.Namespace, prestoTable.Name)
 		return nil, err
@@ -187,9 +192,9 @@ func prestoTableNeedsFinalizer(prestoTable *cbTypes.PrestoTable) bool {
 
 func (op *Reporting) dropPrestoTable(prestoTable *cbTypes.PrestoTable) error {
 	tableName := prestoTable.Status.Parameters.Name
-	logger := op.logger.WithFields(log.Fields{"PrestoTable": prestoTable.Name, "tableName": tableName})
+	logger := newObjectLogger(op.logger, "PrestoTable", prestoTable).WithField("tableName", tableName)
 	logger.Infof("dropping presto table %s", tableName)
-	err := op.tableManager.DropTable(tableName, true)
+	err := op.tableManager.DropTable(op.workCtx, tableName, true)
 	if err != nil {
 		logger.WithError(err).Error("unable to drop presto table")
 		return err
@@ -197,3 +202,35 @@ func (op *Reporting) dropPrestoTable(prestoTable *cbTypes.PrestoTable) error {
 	logger.Infof("successfully deleted table %s", tableName)
 	return nil
 }
+
+// orphanPrestoTable removes the ownerReference pointing at ownerUID from the
+// PrestoTable, preventing it, and it's table, from being garbage collected
+// when the owner is deleted.
+func (op *Reporting) orphanPrestoTable(logger log.FieldLogger, namespace, name string, ownerUID types.UID) error {
+	prestoTable, err := op.prestoTableLister.PrestoTables(namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	var ownerRefs []metav1.OwnerReference
+	for _, ref := range prestoTable.OwnerReferences {
+		if ref.UID != ownerUID {
+			ownerRefs = append(ownerRefs, ref)
+		}
+	}
+	if len(ownerRefs) == len(prestoTable.OwnerReferences) {
+		return nil
+	}
+
+	pt := prestoTable.DeepCopy()
+	pt.OwnerReferences = ownerRefs
+	_, err = op.meteringClient.MeteringV1alpha1().PrestoTables(pt.Namespace).Update(pt)
+	if err != nil {
+		return fmt.Errorf("unable to remove ownerReference from PrestoTable %s: %v", pt.Name, err)
+	}
+	logger.Infof("orphaned PrestoTable %s to retain table %s", pt.Name, pt.Status.Parameters.Name)
+	return nil
+}
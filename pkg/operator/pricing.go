@@ -0,0 +1,66 @@
+package operator
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+)
+
+// getPricingPolicy returns the spec of the named PricingPolicy, looking in
+// namespace first and falling back to the operator's namespace. If name is
+// empty, the default PricingPolicy of namespace, or of the operator's
+// namespace, is used. nil is returned if name is empty and there's no
+// default PricingPolicy.
+func (op *Reporting) getPricingPolicy(namespace, name string) (*cbTypes.PricingPolicySpec, error) {
+	namespaces := []string{namespace}
+	if namespace != op.cfg.Namespace {
+		namespaces = append(namespaces, op.cfg.Namespace)
+	}
+
+	if name != "" {
+		for _, ns := range namespaces {
+			pricingPolicy, err := op.pricingPolicyLister.PricingPolicies(ns).Get(name)
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			return validatedPricingPolicy(pricingPolicy)
+		}
+		return nil, fmt.Errorf("PricingPolicy %s does not exist", name)
+	}
+
+	for _, ns := range namespaces {
+		pricingPolicies, err := op.pricingPolicyLister.PricingPolicies(ns).List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		var defaultPricingPolicies []*cbTypes.PricingPolicy
+		for _, pricingPolicy := range pricingPolicies {
+			if pricingPolicy.Annotations[cbTypes.IsDefaultPricingPolicyAnnotation] == "true" {
+				defaultPricingPolicies = append(defaultPricingPolicies, pricingPolicy)
+			}
+		}
+		switch len(defaultPricingPolicies) {
+		case 0:
+			continue
+		case 1:
+			return validatedPricingPolicy(defaultPricingPolicies[0])
+		default:
+			return nil, fmt.Errorf("%d PricingPolicies with annotation %s were found in namespace %s", len(defaultPricingPolicies), cbTypes.IsDefaultPricingPolicyAnnotation, ns)
+		}
+	}
+	return nil, nil
+}
+
+func validatedPricingPolicy(pricingPolicy *cbTypes.PricingPolicy) (*cbTypes.PricingPolicySpec, error) {
+	if err := reporting.ValidatePricingPolicy(&pricingPolicy.Spec); err != nil {
+		return nil, fmt.Errorf("invalid PricingPolicy %s: %v", pricingPolicy.Name, err)
+	}
+	return &pricingPolicy.Spec, nil
+}
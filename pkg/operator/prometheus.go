@@ -0,0 +1,133 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	prom "github.com/prometheus/client_golang/api/prometheus/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+type prometheusConn struct {
+	bearerToken string
+	api         prom.API
+}
+
+// getPrometheusConnForDataSource returns the connection to the Prometheus
+// instance the ReportDataSource is configured to use. Connections are shared
+// by all ReportDataSources using the same URL and credentials.
+func (op *Reporting) getPrometheusConnForDataSource(dataSource *cbTypes.ReportDataSource) (prom.API, error) {
+	promConfig := dataSource.Spec.Promsum.PrometheusConfig
+	if promConfig == nil || (promConfig.URL == "" && promConfig.BearerTokenSecret == nil) {
+		return op.promConn, nil
+	}
+
+	url := promConfig.URL
+	if url == "" {
+		url = op.cfg.PrometheusConfig.Address
+	}
+	key := url
+	var bearerToken string
+	if promConfig.BearerTokenSecret != nil {
+		secret, err := op.getPrometheusCredentialsSecret(dataSource.Namespace, promConfig.BearerTokenSecret.Name)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get Prometheus bearer token Secret %s for ReportDataSource %s: %v", promConfig.BearerTokenSecret.Name, dataSource.Name, err)
+		}
+		token, ok := secret.Data[promConfig.BearerTokenSecret.Key]
+		if !ok {
+			return nil, fmt.Errorf("Prometheus bearer token Secret %s for ReportDataSource %s has no key %s", secret.Name, dataSource.Name, promConfig.BearerTokenSecret.Key)
+		}
+		bearerToken = string(token)
+		key = fmt.Sprintf("%s|%s/%s/%s", url, dataSource.Namespace, promConfig.BearerTokenSecret.Name, promConfig.BearerTokenSecret.Key)
+	}
+
+	op.prometheusConnsMu.Lock()
+	defer op.prometheusConnsMu.Unlock()
+	// if the token has been rotated, replace the existing connection
+	if conn, exists := op.prometheusConns[key]; exists && conn.bearerToken == bearerToken {
+		return conn.api, nil
+	}
+	api, err := op.newPrometheusConnFromURL(url, bearerToken)
+	if err != nil {
+		return nil, err
+	}
+	op.prometheusConns[key] = &prometheusConn{bearerToken: bearerToken, api: api}
+	return api, nil
+}
+
+// newPrometheusCredentialsSecretInformer returns an informer for the Secrets
+// in namespace with the PrometheusCredentialsSecretLabel, which are the only
+// Secrets ReportDataSources can authenticate to Prometheus with.
+func newPrometheusCredentialsSecretInformer(kubeClient corev1.CoreV1Interface, namespace string, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	labelSelector := cbTypes.PrometheusCredentialsSecretLabel + "=true"
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector = labelSelector
+			return kubeClient.Secrets(namespace).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector = labelSelector
+			return kubeClient.Secrets(namespace).Watch(options)
+		},
+	}
+	return cache.NewSharedIndexInformer(listWatch, &v1.Secret{}, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+}
+
+// getPrometheusCredentialsSecret returns the Secret name in namespace if it
+// has the PrometheusCredentialsSecretLabel. Other Secrets aren't used, since
+// anyone able to create a ReportDataSource could otherwise send any Secret in
+// their namespace to a URL of their choosing.
+func (op *Reporting) getPrometheusCredentialsSecret(namespace, name string) (*v1.Secret, error) {
+	obj, exists, err := op.prometheusCredentialsSecrets.GetByKey(namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists || obj.(*v1.Secret).Labels[cbTypes.PrometheusCredentialsSecretLabel] != "true" {
+		return nil, fmt.Errorf("Secret %s doesn't exist or doesn't have the label %s=true", name, cbTypes.PrometheusCredentialsSecretLabel)
+	}
+	return obj.(*v1.Secret), nil
+}
+
+// prometheusURLForDataSource returns the URL of the Prometheus instance the
+// ReportDataSource imports metrics from.
+func (op *Reporting) prometheusURLForDataSource(dataSource *cbTypes.ReportDataSource) string {
+	if promConfig := dataSource.Spec.Promsum.PrometheusConfig; promConfig != nil && promConfig.URL != "" {
+		return promConfig.URL
+	}
+	return op.cfg.PrometheusConfig.Address
+}
+
+// acquirePrometheusImportSlot blocks until fewer than
+// PrometheusMaxConcurrentImportsPerEndpoint imports are running against the
+// Prometheus instance the ReportDataSource imports metrics from, or ctx is
+// cancelled. The returned function must be called once the import finishes.
+func (op *Reporting) acquirePrometheusImportSlot(ctx context.Context, dataSource *cbTypes.ReportDataSource) (release func(), err error) {
+	maxConcurrentImports := op.config().PrometheusMaxConcurrentImportsPerEndpoint
+	if maxConcurrentImports <= 0 {
+		return func() {}, nil
+	}
+
+	url := op.prometheusURLForDataSource(dataSource)
+	op.prometheusImportSemaphoresMu.Lock()
+	semaphore, exists := op.prometheusImportSemaphores[url]
+	if !exists {
+		semaphore = make(chan struct{}, maxConcurrentImports)
+		op.prometheusImportSemaphores[url] = semaphore
+	}
+	op.prometheusImportSemaphoresMu.Unlock()
+
+	select {
+	case semaphore <- struct{}{}:
+		return func() { <-semaphore }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,162 @@
+package operator
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"time"
+
+	"github.com/juju/ratelimit"
+	prom "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+var prometheusQueryRateLimitWaitHistogram = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: prometheusMetricNamespace,
+		Name:      "prometheus_query_rate_limit_wait_seconds",
+		Help:      "Time Prometheus queries spent waiting to be sent because of the configured QPS and in-flight limits.",
+		Buckets:   []float64{0.01, 0.1, 0.5, 1, 5, 10, 30, 60},
+	},
+	[]string{"host"},
+)
+
+func init() {
+	prometheus.MustRegister(prometheusQueryRateLimitWaitHistogram)
+}
+
+// prometheusRateLimiter limits the rate of queries sent to a Prometheus
+// instance, and the number of queries in flight at once.
+type prometheusRateLimiter struct {
+	clock clock.Clock
+	// host is the host of the Prometheus instance, which labels the wait
+	// histogram, since the URL may contain credentials.
+	host string
+	// bucket is nil if the rate of queries isn't limited.
+	bucket *ratelimit.Bucket
+	// tokenInterval is how often a token is added to bucket.
+	tokenInterval time.Duration
+	// inFlight is nil if the number of queries in flight isn't limited.
+	inFlight chan struct{}
+}
+
+// newPrometheusRateLimiter returns a prometheusRateLimiter for the
+// Prometheus at rawURL, allowing qps queries per second, with bursts of up
+// to qps rounded up, and maxInFlight queries at once. A value of 0 disables
+// each limit, and if both are disabled nil is returned.
+func newPrometheusRateLimiter(clock clock.Clock, rawURL string, qps float64, maxInFlight int) *prometheusRateLimiter {
+	if qps <= 0 && maxInFlight <= 0 {
+		return nil
+	}
+	limiter := &prometheusRateLimiter{clock: clock, host: prometheusURLHost(rawURL)}
+	if qps > 0 {
+		limiter.bucket = ratelimit.NewBucketWithRateAndClock(qps, int64(math.Ceil(qps)), clock)
+		limiter.tokenInterval = time.Duration(float64(time.Second) / qps)
+	}
+	if maxInFlight > 0 {
+		limiter.inFlight = make(chan struct{}, maxInFlight)
+	}
+	return limiter
+}
+
+// prometheusURLHost returns the host of the Prometheus at rawURL, without
+// any credentials in it's userinfo.
+func prometheusURLHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "unknown"
+	}
+	return u.Host
+}
+
+// wait blocks until a query can be sent, returning a function which must be
+// called once the query finishes.
+func (l *prometheusRateLimiter) wait(ctx context.Context) (done func(), err error) {
+	start := l.clock.Now()
+	defer func() {
+		prometheusQueryRateLimitWaitHistogram.WithLabelValues(l.host).Observe(l.clock.Since(start).Seconds())
+	}()
+
+	if l.inFlight != nil {
+		select {
+		case l.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release := func() {
+		if l.inFlight != nil {
+			<-l.inFlight
+		}
+	}
+
+	if l.bucket != nil {
+		// only take a token once one is available, so queries which are
+		// cancelled while waiting don't use up the rate of those sent
+		for l.bucket.TakeAvailable(1) == 0 {
+			timer := l.clock.NewTimer(l.tokenInterval)
+			select {
+			case <-timer.C():
+				timer.Stop()
+			case <-ctx.Done():
+				timer.Stop()
+				release()
+				return nil, ctx.Err()
+			}
+		}
+	}
+	return release, nil
+}
+
+// rateLimitedPrometheusAPI is a Prometheus API client which waits for a
+// prometheusRateLimiter before each request.
+type rateLimitedPrometheusAPI struct {
+	api     prom.API
+	limiter *prometheusRateLimiter
+}
+
+func (r *rateLimitedPrometheusAPI) Query(ctx context.Context, query string, ts time.Time) (model.Value, error) {
+	done, err := r.limiter.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.api.Query(ctx, query, ts)
+}
+
+func (r *rateLimitedPrometheusAPI) QueryRange(ctx context.Context, query string, rng prom.Range) (model.Value, error) {
+	done, err := r.limiter.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.api.QueryRange(ctx, query, rng)
+}
+
+func (r *rateLimitedPrometheusAPI) LabelValues(ctx context.Context, label string) (model.LabelValues, error) {
+	done, err := r.limiter.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.api.LabelValues(ctx, label)
+}
+
+// rateLimitPrometheusAPI wraps the client for the Prometheus at url, so
+// that it's queries are limited by the configured QPS and in-flight limits.
+// Every client for the same url shares the same limits.
+func (op *Reporting) rateLimitPrometheusAPI(url string, api prom.API) prom.API {
+	op.prometheusRateLimitersMu.Lock()
+	defer op.prometheusRateLimitersMu.Unlock()
+	limiter, exists := op.prometheusRateLimiters[url]
+	if !exists {
+		limiter = newPrometheusRateLimiter(op.clock, url, op.cfg.PrometheusConfig.QueryQPS, op.cfg.PrometheusConfig.MaxInFlightQueries)
+		op.prometheusRateLimiters[url] = limiter
+	}
+	if limiter == nil {
+		return api
+	}
+	return &rateLimitedPrometheusAPI{api: api, limiter: limiter}
+}
@@ -0,0 +1,302 @@
+package operator
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	prom "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+// fakePrometheus records the Authorization header of each request it
+// receives.
+type fakePrometheus struct {
+	*httptest.Server
+	authorizations chan string
+}
+
+func newFakePrometheus() *fakePrometheus {
+	return startFakePrometheus(httptest.NewServer)
+}
+
+// newFakeTLSPrometheus returns a fakePrometheus serving HTTPS using a
+// certificate for 127.0.0.1 and example.com.
+func newFakeTLSPrometheus() *fakePrometheus {
+	return startFakePrometheus(httptest.NewTLSServer)
+}
+
+func startFakePrometheus(newServer func(http.Handler) *httptest.Server) *fakePrometheus {
+	f := &fakePrometheus{authorizations: make(chan string, 10)}
+	f.Server = newServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.authorizations <- r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	return f
+}
+
+// authorization returns the Authorization header sent by a query using api.
+func (f *fakePrometheus) authorization(t *testing.T, api prom.API) string {
+	_, err := api.Query(context.Background(), "up", time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	return <-f.authorizations
+}
+
+func newPrometheusCredentialsSecret(namespace, name, token string, labelled bool) *v1.Secret {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Data:       map[string][]byte{"token": []byte(token)},
+	}
+	if labelled {
+		secret.Labels = map[string]string{cbTypes.PrometheusCredentialsSecretLabel: "true"}
+	}
+	return secret
+}
+
+func newPromsumDataSource(namespace, name string, promConfig *cbTypes.PrometheusConnectionConfig) *cbTypes.ReportDataSource {
+	return &cbTypes.ReportDataSource{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: cbTypes.ReportDataSourceSpec{
+			Promsum: &cbTypes.PrometheusMetricsDataSource{Query: "pod-cpu", PrometheusConfig: promConfig},
+		},
+	}
+}
+
+func TestGetPrometheusConnForDataSource(t *testing.T) {
+	prometheus := newFakePrometheus()
+	defer prometheus.Close()
+	secrets := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, secrets.Add(newPrometheusCredentialsSecret("team-a", "prometheus", "team-a-token", true)))
+	require.NoError(t, secrets.Add(newPrometheusCredentialsSecret("team-b", "prometheus", "team-b-token", true)))
+	require.NoError(t, secrets.Add(newPrometheusCredentialsSecret("team-a", "other", "other-token", false)))
+	defaultConn := prom.NewAPI(nil)
+	op := &Reporting{
+		logger:                       testLogger,
+		cfg:                          Config{PrometheusConfig: PrometheusConfig{Address: "http://prometheus:9090"}},
+		kubeConfig:                   &rest.Config{},
+		promConn:                     defaultConn,
+		prometheusConns:              make(map[string]*prometheusConn),
+		prometheusRateLimiters:       make(map[string]*prometheusRateLimiter),
+		prometheusCredentialsSecrets: secrets,
+	}
+	secretConfig := func(name string) *cbTypes.PrometheusConnectionConfig {
+		return &cbTypes.PrometheusConnectionConfig{
+			URL:               prometheus.URL,
+			BearerTokenSecret: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: name}, Key: "token"},
+		}
+	}
+
+	// without an override, the operator's connection is used
+	conn, err := op.getPrometheusConnForDataSource(newPromsumDataSource("team-a", "default", nil))
+	require.NoError(t, err)
+	assert.Equal(t, defaultConn, conn)
+
+	// ReportDataSources overriding the URL share a connection
+	urlConn, err := op.getPrometheusConnForDataSource(newPromsumDataSource("team-a", "url", &cbTypes.PrometheusConnectionConfig{URL: prometheus.URL}))
+	require.NoError(t, err)
+	conn, err = op.getPrometheusConnForDataSource(newPromsumDataSource("team-b", "url", &cbTypes.PrometheusConnectionConfig{URL: prometheus.URL}))
+	require.NoError(t, err)
+	assert.True(t, urlConn == conn, "expected ReportDataSources with the same URL to share a connection")
+	assert.Equal(t, "", prometheus.authorization(t, urlConn))
+
+	// as do ReportDataSources using the same Secret, which is sent as a
+	// bearer token
+	teamAConn, err := op.getPrometheusConnForDataSource(newPromsumDataSource("team-a", "secret", secretConfig("prometheus")))
+	require.NoError(t, err)
+	conn, err = op.getPrometheusConnForDataSource(newPromsumDataSource("team-a", "other-secret", secretConfig("prometheus")))
+	require.NoError(t, err)
+	assert.True(t, teamAConn == conn, "expected ReportDataSources with the same Secret to share a connection")
+	assert.False(t, teamAConn == urlConn, "expected ReportDataSources with a Secret not to share a connection without it")
+	assert.Equal(t, "Bearer team-a-token", prometheus.authorization(t, teamAConn))
+	teamBConn, err := op.getPrometheusConnForDataSource(newPromsumDataSource("team-b", "secret", secretConfig("prometheus")))
+	require.NoError(t, err)
+	assert.False(t, teamAConn == teamBConn, "expected Secrets of the same name in different namespaces not to share a connection")
+	assert.Equal(t, "Bearer team-b-token", prometheus.authorization(t, teamBConn))
+
+	// rotating the token replaces the connection
+	require.NoError(t, secrets.Update(newPrometheusCredentialsSecret("team-a", "prometheus", "rotated-token", true)))
+	conn, err = op.getPrometheusConnForDataSource(newPromsumDataSource("team-a", "secret", secretConfig("prometheus")))
+	require.NoError(t, err)
+	assert.False(t, teamAConn == conn, "expected the connection to be replaced once the token is rotated")
+	assert.Equal(t, "Bearer rotated-token", prometheus.authorization(t, conn))
+
+	// Secrets without the label, and missing Secrets, can't be used
+	_, err = op.getPrometheusConnForDataSource(newPromsumDataSource("team-a", "unlabelled", secretConfig("other")))
+	assert.Error(t, err)
+	_, err = op.getPrometheusConnForDataSource(newPromsumDataSource("team-a", "missing", secretConfig("missing")))
+	assert.Error(t, err)
+	assert.Len(t, prometheus.authorizations, 0)
+}
+
+func TestPrometheusConfigValid(t *testing.T) {
+	tests := map[string]struct {
+		cfg         PrometheusConfig
+		expectedErr bool
+	}{
+		"no credentials":                       {cfg: PrometheusConfig{}},
+		"basic auth":                           {cfg: PrometheusConfig{BasicAuthUsername: "user", BasicAuthPassword: "pass"}},
+		"bearer token":                         {cfg: PrometheusConfig{BearerToken: "token"}},
+		"client certificate and key":           {cfg: PrometheusConfig{ClientCertFile: "tls.crt", ClientKeyFile: "tls.key"}},
+		"basic auth and bearer token":          {cfg: PrometheusConfig{BasicAuthUsername: "user", BearerToken: "token"}, expectedErr: true},
+		"basic auth and bearer token file":     {cfg: PrometheusConfig{BasicAuthUsername: "user", BearerTokenFile: "token"}, expectedErr: true},
+		"basic auth and service account token": {cfg: PrometheusConfig{BasicAuthUsername: "user", UseServiceAccountToken: true}, expectedErr: true},
+		"client certificate without key":       {cfg: PrometheusConfig{ClientCertFile: "tls.crt"}, expectedErr: true},
+		"client key without certificate":       {cfg: PrometheusConfig{ClientKeyFile: "tls.key"}, expectedErr: true},
+	}
+	for name, tt := range tests {
+		err := tt.cfg.Valid()
+		if tt.expectedErr {
+			assert.Error(t, err, name)
+		} else {
+			assert.NoError(t, err, name)
+		}
+	}
+}
+
+func TestPrometheusConfigBearerToken(t *testing.T) {
+	tokenFile, err := ioutil.TempFile("", "prometheus-token")
+	require.NoError(t, err)
+	defer os.Remove(tokenFile.Name())
+	tokenFile.WriteString("file-token\n")
+	tokenFile.Close()
+
+	tests := map[string]struct {
+		cfg           PrometheusConfig
+		expectedToken string
+		expectedErr   bool
+	}{
+		"no token": {cfg: PrometheusConfig{}},
+		"token":    {cfg: PrometheusConfig{BearerToken: "token"}, expectedToken: "token"},
+		"token file, without trailing whitespace":                    {cfg: PrometheusConfig{BearerTokenFile: tokenFile.Name()}, expectedToken: "file-token"},
+		"token takes precedence over the token file":                 {cfg: PrometheusConfig{BearerToken: "token", BearerTokenFile: tokenFile.Name()}, expectedToken: "token"},
+		"token file takes precedence over the service account token": {cfg: PrometheusConfig{BearerTokenFile: tokenFile.Name(), UseServiceAccountToken: true}, expectedToken: "file-token"},
+		"missing token file":                                         {cfg: PrometheusConfig{BearerTokenFile: tokenFile.Name() + "-missing"}, expectedErr: true},
+	}
+	for name, tt := range tests {
+		token, err := tt.cfg.bearerToken()
+		if tt.expectedErr {
+			assert.Error(t, err, name)
+			continue
+		}
+		assert.NoError(t, err, name)
+		assert.Equal(t, tt.expectedToken, token, name)
+	}
+}
+
+func TestPrometheusConfigRedacted(t *testing.T) {
+	cfg := PrometheusConfig{Address: "https://prometheus:9091", BearerToken: "token", BasicAuthUsername: "user", BasicAuthPassword: "pass"}
+	assert.Equal(t, PrometheusConfig{Address: "https://prometheus:9091", BearerToken: redactedValue, BasicAuthUsername: "user", BasicAuthPassword: redactedValue}, cfg.redacted())
+	assert.Equal(t, "token", cfg.BearerToken, "expected the config to be copied")
+	assert.Equal(t, PrometheusConfig{BearerTokenFile: "/token"}, PrometheusConfig{BearerTokenFile: "/token"}.redacted())
+}
+
+func TestNewPrometheusConnFromURL(t *testing.T) {
+	basicAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))
+	newOperator := func(promCfg PrometheusConfig) *Reporting {
+		return &Reporting{
+			logger:                 testLogger,
+			cfg:                    Config{PrometheusConfig: promCfg},
+			kubeConfig:             &rest.Config{BearerToken: "kube-token"},
+			prometheusRateLimiters: make(map[string]*prometheusRateLimiter),
+		}
+	}
+
+	prometheus := newFakePrometheus()
+	defer prometheus.Close()
+	for _, tt := range []struct {
+		name                  string
+		cfg                   PrometheusConfig
+		bearerToken           string
+		expectedAuthorization string
+	}{
+		{
+			name:                  "the kubeconfig's credentials are used by default",
+			expectedAuthorization: "Bearer kube-token",
+		},
+		{
+			name:                  "basic auth replaces the kubeconfig's bearer token",
+			cfg:                   PrometheusConfig{BasicAuthUsername: "user", BasicAuthPassword: "pass"},
+			expectedAuthorization: basicAuth,
+		},
+		{
+			name:                  "configured bearer token",
+			cfg:                   PrometheusConfig{BearerToken: "cfg-token"},
+			expectedAuthorization: "Bearer cfg-token",
+		},
+		{
+			name:                  "a ReportDataSource's bearer token takes precedence over the configured bearer token",
+			cfg:                   PrometheusConfig{BearerToken: "cfg-token"},
+			bearerToken:           "secret-token",
+			expectedAuthorization: "Bearer secret-token",
+		},
+		{
+			name:                  "a ReportDataSource's bearer token takes precedence over basic auth",
+			cfg:                   PrometheusConfig{BasicAuthUsername: "user", BasicAuthPassword: "pass"},
+			bearerToken:           "secret-token",
+			expectedAuthorization: "Bearer secret-token",
+		},
+	} {
+		conn, err := newOperator(tt.cfg).newPrometheusConnFromURL(prometheus.URL, tt.bearerToken)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.expectedAuthorization, prometheus.authorization(t, conn), tt.name)
+	}
+
+	tlsPrometheus := newFakeTLSPrometheus()
+	defer tlsPrometheus.Close()
+	caFile, err := ioutil.TempFile("", "prometheus-ca")
+	require.NoError(t, err)
+	defer os.Remove(caFile.Name())
+	caFile.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsPrometheus.Certificate().Raw}))
+	caFile.Close()
+	for _, tt := range []struct {
+		name        string
+		cfg         PrometheusConfig
+		expectedErr bool
+	}{
+		{
+			name:        "unknown CA",
+			expectedErr: true,
+		},
+		{
+			name: "configured CA",
+			cfg:  PrometheusConfig{CAFile: caFile.Name()},
+		},
+		{
+			name: "configured CA and server name",
+			cfg:  PrometheusConfig{CAFile: caFile.Name(), TLSServerName: "example.com"},
+		},
+		{
+			name:        "server name not in the certificate",
+			cfg:         PrometheusConfig{CAFile: caFile.Name(), TLSServerName: "prometheus.example.org"},
+			expectedErr: true,
+		},
+		{
+			name: "skipping TLS verification takes precedence over the CA and server name",
+			cfg:  PrometheusConfig{CAFile: caFile.Name(), TLSServerName: "prometheus.example.org", SkipTLSVerify: true},
+		},
+	} {
+		conn, err := newOperator(tt.cfg).newPrometheusConnFromURL(tlsPrometheus.URL, "")
+		require.NoError(t, err, tt.name)
+		_, err = conn.Query(context.Background(), "up", time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC))
+		if tt.expectedErr {
+			assert.Error(t, err, tt.name)
+			continue
+		}
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, "Bearer kube-token", <-tlsPrometheus.authorizations, tt.name)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"time"
 
-	prom "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
@@ -13,7 +12,7 @@ import (
 
 	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
 	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
-	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
+	"github.com/operator-framework/operator-metering/pkg/tracing"
 )
 
 const (
@@ -161,23 +160,28 @@ type prometheusImportResults struct {
 }
 
 func (op *Reporting) importPrometheusForTimeRange(ctx context.Context, start, end time.Time) ([]*prometheusImportResults, error) {
-	reportDataSources, err := op.meteringClient.MeteringV1alpha1().ReportDataSources(op.cfg.Namespace).List(metav1.ListOptions{})
+	reportDataSources, err := op.meteringClient.MeteringV1alpha1().ReportDataSources(op.cfg.watchNamespace()).List(metav1.ListOptions{})
 	if err != nil {
 		return nil, err
 	}
 
 	logger := op.logger.WithField("component", "importPrometheusForTimeRange")
-	const concurrency = 4
 	// create a channel to act as a semaphore to limit the number of
 	// imports happening in parallel
-	semaphore := make(chan struct{}, concurrency)
+	semaphore := make(chan struct{}, op.cfg.ReportDataSourceWorkers)
+
+	ctx, span := op.tracer.Start(ctx, "prometheus.importForTimeRange",
+		tracing.String("prometheus.start", start.Format(time.RFC3339)),
+		tracing.String("prometheus.end", end.Format(time.RFC3339)),
+	)
+	defer span.End()
 
 	resultsCh := make(chan *prometheusImportResults)
 	g, ctx := errgroup.WithContext(ctx)
 
 	for _, reportDataSource := range reportDataSources.Items {
 		reportDataSource := reportDataSource
-		if reportDataSource.Spec.Promsum == nil {
+		if reportDataSource.Spec.Promsum == nil || reportDataSource.Spec.Promsum.RemoteWrite != nil || !op.cfg.isWatchedNamespace(reportDataSource.Namespace) {
 			continue
 		}
 
@@ -188,10 +192,9 @@ func (op *Reporting) importPrometheusForTimeRange(ctx context.Context, start, en
 				return err
 			}
 
-			dataSourceLogger := logger.WithFields(logrus.Fields{
-				"queryName":        reportDataSource.Spec.Promsum.Query,
-				"reportDataSource": reportDataSource.Name,
-				"tableName":        reportingutil.DataSourceTableName(reportDataSource.Name),
+			dataSourceLogger := newObjectLogger(logger, "ReportDataSource", reportDataSource).WithFields(logrus.Fields{
+				"queryName": reportDataSource.Spec.Promsum.Query,
+				"tableName": op.dataSourceTableName(reportDataSource),
 			})
 			importCfg := op.newPromImporterCfg(reportDataSource, reportPromQuery)
 			// ignore any global ImportFrom configuration since this is an
@@ -209,17 +212,24 @@ func (op *Reporting) importPrometheusForTimeRange(ctx context.Context, start, en
 				<-semaphore
 			}()
 
-			var promConn prom.API
-			if (reportDataSource.Spec.Promsum.PrometheusConfig != nil) && (reportDataSource.Spec.Promsum.PrometheusConfig.URL != "") {
-				promConn, err = op.newPrometheusConnFromURL(reportDataSource.Spec.Promsum.PrometheusConfig.URL)
-				if err != nil {
-					return err
-				}
-			} else {
-				promConn = op.promConn
+			promConn, err := op.getPrometheusConnForDataSource(reportDataSource)
+			if err != nil {
+				return err
 			}
 
-			importResults, err := prestostore.ImportFromTimeRange(dataSourceLogger, op.clock, promConn, op.prometheusMetricsRepo, metricsCollectors, ctx, start, end, importCfg, true)
+			release, err := op.acquirePrometheusImportSlot(ctx, reportDataSource)
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			importCtx, span := tracing.StartSpan(ctx, "prometheus.import",
+				tracing.String("metering.key", reportDataSource.Namespace+"/"+reportDataSource.Name),
+				tracing.String("db.table", op.dataSourceTableName(reportDataSource)),
+			)
+			importResults, err := prestostore.ImportFromTimeRange(dataSourceLogger, op.clock, promConn, op.prometheusMetricsRepo, metricsCollectors, importCtx, start, end, importCfg, true)
+			span.SetError(err)
+			span.End()
 			if err != nil {
 				return fmt.Errorf("error importing Prometheus data for ReportDataSource %s: %v", reportDataSource.Name, err)
 			}
@@ -241,12 +251,14 @@ func (op *Reporting) importPrometheusForTimeRange(ctx context.Context, start, en
 		results = append(results, importResults)
 	}
 
-	return results, g.Wait()
+	err = g.Wait()
+	span.SetError(err)
+	return results, err
 }
 
 func (op *Reporting) getQueryIntervalForReportDataSource(reportDataSource *cbTypes.ReportDataSource) time.Duration {
 	queryConf := reportDataSource.Spec.Promsum.QueryConfig
-	queryInterval := op.cfg.PrometheusQueryConfig.QueryInterval.Duration
+	queryInterval := op.config().PrometheusQueryConfig.QueryInterval.Duration
 	if queryConf != nil {
 		if queryConf.QueryInterval != nil {
 			queryInterval = queryConf.QueryInterval.Duration
@@ -255,21 +267,29 @@ func (op *Reporting) getQueryIntervalForReportDataSource(reportDataSource *cbTyp
 	return queryInterval
 }
 
+func (op *Reporting) getStepSizeForReportDataSource(reportDataSource *cbTypes.ReportDataSource) time.Duration {
+	queryConf := reportDataSource.Spec.Promsum.QueryConfig
+	stepSize := op.config().PrometheusQueryConfig.StepSize.Duration
+	if queryConf != nil {
+		if queryConf.StepSize != nil {
+			stepSize = queryConf.StepSize.Duration
+		}
+	}
+	return stepSize
+}
+
 func (op *Reporting) newPromImporterCfg(reportDataSource *cbTypes.ReportDataSource, reportPromQuery *cbTypes.ReportPrometheusQuery) prestostore.Config {
-	dataSourceName := reportDataSource.Name
-	tableName := reportingutil.DataSourceTableName(dataSourceName)
+	tableName := op.dataSourceTableName(reportDataSource)
+	cfg := op.config()
 
-	chunkSize := op.cfg.PrometheusQueryConfig.ChunkSize.Duration
-	stepSize := op.cfg.PrometheusQueryConfig.StepSize.Duration
+	chunkSize := cfg.PrometheusQueryConfig.ChunkSize.Duration
+	stepSize := op.getStepSizeForReportDataSource(reportDataSource)
 
 	queryConf := reportDataSource.Spec.Promsum.QueryConfig
 	if queryConf != nil {
 		if queryConf.ChunkSize != nil {
 			chunkSize = queryConf.ChunkSize.Duration
 		}
-		if queryConf.StepSize != nil {
-			stepSize = queryConf.StepSize.Duration
-		}
 	}
 
 	// round to the nearest second for chunk/step sizes
@@ -287,7 +307,12 @@ func (op *Reporting) newPromImporterCfg(reportDataSource *cbTypes.ReportDataSour
 	// max amount of StorePrometheusMetrics calls (roughly equivalent to presto
 	// queries) we make in a single import. We set it to the number of queries
 	// it would take to chunk up our MaxQueryRangeDuration.
-	defaultMaxPromTimeRanges := int64(op.cfg.PrometheusDataSourceMaxQueryRangeDuration / chunkSize)
+	defaultMaxPromTimeRanges := int64(cfg.PrometheusDataSourceMaxQueryRangeDuration / chunkSize)
+
+	importFromTime := op.cfg.PrometheusDataSourceGlobalImportFromTime
+	if backfillStart := backfillStartTime(reportDataSource); backfillStart != nil {
+		importFromTime = backfillStart
+	}
 
 	return prestostore.Config{
 		PrometheusQuery:           reportPromQuery.Spec.Query,
@@ -295,33 +320,87 @@ func (op *Reporting) newPromImporterCfg(reportDataSource *cbTypes.ReportDataSour
 		ChunkSize:                 chunkSize,
 		StepSize:                  stepSize,
 		MaxTimeRanges:             defaultMaxPromTimeRanges,
-		MaxQueryRangeDuration:     op.cfg.PrometheusDataSourceMaxQueryRangeDuration,
-		MaxBackfillImportDuration: op.cfg.PrometheusDataSourceMaxBackfillImportDuration,
-		ImportFromTime:            op.cfg.PrometheusDataSourceGlobalImportFromTime,
+		MaxQueryRangeDuration:     cfg.PrometheusDataSourceMaxQueryRangeDuration,
+		MaxBackfillImportDuration: cfg.PrometheusDataSourceMaxBackfillImportDuration,
+		ImportFromTime:            importFromTime,
+		PartitionGranularity:      dataSourcePartitionGranularity(reportDataSource),
+		ClusterID:                 op.cfg.ClusterID,
 	}
 }
 
+// dataSourcePartitionGranularity returns the partition granularity the
+// ReportDataSource's table was created with. Changes to
+// spec.promsum.partitionGranularity don't affect existing tables.
+func dataSourcePartitionGranularity(reportDataSource *cbTypes.ReportDataSource) prestostore.PartitionGranularity {
+	return prestostore.PartitionGranularity(reportDataSource.Status.PartitionGranularity)
+}
+
+// backfillStartTime returns the time the ReportDataSource's spec.backfill
+// begins importing data from, or nil if it has no backfill configured.
+func backfillStartTime(reportDataSource *cbTypes.ReportDataSource) *time.Time {
+	backfill := reportDataSource.Spec.Backfill
+	var startTime time.Time
+	switch {
+	case backfill == nil:
+		return nil
+	case backfill.StartTime != nil:
+		startTime = backfill.StartTime.Time.UTC()
+	case backfill.Duration != nil:
+		startTime = reportDataSource.CreationTimestamp.Time.Add(-backfill.Duration.Duration).UTC()
+	default:
+		return nil
+	}
+	return &startTime
+}
+
+// newPrometheusBackfillStatus returns the progress of a backfill which
+// started at startTime and is complete once data up to endTime has been
+// imported.
+func newPrometheusBackfillStatus(startTime, endTime time.Time, newestImportedMetricTime *metav1.Time) *cbTypes.PrometheusBackfillStatus {
+	status := &cbTypes.PrometheusBackfillStatus{
+		StartTime: metav1.NewTime(startTime),
+		EndTime:   metav1.NewTime(endTime),
+	}
+	total := endTime.Sub(startTime)
+	// the percentage is computed in seconds, since multiplying a backfill of
+	// more than about 3 years by 100 overflows a time.Duration
+	var percentComplete float64
+	switch {
+	case total <= 0:
+		percentComplete = 100
+	case newestImportedMetricTime != nil:
+		imported := newestImportedMetricTime.Time.Sub(startTime)
+		percentComplete = 100 * imported.Seconds() / total.Seconds()
+	}
+	switch {
+	case percentComplete >= 100:
+		status.PercentComplete = 100
+		status.Completed = true
+	case percentComplete > 0:
+		status.PercentComplete = int(percentComplete)
+	}
+	return status
+}
+
 func (op *Reporting) newPromImporter(logger logrus.FieldLogger, reportDataSource *cbTypes.ReportDataSource, reportPromQuery *cbTypes.ReportPrometheusQuery, cfg prestostore.Config) (*prestostore.PrometheusImporter, error) {
 	metricsCollectors := op.newPromImporterMetricsCollectors(reportDataSource, reportPromQuery)
-	var promConn prom.API
-	var err error
-	if (reportDataSource.Spec.Promsum.PrometheusConfig != nil) && (reportDataSource.Spec.Promsum.PrometheusConfig.URL != "") {
-		promConn, err = op.newPrometheusConnFromURL(reportDataSource.Spec.Promsum.PrometheusConfig.URL)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		promConn = op.promConn
+	promConn, err := op.getPrometheusConnForDataSource(reportDataSource)
+	if err != nil {
+		return nil, err
 	}
 
-	return prestostore.NewPrometheusImporter(logger, promConn, op.prometheusMetricsRepo, op.clock, cfg, metricsCollectors), nil
+	repo := op.prometheusMetricsRepo
+	if op.cfg.AgentConfig.Enabled() {
+		repo = newCentralPrometheusMetricsRepo(op.agentClient, op.cfg.AgentConfig.CentralURL, op.cfg.AgentConfig.CentralNamespace, reportDataSource.Name)
+	}
+	return prestostore.NewPrometheusImporter(logger, promConn, repo, op.clock, cfg, metricsCollectors), nil
 }
 
 func (op *Reporting) newPromImporterMetricsCollectors(reportDataSource *cbTypes.ReportDataSource, reportPromQuery *cbTypes.ReportPrometheusQuery) prestostore.ImporterMetricsCollectors {
 	promLabels := prometheus.Labels{
 		"reportdatasource":      reportDataSource.Name,
 		"reportprometheusquery": reportPromQuery.Name,
-		"table_name":            reportingutil.DataSourceTableName(reportDataSource.Name),
+		"table_name":            op.dataSourceTableName(reportDataSource),
 	}
 
 	totalImportsCounter := prometheusReportDatasourceTotalImportsCounter.With(promLabels)
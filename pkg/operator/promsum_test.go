@@ -0,0 +1,110 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestBackfillStartTime(t *testing.T) {
+	created := time.Date(2019, time.January, 10, 0, 0, 0, 0, time.UTC)
+	startTime := time.Date(2018, time.December, 1, 0, 0, 0, 0, time.FixedZone("test", 3600))
+	newTime := func(t time.Time) *time.Time { return &t }
+
+	tests := map[string]struct {
+		backfill *cbTypes.ReportDataSourceBackfill
+		expected *time.Time
+	}{
+		"no backfill": {},
+		"empty backfill": {
+			backfill: &cbTypes.ReportDataSourceBackfill{},
+		},
+		"duration before the ReportDataSource was created": {
+			backfill: &cbTypes.ReportDataSourceBackfill{Duration: &metav1.Duration{Duration: 7 * 24 * time.Hour}},
+			expected: newTime(time.Date(2019, time.January, 3, 0, 0, 0, 0, time.UTC)),
+		},
+		"start time in UTC": {
+			backfill: &cbTypes.ReportDataSourceBackfill{StartTime: &metav1.Time{Time: startTime}},
+			expected: newTime(startTime.UTC()),
+		},
+		"start time takes precedence over duration": {
+			backfill: &cbTypes.ReportDataSourceBackfill{StartTime: &metav1.Time{Time: startTime}, Duration: &metav1.Duration{Duration: time.Hour}},
+			expected: newTime(startTime.UTC()),
+		},
+	}
+	for name, tt := range tests {
+		reportDataSource := &cbTypes.ReportDataSource{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-cpu", CreationTimestamp: metav1.NewTime(created)},
+			Spec:       cbTypes.ReportDataSourceSpec{Backfill: tt.backfill},
+		}
+		assert.Equal(t, tt.expected, backfillStartTime(reportDataSource), name)
+	}
+}
+
+func TestNewPrometheusBackfillStatus(t *testing.T) {
+	startTime := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endTime := startTime.Add(10 * time.Hour)
+	// 100 times a backfill of 10 years overflows a time.Duration
+	longEndTime := startTime.AddDate(10, 0, 0)
+	newTime := func(t time.Time) *metav1.Time { return &metav1.Time{Time: t} }
+
+	tests := map[string]struct {
+		endTime                  time.Time
+		newestImportedMetricTime *metav1.Time
+		expectedPercentComplete  int
+		expectedCompleted        bool
+	}{
+		"nothing imported": {
+			endTime: endTime,
+		},
+		"imported before the start time": {
+			endTime:                  endTime,
+			newestImportedMetricTime: newTime(startTime.Add(-time.Hour)),
+		},
+		"partially imported": {
+			endTime:                  endTime,
+			newestImportedMetricTime: newTime(startTime.Add(2*time.Hour + 30*time.Minute)),
+			expectedPercentComplete:  25,
+		},
+		"imported up to the end time": {
+			endTime:                  endTime,
+			newestImportedMetricTime: newTime(endTime),
+			expectedPercentComplete:  100,
+			expectedCompleted:        true,
+		},
+		"imported past the end time": {
+			endTime:                  endTime,
+			newestImportedMetricTime: newTime(endTime.Add(time.Hour)),
+			expectedPercentComplete:  100,
+			expectedCompleted:        true,
+		},
+		"end time before the start time": {
+			endTime:                 startTime.Add(-time.Hour),
+			expectedPercentComplete: 100,
+			expectedCompleted:       true,
+		},
+		"long backfill partially imported": {
+			endTime:                  longEndTime,
+			newestImportedMetricTime: newTime(startTime.Add(longEndTime.Sub(startTime) / 2)),
+			expectedPercentComplete:  50,
+		},
+		"long backfill imported": {
+			endTime:                  longEndTime,
+			newestImportedMetricTime: newTime(longEndTime),
+			expectedPercentComplete:  100,
+			expectedCompleted:        true,
+		},
+	}
+	for name, tt := range tests {
+		assert.Equal(t, &cbTypes.PrometheusBackfillStatus{
+			StartTime:       metav1.NewTime(startTime),
+			EndTime:         metav1.NewTime(tt.endTime),
+			PercentComplete: tt.expectedPercentComplete,
+			Completed:       tt.expectedCompleted,
+		}, newPrometheusBackfillStatus(startTime, tt.endTime, tt.newestImportedMetricTime), name)
+	}
+}
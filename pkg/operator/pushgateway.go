@@ -0,0 +1,122 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const (
+	// DefaultPushgatewayJob is the job label metrics are pushed to the
+	// Pushgateway with.
+	DefaultPushgatewayJob = "metering"
+
+	pushgatewayTimeout = 30 * time.Second
+)
+
+var pushgatewayPushesCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: prometheusMetricNamespace,
+		Name:      "pushgateway_pushes_total",
+		Help:      "Number of times the results of a ScheduledReport were pushed to the Pushgateway, by result.",
+	},
+	[]string{"result"},
+)
+
+func init() {
+	prometheus.MustRegister(pushgatewayPushesCounter)
+}
+
+// pushgatewayGroupURL returns the URL of the group a ScheduledReport's
+// metrics are pushed to. The group is identified by the scheduledreport and
+// scheduledreport_namespace labels rather than namespace, which is commonly
+// one of the report's label columns.
+func pushgatewayGroupURL(pushgatewayURL, job, namespace, name string) string {
+	return fmt.Sprintf("%s/metrics/job/%s/scheduledreport/%s/scheduledreport_namespace/%s",
+		strings.TrimSuffix(pushgatewayURL, "/"),
+		url.PathEscape(job),
+		url.PathEscape(name),
+		url.PathEscape(namespace),
+	)
+}
+
+// pushScheduledReportMetrics replaces the metrics in the ScheduledReport's
+// group on the Pushgateway with the gauges exported for it's most recent
+// run. It does nothing unless a Pushgateway is configured.
+func (op *Reporting) pushScheduledReportMetrics(ctx context.Context, report *cbTypes.ScheduledReport, rows int, gauges []scheduledReportGauge) error {
+	if op.cfg.PushgatewayURL == "" {
+		return nil
+	}
+	collector := newScheduledReportResultsCollector()
+	collector.set(report, rows, gauges)
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(collector); err != nil {
+		return err
+	}
+	families, err := registry.Gather()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := expfmt.NewEncoder(&buf, expfmt.FmtText)
+	for _, family := range families {
+		if err := enc.Encode(family); err != nil {
+			return err
+		}
+	}
+	err = op.sendPushgatewayRequest(ctx, "PUT", report.Namespace, report.Name, &buf)
+	if err != nil {
+		pushgatewayPushesCounter.WithLabelValues("failed").Inc()
+		return err
+	}
+	pushgatewayPushesCounter.WithLabelValues("succeeded").Inc()
+	return nil
+}
+
+// deleteScheduledReportPushgatewayMetrics deletes the group of the
+// ScheduledReport with the specified namespace and name from the
+// Pushgateway, if one is configured.
+func (op *Reporting) deleteScheduledReportPushgatewayMetrics(ctx context.Context, namespace, name string) error {
+	if op.cfg.PushgatewayURL == "" {
+		return nil
+	}
+	return op.sendPushgatewayRequest(ctx, "DELETE", namespace, name, nil)
+}
+
+func (op *Reporting) sendPushgatewayRequest(ctx context.Context, method, namespace, name string, body io.Reader) error {
+	job := op.cfg.PushgatewayJob
+	if job == "" {
+		job = DefaultPushgatewayJob
+	}
+	req, err := http.NewRequest(method, pushgatewayGroupURL(op.cfg.PushgatewayURL, job, namespace, name), body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", string(expfmt.FmtText))
+	}
+	ctx, cancel := context.WithTimeout(ctx, pushgatewayTimeout)
+	defer cancel()
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("unable to %s metrics on the Pushgateway: %v", method, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unable to %s metrics on the Pushgateway, got status %d: %s", method, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
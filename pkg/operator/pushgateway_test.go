@@ -0,0 +1,46 @@
+package operator
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestPushScheduledReportMetrics(t *testing.T) {
+	var method, path, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	op := &Reporting{cfg: Config{PushgatewayURL: srv.URL + "/"}}
+	report := &cbTypes.ScheduledReport{ObjectMeta: metav1.ObjectMeta{Name: "namespace-cost-daily", Namespace: "metering"}}
+	gauges := []scheduledReportGauge{{
+		fqName:     "metering_namespace_cost_daily_cost",
+		labelNames: []string{"namespace"},
+		samples:    []scheduledReportSample{{labelValues: []string{"team-a"}, value: 2}},
+	}}
+	require.NoError(t, op.pushScheduledReportMetrics(context.Background(), report, 1, gauges))
+	assert.Equal(t, "PUT", method)
+	assert.Equal(t, "/metrics/job/metering/scheduledreport/namespace-cost-daily/scheduledreport_namespace/metering", path)
+	assert.Contains(t, body, `metering_namespace_cost_daily_cost{namespace="team-a"} 2`)
+
+	require.NoError(t, op.deleteScheduledReportPushgatewayMetrics(context.Background(), "metering", "namespace-cost-daily"))
+	assert.Equal(t, "DELETE", method)
+
+	op.cfg.PushgatewayURL = ""
+	method = ""
+	require.NoError(t, op.pushScheduledReportMetrics(context.Background(), report, 1, gauges))
+	assert.Empty(t, method, "expected nothing to be pushed without a Pushgateway configured")
+}
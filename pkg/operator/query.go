@@ -1,7 +1,8 @@
 package operator
 
 import (
-	"fmt"
+	"context"
+	"reflect"
 
 	log "github.com/sirupsen/logrus"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -9,6 +10,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
 	"github.com/operator-framework/operator-metering/pkg/db"
 	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
 	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
@@ -16,13 +18,12 @@ import (
 )
 
 func (op *Reporting) runReportGenerationQueryWorker() {
-	logger := op.logger.WithField("component", "reportGenerationQueryWorker")
+	logger := op.logger.WithField(logFieldController, "reportgenerationquery")
 	logger.Infof("ReportGenerationQuery worker started")
 	// 10 requeues compared to the 5 others have because
 	// ReportGenerationQueries can reference a lot of other resources, and it may
 	// take time for them to all to finish setup
-	const maxRequeues = 10
-	for op.processResource(logger, op.syncReportGenerationQuery, "ReportGenerationQuery", op.reportGenerationQueryQueue, maxRequeues) {
+	for op.processResource(logger, op.syncReportGenerationQuery, "ReportGenerationQuery", op.reportGenerationQueryQueue, op.cfg.MaxRetriesConfig.ReportGenerationQuery) {
 	}
 }
 
@@ -33,8 +34,6 @@ func (op *Reporting) syncReportGenerationQuery(logger log.FieldLogger, key strin
 		return nil
 	}
 
-	logger = logger.WithField("ReportGenerationQuery", name)
-
 	reportGenerationQueryLister := op.reportGenerationQueryLister
 	reportGenerationQuery, err := reportGenerationQueryLister.ReportGenerationQueries(namespace).Get(name)
 	if err != nil {
@@ -55,13 +54,22 @@ func (op *Reporting) handleReportGenerationQuery(logger log.FieldLogger, generat
 		logger.Infof("ReportGenerationQuery has spec.view.disabled=true, skipping view creation")
 	} else if generationQuery.Status.ViewName == "" {
 		logger.Infof("new ReportGenerationQuery discovered")
-		viewName = reportingutil.GenerationQueryViewName(generationQuery.Name)
+		viewName = reportingutil.GenerationQueryViewName(op.tableNamespace(generationQuery.Namespace), generationQuery.Name)
 		createView = true
 	} else {
 		logger.Infof("existing ReportGenerationQuery discovered, viewName: %s", generationQuery.Status.ViewName)
 		viewName = generationQuery.Status.ViewName
 	}
 
+	if err := reporting.ValidateReportGenerationQueryInputDefinitions(generationQuery); err != nil {
+		return reasonErrorf(err, cbutil.ValidationErrorReason, "unable to validate ReportGenerationQuery %s: %v", generationQuery.Name, err)
+	}
+	if generationQuery.Spec.Rollup != nil {
+		if err := reporting.ValidateRollup(generationQuery); err != nil {
+			return reasonErrorf(err, cbutil.ValidationErrorReason, "unable to validate ReportGenerationQuery %s: %v", generationQuery.Name, err)
+		}
+	}
+
 	queryDependencies, err := reporting.GetAndValidateGenerationQueryDependencies(
 		reporting.NewReportGenerationQueryListerGetter(op.reportGenerationQueryLister),
 		reporting.NewReportDataSourceListerGetter(op.reportDataSourceLister),
@@ -70,23 +78,34 @@ func (op *Reporting) handleReportGenerationQuery(logger log.FieldLogger, generat
 		generationQuery,
 		op.uninitialiedDependendenciesHandler(),
 	)
+	generationQuery = op.updateReportGenerationQueryDependencyStatus(logger, generationQuery, err)
 	if err != nil {
-		return fmt.Errorf("unable to validate ReportGenerationQuery %s, failed to validate dependencies %v", generationQuery.Name, err)
+		return reasonErrorf(err, cbutil.ValidationErrorReason, "unable to validate ReportGenerationQuery %s, failed to validate dependencies %v", generationQuery.Name, err)
 	}
 
 	if createView {
+		// views aren't rendered for a report, so they use the rates of
+		// the namespace's default PricingPolicy when they're created
+		pricing, err := op.getPricingPolicy(generationQuery.Namespace, "")
+		if err != nil {
+			return reasonErrorf(err, cbutil.ValidationErrorReason, "unable to get the default PricingPolicy for ReportGenerationQuery %s: %v", generationQuery.Name, err)
+		}
 		tmplCtx := &reporting.ReportQueryTemplateContext{
+			TableNamespace:          op.tableNamespace(generationQuery.Namespace),
 			DynamicDependentQueries: queryDependencies.DynamicReportGenerationQueries,
 			Report:                  nil,
+			Pricing:                 pricing,
+			Currency:                op.cfg.Currency,
+			ClusterID:               op.cfg.ClusterID,
 		}
 		renderedQuery, err := reporting.RenderQuery(generationQuery.Spec.Query, tmplCtx)
 		if err != nil {
 			return err
 		}
 
-		err = op.prestoViewCreator.CreateView(viewName, renderedQuery)
+		err = op.prestoViewCreator.CreateView(op.workCtx, viewName, renderedQuery)
 		if err != nil {
-			return fmt.Errorf("error creating view %s for ReportGenerationQuery %s: %v", viewName, generationQuery.Name, err)
+			return reasonErrorf(err, cbutil.PrestoErrorReason, "error creating view %s for ReportGenerationQuery %s: %v", viewName, generationQuery.Name, err)
 		}
 
 		err = op.updateReportQueryViewName(logger, generationQuery, viewName)
@@ -95,6 +114,12 @@ func (op *Reporting) handleReportGenerationQuery(logger log.FieldLogger, generat
 		}
 	}
 
+	if generationQuery.Spec.Materialized != nil {
+		if err := op.refreshMaterializedQuery(logger, generationQuery, queryDependencies); err != nil {
+			return err
+		}
+	}
+
 	// enqueue any queries depending on this one
 	if err := op.queueDependentReportGenerationQueriesForQuery(generationQuery); err != nil {
 		logger.WithError(err).Errorf("error queuing ReportGenerationQuery dependents of ReportGenerationQuery %s", generationQuery.Name)
@@ -111,6 +136,29 @@ func (op *Reporting) handleReportGenerationQuery(logger log.FieldLogger, generat
 	return nil
 }
 
+// updateReportGenerationQueryDependencyStatus records the unresolved
+// dependencies and dependency cycle of the ReportGenerationQuery found when
+// resolving it's dependencies in it's status, returning the updated
+// ReportGenerationQuery.
+func (op *Reporting) updateReportGenerationQueryDependencyStatus(logger log.FieldLogger, generationQuery *cbTypes.ReportGenerationQuery, depsErr error) *cbTypes.ReportGenerationQuery {
+	unresolved := reporting.UnresolvedDependencies(depsErr)
+	var cycle []string
+	if cycleErr, ok := depsErr.(*reporting.DependencyCycleError); ok {
+		cycle = cycleErr.Cycle
+	}
+	if reflect.DeepEqual(unresolved, generationQuery.Status.UnresolvedDependencies) && reflect.DeepEqual(cycle, generationQuery.Status.DependencyCycle) {
+		return generationQuery
+	}
+	generationQuery.Status.UnresolvedDependencies = unresolved
+	generationQuery.Status.DependencyCycle = cycle
+	newQuery, err := op.meteringClient.MeteringV1alpha1().ReportGenerationQueries(generationQuery.Namespace).Update(generationQuery)
+	if err != nil {
+		logger.WithError(err).Errorf("failed to update ReportGenerationQuery dependency status for %q", generationQuery.Name)
+		return generationQuery
+	}
+	return newQuery
+}
+
 func (op *Reporting) updateReportQueryViewName(logger log.FieldLogger, generationQuery *cbTypes.ReportGenerationQuery, viewName string) error {
 	generationQuery.Status.ViewName = viewName
 	_, err := op.meteringClient.MeteringV1alpha1().ReportGenerationQueries(generationQuery.Namespace).Update(generationQuery)
@@ -185,13 +233,13 @@ func (op *Reporting) queueDependentScheduledReportsForQuery(generationQuery *cbT
 }
 
 type PrestoViewCreator interface {
-	CreateView(viewName, query string) error
+	CreateView(ctx context.Context, viewName, query string) error
 }
 
 type prestoViewCreator struct {
 	queryer db.Queryer
 }
 
-func (c *prestoViewCreator) CreateView(viewName, query string) error {
-	return presto.CreateView(c.queryer, viewName, query, true)
+func (c *prestoViewCreator) CreateView(ctx context.Context, viewName, query string) error {
+	return presto.CreateView(ctx, c.queryer, viewName, query, true)
 }
@@ -0,0 +1,239 @@
+package operator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/operator-framework/operator-metering/pkg/athena"
+	"github.com/operator-framework/operator-metering/pkg/bigquery"
+	"github.com/operator-framework/operator-metering/pkg/clickhouse"
+	"github.com/operator-framework/operator-metering/pkg/db"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	// QueryBackendPresto runs queries using Presto, and manages tables using
+	// Hive.
+	QueryBackendPresto = "presto"
+	// QueryBackendAthena runs queries and manages tables using AWS Athena.
+	QueryBackendAthena = "athena"
+	// QueryBackendBigQuery runs queries and manages tables using Google
+	// BigQuery.
+	QueryBackendBigQuery = "bigquery"
+	// QueryBackendClickHouse runs queries and manages tables using
+	// ClickHouse.
+	QueryBackendClickHouse = "clickhouse"
+)
+
+// queryBackend is what's used to run queries and manage tables.
+type queryBackend struct {
+	// queryer runs the queries generating reports and storing data.
+	queryer db.Queryer
+	// ddlQueryer runs the queries managing tables.
+	ddlQueryer db.Queryer
+
+	reportResultsRepo        prestostore.ReportResultsRepo
+	tableManager             reporting.TableManager
+	awsTablePartitionManager reporting.AWSTablePartitionManager
+	healthCheckQueries       reporting.HealthCheckQueries
+	// sqlDialect generates the SQL used to store Prometheus metrics and
+	// rows from other data sources.
+	sqlDialect prestostore.SQLDialect
+}
+
+func (b *queryBackend) Close() error {
+	queryerErr := b.queryer.Close()
+	ddlQueryerErr := b.ddlQueryer.Close()
+	if queryerErr != nil {
+		return queryerErr
+	}
+	return ddlQueryerErr
+}
+
+type newQueryBackendFunc func(op *Reporting, ctx context.Context) (*queryBackend, error)
+
+// queryBackends contains the query backends which can be selected using
+// Config.QueryBackend.
+var queryBackends = map[string]newQueryBackendFunc{
+	QueryBackendPresto:     newPrestoQueryBackend,
+	QueryBackendAthena:     newAthenaQueryBackend,
+	QueryBackendBigQuery:   newBigQueryQueryBackend,
+	QueryBackendClickHouse: newClickHouseQueryBackend,
+}
+
+func validateHiveConfig(cfg Config) error {
+	if cfg.HiveMaxConnRetries < 1 {
+		return fmt.Errorf("HiveMaxConnRetries must be at least 1, got %d", cfg.HiveMaxConnRetries)
+	}
+	if cfg.HiveConnBackoff < 0 || cfg.HiveQueryTimeout < 0 {
+		return fmt.Errorf("HiveConnBackoff and HiveQueryTimeout cannot be negative")
+	}
+	return nil
+}
+
+func validateQueryBackend(cfg Config) error {
+	if _, ok := queryBackends[cfg.QueryBackend]; !ok {
+		var names []string
+		for name := range queryBackends {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("invalid QueryBackend %q, must be one of %s", cfg.QueryBackend, strings.Join(names, ", "))
+	}
+	switch cfg.QueryBackend {
+	case QueryBackendAthena:
+		if cfg.AthenaConfig.Database == "" || cfg.AthenaConfig.OutputLocation == "" {
+			return fmt.Errorf("AthenaConfig.Database and AthenaConfig.OutputLocation must be set when using the %s query backend", QueryBackendAthena)
+		}
+	case QueryBackendBigQuery:
+		if cfg.BigQueryConfig.ProjectID == "" || cfg.BigQueryConfig.Dataset == "" {
+			return fmt.Errorf("BigQueryConfig.ProjectID and BigQueryConfig.Dataset must be set when using the %s query backend", QueryBackendBigQuery)
+		}
+		// Prometheus metrics are stored using Presto specific SQL
+		if !cfg.DisablePromsum {
+			return fmt.Errorf("DisablePromsum must be set when using the %s query backend, as collecting Prometheus metrics isn't supported", QueryBackendBigQuery)
+		}
+	case QueryBackendClickHouse:
+		if cfg.ClickHouseConfig.Address == "" || cfg.ClickHouseConfig.Database == "" {
+			return fmt.Errorf("ClickHouseConfig.Address and ClickHouseConfig.Database must be set when using the %s query backend", QueryBackendClickHouse)
+		}
+	}
+	return nil
+}
+
+// newPrestoQueryBackend returns a queryBackend running queries using Presto,
+// and managing tables using Hive.
+func newPrestoQueryBackend(op *Reporting, ctx context.Context) (*queryBackend, error) {
+	var prestoQueryer, hiveQueryer db.Queryer
+	// Use errgroup to setup both hive and presto connections
+	// at the sametime, waiting for both to be ready before continuing.
+	// if either errors, we return the first error
+	var g errgroup.Group
+	g.Go(func() error {
+		connStr, err := presto.ConnectionString(op.cfg.PrestoDialect, prestoUsername, op.cfg.PrestoHost)
+		if err != nil {
+			return err
+		}
+		if op.cfg.TableCompression != "" {
+			// Presto compresses the files it writes using the codec of
+			// it's session, rather than the table's properties
+			connStr, err = presto.SessionConnectionString(connStr, presto.Session{
+				Properties: map[string]string{"hive.compression_codec": strings.ToUpper(op.cfg.TableCompression)},
+			})
+			if err != nil {
+				return err
+			}
+		}
+		prestoConn, err := presto.NewPrestoConnWithRetry(ctx, op.logger, connStr, connBackoff, maxConnRetries)
+		if err != nil {
+			return err
+		}
+		// queries using a ReportGenerationQuery's session are run using
+		// separate connections with the session configured
+		sessionQueryer := presto.NewSessionQueryer(prestoConn, connStr)
+		prestoQueryer = db.NewTracingQueryer(db.NewLoggingQueryer(sessionQueryer, op.logger, op.logDMLQueries), QueryBackendPresto)
+		return nil
+	})
+	g.Go(func() error {
+		hiveTLSConfig, err := op.cfg.HiveTLSConfig.ClientConfig()
+		if err != nil {
+			return err
+		}
+		reconnectingHiveQueryer := hive.NewReconnectingQueryer(ctx, op.logger, op.cfg.HiveHost, hiveTLSConfig, op.cfg.HiveConnBackoff, op.cfg.HiveMaxConnRetries, op.cfg.HiveQueryTimeout)
+		hiveQueryer = db.NewTracingQueryer(db.NewLoggingQueryer(reconnectingHiveQueryer, op.logger, op.logDDLQueries), "hive")
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	backend := &queryBackend{
+		queryer:            prestoQueryer,
+		ddlQueryer:         hiveQueryer,
+		reportResultsRepo:  prestostore.NewReportResultsRepo(prestoQueryer),
+		healthCheckQueries: reporting.PrestoHealthCheckQueries,
+		sqlDialect:         prestostore.PrestoSQLDialect,
+	}
+	if op.cfg.HiveMetastoreHost != "" {
+		metastoreClient := hive.NewMetastoreClient(ctx, op.logger, op.cfg.HiveMetastoreHost, op.cfg.HiveConnBackoff, op.cfg.HiveMaxConnRetries)
+		metastoreTableManager := reporting.NewHiveMetastoreTableManager(hiveQueryer, metastoreClient)
+		backend.tableManager = metastoreTableManager
+		backend.awsTablePartitionManager = metastoreTableManager
+	} else {
+		hiveTableManager := reporting.NewHiveTableManager(hiveQueryer)
+		backend.tableManager = hiveTableManager
+		backend.awsTablePartitionManager = hiveTableManager
+	}
+	return backend, nil
+}
+
+// newAthenaQueryBackend returns a queryBackend running queries and managing
+// tables using AWS Athena.
+func newAthenaQueryBackend(op *Reporting, ctx context.Context) (*queryBackend, error) {
+	athenaConn, err := sql.Open(athena.DriverName, op.cfg.AthenaConfig.FormatDSN())
+	if err != nil {
+		return nil, err
+	}
+	queryer := db.NewTracingQueryer(db.NewLoggingQueryer(athenaConn, op.logger, op.logDMLQueries), QueryBackendAthena)
+	ddlQueryer := db.NewTracingQueryer(db.NewLoggingQueryer(athenaConn, op.logger, op.logDDLQueries), QueryBackendAthena)
+	athenaTableManager := reporting.NewAthenaTableManager(ddlQueryer)
+	return &queryBackend{
+		queryer:                  queryer,
+		ddlQueryer:               ddlQueryer,
+		reportResultsRepo:        prestostore.NewReportResultsRepo(queryer),
+		tableManager:             athenaTableManager,
+		awsTablePartitionManager: athenaTableManager,
+		healthCheckQueries:       reporting.AthenaHealthCheckQueries,
+		sqlDialect:               prestostore.PrestoSQLDialect,
+	}, nil
+}
+
+// newBigQueryQueryBackend returns a queryBackend running queries and managing
+// tables using Google BigQuery.
+func newBigQueryQueryBackend(op *Reporting, ctx context.Context) (*queryBackend, error) {
+	bigQueryConn, err := sql.Open(bigquery.DriverName, op.cfg.BigQueryConfig.FormatDSN())
+	if err != nil {
+		return nil, err
+	}
+	queryer := db.NewTracingQueryer(db.NewLoggingQueryer(bigQueryConn, op.logger, op.logDMLQueries), QueryBackendBigQuery)
+	ddlQueryer := db.NewTracingQueryer(db.NewLoggingQueryer(bigQueryConn, op.logger, op.logDDLQueries), QueryBackendBigQuery)
+	bigQueryTableManager := reporting.NewBigQueryTableManager(ddlQueryer, op.cfg.BigQueryConfig.Dataset)
+	return &queryBackend{
+		queryer:                  queryer,
+		ddlQueryer:               ddlQueryer,
+		reportResultsRepo:        prestostore.NewBigQueryReportResultsRepo(queryer),
+		tableManager:             bigQueryTableManager,
+		awsTablePartitionManager: bigQueryTableManager,
+		healthCheckQueries:       reporting.BigQueryHealthCheckQueries,
+		sqlDialect:               prestostore.PrestoSQLDialect,
+	}, nil
+}
+
+// newClickHouseQueryBackend returns a queryBackend running queries and
+// managing tables using ClickHouse.
+func newClickHouseQueryBackend(op *Reporting, ctx context.Context) (*queryBackend, error) {
+	clickHouseConn, err := sql.Open(clickhouse.DriverName, op.cfg.ClickHouseConfig.FormatDSN())
+	if err != nil {
+		return nil, err
+	}
+	queryer := db.NewTracingQueryer(db.NewLoggingQueryer(clickHouseConn, op.logger, op.logDMLQueries), QueryBackendClickHouse)
+	ddlQueryer := db.NewTracingQueryer(db.NewLoggingQueryer(clickHouseConn, op.logger, op.logDDLQueries), QueryBackendClickHouse)
+	clickHouseTableManager := reporting.NewClickHouseTableManager(ddlQueryer)
+	return &queryBackend{
+		queryer:                  queryer,
+		ddlQueryer:               ddlQueryer,
+		reportResultsRepo:        prestostore.NewClickHouseReportResultsRepo(queryer),
+		tableManager:             clickHouseTableManager,
+		awsTablePartitionManager: clickHouseTableManager,
+		healthCheckQueries:       reporting.ClickHouseHealthCheckQueries,
+		sqlDialect:               prestostore.ClickHouseSQLDialect,
+	}, nil
+}
@@ -0,0 +1,45 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateHiveConfig(t *testing.T) {
+	tests := map[string]struct {
+		cfg         Config
+		expectedErr bool
+	}{
+		"defaults": {
+			cfg: Config{HiveConnBackoff: DefaultHiveConnBackoff, HiveMaxConnRetries: DefaultHiveMaxConnRetries},
+		},
+		"query timeout": {
+			cfg: Config{HiveConnBackoff: DefaultHiveConnBackoff, HiveMaxConnRetries: 1, HiveQueryTimeout: time.Minute},
+		},
+		"no backoff": {
+			cfg: Config{HiveMaxConnRetries: DefaultHiveMaxConnRetries},
+		},
+		"no attempts": {
+			cfg:         Config{HiveConnBackoff: DefaultHiveConnBackoff},
+			expectedErr: true,
+		},
+		"negative backoff": {
+			cfg:         Config{HiveConnBackoff: -time.Second, HiveMaxConnRetries: DefaultHiveMaxConnRetries},
+			expectedErr: true,
+		},
+		"negative query timeout": {
+			cfg:         Config{HiveConnBackoff: DefaultHiveConnBackoff, HiveMaxConnRetries: DefaultHiveMaxConnRetries, HiveQueryTimeout: -time.Minute},
+			expectedErr: true,
+		},
+	}
+	for name, tt := range tests {
+		err := validateHiveConfig(tt.cfg)
+		if tt.expectedErr {
+			assert.Error(t, err, name)
+		} else {
+			assert.NoError(t, err, name)
+		}
+	}
+}
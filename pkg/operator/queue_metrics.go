@@ -0,0 +1,43 @@
+package operator
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	syncsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: prometheusMetricNamespace,
+			Name:      "syncs_total",
+			Help:      "Number of times resources were synced by their worker, by kind and result.",
+		},
+		[]string{"kind", "result"},
+	)
+	syncDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: prometheusMetricNamespace,
+			Name:      "sync_duration_seconds",
+			Help:      "Time taken to sync resources by their worker, by kind.",
+			Buckets:   []float64{0.01, 0.1, 1, 10, 60, 300, 900, 1800, 3600},
+		},
+		[]string{"kind"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(syncsCounter)
+	prometheus.MustRegister(syncDurationHistogram)
+}
+
+// observeSync records the duration and result of syncing a resource of the
+// given kind.
+func observeSync(kind string, duration time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	syncsCounter.WithLabelValues(kind, result).Inc()
+	syncDurationHistogram.WithLabelValues(kind).Observe(duration.Seconds())
+}
@@ -0,0 +1,61 @@
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/ratelimit"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	// DefaultResyncPeriod is how often the informers resync, adding every
+	// resource to it's work queue again.
+	DefaultResyncPeriod = 15 * time.Minute
+
+	// The defaults of the work queue rate limiter, which are the same as
+	// workqueue.DefaultControllerRateLimiter.
+	DefaultQueueBaseDelay = 5 * time.Millisecond
+	DefaultQueueMaxDelay  = 1000 * time.Second
+	DefaultQueueQPS       = 10
+	DefaultQueueBurst     = 100
+)
+
+// RateLimiterConfig configures how quickly items are added back to the work
+// queues after failing, and how many items are added per second overall.
+type RateLimiterConfig struct {
+	// BaseDelay is how long an item waits before it's first retry. The
+	// delay doubles after each failure, up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// QPS is the number of items added to each queue per second, allowing
+	// bursts of up to Burst items.
+	QPS   float64
+	Burst int
+}
+
+func (cfg *RateLimiterConfig) Valid() error {
+	if cfg.BaseDelay <= 0 {
+		return fmt.Errorf("work queue base delay must be positive, got %s", cfg.BaseDelay)
+	}
+	if cfg.MaxDelay < cfg.BaseDelay {
+		return fmt.Errorf("work queue max delay %s must not be less than the base delay %s", cfg.MaxDelay, cfg.BaseDelay)
+	}
+	if cfg.QPS <= 0 {
+		return fmt.Errorf("work queue QPS must be positive, got %v", cfg.QPS)
+	}
+	if cfg.Burst < 1 {
+		return fmt.Errorf("work queue burst must be at least 1, got %d", cfg.Burst)
+	}
+	return nil
+}
+
+// newRateLimiter returns a rate limiter like
+// workqueue.DefaultControllerRateLimiter, using the configured parameters.
+// Each queue needs it's own rate limiter.
+func (cfg *RateLimiterConfig) newRateLimiter() workqueue.RateLimiter {
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(cfg.BaseDelay, cfg.MaxDelay),
+		&workqueue.BucketRateLimiter{Bucket: ratelimit.NewBucketWithRate(cfg.QPS, int64(cfg.Burst))},
+	)
+}
@@ -0,0 +1,40 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterConfig(t *testing.T) {
+	defaults := RateLimiterConfig{
+		BaseDelay: DefaultQueueBaseDelay,
+		MaxDelay:  DefaultQueueMaxDelay,
+		QPS:       DefaultQueueQPS,
+		Burst:     DefaultQueueBurst,
+	}
+	assert.NoError(t, defaults.Valid())
+
+	invalid := map[string]func(cfg *RateLimiterConfig){
+		"zero base delay":            func(cfg *RateLimiterConfig) { cfg.BaseDelay = 0 },
+		"max delay below base delay": func(cfg *RateLimiterConfig) { cfg.MaxDelay = time.Millisecond },
+		"zero qps":                   func(cfg *RateLimiterConfig) { cfg.QPS = 0 },
+		"zero burst":                 func(cfg *RateLimiterConfig) { cfg.Burst = 0 },
+	}
+	for name, modify := range invalid {
+		cfg := defaults
+		modify(&cfg)
+		assert.Error(t, cfg.Valid(), name)
+	}
+
+	cfg := RateLimiterConfig{BaseDelay: time.Second, MaxDelay: 4 * time.Second, QPS: 1000, Burst: 1000}
+	rateLimiter := cfg.newRateLimiter()
+	var delays []time.Duration
+	for i := 0; i < 4; i++ {
+		delays = append(delays, rateLimiter.When("report"))
+	}
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second}, delays)
+	rateLimiter.Forget("report")
+	assert.Equal(t, time.Second, rateLimiter.When("report"))
+}
@@ -75,6 +75,8 @@ func (op *Reporting) deleteReport(obj interface{}) {
 		op.logger.WithField("report", report.Name).WithError(err).Errorf("couldn't get key for object: %#v", report)
 		return
 	}
+	// stop any queries still generating the report
+	op.cancelReportQueries("Report", key)
 	op.reportQueue.Add(key)
 }
 
@@ -158,6 +160,8 @@ func (op *Reporting) deleteScheduledReport(obj interface{}) {
 		op.logger.WithField("scheduledReport", report.Name).WithError(err).Errorf("couldn't get key for object: %#v", report)
 		return
 	}
+	// stop any queries still generating the report
+	op.cancelReportQueries("ScheduledReport", key)
 	op.scheduledReportQueue.Add(key)
 }
 
@@ -367,8 +371,11 @@ type syncHandler func(logger log.FieldLogger, key string) error
 func (op *Reporting) runHandler(logger log.FieldLogger, handlerFunc syncHandler, objType string, obj interface{}, queue workqueue.RateLimitingInterface, maxRequeues int) {
 	logger = logger.WithFields(newLogIdentifier(op.rand))
 	if key, ok := op.getKeyFromQueueObj(logger, objType, obj, queue); ok {
+		logger = newResourceLogger(logger, objType, key)
 		logger.Infof("syncing %s %s", objType, key)
+		syncStart := op.clock.Now()
 		err := handlerFunc(logger, key)
+		observeSync(objType, op.clock.Since(syncStart), err)
 		op.handleErr(logger, err, objType, key, queue, maxRequeues)
 	}
 }
@@ -397,11 +404,18 @@ func (op *Reporting) handleErr(logger log.FieldLogger, err error, objType string
 	if err == nil {
 		logger.Infof("successfully synced %s %q", objType, obj)
 		queue.Forget(obj)
+		if key, ok := obj.(string); ok {
+			op.clearRetriesExhausted(logger, objType, key)
+		}
 		return
 	}
 
+	if key, ok := obj.(string); ok {
+		op.recordSyncError(objType, key, err)
+	}
+
 	// This controller retries up to maxRequeues times if something goes wrong.
-	// After that, it stops trying.
+	// After that, it stops trying, and marks the resource as failed.
 	if queue.NumRequeues(obj) < maxRequeues {
 		logger.WithError(err).Errorf("error syncing %s %q, adding back to queue", objType, obj)
 		queue.AddRateLimited(obj)
@@ -409,5 +423,9 @@ func (op *Reporting) handleErr(logger log.FieldLogger, err error, objType string
 	}
 
 	queue.Forget(obj)
-	logger.WithError(err).Infof("error syncing %s %q, dropping out of the queue", objType, obj)
+	logger.WithError(err).Errorf("error syncing %s %q after %d retries, dropping out of the queue", objType, obj, maxRequeues)
+	// errors caused by shutting down aren't the resource's fault
+	if key, ok := obj.(string); ok && !queue.ShuttingDown() {
+		op.recordRetriesExhausted(logger, objType, key, maxRequeues, err)
+	}
 }
@@ -0,0 +1,158 @@
+package operator
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// The reasons reported when reporting-operator isn't ready to serve API
+	// requests.
+	NotReadyInitializingReason      = "Initializing"
+	NotReadyPrestoUnavailableReason = "PrestoUnavailable"
+	NotReadyQueuesSaturatedReason   = "QueuesSaturated"
+
+	// DefaultAPIMaxQueueDepth disables rejecting API requests when the work
+	// queues are saturated, since every resync fills the queues with an item
+	// for each resource.
+	DefaultAPIMaxQueueDepth = 0
+
+	// prestoReadinessCacheDuration is how long the result of checking if
+	// Presto is readable is reused for, to avoid querying Presto on every
+	// API request.
+	prestoReadinessCacheDuration = 10 * time.Second
+)
+
+var (
+	// apiIngestPaths are the API endpoints which store data pushed to
+	// reporting-operator. They aren't rejected when the work queues are
+	// saturated, since the data would otherwise be lost or have to be resent.
+	apiIngestPaths = []string{
+		"/api/v1/prometheus/write",
+		"/api/v1/datasources/prometheus/store/",
+	}
+
+	// notReadyRetryAfter is how long clients are asked to wait before retrying
+	// a request for each not ready reason.
+	notReadyRetryAfter = map[string]time.Duration{
+		NotReadyInitializingReason:      10 * time.Second,
+		NotReadyPrestoUnavailableReason: 30 * time.Second,
+		NotReadyQueuesSaturatedReason:   5 * time.Second,
+	}
+
+	apiNotReadyResponsesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: prometheusMetricNamespace,
+			Name:      "api_not_ready_responses_total",
+			Help:      "Number of API requests rejected because reporting-operator was not ready, by reason.",
+		},
+		[]string{"reason"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(apiNotReadyResponsesCounter)
+}
+
+type notReadyResponse struct {
+	Error             string `json:"error"`
+	Reason            string `json:"reason"`
+	RetryAfterSeconds int    `json:"retryAfterSeconds"`
+}
+
+// readiness returns an empty reason if reporting-operator is able to serve
+// API requests, otherwise it returns the reason it's not ready and a human
+// readable message.
+func (op *Reporting) readiness(checkQueues bool) (reason, message string) {
+	if !op.isInitialized() {
+		return NotReadyInitializingReason, "not initialized"
+	}
+	if !op.prestoReadable() {
+		return NotReadyPrestoUnavailableReason, "cannot read from PrestoDB"
+	}
+	if checkQueues && op.cfg.APIMaxQueueDepth > 0 {
+		if depth := op.queueDepth(); depth > op.cfg.APIMaxQueueDepth {
+			return NotReadyQueuesSaturatedReason, "work queues are saturated, " + strconv.Itoa(depth) + " items are waiting to be processed"
+		}
+	}
+	return "", ""
+}
+
+// prestoReadable returns whether Presto can be read from, re-using the
+// result of the previous check for up to prestoReadinessCacheDuration. Presto
+// is queried without holding prestoReadinessMu, and while a check is running
+// other callers use the previous result.
+func (op *Reporting) prestoReadable() bool {
+	op.prestoReadinessMu.Lock()
+	now := op.clock.Now()
+	stale := op.prestoReadinessCheckTime.IsZero() || now.Sub(op.prestoReadinessCheckTime) >= prestoReadinessCacheDuration
+	if !stale || op.prestoReadinessChecking {
+		readable := op.prestoReadinessReadable
+		op.prestoReadinessMu.Unlock()
+		return readable
+	}
+	op.prestoReadinessChecking = true
+	op.prestoReadinessMu.Unlock()
+
+	readable := op.testReadFromPrestoFunc()
+
+	op.prestoReadinessMu.Lock()
+	op.prestoReadinessReadable = readable
+	op.prestoReadinessCheckTime = now
+	op.prestoReadinessChecking = false
+	op.prestoReadinessMu.Unlock()
+	return readable
+}
+
+// queueDepth returns the total number of items waiting in the work queues.
+func (op *Reporting) queueDepth() int {
+	depth := 0
+	for _, queue := range op.queueList {
+		depth += queue.Len()
+	}
+	return depth
+}
+
+// writeNotReadyResponse writes a 503 response with a Retry-After header
+// indicating when the request should be retried.
+func (op *Reporting) writeNotReadyResponse(w http.ResponseWriter, r *http.Request, reason, message string) {
+	logger := newRequestLogger(op.logger, r, op.rand)
+	logger.Debugf("not ready: %s", message)
+	apiNotReadyResponsesCounter.WithLabelValues(reason).Inc()
+
+	retryAfter := int(notReadyRetryAfter[reason].Seconds())
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	writeResponseAsJSON(logger, w, http.StatusServiceUnavailable, notReadyResponse{
+		Error:             "not ready: " + message,
+		Reason:            reason,
+		RetryAfterSeconds: retryAfter,
+	})
+}
+
+// apiReadinessHandler wraps the API handler, returning a 503 for API requests
+// until reporting-operator is ready to serve them, instead of letting the
+// requests time out or return partial results.
+func (op *Reporting) apiReadinessHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			if reason, message := op.readiness(!isAPIIngestPath(r.URL.Path)); reason != "" {
+				op.writeNotReadyResponse(w, r, reason, message)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isAPIIngestPath(path string) bool {
+	for _, ingestPath := range apiIngestPaths {
+		if path == ingestPath || (strings.HasSuffix(ingestPath, "/") && strings.HasPrefix(path, ingestPath)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package operator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestAPIReadinessHandlerQueuesSaturated(t *testing.T) {
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+	queue.Add("default/a")
+	queue.Add("default/b")
+	op := &Reporting{
+		logger:                 logrus.New(),
+		rand:                   testRand,
+		clock:                  clock.NewFakeClock(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		cfg:                    Config{APIMaxQueueDepth: 1},
+		initialized:            true,
+		queueList:              []workqueue.RateLimitingInterface{queue},
+		testReadFromPrestoFunc: func() bool { return true },
+	}
+	handler := op.apiReadinessHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for path, expected := range map[string]int{
+		"/api/v1/reports":                              http.StatusServiceUnavailable,
+		"/api/v1/prometheus/write":                     http.StatusOK,
+		"/api/v1/datasources/prometheus/store/pod-cpu": http.StatusOK,
+		"/healthy": http.StatusOK,
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", path, nil))
+		assert.Equal(t, expected, w.Code, "unexpected status for %s", path)
+	}
+}
+
+func TestPrestoReadableChecksWithoutLock(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC))
+	checking := make(chan struct{})
+	release := make(chan struct{})
+	checks := 0
+	op := &Reporting{
+		clock: fakeClock,
+		testReadFromPrestoFunc: func() bool {
+			checks++
+			if checks == 2 {
+				close(checking)
+				<-release
+				return false
+			}
+			return true
+		},
+	}
+	assert.True(t, op.prestoReadable())
+
+	fakeClock.Step(prestoReadinessCacheDuration)
+	done := make(chan bool)
+	go func() { done <- op.prestoReadable() }()
+	<-checking
+	// while Presto is being checked, other callers get the previous result
+	// instead of waiting
+	assert.True(t, op.prestoReadable())
+	close(release)
+	assert.False(t, <-done)
+	assert.False(t, op.prestoReadable())
+	assert.Equal(t, 2, checks)
+}
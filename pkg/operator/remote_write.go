@@ -0,0 +1,150 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/labels"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/remotewrite"
+)
+
+const metricNameLabel = "__name__"
+
+var (
+	remoteWriteSamplesReceivedCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: prometheusMetricNamespace,
+			Name:      "prometheus_remote_write_samples_received_total",
+			Help:      "Number of samples received on the Prometheus remote-write endpoint.",
+		},
+	)
+	remoteWriteSamplesStoredCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: prometheusMetricNamespace,
+			Name:      "prometheus_remote_write_samples_stored_total",
+			Help:      "Number of samples received on the Prometheus remote-write endpoint stored by a ReportDataSource.",
+		},
+		[]string{"reportdatasource", "table_name"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(remoteWriteSamplesReceivedCounter)
+	prometheus.MustRegister(remoteWriteSamplesStoredCounter)
+}
+
+// remoteWriteHandler implements the Prometheus remote-write protocol,
+// storing the received samples in the tables of ReportDataSources with
+// remoteWrite configured.
+func (op *Reporting) remoteWriteHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(op.logger, r, op.rand)
+	if r.Method != "POST" {
+		writeErrorResponse(logger, w, r, http.StatusMethodNotAllowed, "only POST is supported")
+		return
+	}
+	// samples are stored by ReportDataSources in any namespace
+	if !op.resultsAuthorizer.authorizeRequest(logger, w, r, "update", resultsResourceReportDataSources, "") {
+		return
+	}
+
+	req, err := remotewrite.DecodeWriteRequest(r.Body, remotewrite.DefaultMaxRequestSize)
+	if err != nil {
+		// Prometheus doesn't retry requests which fail with a 4xx status
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to decode remote-write request: %v", err)
+		return
+	}
+
+	if err := op.storeRemoteWriteRequest(r.Context(), req); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to store remote-write samples: %v", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (op *Reporting) storeRemoteWriteRequest(ctx context.Context, req *remotewrite.WriteRequest) error {
+	allDataSources, err := op.reportDataSourceLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	var dataSources []*cbTypes.ReportDataSource
+	for _, dataSource := range allDataSources {
+		if dataSource.Spec.Promsum == nil || dataSource.Spec.Promsum.RemoteWrite == nil || dataSource.Status.TableName == "" {
+			continue
+		}
+		if !op.cfg.isWatchedNamespace(dataSource.Namespace) {
+			continue
+		}
+		dataSources = append(dataSources, dataSource)
+	}
+
+	metricsByDataSource := make(map[*cbTypes.ReportDataSource][]*prestostore.PrometheusMetric)
+	for _, series := range req.Timeseries {
+		remoteWriteSamplesReceivedCounter.Add(float64(len(series.Samples)))
+		seriesLabels := series.LabelsMap()
+		for _, dataSource := range dataSources {
+			remoteWrite := dataSource.Spec.Promsum.RemoteWrite
+			if !remoteWriteSeriesMatches(remoteWrite, seriesLabels) {
+				continue
+			}
+			metricLabels := remoteWriteMetricLabels(remoteWrite, seriesLabels)
+			stepSize := op.getStepSizeForReportDataSource(dataSource)
+			for _, sample := range series.Samples {
+				metricsByDataSource[dataSource] = append(metricsByDataSource[dataSource], &prestostore.PrometheusMetric{
+					Labels:    metricLabels,
+					Amount:    sample.Value,
+					StepSize:  stepSize,
+					Timestamp: time.Unix(0, sample.Timestamp*int64(time.Millisecond)).UTC(),
+					ClusterID: op.cfg.ClusterID,
+				})
+			}
+		}
+	}
+
+	for dataSource, metrics := range metricsByDataSource {
+		tableName := dataSource.Status.TableName
+		if err := op.prometheusMetricsRepo.StorePrometheusMetrics(ctx, tableName, dataSourcePartitionGranularity(dataSource), metrics); err != nil {
+			return fmt.Errorf("unable to store samples for ReportDataSource %s/%s: %v", dataSource.Namespace, dataSource.Name, err)
+		}
+		remoteWriteSamplesStoredCounter.WithLabelValues(dataSource.Name, tableName).Add(float64(len(metrics)))
+	}
+	return nil
+}
+
+// remoteWriteSeriesMatches returns true if a series with the labels should
+// be stored by a ReportDataSource with the remoteWrite configuration.
+func remoteWriteSeriesMatches(remoteWrite *cbTypes.PrometheusRemoteWriteConfig, seriesLabels map[string]string) bool {
+	if seriesLabels[metricNameLabel] != remoteWrite.MetricName {
+		return false
+	}
+	for name, value := range remoteWrite.MatchLabels {
+		if seriesLabels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// remoteWriteMetricLabels returns the labels of a series to store.
+func remoteWriteMetricLabels(remoteWrite *cbTypes.PrometheusRemoteWriteConfig, seriesLabels map[string]string) map[string]string {
+	metricLabels := make(map[string]string)
+	if len(remoteWrite.KeepLabels) == 0 {
+		for name, value := range seriesLabels {
+			if name != metricNameLabel {
+				metricLabels[name] = value
+			}
+		}
+		return metricLabels
+	}
+	for _, name := range remoteWrite.KeepLabels {
+		if value, ok := seriesLabels[name]; ok {
+			metricLabels[name] = value
+		}
+	}
+	return metricLabels
+}
@@ -0,0 +1,128 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+	"github.com/operator-framework/operator-metering/pkg/tracing"
+)
+
+// maxReportChunkFailures is the number of consecutive times a chunk of a
+// Report without a retryPolicy can fail before the Report is marked as
+// failed.
+const maxReportChunkFailures = 5
+
+type reportChunk struct {
+	start, end time.Time
+}
+
+// reportChunks splits the period between start and end into chunks of at
+// most size. The last chunk ends at end, so it may be shorter.
+func reportChunks(start, end time.Time, size time.Duration) ([]reportChunk, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("chunkSize must be greater than 0, got %s", size)
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("reportingEnd %s must be after reportingStart %s", end, start)
+	}
+	var chunks []reportChunk
+	for chunkStart := start; chunkStart.Before(end); chunkStart = chunkStart.Add(size) {
+		chunkEnd := chunkStart.Add(size)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+		chunks = append(chunks, reportChunk{start: chunkStart, end: chunkEnd})
+	}
+	return chunks, nil
+}
+
+// generateReportChunks runs the ReportGenerationQuery for each chunk of the
+// Report's reporting period which hasn't completed yet, appending the
+// results to the Report's table and recording each completed chunk in
+// status.chunks using progress. The Report must have status.chunks set.
+//
+// The results of a chunk are stored before it's recorded as completed, and
+// can't be removed from the table on their own, so if recording it failed,
+// running the chunk again would store it's results twice. Instead, the rows
+// in the table are compared to the rows once the last chunk was recorded as
+// completed, and the chunk is recorded without being run again if the table
+// has more. Reports which didn't record their rows can't be checked.
+func (op *Reporting) generateReportChunks(ctx context.Context, logger log.FieldLogger, progress *reportProgressTracker, report *cbTypes.Report, chunks []reportChunk, genQuery *cbTypes.ReportGenerationQuery, queryDependencies *reporting.ReportGenerationQueryDependencies) error {
+	next := report.Status.Chunks.Completed
+	if next < len(chunks) && report.Status.Chunks.CompletedRows != nil {
+		rows, err := op.reportResultsRepo.CountReportResults(ctx, report.Status.TableName)
+		if err != nil {
+			return reasonErrorf(err, cbutil.PrestoErrorReason, "failed to count the rows of table %s before resuming at chunk %d/%d: %v", report.Status.TableName, next+1, len(chunks), err)
+		}
+		if rows > *report.Status.Chunks.CompletedRows {
+			logger.Infof("the results of chunk %d/%d were already stored, recording it as completed", next+1, len(chunks))
+			if err := recordReportChunk(progress, report, chunks, next, rows); err != nil {
+				return err
+			}
+			next++
+		}
+	}
+
+	for i := next; i < len(chunks); i++ {
+		chunk := chunks[i]
+		chunkLogger := logger.WithFields(log.Fields{
+			"chunk":      fmt.Sprintf("%d/%d", i+1, len(chunks)),
+			"chunkStart": chunk.start,
+			"chunkEnd":   chunk.end,
+		})
+		chunkLogger.Infof("generating Report chunk")
+		chunkCtx, span := tracing.StartSpan(ctx, "report.chunk",
+			tracing.Int64("metering.chunk", int64(i+1)),
+			tracing.String("metering.chunk.start", chunk.start.Format(time.RFC3339)),
+			tracing.String("metering.chunk.end", chunk.end.Format(time.RFC3339)),
+		)
+		err := op.reportGenerator.GenerateReport(
+			reporting.WithQueryPriority(chunkCtx, reportQueryPriority(reporting.ReportQueryClass, &chunk.start, &chunk.end)),
+			report.Status.TableName,
+			&chunk.start,
+			&chunk.end,
+			genQuery,
+			queryDependencies.DynamicReportGenerationQueries,
+			report.Spec.Inputs,
+			false,
+		)
+		span.SetError(err)
+		span.End()
+		if err != nil {
+			return reasonErrorf(err, cbutil.PrestoErrorReason, "failed to generate chunk %d/%d (%s to %s): %v", i+1, len(chunks), chunk.start, chunk.end, err)
+		}
+
+		rows, err := op.reportResultsRepo.CountReportResults(ctx, report.Status.TableName)
+		if err != nil {
+			return reasonErrorf(err, cbutil.PrestoErrorReason, "failed to count the rows of table %s after chunk %d/%d: %v", report.Status.TableName, i+1, len(chunks), err)
+		}
+		if err := recordReportChunk(progress, report, chunks, i, rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordReportChunk records chunks[i] as completed using progress, along with
+// the number of rows in the Report's table once it completed.
+func recordReportChunk(progress *reportProgressTracker, report *cbTypes.Report, chunks []reportChunk, i int, rows int64) error {
+	err := progress.update(func(report *cbTypes.Report) {
+		report.Status.Chunks.Completed = i + 1
+		report.Status.Chunks.CompletedUntil = &metav1.Time{Time: chunks[i].end}
+		report.Status.Chunks.CompletedRows = &rows
+		report.Status.Chunks.Failures = 0
+		report.Status.Progress.PercentComplete = percentComplete(report.Status.Chunks)
+		report.Status.Progress.RowsWritten = &rows
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update report %s status.chunks: %v", report.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package operator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/fake"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+)
+
+func TestReportChunks(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2019, time.January, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	chunks, err := reportChunks(day(1), day(4), 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []reportChunk{
+		{start: day(1), end: day(2)},
+		{start: day(2), end: day(3)},
+		{start: day(3), end: day(4)},
+	}, chunks)
+
+	chunks, err = reportChunks(day(1), day(6), 48*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []reportChunk{
+		{start: day(1), end: day(3)},
+		{start: day(3), end: day(5)},
+		{start: day(5), end: day(6)},
+	}, chunks, "expected the last chunk to end at reportingEnd")
+
+	_, err = reportChunks(day(1), day(4), 0)
+	assert.Error(t, err, "expected an error with a chunkSize of 0")
+	_, err = reportChunks(day(4), day(1), time.Hour)
+	assert.Error(t, err, "expected an error with reportingEnd before reportingStart")
+}
+
+// fakeChunkTable stores a row for each chunk generated, and counts them.
+// Calling any other method panics.
+type fakeChunkTable struct {
+	reporting.ReportGenerator
+	prestostore.ReportResultsRepo
+	chunkStarts []time.Time
+}
+
+func (f *fakeChunkTable) GenerateReport(ctx context.Context, tableName string, reportStart, reportEnd *time.Time, generationQuery *cbTypes.ReportGenerationQuery, dynamicReportGenerationQueries []*cbTypes.ReportGenerationQuery, inputs []cbTypes.ReportGenerationQueryInputValue, deleteExistingData bool) error {
+	f.chunkStarts = append(f.chunkStarts, *reportStart)
+	return nil
+}
+
+func (f *fakeChunkTable) CountReportResults(ctx context.Context, tableName string) (int64, error) {
+	return int64(len(f.chunkStarts)), nil
+}
+
+func TestGenerateReportChunksResumesIdempotently(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2019, time.January, d, 0, 0, 0, 0, time.UTC)
+	}
+	chunks, err := reportChunks(day(1), day(4), 24*time.Hour)
+	require.NoError(t, err)
+	oneRow := int64(1)
+
+	for _, tt := range []struct {
+		name string
+		// stored are the chunks whose results are already in the table
+		stored        []time.Time
+		completedRows *int64
+		expected      []time.Time
+	}{
+		{
+			name:          "chunk stored and recorded",
+			stored:        []time.Time{day(1)},
+			completedRows: &oneRow,
+			expected:      []time.Time{day(1), day(2), day(3)},
+		},
+		{
+			name:          "chunk stored without being recorded",
+			stored:        []time.Time{day(1), day(2)},
+			completedRows: &oneRow,
+			expected:      []time.Time{day(1), day(2), day(3)},
+		},
+		{
+			name:     "rows not recorded",
+			stored:   []time.Time{day(1), day(2)},
+			expected: []time.Time{day(1), day(2), day(2), day(3)},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &cbTypes.Report{
+				ObjectMeta: metav1.ObjectMeta{Name: "cpu", Namespace: "default"},
+				Status: cbTypes.ReportStatus{
+					TableName: "report_default_cpu",
+					Chunks:    &cbTypes.ReportChunksStatus{Total: len(chunks), Completed: 1, CompletedRows: tt.completedRows},
+				},
+			}
+			table := &fakeChunkTable{chunkStarts: tt.stored}
+			op := &Reporting{
+				logger:            logrus.New(),
+				clock:             clock.NewFakeClock(day(5)),
+				meteringClient:    fake.NewSimpleClientset(report.DeepCopy()),
+				reportGenerator:   table,
+				reportResultsRepo: table,
+			}
+			progress := op.startReportProgress(op.logger, report, day(5))
+			err := op.generateReportChunks(context.Background(), op.logger, progress, report, chunks, &cbTypes.ReportGenerationQuery{}, &reporting.ReportGenerationQueryDependencies{})
+			report = progress.stop()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, table.chunkStarts)
+			assert.Equal(t, len(chunks), report.Status.Chunks.Completed)
+			require.NotNil(t, report.Status.Chunks.CompletedRows)
+			assert.Equal(t, int64(len(tt.expected)), *report.Status.Chunks.CompletedRows)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+)
+
+const (
+	// reportPreviewRowLimit is the maximum number of rows returned when
+	// previewing a ReportGenerationQuery.
+	reportPreviewRowLimit = 10
+
+	// reportPreviewQueryName is the name given to a ReportGenerationQuery
+	// previewed from its spec, since it hasn't been created yet.
+	reportPreviewQueryName = "preview"
+)
+
+type PreviewReportGenerationQueryRequest struct {
+	// Name is the name of an existing ReportGenerationQuery to preview.
+	Name string `json:"name,omitempty"`
+	// Namespace is the namespace of the ReportGenerationQuery, defaulting to
+	// the namespace reporting-operator is running in.
+	Namespace string `json:"namespace,omitempty"`
+	// Spec is the spec of a ReportGenerationQuery to preview instead of an
+	// existing one, allowing queries to be tested before being created.
+	Spec *cbTypes.ReportGenerationQuerySpec `json:"spec,omitempty"`
+	// ReportingStart and ReportingEnd are the period of the hypothetical
+	// Report the query is rendered for.
+	ReportingStart time.Time                                `json:"reportingStart"`
+	ReportingEnd   time.Time                                `json:"reportingEnd"`
+	Inputs         cbTypes.ReportGenerationQueryInputValues `json:"inputs,omitempty"`
+	// PricingPolicy is the name of the PricingPolicy whose rates are
+	// available to the query, defaulting to the namespace's default
+	// PricingPolicy.
+	PricingPolicy string `json:"pricingPolicy,omitempty"`
+}
+
+func (req *PreviewReportGenerationQueryRequest) validate() error {
+	if req.Name == "" && req.Spec == nil {
+		return fmt.Errorf("either name or spec must be set")
+	}
+	if req.Name != "" && req.Spec != nil {
+		return fmt.Errorf("name and spec cannot both be set")
+	}
+	if req.ReportingStart.IsZero() || req.ReportingEnd.IsZero() {
+		return fmt.Errorf("reportingStart and reportingEnd must be set")
+	}
+	if !req.ReportingStart.Before(req.ReportingEnd) {
+		return fmt.Errorf("reportingStart must be before reportingEnd")
+	}
+	return nil
+}
+
+// previewReportGenerationQueryHandler renders a ReportGenerationQuery for a
+// hypothetical reporting period and executes it against the real tables,
+// returning the rendered query and a sample of the rows with their column
+// types. Nothing is stored.
+func (op *Reporting) previewReportGenerationQueryHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(op.logger, r, op.rand)
+	if r.Method != "POST" {
+		writeErrorResponse(logger, w, r, http.StatusMethodNotAllowed, "only POST is supported")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	var req PreviewReportGenerationQueryRequest
+	err := decoder.Decode(&req)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to decode request as JSON: %v", err)
+		return
+	}
+	if req.Namespace == "" {
+		req.Namespace = op.cfg.Namespace
+	}
+	req.ReportingStart = req.ReportingStart.UTC()
+	req.ReportingEnd = req.ReportingEnd.UTC()
+	if err := req.validate(); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if !op.cfg.isWatchedNamespace(req.Namespace) {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "namespace %s is not watched by reporting-operator", req.Namespace)
+		return
+	}
+	// a query given in the request can read any table, so previewing it
+	// requires the same access as creating a ReportGenerationQuery
+	verb := "get"
+	if req.Spec != nil {
+		verb = "create"
+	}
+	if !op.resultsAuthorizer.authorizeRequest(logger, w, r, verb, resultsResourceReportGenerationQueries, req.Namespace) {
+		return
+	}
+
+	var genQuery *cbTypes.ReportGenerationQuery
+	if req.Spec != nil {
+		genQuery = &cbTypes.ReportGenerationQuery{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      reportPreviewQueryName,
+				Namespace: req.Namespace,
+			},
+			Spec: *req.Spec,
+		}
+	} else {
+		genQuery, err = op.reportGenerationQueryLister.ReportGenerationQueries(req.Namespace).Get(req.Name)
+		if apierrors.IsNotFound(err) {
+			writeErrorResponse(logger, w, r, http.StatusNotFound, "ReportGenerationQuery %s does not exist", req.Name)
+			return
+		}
+		if err != nil {
+			writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to get ReportGenerationQuery %s: %v", req.Name, err)
+			return
+		}
+	}
+
+	queryDependencies, err := reporting.GetAndValidateGenerationQueryDependencies(
+		reporting.NewReportGenerationQueryListerGetter(op.reportGenerationQueryLister),
+		reporting.NewReportDataSourceListerGetter(op.reportDataSourceLister),
+		reporting.NewReportListerGetter(op.reportLister),
+		reporting.NewScheduledReportListerGetter(op.scheduledReportLister),
+		genQuery,
+		op.uninitialiedDependendenciesHandler(),
+	)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to preview ReportGenerationQuery %s, failed to validate dependencies: %v", genQuery.Name, err)
+		return
+	}
+
+	pricing, err := op.getPricingPolicy(req.Namespace, req.PricingPolicy)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to preview ReportGenerationQuery %s, invalid pricingPolicy: %v", genQuery.Name, err)
+		return
+	}
+
+	// the query is cancelled if the client disconnects
+	ctx, cancel := op.withReportQueryTimeout(r.Context())
+	defer cancel()
+	ctx = reporting.WithQueryPriority(ctx, reportQueryPriority(reporting.AdHocQueryClass, &req.ReportingStart, &req.ReportingEnd))
+	ctx = reporting.WithPricing(ctx, pricing)
+	preview, err := op.reportGenerator.PreviewReport(
+		ctx,
+		&req.ReportingStart,
+		&req.ReportingEnd,
+		genQuery,
+		queryDependencies.DynamicReportGenerationQueries,
+		req.Inputs,
+		reportPreviewRowLimit,
+	)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to preview ReportGenerationQuery %s: %v", genQuery.Name, err)
+		return
+	}
+
+	writeResponseAsJSON(logger, w, http.StatusOK, preview)
+}
@@ -0,0 +1,218 @@
+package operator
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+	mockprestostore "github.com/operator-framework/operator-metering/pkg/operator/prestostore/mock"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// newTestReportQueryOperator returns a Reporting watching the team-a and
+// team-b namespaces, whose pod-cpu ReportGenerationQuery in team-a, and
+// default PricingPolicy in the operator's namespace, can be used by the
+// report query handlers.
+func newTestReportQueryOperator(t *testing.T, reportResultsRepo *mockprestostore.MockReportResultsRepo) (*Reporting, *fakeAccessReviews) {
+	fakeClock := clock.NewFakeClock(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC))
+	authorizer, _, accessReviews := newTestResultsAuthorizer(fakeClock)
+
+	queries := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, queries.Add(&cbTypes.ReportGenerationQuery{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-cpu", Namespace: "team-a"},
+		Spec: cbTypes.ReportGenerationQuerySpec{
+			Query: `SELECT timestamp '{| .Report.ReportingStart | prestoTimestamp |}', timestamp '{| .Report.ReportingEnd | prestoTimestamp |}', {| quoteString .Report.Inputs.Team |}`,
+			Inputs: []cbTypes.ReportGenerationQueryInputDefinition{
+				{Name: "Team", Required: true},
+			},
+			Columns: []cbTypes.ReportGenerationQueryColumn{{Name: "team", Type: "varchar"}},
+		},
+	}))
+	cpuCoreHour := 0.5
+	pricingPolicies := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, pricingPolicies.Add(&cbTypes.PricingPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Namespace:   "metering",
+			Annotations: map[string]string{cbTypes.IsDefaultPricingPolicyAnnotation: "true"},
+		},
+		Spec: cbTypes.PricingPolicySpec{Rates: cbTypes.PricingRates{CPUCoreHour: &cpuCoreHour}},
+	}))
+
+	op := &Reporting{
+		logger: testLogger,
+		rand:   testRand,
+		cfg: Config{
+			Namespace:        "metering",
+			TargetNamespaces: []string{"team-a", "team-b"},
+		},
+		resultsAuthorizer:           authorizer,
+		reportGenerator:             reporting.NewReportGenerator(testLogger, reportResultsRepo, true, nil, reporting.DefaultCurrency, ""),
+		reportGenerationQueryLister: listers.NewReportGenerationQueryLister(queries),
+		reportDataSourceLister:      listers.NewReportDataSourceLister(cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})),
+		reportLister:                listers.NewReportLister(cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})),
+		scheduledReportLister:       listers.NewScheduledReportLister(cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})),
+		pricingPolicyLister:         listers.NewPricingPolicyLister(pricingPolicies),
+	}
+	return op, accessReviews
+}
+
+func TestPreviewReportGenerationQueryHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reportResultsRepo := mockprestostore.NewMockReportResultsRepo(ctrl)
+	op, accessReviews := newTestReportQueryOperator(t, reportResultsRepo)
+
+	preview := func(method, token, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/api/v1/reportgenerationqueries/preview", strings.NewReader(body))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		op.previewReportGenerationQueryHandler(w, r)
+		return w
+	}
+
+	// an existing query is rendered for the reporting period and it's inputs
+	columns := []presto.Column{{Name: "team", Type: "varchar"}}
+	results := []presto.Row{{"team": "a"}}
+	reportResultsRepo.EXPECT().PreviewReportResults(
+		gomock.Any(),
+		"SELECT timestamp '2019-01-01 00:00:00.000', timestamp '2019-01-02 00:00:00.000', 'a'",
+		reportPreviewRowLimit,
+	).Return(columns, results, nil)
+	w := preview("POST", "alice-token", `{"name": "pod-cpu", "namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z", "inputs": [{"name": "Team", "value": "a"}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp reporting.ReportPreview
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, reporting.ReportPreview{
+		Query:   "SELECT timestamp '2019-01-01 00:00:00.000', timestamp '2019-01-02 00:00:00.000', 'a'",
+		Columns: columns,
+		Results: []presto.Row{{"team": "a"}},
+	}, resp)
+	assert.Equal(t, "get", accessReviews.reviews[len(accessReviews.reviews)-1].ResourceAttributes.Verb)
+
+	// a query given in the request uses the default PricingPolicy, and
+	// requires access to create ReportGenerationQueries
+	reportResultsRepo.EXPECT().PreviewReportResults(gomock.Any(), "SELECT CAST(0.5 AS DOUBLE)", reportPreviewRowLimit).Return(columns, nil, nil)
+	w = preview("POST", "alice-token", `{"spec": {"query": "SELECT {| pricingRate \"cpuCoreHour\" |}"}, "namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "create", accessReviews.reviews[len(accessReviews.reviews)-1].ResourceAttributes.Verb)
+
+	reportResultsRepo.EXPECT().PreviewReportResults(gomock.Any(), "SELECT 1", reportPreviewRowLimit).Return(nil, nil, errors.New("Table not found"))
+	w = preview("POST", "alice-token", `{"spec": {"query": "SELECT 1"}, "namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z"}`)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Table not found")
+
+	for _, tt := range []struct {
+		name, method, token, body string
+		expectedCode              int
+	}{
+		{
+			name:         "only POST is supported",
+			method:       "GET",
+			token:        "alice-token",
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:         "invalid JSON",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"name": `,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "neither name nor spec",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "both name and spec",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"name": "pod-cpu", "spec": {"query": "SELECT 1"}, "namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "missing reporting period",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"name": "pod-cpu", "namespace": "team-a"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "reportingStart after reportingEnd",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"name": "pod-cpu", "namespace": "team-a", "reportingStart": "2019-01-02T00:00:00Z", "reportingEnd": "2019-01-01T00:00:00Z"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "the namespace defaults to the operator's namespace, which isn't watched",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"name": "pod-cpu", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unauthenticated",
+			method:       "POST",
+			body:         `{"name": "pod-cpu", "namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z"}`,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "unauthorized namespace",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"spec": {"query": "SELECT 1"}, "namespace": "team-b", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z"}`,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "missing ReportGenerationQuery",
+			method:       "POST",
+			token:        "bob-token",
+			body:         `{"name": "pod-cpu", "namespace": "team-b", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z"}`,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "missing dependency",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"spec": {"query": "SELECT 1", "reportQueries": ["missing"]}, "namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "missing PricingPolicy",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"spec": {"query": "SELECT 1"}, "namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z", "pricingPolicy": "missing"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid template",
+			method:       "POST",
+			token:        "alice-token",
+			body:         `{"spec": {"query": "SELECT {|"}, "namespace": "team-a", "reportingStart": "2019-01-01T00:00:00Z", "reportingEnd": "2019-01-02T00:00:00Z"}`,
+			expectedCode: http.StatusInternalServerError,
+		},
+	} {
+		w := preview(tt.method, tt.token, tt.body)
+		assert.Equal(t, tt.expectedCode, w.Code, "unexpected status for %s: %s", tt.name, w.Body.String())
+	}
+}
@@ -0,0 +1,113 @@
+package operator
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+// reportProgressInterval is how often status.progress of a Report is updated
+// while it's being generated.
+const reportProgressInterval = 30 * time.Second
+
+// reportProgressTracker records the progress of a Report being generated in
+// it's status.progress. It's the only thing updating the Report while it's
+// running, so updates made by the Report's generation and the periodic
+// progress updates don't conflict.
+type reportProgressTracker struct {
+	op     *Reporting
+	logger log.FieldLogger
+	start  time.Time
+
+	mu     sync.Mutex
+	report *cbTypes.Report
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// startReportProgress starts periodically updating the progress of report,
+// which started being generated at start. stop must be called once the
+// report's generation finishes.
+func (op *Reporting) startReportProgress(logger log.FieldLogger, report *cbTypes.Report, start time.Time) *reportProgressTracker {
+	report.Status.Progress = &cbTypes.ReportProgress{
+		StartTime: metav1.Time{Time: start},
+	}
+	if report.Status.Chunks != nil {
+		report.Status.Progress.PercentComplete = percentComplete(report.Status.Chunks)
+	}
+	t := &reportProgressTracker{
+		op:     op,
+		logger: logger,
+		start:  start,
+		report: report,
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	if err := t.update(nil); err != nil {
+		logger.WithError(err).Warnf("unable to update report status.progress")
+	}
+	go t.run()
+	return t
+}
+
+func (t *reportProgressTracker) run() {
+	defer close(t.doneCh)
+	timer := t.op.clock.NewTimer(reportProgressInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-t.stopCh:
+			return
+		case <-timer.C():
+			if err := t.update(nil); err != nil {
+				t.logger.WithError(err).Warnf("unable to update report status.progress")
+			}
+			timer.Reset(reportProgressInterval)
+		}
+	}
+}
+
+// update applies mutate to the Report, if it's not nil, and updates the
+// Report along with it's elapsed time.
+func (t *reportProgressTracker) update(mutate func(report *cbTypes.Report)) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if mutate != nil {
+		mutate(t.report)
+	}
+	now := t.op.clock.Now()
+	t.report.Status.Progress.LastUpdateTime = metav1.Time{Time: now}
+	t.report.Status.Progress.Elapsed = metav1.Duration{Duration: now.Sub(t.start).Round(time.Second)}
+	newReport, err := t.op.meteringClient.MeteringV1alpha1().Reports(t.report.Namespace).Update(t.report)
+	if err != nil {
+		return err
+	}
+	t.report = newReport
+	return nil
+}
+
+// stop stops the periodic progress updates, returning the latest version of
+// the Report.
+func (t *reportProgressTracker) stop() *cbTypes.Report {
+	close(t.stopCh)
+	<-t.doneCh
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.op.clock.Now()
+	t.report.Status.Progress.LastUpdateTime = metav1.Time{Time: now}
+	t.report.Status.Progress.Elapsed = metav1.Duration{Duration: now.Sub(t.start).Round(time.Second)}
+	return t.report
+}
+
+func percentComplete(chunks *cbTypes.ReportChunksStatus) *int {
+	if chunks.Total == 0 {
+		return nil
+	}
+	percent := chunks.Completed * 100 / chunks.Total
+	return &percent
+}
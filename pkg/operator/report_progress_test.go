@@ -0,0 +1,75 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/fake"
+)
+
+func TestReportProgressTracker(t *testing.T) {
+	start := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(start)
+	report := &cbTypes.Report{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Status: cbTypes.ReportStatus{
+			Chunks: &cbTypes.ReportChunksStatus{Total: 4, Completed: 1},
+		},
+	}
+	client := fake.NewSimpleClientset(report.DeepCopy())
+	op := &Reporting{
+		logger:         logrus.New(),
+		clock:          fakeClock,
+		meteringClient: client,
+	}
+
+	getProgress := func() *cbTypes.ReportProgress {
+		r, err := client.MeteringV1alpha1().Reports("default").Get("test", metav1.GetOptions{})
+		require.NoError(t, err)
+		return r.Status.Progress
+	}
+	waitForUpdate := func(at time.Time) *cbTypes.ReportProgress {
+		for i := 0; i < 100; i++ {
+			if progress := getProgress(); progress != nil && progress.LastUpdateTime.Time.Equal(at) {
+				return progress
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("expected status.progress to be updated at %s", at)
+		return nil
+	}
+
+	tracker := op.startReportProgress(op.logger, report, start)
+	progress := waitForUpdate(start)
+	require.NotNil(t, progress.PercentComplete)
+	assert.Equal(t, 25, *progress.PercentComplete, "expected resumed chunks to count as complete")
+
+	for i := 0; i < 100 && !fakeClock.HasWaiters(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	fakeClock.Step(reportProgressInterval)
+	progress = waitForUpdate(start.Add(reportProgressInterval))
+	assert.Equal(t, reportProgressInterval, progress.Elapsed.Duration, "expected the elapsed time to be updated periodically")
+
+	rows := int64(10)
+	require.NoError(t, tracker.update(func(report *cbTypes.Report) {
+		report.Status.Chunks.Completed = 2
+		report.Status.Progress.PercentComplete = percentComplete(report.Status.Chunks)
+		report.Status.Progress.RowsWritten = &rows
+	}))
+	progress = getProgress()
+	assert.Equal(t, 50, *progress.PercentComplete)
+	assert.Equal(t, rows, *progress.RowsWritten)
+
+	fakeClock.Step(time.Minute)
+	finished := tracker.stop()
+	assert.Equal(t, 2, finished.Status.Chunks.Completed)
+	assert.Equal(t, reportProgressInterval+time.Minute, finished.Status.Progress.Elapsed.Duration)
+}
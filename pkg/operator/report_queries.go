@@ -0,0 +1,131 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/db"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+	"github.com/operator-framework/operator-metering/pkg/tracing"
+)
+
+const (
+	DefaultReportQueryTimeout = time.Hour
+)
+
+// withReportQueryTimeout returns a context derived from parent which is
+// cancelled once the configured ReportQueryTimeout has elapsed.
+func (op *Reporting) withReportQueryTimeout(parent context.Context) (context.Context, context.CancelFunc) {
+	if timeout := op.config().ReportQueryTimeout; timeout > 0 {
+		return context.WithTimeout(parent, timeout)
+	}
+	return context.WithCancel(parent)
+}
+
+// newReportQueryContext returns a context for the queries generating the
+// Report or ScheduledReport identified by kind and key. It's cancelled when
+// the configured ReportQueryTimeout elapses, when the ShutdownDrainTimeout
+// elapses after the operator begins shutting down, or when
+// cancelReportQueries is called with the same kind and key. The returned
+// CancelFunc must be called once the queries are finished. Queries run using
+// the context are logged with logger, and traced as part of a new trace if
+// tracing is enabled.
+func (op *Reporting) newReportQueryContext(logger log.FieldLogger, kind, key string) (context.Context, context.CancelFunc) {
+	ctx, span := op.tracer.Start(op.workCtx, strings.ToLower(kind)+".generate",
+		tracing.String("metering.kind", kind),
+		tracing.String("metering.key", key),
+	)
+	if traceID := tracing.TraceIDFromContext(ctx); traceID != "" {
+		logger = logger.WithField("traceID", traceID)
+	}
+	ctx, cancel := op.withReportQueryTimeout(db.WithLogger(ctx, logger))
+
+	op.reportQueryCancelsMu.Lock()
+	defer op.reportQueryCancelsMu.Unlock()
+	// each key is only processed by a single worker at a time, so there's no
+	// existing context to replace.
+	op.reportQueryCancels[kind+"/"+key] = cancel
+	return ctx, func() {
+		op.reportQueryCancelsMu.Lock()
+		delete(op.reportQueryCancels, kind+"/"+key)
+		op.reportQueryCancelsMu.Unlock()
+		cancel()
+		span.End()
+	}
+}
+
+// queriesCancelledByShutdown returns whether in-flight queries have been
+// cancelled because the ShutdownDrainTimeout elapsed while the operator was
+// shutting down.
+func (op *Reporting) queriesCancelledByShutdown() bool {
+	return op.workCtx.Err() != nil
+}
+
+// cancelReportQueries cancels the queries running for the Report or
+// ScheduledReport identified by kind and key, if any.
+func (op *Reporting) cancelReportQueries(kind, key string) {
+	op.reportQueryCancelsMu.Lock()
+	defer op.reportQueryCancelsMu.Unlock()
+	if cancel, ok := op.reportQueryCancels[kind+"/"+key]; ok {
+		op.logger.Infof("cancelling queries for %s %s", kind, key)
+		cancel()
+		delete(op.reportQueryCancels, kind+"/"+key)
+	}
+}
+
+// acquireReportSlot blocks until fewer than MaxConcurrentReports Reports and
+// ScheduledReports are being generated, or the operator shuts down. While
+// it's blocked, other reports wait in their work queue in order. The returned
+// function must be called once the report is generated.
+func (op *Reporting) acquireReportSlot(logger log.FieldLogger) (release func(), err error) {
+	if op.reportSemaphore == nil {
+		return func() {}, nil
+	}
+	select {
+	case op.reportSemaphore <- struct{}{}:
+		return func() { <-op.reportSemaphore }, nil
+	default:
+	}
+
+	logger.Infof("waiting for one of the %d running reports to finish", cap(op.reportSemaphore))
+	select {
+	case op.reportSemaphore <- struct{}{}:
+		return func() { <-op.reportSemaphore }, nil
+	case <-op.shutdownCtx.Done():
+		return nil, op.shutdownCtx.Err()
+	}
+}
+
+// reportQueryPriority returns the priority of the queries of class covering
+// the reporting period between start and end, either of which may be nil.
+func reportQueryPriority(class reporting.QueryClass, start, end *time.Time) reporting.QueryPriority {
+	priority := reporting.QueryPriority{Class: class}
+	if start != nil && end != nil {
+		priority.Period = end.Sub(*start)
+	}
+	return priority
+}
+
+// reportExecutionLimits returns the ExecutionLimits for the maxRuntime and
+// maxResultRows of a Report or ScheduledReport, either of which may be nil.
+func reportExecutionLimits(maxRuntime *metav1.Duration, maxResultRows *int64) (reporting.ExecutionLimits, error) {
+	var limits reporting.ExecutionLimits
+	if maxRuntime != nil {
+		if maxRuntime.Duration <= 0 {
+			return limits, fmt.Errorf("maxRuntime must be greater than 0, got %s", maxRuntime.Duration)
+		}
+		limits.MaxRuntime = maxRuntime.Duration
+	}
+	if maxResultRows != nil {
+		if *maxResultRows <= 0 {
+			return limits, fmt.Errorf("maxResultRows must be greater than 0, got %d", *maxResultRows)
+		}
+		limits.MaxResultRows = *maxResultRows
+	}
+	return limits, nil
+}
@@ -0,0 +1,79 @@
+package operator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportQueryContext(t *testing.T) {
+	workCtx, cancelWork := context.WithCancel(context.Background())
+	defer cancelWork()
+	op := &Reporting{
+		cfg:                Config{ReportQueryTimeout: time.Hour},
+		logger:             logrus.New(),
+		workCtx:            workCtx,
+		reportQueryCancels: make(map[string]context.CancelFunc),
+	}
+
+	reportCtx, reportCancel := op.newReportQueryContext(testLogger, "Report", "default/test")
+	defer reportCancel()
+	scheduledCtx, scheduledCancel := op.newReportQueryContext(testLogger, "ScheduledReport", "default/test")
+	defer scheduledCancel()
+	_, hasDeadline := reportCtx.Deadline()
+	assert.True(t, hasDeadline, "expected the report query timeout to be used as the deadline")
+
+	op.cancelReportQueries("Report", "default/test")
+	assert.Equal(t, context.Canceled, reportCtx.Err(), "expected the Report's queries to be cancelled")
+	assert.NoError(t, scheduledCtx.Err(), "expected the ScheduledReport with the same name to be unaffected")
+
+	assert.False(t, op.queriesCancelledByShutdown())
+	cancelWork()
+	assert.Equal(t, context.Canceled, scheduledCtx.Err(), "expected queries to be cancelled once the shutdown drain timeout elapses")
+	assert.True(t, op.queriesCancelledByShutdown())
+
+	scheduledCancel()
+	assert.Empty(t, op.reportQueryCancels)
+}
+
+func TestAcquireReportSlot(t *testing.T) {
+	shutdownCtx, shutdown := context.WithCancel(context.Background())
+	defer shutdown()
+	op := &Reporting{
+		logger:          logrus.New(),
+		shutdownCtx:     shutdownCtx,
+		reportSemaphore: make(chan struct{}, 1),
+	}
+
+	release, err := op.acquireReportSlot(op.logger)
+	assert.NoError(t, err)
+
+	acquired := make(chan error)
+	go func() {
+		release, err := op.acquireReportSlot(op.logger)
+		if err == nil {
+			release()
+		}
+		acquired <- err
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("expected the second report to wait for the first to finish")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	assert.NoError(t, <-acquired)
+
+	release, err = op.acquireReportSlot(op.logger)
+	assert.NoError(t, err)
+	defer release()
+	go func() {
+		_, err := op.acquireReportSlot(op.logger)
+		acquired <- err
+	}()
+	shutdown()
+	assert.Equal(t, context.Canceled, <-acquired, "expected waiting reports to stop waiting on shutdown")
+}
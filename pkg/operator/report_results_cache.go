@@ -0,0 +1,155 @@
+package operator
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	DefaultReportResultsCacheMaxRows = 100000
+	DefaultReportResultsCacheTTL     = 5 * time.Minute
+)
+
+var reportResultsCacheRequestsCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: prometheusMetricNamespace,
+		Name:      "report_results_cache_requests_total",
+		Help:      "Number of requests for report results, by whether the results were cached.",
+	},
+	[]string{"result"},
+)
+
+func init() {
+	prometheus.MustRegister(reportResultsCacheRequestsCounter)
+}
+
+// reportResultsCache is an LRU cache of the results of finished Reports and
+// ScheduledReports, which prevents clients repeatedly downloading the same
+// results from querying Presto each time. Results are cached for each
+// resource along with it's resourceVersion, so re-running a report, which
+// always updates it's status, invalidates the cached results.
+type reportResultsCache struct {
+	clock   clock.Clock
+	ttl     time.Duration
+	maxRows int
+
+	mu sync.Mutex
+	// lru holds *reportResultsCacheEntry values, and is bounded by maxRows
+	// entries. Each entry's cost in rows is tracked in rows, and entries
+	// are evicted until it's no more than maxRows.
+	lru  *simplelru.LRU
+	rows int
+}
+
+type reportResultsCacheKey struct {
+	kind, namespace, name string
+}
+
+type reportResultsCacheEntry struct {
+	resourceVersion string
+	tableName       string
+	results         []presto.Row
+	expires         time.Time
+}
+
+// newReportResultsCache returns a reportResultsCache holding up to maxRows
+// rows in total, with each entry expiring after ttl. If maxRows or ttl is
+// 0, nil is returned, which disables caching.
+func newReportResultsCache(clock clock.Clock, maxRows int, ttl time.Duration) *reportResultsCache {
+	if maxRows <= 0 || ttl <= 0 {
+		return nil
+	}
+	c := &reportResultsCache{
+		clock:   clock,
+		ttl:     ttl,
+		maxRows: maxRows,
+	}
+	// every entry costs at least one row, so there can never be more than
+	// maxRows entries
+	c.lru, _ = simplelru.NewLRU(maxRows, func(_, value interface{}) {
+		c.rows -= entryRows(value.(*reportResultsCacheEntry))
+	})
+	return c
+}
+
+func entryRows(entry *reportResultsCacheEntry) int {
+	if len(entry.results) == 0 {
+		return 1
+	}
+	return len(entry.results)
+}
+
+// get returns the cached results of a resource's table, if they were cached
+// for the same resourceVersion and haven't expired.
+func (c *reportResultsCache) get(kind, namespace, name, resourceVersion, tableName string) ([]presto.Row, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := reportResultsCacheKey{kind: kind, namespace: namespace, name: name}
+	value, ok := c.lru.Get(key)
+	if !ok {
+		reportResultsCacheRequestsCounter.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+	entry := value.(*reportResultsCacheEntry)
+	if entry.resourceVersion != resourceVersion || entry.tableName != tableName || !c.clock.Now().Before(entry.expires) {
+		c.lru.Remove(key)
+		reportResultsCacheRequestsCounter.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+	reportResultsCacheRequestsCounter.WithLabelValues("hit").Inc()
+	return copyRows(entry.results), true
+}
+
+// add caches the results of a resource's table. Results with more rows than
+// the cache can hold aren't cached.
+func (c *reportResultsCache) add(kind, namespace, name, resourceVersion, tableName string, results []presto.Row) {
+	if c == nil {
+		return
+	}
+	if len(results) > c.maxRows {
+		return
+	}
+	entry := &reportResultsCacheEntry{
+		resourceVersion: resourceVersion,
+		tableName:       tableName,
+		results:         copyRows(results),
+		expires:         c.clock.Now().Add(c.ttl),
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := reportResultsCacheKey{kind: kind, namespace: namespace, name: name}
+	// remove any existing entry first, so it's rows are subtracted by the
+	// eviction callback
+	c.lru.Remove(key)
+	c.lru.Add(key, entry)
+	c.rows += entryRows(entry)
+	for c.rows > c.maxRows {
+		c.lru.RemoveOldest()
+	}
+}
+
+// copyRows returns a copy of rows, so rows returned from the cache can be
+// modified, for example to remove hidden columns, without modifying the
+// cached rows.
+func copyRows(rows []presto.Row) []presto.Row {
+	if rows == nil {
+		return nil
+	}
+	copied := make([]presto.Row, len(rows))
+	for i, row := range rows {
+		copied[i] = make(presto.Row, len(row))
+		for column, value := range row {
+			copied[i][column] = value
+		}
+	}
+	return copied
+}
@@ -0,0 +1,57 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func TestReportResultsCache(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC))
+	cache := newReportResultsCache(fakeClock, 4, time.Minute)
+	rows := []presto.Row{{"namespace": "a"}, {"namespace": "b"}}
+
+	_, ok := cache.get("Report", "metering", "report-1", "1", "report_1")
+	assert.False(t, ok, "expected empty cache to miss")
+
+	cache.add("Report", "metering", "report-1", "1", "report_1", rows)
+	results, ok := cache.get("Report", "metering", "report-1", "1", "report_1")
+	assert.True(t, ok, "expected cached results")
+	assert.Equal(t, rows, results)
+
+	// modifying returned rows doesn't modify the cache
+	delete(results[0], "namespace")
+	results, _ = cache.get("Report", "metering", "report-1", "1", "report_1")
+	assert.Equal(t, rows, results)
+
+	_, ok = cache.get("Report", "metering", "report-1", "2", "report_1")
+	assert.False(t, ok, "expected re-run report to miss")
+	_, ok = cache.get("Report", "metering", "report-1", "1", "report_1")
+	assert.False(t, ok, "expected results of the previous run to be invalidated")
+
+	cache.add("Report", "metering", "report-1", "2", "report_1", rows)
+	fakeClock.Step(time.Minute)
+	_, ok = cache.get("Report", "metering", "report-1", "2", "report_1")
+	assert.False(t, ok, "expected expired results to miss")
+
+	// the least recently used results are evicted when the rows exceed the limit
+	cache.add("Report", "metering", "report-1", "2", "report_1", rows)
+	cache.add("ScheduledReport", "metering", "report-1", "1", "scheduledreport_1", rows)
+	cache.get("Report", "metering", "report-1", "2", "report_1")
+	cache.add("Report", "metering", "report-2", "1", "report_2", rows[:1])
+	_, ok = cache.get("ScheduledReport", "metering", "report-1", "1", "scheduledreport_1")
+	assert.False(t, ok, "expected least recently used results to be evicted")
+	_, ok = cache.get("Report", "metering", "report-1", "2", "report_1")
+	assert.True(t, ok, "expected recently used results to be cached")
+
+	// results larger than the cache aren't cached
+	cache.add("Report", "metering", "report-3", "1", "report_3", append(append(rows, rows...), rows[0]))
+	_, ok = cache.get("Report", "metering", "report-3", "1", "report_3")
+	assert.False(t, ok, "expected results larger than the cache not to be cached")
+
+	assert.Nil(t, newReportResultsCache(fakeClock, 0, time.Minute), "expected 0 maxRows to disable caching")
+}
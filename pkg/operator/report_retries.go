@@ -0,0 +1,118 @@
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const (
+	defaultReportRetryBackoff    = time.Minute
+	defaultReportRetryMaxBackoff = time.Hour
+	maxReportAttemptHistory      = 10
+)
+
+// validateReportRetryPolicy returns an error if the Report's retryPolicy is
+// invalid.
+func validateReportRetryPolicy(policy *cbTypes.ReportRetryPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if policy.MaxAttempts < 1 {
+		return fmt.Errorf("retryPolicy.maxAttempts must be at least 1, got %d", policy.MaxAttempts)
+	}
+	if policy.Backoff != nil && policy.Backoff.Duration <= 0 {
+		return fmt.Errorf("retryPolicy.backoff must be greater than 0, got %s", policy.Backoff.Duration)
+	}
+	if policy.MaxBackoff != nil && policy.MaxBackoff.Duration <= 0 {
+		return fmt.Errorf("retryPolicy.maxBackoff must be greater than 0, got %s", policy.MaxBackoff.Duration)
+	}
+	return nil
+}
+
+// reportMaxAttempts returns the number of consecutive failed attempts after
+// which the Report is marked as failed.
+func reportMaxAttempts(report *cbTypes.Report) int {
+	if report.Spec.RetryPolicy != nil {
+		return report.Spec.RetryPolicy.MaxAttempts
+	}
+	if report.Spec.ChunkSize != nil {
+		return maxReportChunkFailures
+	}
+	return 1
+}
+
+// reportRetryBackoff returns how long to wait before retrying a Report after
+// it's failed attempts consecutive times, doubling the backoff after each
+// failure up to the maximum backoff.
+func reportRetryBackoff(policy *cbTypes.ReportRetryPolicy, failures int) time.Duration {
+	backoff, maxBackoff := defaultReportRetryBackoff, defaultReportRetryMaxBackoff
+	if policy != nil && policy.Backoff != nil {
+		backoff = policy.Backoff.Duration
+	}
+	if policy != nil && policy.MaxBackoff != nil {
+		maxBackoff = policy.MaxBackoff.Duration
+	}
+	for i := 1; i < failures && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+// recordReportAttempt adds an attempt to generate the Report which started
+// at start to it's status, keeping a limited history. err is nil if the
+// attempt succeeded.
+func (op *Reporting) recordReportAttempt(report *cbTypes.Report, start time.Time, reason string, err error) {
+	attempt := cbTypes.ReportAttempt{
+		StartTime:  metav1.Time{Time: start},
+		FinishTime: metav1.Time{Time: op.clock.Now()},
+	}
+	if err != nil {
+		attempt.Reason = reason
+		attempt.Message = err.Error()
+		report.Status.FailedAttempts++
+	}
+	report.Status.Attempts = append([]cbTypes.ReportAttempt{attempt}, report.Status.Attempts...)
+	if len(report.Status.Attempts) > maxReportAttemptHistory {
+		report.Status.Attempts = report.Status.Attempts[:maxReportAttemptHistory]
+	}
+}
+
+// retryReport schedules the Report to be generated again after it's backoff
+// if it hasn't used all of it's attempts, returning false if it has. Chunked
+// reports resume from their last completed chunk, and other reports start
+// over.
+func (op *Reporting) retryReport(logger log.FieldLogger, report *cbTypes.Report, err error) bool {
+	failures := report.Status.FailedAttempts
+	if report.Status.Chunks != nil {
+		report.Status.Chunks.Failures++
+		failures = report.Status.Chunks.Failures
+	}
+	maxAttempts := reportMaxAttempts(report)
+	if failures >= maxAttempts {
+		return false
+	}
+
+	backoff := reportRetryBackoff(report.Spec.RetryPolicy, failures)
+	if report.Status.Chunks == nil {
+		report.Status.Phase = cbTypes.ReportPhaseWaiting
+	}
+	report.Status.Output = err.Error()
+	report.Status.NextRetryTime = &metav1.Time{Time: op.clock.Now().Add(backoff)}
+	newReport, updateErr := op.meteringClient.MeteringV1alpha1().Reports(report.Namespace).Update(report)
+	if updateErr != nil {
+		logger.WithError(updateErr).Errorf("unable to update report status for retry")
+	} else {
+		report = newReport
+	}
+	logger.WithError(err).Warnf("attempt %d/%d to generate report failed, retrying in %s", failures, maxAttempts, backoff)
+	op.enqueueReportAfter(report, backoff)
+	return true
+}
@@ -0,0 +1,66 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestReportRetryBackoff(t *testing.T) {
+	tests := map[string]struct {
+		policy   *cbTypes.ReportRetryPolicy
+		failures int
+		expected time.Duration
+	}{
+		"default first retry": {
+			failures: 1,
+			expected: time.Minute,
+		},
+		"default doubles": {
+			failures: 3,
+			expected: 4 * time.Minute,
+		},
+		"default capped": {
+			failures: 10,
+			expected: time.Hour,
+		},
+		"custom backoff": {
+			policy:   &cbTypes.ReportRetryPolicy{MaxAttempts: 5, Backoff: &meta.Duration{Duration: 10 * time.Second}},
+			failures: 2,
+			expected: 20 * time.Second,
+		},
+		"custom max backoff": {
+			policy: &cbTypes.ReportRetryPolicy{
+				MaxAttempts: 5,
+				Backoff:     &meta.Duration{Duration: 10 * time.Second},
+				MaxBackoff:  &meta.Duration{Duration: 30 * time.Second},
+			},
+			failures: 4,
+			expected: 30 * time.Second,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, reportRetryBackoff(tt.policy, tt.failures))
+		})
+	}
+}
+
+func TestReportMaxAttempts(t *testing.T) {
+	report := &cbTypes.Report{}
+	assert.Equal(t, 1, reportMaxAttempts(report), "expected reports to be attempted once by default")
+
+	report.Spec.ChunkSize = &meta.Duration{Duration: time.Hour}
+	assert.Equal(t, maxReportChunkFailures, reportMaxAttempts(report), "expected chunked reports to be retried by default")
+
+	report.Spec.RetryPolicy = &cbTypes.ReportRetryPolicy{MaxAttempts: 3}
+	assert.Equal(t, 3, reportMaxAttempts(report))
+
+	assert.NoError(t, validateReportRetryPolicy(report.Spec.RetryPolicy))
+	assert.Error(t, validateReportRetryPolicy(&cbTypes.ReportRetryPolicy{}), "expected maxAttempts to be required")
+	assert.Error(t, validateReportRetryPolicy(&cbTypes.ReportRetryPolicy{MaxAttempts: 1, Backoff: &meta.Duration{}}), "expected a zero backoff to be invalid")
+}
@@ -0,0 +1,77 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/operator-framework/operator-metering/pkg/admission"
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+)
+
+// validateReportAdmission rejects Reports which would fail as soon as
+// they're processed, so users find out when they create the Report.
+func (op *Reporting) validateReportAdmission(req *admission.AdmissionRequest) *admission.AdmissionResponse {
+	if req.Operation != admission.Create && req.Operation != admission.Update {
+		return admission.Allowed()
+	}
+	var report cbTypes.Report
+	if err := json.Unmarshal(req.Object.Raw, &report); err != nil {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("unable to decode Report: %v", err))
+	}
+	// Reports in namespaces we don't watch aren't in our listers, and won't
+	// be processed.
+	if !op.cfg.isWatchedNamespace(req.Namespace) {
+		return admission.Allowed()
+	}
+	if req.Operation == admission.Update {
+		// only changes to the spec are validated, so the status of existing
+		// Reports can always be updated, and they can always be deleted.
+		var oldReport cbTypes.Report
+		if err := json.Unmarshal(req.OldObject.Raw, &oldReport); err != nil {
+			return admission.Errored(http.StatusBadRequest, fmt.Errorf("unable to decode old Report: %v", err))
+		}
+		if reflect.DeepEqual(report.Spec, oldReport.Spec) || report.DeletionTimestamp != nil {
+			return admission.Allowed()
+		}
+	}
+
+	if errs := validateReportSpec(&report, op.clock.Now(), op.reportGenerationQueryLister); len(errs) != 0 {
+		return admission.Denied(fmt.Sprintf("invalid Report %s: %s", report.Name, strings.Join(errs, ", ")))
+	}
+	return admission.Allowed()
+}
+
+// validateReportSpec returns the problems with the Report's spec which mean
+// it can't be generated.
+func validateReportSpec(report *cbTypes.Report, now time.Time, queryLister listers.ReportGenerationQueryLister) []string {
+	var errs []string
+	start, end := report.Spec.ReportingStart, report.Spec.ReportingEnd
+	if start != nil && end != nil && !end.After(start.Time) {
+		errs = append(errs, fmt.Sprintf("spec.reportingEnd (%s) must be after spec.reportingStart (%s)", end.Time.UTC(), start.Time.UTC()))
+	}
+	if start != nil && !start.Time.Before(now) {
+		errs = append(errs, fmt.Sprintf("spec.reportingStart (%s) is in the future, so there's no data to report on yet", start.Time.UTC()))
+	}
+
+	if report.Spec.GenerationQueryName == "" {
+		return append(errs, "spec.generationQuery must be set")
+	}
+	genQuery, err := queryLister.ReportGenerationQueries(report.Namespace).Get(report.Spec.GenerationQueryName)
+	if apierrors.IsNotFound(err) {
+		return append(errs, fmt.Sprintf("spec.generationQuery: ReportGenerationQuery %s does not exist", report.Spec.GenerationQueryName))
+	} else if err != nil {
+		return append(errs, fmt.Sprintf("unable to get ReportGenerationQuery %s: %v", report.Spec.GenerationQueryName, err))
+	}
+	if _, err := reporting.ValidateReportGenerationQueryInputs(genQuery, report.Spec.Inputs); err != nil {
+		errs = append(errs, fmt.Sprintf("spec.inputs: %v", err))
+	}
+	return errs
+}
@@ -0,0 +1,127 @@
+package operator
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/operator-framework/operator-metering/pkg/admission"
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+)
+
+func TestValidateReportAdmission(t *testing.T) {
+	now := time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC)
+	queryIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, queryIndexer.Add(&cbTypes.ReportGenerationQuery{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-cpu", Namespace: "metering"},
+		Spec: cbTypes.ReportGenerationQuerySpec{
+			Inputs: []cbTypes.ReportGenerationQueryInputDefinition{
+				{Name: "Node", Required: true},
+				{Name: "Limit", Type: cbTypes.ReportGenerationQueryInputTypeInteger},
+			},
+		},
+	}))
+	op := &Reporting{
+		cfg:                         Config{Namespace: "metering"},
+		logger:                      logrus.New(),
+		clock:                       clock.NewFakeClock(now),
+		reportGenerationQueryLister: listers.NewReportGenerationQueryLister(queryIndexer),
+	}
+	server := httptest.NewServer(op.newWebhookServer().Handler)
+	defer server.Close()
+
+	newReport := func(namespace string, start, end time.Time, query string, inputs ...cbTypes.ReportGenerationQueryInputValue) *cbTypes.Report {
+		return &cbTypes.Report{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: namespace},
+			Spec: cbTypes.ReportSpec{
+				ReportingStart:      &metav1.Time{Time: start},
+				ReportingEnd:        &metav1.Time{Time: end},
+				GenerationQueryName: query,
+				Inputs:              inputs,
+			},
+		}
+	}
+	review := func(op admission.Operation, report, oldReport *cbTypes.Report) *admission.AdmissionResponse {
+		req := &admission.AdmissionRequest{UID: "1234", Operation: op, Namespace: report.Namespace, Name: report.Name}
+		raw, err := json.Marshal(report)
+		require.NoError(t, err)
+		req.Object = runtime.RawExtension{Raw: raw}
+		if oldReport != nil {
+			raw, err = json.Marshal(oldReport)
+			require.NoError(t, err)
+			req.OldObject = runtime.RawExtension{Raw: raw}
+		}
+		body, err := json.Marshal(admission.AdmissionReview{Request: req})
+		require.NoError(t, err)
+		resp, err := http.Post(server.URL+ValidateReportWebhookEndpoint, "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var result admission.AdmissionReview
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		require.NotNil(t, result.Response)
+		assert.Equal(t, req.UID, result.Response.UID)
+		return result.Response
+	}
+
+	start := now.Add(-48 * time.Hour)
+	end := now.Add(-24 * time.Hour)
+	node := cbTypes.ReportGenerationQueryInputValue{Name: "Node", Value: "node-1"}
+	valid := newReport("metering", start, end, "pod-cpu", node)
+	assert.True(t, review(admission.Create, valid, nil).Allowed)
+
+	tests := map[string]struct {
+		report  *cbTypes.Report
+		message string
+	}{
+		"end before start": {
+			report:  newReport("metering", end, start, "pod-cpu", node),
+			message: "invalid Report test: spec.reportingEnd (2019-01-30 00:00:00 +0000 UTC) must be after spec.reportingStart (2019-01-31 00:00:00 +0000 UTC)",
+		},
+		"period in the future": {
+			report:  newReport("metering", now.Add(time.Hour), now.Add(2*time.Hour), "pod-cpu", node),
+			message: "invalid Report test: spec.reportingStart (2019-02-01 01:00:00 +0000 UTC) is in the future, so there's no data to report on yet",
+		},
+		"unknown generationQuery": {
+			report:  newReport("metering", start, end, "pod-memory"),
+			message: "invalid Report test: spec.generationQuery: ReportGenerationQuery pod-memory does not exist",
+		},
+		"missing required input": {
+			report:  newReport("metering", start, end, "pod-cpu", cbTypes.ReportGenerationQueryInputValue{Name: "Limit", Value: "ten"}),
+			message: "invalid Report test: spec.inputs: unable to validate ReportGenerationQuery pod-cpu inputs",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := review(admission.Create, tt.report, nil)
+			assert.False(t, resp.Allowed)
+			require.NotNil(t, resp.Result)
+			assert.Contains(t, resp.Result.Message, tt.message)
+			assert.Equal(t, metav1.StatusReasonInvalid, resp.Result.Reason)
+		})
+	}
+
+	// updates which don't change the spec, such as to the status, are
+	// always allowed
+	invalid := tests["unknown generationQuery"].report
+	updated := invalid.DeepCopy()
+	updated.Status.Phase = cbTypes.ReportPhaseError
+	assert.True(t, review(admission.Update, updated, invalid).Allowed)
+	updated.Spec.GenerationQueryName = "pod-memory-usage"
+	assert.False(t, review(admission.Update, updated, invalid).Allowed)
+
+	// reports in namespaces we don't watch aren't validated
+	assert.True(t, review(admission.Create, newReport("other", end, start, "pod-memory"), nil).Allowed)
+}
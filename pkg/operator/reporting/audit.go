@@ -0,0 +1,172 @@
+package reporting
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+	"github.com/pmezard/go-difflib/difflib"
+
+	metering "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+// GenerationQueryTexts holds the text of recently used
+// ReportGenerationQuery versions by their hash, so they can be diffed
+// without recording the text in the status of every report. It's safe for
+// concurrent use, and a nil GenerationQueryTexts holds nothing.
+type GenerationQueryTexts struct {
+	mu    sync.Mutex
+	texts *simplelru.LRU
+}
+
+// NewGenerationQueryTexts returns a GenerationQueryTexts holding the text of
+// up to size versions, evicting the least recently used.
+func NewGenerationQueryTexts(size int) (*GenerationQueryTexts, error) {
+	texts, err := simplelru.NewLRU(size, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &GenerationQueryTexts{texts: texts}, nil
+}
+
+// Add records the text of the current version of query, returning it's
+// hash.
+func (t *GenerationQueryTexts) Add(query *metering.ReportGenerationQuery) string {
+	// rollups are versioned by the query generated from them
+	queryText, err := GenerationQueryTemplate(query)
+	if err != nil {
+		queryText = query.Spec.Query
+	}
+	hash := hashQuery(queryText)
+	if t != nil {
+		t.mu.Lock()
+		t.texts.Add(hash, queryText)
+		t.mu.Unlock()
+	}
+	return hash
+}
+
+// Get returns the text of the version with the hash, if it's held.
+func (t *GenerationQueryTexts) Get(hash string) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	text, ok := t.texts.Get(hash)
+	if !ok {
+		return "", false
+	}
+	return text.(string), true
+}
+
+// GetGenerationQueryVersions returns the versions of the generationQuery and
+// all ReportGenerationQueries it depends on, sorted by name, recording the
+// text of each version in texts.
+func GetGenerationQueryVersions(generationQuery *metering.ReportGenerationQuery, deps *ReportGenerationQueryDependencies, texts *GenerationQueryTexts) []metering.ReportGenerationQueryVersion {
+	queries := map[string]*metering.ReportGenerationQuery{
+		generationQuery.Name: generationQuery,
+	}
+	if deps != nil {
+		for _, query := range deps.ReportGenerationQueries {
+			queries[query.Name] = query
+		}
+		for _, query := range deps.DynamicReportGenerationQueries {
+			queries[query.Name] = query
+		}
+	}
+
+	versions := make([]metering.ReportGenerationQueryVersion, 0, len(queries))
+	for _, query := range queries {
+		versions = append(versions, metering.ReportGenerationQueryVersion{
+			Name:            query.Name,
+			ResourceVersion: query.ResourceVersion,
+			QueryHash:       texts.Add(query),
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Name < versions[j].Name
+	})
+	return versions
+}
+
+func hashQuery(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerationQueryDiff describes how a ReportGenerationQuery changed between
+// two report runs.
+type GenerationQueryDiff struct {
+	Name                string `json:"name"`
+	FromResourceVersion string `json:"fromResourceVersion,omitempty"`
+	ToResourceVersion   string `json:"toResourceVersion,omitempty"`
+	FromQueryHash       string `json:"fromQueryHash,omitempty"`
+	ToQueryHash         string `json:"toQueryHash,omitempty"`
+	Changed             bool   `json:"changed"`
+	// Diff is a unified diff of the query text, empty if unchanged or if
+	// DiffUnavailable.
+	Diff string `json:"diff,omitempty"`
+	// DiffUnavailable is true if the query changed, but the text of either
+	// version is no longer held, so it can't be diffed.
+	DiffUnavailable bool `json:"diffUnavailable,omitempty"`
+}
+
+// DiffGenerationQueryVersions compares the ReportGenerationQuery versions
+// used by two report runs, returning a GenerationQueryDiff for every
+// ReportGenerationQuery used by either run, sorted by name. Changed queries
+// are diffed using the text of their versions held by texts.
+func DiffGenerationQueryVersions(from, to []metering.ReportGenerationQueryVersion, texts *GenerationQueryTexts) ([]GenerationQueryDiff, error) {
+	fromVersions := make(map[string]metering.ReportGenerationQueryVersion)
+	toVersions := make(map[string]metering.ReportGenerationQueryVersion)
+	var names []string
+	for _, v := range from {
+		fromVersions[v.Name] = v
+		names = append(names, v.Name)
+	}
+	for _, v := range to {
+		toVersions[v.Name] = v
+		if _, exists := fromVersions[v.Name]; !exists {
+			names = append(names, v.Name)
+		}
+	}
+	sort.Strings(names)
+
+	diffs := make([]GenerationQueryDiff, 0, len(names))
+	for _, name := range names {
+		fromVersion := fromVersions[name]
+		toVersion := toVersions[name]
+		queryDiff := GenerationQueryDiff{
+			Name:                name,
+			FromResourceVersion: fromVersion.ResourceVersion,
+			ToResourceVersion:   toVersion.ResourceVersion,
+			FromQueryHash:       fromVersion.QueryHash,
+			ToQueryHash:         toVersion.QueryHash,
+			Changed:             fromVersion.QueryHash != toVersion.QueryHash,
+		}
+		if queryDiff.Changed {
+			// a query only used by one of the runs is diffed against nothing
+			fromText, fromOK := texts.Get(fromVersion.QueryHash)
+			toText, toOK := texts.Get(toVersion.QueryHash)
+			if (fromVersion.QueryHash != "" && !fromOK) || (toVersion.QueryHash != "" && !toOK) {
+				queryDiff.DiffUnavailable = true
+			} else {
+				diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+					A:        difflib.SplitLines(fromText),
+					B:        difflib.SplitLines(toText),
+					FromFile: name + "@" + fromVersion.ResourceVersion,
+					ToFile:   name + "@" + toVersion.ResourceVersion,
+					Context:  3,
+				})
+				if err != nil {
+					return nil, err
+				}
+				queryDiff.Diff = diff
+			}
+		}
+		diffs = append(diffs, queryDiff)
+	}
+	return diffs, nil
+}
@@ -0,0 +1,118 @@
+package reporting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	metering "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func newVersionedQuery(name, resourceVersion, query string) *metering.ReportGenerationQuery {
+	return &metering.ReportGenerationQuery{
+		ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: resourceVersion},
+		Spec:       metering.ReportGenerationQuerySpec{Query: query},
+	}
+}
+
+func TestGetGenerationQueryVersions(t *testing.T) {
+	texts, err := NewGenerationQueryTexts(10)
+	require.NoError(t, err)
+	genQuery := newVersionedQuery("namespace-cpu", "3", "SELECT * FROM pod_cpu\n")
+	deps := &ReportGenerationQueryDependencies{
+		ReportGenerationQueries:        []*metering.ReportGenerationQuery{newVersionedQuery("pod-cpu-raw", "1", "SELECT * FROM datasource\n")},
+		DynamicReportGenerationQueries: []*metering.ReportGenerationQuery{newVersionedQuery("cluster-cpu", "2", "SELECT sum(cpu) FROM pod_cpu\n")},
+	}
+
+	versions := GetGenerationQueryVersions(genQuery, deps, texts)
+	assert.Equal(t, []metering.ReportGenerationQueryVersion{
+		{Name: "cluster-cpu", ResourceVersion: "2", QueryHash: hashQuery("SELECT sum(cpu) FROM pod_cpu\n")},
+		{Name: "namespace-cpu", ResourceVersion: "3", QueryHash: hashQuery("SELECT * FROM pod_cpu\n")},
+		{Name: "pod-cpu-raw", ResourceVersion: "1", QueryHash: hashQuery("SELECT * FROM datasource\n")},
+	}, versions)
+	for _, query := range append([]*metering.ReportGenerationQuery{genQuery}, append(deps.ReportGenerationQueries, deps.DynamicReportGenerationQueries...)...) {
+		text, ok := texts.Get(hashQuery(query.Spec.Query))
+		assert.True(t, ok, "expected the text of %s to be held", query.Name)
+		assert.Equal(t, query.Spec.Query, text)
+	}
+
+	// without texts, only the versions are returned
+	assert.Equal(t, []metering.ReportGenerationQueryVersion{
+		{Name: "namespace-cpu", ResourceVersion: "3", QueryHash: hashQuery("SELECT * FROM pod_cpu\n")},
+	}, GetGenerationQueryVersions(genQuery, nil, nil))
+}
+
+func TestGenerationQueryTextsEvictsLeastRecentlyUsed(t *testing.T) {
+	texts, err := NewGenerationQueryTexts(2)
+	require.NoError(t, err)
+	first := texts.Add(newVersionedQuery("first", "1", "SELECT 1"))
+	second := texts.Add(newVersionedQuery("second", "1", "SELECT 2"))
+	_, ok := texts.Get(first)
+	require.True(t, ok)
+	texts.Add(newVersionedQuery("third", "1", "SELECT 3"))
+
+	_, ok = texts.Get(first)
+	assert.True(t, ok, "expected the recently read text to be held")
+	_, ok = texts.Get(second)
+	assert.False(t, ok, "expected the least recently used text to be evicted")
+}
+
+func TestDiffGenerationQueryVersions(t *testing.T) {
+	texts, err := NewGenerationQueryTexts(10)
+	require.NoError(t, err)
+	unchanged := GetGenerationQueryVersions(newVersionedQuery("unchanged", "1", "SELECT 1\n"), nil, texts)[0]
+	before := GetGenerationQueryVersions(newVersionedQuery("changed", "1", "SELECT a\nFROM t\n"), nil, texts)[0]
+	after := GetGenerationQueryVersions(newVersionedQuery("changed", "2", "SELECT b\nFROM t\n"), nil, texts)[0]
+	removed := GetGenerationQueryVersions(newVersionedQuery("removed", "1", "SELECT 2\n"), nil, texts)[0]
+	// a version whose text isn't held, such as one used before
+	// reporting-operator restarted
+	forgotten := metering.ReportGenerationQueryVersion{Name: "changed", ResourceVersion: "0", QueryHash: hashQuery("SELECT c\nFROM t\n")}
+
+	diffs, err := DiffGenerationQueryVersions(
+		[]metering.ReportGenerationQueryVersion{before, removed, unchanged},
+		[]metering.ReportGenerationQueryVersion{after, unchanged},
+		texts,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []GenerationQueryDiff{
+		{
+			Name:                "changed",
+			FromResourceVersion: "1",
+			ToResourceVersion:   "2",
+			FromQueryHash:       before.QueryHash,
+			ToQueryHash:         after.QueryHash,
+			Changed:             true,
+			Diff:                "--- changed@1\n+++ changed@2\n@@ -1,3 +1,3 @@\n-SELECT a\n+SELECT b\n FROM t\n \n",
+		},
+		{
+			Name:                "removed",
+			FromResourceVersion: "1",
+			FromQueryHash:       removed.QueryHash,
+			Changed:             true,
+			Diff:                "--- removed@1\n+++ removed@\n@@ -1,2 +1 @@\n-SELECT 2\n \n",
+		},
+		{
+			Name:                "unchanged",
+			FromResourceVersion: "1",
+			ToResourceVersion:   "1",
+			FromQueryHash:       unchanged.QueryHash,
+			ToQueryHash:         unchanged.QueryHash,
+		},
+	}, diffs)
+
+	diffs, err = DiffGenerationQueryVersions([]metering.ReportGenerationQueryVersion{forgotten}, []metering.ReportGenerationQueryVersion{after}, texts)
+	require.NoError(t, err)
+	assert.Equal(t, []GenerationQueryDiff{
+		{
+			Name:                "changed",
+			FromResourceVersion: "0",
+			ToResourceVersion:   "2",
+			FromQueryHash:       forgotten.QueryHash,
+			ToQueryHash:         after.QueryHash,
+			Changed:             true,
+			DiffUnavailable:     true,
+		},
+	}, diffs)
+}
@@ -0,0 +1,56 @@
+package reporting
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const (
+	DefaultCurrencyCode      = "USD"
+	DefaultCurrencyPrecision = 2
+	// maxCurrencyPrecision is the most decimal places costs can be rounded
+	// to.
+	maxCurrencyPrecision = 10
+
+	// CurrencyColumnUnit is the unit of ReportGenerationQuery columns
+	// containing costs. The API includes the configured currency code in the
+	// metadata of these columns.
+	CurrencyColumnUnit = "currency"
+)
+
+var currencyCodeRegexp = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Currency configures the currency costs are reported in. The rates of
+// PricingPolicies are assumed to be in the same currency.
+type Currency struct {
+	// Code is the ISO 4217 code of the currency, for example USD or EUR.
+	// Defaults to DefaultCurrencyCode.
+	Code string
+	// Precision is the number of decimal places costs are rounded to by
+	// queries using the costPrecision template function. Defaults to
+	// DefaultCurrencyPrecision if Code is empty.
+	Precision int
+}
+
+// DefaultCurrency is used when no currency is configured.
+var DefaultCurrency = Currency{Code: DefaultCurrencyCode, Precision: DefaultCurrencyPrecision}
+
+// ValidateCurrency returns an error if currency is invalid. The zero
+// Currency is valid, and is treated as DefaultCurrency.
+func ValidateCurrency(currency Currency) error {
+	if currency.Code != "" && !currencyCodeRegexp.MatchString(currency.Code) {
+		return fmt.Errorf("currency code must be a 3 letter uppercase ISO 4217 code, got %q", currency.Code)
+	}
+	if currency.Precision < 0 || currency.Precision > maxCurrencyPrecision {
+		return fmt.Errorf("currency precision must be between 0 and %d, got %d", maxCurrencyPrecision, currency.Precision)
+	}
+	return nil
+}
+
+// OrDefault returns DefaultCurrency if currency is unset.
+func (currency Currency) OrDefault() Currency {
+	if currency.Code == "" {
+		return DefaultCurrency
+	}
+	return currency
+}
@@ -0,0 +1,248 @@
+package reporting
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	metering "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+// DependencyCycleError is returned when ReportGenerationQueries depend on
+// each other in a cycle, which can never be resolved.
+type DependencyCycleError struct {
+	// Cycle contains the names of the ReportGenerationQueries in the cycle,
+	// starting and ending with the same query.
+	Cycle []string
+}
+
+func (e *DependencyCycleError) Error() string {
+	return fmt.Sprintf("ReportGenerationQuery dependency cycle detected: %s", strings.Join(e.Cycle, " -> "))
+}
+
+// IsDependencyCycle returns true if err is a DependencyCycleError.
+func IsDependencyCycle(err error) bool {
+	_, ok := err.(*DependencyCycleError)
+	return ok
+}
+
+// UnresolvedDependencies returns the dependencies which don't exist, as
+// kind/name, if err is a DependencyMissingError caused by them.
+func UnresolvedDependencies(err error) []string {
+	if missingErr, ok := err.(*DependencyMissingError); ok {
+		return missingErr.Unresolved
+	}
+	return nil
+}
+
+type visitState int
+
+const (
+	unvisited visitState = iota
+	visiting
+	visited
+)
+
+// dependencyResolver walks the graph of ReportGenerationQueries a query
+// depends on, both through spec.reportQueries and
+// spec.dynamicReportQueries, recording dependencies which don't exist and
+// detecting cycles.
+type dependencyResolver struct {
+	queryGetter reportGenerationQueryGetter
+	namespace   string
+
+	queries map[string]*metering.ReportGenerationQuery
+	state   map[string]visitState
+	// order contains the names of the resolved queries with each query
+	// after all of it's dependencies.
+	order      []string
+	path       []string
+	cycle      []string
+	unresolved map[string]struct{}
+}
+
+func newDependencyResolver(queryGetter reportGenerationQueryGetter, namespace string) *dependencyResolver {
+	return &dependencyResolver{
+		queryGetter: queryGetter,
+		namespace:   namespace,
+		queries:     make(map[string]*metering.ReportGenerationQuery),
+		state:       make(map[string]visitState),
+		unresolved:  make(map[string]struct{}),
+	}
+}
+
+func queryDependencyNames(query *metering.ReportGenerationQuery) []string {
+	return append(append([]string(nil), query.Spec.ReportQueries...), query.Spec.DynamicReportQueries...)
+}
+
+// visit resolves the dependencies of the query depth first. The first cycle
+// found is recorded and stops the walk.
+func (r *dependencyResolver) visit(query *metering.ReportGenerationQuery) error {
+	r.state[query.Name] = visiting
+	r.path = append(r.path, query.Name)
+	for _, name := range queryDependencyNames(query) {
+		if r.cycle != nil {
+			return nil
+		}
+		switch r.state[name] {
+		case visited:
+			continue
+		case visiting:
+			for i, pathName := range r.path {
+				if pathName == name {
+					r.cycle = append(append([]string(nil), r.path[i:]...), name)
+					break
+				}
+			}
+			return nil
+		}
+		dep, err := r.queryGetter.getReportGenerationQuery(r.namespace, name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				r.unresolved["ReportGenerationQuery/"+name] = struct{}{}
+				r.state[name] = visited
+				continue
+			}
+			return err
+		}
+		r.queries[name] = dep
+		if err := r.visit(dep); err != nil {
+			return err
+		}
+	}
+	r.path = r.path[:len(r.path)-1]
+	r.state[query.Name] = visited
+	r.order = append(r.order, query.Name)
+	return nil
+}
+
+// reachable returns the resolved queries reachable from the query through
+// spec.dynamicReportQueries if dynamic is true, or spec.reportQueries
+// otherwise, ordered so each query comes after it's dependencies.
+func (r *dependencyResolver) reachable(query *metering.ReportGenerationQuery, dynamic bool) []*metering.ReportGenerationQuery {
+	seen := make(map[string]bool)
+	var walk func(*metering.ReportGenerationQuery)
+	walk = func(q *metering.ReportGenerationQuery) {
+		names := q.Spec.ReportQueries
+		if dynamic {
+			names = q.Spec.DynamicReportQueries
+		}
+		for _, name := range names {
+			dep, exists := r.queries[name]
+			if !exists || seen[name] {
+				continue
+			}
+			seen[name] = true
+			walk(dep)
+		}
+	}
+	walk(query)
+
+	var queries []*metering.ReportGenerationQuery
+	for _, name := range r.order {
+		if seen[name] {
+			queries = append(queries, r.queries[name])
+		}
+	}
+	return queries
+}
+
+func (r *dependencyResolver) unresolvedNames() []string {
+	names := make([]string, 0, len(r.unresolved))
+	for name := range r.unresolved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetGenerationQueryDependencies resolves all of the dependencies of the
+// generationQuery. ReportGenerationQueries are returned with each query after
+// the queries it depends on, which is the order their views must be created
+// in. If any dependencies don't exist, a DependencyMissingError listing all of
+// them is returned, and if ReportGenerationQueries depend on each other in a
+// cycle, a DependencyCycleError is returned.
+func GetGenerationQueryDependencies(
+	queryGetter reportGenerationQueryGetter,
+	dataSourceGetter reportDataSourceGetter,
+	reportGetter reportGetter,
+	scheduledReportGetter scheduledReportGetter,
+	generationQuery *metering.ReportGenerationQuery,
+) (*ReportGenerationQueryDependencies, error) {
+	resolver := newDependencyResolver(queryGetter, generationQuery.Namespace)
+	if err := resolver.visit(generationQuery); err != nil {
+		return nil, err
+	}
+	if resolver.cycle != nil {
+		return nil, &DependencyCycleError{Cycle: resolver.cycle}
+	}
+
+	viewQueries := resolver.reachable(generationQuery, false)
+	dynamicQueries := resolver.reachable(generationQuery, true)
+
+	// deduplicate the list of ReportDataSources
+	seen := make(map[string]struct{})
+	var dataSources []*metering.ReportDataSource
+	queries := append([]*metering.ReportGenerationQuery{generationQuery}, viewQueries...)
+	queries = append(queries, dynamicQueries...)
+	for _, query := range queries {
+		for _, name := range query.Spec.DataSources {
+			if _, exists := seen[name]; exists {
+				continue
+			}
+			seen[name] = struct{}{}
+			ds, err := dataSourceGetter.getReportDataSource(generationQuery.Namespace, name)
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					resolver.unresolved["ReportDataSource/"+name] = struct{}{}
+					continue
+				}
+				return nil, err
+			}
+			dataSources = append(dataSources, ds)
+		}
+	}
+
+	var reports []*metering.Report
+	for _, name := range DependentReportNames(generationQuery) {
+		report, err := reportGetter.getReport(generationQuery.Namespace, name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				resolver.unresolved["Report/"+name] = struct{}{}
+				continue
+			}
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	var scheduledReports []*metering.ScheduledReport
+	for _, name := range DependentScheduledReportNames(generationQuery) {
+		scheduledReport, err := scheduledReportGetter.getScheduledReport(generationQuery.Namespace, name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				resolver.unresolved["ScheduledReport/"+name] = struct{}{}
+				continue
+			}
+			return nil, err
+		}
+		scheduledReports = append(scheduledReports, scheduledReport)
+	}
+
+	if unresolved := resolver.unresolvedNames(); len(unresolved) != 0 {
+		return nil, &DependencyMissingError{
+			err:        fmt.Errorf("missing dependencies: %s", strings.Join(unresolved, ", ")),
+			Unresolved: unresolved,
+		}
+	}
+
+	return &ReportGenerationQueryDependencies{
+		ReportGenerationQueries:        viewQueries,
+		DynamicReportGenerationQueries: dynamicQueries,
+		ReportDataSources:              dataSources,
+		Reports:                        reports,
+		ScheduledReports:               scheduledReports,
+	}, nil
+}
@@ -0,0 +1,87 @@
+package reporting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	metering "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/test/testhelpers"
+)
+
+func newTestGetters(queries []*metering.ReportGenerationQuery, dataSources []*metering.ReportDataSource) (reportGenerationQueryGetter, reportDataSourceGetter, reportGetter, scheduledReportGetter) {
+	queryGetter := reportGenerationQueryGetterFunc(func(namespace, name string) (*metering.ReportGenerationQuery, error) {
+		for _, query := range queries {
+			if query.Name == name {
+				return query, nil
+			}
+		}
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "reportgenerationqueries"}, name)
+	})
+	dataSourceGetter := reportDataSourceGetterFunc(func(namespace, name string) (*metering.ReportDataSource, error) {
+		for _, ds := range dataSources {
+			if ds.Name == name {
+				return ds, nil
+			}
+		}
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "reportdatasources"}, name)
+	})
+	reportGetter := reportGetterFunc(func(namespace, name string) (*metering.Report, error) {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "reports"}, name)
+	})
+	scheduledReportGetter := scheduledReportGetterFunc(func(namespace, name string) (*metering.ScheduledReport, error) {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "scheduledreports"}, name)
+	})
+	return queryGetter, dataSourceGetter, reportGetter, scheduledReportGetter
+}
+
+func TestGetGenerationQueryDependencies(t *testing.T) {
+	newQuery := func(name string, reportQueries, dynamicReportQueries, dataSources []string) *metering.ReportGenerationQuery {
+		query := testhelpers.NewReportGenerationQuery(name, "default", nil)
+		query.Spec.ReportQueries = reportQueries
+		query.Spec.DynamicReportQueries = dynamicReportQueries
+		query.Spec.DataSources = dataSources
+		return query
+	}
+	queryNames := func(queries []*metering.ReportGenerationQuery) []string {
+		var names []string
+		for _, query := range queries {
+			names = append(names, query.Name)
+		}
+		return names
+	}
+
+	root := newQuery("root", []string{"b", "a"}, []string{"dynamic"}, nil)
+	a := newQuery("a", []string{"b"}, nil, []string{"ds-a"})
+	b := newQuery("b", []string{"c"}, nil, []string{"ds-b"})
+	c := newQuery("c", nil, nil, []string{"ds-a"})
+	dynamic := newQuery("dynamic", nil, nil, nil)
+	dataSources := []*metering.ReportDataSource{
+		testhelpers.NewReportDataSource("ds-a", "default"),
+		testhelpers.NewReportDataSource("ds-b", "default"),
+	}
+
+	queryGetter, dataSourceGetter, reportGetter, scheduledReportGetter := newTestGetters([]*metering.ReportGenerationQuery{a, b, c, dynamic}, dataSources)
+	deps, err := GetGenerationQueryDependencies(queryGetter, dataSourceGetter, reportGetter, scheduledReportGetter, root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "b", "a"}, queryNames(deps.ReportGenerationQueries), "expected views to be ordered after the views they depend on")
+	assert.Equal(t, []string{"dynamic"}, queryNames(deps.DynamicReportGenerationQueries))
+	assert.Len(t, deps.ReportDataSources, 2)
+
+	// c depends on root, making a cycle
+	cyclic := newQuery("c", []string{"root"}, nil, nil)
+	queryGetter, dataSourceGetter, reportGetter, scheduledReportGetter = newTestGetters([]*metering.ReportGenerationQuery{root, a, b, cyclic, dynamic}, dataSources)
+	_, err = GetGenerationQueryDependencies(queryGetter, dataSourceGetter, reportGetter, scheduledReportGetter, root)
+	require.True(t, IsDependencyCycle(err), "expected a dependency cycle error, got %v", err)
+	assert.Equal(t, []string{"root", "b", "c", "root"}, err.(*DependencyCycleError).Cycle)
+
+	// every missing dependency is reported at once
+	root.Spec.Reports = []string{"some-report"}
+	queryGetter, dataSourceGetter, reportGetter, scheduledReportGetter = newTestGetters([]*metering.ReportGenerationQuery{a, b, dynamic}, dataSources[:1])
+	_, err = GetGenerationQueryDependencies(queryGetter, dataSourceGetter, reportGetter, scheduledReportGetter, root)
+	require.True(t, IsDependencyMissing(err), "expected a dependency missing error, got %v", err)
+	assert.Equal(t, []string{"Report/some-report", "ReportDataSource/ds-b", "ReportGenerationQuery/c"}, UnresolvedDependencies(err))
+}
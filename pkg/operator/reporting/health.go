@@ -1,6 +1,8 @@
 package reporting
 
 import (
+	"context"
+
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/singleflight"
 
@@ -9,23 +11,59 @@ import (
 	"github.com/operator-framework/operator-metering/pkg/presto"
 )
 
+// HealthCheckQueries are the queries a PrestoHealthChecker runs, which
+// depend on the SQL dialect of the query backend.
+type HealthCheckQueries struct {
+	// Read is a query which succeeds if queries can be run.
+	Read string
+	// InsertValues is a VALUES clause containing a single row with the
+	// current time, as a TIMESTAMP.
+	InsertValues string
+}
+
+var (
+	PrestoHealthCheckQueries = HealthCheckQueries{
+		Read: "SELECT * FROM system.runtime.nodes",
+		// Hive does not support timezones, and now() returns a
+		// TIMESTAMP WITH TIMEZONE so we cast the return of now() to a
+		// TIMESTAMP.
+		InsertValues: "VALUES (cast(now() AS TIMESTAMP))",
+	}
+	// AthenaHealthCheckQueries are used with Athena, which doesn't have the
+	// system catalog tables used to check Presto.
+	AthenaHealthCheckQueries = HealthCheckQueries{
+		Read:         "SELECT 1",
+		InsertValues: PrestoHealthCheckQueries.InsertValues,
+	}
+	BigQueryHealthCheckQueries = HealthCheckQueries{
+		Read:         "SELECT 1",
+		InsertValues: "VALUES (CURRENT_TIMESTAMP())",
+	}
+	ClickHouseHealthCheckQueries = HealthCheckQueries{
+		Read:         "SELECT 1",
+		InsertValues: "VALUES (now())",
+	}
+)
+
 type PrestoHealthChecker struct {
 	logger       logrus.FieldLogger
 	queryer      db.Queryer
 	tableManager TableManager
 
 	tableProperties hive.TableProperties
+	queries         HealthCheckQueries
 	// ensures only at most a single testRead query is running against Presto
 	// at one time
 	healthCheckSingleFlight singleflight.Group
 }
 
-func NewPrestoHealthChecker(logger logrus.FieldLogger, queryer db.Queryer, tableManager TableManager, tableProperties hive.TableProperties) *PrestoHealthChecker {
+func NewPrestoHealthChecker(logger logrus.FieldLogger, queryer db.Queryer, tableManager TableManager, tableProperties hive.TableProperties, queries HealthCheckQueries) *PrestoHealthChecker {
 	return &PrestoHealthChecker{
 		logger:          logger,
 		queryer:         queryer,
 		tableManager:    tableManager,
 		tableProperties: tableProperties,
+		queries:         queries,
 	}
 }
 
@@ -52,9 +90,9 @@ func (checker *PrestoHealthChecker) TestReadFromPrestoSingleFlight() bool {
 }
 
 func (checker *PrestoHealthChecker) TestReadFromPresto() bool {
-	_, err := presto.ExecuteSelect(checker.queryer, "SELECT * FROM system.runtime.nodes")
+	_, err := presto.ExecuteSelect(context.Background(), checker.queryer, checker.queries.Read)
 	if err != nil {
-		checker.logger.WithError(err).Debugf("cannot query Presto system.runtime.nodes table")
+		checker.logger.WithError(err).Debugf("cannot run health check query %q", checker.queries.Read)
 		return false
 	}
 	return true
@@ -70,15 +108,13 @@ func (checker *PrestoHealthChecker) TestWriteToPresto() bool {
 		Columns:      columns,
 		IgnoreExists: true,
 	}
-	err := checker.tableManager.CreateTable(params, checker.tableProperties)
+	err := checker.tableManager.CreateTable(context.Background(), params, checker.tableProperties)
 	if err != nil {
 		logger.WithError(err).Errorf("cannot create Presto table %s", tableName)
 		return false
 	}
 
-	// Hive does not support timezones, and now() returns a
-	// TIMESTAMP WITH TIMEZONE so we cast the return of now() to a TIMESTAMP.
-	err = presto.InsertInto(checker.queryer, tableName, "VALUES (cast(now() AS TIMESTAMP))")
+	err = presto.InsertInto(context.Background(), checker.queryer, tableName, checker.queries.InsertValues)
 	if err != nil {
 		logger.WithError(err).Errorf("cannot insert into Presto table %s", tableName)
 		return false
@@ -0,0 +1,73 @@
+package reporting
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const prestoQueryMaxRunTimeProperty = "query_max_run_time"
+
+// ExecutionLimits limit the resources used by the queries generating a
+// report.
+type ExecutionLimits struct {
+	// MaxRuntime is how long Presto runs a query before failing it. If it's
+	// zero, the query_max_run_time of the Presto cluster or the
+	// ReportGenerationQuery's session is used.
+	MaxRuntime time.Duration
+	// MaxResultRows is the maximum number of rows each run of a query can
+	// store. If it's zero, the number of rows is unlimited.
+	MaxResultRows int64
+}
+
+type executionLimitsContextKey struct{}
+
+// WithExecutionLimits returns a context causing reports generated with it to
+// be limited by limits.
+func WithExecutionLimits(ctx context.Context, limits ExecutionLimits) context.Context {
+	return context.WithValue(ctx, executionLimitsContextKey{}, limits)
+}
+
+func executionLimitsFromContext(ctx context.Context) ExecutionLimits {
+	limits, _ := ctx.Value(executionLimitsContextKey{}).(ExecutionLimits)
+	return limits
+}
+
+// ExecutionLimitExceededError is returned when generating a report exceeded
+// one of it's ExecutionLimits.
+type ExecutionLimitExceededError struct {
+	err error
+}
+
+func (e *ExecutionLimitExceededError) Error() string {
+	return e.err.Error()
+}
+
+// IsExecutionLimitExceeded returns true if err is an
+// ExecutionLimitExceededError.
+func IsExecutionLimitExceeded(err error) bool {
+	_, ok := err.(*ExecutionLimitExceededError)
+	return ok
+}
+
+// isPrestoTimeLimitError returns true if the query failed because it ran for
+// longer than query_max_run_time.
+func isPrestoTimeLimitError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "exceeded_time_limit") || strings.Contains(msg, "exceeded maximum time limit")
+}
+
+// prestoDuration formats d as a Presto duration session property value,
+// rounding up to the nearest second.
+func prestoDuration(d time.Duration) string {
+	return fmt.Sprintf("%ds", int64(math.Ceil(d.Seconds())))
+}
+
+// limitQuery limits the number of rows returned by query to one more than
+// maxRows, so that exceeding the limit can be detected without storing an
+// unbounded number of rows.
+func limitQuery(query string, maxRows int64) string {
+	return fmt.Sprintf("SELECT * FROM (%s) LIMIT %d", query, maxRows+1)
+}
@@ -0,0 +1,260 @@
+package reporting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const (
+	CPUCoreHourRate     = "cpuCoreHour"
+	MemoryGBHourRate    = "memoryGBHour"
+	StorageGBHourRate   = "storageGBHour"
+	NodeHourRate        = "nodeHour"
+	GPUHourRate         = "gpuHour"
+	NetworkEgressGBRate = "networkEgressGB"
+	LicenseCoreHourRate = "licenseCoreHour"
+)
+
+var errNoPricingPolicy = errors.New("no PricingPolicy is available, set spec.pricingPolicy on the report or mark a PricingPolicy in it's namespace as the default")
+
+type pricingContextKey struct{}
+
+// WithPricing returns a context whose reports can use the rates of pricing
+// in their queries.
+func WithPricing(ctx context.Context, pricing *cbTypes.PricingPolicySpec) context.Context {
+	return context.WithValue(ctx, pricingContextKey{}, pricing)
+}
+
+func pricingFromContext(ctx context.Context) *cbTypes.PricingPolicySpec {
+	pricing, _ := ctx.Value(pricingContextKey{}).(*cbTypes.PricingPolicySpec)
+	return pricing
+}
+
+// pricingRateValue returns the named rate of rates, or nil if it isn't set.
+func pricingRateValue(rates cbTypes.PricingRates, name string) (*float64, error) {
+	switch name {
+	case CPUCoreHourRate:
+		return rates.CPUCoreHour, nil
+	case MemoryGBHourRate:
+		return rates.MemoryGBHour, nil
+	case StorageGBHourRate:
+		return rates.StorageGBHour, nil
+	case NodeHourRate:
+		return rates.NodeHour, nil
+	case GPUHourRate:
+		return rates.GPUHour, nil
+	case NetworkEgressGBRate:
+		return rates.NetworkEgressGB, nil
+	case LicenseCoreHourRate:
+		return rates.LicenseCoreHour, nil
+	default:
+		return nil, fmt.Errorf("unknown rate %q, must be one of %s, %s, %s, %s, %s, %s or %s", name, CPUCoreHourRate, MemoryGBHourRate, StorageGBHourRate, NodeHourRate, GPUHourRate, NetworkEgressGBRate, LicenseCoreHourRate)
+	}
+}
+
+func prestoDouble(value *float64) string {
+	var v float64
+	if value != nil {
+		v = *value
+	}
+	return fmt.Sprintf("CAST(%s AS DOUBLE)", strconv.FormatFloat(v, 'f', -1, 64))
+}
+
+// ValidatePricingPolicy returns an error if the spec of a PricingPolicy is
+// invalid.
+func ValidatePricingPolicy(pricing *cbTypes.PricingPolicySpec) error {
+	if pricing.Spot != nil && pricing.Spot.DiscountPercent != nil {
+		if discount := *pricing.Spot.DiscountPercent; discount < 0 || discount > 100 {
+			return fmt.Errorf("spot.discountPercent must be between 0 and 100, got %v", discount)
+		}
+	}
+	storageClasses := make(map[string]bool)
+	for i, storageClassRate := range pricing.StorageClassRates {
+		if storageClassRate.StorageClass == "" {
+			return fmt.Errorf("storageClassRates[%d].storageClass must be set", i)
+		}
+		if storageClasses[storageClassRate.StorageClass] {
+			return fmt.Errorf("storageClassRates contains storageClass %s more than once", storageClassRate.StorageClass)
+		}
+		storageClasses[storageClassRate.StorageClass] = true
+	}
+	if markup := pricing.Markup; markup != nil {
+		if markup.Percent != nil && *markup.Percent < 0 {
+			return fmt.Errorf("markup.percent cannot be negative, got %v", *markup.Percent)
+		}
+		for i, namespaceMarkup := range markup.Namespaces {
+			if len(namespaceMarkup.Names) == 0 && len(namespaceMarkup.NamespaceSelector) == 0 {
+				return fmt.Errorf("markup.namespaces[%d] must set names or namespaceSelector", i)
+			}
+			if namespaceMarkup.Percent == nil || *namespaceMarkup.Percent < 0 {
+				return fmt.Errorf("markup.namespaces[%d].percent must be set and cannot be negative", i)
+			}
+		}
+	}
+	return nil
+}
+
+// DefaultSpotNodeSelectors identify spot nodes when a PricingPolicy's
+// spec.spot doesn't set nodeSelectors. The built-in node queries derive the
+// lifecycle label from the labels cloud providers set on spot and
+// preemptible nodes.
+var DefaultSpotNodeSelectors = []map[string]string{{"lifecycle": "spot"}}
+
+// PricingRate returns a Presto expression for the named rate of pricing. If
+// a nodeLabelsColumn is passed, the expression uses the spot rates of spot
+// nodes and the rates of the nodeRates entry matching other nodes, using the
+// labels in the column, which must be a map(varchar, varchar) of node
+// labels.
+func PricingRate(pricing *cbTypes.PricingPolicySpec, name string, nodeLabelsColumn ...string) (string, error) {
+	if pricing == nil {
+		return "", errNoPricingPolicy
+	}
+	if len(nodeLabelsColumn) > 1 {
+		return "", fmt.Errorf("pricingRate takes at most one node labels column, got %d", len(nodeLabelsColumn))
+	}
+	defaultRate, err := pricingRateValue(pricing.Rates, name)
+	if err != nil {
+		return "", err
+	}
+	if len(nodeLabelsColumn) == 0 {
+		return prestoDouble(defaultRate), nil
+	}
+	column := nodeLabelsColumn[0]
+
+	nodeRate := prestoDouble(defaultRate)
+	if len(pricing.NodeRates) != 0 {
+		var expr strings.Builder
+		expr.WriteString("(CASE")
+		for _, nodeRates := range pricing.NodeRates {
+			rate, _ := pricingRateValue(nodeRates.Rates, name)
+			if rate == nil {
+				rate = defaultRate
+			}
+			fmt.Fprintf(&expr, " WHEN %s THEN %s", nodeSelectorCondition(column, nodeRates.NodeSelector), prestoDouble(rate))
+		}
+		fmt.Fprintf(&expr, " ELSE %s END)", nodeRate)
+		nodeRate = expr.String()
+	}
+	if pricing.Spot == nil {
+		return nodeRate, nil
+	}
+
+	var spotRate string
+	if rate, _ := pricingRateValue(pricing.Spot.Rates, name); rate != nil {
+		spotRate = prestoDouble(rate)
+	} else if pricing.Spot.DiscountPercent != nil {
+		multiplier := 1 - *pricing.Spot.DiscountPercent/100
+		spotRate = fmt.Sprintf("(%s * %s)", nodeRate, prestoDouble(&multiplier))
+	} else {
+		return nodeRate, nil
+	}
+	return fmt.Sprintf("(CASE WHEN %s THEN %s ELSE %s END)", SpotNodeCondition(pricing, column), spotRate, nodeRate), nil
+}
+
+// StorageClassPricingRate returns a Presto expression for the storageGBHour
+// rate of the volumes of the storage class named in storageClassColumn,
+// using the PricingPolicy's storageClassRates and falling back to
+// rates.storageGBHour.
+func StorageClassPricingRate(pricing *cbTypes.PricingPolicySpec, storageClassColumn string) (string, error) {
+	if pricing == nil {
+		return "", errNoPricingPolicy
+	}
+	defaultRate := prestoDouble(pricing.Rates.StorageGBHour)
+	if len(pricing.StorageClassRates) == 0 {
+		return defaultRate, nil
+	}
+	var expr strings.Builder
+	fmt.Fprintf(&expr, "(CASE %s", QuoteIdentifier(storageClassColumn))
+	for _, storageClassRate := range pricing.StorageClassRates {
+		fmt.Fprintf(&expr, " WHEN %s THEN %s", QuoteString(storageClassRate.StorageClass), prestoDouble(storageClassRate.StorageGBHour))
+	}
+	fmt.Fprintf(&expr, " ELSE %s END)", defaultRate)
+	return expr.String(), nil
+}
+
+// CostMarkup returns a Presto expression for the multiplier costs of the
+// namespace in namespaceColumn are multiplied by to add the markup of
+// pricing. If a namespaceLabelsColumn is passed, entries of
+// markup.namespaces with a namespaceSelector are matched using the labels in
+// the column, which must be a map(varchar, varchar) of namespace labels,
+// otherwise only entries without a namespaceSelector are used. Without a
+// PricingPolicy there's no markup, and the multiplier is 1.
+func CostMarkup(pricing *cbTypes.PricingPolicySpec, namespaceColumn string, namespaceLabelsColumn ...string) (string, error) {
+	if len(namespaceLabelsColumn) > 1 {
+		return "", fmt.Errorf("costMarkup takes at most one namespace labels column, got %d", len(namespaceLabelsColumn))
+	}
+	if pricing == nil || pricing.Markup == nil {
+		return prestoMarkupMultiplier(nil), nil
+	}
+	defaultMultiplier := prestoMarkupMultiplier(pricing.Markup.Percent)
+
+	var expr strings.Builder
+	for _, namespaceMarkup := range pricing.Markup.Namespaces {
+		var conditions []string
+		if len(namespaceMarkup.Names) != 0 {
+			names := make([]string, len(namespaceMarkup.Names))
+			for i, name := range namespaceMarkup.Names {
+				names[i] = QuoteString(name)
+			}
+			conditions = append(conditions, fmt.Sprintf("%s IN (%s)", QuoteIdentifier(namespaceColumn), strings.Join(names, ", ")))
+		}
+		if len(namespaceMarkup.NamespaceSelector) != 0 {
+			if len(namespaceLabelsColumn) == 0 {
+				continue
+			}
+			conditions = append(conditions, nodeSelectorCondition(namespaceLabelsColumn[0], namespaceMarkup.NamespaceSelector))
+		}
+		fmt.Fprintf(&expr, " WHEN %s THEN %s", strings.Join(conditions, " AND "), prestoMarkupMultiplier(namespaceMarkup.Percent))
+	}
+	if expr.Len() == 0 {
+		return defaultMultiplier, nil
+	}
+	return fmt.Sprintf("(CASE%s ELSE %s END)", expr.String(), defaultMultiplier), nil
+}
+
+func prestoMarkupMultiplier(percent *float64) string {
+	multiplier := float64(1)
+	if percent != nil {
+		multiplier += *percent / 100
+	}
+	return prestoDouble(&multiplier)
+}
+
+// SpotNodeCondition returns a Presto condition which is true for rows whose
+// node labels, in the map(varchar, varchar) column nodeLabelsColumn,
+// identify a spot node according to pricing.
+func SpotNodeCondition(pricing *cbTypes.PricingPolicySpec, nodeLabelsColumn string) string {
+	selectors := DefaultSpotNodeSelectors
+	if pricing != nil && pricing.Spot != nil && len(pricing.Spot.NodeSelectors) != 0 {
+		selectors = pricing.Spot.NodeSelectors
+	}
+	conditions := make([]string, 0, len(selectors))
+	for _, selector := range selectors {
+		conditions = append(conditions, nodeSelectorCondition(nodeLabelsColumn, selector))
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+// nodeSelectorCondition returns a Presto condition which is true for rows
+// whose node labels in column include all of the labels of selector.
+func nodeSelectorCondition(column string, selector map[string]string) string {
+	labels := make([]string, 0, len(selector))
+	for label := range selector {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	if len(labels) == 0 {
+		return "true"
+	}
+	conditions := make([]string, 0, len(labels))
+	for _, label := range labels {
+		conditions = append(conditions, fmt.Sprintf("element_at(%s, %s) = %s", QuoteIdentifier(column), QuoteString(label), QuoteString(selector[label])))
+	}
+	return strings.Join(conditions, " AND ")
+}
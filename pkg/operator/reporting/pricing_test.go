@@ -0,0 +1,234 @@
+package reporting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestPricingRate(t *testing.T) {
+	rate := func(v float64) *float64 { return &v }
+	pricing := &cbTypes.PricingPolicySpec{
+		Rates: cbTypes.PricingRates{
+			CPUCoreHour:     rate(0.03),
+			MemoryGBHour:    rate(0.004),
+			GPUHour:         rate(2.48),
+			NetworkEgressGB: rate(0.09),
+		},
+		NodeRates: []cbTypes.NodePricingRates{
+			{
+				NodeSelector: map[string]string{"beta.kubernetes.io/instance-type": "m5.large", "zone": "us-east-1a"},
+				Rates:        cbTypes.PricingRates{CPUCoreHour: rate(0.048)},
+			},
+			{
+				NodeSelector: map[string]string{"gpu": "true"},
+				Rates:        cbTypes.PricingRates{CPUCoreHour: rate(0.9), MemoryGBHour: rate(0.01)},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		pricing     *cbTypes.PricingPolicySpec
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "default rate",
+			query:    `{| pricingRate "cpuCoreHour" |}`,
+			pricing:  pricing,
+			expected: `CAST(0.03 AS DOUBLE)`,
+		},
+		{
+			name:     "gpu rate",
+			query:    `{| pricingRate "gpuHour" |}`,
+			pricing:  pricing,
+			expected: `CAST(2.48 AS DOUBLE)`,
+		},
+		{
+			name:     "network egress rate",
+			query:    `{| pricingRate "networkEgressGB" |}`,
+			pricing:  pricing,
+			expected: `CAST(0.09 AS DOUBLE)`,
+		},
+		{
+			name:     "unset rate",
+			query:    `{| pricingRate "nodeHour" |}`,
+			pricing:  pricing,
+			expected: `CAST(0 AS DOUBLE)`,
+		},
+		{
+			name:     "node rates",
+			query:    `{| pricingRate "memoryGBHour" "node_labels" |}`,
+			pricing:  pricing,
+			expected: `(CASE WHEN element_at("node_labels", 'beta.kubernetes.io/instance-type') = 'm5.large' AND element_at("node_labels", 'zone') = 'us-east-1a' THEN CAST(0.004 AS DOUBLE) WHEN element_at("node_labels", 'gpu') = 'true' THEN CAST(0.01 AS DOUBLE) ELSE CAST(0.004 AS DOUBLE) END)`,
+		},
+		{
+			name:        "unknown rate",
+			query:       `{| pricingRate "tpuHour" |}`,
+			pricing:     pricing,
+			expectError: true,
+		},
+		{
+			name:        "no PricingPolicy",
+			query:       `{| pricingRate "cpuCoreHour" |}`,
+			expectError: true,
+		},
+	}
+	spotPricing := pricing.DeepCopy()
+	spotPricing.NodeRates = nil
+	spotPricing.Spot = &cbTypes.SpotPricing{
+		Rates:           cbTypes.PricingRates{CPUCoreHour: rate(0.01)},
+		DiscountPercent: rate(75),
+	}
+	customSpotPricing := spotPricing.DeepCopy()
+	customSpotPricing.Spot.NodeSelectors = []map[string]string{{"spotinst.io/node-lifecycle": "spot"}, {"preemptible": "true"}}
+	storagePricing := pricing.DeepCopy()
+	storagePricing.Rates.StorageGBHour = rate(0.0001)
+	storagePricing.StorageClassRates = []cbTypes.StorageClassPricingRate{
+		{StorageClass: "gp2", StorageGBHour: rate(0.00014)},
+		{StorageClass: "io1", StorageGBHour: rate(0.00017)},
+	}
+	licensePricing := pricing.DeepCopy()
+	licensePricing.NodeRates = []cbTypes.NodePricingRates{
+		{
+			NodeSelector: map[string]string{"os": "windows"},
+			Rates:        cbTypes.PricingRates{LicenseCoreHour: rate(0.046)},
+		},
+	}
+	tests = append(tests, []struct {
+		name        string
+		query       string
+		pricing     *cbTypes.PricingPolicySpec
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "spot rate",
+			query:    `{| pricingRate "cpuCoreHour" "labels" |}`,
+			pricing:  spotPricing,
+			expected: `(CASE WHEN (element_at("labels", 'lifecycle') = 'spot') THEN CAST(0.01 AS DOUBLE) ELSE CAST(0.03 AS DOUBLE) END)`,
+		},
+		{
+			name:     "spot discount",
+			query:    `{| pricingRate "memoryGBHour" "labels" |}`,
+			pricing:  spotPricing,
+			expected: `(CASE WHEN (element_at("labels", 'lifecycle') = 'spot') THEN (CAST(0.004 AS DOUBLE) * CAST(0.25 AS DOUBLE)) ELSE CAST(0.004 AS DOUBLE) END)`,
+		},
+		{
+			name:     "spot without node labels",
+			query:    `{| pricingRate "cpuCoreHour" |}`,
+			pricing:  spotPricing,
+			expected: `CAST(0.03 AS DOUBLE)`,
+		},
+		{
+			name:     "spot node selectors",
+			query:    `{| spotNodeCondition "labels" |}`,
+			pricing:  customSpotPricing,
+			expected: `(element_at("labels", 'spotinst.io/node-lifecycle') = 'spot' OR element_at("labels", 'preemptible') = 'true')`,
+		},
+		{
+			name:     "storage class rates",
+			query:    `{| storageClassPricingRate "storageclass" |}`,
+			pricing:  storagePricing,
+			expected: `(CASE "storageclass" WHEN 'gp2' THEN CAST(0.00014 AS DOUBLE) WHEN 'io1' THEN CAST(0.00017 AS DOUBLE) ELSE CAST(0.0001 AS DOUBLE) END)`,
+		},
+		{
+			name:     "license surcharge",
+			query:    `{| pricingRate "licenseCoreHour" "labels" |}`,
+			pricing:  licensePricing,
+			expected: `(CASE WHEN element_at("labels", 'os') = 'windows' THEN CAST(0.046 AS DOUBLE) ELSE CAST(0 AS DOUBLE) END)`,
+		},
+		{
+			name:     "no storage class rates",
+			query:    `{| storageClassPricingRate "storageclass" |}`,
+			pricing:  pricing,
+			expected: `CAST(0 AS DOUBLE)`,
+		},
+	}...)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := RenderQuery(tt.query, &ReportQueryTemplateContext{Pricing: tt.pricing})
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, query)
+		})
+	}
+}
+
+func TestValidatePricingPolicy(t *testing.T) {
+	discount := float64(120)
+	err := ValidatePricingPolicy(&cbTypes.PricingPolicySpec{Spot: &cbTypes.SpotPricing{DiscountPercent: &discount}})
+	assert.Error(t, err, "expected a discount over 100% to be invalid")
+	discount = 40
+	err = ValidatePricingPolicy(&cbTypes.PricingPolicySpec{Spot: &cbTypes.SpotPricing{DiscountPercent: &discount}})
+	assert.NoError(t, err)
+
+	err = ValidatePricingPolicy(&cbTypes.PricingPolicySpec{StorageClassRates: []cbTypes.StorageClassPricingRate{{StorageClass: "gp2"}, {StorageClass: "gp2"}}})
+	assert.Error(t, err, "expected a storage class with two rates to be invalid")
+
+	percent := float64(10)
+	err = ValidatePricingPolicy(&cbTypes.PricingPolicySpec{Markup: &cbTypes.PricingMarkup{Namespaces: []cbTypes.NamespacePricingMarkup{{Percent: &percent}}}})
+	assert.Error(t, err, "expected a namespace markup matching every namespace to be invalid")
+	percent = -10
+	err = ValidatePricingPolicy(&cbTypes.PricingPolicySpec{Markup: &cbTypes.PricingMarkup{Percent: &percent}})
+	assert.Error(t, err, "expected a negative markup to be invalid")
+}
+
+func TestCostMarkup(t *testing.T) {
+	percent := func(v float64) *float64 { return &v }
+	pricing := &cbTypes.PricingPolicySpec{
+		Markup: &cbTypes.PricingMarkup{
+			Percent: percent(15),
+			Namespaces: []cbTypes.NamespacePricingMarkup{
+				{Names: []string{"kube-system", "openshift-monitoring"}, Percent: percent(0)},
+				{NamespaceSelector: map[string]string{"tier": "gold"}, Percent: percent(25)},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		pricing  *cbTypes.PricingPolicySpec
+		expected string
+	}{
+		{
+			name:     "no PricingPolicy",
+			query:    `{| costMarkup "namespace" |}`,
+			expected: `CAST(1 AS DOUBLE)`,
+		},
+		{
+			name:     "no markup",
+			query:    `{| costMarkup "namespace" |}`,
+			pricing:  &cbTypes.PricingPolicySpec{},
+			expected: `CAST(1 AS DOUBLE)`,
+		},
+		{
+			name:     "without namespace labels",
+			query:    `{| costMarkup "namespace" |}`,
+			pricing:  pricing,
+			expected: `(CASE WHEN "namespace" IN ('kube-system', 'openshift-monitoring') THEN CAST(1 AS DOUBLE) ELSE CAST(1.15 AS DOUBLE) END)`,
+		},
+		{
+			name:     "with namespace labels",
+			query:    `{| costMarkup "namespace" "namespace_labels" |}`,
+			pricing:  pricing,
+			expected: `(CASE WHEN "namespace" IN ('kube-system', 'openshift-monitoring') THEN CAST(1 AS DOUBLE) WHEN element_at("namespace_labels", 'tier') = 'gold' THEN CAST(1.25 AS DOUBLE) ELSE CAST(1.15 AS DOUBLE) END)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := RenderQuery(tt.query, &ReportQueryTemplateContext{Pricing: tt.pricing})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, query)
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package reporting
+
+import (
+	"container/heap"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	prestoQueryPriorityProperty = "query_priority"
+	prestoQuerySourcePrefix     = "metering-"
+)
+
+// QueryClass identifies what a query is run for.
+type QueryClass string
+
+const (
+	// ScheduledReportQueryClass is used for the queries of ScheduledReports
+	// and materialized ReportGenerationQueries, which other reports
+	// usually depend on.
+	ScheduledReportQueryClass QueryClass = "scheduledreport"
+	// ReportQueryClass is used for the queries of Reports, and queries
+	// without a QueryPriority.
+	ReportQueryClass QueryClass = "report"
+	// AdHocQueryClass is used for the queries run by API requests.
+	AdHocQueryClass QueryClass = "adhoc"
+)
+
+// rank returns how important queries of the class are, with higher ranks run
+// first. It's also used as the query_priority of the class's queries in
+// Presto.
+func (c QueryClass) rank() int {
+	switch c {
+	case ScheduledReportQueryClass:
+		return 3
+	case AdHocQueryClass:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// QueryPriority determines the order queries waiting in a QueryQueue are run.
+// Queries with a more important Class are run first, followed by those with
+// a shorter Period, as they finish sooner. Queries with the same priority are
+// run in the order they were queued.
+type QueryPriority struct {
+	Class QueryClass
+	// Period is the length of the reporting period the query covers, if
+	// known.
+	Period time.Duration
+}
+
+// before returns true if a query with priority p should run before a query
+// with priority other.
+func (p QueryPriority) before(other QueryPriority) bool {
+	if p.Class.rank() != other.Class.rank() {
+		return p.Class.rank() > other.Class.rank()
+	}
+	return p.Period < other.Period
+}
+
+type queryPriorityContextKey struct{}
+
+// WithQueryPriority returns a context causing reports generated with it to be
+// queued with priority, and run by Presto with the query_priority and source
+// of it's class.
+func WithQueryPriority(ctx context.Context, priority QueryPriority) context.Context {
+	return context.WithValue(ctx, queryPriorityContextKey{}, priority)
+}
+
+func queryPriorityFromContext(ctx context.Context) (QueryPriority, bool) {
+	priority, ok := ctx.Value(queryPriorityContextKey{}).(QueryPriority)
+	return priority, ok
+}
+
+// prestoSession returns the session properties and source Presto
+// resource groups can use to select the queries of the class.
+func (c QueryClass) prestoSession() (map[string]string, string) {
+	return map[string]string{prestoQueryPriorityProperty: strconv.Itoa(c.rank())}, prestoQuerySourcePrefix + string(c)
+}
+
+type queryWaiter struct {
+	priority QueryPriority
+	seq      uint64
+	ready    chan struct{}
+	index    int
+}
+
+// queryWaiterHeap implements heap.Interface, ordering waiters by priority.
+type queryWaiterHeap []*queryWaiter
+
+func (h queryWaiterHeap) Len() int { return len(h) }
+func (h queryWaiterHeap) Less(i, j int) bool {
+	if h[i].priority.before(h[j].priority) {
+		return true
+	}
+	if h[j].priority.before(h[i].priority) {
+		return false
+	}
+	return h[i].seq < h[j].seq
+}
+func (h queryWaiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *queryWaiterHeap) Push(x interface{}) {
+	waiter := x.(*queryWaiter)
+	waiter.index = len(*h)
+	*h = append(*h, waiter)
+}
+func (h *queryWaiterHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	waiter := old[n-1]
+	old[n-1] = nil
+	waiter.index = -1
+	*h = old[:n-1]
+	return waiter
+}
+
+// QueryQueue limits the number of report queries running against Presto at
+// once. Queries waiting to run are started in the order of their
+// QueryPriority, so a burst of large or ad-hoc queries can't delay the
+// ScheduledReports other reports depend on.
+type QueryQueue struct {
+	maxConcurrent int
+
+	mu      sync.Mutex
+	running int
+	seq     uint64
+	waiting queryWaiterHeap
+}
+
+// NewQueryQueue returns a QueryQueue running at most maxConcurrent queries at
+// once. If maxConcurrent isn't positive, queries are never queued.
+func NewQueryQueue(maxConcurrent int) *QueryQueue {
+	return &QueryQueue{maxConcurrent: maxConcurrent}
+}
+
+// Acquire waits until a query with priority can run, returning a function
+// which must be called when the query finishes. An error is returned if ctx
+// is done before the query can run.
+func (q *QueryQueue) Acquire(ctx context.Context, priority QueryPriority) (func(), error) {
+	if q == nil {
+		return func() {}, nil
+	}
+
+	q.mu.Lock()
+	if q.maxConcurrent <= 0 || (q.running < q.maxConcurrent && len(q.waiting) == 0) {
+		q.running++
+		q.mu.Unlock()
+		return q.release, nil
+	}
+	q.seq++
+	waiter := &queryWaiter{priority: priority, seq: q.seq, ready: make(chan struct{})}
+	heap.Push(&q.waiting, waiter)
+	q.mu.Unlock()
+
+	select {
+	case <-waiter.ready:
+		return q.release, nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		if waiter.index >= 0 {
+			heap.Remove(&q.waiting, waiter.index)
+			return nil, ctx.Err()
+		}
+		// the query was started as ctx finished, so pass it's slot on
+		q.releaseLocked()
+		return nil, ctx.Err()
+	}
+}
+
+// SetMaxConcurrent changes the number of queries run at once. Waiting queries
+// are started if the limit increases, and if it decreases, queries which are
+// already running aren't affected. If maxConcurrent isn't positive, every
+// waiting query is started and queries are no longer queued.
+func (q *QueryQueue) SetMaxConcurrent(maxConcurrent int) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.maxConcurrent = maxConcurrent
+	for len(q.waiting) != 0 && (maxConcurrent <= 0 || q.running < maxConcurrent) {
+		waiter := heap.Pop(&q.waiting).(*queryWaiter)
+		q.running++
+		close(waiter.ready)
+	}
+}
+
+// Waiting returns the number of queries waiting to run.
+func (q *QueryQueue) Waiting() int {
+	if q == nil {
+		return 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.waiting)
+}
+
+func (q *QueryQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.releaseLocked()
+}
+
+// releaseLocked passes the slot of a finished query to the highest priority
+// waiting query, if any. q.mu must be held.
+func (q *QueryQueue) releaseLocked() {
+	// after the limit is lowered, slots aren't passed on until fewer queries
+	// than the new limit are running.
+	if len(q.waiting) == 0 || (q.maxConcurrent > 0 && q.running > q.maxConcurrent) {
+		q.running--
+		return
+	}
+	waiter := heap.Pop(&q.waiting).(*queryWaiter)
+	close(waiter.ready)
+}
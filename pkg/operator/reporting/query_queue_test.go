@@ -0,0 +1,126 @@
+package reporting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryQueue(t *testing.T) {
+	queue := NewQueryQueue(1)
+	ctx := context.Background()
+
+	release, err := queue.Acquire(ctx, QueryPriority{Class: ReportQueryClass})
+	require.NoError(t, err)
+
+	waitForWaiting := func(n int) {
+		for i := 0; i < 100 && queue.Waiting() != n; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		require.Equal(t, n, queue.Waiting(), "expected %d queries to be waiting", n)
+	}
+
+	priorities := []struct {
+		name     string
+		priority QueryPriority
+	}{
+		{"adhoc", QueryPriority{Class: AdHocQueryClass}},
+		{"monthly report", QueryPriority{Class: ReportQueryClass, Period: 31 * 24 * time.Hour}},
+		{"daily report", QueryPriority{Class: ReportQueryClass, Period: 24 * time.Hour}},
+		{"hourly scheduled report", QueryPriority{Class: ScheduledReportQueryClass, Period: time.Hour}},
+	}
+	started := make(chan string, len(priorities))
+	for i, p := range priorities {
+		p := p
+		go func() {
+			release, err := queue.Acquire(ctx, p.priority)
+			if err != nil {
+				started <- err.Error()
+				return
+			}
+			started <- p.name
+			release()
+		}()
+		waitForWaiting(i + 1)
+	}
+
+	// a cancelled query gives up it's place in the queue
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancelled := make(chan error)
+	go func() {
+		_, err := queue.Acquire(cancelledCtx, QueryPriority{Class: ScheduledReportQueryClass})
+		cancelled <- err
+	}()
+	waitForWaiting(len(priorities) + 1)
+	cancel()
+	assert.Equal(t, context.Canceled, <-cancelled)
+	waitForWaiting(len(priorities))
+
+	release()
+	var order []string
+	for range priorities {
+		order = append(order, <-started)
+	}
+	assert.Equal(t, []string{"hourly scheduled report", "daily report", "monthly report", "adhoc"}, order)
+	assert.Equal(t, 0, queue.Waiting())
+
+	// the queue is empty again, so queries start immediately
+	release, err = queue.Acquire(ctx, QueryPriority{})
+	require.NoError(t, err)
+	release()
+}
+
+func TestQueryQueueSetMaxConcurrent(t *testing.T) {
+	queue := NewQueryQueue(1)
+	ctx := context.Background()
+
+	release, err := queue.Acquire(ctx, QueryPriority{})
+	require.NoError(t, err)
+
+	started := make(chan struct{}, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			release, err := queue.Acquire(ctx, QueryPriority{})
+			if err == nil {
+				started <- struct{}{}
+				defer release()
+			}
+		}()
+	}
+	for i := 0; i < 100 && queue.Waiting() != 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Equal(t, 2, queue.Waiting())
+
+	// raising the limit starts waiting queries without any finishing
+	queue.SetMaxConcurrent(3)
+	<-started
+	<-started
+	assert.Equal(t, 0, queue.Waiting())
+	release()
+
+	// disabling the limit never queues
+	queue.SetMaxConcurrent(0)
+	var releases []func()
+	for i := 0; i < 5; i++ {
+		release, err := queue.Acquire(ctx, QueryPriority{})
+		require.NoError(t, err)
+		releases = append(releases, release)
+	}
+	for _, release := range releases {
+		release()
+	}
+
+	// re-enabling the limit queues queries again
+	queue.SetMaxConcurrent(1)
+	first, err := queue.Acquire(ctx, QueryPriority{})
+	require.NoError(t, err)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = queue.Acquire(timeoutCtx, QueryPriority{})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	first()
+}
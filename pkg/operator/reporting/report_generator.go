@@ -1,6 +1,7 @@
 package reporting
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -9,6 +10,8 @@ import (
 
 	metering "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
 	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/tracing"
 )
 
 const (
@@ -23,22 +26,66 @@ var (
 )
 
 type ReportGenerator interface {
-	GenerateReport(tableName string, reportStart, reportEnd *time.Time, generationQuery *metering.ReportGenerationQuery, dynamicReportGenerationQueries []*metering.ReportGenerationQuery, inputs []metering.ReportGenerationQueryInputValue, deleteExistingData bool) error
+	GenerateReport(ctx context.Context, tableName string, reportStart, reportEnd *time.Time, generationQuery *metering.ReportGenerationQuery, dynamicReportGenerationQueries []*metering.ReportGenerationQuery, inputs []metering.ReportGenerationQueryInputValue, deleteExistingData bool) error
+	PreviewReport(ctx context.Context, reportStart, reportEnd *time.Time, generationQuery *metering.ReportGenerationQuery, dynamicReportGenerationQueries []*metering.ReportGenerationQuery, inputs []metering.ReportGenerationQueryInputValue, limit int) (*ReportPreview, error)
+	ExplainReport(ctx context.Context, reportStart, reportEnd *time.Time, generationQuery *metering.ReportGenerationQuery, dynamicReportGenerationQueries []*metering.ReportGenerationQuery, inputs []metering.ReportGenerationQueryInputValue, analyze bool) (*ReportExplanation, error)
+}
+
+// ReportPreview contains the rendered query of a ReportGenerationQuery and a
+// sample of the rows it returns.
+type ReportPreview struct {
+	Query   string          `json:"query"`
+	Columns []presto.Column `json:"columns"`
+	Results []presto.Row    `json:"results"`
+}
+
+// ReportExplanation contains the rendered query of a ReportGenerationQuery and
+// the plan Presto would use to execute it.
+type ReportExplanation struct {
+	Query string `json:"query"`
+	Plan  string `json:"plan"`
 }
 
 type reportGenerator struct {
-	logger            log.FieldLogger
-	reportResultsRepo prestostore.ReportResultsRepo
+	logger               log.FieldLogger
+	reportResultsRepo    prestostore.ReportResultsRepo
+	namespacedTableNames bool
+	queryQueue           *QueryQueue
+	currency             Currency
+	clusterID            string
 }
 
-func NewReportGenerator(logger log.FieldLogger, reportResultsRepo prestostore.ReportResultsRepo) *reportGenerator {
+// NewReportGenerator returns a ReportGenerator. If namespacedTableNames is
+// true, table and view names referenced by queries include the namespace of
+// the ReportGenerationQuery. Queries wait for their turn in queryQueue before
+// running, unless it's nil. Costs are reported in currency, and clusterID is
+// available to queries using the clusterID template function.
+func NewReportGenerator(logger log.FieldLogger, reportResultsRepo prestostore.ReportResultsRepo, namespacedTableNames bool, queryQueue *QueryQueue, currency Currency, clusterID string) *reportGenerator {
 	return &reportGenerator{
-		logger:            logger,
-		reportResultsRepo: reportResultsRepo,
+		logger:               logger,
+		reportResultsRepo:    reportResultsRepo,
+		namespacedTableNames: namespacedTableNames,
+		queryQueue:           queryQueue,
+		currency:             currency,
+		clusterID:            clusterID,
+	}
+}
+
+// waitForQuery waits until the query can run according to the priority of
+// ctx, returning a function to call once it's finished.
+func (g *reportGenerator) waitForQuery(ctx context.Context) (func(), error) {
+	priority, _ := queryPriorityFromContext(ctx)
+	_, span := tracing.StartSpan(ctx, "report.queueWait", tracing.Int64("metering.queue.waiting", int64(g.queryQueue.Waiting())))
+	release, err := g.queryQueue.Acquire(ctx, priority)
+	span.SetError(err)
+	span.End()
+	if err != nil {
+		return nil, fmt.Errorf("cancelled while waiting for %d queued queries to run: %v", g.queryQueue.Waiting(), err)
 	}
+	return release, nil
 }
 
-func (g *reportGenerator) GenerateReport(tableName string, reportStart, reportEnd *time.Time, generationQuery *metering.ReportGenerationQuery, dynamicReportGenerationQueries []*metering.ReportGenerationQuery, inputs []metering.ReportGenerationQueryInputValue, deleteExistingData bool) error {
+func (g *reportGenerator) GenerateReport(ctx context.Context, tableName string, reportStart, reportEnd *time.Time, generationQuery *metering.ReportGenerationQuery, dynamicReportGenerationQueries []*metering.ReportGenerationQuery, inputs []metering.ReportGenerationQueryInputValue, deleteExistingData bool) error {
 	if generationQuery == nil {
 		panic("GenerateReport: must specify generationQuery")
 	}
@@ -48,7 +95,7 @@ func (g *reportGenerator) GenerateReport(tableName string, reportStart, reportEn
 	if generationQuery.Name == "" {
 		return errInvalidReportGenerationQueryName
 	}
-	if generationQuery.Spec.Query == "" {
+	if generationQuery.Spec.Query == "" && generationQuery.Spec.Rollup == nil {
 		return errEmptyQueryField
 	}
 
@@ -57,39 +104,211 @@ func (g *reportGenerator) GenerateReport(tableName string, reportStart, reportEn
 		"reportGenerationQuery": generationQuery.Name,
 	})
 	logger.Infof("generating Report")
+	ctx, span := tracing.StartSpan(ctx, "report.query",
+		tracing.String("metering.reportGenerationQuery", generationQuery.Name),
+		tracing.String("db.table", tableName),
+	)
+	defer span.End()
 
-	reportQueryInputs, err := ValidateReportGenerationQueryInputs(generationQuery, inputs)
+	query, err := g.renderQuery(ctx, reportStart, reportEnd, generationQuery, dynamicReportGenerationQueries, inputs)
 	if err != nil {
-		return fmt.Errorf("unable to GenerateReport for Report Table %s, ReportGenerationQuery %s, failed to validate ReportGenerationQueryInputs: %s", tableName, generationQuery.Name, err)
+		return fmt.Errorf("unable to GenerateReport for Report Table %s, ReportGenerationQuery %s, %s", tableName, generationQuery.Name, err)
 	}
-
-	tmplCtx := &ReportQueryTemplateContext{
-		DynamicDependentQueries: dynamicReportGenerationQueries,
-		Report: &ReportTemplateInfo{
-			ReportingStart: reportStart,
-			ReportingEnd:   reportEnd,
-			Inputs:         reportQueryInputs,
-		},
+	limits := executionLimitsFromContext(ctx)
+	ctx, err = withQuerySession(ctx, generationQuery)
+	if err != nil {
+		return fmt.Errorf("unable to GenerateReport for Report Table %s, ReportGenerationQuery %s, %s", tableName, generationQuery.Name, err)
 	}
-	query, err := RenderQuery(generationQuery.Spec.Query, tmplCtx)
+
+	release, err := g.waitForQuery(ctx)
 	if err != nil {
-		return err
+		return fmt.Errorf("unable to GenerateReport for Report Table %s, ReportGenerationQuery %s, %s", tableName, generationQuery.Name, err)
 	}
+	defer release()
 
 	if deleteExistingData {
 		logger.Debugf("deleting any preexisting rows in %s", tableName)
-		err = g.reportResultsRepo.DeleteReportResults(tableName)
+		err = g.reportResultsRepo.DeleteReportResults(ctx, tableName)
 		if err != nil {
 			return fmt.Errorf("couldn't empty table %s of preexisting rows: %v", tableName, err)
 		}
 	}
 
+	// count the existing rows so the number of rows stored by this run can
+	// be compared to maxResultRows
+	var existingRows int64
+	if limits.MaxResultRows > 0 {
+		query = limitQuery(query, limits.MaxResultRows)
+		if !deleteExistingData {
+			existingRows, err = g.reportResultsRepo.CountReportResults(ctx, tableName)
+			if err != nil {
+				return fmt.Errorf("couldn't count the existing rows of table %s: %v", tableName, err)
+			}
+		}
+	}
+
 	logger.Debugf("StoreReportResults: executing ReportGenerationQuery")
-	err = g.reportResultsRepo.StoreReportResults(tableName, query)
+	err = g.reportResultsRepo.StoreReportResults(ctx, tableName, query)
 	if err != nil {
 		logger.WithError(err).Errorf("creating usage report FAILED!")
-		return fmt.Errorf("Failed to execute query %s for Report table %s: %v", generationQuery.Name, tableName, err)
+		err = fmt.Errorf("Failed to execute query %s for Report table %s: %v", generationQuery.Name, tableName, err)
+		if limits.MaxRuntime > 0 && isPrestoTimeLimitError(err) {
+			return &ExecutionLimitExceededError{err: fmt.Errorf("query exceeded the maxRuntime of %s: %v", limits.MaxRuntime, err)}
+		}
+		return err
+	}
+
+	if limits.MaxResultRows > 0 {
+		rows, err := g.reportResultsRepo.CountReportResults(ctx, tableName)
+		if err != nil {
+			return fmt.Errorf("couldn't count the rows of table %s: %v", tableName, err)
+		}
+		if rows-existingRows > limits.MaxResultRows {
+			return &ExecutionLimitExceededError{err: fmt.Errorf("query %s returned more than the maxResultRows of %d rows", generationQuery.Name, limits.MaxResultRows)}
+		}
 	}
 
 	return nil
 }
+
+// PreviewReport renders the ReportGenerationQuery for the reporting period
+// and executes it, returning at most limit rows without storing them.
+func (g *reportGenerator) PreviewReport(ctx context.Context, reportStart, reportEnd *time.Time, generationQuery *metering.ReportGenerationQuery, dynamicReportGenerationQueries []*metering.ReportGenerationQuery, inputs []metering.ReportGenerationQueryInputValue, limit int) (*ReportPreview, error) {
+	if generationQuery == nil {
+		panic("PreviewReport: must specify generationQuery")
+	}
+	if generationQuery.Spec.Query == "" && generationQuery.Spec.Rollup == nil {
+		return nil, errEmptyQueryField
+	}
+
+	query, err := g.renderQuery(ctx, reportStart, reportEnd, generationQuery, dynamicReportGenerationQueries, inputs)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err = withQuerySession(ctx, generationQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := g.waitForQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	g.logger.WithField("reportGenerationQuery", generationQuery.Name).Debugf("PreviewReportResults: executing ReportGenerationQuery")
+	columns, results, err := g.reportResultsRepo.PreviewReportResults(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query %s: %v", generationQuery.Name, err)
+	}
+	return &ReportPreview{
+		Query:   query,
+		Columns: columns,
+		Results: results,
+	}, nil
+}
+
+// ExplainReport renders the ReportGenerationQuery for the reporting period
+// and returns the plan for executing it, without storing any results. If
+// analyze is true, the query is executed to measure the cost of each stage.
+func (g *reportGenerator) ExplainReport(ctx context.Context, reportStart, reportEnd *time.Time, generationQuery *metering.ReportGenerationQuery, dynamicReportGenerationQueries []*metering.ReportGenerationQuery, inputs []metering.ReportGenerationQueryInputValue, analyze bool) (*ReportExplanation, error) {
+	if generationQuery == nil {
+		panic("ExplainReport: must specify generationQuery")
+	}
+	if generationQuery.Spec.Query == "" && generationQuery.Spec.Rollup == nil {
+		return nil, errEmptyQueryField
+	}
+
+	query, err := g.renderQuery(ctx, reportStart, reportEnd, generationQuery, dynamicReportGenerationQueries, inputs)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err = withQuerySession(ctx, generationQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := g.waitForQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	g.logger.WithField("reportGenerationQuery", generationQuery.Name).Debugf("ExplainReportResults: explaining ReportGenerationQuery")
+	plan, err := g.reportResultsRepo.ExplainReportResults(ctx, query, analyze)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain query %s: %v", generationQuery.Name, err)
+	}
+	return &ReportExplanation{
+		Query: query,
+		Plan:  plan,
+	}, nil
+}
+
+func (g *reportGenerator) renderQuery(ctx context.Context, reportStart, reportEnd *time.Time, generationQuery *metering.ReportGenerationQuery, dynamicReportGenerationQueries []*metering.ReportGenerationQuery, inputs []metering.ReportGenerationQueryInputValue) (string, error) {
+	queryTemplate, err := GenerationQueryTemplate(generationQuery)
+	if err != nil {
+		return "", err
+	}
+	reportQueryInputs, err := ValidateReportGenerationQueryInputs(generationQuery, inputs)
+	if err != nil {
+		return "", fmt.Errorf("failed to validate ReportGenerationQueryInputs: %s", err)
+	}
+
+	var tableNamespace string
+	if g.namespacedTableNames {
+		tableNamespace = generationQuery.Namespace
+	}
+
+	tmplCtx := &ReportQueryTemplateContext{
+		TableNamespace:          tableNamespace,
+		DynamicDependentQueries: dynamicReportGenerationQueries,
+		Pricing:                 pricingFromContext(ctx),
+		Currency:                g.currency,
+		ClusterID:               g.clusterID,
+		Report: &ReportTemplateInfo{
+			ReportingStart: reportStart,
+			ReportingEnd:   reportEnd,
+			Inputs:         reportQueryInputs,
+		},
+	}
+	return RenderQuery(queryTemplate, tmplCtx)
+}
+
+// withQuerySession returns a context which runs queries in the Presto
+// session configured by the ReportGenerationQuery, if any, with
+// query_max_run_time set to the maxRuntime of the ExecutionLimits of ctx, and
+// the query_priority and source of the QueryClass of ctx, if it has them.
+func withQuerySession(ctx context.Context, generationQuery *metering.ReportGenerationQuery) (context.Context, error) {
+	limits := executionLimitsFromContext(ctx)
+	priority, hasPriority := queryPriorityFromContext(ctx)
+	if generationQuery.Spec.Session == nil && limits.MaxRuntime <= 0 && !hasPriority {
+		return ctx, nil
+	}
+	session := presto.Session{Properties: make(map[string]string)}
+	// the query's session takes precedence over the defaults for it's
+	// class, so it can choose a different resource group
+	if hasPriority {
+		properties, source := priority.Class.prestoSession()
+		for name, value := range properties {
+			session.Properties[name] = value
+		}
+		session.Source = source
+	}
+	if generationQuery.Spec.Session != nil {
+		for name, value := range generationQuery.Spec.Session.Properties {
+			session.Properties[name] = value
+		}
+		if generationQuery.Spec.Session.Source != "" {
+			session.Source = generationQuery.Spec.Session.Source
+		}
+	}
+	// the report's maxRuntime takes precedence over the query's session
+	if limits.MaxRuntime > 0 {
+		session.Properties[prestoQueryMaxRunTimeProperty] = prestoDuration(limits.MaxRuntime)
+	}
+	if err := session.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid session: %v", err)
+	}
+	return presto.WithSession(ctx, session), nil
+}
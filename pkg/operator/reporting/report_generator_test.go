@@ -1,6 +1,8 @@
 package reporting
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -62,7 +64,8 @@ func TestGenerateReport(t *testing.T) {
 		"an ReportGenerationQuery spec.query with invalid template expressions will error": {
 			tableName:             tableName,
 			reportGenerationQuery: &testQueryInvalidQuery,
-			expectedErr:           "error parsing query: template: report-generation-query:1: unexpected unclosed action in command",
+			// the text of template parse errors differs between Go versions
+			expectedErr: "unable to GenerateReport for Report Table test-table, ReportGenerationQuery test-query-1, error parsing query: template: report-generation-query:1:",
 		},
 
 		"a table name and a ReportGenerationQuery with a query field and deleteExistingData=true will succeed": {
@@ -79,22 +82,131 @@ func TestGenerateReport(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
+			ctx := context.Background()
 			logger := logrus.New()
 			reportResultsRepo := mockprestostore.NewMockReportResultsRepo(ctrl)
 			if tt.deleteExistingData {
-				reportResultsRepo.EXPECT().DeleteReportResults(tt.tableName).Return(nil)
+				reportResultsRepo.EXPECT().DeleteReportResults(ctx, tt.tableName).Return(nil)
 			}
 			if tt.expectedErr == "" {
-				reportResultsRepo.EXPECT().StoreReportResults(tt.tableName, tt.reportGenerationQuery.Spec.Query).Return(nil)
+				reportResultsRepo.EXPECT().StoreReportResults(ctx, tt.tableName, tt.reportGenerationQuery.Spec.Query).Return(nil)
 			}
 
-			reportGenerator := NewReportGenerator(logger, reportResultsRepo)
-			err := reportGenerator.GenerateReport(tt.tableName, tt.reportStart, tt.reportEnd, tt.reportGenerationQuery, tt.dynamicReportGenerationQueries, tt.inputs, tt.deleteExistingData)
+			reportGenerator := NewReportGenerator(logger, reportResultsRepo, false, nil, DefaultCurrency, "")
+			err := reportGenerator.GenerateReport(ctx, tt.tableName, tt.reportStart, tt.reportEnd, tt.reportGenerationQuery, tt.dynamicReportGenerationQueries, tt.inputs, tt.deleteExistingData)
 			if tt.expectedErr == "" {
 				assert.NoError(t, err, "expected GenerateReport to not error")
 			} else {
-				assert.EqualError(t, err, tt.expectedErr, "expected GenerateReport to error")
+				if assert.Error(t, err, "expected GenerateReport to error") {
+					assert.Contains(t, err.Error(), tt.expectedErr)
+				}
 			}
 		})
 	}
 }
+
+func TestExplainReport(t *testing.T) {
+	testQuery := &metering.ReportGenerationQuery{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "test-query-1",
+			Namespace: "default",
+		},
+		Spec: metering.ReportGenerationQuerySpec{
+			Query: "SELECT 1",
+		},
+	}
+	plan := "- Output[_col0]\n    - Values"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	reportResultsRepo := mockprestostore.NewMockReportResultsRepo(ctrl)
+	reportResultsRepo.EXPECT().ExplainReportResults(ctx, "SELECT 1", true).Return(plan, nil)
+
+	reportGenerator := NewReportGenerator(logrus.New(), reportResultsRepo, false, nil, DefaultCurrency, "")
+	explanation, err := reportGenerator.ExplainReport(ctx, nil, nil, testQuery, nil, nil, true)
+	assert.NoError(t, err, "expected ExplainReport to not error")
+	assert.Equal(t, &ReportExplanation{Query: "SELECT 1", Plan: plan}, explanation)
+}
+
+func TestGenerateReportExecutionLimits(t *testing.T) {
+	testQuery := &metering.ReportGenerationQuery{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "test-query-1",
+			Namespace: "default",
+		},
+		Spec: metering.ReportGenerationQuerySpec{
+			Query: "SELECT 1",
+		},
+	}
+	tableName := "test-table"
+	limitedQuery := "SELECT * FROM (SELECT 1) LIMIT 11"
+
+	tests := map[string]struct {
+		limits      ExecutionLimits
+		setup       func(repo *mockprestostore.MockReportResultsRepo)
+		exceeded    bool
+		expectedErr bool
+	}{
+		"rows stored within maxResultRows succeed": {
+			limits: ExecutionLimits{MaxResultRows: 10},
+			setup: func(repo *mockprestostore.MockReportResultsRepo) {
+				gomock.InOrder(
+					repo.EXPECT().CountReportResults(gomock.Any(), tableName).Return(int64(5), nil),
+					repo.EXPECT().StoreReportResults(gomock.Any(), tableName, limitedQuery).Return(nil),
+					repo.EXPECT().CountReportResults(gomock.Any(), tableName).Return(int64(15), nil),
+				)
+			},
+		},
+		"more rows than maxResultRows are stored": {
+			limits: ExecutionLimits{MaxResultRows: 10},
+			setup: func(repo *mockprestostore.MockReportResultsRepo) {
+				gomock.InOrder(
+					repo.EXPECT().CountReportResults(gomock.Any(), tableName).Return(int64(5), nil),
+					repo.EXPECT().StoreReportResults(gomock.Any(), tableName, limitedQuery).Return(nil),
+					repo.EXPECT().CountReportResults(gomock.Any(), tableName).Return(int64(16), nil),
+				)
+			},
+			exceeded:    true,
+			expectedErr: true,
+		},
+		"query exceeds maxRuntime": {
+			limits: ExecutionLimits{MaxRuntime: time.Minute},
+			setup: func(repo *mockprestostore.MockReportResultsRepo) {
+				repo.EXPECT().StoreReportResults(gomock.Any(), tableName, "SELECT 1").Return(errors.New("Query exceeded maximum time limit of 1.00m"))
+			},
+			exceeded:    true,
+			expectedErr: true,
+		},
+		"other query failures aren't limit errors": {
+			limits: ExecutionLimits{MaxRuntime: time.Minute},
+			setup: func(repo *mockprestostore.MockReportResultsRepo) {
+				repo.EXPECT().StoreReportResults(gomock.Any(), tableName, "SELECT 1").Return(errors.New("Table not found"))
+			},
+			expectedErr: true,
+		},
+	}
+
+	for testName, tt := range tests {
+		testName := testName
+		tt := tt
+		t.Run(testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			reportResultsRepo := mockprestostore.NewMockReportResultsRepo(ctrl)
+			tt.setup(reportResultsRepo)
+
+			ctx := WithExecutionLimits(context.Background(), tt.limits)
+			reportGenerator := NewReportGenerator(logrus.New(), reportResultsRepo, false, nil, DefaultCurrency, "")
+			err := reportGenerator.GenerateReport(ctx, tableName, nil, nil, testQuery, nil, nil, false)
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.exceeded, IsExecutionLimitExceeded(err), "unexpected IsExecutionLimitExceeded result for error %v", err)
+		})
+	}
+}
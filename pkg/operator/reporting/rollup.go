@@ -0,0 +1,150 @@
+package reporting
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	metering "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/util/slice"
+)
+
+const (
+	rollupAggregationGroup = "group"
+
+	periodStartColumn = "period_start"
+	periodEndColumn   = "period_end"
+)
+
+var rollupAggregations = map[string]bool{
+	"sum":                  true,
+	"avg":                  true,
+	"min":                  true,
+	"max":                  true,
+	"count":                true,
+	rollupAggregationGroup: true,
+}
+
+// GenerationQueryTemplate returns the query template of the
+// ReportGenerationQuery, generating it from spec.rollup if it's set.
+func GenerationQueryTemplate(generationQuery *metering.ReportGenerationQuery) (string, error) {
+	if generationQuery.Spec.Rollup != nil {
+		return rollupQueryTemplate(generationQuery)
+	}
+	if generationQuery.Spec.Query == "" {
+		return "", errEmptyQueryField
+	}
+	return generationQuery.Spec.Query, nil
+}
+
+// ValidateRollup returns an error if spec.rollup of the
+// ReportGenerationQuery is invalid.
+func ValidateRollup(generationQuery *metering.ReportGenerationQuery) error {
+	_, err := rollupQueryTemplate(generationQuery)
+	return err
+}
+
+// rollupQueryTemplate generates a query aggregating the rows of the rollup's
+// source table within the reporting period, using the columns of the
+// ReportGenerationQuery. The period_start and period_end columns are set to
+// the reporting period.
+func rollupQueryTemplate(generationQuery *metering.ReportGenerationQuery) (string, error) {
+	rollup := generationQuery.Spec.Rollup
+	var source string
+	switch {
+	case rollup.Report != "" && rollup.ScheduledReport != "":
+		return "", errors.New("spec.rollup: only one of report or scheduledReport can be set")
+	case rollup.Report != "":
+		source = fmt.Sprintf("{| reportTableName %q |}", rollup.Report)
+	case rollup.ScheduledReport != "":
+		source = fmt.Sprintf("{| scheduledReportTableName %q |}", rollup.ScheduledReport)
+	default:
+		return "", errors.New("spec.rollup: one of report or scheduledReport must be set")
+	}
+	if !generationQuery.Spec.View.Disabled {
+		return "", errors.New("spec.rollup: spec.view.disabled must be true, a rollup can only be run for a reporting period")
+	}
+	if len(generationQuery.Spec.Columns) == 0 {
+		return "", errors.New("spec.rollup: spec.columns cannot be empty")
+	}
+
+	columnNames := make(map[string]bool, len(generationQuery.Spec.Columns))
+	for _, column := range generationQuery.Spec.Columns {
+		columnNames[column.Name] = true
+	}
+	for name, aggregation := range rollup.Aggregations {
+		if !columnNames[name] {
+			return "", fmt.Errorf("spec.rollup.aggregations: %s is not a column", name)
+		}
+		if !rollupAggregations[strings.ToLower(aggregation)] {
+			return "", fmt.Errorf("spec.rollup.aggregations: invalid aggregation %q for column %s, must be one of: sum, avg, min, max, count or group", aggregation, name)
+		}
+	}
+
+	var selects, groupBy []string
+	for _, column := range generationQuery.Spec.Columns {
+		name := column.Name
+		switch name {
+		case periodStartColumn:
+			selects = append(selects, "timestamp '{| .Report.ReportingStart | prestoTimestamp |}' AS period_start")
+			continue
+		case periodEndColumn:
+			selects = append(selects, "timestamp '{| .Report.ReportingEnd | prestoTimestamp |}' AS period_end")
+			continue
+		}
+
+		aggregation := strings.ToLower(rollup.Aggregations[name])
+		if aggregation == "" {
+			aggregation = defaultRollupAggregation(column)
+		}
+		if aggregation == rollupAggregationGroup {
+			selects = append(selects, name)
+			groupBy = append(groupBy, name)
+		} else {
+			selects = append(selects, fmt.Sprintf("%s(%s) AS %s", aggregation, name, name))
+		}
+	}
+
+	query := fmt.Sprintf(`SELECT
+  %s
+FROM %s
+WHERE period_start >= timestamp '{| .Report.ReportingStart | prestoTimestamp |}'
+AND period_end <= timestamp '{| .Report.ReportingEnd | prestoTimestamp |}'`, strings.Join(selects, ",\n  "), source)
+	if len(groupBy) != 0 {
+		query += "\nGROUP BY " + strings.Join(groupBy, ", ")
+	}
+	return query, nil
+}
+
+func defaultRollupAggregation(column metering.ReportGenerationQueryColumn) string {
+	switch strings.ToLower(column.Type) {
+	case "double", "real", "bigint", "integer", "int", "smallint", "tinyint":
+		return "sum"
+	case "timestamp":
+		if strings.HasSuffix(column.Name, "_start") {
+			return "min"
+		}
+		return "max"
+	}
+	return rollupAggregationGroup
+}
+
+// DependentReportNames returns the names of the Reports the
+// ReportGenerationQuery depends on, including the source of it's rollup.
+func DependentReportNames(generationQuery *metering.ReportGenerationQuery) []string {
+	names := generationQuery.Spec.Reports
+	if rollup := generationQuery.Spec.Rollup; rollup != nil && rollup.Report != "" && !slice.ContainsString(names, rollup.Report, nil) {
+		names = append(append([]string(nil), names...), rollup.Report)
+	}
+	return names
+}
+
+// DependentScheduledReportNames returns the names of the ScheduledReports the
+// ReportGenerationQuery depends on, including the source of it's rollup.
+func DependentScheduledReportNames(generationQuery *metering.ReportGenerationQuery) []string {
+	names := generationQuery.Spec.ScheduledReports
+	if rollup := generationQuery.Spec.Rollup; rollup != nil && rollup.ScheduledReport != "" && !slice.ContainsString(names, rollup.ScheduledReport, nil) {
+		names = append(append([]string(nil), names...), rollup.ScheduledReport)
+	}
+	return names
+}
@@ -0,0 +1,90 @@
+package reporting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	metering "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestRollupQueryTemplate(t *testing.T) {
+	query := &metering.ReportGenerationQuery{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "namespace-cpu-usage-monthly",
+			Namespace: "default",
+		},
+		Spec: metering.ReportGenerationQuerySpec{
+			Columns: []metering.ReportGenerationQueryColumn{
+				{Name: "period_start", Type: "timestamp"},
+				{Name: "period_end", Type: "timestamp"},
+				{Name: "namespace", Type: "string"},
+				{Name: "data_start", Type: "timestamp"},
+				{Name: "data_end", Type: "timestamp"},
+				{Name: "pod_usage_cpu_core_seconds", Type: "double"},
+				{Name: "pod_count", Type: "bigint"},
+			},
+			View: metering.GenQueryView{Disabled: true},
+			Rollup: &metering.ReportGenerationQueryRollup{
+				ScheduledReport: "namespace-cpu-usage-daily",
+				Aggregations: map[string]string{
+					"pod_count": "max",
+				},
+			},
+		},
+	}
+
+	queryTemplate, err := GenerationQueryTemplate(query)
+	require.NoError(t, err)
+
+	start := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC)
+	rendered, err := RenderQuery(queryTemplate, &ReportQueryTemplateContext{
+		TableNamespace: "default",
+		Report: &ReportTemplateInfo{
+			ReportingStart: &start,
+			ReportingEnd:   &end,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT
+  timestamp '2019-01-01 00:00:00.000' AS period_start,
+  timestamp '2019-02-01 00:00:00.000' AS period_end,
+  namespace,
+  min(data_start) AS data_start,
+  max(data_end) AS data_end,
+  sum(pod_usage_cpu_core_seconds) AS pod_usage_cpu_core_seconds,
+  max(pod_count) AS pod_count
+FROM scheduled_report_default__namespace_cpu_usage_daily
+WHERE period_start >= timestamp '2019-01-01 00:00:00.000'
+AND period_end <= timestamp '2019-02-01 00:00:00.000'
+GROUP BY namespace`, rendered)
+	assert.Equal(t, []string{"namespace-cpu-usage-daily"}, DependentScheduledReportNames(query))
+	assert.Empty(t, DependentReportNames(query))
+
+	tests := map[string]func(*metering.ReportGenerationQuery){
+		"no source": func(q *metering.ReportGenerationQuery) {
+			q.Spec.Rollup.ScheduledReport = ""
+		},
+		"both sources": func(q *metering.ReportGenerationQuery) {
+			q.Spec.Rollup.Report = "namespace-cpu-usage"
+		},
+		"view enabled": func(q *metering.ReportGenerationQuery) {
+			q.Spec.View.Disabled = false
+		},
+		"unknown column": func(q *metering.ReportGenerationQuery) {
+			q.Spec.Rollup.Aggregations["pods"] = "sum"
+		},
+		"invalid aggregation": func(q *metering.ReportGenerationQuery) {
+			q.Spec.Rollup.Aggregations["pod_count"] = "median"
+		},
+	}
+	for name, modify := range tests {
+		invalid := query.DeepCopy()
+		modify(invalid)
+		assert.Error(t, ValidateRollup(invalid), name)
+	}
+}
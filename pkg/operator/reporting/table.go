@@ -1,19 +1,39 @@
 package reporting
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/operator-framework/operator-metering/pkg/athena"
+	"github.com/operator-framework/operator-metering/pkg/bigquery"
+	"github.com/operator-framework/operator-metering/pkg/clickhouse"
 	"github.com/operator-framework/operator-metering/pkg/db"
 	"github.com/operator-framework/operator-metering/pkg/hive"
 	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
+	"github.com/operator-framework/operator-metering/pkg/presto"
 )
 
+// TableManager creates and modifies the tables of a query backend.
+// Cancelling the ctx passed to it's methods cancels the statements they run,
+// if the backend supports cancelling statements.
 type TableManager interface {
-	CreateTable(params hive.TableParameters, properties hive.TableProperties) error
-	DropTable(tableName string, ignoreNotExists bool) error
+	CreateTable(ctx context.Context, params hive.TableParameters, properties hive.TableProperties) error
+	DropTable(ctx context.Context, tableName string, ignoreNotExists bool) error
+	SetTableExternal(ctx context.Context, tableName string, external bool) error
+	GetTableSize(ctx context.Context, tableName string) (int64, error)
+	InsertIntoTable(ctx context.Context, tableName, selectQuery string) error
+	InsertOverwritePartition(ctx context.Context, tableName, partitionColumn, partitionValue, selectQuery string) error
+	DropTablePartition(ctx context.Context, tableName, partitionColumn, partitionValue string) error
+	RepairTable(ctx context.Context, tableName string) error
+	// AddColumns adds columns to the existing table described by params,
+	// after it's existing columns but before any partition columns. Rows
+	// stored before the columns were added have NULL values for them.
+	AddColumns(ctx context.Context, params hive.TableParameters, columns []hive.Column) error
 }
 
 type AWSTablePartitionManager interface {
-	AddPartition(tableName, start, end, location string) error
-	DropPartition(tableName, start, end string) error
+	AddPartition(ctx context.Context, tableName, start, end, location string) error
+	DropPartition(ctx context.Context, tableName, start, end string) error
 }
 
 type HiveTableManager struct {
@@ -24,18 +44,307 @@ func NewHiveTableManager(queryer db.Queryer) *HiveTableManager {
 	return &HiveTableManager{queryer: queryer}
 }
 
-func (m *HiveTableManager) CreateTable(params hive.TableParameters, properties hive.TableProperties) error {
-	return hive.ExecuteCreateTable(m.queryer, params, properties)
+func (m *HiveTableManager) CreateTable(ctx context.Context, params hive.TableParameters, properties hive.TableProperties) error {
+	return hive.ExecuteCreateTable(ctx, m.queryer, params, properties)
+}
+
+func (m *HiveTableManager) DropTable(ctx context.Context, tableName string, ignoreNotExists bool) error {
+	return hive.ExecuteDropTable(ctx, m.queryer, tableName, ignoreNotExists)
+}
+
+func (m *HiveTableManager) SetTableExternal(ctx context.Context, tableName string, external bool) error {
+	return hive.ExecuteSetTableExternal(ctx, m.queryer, tableName, external)
+}
+
+func (m *HiveTableManager) GetTableSize(ctx context.Context, tableName string) (int64, error) {
+	return hive.ExecuteGetTableSize(ctx, m.queryer, tableName)
+}
+
+func (m *HiveTableManager) InsertIntoTable(ctx context.Context, tableName, selectQuery string) error {
+	return hive.ExecuteInsertIntoTable(ctx, m.queryer, tableName, selectQuery)
+}
+
+func (m *HiveTableManager) InsertOverwritePartition(ctx context.Context, tableName, partitionColumn, partitionValue, selectQuery string) error {
+	return hive.ExecuteInsertOverwritePartition(ctx, m.queryer, tableName, partitionColumn, partitionValue, selectQuery)
+}
+
+func (m *HiveTableManager) DropTablePartition(ctx context.Context, tableName, partitionColumn, partitionValue string) error {
+	return hive.ExecuteDropPartition(ctx, m.queryer, tableName, partitionColumn, partitionValue)
+}
+
+func (m *HiveTableManager) AddPartition(ctx context.Context, tableName, start, end, location string) error {
+	return reportingutil.AddAWSHivePartition(ctx, m.queryer, tableName, start, end, location)
+}
+
+func (m *HiveTableManager) DropPartition(ctx context.Context, tableName, start, end string) error {
+	return reportingutil.DropAWSHivePartition(ctx, m.queryer, tableName, start, end)
+}
+
+func (m *HiveTableManager) RepairTable(ctx context.Context, tableName string) error {
+	return hive.ExecuteRepairTable(ctx, m.queryer, tableName)
+}
+
+func (m *HiveTableManager) AddColumns(ctx context.Context, params hive.TableParameters, columns []hive.Column) error {
+	return hive.ExecuteAddColumns(ctx, m.queryer, params.Name, columns, true)
+}
+
+// HiveMetastoreTableManager manages tables and partitions using the Hive
+// Metastore's Thrift API directly, which is faster and more reliable than
+// running DDL statements using HiveServer2. Statements which read or write
+// data are still run using HiveServer2.
+type HiveMetastoreTableManager struct {
+	*HiveTableManager
+	metastoreClient *hive.MetastoreClient
+}
+
+func NewHiveMetastoreTableManager(queryer db.Queryer, metastoreClient *hive.MetastoreClient) *HiveMetastoreTableManager {
+	return &HiveMetastoreTableManager{
+		HiveTableManager: NewHiveTableManager(queryer),
+		metastoreClient:  metastoreClient,
+	}
+}
+
+func (m *HiveMetastoreTableManager) CreateTable(ctx context.Context, params hive.TableParameters, properties hive.TableProperties) error {
+	return m.metastoreClient.CreateTable(ctx, params, properties)
+}
+
+func (m *HiveMetastoreTableManager) DropTable(ctx context.Context, tableName string, ignoreNotExists bool) error {
+	return m.metastoreClient.DropTable(ctx, tableName, ignoreNotExists)
+}
+
+func (m *HiveMetastoreTableManager) SetTableExternal(ctx context.Context, tableName string, external bool) error {
+	return m.metastoreClient.SetTableExternal(ctx, tableName, external)
+}
+
+func (m *HiveMetastoreTableManager) GetTableSize(ctx context.Context, tableName string) (int64, error) {
+	return m.metastoreClient.GetTableSize(ctx, tableName)
+}
+
+func (m *HiveMetastoreTableManager) AddColumns(ctx context.Context, params hive.TableParameters, columns []hive.Column) error {
+	return m.metastoreClient.AddColumns(ctx, params.Name, columns)
+}
+
+func (m *HiveMetastoreTableManager) DropTablePartition(ctx context.Context, tableName, partitionColumn, partitionValue string) error {
+	return m.metastoreClient.DropPartition(ctx, tableName, map[string]string{partitionColumn: partitionValue})
+}
+
+func (m *HiveMetastoreTableManager) AddPartition(ctx context.Context, tableName, start, end, location string) error {
+	return m.metastoreClient.AddPartition(ctx, tableName, awsPartitionSpec(start, end), location)
+}
+
+func (m *HiveMetastoreTableManager) DropPartition(ctx context.Context, tableName, start, end string) error {
+	return m.metastoreClient.DropPartition(ctx, tableName, awsPartitionSpec(start, end))
+}
+
+// awsPartitionSpec returns the values of the partition columns of AWS billing
+// tables, the same as reportingutil.AddAWSHivePartition.
+func awsPartitionSpec(start, end string) map[string]string {
+	return map[string]string{
+		"billing_period_start": start,
+		"billing_period_end":   end,
+	}
+}
+
+// AthenaTableManager manages tables using AWS Athena. Athena only supports
+// external tables stored in S3, so every table created is external, and
+// dropping a table leaves it's data in S3.
+type AthenaTableManager struct {
+	queryer db.Queryer
+}
+
+func NewAthenaTableManager(queryer db.Queryer) *AthenaTableManager {
+	return &AthenaTableManager{queryer: queryer}
+}
+
+func (m *AthenaTableManager) CreateTable(ctx context.Context, params hive.TableParameters, properties hive.TableProperties) error {
+	location, err := athena.S3Location(properties.Location)
+	if err != nil {
+		return err
+	}
+	properties.Location = location
+	properties.External = true
+	return hive.ExecuteCreateTable(ctx, m.queryer, params, properties)
+}
+
+func (m *AthenaTableManager) DropTable(ctx context.Context, tableName string, ignoreNotExists bool) error {
+	return athena.ExecuteDropTable(ctx, m.queryer, tableName, ignoreNotExists)
+}
+
+// SetTableExternal does nothing, as Athena tables are always external.
+func (m *AthenaTableManager) SetTableExternal(ctx context.Context, tableName string, external bool) error {
+	return nil
+}
+
+func (m *AthenaTableManager) GetTableSize(ctx context.Context, tableName string) (int64, error) {
+	return hive.ExecuteGetTableSize(ctx, m.queryer, tableName)
+}
+
+func (m *AthenaTableManager) InsertIntoTable(ctx context.Context, tableName, selectQuery string) error {
+	return presto.InsertInto(ctx, m.queryer, tableName, selectQuery)
+}
+
+func (m *AthenaTableManager) InsertOverwritePartition(ctx context.Context, tableName, partitionColumn, partitionValue, selectQuery string) error {
+	return fmt.Errorf("unable to overwrite partition %s=%s of table %s: Athena does not support INSERT OVERWRITE", partitionColumn, partitionValue, tableName)
+}
+
+// DropTablePartition drops the partition, leaving it's data in S3.
+func (m *AthenaTableManager) DropTablePartition(ctx context.Context, tableName, partitionColumn, partitionValue string) error {
+	return hive.ExecuteDropPartition(ctx, m.queryer, tableName, partitionColumn, partitionValue)
+}
+
+func (m *AthenaTableManager) AddPartition(ctx context.Context, tableName, start, end, location string) error {
+	location, err := athena.S3Location(location)
+	if err != nil {
+		return err
+	}
+	return reportingutil.AddAWSHivePartition(ctx, m.queryer, tableName, start, end, location)
+}
+
+func (m *AthenaTableManager) DropPartition(ctx context.Context, tableName, start, end string) error {
+	return reportingutil.DropAWSHivePartition(ctx, m.queryer, tableName, start, end)
+}
+
+func (m *AthenaTableManager) RepairTable(ctx context.Context, tableName string) error {
+	return hive.ExecuteRepairTable(ctx, m.queryer, tableName)
+}
+
+// AddColumns adds the columns to the table only, as Athena doesn't support
+// CASCADE. Athena reads the columns of partitions from the table.
+func (m *AthenaTableManager) AddColumns(ctx context.Context, params hive.TableParameters, columns []hive.Column) error {
+	return hive.ExecuteAddColumns(ctx, m.queryer, params.Name, columns, false)
+}
+
+// BigQueryTableManager manages tables in a BigQuery dataset. BigQuery stores
+// the data of it's tables itself, so the location and format of tables are
+// ignored, and dropping a table always deletes it's data.
+type BigQueryTableManager struct {
+	queryer db.Queryer
+	dataset string
+}
+
+func NewBigQueryTableManager(queryer db.Queryer, dataset string) *BigQueryTableManager {
+	return &BigQueryTableManager{queryer: queryer, dataset: dataset}
+}
+
+func (m *BigQueryTableManager) CreateTable(ctx context.Context, params hive.TableParameters, properties hive.TableProperties) error {
+	return bigquery.ExecuteCreateTable(ctx, m.queryer, params)
+}
+
+func (m *BigQueryTableManager) DropTable(ctx context.Context, tableName string, ignoreNotExists bool) error {
+	return bigquery.ExecuteDropTable(ctx, m.queryer, tableName, ignoreNotExists)
+}
+
+// SetTableExternal does nothing, as BigQuery tables are never external.
+func (m *BigQueryTableManager) SetTableExternal(ctx context.Context, tableName string, external bool) error {
+	return nil
+}
+
+func (m *BigQueryTableManager) GetTableSize(ctx context.Context, tableName string) (int64, error) {
+	return bigquery.ExecuteGetTableSize(ctx, m.queryer, m.dataset, tableName)
+}
+
+func (m *BigQueryTableManager) InsertIntoTable(ctx context.Context, tableName, selectQuery string) error {
+	return presto.InsertInto(ctx, m.queryer, tableName, selectQuery)
+}
+
+// InsertOverwritePartition deletes the rows of the partition, and then
+// inserts the results of selectQuery. Partition columns are regular columns in
+// BigQuery, so this isn't atomic.
+func (m *BigQueryTableManager) InsertOverwritePartition(ctx context.Context, tableName, partitionColumn, partitionValue, selectQuery string) error {
+	if err := bigquery.ExecuteDeleteWhere(ctx, m.queryer, tableName, partitionColumn, partitionValue); err != nil {
+		return err
+	}
+	return presto.InsertInto(ctx, m.queryer, tableName, selectQuery)
+}
+
+// DropTablePartition deletes the rows of the partition, as partition columns
+// are regular columns in BigQuery.
+func (m *BigQueryTableManager) DropTablePartition(ctx context.Context, tableName, partitionColumn, partitionValue string) error {
+	return bigquery.ExecuteDeleteWhere(ctx, m.queryer, tableName, partitionColumn, partitionValue)
+}
+
+func (m *BigQueryTableManager) AddPartition(ctx context.Context, tableName, start, end, location string) error {
+	return fmt.Errorf("unable to add partition of table %s at %s: BigQuery tables cannot read data from other locations", tableName, location)
+}
+
+func (m *BigQueryTableManager) DropPartition(ctx context.Context, tableName, start, end string) error {
+	return fmt.Errorf("unable to drop partition of table %s: BigQuery tables cannot read data from other locations", tableName)
+}
+
+// RepairTable does nothing, as BigQuery tables only contain the data inserted
+// into them.
+func (m *BigQueryTableManager) RepairTable(ctx context.Context, tableName string) error {
+	return nil
+}
+
+// AddColumns adds the columns after all of the table's columns, including
+// partition columns, which are regular columns in BigQuery.
+func (m *BigQueryTableManager) AddColumns(ctx context.Context, params hive.TableParameters, columns []hive.Column) error {
+	return bigquery.ExecuteAddColumns(ctx, m.queryer, params.Name, columns)
+}
+
+// ClickHouseTableManager manages MergeTree tables in a ClickHouse database.
+// ClickHouse stores the data of it's tables itself, so the location and format
+// of tables are ignored, and dropping a table always deletes it's data.
+type ClickHouseTableManager struct {
+	queryer db.Queryer
+}
+
+func NewClickHouseTableManager(queryer db.Queryer) *ClickHouseTableManager {
+	return &ClickHouseTableManager{queryer: queryer}
+}
+
+func (m *ClickHouseTableManager) CreateTable(ctx context.Context, params hive.TableParameters, properties hive.TableProperties) error {
+	return clickhouse.ExecuteCreateTable(ctx, m.queryer, params)
+}
+
+func (m *ClickHouseTableManager) DropTable(ctx context.Context, tableName string, ignoreNotExists bool) error {
+	return clickhouse.ExecuteDropTable(ctx, m.queryer, tableName, ignoreNotExists)
+}
+
+// SetTableExternal does nothing, as ClickHouse tables are never external.
+func (m *ClickHouseTableManager) SetTableExternal(ctx context.Context, tableName string, external bool) error {
+	return nil
+}
+
+func (m *ClickHouseTableManager) GetTableSize(ctx context.Context, tableName string) (int64, error) {
+	return clickhouse.ExecuteGetTableSize(ctx, m.queryer, tableName)
+}
+
+func (m *ClickHouseTableManager) InsertIntoTable(ctx context.Context, tableName, selectQuery string) error {
+	return presto.InsertInto(ctx, m.queryer, tableName, selectQuery)
+}
+
+// InsertOverwritePartition drops the partition, and then inserts the results
+// of selectQuery. This isn't atomic.
+func (m *ClickHouseTableManager) InsertOverwritePartition(ctx context.Context, tableName, partitionColumn, partitionValue, selectQuery string) error {
+	if err := clickhouse.ExecuteDropPartition(ctx, m.queryer, tableName, partitionValue); err != nil {
+		return err
+	}
+	return presto.InsertInto(ctx, m.queryer, tableName, selectQuery)
+}
+
+func (m *ClickHouseTableManager) DropTablePartition(ctx context.Context, tableName, partitionColumn, partitionValue string) error {
+	return clickhouse.ExecuteDropPartition(ctx, m.queryer, tableName, partitionValue)
+}
+
+func (m *ClickHouseTableManager) AddPartition(ctx context.Context, tableName, start, end, location string) error {
+	return fmt.Errorf("unable to add partition of table %s at %s: ClickHouse tables cannot read data from other locations", tableName, location)
 }
 
-func (m *HiveTableManager) DropTable(tableName string, ignoreNotExists bool) error {
-	return hive.ExecuteDropTable(m.queryer, tableName, ignoreNotExists)
+func (m *ClickHouseTableManager) DropPartition(ctx context.Context, tableName, start, end string) error {
+	return fmt.Errorf("unable to drop partition of table %s: ClickHouse tables cannot read data from other locations", tableName)
 }
 
-func (m *HiveTableManager) AddPartition(tableName, start, end, location string) error {
-	return reportingutil.AddAWSHivePartition(m.queryer, tableName, start, end, location)
+// RepairTable does nothing, as ClickHouse tables only contain the data
+// inserted into them.
+func (m *ClickHouseTableManager) RepairTable(ctx context.Context, tableName string) error {
+	return nil
 }
 
-func (m *HiveTableManager) DropPartition(tableName, start, end string) error {
-	return reportingutil.DropAWSHivePartition(m.queryer, tableName, start, end)
+func (m *ClickHouseTableManager) AddColumns(ctx context.Context, params hive.TableParameters, columns []hive.Column) error {
+	if len(params.Columns) == 0 {
+		return fmt.Errorf("unable to add columns to table %s: the table has no columns to add them after", params.Name)
+	}
+	return clickhouse.ExecuteAddColumns(ctx, m.queryer, params.Name, columns, params.Columns[len(params.Columns)-1].Name)
 }
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 	"text/template"
 	"time"
 
@@ -16,8 +18,20 @@ import (
 )
 
 type ReportQueryTemplateContext struct {
+	// TableNamespace is the namespace used when generating table and view
+	// names. If empty, names are not namespaced.
+	TableNamespace          string
 	Report                  *ReportTemplateInfo
 	DynamicDependentQueries []*cbTypes.ReportGenerationQuery
+	// Pricing is the spec of the PricingPolicy used by the pricingRate
+	// template function, if any.
+	Pricing *cbTypes.PricingPolicySpec
+	// Currency is the currency used by the currencyCode and costPrecision
+	// template functions. If unset, DefaultCurrency is used.
+	Currency Currency
+	// ClusterID is the ID of the cluster output by the clusterID template
+	// function.
+	ClusterID string
 }
 
 type ReportTemplateInfo struct {
@@ -26,16 +40,58 @@ type ReportTemplateInfo struct {
 	Inputs         map[string]interface{}
 }
 
-func newQueryTemplate(queryTemplate string) (*template.Template, error) {
+func newQueryTemplate(queryTemplate, tableNamespace string, pricing *cbTypes.PricingPolicySpec, currency Currency, clusterID string) (*template.Template, error) {
+	currency = currency.OrDefault()
 	var templateFuncMap = template.FuncMap{
-		"prestoTimestamp":                 PrestoTimestamp,
-		"prometheusMetricPartitionFormat": PrometheusMetricPartitionFormat,
-		"reportTableName":                 reportingutil.ReportTableName,
-		"scheduledReportTableName":        reportingutil.ScheduledReportTableName,
-		"dataSourceTableName":             reportingutil.DataSourceTableName,
-		"generationQueryViewName":         reportingutil.GenerationQueryViewName,
-		"billingPeriodTimestamp":          reportingutil.BillingPeriodTimestamp,
-		"renderReportGenerationQuery":     renderReportGenerationQuery,
+		"prestoTimestamp":                     PrestoTimestamp,
+		"prometheusMetricPartitionFormat":     PrometheusMetricPartitionFormat,
+		"prometheusMetricPartitionRangeStart": PrometheusMetricPartitionRangeStart,
+		"prometheusMetricPartitionRangeEnd":   PrometheusMetricPartitionRangeEnd,
+		"reportTableName": func(name string) string {
+			return reportingutil.ReportTableName(tableNamespace, name)
+		},
+		"scheduledReportTableName": func(name string) string {
+			return reportingutil.ScheduledReportTableName(tableNamespace, name)
+		},
+		"dataSourceTableName": func(name string) string {
+			return reportingutil.DataSourceTableName(tableNamespace, name)
+		},
+		"generationQueryViewName": func(name string) string {
+			return reportingutil.GenerationQueryViewName(tableNamespace, name)
+		},
+		"materializedQueryTableName": func(name string) string {
+			return reportingutil.MaterializedQueryTableName(tableNamespace, name)
+		},
+		"billingPeriodTimestamp":      reportingutil.BillingPeriodTimestamp,
+		"billingPeriodFilter":         BillingPeriodFilter,
+		"renderReportGenerationQuery": renderReportGenerationQuery,
+		"prometheusLabelToColumn":     PrometheusLabelToColumn,
+		"kubernetesLabelToPrometheus": KubernetesLabelToPrometheus,
+		"addDuration":                 AddDuration,
+		"durationSeconds":             DurationSeconds,
+		"quoteIdentifier":             QuoteIdentifier,
+		"quoteString":                 QuoteString,
+		"pricingRate": func(name string, nodeLabelsColumn ...string) (string, error) {
+			return PricingRate(pricing, name, nodeLabelsColumn...)
+		},
+		"spotNodeCondition": func(nodeLabelsColumn string) string {
+			return SpotNodeCondition(pricing, nodeLabelsColumn)
+		},
+		"storageClassPricingRate": func(storageClassColumn string) (string, error) {
+			return StorageClassPricingRate(pricing, storageClassColumn)
+		},
+		"costMarkup": func(namespaceColumn string, namespaceLabelsColumn ...string) (string, error) {
+			return CostMarkup(pricing, namespaceColumn, namespaceLabelsColumn...)
+		},
+		"currencyCode": func() string {
+			return QuoteString(currency.Code)
+		},
+		"costPrecision": func() int {
+			return currency.Precision
+		},
+		"clusterID": func() string {
+			return QuoteString(clusterID)
+		},
 	}
 
 	tmpl, err := template.New("report-generation-query").Delims("{|", "|}").Funcs(templateFuncMap).Funcs(sprig.TxtFuncMap()).Parse(queryTemplate)
@@ -46,7 +102,7 @@ func newQueryTemplate(queryTemplate string) (*template.Template, error) {
 }
 
 func RenderQuery(query string, tmplCtx *ReportQueryTemplateContext) (string, error) {
-	tmpl, err := newQueryTemplate(query)
+	tmpl, err := newQueryTemplate(query, tmplCtx.TableNamespace, tmplCtx.Pricing, tmplCtx.Currency, tmplCtx.ClusterID)
 	if err != nil {
 		return "", err
 	}
@@ -63,16 +119,20 @@ func renderTemplate(tmpl *template.Template, tmplCtx *ReportQueryTemplateContext
 }
 
 func renderReportGenerationQuery(queryName string, tmplCtx *ReportQueryTemplateContext) (string, error) {
-	var query string
+	var generationQuery *cbTypes.ReportGenerationQuery
 	for _, q := range tmplCtx.DynamicDependentQueries {
 		if q.Name == queryName {
-			query = q.Spec.Query
+			generationQuery = q
 			break
 		}
 	}
-	if query == "" {
+	if generationQuery == nil {
 		return "", fmt.Errorf("unknown ReportGenerationQuery %s", queryName)
 	}
+	query, err := GenerationQueryTemplate(generationQuery)
+	if err != nil {
+		return "", fmt.Errorf("unable to render query %s, err: %v", queryName, err)
+	}
 
 	renderedQuery, err := RenderQuery(query, tmplCtx)
 	if err != nil {
@@ -82,6 +142,13 @@ func renderReportGenerationQuery(queryName string, tmplCtx *ReportQueryTemplateC
 }
 
 func TimestampFormat(input interface{}, format string) (string, error) {
+	d, err := templateTimestamp(input)
+	return d.Format(format), err
+}
+
+// templateTimestamp converts a timestamp passed to a template function into
+// a time.Time.
+func templateTimestamp(input interface{}) (time.Time, error) {
 	var err error
 	var d time.Time
 	switch v := input.(type) {
@@ -89,21 +156,138 @@ func TimestampFormat(input interface{}, format string) (string, error) {
 		d = v
 	case *time.Time:
 		if v == nil {
-			return "", errors.New("got nil timestamp")
+			return d, errors.New("got nil timestamp")
 		}
 		d = *v
 	case string:
 		d, err = time.Parse(time.RFC3339, v)
 	default:
-		return "", fmt.Errorf("couldn't convert %#v to a Presto timestamp", input)
+		return d, fmt.Errorf("couldn't convert %#v to a Presto timestamp", input)
 	}
-	return d.Format(format), err
+	return d, err
 }
 
+// PrometheusMetricPartitionFormat returns the daily dt partition of the
+// timestamp. Queries should prefer PrometheusMetricPartitionRangeStart and
+// PrometheusMetricPartitionRangeEnd, which also work for tables which aren't
+// partitioned daily.
 func PrometheusMetricPartitionFormat(input interface{}) (string, error) {
 	return TimestampFormat(input, prestostore.PrometheusMetricTimestampPartitionFormat)
 }
 
+// PrometheusMetricPartitionRangeStart returns the lower bound of a dt filter
+// including the timestamp, for tables of any partition granularity.
+func PrometheusMetricPartitionRangeStart(input interface{}) (string, error) {
+	d, err := templateTimestamp(input)
+	if err != nil {
+		return "", err
+	}
+	return prestostore.PrometheusMetricPartitionRangeStart(d), nil
+}
+
+// PrometheusMetricPartitionRangeEnd returns the upper bound of a dt filter
+// including the timestamp, for tables of any partition granularity.
+func PrometheusMetricPartitionRangeEnd(input interface{}) (string, error) {
+	d, err := templateTimestamp(input)
+	if err != nil {
+		return "", err
+	}
+	return prestostore.PrometheusMetricPartitionRangeEnd(d), nil
+}
+
 func PrestoTimestamp(input interface{}) (string, error) {
 	return TimestampFormat(input, presto.TimestampFormat)
 }
+
+// BillingPeriodFilter returns a condition matching rows of an AWS billing
+// table whose billing period overlaps the period between start and end. By
+// default the billing_period_start and billing_period_end columns are
+// compared, which can be overridden by passing the names of the start and
+// end columns.
+func BillingPeriodFilter(start, end interface{}, columns ...string) (string, error) {
+	startColumn, endColumn := "billing_period_start", "billing_period_end"
+	switch len(columns) {
+	case 0:
+	case 2:
+		startColumn, endColumn = columns[0], columns[1]
+	default:
+		return "", fmt.Errorf("billingPeriodFilter takes either no columns or a start and end column, got %d columns", len(columns))
+	}
+	startTime, err := templateTimestamp(start)
+	if err != nil {
+		return "", err
+	}
+	endTime, err := templateTimestamp(end)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("(%s >= %s AND %s <= %s)",
+		QuoteIdentifier(endColumn),
+		QuoteString(reportingutil.BillingPeriodTimestamp(startTime)),
+		QuoteIdentifier(startColumn),
+		QuoteString(reportingutil.BillingPeriodTimestamp(endTime)),
+	), nil
+}
+
+// PrometheusLabelToColumn returns a select expression extracting a label
+// from the labels column of a Prometheus metric table. The column is named
+// after the label unless a column name is passed.
+func PrometheusLabelToColumn(label string, columnName ...string) (string, error) {
+	name := label
+	switch len(columnName) {
+	case 0:
+	case 1:
+		name = columnName[0]
+	default:
+		return "", fmt.Errorf("prometheusLabelToColumn takes at most one column name, got %d", len(columnName))
+	}
+	return fmt.Sprintf("element_at(labels, %s) AS %s", QuoteString(label), QuoteIdentifier(name)), nil
+}
+
+var invalidPrometheusLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// KubernetesLabelToPrometheus returns the name kube-state-metrics gives the
+// Prometheus label containing a Kubernetes label, such as label_cost_center
+// for cost-center in the kube_pod_labels metric.
+func KubernetesLabelToPrometheus(label string) string {
+	return "label_" + invalidPrometheusLabelChars.ReplaceAllString(label, "_")
+}
+
+// AddDuration adds a duration, such as "24h" or "-30m", to a timestamp. The
+// timestamp is the last argument so it can be piped in.
+func AddDuration(duration string, input interface{}) (time.Time, error) {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := templateTimestamp(input)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Add(d), nil
+}
+
+// DurationSeconds returns the number of seconds between two timestamps.
+func DurationSeconds(start, end interface{}) (float64, error) {
+	startTime, err := templateTimestamp(start)
+	if err != nil {
+		return 0, err
+	}
+	endTime, err := templateTimestamp(end)
+	if err != nil {
+		return 0, err
+	}
+	return endTime.Sub(startTime).Seconds(), nil
+}
+
+// QuoteIdentifier quotes a Presto identifier, such as a column or table
+// name, so it can contain any character.
+func QuoteIdentifier(identifier string) string {
+	return `"` + strings.Replace(identifier, `"`, `""`, -1) + `"`
+}
+
+// QuoteString quotes a value as a Presto string literal, so values such as
+// inputs can be used in queries safely.
+func QuoteString(value string) string {
+	return "'" + strings.Replace(value, "'", "''", -1) + "'"
+}
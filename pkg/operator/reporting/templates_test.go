@@ -0,0 +1,102 @@
+package reporting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderQueryTemplateFunctions(t *testing.T) {
+	start := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2019, time.January, 2, 0, 0, 0, 0, time.UTC)
+	tmplCtx := &ReportQueryTemplateContext{
+		Report: &ReportTemplateInfo{
+			ReportingStart: &start,
+			ReportingEnd:   &end,
+			Inputs: map[string]interface{}{
+				"Team": "o'brien",
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "prometheusLabelToColumn",
+			query:    `{| prometheusLabelToColumn "namespace" |}, {| prometheusLabelToColumn "label_team" "team" |}`,
+			expected: `element_at(labels, 'namespace') AS "namespace", element_at(labels, 'label_team') AS "team"`,
+		},
+		{
+			name:     "kubernetesLabelToPrometheus",
+			query:    `{| "app.kubernetes.io/cost-center" | kubernetesLabelToPrometheus | quoteString |}`,
+			expected: `'label_app_kubernetes_io_cost_center'`,
+		},
+		{
+			name:     "billingPeriodFilter",
+			query:    `{| billingPeriodFilter .Report.ReportingStart .Report.ReportingEnd |}`,
+			expected: `("billing_period_end" >= '20190101' AND "billing_period_start" <= '20190102')`,
+		},
+		{
+			name:     "billingPeriodFilter with columns",
+			query:    `{| billingPeriodFilter .Report.ReportingStart .Report.ReportingEnd "partition_start" "partition_stop" |}`,
+			expected: `("partition_stop" >= '20190101' AND "partition_start" <= '20190102')`,
+		},
+		{
+			name:     "addDuration",
+			query:    `{| .Report.ReportingStart | addDuration "-1h" | prestoTimestamp |}`,
+			expected: `2018-12-31 23:00:00.000`,
+		},
+		{
+			name:     "durationSeconds",
+			query:    `{| durationSeconds .Report.ReportingStart .Report.ReportingEnd |}`,
+			expected: `86400`,
+		},
+		{
+			name:     "quoting",
+			query:    `{| quoteIdentifier "my\"column" |} = {| quoteString .Report.Inputs.Team |}`,
+			expected: `"my""column" = 'o''brien'`,
+		},
+		{
+			name:     "default currency",
+			query:    `{| currencyCode |} AS currency, round(cost, {| costPrecision |})`,
+			expected: `'USD' AS currency, round(cost, 2)`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := RenderQuery(tt.query, tmplCtx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rendered)
+		})
+	}
+
+	_, err := RenderQuery(`{| addDuration "a day" .Report.ReportingStart |}`, tmplCtx)
+	assert.Error(t, err, "expected an error with an invalid duration")
+}
+
+func TestRenderQueryCurrency(t *testing.T) {
+	tmplCtx := &ReportQueryTemplateContext{Currency: Currency{Code: "JPY", Precision: 0}}
+	rendered, err := RenderQuery(`{| currencyCode |} AS currency, round(cost, {| costPrecision |})`, tmplCtx)
+	require.NoError(t, err)
+	assert.Equal(t, `'JPY' AS currency, round(cost, 0)`, rendered)
+}
+
+func TestRenderQueryClusterID(t *testing.T) {
+	rendered, err := RenderQuery(`{| clusterID |} AS cluster_id`, &ReportQueryTemplateContext{ClusterID: "it's-a-cluster"})
+	require.NoError(t, err)
+	assert.Equal(t, `'it''s-a-cluster' AS cluster_id`, rendered)
+}
+
+func TestValidateCurrency(t *testing.T) {
+	assert.NoError(t, ValidateCurrency(Currency{}))
+	assert.NoError(t, ValidateCurrency(Currency{Code: "EUR", Precision: 4}))
+	assert.Error(t, ValidateCurrency(Currency{Code: "eur", Precision: 2}), "expected a lowercase code to be invalid")
+	assert.Error(t, ValidateCurrency(Currency{Code: "EURO", Precision: 2}), "expected a 4 letter code to be invalid")
+	assert.Error(t, ValidateCurrency(Currency{Code: "EUR", Precision: -1}), "expected a negative precision to be invalid")
+}
@@ -3,9 +3,11 @@ package reporting
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	metering "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
@@ -13,8 +15,6 @@ import (
 	meteringListers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
 )
 
-const maxDepth = 100
-
 type ReportGenerationQueryDependencies struct {
 	ReportGenerationQueries        []*metering.ReportGenerationQuery
 	DynamicReportGenerationQueries []*metering.ReportGenerationQuery
@@ -40,15 +40,47 @@ func GetAndValidateGenerationQueryDependencies(
 		generationQuery,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("unable to get dependencies for ReportGenerationQuery %s: %v", generationQuery.Name, err)
+		wrappedErr := fmt.Errorf("unable to get dependencies for ReportGenerationQuery %s: %v", generationQuery.Name, err)
+		switch {
+		case IsDependencyMissing(err):
+			return nil, &DependencyMissingError{err: wrappedErr, Unresolved: UnresolvedDependencies(err)}
+		case IsDependencyCycle(err):
+			return nil, err
+		case apierrors.IsNotFound(err):
+			return nil, &DependencyMissingError{err: wrappedErr}
+		}
+		return nil, wrappedErr
 	}
 	err = ValidateGenerationQueryDependencies(deps, handler)
 	if err != nil {
-		return nil, fmt.Errorf("ReportGenerationQuery dependencies validation failed for ReportGenerationQuery %s: %v", generationQuery.Name, err)
+		wrappedErr := fmt.Errorf("ReportGenerationQuery dependencies validation failed for ReportGenerationQuery %s: %v", generationQuery.Name, err)
+		if IsDependencyMissing(err) {
+			return nil, &DependencyMissingError{err: wrappedErr}
+		}
+		return nil, wrappedErr
 	}
 	return deps, nil
 }
 
+// DependencyMissingError is returned when a ReportGenerationQuery's
+// dependencies don't exist or aren't initialized yet, rather than the
+// ReportGenerationQuery being invalid.
+type DependencyMissingError struct {
+	err error
+	// Unresolved lists the dependencies which don't exist, as kind/name.
+	Unresolved []string
+}
+
+func (e *DependencyMissingError) Error() string {
+	return e.err.Error()
+}
+
+// IsDependencyMissing returns true if err is a DependencyMissingError.
+func IsDependencyMissing(err error) bool {
+	_, ok := err.(*DependencyMissingError)
+	return ok
+}
+
 type UninitialiedDependendenciesHandler struct {
 	HandleUninitializedReportGenerationQuery func(*metering.ReportGenerationQuery)
 	HandleUninitializedReportDataSource      func(*metering.ReportDataSource)
@@ -128,108 +160,15 @@ func ValidateGenerationQueryDependencies(deps *ReportGenerationQueryDependencies
 	}
 
 	if len(errs) != 0 {
-		return fmt.Errorf("ReportGenerationQuery dependency validation error: %s", strings.Join(errs, ", "))
-	}
-	return nil
-}
-
-func GetGenerationQueryDependencies(
-	queryGetter reportGenerationQueryGetter,
-	dataSourceGetter reportDataSourceGetter,
-	reportGetter reportGetter,
-	scheduledReportGetter scheduledReportGetter,
-	generationQuery *metering.ReportGenerationQuery,
-) (*ReportGenerationQueryDependencies, error) {
-	dataSourceDeps, err := GetDependentDataSources(dataSourceGetter, generationQuery)
-	if err != nil {
-		return nil, err
-	}
-	viewQueries, viewQueriesDataSources, err := GetDependentViewGenerationQueries(queryGetter, dataSourceGetter, generationQuery)
-	if err != nil {
-		return nil, err
-	}
-	dynamicQueries, dynamicQueriesDataSources, err := GetDependentDynamicGenerationQueries(queryGetter, dataSourceGetter, generationQuery)
-	if err != nil {
-		return nil, err
-	}
-
-	allDataSources := [][]*metering.ReportDataSource{
-		dataSourceDeps,
-		viewQueriesDataSources,
-		dynamicQueriesDataSources,
-	}
-
-	// deduplicate the list of ReportDataSources
-	seen := make(map[string]struct{})
-	var dataSources []*metering.ReportDataSource
-	for _, dsList := range allDataSources {
-		for _, ds := range dsList {
-			if _, exists := seen[ds.Name]; exists {
-				continue
-			}
-			dataSources = append(dataSources, ds)
-			seen[ds.Name] = struct{}{}
+		err := fmt.Errorf("ReportGenerationQuery dependency validation error: %s", strings.Join(errs, ", "))
+		// disabled views are a configuration error, everything else
+		// will resolve itself once the dependencies are initialized
+		if len(disabledViewQueryNames) == 0 {
+			return &DependencyMissingError{err: err}
 		}
+		return err
 	}
-
-	reports, err := GetDependentReports(reportGetter, generationQuery)
-	if err != nil {
-		return nil, err
-	}
-
-	scheduledReports, err := GetDependentScheduledReports(scheduledReportGetter, generationQuery)
-	if err != nil {
-		return nil, err
-	}
-
-	return &ReportGenerationQueryDependencies{
-		ReportGenerationQueries:        viewQueries,
-		DynamicReportGenerationQueries: dynamicQueries,
-		ReportDataSources:              dataSources,
-		Reports:                        reports,
-		ScheduledReports:               scheduledReports,
-	}, nil
-}
-
-func GetDependentViewGenerationQueries(queryGetter reportGenerationQueryGetter, dataSourceGetter reportDataSourceGetter, generationQuery *metering.ReportGenerationQuery) ([]*metering.ReportGenerationQuery, []*metering.ReportDataSource, error) {
-	viewReportQueriesAccumulator := make(map[string]*metering.ReportGenerationQuery)
-	dataSourcesAccumulator := make(map[string]*metering.ReportDataSource)
-	err := GetDependentGenerationQueriesWithDataSourcesMemoized(queryGetter, dataSourceGetter, generationQuery, 0, maxDepth, viewReportQueriesAccumulator, dataSourcesAccumulator, false)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	viewQueries := make([]*metering.ReportGenerationQuery, 0, len(viewReportQueriesAccumulator))
-	for _, query := range viewReportQueriesAccumulator {
-		viewQueries = append(viewQueries, query)
-	}
-	dataSources := make([]*metering.ReportDataSource, 0, len(dataSourcesAccumulator))
-	for _, ds := range dataSourcesAccumulator {
-		dataSources = append(dataSources, ds)
-	}
-
-	return viewQueries, dataSources, nil
-}
-
-func GetDependentDynamicGenerationQueries(queryGetter reportGenerationQueryGetter, dataSourceGetter reportDataSourceGetter, generationQuery *metering.ReportGenerationQuery) ([]*metering.ReportGenerationQuery, []*metering.ReportDataSource, error) {
-	dynamicReportQueriesAccumulator := make(map[string]*metering.ReportGenerationQuery)
-	dataSourcesAccumulator := make(map[string]*metering.ReportDataSource)
-	err := GetDependentGenerationQueriesWithDataSourcesMemoized(queryGetter, dataSourceGetter, generationQuery, 0, maxDepth, dynamicReportQueriesAccumulator, dataSourcesAccumulator, true)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	dynamicQueries := make([]*metering.ReportGenerationQuery, 0, len(dynamicReportQueriesAccumulator))
-	for _, query := range dynamicReportQueriesAccumulator {
-		dynamicQueries = append(dynamicQueries, query)
-	}
-
-	dataSources := make([]*metering.ReportDataSource, 0, len(dataSourcesAccumulator))
-	for _, ds := range dataSourcesAccumulator {
-		dataSources = append(dataSources, ds)
-	}
-
-	return dynamicQueries, dataSources, nil
+	return nil
 }
 
 type reportGenerationQueryGetter interface {
@@ -254,65 +193,6 @@ func NewReportGenerationQueryClientGetter(getter meteringClient.ReportGeneration
 	})
 }
 
-func GetDependentGenerationQueriesWithDataSourcesMemoized(queryGetter reportGenerationQueryGetter, dataSourceGetter reportDataSourceGetter, generationQuery *metering.ReportGenerationQuery, depth, maxDepth int, queriesAccumulator map[string]*metering.ReportGenerationQuery, dataSourceAccumulator map[string]*metering.ReportDataSource, dynamicQueries bool) error {
-	if depth >= maxDepth {
-		return fmt.Errorf("detected a cycle at depth %d for generationQuery %s", depth, generationQuery.Name)
-	}
-	var queries []string
-	if dynamicQueries {
-		queries = generationQuery.Spec.DynamicReportQueries
-	} else {
-		queries = generationQuery.Spec.ReportQueries
-	}
-	for _, queryName := range queries {
-		if _, exists := queriesAccumulator[queryName]; exists {
-			continue
-		}
-		genQuery, err := queryGetter.getReportGenerationQuery(generationQuery.Namespace, queryName)
-		if err != nil {
-			return err
-		}
-		// get dependent ReportDataSources
-		err = GetDependentDataSourcesMemoized(dataSourceGetter, genQuery, dataSourceAccumulator)
-		if err != nil {
-			return err
-		}
-		err = GetDependentGenerationQueriesWithDataSourcesMemoized(queryGetter, dataSourceGetter, genQuery, depth+1, maxDepth, queriesAccumulator, dataSourceAccumulator, dynamicQueries)
-		if err != nil {
-			return err
-		}
-		queriesAccumulator[genQuery.Name] = genQuery
-	}
-	return nil
-}
-
-func GetDependentGenerationQueriesMemoized(queryGetter reportGenerationQueryGetter, generationQuery *metering.ReportGenerationQuery, depth, maxDepth int, queriesAccumulator map[string]*metering.ReportGenerationQuery, dynamicQueries bool) error {
-	if depth >= maxDepth {
-		return fmt.Errorf("detected a cycle at depth %d for generationQuery %s", depth, generationQuery.Name)
-	}
-	var queries []string
-	if dynamicQueries {
-		queries = generationQuery.Spec.DynamicReportQueries
-	} else {
-		queries = generationQuery.Spec.ReportQueries
-	}
-	for _, queryName := range queries {
-		if _, exists := queriesAccumulator[queryName]; exists {
-			continue
-		}
-		genQuery, err := queryGetter.getReportGenerationQuery(generationQuery.Namespace, queryName)
-		if err != nil {
-			return err
-		}
-		err = GetDependentGenerationQueriesMemoized(queryGetter, genQuery, depth+1, maxDepth, queriesAccumulator, dynamicQueries)
-		if err != nil {
-			return err
-		}
-		queriesAccumulator[genQuery.Name] = genQuery
-	}
-	return nil
-}
-
 type reportDataSourceGetter interface {
 	getReportDataSource(namespace, name string) (*metering.ReportDataSource, error)
 }
@@ -335,33 +215,6 @@ func NewReportDataSourceClientGetter(getter meteringClient.ReportDataSourcesGett
 	})
 }
 
-func GetDependentDataSourcesMemoized(dataSourceGetter reportDataSourceGetter, generationQuery *metering.ReportGenerationQuery, dataSourceAccumulator map[string]*metering.ReportDataSource) error {
-	for _, dataSourceName := range generationQuery.Spec.DataSources {
-		if _, exists := dataSourceAccumulator[dataSourceName]; exists {
-			continue
-		}
-		dataSource, err := dataSourceGetter.getReportDataSource(generationQuery.Namespace, dataSourceName)
-		if err != nil {
-			return err
-		}
-		dataSourceAccumulator[dataSource.Name] = dataSource
-	}
-	return nil
-}
-
-func GetDependentDataSources(dataSourceGetter reportDataSourceGetter, generationQuery *metering.ReportGenerationQuery) ([]*metering.ReportDataSource, error) {
-	dataSourceAccumulator := make(map[string]*metering.ReportDataSource)
-	err := GetDependentDataSourcesMemoized(dataSourceGetter, generationQuery, dataSourceAccumulator)
-	if err != nil {
-		return nil, err
-	}
-	dataSources := make([]*metering.ReportDataSource, 0, len(dataSourceAccumulator))
-	for _, ds := range dataSourceAccumulator {
-		dataSources = append(dataSources, ds)
-	}
-	return dataSources, nil
-}
-
 type reportGetter interface {
 	getReport(namespace, name string) (*metering.Report, error)
 }
@@ -384,18 +237,6 @@ func NewReportClientGetter(getter meteringClient.ReportsGetter) reportGetter {
 	})
 }
 
-func GetDependentReports(reportGetter reportGetter, generationQuery *metering.ReportGenerationQuery) ([]*metering.Report, error) {
-	reports := make([]*metering.Report, len(generationQuery.Spec.Reports))
-	for i, reportName := range generationQuery.Spec.Reports {
-		report, err := reportGetter.getReport(generationQuery.Namespace, reportName)
-		if err != nil {
-			return nil, err
-		}
-		reports[i] = report
-	}
-	return reports, nil
-}
-
 type scheduledReportGetter interface {
 	getScheduledReport(namespace, name string) (*metering.ScheduledReport, error)
 }
@@ -418,56 +259,137 @@ func NewScheduledReportClientGetter(getter meteringClient.ScheduledReportsGetter
 	})
 }
 
-func GetDependentScheduledReports(scheduledReportGetter scheduledReportGetter, generationQuery *metering.ReportGenerationQuery) ([]*metering.ScheduledReport, error) {
-	scheduledReports := make([]*metering.ScheduledReport, len(generationQuery.Spec.ScheduledReports))
-	for i, scheduledReportName := range generationQuery.Spec.ScheduledReports {
-		scheduledReport, err := scheduledReportGetter.getScheduledReport(generationQuery.Namespace, scheduledReportName)
-		if err != nil {
-			return nil, err
-		}
-		scheduledReports[i] = scheduledReport
+// ValidateReportGenerationQueryInputs validates the inputs of a report
+// against the input definitions of the ReportGenerationQuery, returning the
+// values to pass to the query template converted to the types of their
+// definitions. Inputs which aren't set use their default, if any.
+func ValidateReportGenerationQueryInputs(generationQuery *metering.ReportGenerationQuery, inputs []metering.ReportGenerationQueryInputValue) (map[string]interface{}, error) {
+	definitions := make(map[string]metering.ReportGenerationQueryInputDefinition, len(generationQuery.Spec.Inputs))
+	for _, def := range generationQuery.Spec.Inputs {
+		definitions[def.Name] = def
 	}
-	return scheduledReports, nil
-}
 
-func ValidateReportGenerationQueryInputs(generationQuery *metering.ReportGenerationQuery, inputs []metering.ReportGenerationQueryInputValue) (map[string]interface{}, error) {
-	var givenInputs, missingInputs, expectedInputs []string
+	var errs []string
 	reportQueryInputs := make(map[string]interface{})
 	for _, v := range inputs {
-		// currently inputs can only have string values, but we want to support
-		// other types in the future.
-		// To support overriding the default ReportingStart and ReportingEnd
-		// using inputs, we have to treat them specially and turn them into
-		// time.Time objects before passing to the template context.
-		if v.Name == ReportingStartInputName || v.Name == ReportingEndInputName {
-			tVal, err := time.Parse(time.RFC3339, v.Value)
-			if err != nil {
-				return nil, fmt.Errorf("inputs Name: %s is not a valid timestamp: %s, must be RFC3339 formatted, err: %s", v.Name, v.Value, err)
-			}
-			reportQueryInputs[v.Name] = tVal
-		} else {
-			reportQueryInputs[v.Name] = v.Value
+		val, err := convertInputValue(v.Name, inputType(definitions[v.Name]), v.Value)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
 		}
-		givenInputs = append(givenInputs, v.Name)
+		reportQueryInputs[v.Name] = val
 	}
 
 	// now validate the inputs match what the query is expecting
-	for _, input := range generationQuery.Spec.Inputs {
-		expectedInputs = append(expectedInputs, input.Name)
-		// If the input isn't required than don't include it in the missing
-		if !input.Required {
+	var missingInputs []string
+	for _, def := range generationQuery.Spec.Inputs {
+		if _, ok := reportQueryInputs[def.Name]; ok {
 			continue
 		}
-		if _, ok := reportQueryInputs[input.Name]; !ok {
-			missingInputs = append(missingInputs, input.Name)
+		if def.Default != nil {
+			val, err := convertInputValue(def.Name, inputType(def), *def.Default)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("default of %s", err))
+				continue
+			}
+			reportQueryInputs[def.Name] = val
+		} else if def.Required {
+			missingInputs = append(missingInputs, def.Name)
 		}
 	}
-
 	if len(missingInputs) != 0 {
-		sort.Strings(expectedInputs)
-		sort.Strings(givenInputs)
-		return nil, fmt.Errorf("unable to validate ReportGenerationQuery %s inputs: requires %s as inputs, got %s", generationQuery.Name, strings.Join(expectedInputs, ","), strings.Join(givenInputs, ","))
+		sort.Strings(missingInputs)
+		errs = append(errs, fmt.Sprintf("missing required inputs: %s", strings.Join(missingInputs, ", ")))
 	}
 
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("unable to validate ReportGenerationQuery %s inputs: %s", generationQuery.Name, strings.Join(errs, ", "))
+	}
 	return reportQueryInputs, nil
 }
+
+// ValidateReportGenerationQueryInputDefinitions returns an error if any of
+// the ReportGenerationQuery's input definitions have an unknown type or a
+// default which isn't valid for it's type.
+func ValidateReportGenerationQueryInputDefinitions(generationQuery *metering.ReportGenerationQuery) error {
+	var errs []string
+	seen := make(map[string]bool)
+	for _, def := range generationQuery.Spec.Inputs {
+		if def.Name == "" {
+			errs = append(errs, "input name cannot be empty")
+			continue
+		}
+		if seen[def.Name] {
+			errs = append(errs, fmt.Sprintf("input %s is defined more than once", def.Name))
+		}
+		seen[def.Name] = true
+		typ := inputType(def)
+		if (def.Name == ReportingStartInputName || def.Name == ReportingEndInputName) && typ != metering.ReportGenerationQueryInputTypeTime {
+			errs = append(errs, fmt.Sprintf("input %s must have type %s", def.Name, metering.ReportGenerationQueryInputTypeTime))
+			continue
+		}
+		if !inputTypes[typ] {
+			errs = append(errs, unknownInputTypeError(def.Name, typ).Error())
+			continue
+		}
+		if def.Default != nil {
+			if _, err := convertInputValue(def.Name, typ, *def.Default); err != nil {
+				errs = append(errs, fmt.Sprintf("default of %s", err))
+			}
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("invalid spec.inputs: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// inputType returns the type of an input. The ReportingStart and
+// ReportingEnd inputs are times unless specified otherwise, so they can
+// override the reporting period.
+func inputType(def metering.ReportGenerationQueryInputDefinition) string {
+	if def.Type != "" {
+		return def.Type
+	}
+	if def.Name == ReportingStartInputName || def.Name == ReportingEndInputName {
+		return metering.ReportGenerationQueryInputTypeTime
+	}
+	return metering.ReportGenerationQueryInputTypeString
+}
+
+var inputTypes = map[string]bool{
+	metering.ReportGenerationQueryInputTypeString:  true,
+	metering.ReportGenerationQueryInputTypeInteger: true,
+	metering.ReportGenerationQueryInputTypeFloat:   true,
+	metering.ReportGenerationQueryInputTypeBoolean: true,
+	metering.ReportGenerationQueryInputTypeTime:    true,
+}
+
+func unknownInputTypeError(name, typ string) error {
+	return fmt.Errorf("unknown type %q for input %s, must be one of: string, integer, float, boolean or time", typ, name)
+}
+
+func convertInputValue(name, typ, value string) (interface{}, error) {
+	var (
+		val interface{}
+		err error
+	)
+	switch typ {
+	case metering.ReportGenerationQueryInputTypeString:
+		return value, nil
+	case metering.ReportGenerationQueryInputTypeInteger:
+		val, err = strconv.ParseInt(value, 10, 64)
+	case metering.ReportGenerationQueryInputTypeFloat:
+		val, err = strconv.ParseFloat(value, 64)
+	case metering.ReportGenerationQueryInputTypeBoolean:
+		val, err = strconv.ParseBool(value)
+	case metering.ReportGenerationQueryInputTypeTime:
+		val, err = time.Parse(time.RFC3339, value)
+	default:
+		return nil, unknownInputTypeError(name, typ)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("input %s: %q is not a valid %s", name, value, typ)
+	}
+	return val, nil
+}
@@ -2,8 +2,10 @@ package reporting
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	metering "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
 	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
@@ -15,15 +17,15 @@ func TestValidateGenerationQueryDependencies(t *testing.T) {
 	reportQueryViewDisabled := testhelpers.NewReportGenerationQuery("query-view-disabled", "default", nil)
 	reportQueryViewDisabled.Spec.View.Disabled = true
 	reportQueryViewSet := testhelpers.NewReportGenerationQuery("initialized-query", "default", nil)
-	reportQueryViewSet.Status.ViewName = reportingutil.GenerationQueryViewName("initialized-query")
+	reportQueryViewSet.Status.ViewName = reportingutil.GenerationQueryViewName("", "initialized-query")
 
 	dataSourceTableUnset := testhelpers.NewReportDataSource("uninitialized-datasource", "default")
 	dataSourceTableSet := testhelpers.NewReportDataSource("initialized-datasource", "default")
-	dataSourceTableSet.Status.TableName = reportingutil.DataSourceTableName("initialized-datasource")
+	dataSourceTableSet.Status.TableName = reportingutil.DataSourceTableName("", "initialized-datasource")
 
 	reportTableUnset := testhelpers.NewReport("uninitialized-report", "default", "some-query", nil, nil, metering.ReportStatus{})
 	reportTableSet := testhelpers.NewReport("initialized-report", "default", "some-query", nil, nil, metering.ReportStatus{
-		TableName: reportingutil.ReportTableName("initialized-report"),
+		TableName: reportingutil.ReportTableName("", "initialized-report"),
 	})
 
 	// we keep a set of our test objects here since we re-use them in different
@@ -147,3 +149,48 @@ func TestValidateGenerationQueryDependencies(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateReportGenerationQueryInputs(t *testing.T) {
+	defaultThreshold := "0.5"
+	query := testhelpers.NewReportGenerationQuery("typed-inputs", "default", nil)
+	query.Spec.Inputs = []metering.ReportGenerationQueryInputDefinition{
+		{Name: ReportingStartInputName},
+		{Name: "Namespace", Required: true},
+		{Name: "Limit", Type: metering.ReportGenerationQueryInputTypeInteger},
+		{Name: "Threshold", Type: metering.ReportGenerationQueryInputTypeFloat, Default: &defaultThreshold},
+		{Name: "IncludeIdle", Type: metering.ReportGenerationQueryInputTypeBoolean},
+	}
+	require.NoError(t, ValidateReportGenerationQueryInputDefinitions(query))
+
+	inputs, err := ValidateReportGenerationQueryInputs(query, []metering.ReportGenerationQueryInputValue{
+		{Name: ReportingStartInputName, Value: "2019-01-01T00:00:00Z"},
+		{Name: "Namespace", Value: "metering"},
+		{Name: "Limit", Value: "10"},
+		{Name: "IncludeIdle", Value: "true"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		ReportingStartInputName: time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+		"Namespace":             "metering",
+		"Limit":                 int64(10),
+		"Threshold":             0.5,
+		"IncludeIdle":           true,
+	}, inputs)
+
+	_, err = ValidateReportGenerationQueryInputs(query, []metering.ReportGenerationQueryInputValue{
+		{Name: "Limit", Value: "ten"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `input Limit: "ten" is not a valid integer`)
+	assert.Contains(t, err.Error(), "missing required inputs: Namespace")
+
+	invalidDefault := "lots"
+	query.Spec.Inputs = append(query.Spec.Inputs,
+		metering.ReportGenerationQueryInputDefinition{Name: "Cost", Type: "money"},
+		metering.ReportGenerationQueryInputDefinition{Name: "Max", Type: metering.ReportGenerationQueryInputTypeInteger, Default: &invalidDefault},
+	)
+	err = ValidateReportGenerationQueryInputDefinitions(query)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown type "money" for input Cost`)
+	assert.Contains(t, err.Error(), `default of input Max: "lots" is not a valid integer`)
+}
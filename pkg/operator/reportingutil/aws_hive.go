@@ -1,6 +1,7 @@
 package reportingutil
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -36,19 +37,19 @@ var (
 
 // AddAWSHivePartition will add a new partition to the given tableName for the time
 // range, pointing at the location
-func AddAWSHivePartition(queryer db.Queryer, tableName, start, end, location string) error {
+func AddAWSHivePartition(ctx context.Context, queryer db.Queryer, tableName, start, end, location string) error {
 	partitionStr := "ALTER TABLE %s ADD IF NOT EXISTS PARTITION (`billing_period_start`='%s',`billing_period_end`='%s') LOCATION '%s'"
 	stmt := fmt.Sprintf(partitionStr, tableName, start, end, location)
-	_, err := queryer.Query(stmt)
+	_, err := queryer.QueryContext(ctx, stmt)
 	return err
 }
 
 // DropAWSHivePartition will delete a partition from the given tableName for the time
 // range, pointing at the location
-func DropAWSHivePartition(queryer db.Queryer, tableName, start, end string) error {
+func DropAWSHivePartition(ctx context.Context, queryer db.Queryer, tableName, start, end string) error {
 	partitionStr := "ALTER TABLE %s DROP IF EXISTS PARTITION (`billing_period_start`='%s',`billing_period_end`='%s')"
 	stmt := fmt.Sprintf(partitionStr, tableName, start, end)
-	_, err := queryer.Query(stmt)
+	_, err := queryer.QueryContext(ctx, stmt)
 	return err
 }
 
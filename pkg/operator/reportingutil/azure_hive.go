@@ -0,0 +1,44 @@
+package reportingutil
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// AzureCostManagementHiveSerde is the Hadoop serialization/deserialization
+	// implementation used with Azure Cost Management exports, which quote
+	// fields containing commas.
+	AzureCostManagementHiveSerde = "org.apache.hadoop.hive.serde2.OpenCSVSerde"
+)
+
+var (
+	// AzureCostManagementHiveSerdeProps configure the SerDe used with Azure
+	// Cost Management exports.
+	AzureCostManagementHiveSerdeProps = map[string]string{
+		"separatorChar": ",",
+		"quoteChar":     `"`,
+		"escapeChar":    "\\",
+	}
+
+	// AzureCostManagementHiveTableProps skip the header line of each file
+	// in an export.
+	AzureCostManagementHiveTableProps = map[string]string{
+		"skip.header.line.count": "1",
+	}
+
+	// AzureCostManagementHivePartitions are the same as AWS billing data,
+	// allowing the same partition manager to be used.
+	AzureCostManagementHivePartitions = AWSUsageHivePartitions
+
+	invalidHiveColumnCharacters = regexp.MustCompile("[^a-z0-9_]+")
+)
+
+// SanetizeAzureColumnForHive converts the name of a column in an Azure Cost
+// Management export to an identifier allowed in hive SQL. Names are converted
+// to lowercase, and any characters other than letters, numbers and
+// underscores are replaced with underscores.
+func SanetizeAzureColumnForHive(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return invalidHiveColumnCharacters.ReplaceAllString(name, "_")
+}
@@ -0,0 +1,12 @@
+package reportingutil
+
+const (
+	// CSVHiveSerde is the Hadoop serialization/deserialization implementation
+	// used with File ReportDataSources containing CSV files. It only supports
+	// string columns.
+	CSVHiveSerde = "org.apache.hadoop.hive.serde2.OpenCSVSerde"
+	// JSONHiveSerde is the Hadoop serialization/deserialization
+	// implementation used with File ReportDataSources containing JSON files,
+	// which must contain one object per line.
+	JSONHiveSerde = "org.apache.hive.hcatalog.data.JsonSerDe"
+)
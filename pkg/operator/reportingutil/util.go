@@ -1,6 +1,8 @@
 package reportingutil
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"time"
@@ -12,20 +14,47 @@ import (
 
 var resourceNameReplacer = strings.NewReplacer("-", "_", ".", "_")
 
-func DataSourceTableName(dataSourceName string) string {
-	return fmt.Sprintf("datasource_%s", resourceNameReplacer.Replace(dataSourceName))
+// namespacedName returns name with the namespace prepended, separated by two
+// underscores. Since dashes and dots are replaced with underscores, a
+// namespace or name containing consecutive dashes, or a namespace ending or
+// name starting with one, would make the separator ambiguous, for example
+// "a"/"b--c" and "a--b"/"c", so in that case a hash of the namespace and name
+// is appended. If namespace is empty, name is returned unchanged.
+func namespacedName(namespace, name string) string {
+	replacedName := resourceNameReplacer.Replace(name)
+	if namespace == "" {
+		return replacedName
+	}
+	replacedNamespace := resourceNameReplacer.Replace(namespace)
+	result := fmt.Sprintf("%s__%s", replacedNamespace, replacedName)
+	if strings.Contains(replacedNamespace, "__") || strings.HasSuffix(replacedNamespace, "_") ||
+		strings.Contains(replacedName, "__") || strings.HasPrefix(replacedName, "_") {
+		// otherwise the result contains two consecutive underscores exactly
+		// once, so it can't be the same as any of the hashed names
+		sum := sha256.Sum256([]byte(namespace + "/" + name))
+		result += "_" + hex.EncodeToString(sum[:6])
+	}
+	return result
+}
+
+func DataSourceTableName(namespace, dataSourceName string) string {
+	return fmt.Sprintf("datasource_%s", namespacedName(namespace, dataSourceName))
+}
+
+func ReportTableName(namespace, reportName string) string {
+	return fmt.Sprintf("report_%s", namespacedName(namespace, reportName))
 }
 
-func ReportTableName(reportName string) string {
-	return fmt.Sprintf("report_%s", resourceNameReplacer.Replace(reportName))
+func ScheduledReportTableName(namespace, reportName string) string {
+	return fmt.Sprintf("scheduled_report_%s", namespacedName(namespace, reportName))
 }
 
-func ScheduledReportTableName(reportName string) string {
-	return fmt.Sprintf("scheduled_report_%s", resourceNameReplacer.Replace(reportName))
+func MaterializedQueryTableName(namespace, queryName string) string {
+	return fmt.Sprintf("materialized_%s", namespacedName(namespace, queryName))
 }
 
-func GenerationQueryViewName(queryName string) string {
-	return fmt.Sprintf("view_%s", resourceNameReplacer.Replace(queryName))
+func GenerationQueryViewName(namespace, queryName string) string {
+	return fmt.Sprintf("view_%s", namespacedName(namespace, queryName))
 }
 
 func PrestoTableResourceNameFromKind(kind, name string) string {
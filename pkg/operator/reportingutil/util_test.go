@@ -1,6 +1,8 @@
 package reportingutil
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"testing"
 
@@ -127,3 +129,54 @@ func TestHiveColumnToPrestoColumn(t *testing.T) {
 		})
 	}
 }
+
+func TestTableNames(t *testing.T) {
+	tests := map[string]struct {
+		namespace string
+		name      string
+		expected  string
+	}{
+		"no namespace": {
+			name:     "pod-cpu.usage",
+			expected: "datasource_pod_cpu_usage",
+		},
+		"namespace": {
+			namespace: "team-a",
+			name:      "pod-cpu",
+			expected:  "datasource_team_a__pod_cpu",
+		},
+		"namespace and name with consecutive dashes": {
+			namespace: "a",
+			name:      "b--c",
+			expected:  "datasource_a__b__c_" + shortHash("a/b--c"),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DataSourceTableName(tt.namespace, tt.name))
+		})
+	}
+}
+
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:6])
+}
+
+func TestTableNamesAreUnique(t *testing.T) {
+	names := map[string]string{}
+	for _, resource := range [][2]string{
+		{"a", "b--c"},
+		{"a--b", "c"},
+		{"a-b", "c"},
+		{"a", "b--c-"},
+		{"a-", "b"},
+		{"a", "-b"},
+	} {
+		tableName := DataSourceTableName(resource[0], resource[1])
+		other, exists := names[tableName]
+		assert.False(t, exists, "expected %s/%s and %s to have different table names, both are %s", resource[0], resource[1], other, tableName)
+		names[tableName] = resource[0] + "/" + resource[1]
+	}
+}
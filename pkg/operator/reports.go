@@ -1,6 +1,7 @@
 package operator
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -11,8 +12,18 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
 	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
 	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
+	"github.com/operator-framework/operator-metering/pkg/tracing"
+	"github.com/operator-framework/operator-metering/pkg/util/slice"
+)
+
+const (
+	reportFinalizer = cbTypes.GroupName + "/report"
+	// generationQueryTextsMaxEntries is the number of ReportGenerationQuery
+	// versions whose text is held for the query diff API.
+	generationQueryTextsMaxEntries = 1000
 )
 
 var (
@@ -56,10 +67,9 @@ func init() {
 }
 
 func (op *Reporting) runReportWorker() {
-	logger := op.logger.WithField("component", "reportWorker")
+	logger := op.logger.WithField(logFieldController, "report")
 	logger.Infof("Report worker started")
-	const maxRequeues = 5
-	for op.processResource(logger, op.syncReport, "Report", op.reportQueue, maxRequeues) {
+	for op.processResource(logger, op.syncReport, "Report", op.reportQueue, op.cfg.MaxRetriesConfig.Report) {
 	}
 }
 
@@ -75,7 +85,6 @@ func (op *Reporting) syncReport(logger log.FieldLogger, key string) error {
 		return nil
 	}
 
-	logger = logger.WithField("Report", name)
 	report, err := op.reportLister.Reports(namespace).Get(name)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
@@ -86,11 +95,36 @@ func (op *Reporting) syncReport(logger log.FieldLogger, key string) error {
 	}
 
 	r := report.DeepCopy()
+
+	if r.DeletionTimestamp != nil {
+		// Reports created before finalizers were enabled, or whose
+		// finalizer was removed by someone else, aren't cleaned up
+		if !slice.ContainsString(r.Finalizers, reportFinalizer, nil) {
+			logger.Infof("Report is marked for deletion without the %s finalizer, skipping cleanup", reportFinalizer)
+			return nil
+		}
+		logger.Infof("Report is marked for deletion, performing cleanup")
+		err := op.dropReportTable(logger, r)
+		if err != nil {
+			return err
+		}
+		_, err = op.removeReportFinalizer(r)
+		return err
+	}
+
 	return op.handleReport(logger, r)
 }
 
 func (op *Reporting) handleReport(logger log.FieldLogger, report *cbTypes.Report) error {
-	tableName := reportingutil.ReportTableName(report.Name)
+	if op.cfg.EnableFinalizers && reportNeedsFinalizer(report) {
+		var err error
+		report, err = op.addReportFinalizer(report)
+		if err != nil {
+			return err
+		}
+	}
+
+	tableName := reportingutil.ReportTableName(op.tableNamespace(report.Namespace), report.Name)
 	metricLabels := prometheus.Labels{
 		"report":                report.Name,
 		"reportgenerationquery": report.Spec.GenerationQueryName,
@@ -125,8 +159,16 @@ func (op *Reporting) handleReport(logger log.FieldLogger, report *cbTypes.Report
 				return nil
 			}
 
+			// chunked reports record each chunk they complete, so they
+			// can resume from the last completed chunk
+			if newReport.Status.Chunks != nil {
+				logger.Infof("resuming chunked report from chunk %d/%d", newReport.Status.Chunks.Completed+1, newReport.Status.Chunks.Total)
+				report = newReport
+				break
+			}
+
 			err = fmt.Errorf("unable to determine if report generation succeeded")
-			op.setReportError(logger, report, err, "found already started report, report generation likely failed while processing")
+			op.setReportError(logger, report, cbutil.UnknownErrorReason, err, "found already started report, report generation likely failed while processing")
 			return nil
 		}
 	case cbTypes.ReportPhaseFinished, cbTypes.ReportPhaseError:
@@ -138,6 +180,15 @@ func (op *Reporting) handleReport(logger log.FieldLogger, report *cbTypes.Report
 
 	now := op.clock.Now()
 
+	// updates to the report would otherwise retry a failed attempt before
+	// it's backoff has passed
+	if report.Status.NextRetryTime != nil && report.Status.NextRetryTime.After(now) {
+		waitTime := report.Status.NextRetryTime.Sub(now)
+		logger.Infof("report is waiting to retry a failed attempt, ignoring until %s (%s)", report.Status.NextRetryTime.Time, waitTime)
+		op.enqueueReportAfter(report, waitTime)
+		return nil
+	}
+
 	var gracePeriod time.Duration
 	if report.Spec.GracePeriod != nil {
 		gracePeriod = report.Spec.GracePeriod.Duration
@@ -164,6 +215,8 @@ func (op *Reporting) handleReport(logger log.FieldLogger, report *cbTypes.Report
 
 		if report.Spec.RunImmediately {
 			logger.Infof("report configured to run immediately with %s until periodEnd+gracePeriod: %s", waitTime, nextRunTime)
+		} else if report.Spec.DryRun != nil {
+			logger.Infof("report configured to dry run, not waiting until periodEnd+gracePeriod: %s", nextRunTime)
 		} else if reportGracePeriodUnmet {
 			logger.Infof("report %s not past grace period yet, ignoring until %s (%s)", report.Name, nextRunTime, waitTime)
 			op.enqueueReportAfter(report, waitTime)
@@ -187,56 +240,165 @@ func (op *Reporting) handleReport(logger log.FieldLogger, report *cbTypes.Report
 		op.uninitialiedDependendenciesHandler(),
 	)
 	if err != nil {
-		return fmt.Errorf("unable to run Report %s, ReportGenerationQuery %s, failed to validate dependencies: %v", report.Name, genQuery.Name, err)
+		return reasonErrorf(err, cbutil.ValidationErrorReason, "unable to run Report %s, ReportGenerationQuery %s, failed to validate dependencies: %v", report.Name, genQuery.Name, err)
 	}
 
-	logger.Debug("updating report status to started")
-	// update status
-	report.Status.Phase = cbTypes.ReportPhaseStarted
-	report, err = op.meteringClient.MeteringV1alpha1().Reports(report.Namespace).Update(report)
+	// invalid inputs won't become valid without the Report changing, so fail
+	// the report with an explanation rather than retrying
+	if _, err := reporting.ValidateReportGenerationQueryInputs(genQuery, report.Spec.Inputs); err != nil {
+		op.setReportError(logger, report, cbutil.ValidationErrorReason, err, "invalid Report spec.inputs")
+		return nil
+	}
+
+	var chunks []reportChunk
+	if report.Spec.ChunkSize != nil {
+		if reportingStart == nil || reportingEnd == nil {
+			err = fmt.Errorf("spec.chunkSize requires spec.reportingStart and spec.reportingEnd to be set")
+		} else {
+			chunks, err = reportChunks(*reportingStart, *reportingEnd, report.Spec.ChunkSize.Duration)
+		}
+		if err != nil {
+			op.setReportError(logger, report, cbutil.ValidationErrorReason, err, "invalid Report spec.chunkSize")
+			return nil
+		}
+	}
+
+	limits, err := reportExecutionLimits(report.Spec.MaxRuntime, report.Spec.MaxResultRows)
 	if err != nil {
-		return fmt.Errorf("failed to update report status to started for %q", report.Name)
+		op.setReportError(logger, report, cbutil.ValidationErrorReason, err, "invalid Report execution limits")
+		return nil
+	}
+
+	if err := validateReportRetryPolicy(report.Spec.RetryPolicy); err != nil {
+		op.setReportError(logger, report, cbutil.ValidationErrorReason, err, "invalid Report spec.retryPolicy")
+		return nil
 	}
 
-	logger.Debugf("dropping table %s", tableName)
-	err = op.tableManager.DropTable(tableName, true)
+	pricing, err := op.getPricingPolicy(report.Namespace, report.Spec.PricingPolicy)
 	if err != nil {
-		return fmt.Errorf("unable to drop table %s before creating for report %s: %v", tableName, report.Name, err)
+		op.setReportError(logger, report, cbutil.ValidationErrorReason, err, "invalid Report spec.pricingPolicy")
+		return nil
 	}
 
-	columns := reportingutil.GenerateHiveColumns(genQuery)
-	err = op.createTableForStorage(logger, report, cbTypes.SchemeGroupVersion.WithKind("Report"), report.Spec.Output, tableName, columns, nil)
+	release, err := op.acquireReportSlot(logger)
 	if err != nil {
-		return fmt.Errorf("unable to create table %s for report %s: %v", tableName, report.Name, err)
+		return err
+	}
+	defer release()
+
+	if report.Spec.DryRun != nil {
+		return op.dryRunReport(logger, report, reportingStart, reportingEnd, genQuery, queryDependencies, limits, pricing)
 	}
 
-	report.Status.TableName = tableName
-	report, err = op.meteringClient.MeteringV1alpha1().Reports(report.Namespace).Update(report)
+	key, err := cache.MetaNamespaceKeyFunc(report)
 	if err != nil {
-		return fmt.Errorf("failed to update report %s status.tableName to %s: %v", report.Name, tableName, err)
+		return err
+	}
+	queryCtx, cancel := op.newReportQueryContext(logger, "Report", key)
+	defer cancel()
+	queryCtx = reporting.WithExecutionLimits(queryCtx, limits)
+	queryCtx = reporting.WithQueryPriority(queryCtx, reportQueryPriority(reporting.ReportQueryClass, reportingStart, reportingEnd))
+	queryCtx = reporting.WithPricing(queryCtx, pricing)
+
+	// a resumed chunked report already has it's table and is only missing
+	// the results of it's remaining chunks
+	if report.Status.Chunks == nil {
+		logger.Debug("updating report status to started")
+		// update status
+		report.Status.Phase = cbTypes.ReportPhaseStarted
+		report, err = op.meteringClient.MeteringV1alpha1().Reports(report.Namespace).Update(report)
+		if err != nil {
+			return fmt.Errorf("failed to update report status to started for %q", report.Name)
+		}
+
+		logger.Debugf("dropping table %s", tableName)
+		err = traceCall(queryCtx, "hive.dropTable", func() error {
+			return op.tableManager.DropTable(queryCtx, tableName, true)
+		}, tracing.String("db.table", tableName))
+		if err != nil {
+			return reasonErrorf(err, cbutil.HiveErrorReason, "unable to drop table %s before creating for report %s: %v", tableName, report.Name, err)
+		}
+
+		columns := reportingutil.GenerateHiveColumns(genQuery)
+		err = traceCall(queryCtx, "hive.createTable", func() error {
+			return op.createTableForStorage(queryCtx, logger, report, cbTypes.SchemeGroupVersion.WithKind("Report"), report.Spec.Output, tableName, columns, nil)
+		}, tracing.String("db.table", tableName))
+		if err != nil {
+			return reasonErrorf(err, cbutil.HiveErrorReason, "unable to create table %s for report %s: %v", tableName, report.Name, err)
+		}
+
+		report.Status.TableName = tableName
+		report.Status.GenerationQueryVersions = reporting.GetGenerationQueryVersions(genQuery, queryDependencies, op.generationQueryTexts)
+		report, err = op.meteringClient.MeteringV1alpha1().Reports(report.Namespace).Update(report)
+		if err != nil {
+			return fmt.Errorf("failed to update report %s status.tableName to %s: %v", report.Name, tableName, err)
+		}
+	}
+
+	if chunks != nil && report.Status.Chunks == nil {
+		// the table was just created, so it has no rows
+		var rows int64
+		report.Status.Chunks = &cbTypes.ReportChunksStatus{Total: len(chunks), CompletedRows: &rows}
 	}
 
 	genReportTotalCounter.Inc()
 	generateReportStart := op.clock.Now()
-	err = op.reportGenerator.GenerateReport(
-		tableName,
-		reportingStart,
-		reportingEnd,
-		genQuery,
-		queryDependencies.DynamicReportGenerationQueries,
-		report.Spec.Inputs,
-		true,
-	)
+	progress := op.startReportProgress(logger, report, generateReportStart)
+	if chunks != nil {
+		err = op.generateReportChunks(queryCtx, logger, progress, report, chunks, genQuery, queryDependencies)
+	} else {
+		err = op.reportGenerator.GenerateReport(
+			queryCtx,
+			tableName,
+			reportingStart,
+			reportingEnd,
+			genQuery,
+			queryDependencies.DynamicReportGenerationQueries,
+			report.Spec.Inputs,
+			true,
+		)
+	}
+	report = progress.stop()
 	generateReportDuration := op.clock.Since(generateReportStart)
 	genReportDurationObserver.Observe(float64(generateReportDuration.Seconds()))
+	if err != nil && op.queriesCancelledByShutdown() {
+		// the report didn't fail, so rather than recording a failed
+		// attempt, it's generated again once reporting-operator restarts.
+		// chunked reports resume from their last completed chunk.
+		logger.WithError(err).Warnf("report generation was interrupted by reporting-operator shutting down")
+		if report.Status.Chunks == nil {
+			report.Status.Phase = ""
+			if _, updateErr := op.meteringClient.MeteringV1alpha1().Reports(report.Namespace).Update(report); updateErr != nil {
+				logger.WithError(updateErr).Warnf("unable to reset the status of interrupted report %q", report.Name)
+			}
+		}
+		return err
+	}
 	if err != nil {
 		genReportFailedCounter.Inc()
-		op.setReportError(logger, report, err, "report execution failed")
-		return fmt.Errorf("failed to generateReport for Report %s, err: %v", report.Name, err)
+		reason := classifyError(err, cbutil.PrestoErrorReason)
+		if queryCtx.Err() == context.DeadlineExceeded {
+			reason = cbutil.TimeoutReason
+			err = fmt.Errorf("queries exceeded the report query timeout of %s: %v", op.config().ReportQueryTimeout, err)
+		}
+		tracing.SpanFromContext(queryCtx).SetError(err)
+		op.recordReportAttempt(report, generateReportStart, reason, err)
+		// retrying can't fix a failure caused by the report's spec
+		if !cbutil.IsUserErrorReason(reason) && op.retryReport(logger, report, err) {
+			return nil
+		}
+		op.setReportError(logger, report, reason, err, "report execution failed after %d attempt(s)", report.Status.FailedAttempts)
+		return reasonErrorf(err, reason, "failed to generateReport for Report %s, err: %v", report.Name, err)
 	}
 
 	// update status
+	op.recordReportAttempt(report, generateReportStart, "", nil)
+	report.Status.NextRetryTime = nil
 	report.Status.Phase = cbTypes.ReportPhaseFinished
+	report.Status.RowCount = op.countReportRows(queryCtx, logger, tableName)
+	if report.Status.RowCount != nil {
+		report.Status.Progress.RowsWritten = report.Status.RowCount
+	}
 	_, err = op.meteringClient.MeteringV1alpha1().Reports(report.Namespace).Update(report)
 	if err != nil {
 		logger.WithError(err).Warnf("failed to update report status to finished for %q", report.Name)
@@ -251,16 +413,133 @@ func (op *Reporting) handleReport(logger log.FieldLogger, report *cbTypes.Report
 	return nil
 }
 
-func (op *Reporting) setReportError(logger log.FieldLogger, report *cbTypes.Report, err error, errMsg string, errMsgArgs ...interface{}) {
-	logger.WithField("Report", report.Name).WithError(err).Errorf(errMsg, errMsgArgs...)
+// dryRunReport records the rendered query of the report and the plan for
+// executing it in the report's status, and finishes the report without
+// creating it's table.
+func (op *Reporting) dryRunReport(logger log.FieldLogger, report *cbTypes.Report, reportingStart, reportingEnd *time.Time, genQuery *cbTypes.ReportGenerationQuery, queryDependencies *reporting.ReportGenerationQueryDependencies, limits reporting.ExecutionLimits, pricing *cbTypes.PricingPolicySpec) error {
+	key, err := cache.MetaNamespaceKeyFunc(report)
+	if err != nil {
+		return err
+	}
+	queryCtx, cancel := op.newReportQueryContext(logger, "Report", key)
+	defer cancel()
+	// EXPLAIN ANALYZE executes the query, so it's subject to maxRuntime
+	queryCtx = reporting.WithExecutionLimits(queryCtx, limits)
+	queryCtx = reporting.WithQueryPriority(queryCtx, reportQueryPriority(reporting.ReportQueryClass, reportingStart, reportingEnd))
+	queryCtx = reporting.WithPricing(queryCtx, pricing)
+
+	logger.Infof("explaining report query, analyze: %t", report.Spec.DryRun.Analyze)
+	explanation, err := op.reportGenerator.ExplainReport(
+		queryCtx,
+		reportingStart,
+		reportingEnd,
+		genQuery,
+		queryDependencies.DynamicReportGenerationQueries,
+		report.Spec.Inputs,
+		report.Spec.DryRun.Analyze,
+	)
+	if err != nil {
+		reason := classifyError(err, cbutil.PrestoErrorReason)
+		if queryCtx.Err() == context.DeadlineExceeded {
+			reason = cbutil.TimeoutReason
+		}
+		op.setReportError(logger, report, reason, err, "report dry run failed")
+		return nil
+	}
+
+	report.Status.Phase = cbTypes.ReportPhaseFinished
+	report.Status.DryRun = &cbTypes.ReportDryRunStatus{
+		Query: explanation.Query,
+		Plan:  explanation.Plan,
+	}
+	_, err = op.meteringClient.MeteringV1alpha1().Reports(report.Namespace).Update(report)
+	if err != nil {
+		return fmt.Errorf("failed to update report %s status.dryRun: %v", report.Name, err)
+	}
+	logger.Infof("finished dry run of report %q", report.Name)
+	return nil
+}
+
+// countReportRows returns the number of rows in a report's table, or nil if
+// they can't be counted. The count is only informational, so failing to
+// count the rows doesn't fail the report.
+func (op *Reporting) countReportRows(ctx context.Context, logger log.FieldLogger, tableName string) *int64 {
+	count, err := op.reportResultsRepo.CountReportResults(ctx, tableName)
+	if err != nil {
+		logger.WithError(err).Warnf("unable to count the rows in table %s", tableName)
+		return nil
+	}
+	return &count
+}
+
+func (op *Reporting) setReportError(logger log.FieldLogger, report *cbTypes.Report, reason string, err error, errMsg string, errMsgArgs ...interface{}) {
+	logger.WithError(err).Errorf(errMsg, errMsgArgs...)
 	report.Status.Phase = cbTypes.ReportPhaseError
 	report.Status.Output = err.Error()
+	report.Status.Reason = reason
 	_, err = op.meteringClient.MeteringV1alpha1().Reports(report.Namespace).Update(report)
 	if err != nil {
 		logger.WithError(err).Errorf("unable to update report status to error")
 	}
 }
 
+// dropReportTable drops the table containing the results of the Report. If
+// PurgeDeletedReportData is enabled, the table is converted to a managed table
+// first so that Hive also removes the underlying data files.
+func (op *Reporting) dropReportTable(logger log.FieldLogger, report *cbTypes.Report) error {
+	tableName := report.Status.TableName
+	if tableName == "" {
+		logger.Debugf("Report has no table, skipping dropping table")
+		return nil
+	}
+	logger = logger.WithField("tableName", tableName)
+	if op.cfg.PurgeDeletedReportData {
+		logger.Infof("marking table %s as managed to purge its data when dropped", tableName)
+		err := op.tableManager.SetTableExternal(op.workCtx, tableName, false)
+		if err != nil {
+			return reasonErrorf(err, cbutil.HiveErrorReason, "unable to mark table %s as managed for Report %s: %v", tableName, report.Name, err)
+		}
+	}
+	logger.Infof("dropping table %s", tableName)
+	err := op.tableManager.DropTable(op.workCtx, tableName, true)
+	if err != nil {
+		return reasonErrorf(err, cbutil.HiveErrorReason, "unable to drop table %s for Report %s: %v", tableName, report.Name, err)
+	}
+	logger.Infof("successfully dropped table %s", tableName)
+	return nil
+}
+
+func (op *Reporting) addReportFinalizer(report *cbTypes.Report) (*cbTypes.Report, error) {
+	report.Finalizers = append(report.Finalizers, reportFinalizer)
+	newReport, err := op.meteringClient.MeteringV1alpha1().Reports(report.Namespace).Update(report)
+	logger := newObjectLogger(op.logger, "Report", report)
+	if err != nil {
+		logger.WithError(err).Errorf("error adding %s finalizer to Report: %s/%s", reportFinalizer, report.Namespace, report.Name)
+		return nil, err
+	}
+	logger.Infof("added %s finalizer to Report: %s/%s", reportFinalizer, report.Namespace, report.Name)
+	return newReport, nil
+}
+
+func (op *Reporting) removeReportFinalizer(report *cbTypes.Report) (*cbTypes.Report, error) {
+	if !slice.ContainsString(report.ObjectMeta.Finalizers, reportFinalizer, nil) {
+		return report, nil
+	}
+	report.Finalizers = slice.RemoveString(report.Finalizers, reportFinalizer, nil)
+	newReport, err := op.meteringClient.MeteringV1alpha1().Reports(report.Namespace).Update(report)
+	logger := newObjectLogger(op.logger, "Report", report)
+	if err != nil {
+		logger.WithError(err).Errorf("error removing %s finalizer from Report: %s/%s", reportFinalizer, report.Namespace, report.Name)
+		return nil, err
+	}
+	logger.Infof("removed %s finalizer from Report: %s/%s", reportFinalizer, report.Namespace, report.Name)
+	return newReport, nil
+}
+
+func reportNeedsFinalizer(report *cbTypes.Report) bool {
+	return report.ObjectMeta.DeletionTimestamp == nil && !slice.ContainsString(report.ObjectMeta.Finalizers, reportFinalizer, nil)
+}
+
 // queueDependentReportGenerationQueriesForReport will queue all ReportGenerationQueries in the namespace which have a dependency on the Report
 func (op *Reporting) queueDependentReportGenerationQueriesForReport(report *cbTypes.Report) error {
 	queryLister := op.meteringClient.MeteringV1alpha1().ReportGenerationQueries(report.Namespace)
@@ -271,7 +550,7 @@ func (op *Reporting) queueDependentReportGenerationQueriesForReport(report *cbTy
 
 	for _, query := range queries.Items {
 		// look at the list Report of dependencies
-		for _, dependency := range query.Spec.Reports {
+		for _, dependency := range reporting.DependentReportNames(query) {
 			if dependency == report.Name {
 				// this query depends on the Report passed in
 				op.enqueueReportGenerationQuery(query)
@@ -0,0 +1,99 @@
+package operator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/fake"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+)
+
+// fakeTableManager records the tables created, repaired, dropped and marked
+// as managed. Calling any other method panics.
+type fakeTableManager struct {
+	reporting.TableManager
+	createdTables          []hive.TableParameters
+	createdTableProperties []hive.TableProperties
+	repairedTables         []string
+	repairErr              error
+	droppedTables          []string
+	managedTables          []string
+}
+
+func (m *fakeTableManager) CreateTable(ctx context.Context, params hive.TableParameters, properties hive.TableProperties) error {
+	m.createdTables = append(m.createdTables, params)
+	m.createdTableProperties = append(m.createdTableProperties, properties)
+	return nil
+}
+
+func (m *fakeTableManager) RepairTable(ctx context.Context, tableName string) error {
+	m.repairedTables = append(m.repairedTables, tableName)
+	return m.repairErr
+}
+
+func (m *fakeTableManager) SetTableExternal(ctx context.Context, tableName string, external bool) error {
+	if !external {
+		m.managedTables = append(m.managedTables, tableName)
+	}
+	return nil
+}
+
+func (m *fakeTableManager) DropTable(ctx context.Context, tableName string, ignoreNotExists bool) error {
+	m.droppedTables = append(m.droppedTables, tableName)
+	return nil
+}
+
+func TestSyncDeletedReport(t *testing.T) {
+	deleted := metav1.NewTime(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC))
+	for _, tt := range []struct {
+		name       string
+		finalizers []string
+		dropped    []string
+	}{
+		{
+			name:       "with the finalizer, the table is dropped",
+			finalizers: []string{"example.com/other", reportFinalizer},
+			dropped:    []string{"report_default_cpu"},
+		},
+		{
+			name:       "without the finalizer, the table is kept",
+			finalizers: []string{"example.com/other"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &cbTypes.Report{
+				ObjectMeta: metav1.ObjectMeta{Name: "cpu", Namespace: "default", DeletionTimestamp: &deleted, Finalizers: tt.finalizers},
+				Status:     cbTypes.ReportStatus{TableName: "report_default_cpu"},
+			}
+			client := fake.NewSimpleClientset(report.DeepCopy())
+			indexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+			require.NoError(t, indexer.Add(report))
+			tableManager := &fakeTableManager{}
+			op := &Reporting{
+				logger:         logrus.New(),
+				clock:          clock.NewFakeClock(deleted.Time),
+				workCtx:        context.Background(),
+				meteringClient: client,
+				reportLister:   listers.NewReportLister(indexer),
+				tableManager:   tableManager,
+			}
+
+			require.NoError(t, op.syncReport(op.logger, "default/cpu"))
+			assert.Equal(t, tt.dropped, tableManager.droppedTables)
+			updated, err := client.MeteringV1alpha1().Reports("default").Get("cpu", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"example.com/other"}, updated.Finalizers, "expected only reporting-operator's finalizer to be removed")
+		})
+	}
+}
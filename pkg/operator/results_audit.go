@@ -0,0 +1,189 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// auditLogStdout is the AuditLogPath which writes the audit log to
+	// stdout.
+	auditLogStdout = "-"
+
+	resultsAuditMessage = "report results accessed"
+)
+
+// resultsAuditor records who accessed which results, and when, in the audit
+// log. Each access is written as a JSON object, separately from the
+// operator's logs, so it can be retained for longer.
+type resultsAuditor struct {
+	logger log.FieldLogger
+	// authorizer identifies callers by their bearer token, and is nil when
+	// results access isn't authorized.
+	authorizer *resultsAuthorizer
+	// proxies are the proxies whose X-Forwarded-User and X-Forwarded-For
+	// headers are recorded.
+	proxies trustedProxies
+}
+
+func newResultsAuditor(out io.Writer, authorizer *resultsAuthorizer, proxies trustedProxies) *resultsAuditor {
+	logger := log.New()
+	logger.Out = out
+	logger.Formatter = &log.JSONFormatter{}
+	logger.Level = log.InfoLevel
+	return &resultsAuditor{logger: logger, authorizer: authorizer, proxies: proxies}
+}
+
+// openAuditLog opens the file at path for appending audit events to,
+// creating it if it doesn't exist.
+func openAuditLog(path string) (io.Writer, error) {
+	if path == auditLogStdout {
+		return os.Stdout, nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open audit log %s: %v", path, err)
+	}
+	return f, nil
+}
+
+// resultsAuditEvent is an access to the results of a resource, recorded once
+// the results have been returned, or the request failed.
+type resultsAuditEvent struct {
+	auditor *resultsAuditor
+	fields  log.Fields
+	rows    int
+	// response records the status of HTTP responses.
+	response *statusRecorder
+}
+
+// user returns the name of the user identified by token, or an empty string
+// if the token can't be used to identify them.
+func (a *resultsAuditor) user(token string) string {
+	if a.authorizer != nil && token != "" {
+		if user, err := a.authorizer.authenticate(token); err == nil {
+			return user.Username
+		}
+	}
+	return ""
+}
+
+func (a *resultsAuditor) newEvent(api, user, remoteAddr, kind, namespace, name, format string) *resultsAuditEvent {
+	return &resultsAuditEvent{
+		auditor: a,
+		fields: log.Fields{
+			"api":        api,
+			"user":       user,
+			"remoteAddr": remoteAddr,
+			"kind":       kind,
+			"namespace":  namespace,
+			"name":       name,
+			"format":     format,
+		},
+	}
+}
+
+// httpEvent starts recording an access to results over the HTTP API, whose
+// status is recorded by response. It returns nil when auditing is disabled.
+func (a *resultsAuditor) httpEvent(response *statusRecorder, r *http.Request, kind, namespace, name, format string) *resultsAuditEvent {
+	if a == nil {
+		return nil
+	}
+	event := a.newEvent("http", a.user(requestToken(r)), r.RemoteAddr, kind, namespace, name, format)
+	// the user and client a trusted proxy forwarded the request for are
+	// recorded separately, since they're asserted by the proxy rather than
+	// verified
+	if user := a.proxies.forwardedUser(r); user != "" {
+		event.fields["assertedUser"] = user
+	}
+	if forwardedFor := a.proxies.forwardedFor(r); forwardedFor != "" {
+		event.fields["assertedForwardedFor"] = forwardedFor
+	}
+	event.response = response
+	return event
+}
+
+// grpcEvent starts recording an access to results over the gRPC API. It
+// returns nil when auditing is disabled.
+func (a *resultsAuditor) grpcEvent(ctx context.Context, kind, namespace, name string) *resultsAuditEvent {
+	if a == nil {
+		return nil
+	}
+	var remoteAddr string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remoteAddr = p.Addr.String()
+	}
+	return a.newEvent("grpc", a.user(grpcRequestToken(ctx)), remoteAddr, kind, namespace, name, "grpc")
+}
+
+// setRows sets the number of rows of results returned.
+func (e *resultsAuditEvent) setRows(rows int) {
+	if e != nil {
+		e.rows = rows
+	}
+}
+
+// finish records the access with the status of the HTTP response.
+func (e *resultsAuditEvent) finish() {
+	if e != nil {
+		e.record(e.response.code())
+	}
+}
+
+// finishGRPC records the access with the gRPC status of err.
+func (e *resultsAuditEvent) finishGRPC(err error) {
+	if e != nil {
+		e.record(status.Code(err).String())
+	}
+}
+
+func (e *resultsAuditEvent) record(status interface{}) {
+	e.fields["status"] = status
+	e.fields["rows"] = e.rows
+	e.auditor.logger.WithFields(e.fields).Info(resultsAuditMessage)
+}
+
+// startHTTP starts recording an access to the results of a resource over
+// the HTTP API, returning the writer the response must be written to. The
+// access is recorded once the event is finished.
+func (a *resultsAuditor) startHTTP(w http.ResponseWriter, r *http.Request, kind, namespace, name, format string) (http.ResponseWriter, *resultsAuditEvent) {
+	if a == nil {
+		return w, nil
+	}
+	response := &statusRecorder{ResponseWriter: w}
+	return response, a.httpEvent(response, r, kind, namespace, name, format)
+}
+
+// statusRecorder records the status code of the response written to it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusRecorder) code() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
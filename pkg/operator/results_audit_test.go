@@ -0,0 +1,127 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+)
+
+// decodeAuditLog returns the entries written to the audit log.
+func decodeAuditLog(t *testing.T, auditLog *bytes.Buffer) []map[string]interface{} {
+	var entries []map[string]interface{}
+	decoder := json.NewDecoder(auditLog)
+	for decoder.More() {
+		var entry map[string]interface{}
+		require.NoError(t, decoder.Decode(&entry))
+		// the time the entry was written isn't deterministic
+		assert.NotEmpty(t, entry["time"])
+		delete(entry, "time")
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestResultsAuditHTTP(t *testing.T) {
+	proxies, err := parseTrustedProxies([]string{"127.0.0.1"})
+	require.NoError(t, err)
+	auditLog := &bytes.Buffer{}
+	srv := &server{
+		logger:       testLogger,
+		rand:         testRand,
+		auditor:      newResultsAuditor(auditLog, nil, proxies),
+		reportLister: listers.NewReportLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+	}
+
+	for _, remoteAddr := range []string{"127.0.0.1:43210", "10.0.0.2:43210"} {
+		r := httptest.NewRequest("GET", "/api/v2/reports/cpu/full?format=csv&namespace=team-a", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("X-Forwarded-User", "alice")
+		r.Header.Set("X-Forwarded-For", "10.0.0.1")
+		w := httptest.NewRecorder()
+		srv.getReport(testLogger, "team-a", "cpu", "csv", true, true, w, r)
+		require.Equal(t, http.StatusNotFound, w.Code)
+	}
+
+	// requests which fail are recorded too, and the forwarded headers are
+	// only recorded, as asserted by the proxy, for requests made by a
+	// trusted proxy
+	assert.Equal(t, []map[string]interface{}{
+		{
+			"level":                "info",
+			"msg":                  resultsAuditMessage,
+			"api":                  "http",
+			"user":                 "",
+			"remoteAddr":           "127.0.0.1:43210",
+			"assertedUser":         "alice",
+			"assertedForwardedFor": "10.0.0.1",
+			"kind":                 "Report",
+			"namespace":            "team-a",
+			"name":                 "cpu",
+			"format":               "csv",
+			"status":               float64(http.StatusNotFound),
+			"rows":                 float64(0),
+		},
+		{
+			"level":      "info",
+			"msg":        resultsAuditMessage,
+			"api":        "http",
+			"user":       "",
+			"remoteAddr": "10.0.0.2:43210",
+			"kind":       "Report",
+			"namespace":  "team-a",
+			"name":       "cpu",
+			"format":     "csv",
+			"status":     float64(http.StatusNotFound),
+			"rows":       float64(0),
+		},
+	}, decodeAuditLog(t, auditLog))
+}
+
+func TestResultsAuditGRPC(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC))
+	authorizer, _, _ := newTestResultsAuthorizer(fakeClock)
+	auditLog := &bytes.Buffer{}
+	auditor := newResultsAuditor(auditLog, authorizer, nil)
+
+	// callers are identified by their bearer token when results access is
+	// authorized
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer bob-token"))
+	audit := auditor.grpcEvent(ctx, "ScheduledReport", "team-b", "daily")
+	audit.setRows(24)
+	audit.finishGRPC(nil)
+	audit = auditor.grpcEvent(context.Background(), "Report", "team-b", "cpu")
+	audit.finishGRPC(status.Error(codes.Unauthenticated, errNoBearerToken.Error()))
+
+	entries := decodeAuditLog(t, auditLog)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "bob", entries[0]["user"])
+	assert.Equal(t, "OK", entries[0]["status"])
+	assert.Equal(t, float64(24), entries[0]["rows"])
+	assert.Equal(t, "grpc", entries[0]["api"])
+	assert.Equal(t, "", entries[1]["user"])
+	assert.Equal(t, "Unauthenticated", entries[1]["status"])
+}
+
+func TestResultsAuditDisabled(t *testing.T) {
+	var auditor *resultsAuditor
+	w := httptest.NewRecorder()
+	writer, audit := auditor.startHTTP(w, httptest.NewRequest("GET", "/api/v1/reports/get", nil), "Report", "team-a", "cpu", "json")
+	assert.Equal(t, w, writer, "expected the response to be written directly when auditing is disabled")
+	audit.setRows(1)
+	audit.finish()
+	auditor.grpcEvent(context.Background(), "Report", "team-a", "cpu").finishGRPC(nil)
+}
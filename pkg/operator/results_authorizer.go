@@ -0,0 +1,287 @@
+package operator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+	log "github.com/sirupsen/logrus"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	authenticationv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const (
+	// resultsAuthorizationCacheDuration is how long the user identified by
+	// a token, and whether they can read results in a namespace, are
+	// cached for, so each request doesn't create TokenReviews and
+	// SubjectAccessReviews.
+	resultsAuthorizationCacheDuration = time.Minute
+	// resultsAuthorizationCacheMaxEntries limits the number of users and
+	// decisions cached, after which the least recently used are evicted.
+	resultsAuthorizationCacheMaxEntries = 10000
+
+	// forwardedAccessTokenHeader contains the caller's access token when
+	// the auth proxy is run with -pass-access-token.
+	forwardedAccessTokenHeader = "X-Forwarded-Access-Token"
+
+	// The resources callers need access to.
+	resultsResourceReports                 = "reports"
+	resultsResourceScheduledReports        = "scheduledreports"
+	resultsResourceReportDataSources       = "reportdatasources"
+	resultsResourceReportGenerationQueries = "reportgenerationqueries"
+)
+
+var (
+	errNoBearerToken      = errors.New("a bearer token is required to read report results")
+	errInvalidBearerToken = errors.New("the bearer token is invalid or has expired")
+)
+
+// resultsAuthorizer restricts the results returned by the API to the
+// namespaces the caller is allowed to get Reports, ScheduledReports and
+// ReportDataSources in, and the data changed by the API to the namespaces
+// the caller is allowed to change them in. Callers are identified by the
+// bearer token of their request, which is authenticated using a
+// TokenReview, and their access is checked using a SubjectAccessReview.
+type resultsAuthorizer struct {
+	tokenReviews  authenticationv1client.TokenReviewInterface
+	accessReviews authorizationv1client.SubjectAccessReviewInterface
+	clock         clock.Clock
+
+	mu sync.Mutex
+	// users maps the hash of each token to it's cachedResultsUser, whose
+	// user is nil if the token is invalid.
+	users *simplelru.LRU
+	// decisions maps each resultsAccess to it's cachedResultsDecision.
+	decisions *simplelru.LRU
+}
+
+type cachedResultsUser struct {
+	user    *authenticationv1.UserInfo
+	expires time.Time
+}
+
+type resultsAccess struct {
+	tokenHash string
+	verb      string
+	resource  string
+	namespace string
+}
+
+type cachedResultsDecision struct {
+	allowed bool
+	expires time.Time
+}
+
+func newResultsAuthorizer(tokenReviews authenticationv1client.TokenReviewInterface, accessReviews authorizationv1client.SubjectAccessReviewInterface, clock clock.Clock) *resultsAuthorizer {
+	// the size is constant and positive, so NewLRU can't fail
+	users, _ := simplelru.NewLRU(resultsAuthorizationCacheMaxEntries, nil)
+	decisions, _ := simplelru.NewLRU(resultsAuthorizationCacheMaxEntries, nil)
+	return &resultsAuthorizer{
+		tokenReviews:  tokenReviews,
+		accessReviews: accessReviews,
+		clock:         clock,
+		users:         users,
+		decisions:     decisions,
+	}
+}
+
+// requestToken returns the bearer token identifying the caller of r.
+func requestToken(r *http.Request) string {
+	if token := r.Header.Get(forwardedAccessTokenHeader); token != "" {
+		return token
+	}
+	return bearerToken(r.Header.Get("Authorization"))
+}
+
+// bearerToken returns the token in the value of an Authorization header.
+func bearerToken(authorization string) string {
+	parts := strings.SplitN(authorization, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// authenticate returns the user identified by token.
+func (a *resultsAuthorizer) authenticate(token string) (*authenticationv1.UserInfo, error) {
+	if token == "" {
+		return nil, errNoBearerToken
+	}
+	tokenHash := hashToken(token)
+	now := a.clock.Now()
+	cached, ok := a.cachedUser(tokenHash)
+	if !ok || !now.Before(cached.expires) {
+		review, err := a.tokenReviews.Create(&authenticationv1.TokenReview{
+			Spec: authenticationv1.TokenReviewSpec{Token: token},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to authenticate bearer token: %v", err)
+		}
+		cached = cachedResultsUser{expires: now.Add(resultsAuthorizationCacheDuration)}
+		if review.Status.Authenticated {
+			cached.user = &review.Status.User
+		}
+		a.mu.Lock()
+		a.users.Add(tokenHash, cached)
+		a.mu.Unlock()
+	}
+	if cached.user == nil {
+		return nil, errInvalidBearerToken
+	}
+	return cached.user, nil
+}
+
+func (a *resultsAuthorizer) cachedUser(tokenHash string) (cachedResultsUser, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	cached, ok := a.users.Get(tokenHash)
+	if !ok {
+		return cachedResultsUser{}, false
+	}
+	return cached.(cachedResultsUser), true
+}
+
+// authenticated returns the user identified by token if it has already been
+// authenticated, or nil otherwise. Unlike authenticate, it never creates a
+// TokenReview, so it can be used before the caller is rate limited.
+func (a *resultsAuthorizer) authenticated(token string) *authenticationv1.UserInfo {
+	if a == nil || token == "" {
+		return nil
+	}
+	cached, ok := a.cachedUser(hashToken(token))
+	if !ok || !a.clock.Now().Before(cached.expires) {
+		return nil
+	}
+	return cached.user
+}
+
+// allowed returns true if the user identified by token can perform verb on
+// resource in namespace, or in every namespace if namespace is empty.
+func (a *resultsAuthorizer) allowed(token, verb, resource, namespace string) (bool, error) {
+	user, err := a.authenticate(token)
+	if err != nil {
+		return false, err
+	}
+	access := resultsAccess{tokenHash: hashToken(token), verb: verb, resource: resource, namespace: namespace}
+	now := a.clock.Now()
+	a.mu.Lock()
+	cached, ok := a.decisions.Get(access)
+	a.mu.Unlock()
+	if ok && now.Before(cached.(cachedResultsDecision).expires) {
+		return cached.(cachedResultsDecision).allowed, nil
+	}
+
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	review, err := a.accessReviews.Create(&authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      verb,
+				Group:     cbTypes.GroupName,
+				Resource:  resource,
+			},
+			User:   user.Username,
+			Groups: user.Groups,
+			UID:    user.UID,
+			Extra:  extra,
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to check access to %s %s in %s: %v", verb, resource, describeNamespace(namespace), err)
+	}
+	a.mu.Lock()
+	a.decisions.Add(access, cachedResultsDecision{allowed: review.Status.Allowed, expires: now.Add(resultsAuthorizationCacheDuration)})
+	a.mu.Unlock()
+	return review.Status.Allowed, nil
+}
+
+// authorizeRequest returns true if the caller of r can perform verb on
+// resource in namespace, or in every namespace if namespace is empty,
+// otherwise it writes an error response. Every caller is authorized when the
+// API isn't restricted to the namespaces callers have access to.
+func (a *resultsAuthorizer) authorizeRequest(logger log.FieldLogger, w http.ResponseWriter, r *http.Request, verb, resource, namespace string) bool {
+	if a == nil {
+		return true
+	}
+	allowed, err := a.allowed(requestToken(r), verb, resource, namespace)
+	if err != nil {
+		writeAuthorizationError(logger, w, r, err)
+		return false
+	}
+	if !allowed {
+		writeErrorResponse(logger, w, r, http.StatusForbidden, "not allowed to %s %s in %s", verb, resource, describeNamespace(namespace))
+		return false
+	}
+	return true
+}
+
+// requestFilter returns a function reporting whether the caller of r can get
+// resource in a namespace, used to filter listings spanning namespaces. If
+// the caller can't be authenticated, it writes an error response and returns
+// false.
+func (a *resultsAuthorizer) requestFilter(logger log.FieldLogger, w http.ResponseWriter, r *http.Request) (func(resource, namespace string) bool, bool) {
+	if a == nil {
+		return func(resource, namespace string) bool { return true }, true
+	}
+	token := requestToken(r)
+	if _, err := a.authenticate(token); err != nil {
+		writeAuthorizationError(logger, w, r, err)
+		return nil, false
+	}
+	return func(resource, namespace string) bool {
+		allowed, err := a.allowed(token, "get", resource, namespace)
+		if err != nil {
+			logger.WithError(err).Warnf("excluding %s in namespace %s", resource, namespace)
+			return false
+		}
+		return allowed
+	}, true
+}
+
+// authorizeResults returns true if the caller of r can read the results of
+// resource in namespace, otherwise it writes an error response.
+func (srv *server) authorizeResults(logger log.FieldLogger, w http.ResponseWriter, r *http.Request, resource, namespace string) bool {
+	return srv.authorizer.authorizeRequest(logger, w, r, "get", resource, namespace)
+}
+
+// resultsFilter returns a function reporting whether the caller of r can
+// read the results of resource in a namespace, used to filter listings
+// spanning namespaces. If the caller can't be authenticated, it writes an
+// error response and returns false.
+func (srv *server) resultsFilter(logger log.FieldLogger, w http.ResponseWriter, r *http.Request) (func(resource, namespace string) bool, bool) {
+	return srv.authorizer.requestFilter(logger, w, r)
+}
+
+func describeNamespace(namespace string) string {
+	if namespace == "" {
+		return "all namespaces"
+	}
+	return "namespace " + namespace
+}
+
+func writeAuthorizationError(logger log.FieldLogger, w http.ResponseWriter, r *http.Request, err error) {
+	if err == errNoBearerToken || err == errInvalidBearerToken {
+		writeErrorResponse(logger, w, r, http.StatusUnauthorized, "%v", err)
+		return
+	}
+	writeErrorResponse(logger, w, r, http.StatusInternalServerError, "%v", err)
+}
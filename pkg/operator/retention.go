@@ -0,0 +1,211 @@
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const (
+	DefaultRetentionCheckInterval = time.Hour
+
+	// maxPrunedPartitionsPerCheck limits how many partitions of each table
+	// are pruned per check, so enabling retention for a table with a lot of
+	// existing data doesn't occupy Hive for hours.
+	maxPrunedPartitionsPerCheck = 168
+)
+
+var prunedPartitionsCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: prometheusMetricNamespace,
+		Name:      "reportdatasource_pruned_partitions_total",
+		Help:      "Number of partitions of a ReportDataSource's table which have been dropped because they're older than it's retention.",
+	},
+	[]string{"reportdatasource", "table_name"},
+)
+
+func init() {
+	prometheus.MustRegister(prunedPartitionsCounter)
+}
+
+// runRetentionCheck periodically prunes the partitions of ReportDataSources
+// older than their retention until stopCh is closed.
+func (op *Reporting) runRetentionCheck(stopCh <-chan struct{}) {
+	wait.Until(op.pruneExpiredPartitions, op.cfg.RetentionCheckInterval, stopCh)
+}
+
+func (op *Reporting) pruneExpiredPartitions() {
+	logger := op.logger.WithField("component", "retention")
+	dataSources, err := op.reportDataSourceLister.List(labels.Everything())
+	if err != nil {
+		logger.WithError(err).Errorf("unable to list ReportDataSources")
+		return
+	}
+	for _, dataSource := range dataSources {
+		if dataSource.Spec.Promsum == nil || dataSource.Spec.Retention == nil || dataSource.Status.TableName == "" || !op.cfg.isWatchedNamespace(dataSource.Namespace) {
+			continue
+		}
+		err := op.pruneDataSourcePartitions(newObjectLogger(logger, "ReportDataSource", dataSource), dataSource)
+		if err != nil {
+			logger.WithError(err).Errorf("unable to prune partitions of ReportDataSource %s", dataSource.Name)
+		}
+	}
+}
+
+// pruneDataSourcePartitions drops each partition of the ReportDataSource's
+// table older than it's retention. Partitions of external tables keep their
+// files when dropped, so each partition is emptied using INSERT OVERWRITE
+// first.
+func (op *Reporting) pruneDataSourcePartitions(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	tableName := dataSource.Status.TableName
+	granularity := dataSourcePartitionGranularity(dataSource)
+
+	cutoff := op.clock.Now().Add(-dataSource.Spec.Retention.Duration)
+	requiredFrom, requiredBy, err := op.earliestRequiredReportTime(dataSource.Namespace)
+	if err != nil {
+		return err
+	}
+	if requiredFrom != nil && requiredFrom.Before(cutoff) {
+		logger.Infof("not pruning data after %s, which is needed by %s", requiredFrom, requiredBy)
+		cutoff = *requiredFrom
+	}
+
+	partitionStarts := partitionsToPrune(dataSource, cutoff)
+	if len(partitionStarts) == 0 {
+		return nil
+	}
+
+	var prunedUntil time.Time
+	var pruneErr error
+	for _, start := range partitionStarts {
+		dt := granularity.Partition(start)
+		logger.Debugf("pruning partition dt=%s of table %s", dt, tableName)
+		emptyQuery := fmt.Sprintf("SELECT amount, `timestamp`, timeprecision, labels, cluster_id FROM %s WHERE 1 = 0", tableName)
+		if err := op.tableManager.InsertOverwritePartition(op.workCtx, tableName, "dt", dt, emptyQuery); err != nil {
+			pruneErr = fmt.Errorf("unable to delete data of partition dt=%s of table %s: %v", dt, tableName, err)
+			break
+		}
+		if err := op.tableManager.DropTablePartition(op.workCtx, tableName, "dt", dt); err != nil {
+			pruneErr = fmt.Errorf("unable to drop partition dt=%s of table %s: %v", dt, tableName, err)
+			break
+		}
+		prunedPartitionsCounter.WithLabelValues(dataSource.Name, tableName).Inc()
+		prunedUntil = granularity.NextPartitionStart(start)
+	}
+	if prunedUntil.IsZero() {
+		return pruneErr
+	}
+	logger.Infof("pruned partitions of table %s until %s", tableName, prunedUntil)
+
+	// record the progress made, even if a partition failed to be pruned
+	dataSource = dataSource.DeepCopy()
+	now := metav1.NewTime(op.clock.Now())
+	until := metav1.NewTime(prunedUntil)
+	dataSource.Status.RetentionStatus = &cbTypes.RetentionStatus{
+		PrunedUntil:   &until,
+		LastPruneTime: &now,
+	}
+	if _, err := op.meteringClient.MeteringV1alpha1().ReportDataSources(dataSource.Namespace).Update(dataSource); err != nil {
+		return fmt.Errorf("unable to update ReportDataSource %s retention status: %v", dataSource.Name, err)
+	}
+	return pruneErr
+}
+
+// partitionsToPrune returns the start of each partition of the
+// ReportDataSource's table which hasn't been pruned yet, and ends before
+// cutoff, oldest first.
+func partitionsToPrune(dataSource *cbTypes.ReportDataSource, cutoff time.Time) []time.Time {
+	granularity := dataSourcePartitionGranularity(dataSource)
+	start := dataSource.CreationTimestamp.Time
+	status := dataSource.Status
+	switch {
+	case status.RetentionStatus != nil && status.RetentionStatus.PrunedUntil != nil:
+		start = status.RetentionStatus.PrunedUntil.Time
+	case status.PrometheusMetricImportStatus != nil && status.PrometheusMetricImportStatus.EarliestImportedMetricTime != nil:
+		start = status.PrometheusMetricImportStatus.EarliestImportedMetricTime.Time
+	}
+
+	var partitionStarts []time.Time
+	for t := granularity.PartitionStart(start); !granularity.NextPartitionStart(t).After(cutoff); t = granularity.NextPartitionStart(t) {
+		if len(partitionStarts) == maxPrunedPartitionsPerCheck {
+			break
+		}
+		partitionStarts = append(partitionStarts, t)
+	}
+	return partitionStarts
+}
+
+// earliestRequiredReportTime returns the start of the earliest reporting
+// period of a Report or ScheduledReport in the namespace which hasn't
+// finished, and a description of the report, or nil if no report needs any
+// data. ReportDataSources can only be used by reports in the same namespace,
+// so reports in other namespaces are ignored.
+func (op *Reporting) earliestRequiredReportTime(namespace string) (*time.Time, string, error) {
+	var earliest *time.Time
+	var requiredBy string
+	require := func(t time.Time, by string) {
+		if earliest == nil || t.Before(*earliest) {
+			earliest = &t
+			requiredBy = by
+		}
+	}
+
+	reports, err := op.reportLister.Reports(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to list Reports: %v", err)
+	}
+	for _, report := range reports {
+		if start, required := reportRequiredFrom(report); required {
+			require(start, fmt.Sprintf("Report %s", report.Name))
+		}
+	}
+
+	scheduledReports, err := op.scheduledReportLister.ScheduledReports(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to list ScheduledReports: %v", err)
+	}
+	for _, report := range scheduledReports {
+		if start, required := scheduledReportRequiredFrom(report); required {
+			require(start, fmt.Sprintf("ScheduledReport %s", report.Name))
+		}
+	}
+	return earliest, requiredBy, nil
+}
+
+// reportRequiredFrom returns the time a Report which hasn't finished needs
+// data from. A Report without a reportingStart may need any data, so the zero
+// time is returned.
+func reportRequiredFrom(report *cbTypes.Report) (time.Time, bool) {
+	if report.Status.Phase == cbTypes.ReportPhaseFinished {
+		return time.Time{}, false
+	}
+	if report.Spec.ReportingStart == nil {
+		return time.Time{}, true
+	}
+	return report.Spec.ReportingStart.Time, true
+}
+
+// scheduledReportRequiredFrom returns the start of the next period of a
+// ScheduledReport which hasn't reached it's reportingEnd.
+func scheduledReportRequiredFrom(report *cbTypes.ScheduledReport) (time.Time, bool) {
+	switch {
+	case report.Status.LastReportTime != nil:
+		if report.Spec.ReportingEnd != nil && !report.Status.LastReportTime.Time.Before(report.Spec.ReportingEnd.Time) {
+			return time.Time{}, false
+		}
+		return report.Status.LastReportTime.Time, true
+	case report.Spec.ReportingStart != nil:
+		return report.Spec.ReportingStart.Time, true
+	default:
+		// the first period begins when the ScheduledReport is first
+		// processed
+		return report.CreationTimestamp.Time, true
+	}
+}
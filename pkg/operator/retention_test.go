@@ -0,0 +1,92 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestPartitionsToPrune(t *testing.T) {
+	created := metav1.NewTime(time.Date(2019, time.January, 5, 6, 0, 0, 0, time.UTC))
+	earliest := metav1.NewTime(time.Date(2019, time.January, 1, 12, 0, 0, 0, time.UTC))
+	prunedUntil := metav1.NewTime(time.Date(2019, time.January, 3, 0, 0, 0, 0, time.UTC))
+	cutoff := time.Date(2019, time.January, 4, 12, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time {
+		return time.Date(2019, time.January, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		status   cbTypes.ReportDataSourceStatus
+		expected []time.Time
+	}{
+		{
+			name:     "nothing older than when the ReportDataSource was created",
+			expected: nil,
+		},
+		{
+			name: "starts from the earliest imported metric",
+			status: cbTypes.ReportDataSourceStatus{
+				PrometheusMetricImportStatus: &cbTypes.PrometheusMetricImportStatus{EarliestImportedMetricTime: &earliest},
+			},
+			expected: []time.Time{day(1), day(2), day(3)},
+		},
+		{
+			name: "continues from the last partition pruned",
+			status: cbTypes.ReportDataSourceStatus{
+				PrometheusMetricImportStatus: &cbTypes.PrometheusMetricImportStatus{EarliestImportedMetricTime: &earliest},
+				RetentionStatus:              &cbTypes.RetentionStatus{PrunedUntil: &prunedUntil},
+			},
+			expected: []time.Time{day(3)},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dataSource := &cbTypes.ReportDataSource{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created},
+				Status:     tt.status,
+			}
+			assert.Equal(t, tt.expected, partitionsToPrune(dataSource, cutoff))
+		})
+	}
+}
+
+func TestReportRequiredFrom(t *testing.T) {
+	start := metav1.NewTime(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC))
+	end := metav1.NewTime(time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC))
+
+	_, required := reportRequiredFrom(&cbTypes.Report{
+		Spec:   cbTypes.ReportSpec{ReportingStart: &start},
+		Status: cbTypes.ReportStatus{Phase: cbTypes.ReportPhaseFinished},
+	})
+	assert.False(t, required, "expected finished Reports not to need data")
+
+	from, required := reportRequiredFrom(&cbTypes.Report{
+		Spec:   cbTypes.ReportSpec{ReportingStart: &start},
+		Status: cbTypes.ReportStatus{Phase: cbTypes.ReportPhaseWaiting},
+	})
+	assert.True(t, required)
+	assert.Equal(t, start.Time, from)
+
+	from, required = reportRequiredFrom(&cbTypes.Report{})
+	assert.True(t, required)
+	assert.True(t, from.IsZero(), "expected Reports without a reportingStart to need all data")
+
+	_, required = scheduledReportRequiredFrom(&cbTypes.ScheduledReport{
+		Spec:   cbTypes.ScheduledReportSpec{ReportingEnd: &end},
+		Status: cbTypes.ScheduledReportStatus{LastReportTime: &end},
+	})
+	assert.False(t, required, "expected ScheduledReports which reached their reportingEnd not to need data")
+
+	from, required = scheduledReportRequiredFrom(&cbTypes.ScheduledReport{
+		Spec:   cbTypes.ScheduledReportSpec{ReportingStart: &start, ReportingEnd: &end},
+		Status: cbTypes.ScheduledReportStatus{LastReportTime: &start},
+	})
+	assert.True(t, required)
+	assert.Equal(t, start.Time, from)
+}
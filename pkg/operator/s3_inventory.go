@@ -0,0 +1,114 @@
+package operator
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/aws"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
+)
+
+const (
+	// symlinkTextInputFormat reads the files listed in each of the symlink
+	// files in a table's location, which is how S3 Inventory exposes the
+	// data files of each run to Hive.
+	symlinkTextInputFormat    = "org.apache.hadoop.hive.ql.io.SymlinkTextInputFormat"
+	ignoreKeyTextOutputFormat = "org.apache.hadoop.hive.ql.io.HiveIgnoreKeyTextOutputFormat"
+)
+
+func (op *Reporting) handleS3InventoryDataSource(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	source := dataSource.Spec.S3Inventory.Source
+	if source == nil {
+		return reasonErrorf(nil, cbutil.ValidationErrorReason, "ReportDataSource %q: improperly configured datasource, source is empty", dataSource.Name)
+	}
+	if source.Bucket == "" {
+		return reasonErrorf(nil, cbutil.ValidationErrorReason, "ReportDataSource %q: improperly configured datasource, source.bucket is empty", dataSource.Name)
+	}
+
+	if dataSource.Status.TableName != "" {
+		logger.Infof("existing S3Inventory ReportDataSource discovered, tableName: %s", dataSource.Status.TableName)
+	} else {
+		logger.Infof("new S3Inventory ReportDataSource discovered")
+
+		// the columns of the table depend on the fields the inventory was
+		// configured with, which are only known once it's run.
+		manifestRetriever := aws.NewInventoryManifestRetriever(source.Region, source.Bucket, source.Prefix, s3Endpoint(source.Endpoint), source.RoleARN)
+		manifest, err := manifestRetriever.RetrieveNewestManifest()
+		if err != nil {
+			return err
+		}
+		if manifest == nil {
+			logger.Warnf("ReportDataSource %q has no inventory manifests in it's bucket, the first inventory has likely not been generated yet", dataSource.Name)
+			op.enqueueReportDataSourceAfter(dataSource, partitionUpdateInterval)
+			return nil
+		}
+		columns, err := manifest.Columns()
+		if err != nil {
+			return reasonErrorf(err, cbutil.ValidationErrorReason, "ReportDataSource %q: improperly configured datasource, %v", dataSource.Name, err)
+		}
+
+		tableName := op.dataSourceTableName(dataSource)
+		logger.Debugf("creating S3 Inventory DataSource table %s pointing to s3 bucket %s at prefix %s", tableName, source.Bucket, source.Prefix)
+		err = op.createS3InventoryTable(logger, dataSource, tableName, source, columns)
+		if err != nil {
+			return err
+		}
+
+		logger.Debugf("successfully created S3 Inventory DataSource table %s pointing to s3 bucket %s at prefix %s", tableName, source.Bucket, source.Prefix)
+		dataSource, err = op.updateDataSourceTableName(logger, dataSource, tableName)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("discovering new partitions for table %s", dataSource.Status.TableName)
+	err := op.tableManager.RepairTable(op.workCtx, dataSource.Status.TableName)
+	if err != nil {
+		return reasonErrorf(err, cbutil.HiveErrorReason, "unable to update partitions of table %s for ReportDataSource %s: %v", dataSource.Status.TableName, dataSource.Name, err)
+	}
+
+	nextUpdate := op.clock.Now().Add(partitionUpdateInterval).UTC()
+	logger.Infof("queuing S3Inventory ReportDataSource %s to update partitions again in %s at %s", dataSource.Name, partitionUpdateInterval, nextUpdate)
+	op.enqueueReportDataSourceAfter(dataSource, partitionUpdateInterval)
+	return nil
+}
+
+// createS3InventoryTable instantiates a new external Hive table for an S3
+// Inventory. The table reads the symlink files in the inventory's hive
+// directory, which contains a dt partition for each run of the inventory.
+func (op *Reporting) createS3InventoryTable(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource, tableName string, source *cbTypes.S3Bucket, columns []string) error {
+	location, err := hive.S3Location(source.Bucket, source.Prefix+"/"+aws.InventoryHiveDirectory)
+	if err != nil {
+		return err
+	}
+
+	hiveColumns := make([]hive.Column, len(columns))
+	for i, column := range columns {
+		// OpenCSVSerde reads every column as a string
+		hiveColumns[i] = hive.Column{
+			Name: reportingutil.SanetizeAzureColumnForHive(column),
+			Type: "string",
+		}
+	}
+	params := hive.TableParameters{
+		Name:         tableName,
+		Columns:      hiveColumns,
+		Partitions:   []hive.Column{{Name: "dt", Type: "string"}},
+		IgnoreExists: true,
+	}
+	properties := hive.TableProperties{
+		Location:    location,
+		SerdeFormat: reportingutil.CSVHiveSerde,
+		SerdeRowProperties: map[string]string{
+			"separatorChar": ",",
+			"quoteChar":     `"`,
+			"escapeChar":    "\\",
+		},
+		InputFormat:  symlinkTextInputFormat,
+		OutputFormat: ignoreKeyTextOutputFormat,
+		External:     true,
+	}
+	return op.createTableAndCR(op.workCtx, logger, dataSource, cbTypes.SchemeGroupVersion.WithKind("ReportDataSource"), params, properties)
+}
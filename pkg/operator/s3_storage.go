@@ -0,0 +1,93 @@
+package operator
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/aws"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+)
+
+// s3Endpoint converts the S3Endpoint of a resource to the aws package's
+// equivalent. A nil endpoint uses AWS S3.
+func s3Endpoint(endpoint *cbTypes.S3Endpoint) aws.S3Endpoint {
+	if endpoint == nil {
+		return aws.S3Endpoint{}
+	}
+	return aws.S3Endpoint{
+		URL:                endpoint.URL,
+		ForcePathStyle:     endpoint.ForcePathStyle,
+		InsecureSkipVerify: endpoint.InsecureSkipVerify,
+	}
+}
+
+// s3StorageLocation returns the s3a:// URL of the S3Storage's prefix.
+func s3StorageLocation(s3Storage *cbTypes.S3Storage) (string, error) {
+	if s3Storage.Bucket == "" {
+		return "", fmt.Errorf("spec.s3.bucket must be set")
+	}
+	return hive.S3Location(s3Storage.Bucket, s3Storage.Prefix)
+}
+
+// s3HiveStorage returns the HiveStorage equivalent to the S3Storage.
+func s3HiveStorage(s3Storage *cbTypes.S3Storage) (*cbTypes.HiveStorage, error) {
+	location, err := s3StorageLocation(s3Storage)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s3EncryptionAlgorithm(s3Storage.Encryption); err != nil {
+		return nil, fmt.Errorf("invalid spec.s3.encryption: %v", err)
+	}
+	return &cbTypes.HiveStorage{
+		TableProperties: cbTypes.TableProperties{Location: location},
+		FileFormat:      s3Storage.FileFormat,
+		Compression:     s3Storage.Compression,
+	}, nil
+}
+
+// checkS3StorageAccess returns an error if the S3Storage's bucket can't be
+// accessed, so an incorrect endpoint or credentials are reported clearly
+// instead of as a failure to create a table.
+func checkS3StorageAccess(s3Storage *cbTypes.S3Storage) error {
+	endpoint := s3Endpoint(s3Storage.Endpoint)
+	if err := aws.CheckBucket(s3Storage.Region, s3Storage.Bucket, endpoint, s3Storage.RoleARN); err != nil {
+		return err
+	}
+	return checkS3Encryption(s3Storage.Region, s3Storage.Bucket, endpoint, s3Storage.RoleARN, s3Storage.Encryption)
+}
+
+// s3EncryptionAlgorithm validates the S3Encryption, returning the S3
+// server-side encryption algorithm it corresponds to.
+func s3EncryptionAlgorithm(encryption *cbTypes.S3Encryption) (string, error) {
+	if encryption == nil {
+		return "", nil
+	}
+	switch encryption.Type {
+	case cbTypes.S3EncryptionTypeSSES3:
+		if encryption.KMSKeyID != "" {
+			return "", fmt.Errorf("kmsKeyID can only be set when type is %s", cbTypes.S3EncryptionTypeSSEKMS)
+		}
+		return s3.ServerSideEncryptionAes256, nil
+	case cbTypes.S3EncryptionTypeSSEKMS:
+		if encryption.KMSKeyID == "" {
+			return "", fmt.Errorf("kmsKeyID must be set when type is %s", cbTypes.S3EncryptionTypeSSEKMS)
+		}
+		return s3.ServerSideEncryptionAwsKms, nil
+	default:
+		return "", fmt.Errorf("type must be %s or %s, got %q", cbTypes.S3EncryptionTypeSSES3, cbTypes.S3EncryptionTypeSSEKMS, encryption.Type)
+	}
+}
+
+// checkS3Encryption returns an error if the bucket doesn't encrypt objects
+// using the S3Encryption by default. Hive and Presto write objects using
+// their own configuration, so requiring the bucket's default encryption to
+// match ensures data is encrypted even if they're misconfigured.
+func checkS3Encryption(region, bucket string, endpoint aws.S3Endpoint, roleARN string, encryption *cbTypes.S3Encryption) error {
+	algorithm, err := s3EncryptionAlgorithm(encryption)
+	if err != nil || algorithm == "" {
+		return err
+	}
+	return aws.CheckBucketEncryption(region, bucket, endpoint, roleARN, algorithm, encryption.KMSKeyID)
+}
@@ -0,0 +1,282 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+var scheduledReportMetricsCollector = newScheduledReportResultsCollector()
+
+func init() {
+	prometheus.MustRegister(scheduledReportMetricsCollector)
+}
+
+// scheduledReportGauge is a gauge exporting a column of a ScheduledReport's
+// results, with a sample for each distinct set of label values.
+type scheduledReportGauge struct {
+	fqName     string
+	help       string
+	labelNames []string
+	samples    []scheduledReportSample
+}
+
+type scheduledReportSample struct {
+	labelValues []string
+	value       float64
+}
+
+type scheduledReportResults struct {
+	labelValues []string
+	rows        int
+	gauges      []scheduledReportGauge
+}
+
+// scheduledReportResultsCollector exposes the results of the most recent
+// run of each ScheduledReport with spec.metrics set. Gauges are named after
+// their ScheduledReport, so the set of metrics exposed changes as
+// ScheduledReports are created and deleted.
+type scheduledReportResultsCollector struct {
+	rowsDesc *prometheus.Desc
+
+	mu      sync.Mutex
+	results map[string]scheduledReportResults
+}
+
+func newScheduledReportResultsCollector() *scheduledReportResultsCollector {
+	return &scheduledReportResultsCollector{
+		rowsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(prometheusMetricNamespace, "", "scheduledreport_exported_rows"),
+			"Number of rows of the most recent run of a ScheduledReport exported as metrics.",
+			[]string{"scheduledreport", "namespace"},
+			nil,
+		),
+		results: make(map[string]scheduledReportResults),
+	}
+}
+
+func (c *scheduledReportResultsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.rowsDesc
+}
+
+func (c *scheduledReportResultsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]string, 0, len(c.results))
+	for key := range c.results {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// gauges with the same name must have the same labels, so if two
+	// ScheduledReports export conflicting gauges, only the first is exposed
+	labelNamesByName := make(map[string]string)
+	for _, key := range keys {
+		results := c.results[key]
+		ch <- prometheus.MustNewConstMetric(c.rowsDesc, prometheus.GaugeValue, float64(results.rows), results.labelValues...)
+		for _, gauge := range results.gauges {
+			labelNames := strings.Join(gauge.labelNames, ",")
+			if existing, exists := labelNamesByName[gauge.fqName]; exists && existing != labelNames {
+				continue
+			}
+			labelNamesByName[gauge.fqName] = labelNames
+			desc := prometheus.NewDesc(gauge.fqName, gauge.help, gauge.labelNames, nil)
+			for _, sample := range gauge.samples {
+				ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, sample.value, sample.labelValues...)
+			}
+		}
+	}
+}
+
+func (c *scheduledReportResultsCollector) set(report *cbTypes.ScheduledReport, rows int, gauges []scheduledReportGauge) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[report.Namespace+"/"+report.Name] = scheduledReportResults{
+		labelValues: []string{report.Name, report.Namespace},
+		rows:        rows,
+		gauges:      gauges,
+	}
+}
+
+func (c *scheduledReportResultsCollector) has(report *cbTypes.ScheduledReport) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, exists := c.results[report.Namespace+"/"+report.Name]
+	return exists
+}
+
+// delete stops exposing the results of the ScheduledReport with the
+// specified namespace/name key, returning whether they were exposed.
+func (c *scheduledReportResultsCollector) delete(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, exists := c.results[key]
+	delete(c.results, key)
+	return exists
+}
+
+// exportScheduledReportMetrics updates the gauges exposing the results of
+// the ScheduledReport's most recent run and pushes them to the Pushgateway,
+// or stops exposing them if spec.metrics is unset. Failures are logged
+// rather than returned, as they don't affect the report itself.
+func (op *Reporting) exportScheduledReportMetrics(ctx context.Context, logger log.FieldLogger, report *cbTypes.ScheduledReport, genQuery *cbTypes.ReportGenerationQuery) {
+	if report.Spec.Metrics == nil || len(report.Status.RunHistory) == 0 || report.Status.TableName == "" {
+		op.stopExportingScheduledReportMetrics(ctx, logger, report.Namespace, report.Name)
+		return
+	}
+	prestoColumns, err := reportingutil.GeneratePrestoColumns(genQuery)
+	if err != nil {
+		logger.WithError(err).Errorf("unable to export ScheduledReport results as metrics")
+		return
+	}
+	results, err := op.reportResultsRepo.GetReportResults(ctx, report.Status.TableName, prestoColumns)
+	if err != nil {
+		logger.WithError(err).Errorf("unable to export ScheduledReport results as metrics")
+		return
+	}
+	rows, gauges, err := scheduledReportResultGauges(report, genQuery.Spec.Columns, results)
+	if err != nil {
+		logger.WithError(err).Errorf("unable to export ScheduledReport results as metrics")
+		return
+	}
+	scheduledReportMetricsCollector.set(report, rows, gauges)
+	logger.Debugf("exported %d rows of ScheduledReport results as metrics", rows)
+
+	if err := op.pushScheduledReportMetrics(ctx, report, rows, gauges); err != nil {
+		logger.WithError(err).Errorf("unable to push ScheduledReport results to the Pushgateway")
+	}
+}
+
+// stopExportingScheduledReportMetrics stops exposing the results of a
+// ScheduledReport, and deletes them from the Pushgateway if they were
+// exported.
+func (op *Reporting) stopExportingScheduledReportMetrics(ctx context.Context, logger log.FieldLogger, namespace, name string) {
+	if !scheduledReportMetricsCollector.delete(namespace + "/" + name) {
+		return
+	}
+	if err := op.deleteScheduledReportPushgatewayMetrics(ctx, namespace, name); err != nil {
+		logger.WithError(err).Errorf("unable to delete ScheduledReport results from the Pushgateway")
+	}
+}
+
+// scheduledReportResultGauges converts the results of a ScheduledReport's
+// most recent run into gauges. When the results contain a period_start
+// column, rows from previous runs are ignored. If more than one row has the
+// same label values, the last row is used.
+func scheduledReportResultGauges(report *cbTypes.ScheduledReport, columns []cbTypes.ReportGenerationQueryColumn, results []presto.Row) (int, []scheduledReportGauge, error) {
+	columnTypes := make(map[string]string, len(columns))
+	for _, column := range columns {
+		columnTypes[column.Name] = column.Type
+	}
+
+	valueColumns := report.Spec.Metrics.ValueColumns
+	labelColumns := report.Spec.Metrics.LabelColumns
+	if len(valueColumns) == 0 {
+		for _, column := range columns {
+			if isNumericColumnType(column.Type) {
+				valueColumns = append(valueColumns, column.Name)
+			}
+		}
+	}
+	if len(labelColumns) == 0 {
+		for _, column := range columns {
+			if column.Type == "string" || column.Type == "varchar" {
+				labelColumns = append(labelColumns, column.Name)
+			}
+		}
+	}
+	for _, column := range valueColumns {
+		if !isNumericColumnType(columnTypes[column]) {
+			return 0, nil, fmt.Errorf("spec.metrics.valueColumns: %s is not a numeric column of ReportGenerationQuery %s", column, report.Spec.GenerationQueryName)
+		}
+	}
+	for _, column := range labelColumns {
+		if _, exists := columnTypes[column]; !exists {
+			return 0, nil, fmt.Errorf("spec.metrics.labelColumns: %s is not a column of ReportGenerationQuery %s", column, report.Spec.GenerationQueryName)
+		}
+	}
+
+	var latestPeriodStart *time.Time
+	if columnTypes["period_start"] == "timestamp" && len(report.Status.RunHistory) != 0 {
+		latestPeriodStart = &report.Status.RunHistory[0].PeriodStart.Time
+	}
+
+	prefix := report.Spec.Metrics.Name
+	if prefix == "" {
+		prefix = "metering_" + report.Name
+	}
+	labelNames := make([]string, len(labelColumns))
+	for i, column := range labelColumns {
+		labelNames[i] = sanitizePrometheusName(column)
+	}
+
+	gauges := make([]scheduledReportGauge, len(valueColumns))
+	samplesByLabels := make([]map[string]int, len(valueColumns))
+	for i, column := range valueColumns {
+		gauges[i] = scheduledReportGauge{
+			fqName:     sanitizePrometheusName(prefix + "_" + column),
+			help:       fmt.Sprintf("The %s column of the most recent run of ScheduledReport %s.", column, report.Name),
+			labelNames: labelNames,
+		}
+		samplesByLabels[i] = make(map[string]int)
+	}
+
+	rows := 0
+	for _, row := range results {
+		if latestPeriodStart != nil {
+			if periodStart, ok := row["period_start"].(time.Time); !ok || !periodStart.Equal(*latestPeriodStart) {
+				continue
+			}
+		}
+		rows++
+		labelValues := make([]string, len(labelColumns))
+		for i, column := range labelColumns {
+			labelValues[i], _ = formatResultValue(row[column])
+		}
+		key := strings.Join(labelValues, "\xff")
+		for i, column := range valueColumns {
+			value, ok := numericResultValue(row[column])
+			if !ok {
+				continue
+			}
+			sample := scheduledReportSample{labelValues: labelValues, value: value}
+			if idx, exists := samplesByLabels[i][key]; exists {
+				gauges[i].samples[idx] = sample
+			} else {
+				samplesByLabels[i][key] = len(gauges[i].samples)
+				gauges[i].samples = append(gauges[i].samples, sample)
+			}
+		}
+	}
+	return rows, gauges, nil
+}
+
+func isNumericColumnType(columnType string) bool {
+	switch strings.ToLower(columnType) {
+	case "double", "real", "bigint", "integer", "int", "smallint", "tinyint":
+		return true
+	}
+	return false
+}
+
+// sanitizePrometheusName replaces characters which aren't valid in a
+// Prometheus metric or label name with underscores.
+func sanitizePrometheusName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == ':' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,66 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func TestScheduledReportResultGauges(t *testing.T) {
+	day1 := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	report := &cbTypes.ScheduledReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "namespace-cost-daily", Namespace: "metering"},
+		Spec: cbTypes.ScheduledReportSpec{
+			GenerationQueryName: "namespace-cost",
+			Metrics:             &cbTypes.ScheduledReportMetrics{},
+		},
+		Status: cbTypes.ScheduledReportStatus{
+			RunHistory: []cbTypes.ScheduledReportRun{{PeriodStart: metav1.Time{Time: day2}}},
+		},
+	}
+	columns := []cbTypes.ReportGenerationQueryColumn{
+		{Name: "period_start", Type: "timestamp"},
+		{Name: "namespace", Type: "string"},
+		{Name: "cost", Type: "double"},
+	}
+	results := []presto.Row{
+		{"period_start": day1, "namespace": "team-a", "cost": 1.0},
+		{"period_start": day2, "namespace": "team-a", "cost": 2.0},
+		{"period_start": day2, "namespace": "team-b", "cost": 3.0},
+	}
+
+	rows, gauges, err := scheduledReportResultGauges(report, columns, results)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rows, "expected only rows from the most recent run to be exported")
+	require.Len(t, gauges, 1)
+	assert.Equal(t, "metering_namespace_cost_daily_cost", gauges[0].fqName)
+	assert.Equal(t, []string{"namespace"}, gauges[0].labelNames)
+	assert.Equal(t, []scheduledReportSample{
+		{labelValues: []string{"team-a"}, value: 2},
+		{labelValues: []string{"team-b"}, value: 3},
+	}, gauges[0].samples)
+
+	collector := newScheduledReportResultsCollector()
+	collector.set(report, rows, gauges)
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(collector))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	names := make([]string, len(families))
+	for i, family := range families {
+		names[i] = family.GetName()
+	}
+	assert.Equal(t, []string{"metering_namespace_cost_daily_cost", "metering_scheduledreport_exported_rows"}, names)
+
+	report.Spec.Metrics.ValueColumns = []string{"namespace"}
+	_, _, err = scheduledReportResultGauges(report, columns, results)
+	assert.Error(t, err, "expected an error exporting a non-numeric column")
+}
@@ -0,0 +1,92 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/admission"
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+// ScheduledReportDefaultsConfig configures the defaults set on new
+// ScheduledReports by the defaulting webhook. Defaults which are unset
+// aren't set on ScheduledReports.
+type ScheduledReportDefaultsConfig struct {
+	// GracePeriod is the default spec.gracePeriod.
+	GracePeriod time.Duration
+	// Timezone is the default spec.schedule.timezone.
+	Timezone string
+	// StorageLocationName is the name of the StorageLocation used as the
+	// default spec.output.
+	StorageLocationName string
+}
+
+func (cfg *ScheduledReportDefaultsConfig) Valid() error {
+	if cfg.GracePeriod < 0 {
+		return fmt.Errorf("default ScheduledReport grace period must not be negative, got %s", cfg.GracePeriod)
+	}
+	if cfg.Timezone != "" {
+		if _, err := time.LoadLocation(cfg.Timezone); err != nil {
+			return fmt.Errorf("invalid default ScheduledReport timezone %q: %v", cfg.Timezone, err)
+		}
+	}
+	return nil
+}
+
+// jsonPatchOperation is an operation of the JSON patch returned by mutating
+// webhooks.
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// defaultScheduledReportAdmission sets the configured defaults on new
+// ScheduledReports, so they don't have to be repeated in each one.
+func (op *Reporting) defaultScheduledReportAdmission(req *admission.AdmissionRequest) *admission.AdmissionResponse {
+	if req.Operation != admission.Create {
+		return admission.Allowed()
+	}
+	var report cbTypes.ScheduledReport
+	if err := json.Unmarshal(req.Object.Raw, &report); err != nil {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("unable to decode ScheduledReport: %v", err))
+	}
+	// the patch can only add to the spec and schedule if they exist, so
+	// check the object sent rather than the decoded ScheduledReport.
+	var fields struct {
+		Spec *struct {
+			Schedule *json.RawMessage `json:"schedule"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(req.Object.Raw, &fields); err != nil {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("unable to decode ScheduledReport: %v", err))
+	}
+	if fields.Spec == nil {
+		return admission.Allowed()
+	}
+
+	defaults := op.cfg.ScheduledReportDefaults
+	var patch []jsonPatchOperation
+	if report.Spec.GracePeriod == nil && defaults.GracePeriod != 0 {
+		patch = append(patch, jsonPatchOperation{Op: "add", Path: "/spec/gracePeriod", Value: meta.Duration{Duration: defaults.GracePeriod}})
+	}
+	if fields.Spec.Schedule != nil && report.Spec.Schedule.Timezone == "" && defaults.Timezone != "" {
+		patch = append(patch, jsonPatchOperation{Op: "add", Path: "/spec/schedule/timezone", Value: defaults.Timezone})
+	}
+	if report.Spec.Output == nil && defaults.StorageLocationName != "" {
+		patch = append(patch, jsonPatchOperation{Op: "add", Path: "/spec/output", Value: cbTypes.StorageLocationRef{StorageLocationName: defaults.StorageLocationName}})
+	}
+	if len(patch) == 0 {
+		return admission.Allowed()
+	}
+
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, fmt.Errorf("unable to encode patch: %v", err))
+	}
+	return admission.Patched(patchBytes)
+}
@@ -0,0 +1,74 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/operator-framework/operator-metering/pkg/admission"
+)
+
+func TestDefaultScheduledReportAdmission(t *testing.T) {
+	op := &Reporting{
+		cfg: Config{
+			ScheduledReportDefaults: ScheduledReportDefaultsConfig{
+				GracePeriod:         10 * time.Minute,
+				Timezone:            "America/New_York",
+				StorageLocationName: "s3-reports",
+			},
+		},
+	}
+	tests := map[string]struct {
+		operation admission.Operation
+		object    string
+		patch     string
+	}{
+		"all defaults": {
+			operation: admission.Create,
+			object:    `{"spec": {"generationQuery": "pod-cpu", "schedule": {"period": "daily"}}}`,
+			patch:     `[{"op": "add", "path": "/spec/gracePeriod", "value": "10m0s"}, {"op": "add", "path": "/spec/schedule/timezone", "value": "America/New_York"}, {"op": "add", "path": "/spec/output", "value": {"storageLocationName": "s3-reports"}}]`,
+		},
+		"fields already set": {
+			operation: admission.Create,
+			object:    `{"spec": {"gracePeriod": "1h", "schedule": {"period": "daily", "timezone": "UTC"}, "output": {"storageLocationName": "hive"}}}`,
+		},
+		"no schedule": {
+			operation: admission.Create,
+			object:    `{"spec": {"gracePeriod": "1h", "output": {"storageLocationName": "hive"}}}`,
+		},
+		"no spec": {
+			operation: admission.Create,
+			object:    `{}`,
+		},
+		"updates aren't defaulted": {
+			operation: admission.Update,
+			object:    `{"spec": {"generationQuery": "pod-cpu", "schedule": {"period": "daily"}}}`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := op.defaultScheduledReportAdmission(&admission.AdmissionRequest{
+				Operation: tt.operation,
+				Object:    runtime.RawExtension{Raw: []byte(tt.object)},
+			})
+			require.True(t, resp.Allowed)
+			if tt.patch == "" {
+				assert.Nil(t, resp.Patch)
+				return
+			}
+			require.NotNil(t, resp.PatchType)
+			assert.Equal(t, admission.PatchTypeJSONPatch, *resp.PatchType)
+			assert.JSONEq(t, tt.patch, string(resp.Patch))
+		})
+	}
+
+	resp := (&Reporting{}).defaultScheduledReportAdmission(&admission.AdmissionRequest{
+		Operation: admission.Create,
+		Object:    runtime.RawExtension{Raw: []byte(`{"spec": {"schedule": {"period": "daily"}}}`)},
+	})
+	assert.True(t, resp.Allowed)
+	assert.Nil(t, resp.Patch, "expected nothing to be defaulted if no defaults are configured")
+}
@@ -1,6 +1,7 @@
 package operator
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -17,11 +18,15 @@ import (
 	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
 	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
 	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
+	"github.com/operator-framework/operator-metering/pkg/tracing"
 	"github.com/operator-framework/operator-metering/pkg/util/slice"
 )
 
 const (
 	scheduledReportFinalizer = cbTypes.GroupName + "/scheduledreport"
+	// maxScheduledReportRunHistory is the number of runs kept in a
+	// ScheduledReport's status.runHistory
+	maxScheduledReportRunHistory = 5
 )
 
 var (
@@ -63,10 +68,9 @@ func init() {
 }
 
 func (op *Reporting) runScheduledReportWorker() {
-	logger := op.logger.WithField("component", "scheduledReportWorker")
+	logger := op.logger.WithField(logFieldController, "scheduledreport")
 	logger.Infof("ScheduledReport worker started")
-	const maxRequeues = 5
-	for op.processResource(logger, op.syncScheduledReport, "ScheduledReport", op.scheduledReportQueue, maxRequeues) {
+	for op.processResource(logger, op.syncScheduledReport, "ScheduledReport", op.scheduledReportQueue, op.cfg.MaxRetriesConfig.ScheduledReport) {
 	}
 }
 
@@ -77,11 +81,11 @@ func (op *Reporting) syncScheduledReport(logger log.FieldLogger, key string) err
 		return nil
 	}
 
-	logger = logger.WithField("ScheduledReport", name)
 	scheduledReport, err := op.scheduledReportLister.ScheduledReports(namespace).Get(name)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Infof("ScheduledReport %s does not exist anymore, stopping and removing any running jobs for ScheduledReport", name)
+			op.stopExportingScheduledReportMetrics(op.workCtx, logger, namespace, name)
 			return nil
 		}
 		return err
@@ -102,7 +106,30 @@ type reportSchedule interface {
 	Next(time.Time) time.Time
 }
 
+// locationSchedule evaluates a schedule in a time zone, so for example a
+// daily report runs at midnight in that time zone.
+type locationSchedule struct {
+	schedule reportSchedule
+	location *time.Location
+}
+
+func (s locationSchedule) Next(t time.Time) time.Time {
+	return s.schedule.Next(t.In(s.location)).In(t.Location())
+}
+
 func getSchedule(reportSched cbTypes.ScheduledReportSchedule) (reportSchedule, error) {
+	schedule, err := getUTCSchedule(reportSched)
+	if err != nil || reportSched.Timezone == "" {
+		return schedule, err
+	}
+	location, err := time.LoadLocation(reportSched.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ScheduledReport.spec.schedule.timezone %q: %v", reportSched.Timezone, err)
+	}
+	return locationSchedule{schedule: schedule, location: location}, nil
+}
+
+func getUTCSchedule(reportSched cbTypes.ScheduledReportSchedule) (reportSchedule, error) {
 	var cronSpec string
 	switch reportSched.Period {
 	case cbTypes.ScheduledReportPeriodCron:
@@ -197,6 +224,18 @@ func (op *Reporting) handleScheduledReport(logger log.FieldLogger, scheduledRepo
 		}
 	}
 
+	// export the results of reports which last ran before reporting-operator
+	// started, or which spec.metrics was just added to
+	if scheduledReport.Spec.Metrics == nil {
+		op.stopExportingScheduledReportMetrics(op.workCtx, logger, scheduledReport.Namespace, scheduledReport.Name)
+	} else if len(scheduledReport.Status.RunHistory) != 0 && !scheduledReportMetricsCollector.has(scheduledReport) {
+		if genQuery, err := op.reportGenerationQueryLister.ReportGenerationQueries(scheduledReport.Namespace).Get(scheduledReport.Spec.GenerationQueryName); err == nil {
+			ctx, cancel := op.withReportQueryTimeout(op.workCtx)
+			op.exportScheduledReportMetrics(ctx, logger, scheduledReport, genQuery)
+			cancel()
+		}
+	}
+
 	return op.runScheduledReport(logger, scheduledReport)
 }
 
@@ -218,9 +257,9 @@ func (op *Reporting) runScheduledReport(logger log.FieldLogger, report *cbTypes.
 			return nil
 		}
 
-		err := fmt.Errorf("ScheduledReport spec.reportingEnd (%s) must be after spec.reportingStart (%s)", report.Spec.ReportingEnd.Time, report.Spec.ReportingStart.Time)
+		err := reasonErrorf(nil, cbutil.ValidationErrorReason, "ScheduledReport spec.reportingEnd (%s) must be after spec.reportingStart (%s)", report.Spec.ReportingEnd.Time, report.Spec.ReportingStart.Time)
 
-		failureCondition := cbutil.NewScheduledReportCondition(cbTypes.ScheduledReportFailure, v1.ConditionTrue, cbutil.InvalidReportingEndReason, err.Error())
+		failureCondition := cbutil.NewScheduledReportCondition(cbTypes.ScheduledReportFailure, v1.ConditionTrue, cbutil.ValidationErrorReason, err.Error())
 		cbutil.RemoveScheduledReportCondition(&report.Status, cbTypes.ScheduledReportRunning)
 		cbutil.SetScheduledReportCondition(&report.Status, *failureCondition)
 
@@ -256,9 +295,9 @@ func (op *Reporting) runScheduledReport(logger log.FieldLogger, report *cbTypes.
 				return nil
 			}
 
-			err := fmt.Errorf("ScheduledReport spec.reportingEnd (%s) is set to a time before status.lastReportTime (%s), cannot process", report.Spec.ReportingEnd.Time, report.Status.LastReportTime.Time)
+			err := reasonErrorf(nil, cbutil.ValidationErrorReason, "ScheduledReport spec.reportingEnd (%s) is set to a time before status.lastReportTime (%s), cannot process", report.Spec.ReportingEnd.Time, report.Status.LastReportTime.Time)
 
-			failureCondition := cbutil.NewScheduledReportCondition(cbTypes.ScheduledReportFailure, v1.ConditionTrue, cbutil.InvalidReportingEndReason, err.Error())
+			failureCondition := cbutil.NewScheduledReportCondition(cbTypes.ScheduledReportFailure, v1.ConditionTrue, cbutil.ValidationErrorReason, err.Error())
 			cbutil.RemoveScheduledReportCondition(&report.Status, cbTypes.ScheduledReportRunning)
 			cbutil.SetScheduledReportCondition(&report.Status, *failureCondition)
 
@@ -359,15 +398,26 @@ func (op *Reporting) runScheduledReport(logger log.FieldLogger, report *cbTypes.
 	)
 	if err != nil {
 		// wrapped the error with more information
-		err = fmt.Errorf("unable to run ScheduledReport %s, ReportGenerationQuery %s, failed to validate dependencies: %v", report.Name, genQuery.Name, err)
-
+		err = reasonErrorf(err, cbutil.ValidationErrorReason, "unable to run ScheduledReport %s, ReportGenerationQuery %s, failed to validate dependencies: %v", report.Name, genQuery.Name, err)
+	} else if _, inputsErr := reporting.ValidateReportGenerationQueryInputs(genQuery, report.Spec.Inputs); inputsErr != nil {
+		err = reasonErrorf(inputsErr, cbutil.ValidationErrorReason, "unable to run ScheduledReport %s, invalid spec.inputs: %v", report.Name, inputsErr)
+	}
+	limits, limitsErr := reportExecutionLimits(report.Spec.MaxRuntime, report.Spec.MaxResultRows)
+	if err == nil && limitsErr != nil {
+		err = reasonErrorf(limitsErr, cbutil.ValidationErrorReason, "unable to run ScheduledReport %s, invalid execution limits: %v", report.Name, limitsErr)
+	}
+	pricing, pricingErr := op.getPricingPolicy(report.Namespace, report.Spec.PricingPolicy)
+	if err == nil && pricingErr != nil {
+		err = reasonErrorf(pricingErr, cbutil.ValidationErrorReason, "unable to run ScheduledReport %s, invalid spec.pricingPolicy: %v", report.Name, pricingErr)
+	}
+	if err != nil {
 		// avoid continously triggering an update cycle if we're already failed
 		// validation
-		if isFailureCond := cbutil.GetScheduledReportCondition(report.Status, cbTypes.ScheduledReportFailure); isFailureCond != nil && isFailureCond.Status == v1.ConditionTrue && isFailureCond.Reason == cbutil.FailedValidationReason {
+		if isFailureCond := cbutil.GetScheduledReportCondition(report.Status, cbTypes.ScheduledReportFailure); isFailureCond != nil && isFailureCond.Status == v1.ConditionTrue && isFailureCond.Reason == errorReason(err) {
 			logger.Warnf("ScheduledReport %s failed validation last reconcile, skipping updating status", report.Name)
 		} else {
 			// update the status to indicate the query failed validation
-			failureCondition := cbutil.NewScheduledReportCondition(cbTypes.ScheduledReportFailure, v1.ConditionTrue, cbutil.FailedValidationReason, err.Error())
+			failureCondition := cbutil.NewScheduledReportCondition(cbTypes.ScheduledReportFailure, v1.ConditionTrue, errorReason(err), err.Error())
 			cbutil.RemoveScheduledReportCondition(&report.Status, cbTypes.ScheduledReportRunning)
 			cbutil.SetScheduledReportCondition(&report.Status, *failureCondition)
 
@@ -381,7 +431,7 @@ func (op *Reporting) runScheduledReport(logger log.FieldLogger, report *cbTypes.
 		return err
 	}
 	// if it was previously failed validation, remove the status
-	if isFailureCond := cbutil.GetScheduledReportCondition(report.Status, cbTypes.ScheduledReportFailure); isFailureCond != nil && isFailureCond.Status == v1.ConditionTrue && isFailureCond.Reason == cbutil.FailedValidationReason {
+	if isFailureCond := cbutil.GetScheduledReportCondition(report.Status, cbTypes.ScheduledReportFailure); isFailureCond != nil && isFailureCond.Status == v1.ConditionTrue && (isFailureCond.Reason == cbutil.ValidationErrorReason || isFailureCond.Reason == cbutil.DependencyMissingReason) {
 		cbutil.RemoveScheduledReportCondition(&report.Status, cbTypes.ScheduledReportFailure)
 	}
 
@@ -416,18 +466,38 @@ func (op *Reporting) runScheduledReport(logger log.FieldLogger, report *cbTypes.
 		}
 	}
 
-	tableName := reportingutil.ScheduledReportTableName(report.Name)
+	release, err := op.acquireReportSlot(logger)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	key, err := cache.MetaNamespaceKeyFunc(report)
+	if err != nil {
+		return err
+	}
+	queryCtx, cancel := op.newReportQueryContext(logger, "ScheduledReport", key)
+	defer cancel()
+	queryCtx = reporting.WithExecutionLimits(queryCtx, limits)
+	queryCtx = reporting.WithQueryPriority(queryCtx, reportQueryPriority(reporting.ScheduledReportQueryClass, &reportPeriod.periodStart, &reportPeriod.periodEnd))
+	queryCtx = reporting.WithPricing(queryCtx, pricing)
+
+	tableName := reportingutil.ScheduledReportTableName(op.tableNamespace(report.Namespace), report.Name)
 	// if tableName isn't set, this report is still new and we should make sure
 	// no tables exist already in case of a previously failed cleanup.
 	if report.Status.TableName == "" {
 		logger.Debugf("dropping table %s", tableName)
-		err = op.tableManager.DropTable(tableName, true)
+		err = traceCall(queryCtx, "hive.dropTable", func() error {
+			return op.tableManager.DropTable(queryCtx, tableName, true)
+		}, tracing.String("db.table", tableName))
 		if err != nil {
-			return fmt.Errorf("unable to drop table %s before creating for ScheduledReport %s: %v", tableName, report.Name, err)
+			return reasonErrorf(err, cbutil.HiveErrorReason, "unable to drop table %s before creating for ScheduledReport %s: %v", tableName, report.Name, err)
 		}
 
 		columns := reportingutil.GenerateHiveColumns(genQuery)
-		err = op.createTableForStorage(logger, report, cbTypes.SchemeGroupVersion.WithKind("ScheduledReport"), report.Spec.Output, tableName, columns, nil)
+		err = traceCall(queryCtx, "hive.createTable", func() error {
+			return op.createTableForStorage(queryCtx, logger, report, cbTypes.SchemeGroupVersion.WithKind("ScheduledReport"), report.Spec.Output, tableName, columns, nil)
+		}, tracing.String("db.table", tableName))
 		if err != nil {
 			logger.WithError(err).Error("error creating report table for scheduledReport")
 			return err
@@ -454,6 +524,7 @@ func (op *Reporting) runScheduledReport(logger log.FieldLogger, report *cbTypes.
 	genReportTotalCounter.Inc()
 	generateReportStart := op.clock.Now()
 	err = op.reportGenerator.GenerateReport(
+		queryCtx,
 		tableName,
 		&reportPeriod.periodStart,
 		&reportPeriod.periodEnd,
@@ -465,12 +536,24 @@ func (op *Reporting) runScheduledReport(logger log.FieldLogger, report *cbTypes.
 	generateReportDuration := op.clock.Since(generateReportStart)
 	genReportDurationObserver.Observe(float64(generateReportDuration.Seconds()))
 
+	if err != nil && op.queriesCancelledByShutdown() {
+		// the period is generated again once reporting-operator restarts,
+		// as lastReportTime hasn't been updated, so it isn't a failure.
+		logger.WithError(err).Warnf("ScheduledReport generation was interrupted by reporting-operator shutting down")
+		return err
+	}
 	if err != nil {
 		genReportFailedCounter.Inc()
+		reason := classifyError(err, cbutil.PrestoErrorReason)
+		if queryCtx.Err() == context.DeadlineExceeded {
+			reason = cbutil.TimeoutReason
+			err = fmt.Errorf("queries exceeded the report query timeout of %s: %v", op.config().ReportQueryTimeout, err)
+		}
+		tracing.SpanFromContext(queryCtx).SetError(err)
 		// update the status to Failed with message containing the
 		// error
 		errMsg := fmt.Sprintf("error occurred while generating report: %s", err)
-		failureCondition := cbutil.NewScheduledReportCondition(cbTypes.ScheduledReportFailure, v1.ConditionTrue, cbutil.GenerateReportErrorReason, errMsg)
+		failureCondition := cbutil.NewScheduledReportCondition(cbTypes.ScheduledReportFailure, v1.ConditionTrue, reason, errMsg)
 		cbutil.RemoveScheduledReportCondition(&report.Status, cbTypes.ScheduledReportRunning)
 		cbutil.SetScheduledReportCondition(&report.Status, *failureCondition)
 
@@ -479,7 +562,7 @@ func (op *Reporting) runScheduledReport(logger log.FieldLogger, report *cbTypes.
 			logger.WithError(updateErr).Errorf("unable to update ScheduledReport status")
 			return updateErr
 		}
-		return fmt.Errorf("failed to generateReport for ScheduledReport %s, err: %v", report.Name, err)
+		return reasonErrorf(err, reason, "failed to generateReport for ScheduledReport %s, err: %v", report.Name, err)
 	}
 	// We generated a report successfully, remove any existing failure
 	// conditions that may exist
@@ -487,6 +570,18 @@ func (op *Reporting) runScheduledReport(logger log.FieldLogger, report *cbTypes.
 
 	// Update the LastReportTime
 	report.Status.LastReportTime = &metav1.Time{Time: reportPeriod.periodEnd}
+	report.Status.RowCount = op.countReportRows(queryCtx, logger, tableName)
+
+	// Record the queries used for this run, keeping a limited history
+	run := cbTypes.ScheduledReportRun{
+		PeriodStart:             metav1.Time{Time: reportPeriod.periodStart},
+		PeriodEnd:               metav1.Time{Time: reportPeriod.periodEnd},
+		GenerationQueryVersions: reporting.GetGenerationQueryVersions(genQuery, queryDependencies, op.generationQueryTexts),
+	}
+	report.Status.RunHistory = append([]cbTypes.ScheduledReportRun{run}, report.Status.RunHistory...)
+	if len(report.Status.RunHistory) > maxScheduledReportRunHistory {
+		report.Status.RunHistory = report.Status.RunHistory[:maxScheduledReportRunHistory]
+	}
 
 	// check if we've reached the configured ReportingEnd, and if so, update
 	// the status to indicate the report has finished
@@ -506,6 +601,8 @@ func (op *Reporting) runScheduledReport(logger log.FieldLogger, report *cbTypes.
 		return err
 	}
 
+	op.exportScheduledReportMetrics(queryCtx, logger, report, genQuery)
+
 	if err := op.queueDependentReportGenerationQueriesForScheduledReport(report); err != nil {
 		logger.WithError(err).Errorf("error queuing ReportGenerationQuery dependents of ScheduledReport %s", report.Name)
 	}
@@ -557,7 +654,7 @@ func convertDayOfWeek(dow string) (int, error) {
 func (op *Reporting) addScheduledReportFinalizer(report *cbTypes.ScheduledReport) (*cbTypes.ScheduledReport, error) {
 	report.Finalizers = append(report.Finalizers, scheduledReportFinalizer)
 	newScheduledReport, err := op.meteringClient.MeteringV1alpha1().ScheduledReports(report.Namespace).Update(report)
-	logger := op.logger.WithField("ScheduledReport", report.Name)
+	logger := newObjectLogger(op.logger, "ScheduledReport", report)
 	if err != nil {
 		logger.WithError(err).Errorf("error adding %s finalizer to ScheduledReport: %s/%s", scheduledReportFinalizer, report.Namespace, report.Name)
 		return nil, err
@@ -572,7 +669,7 @@ func (op *Reporting) removeScheduledReportFinalizer(report *cbTypes.ScheduledRep
 	}
 	report.Finalizers = slice.RemoveString(report.Finalizers, scheduledReportFinalizer, nil)
 	newScheduledReport, err := op.meteringClient.MeteringV1alpha1().ScheduledReports(report.Namespace).Update(report)
-	logger := op.logger.WithField("ScheduledReport", report.Name)
+	logger := newObjectLogger(op.logger, "ScheduledReport", report)
 	if err != nil {
 		logger.WithError(err).Errorf("error removing %s finalizer from ScheduledReport: %s/%s", scheduledReportFinalizer, report.Namespace, report.Name)
 		return nil, err
@@ -597,7 +694,7 @@ func (op *Reporting) queueDependentReportGenerationQueriesForScheduledReport(sch
 
 	for _, query := range queries.Items {
 		// look at the list Report of dependencies
-		for _, dependency := range query.Spec.ScheduledReports {
+		for _, dependency := range reporting.DependentScheduledReportNames(query) {
 			if dependency == scheduledReport.Name {
 				// this query depends on the Report passed in
 				op.enqueueReportGenerationQuery(query)
@@ -13,6 +13,7 @@ func TestGetNextReportPeriod(t *testing.T) {
 	baseTime := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
 	tests := map[string]struct {
 		period              v1alpha1.ScheduledReportPeriod
+		timezone            string
 		expectError         bool
 		expectReportPeriods []reportPeriod
 	}{
@@ -42,6 +43,20 @@ func TestGetNextReportPeriod(t *testing.T) {
 				},
 			},
 		},
+		"daily in America/New_York": {
+			period:   v1alpha1.ScheduledReportPeriodDaily,
+			timezone: "America/New_York",
+			expectReportPeriods: []reportPeriod{
+				{
+					periodStart: baseTime,
+					periodEnd:   time.Date(2018, time.July, 1, 4, 0, 0, 0, time.UTC),
+				},
+				{
+					periodStart: time.Date(2018, time.July, 1, 4, 0, 0, 0, time.UTC),
+					periodEnd:   time.Date(2018, time.July, 2, 4, 0, 0, 0, time.UTC),
+				},
+			},
+		},
 		"weekly": {
 			period: v1alpha1.ScheduledReportPeriodWeekly,
 			expectReportPeriods: []reportPeriod{
@@ -69,7 +84,8 @@ func TestGetNextReportPeriod(t *testing.T) {
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			apiSched := v1alpha1.ScheduledReportSchedule{
-				Period: test.period,
+				Period:   test.period,
+				Timezone: test.timezone,
 				// Normally only one is set, but we simply use a zero value
 				// for each to make it easier in tests.
 				Hourly:  &v1alpha1.ScheduledReportScheduleHourly{},
@@ -0,0 +1,113 @@
+package operator
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const (
+	// defaultSharedVolumeClaimName and defaultSharedVolumeMountPath are the
+	// PersistentVolumeClaim and mount path the Presto chart uses when
+	// sharedVolume is enabled.
+	defaultSharedVolumeClaimName = "hive-warehouse-data"
+	defaultSharedVolumeMountPath = "/user/hive/warehouse"
+
+	defaultSharedVolumeCapacityWarningThreshold = 80
+)
+
+var storageLocationCapacityBytesGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "metering",
+		Name:      "storagelocation_capacity_bytes",
+		Help:      "Capacity of the PersistentVolumeClaim of a sharedVolume StorageLocation.",
+	},
+	[]string{"namespace", "storagelocation"},
+)
+
+func init() {
+	prometheus.MustRegister(storageLocationCapacityBytesGauge)
+}
+
+// sharedVolumeStorageLocation returns the file:// URL of the
+// SharedVolumeStorage's path within the volume's mount path.
+func sharedVolumeStorageLocation(sharedVolume *cbTypes.SharedVolumeStorage) (string, error) {
+	mountPath := sharedVolume.MountPath
+	if mountPath == "" {
+		mountPath = defaultSharedVolumeMountPath
+	}
+	if !path.IsAbs(mountPath) {
+		return "", fmt.Errorf("spec.sharedVolume.mountPath must be an absolute path, got %q", mountPath)
+	}
+	// the path must not escape the volume
+	for _, elem := range strings.Split(sharedVolume.Path, "/") {
+		if elem == ".." {
+			return "", fmt.Errorf("spec.sharedVolume.path must be within the volume, got %q", sharedVolume.Path)
+		}
+	}
+	return "file://" + path.Join(mountPath, sharedVolume.Path), nil
+}
+
+// sharedVolumeHiveStorage returns the HiveStorage equivalent to the
+// SharedVolumeStorage.
+func sharedVolumeHiveStorage(sharedVolume *cbTypes.SharedVolumeStorage) (*cbTypes.HiveStorage, error) {
+	location, err := sharedVolumeStorageLocation(sharedVolume)
+	if err != nil {
+		return nil, err
+	}
+	return &cbTypes.HiveStorage{
+		TableProperties: cbTypes.TableProperties{Location: location},
+		FileFormat:      sharedVolume.FileFormat,
+		Compression:     sharedVolume.Compression,
+	}, nil
+}
+
+// checkSharedVolumeCapacity records the capacity of a sharedVolume
+// StorageLocation's PersistentVolumeClaim in status, and warns when
+// usedBytes exceeds it's capacityWarningThreshold. The volume may be shared
+// by multiple StorageLocations, or contain other data, so the warning is
+// only an approximation of how full the volume is.
+func (op *Reporting) checkSharedVolumeCapacity(logger log.FieldLogger, storageLocation *cbTypes.StorageLocation, usedBytes int64, status *cbTypes.StorageLocationStatus) error {
+	sharedVolume := storageLocation.Spec.SharedVolume
+	claimName := sharedVolume.PersistentVolumeClaimName
+	if claimName == "" {
+		claimName = defaultSharedVolumeClaimName
+	}
+	claim, err := op.kubeClient.PersistentVolumeClaims(op.cfg.Namespace).Get(claimName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to get PersistentVolumeClaim %s: %v", claimName, err)
+	}
+	capacity, ok := claim.Status.Capacity[v1.ResourceStorage]
+	if !ok {
+		// the claim hasn't been bound yet
+		logger.Debugf("PersistentVolumeClaim %s has no capacity", claimName)
+		return nil
+	}
+	capacityBytes := capacity.Value()
+	storageLocationCapacityBytesGauge.With(prometheus.Labels{"namespace": storageLocation.Namespace, "storagelocation": storageLocation.Name}).Set(float64(capacityBytes))
+
+	threshold := int64(defaultSharedVolumeCapacityWarningThreshold)
+	if sharedVolume.CapacityWarningThreshold != nil {
+		threshold = int64(*sharedVolume.CapacityWarningThreshold)
+	}
+	warning := usedBytes*100 > capacityBytes*threshold
+	if warning != storageLocation.Status.CapacityWarning {
+		if warning {
+			logger.Warnf("StorageLocation is using %d bytes, more than %d%% of the %d byte capacity of PersistentVolumeClaim %s", usedBytes, threshold, capacityBytes, claimName)
+			op.eventRecorder.Eventf(storageLocation, v1.EventTypeWarning, "StorageCapacityLow", "using %d bytes, more than %d%% of the %d byte capacity of PersistentVolumeClaim %s", usedBytes, threshold, capacityBytes, claimName)
+		} else {
+			logger.Infof("StorageLocation is using %d bytes, within %d%% of the %d byte capacity of PersistentVolumeClaim %s", usedBytes, threshold, capacityBytes, claimName)
+			op.eventRecorder.Eventf(storageLocation, v1.EventTypeNormal, "StorageCapacitySufficient", "using %d bytes, within %d%% of the %d byte capacity of PersistentVolumeClaim %s", usedBytes, threshold, capacityBytes, claimName)
+		}
+	}
+	status.CapacityBytes = capacityBytes
+	status.CapacityWarning = warning
+	return nil
+}
@@ -0,0 +1,228 @@
+package operator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	defaultSQLDataSourceRefreshInterval = time.Hour
+	// sqlDataSourceQueryTimeout is the maximum amount of time spent querying
+	// the external database and storing the results.
+	sqlDataSourceQueryTimeout = 10 * time.Minute
+)
+
+// importedColumnTypes maps the Hive types supported in the columns of SQL
+// and Kafka ReportDataSources to the Presto type values are cast to when
+// inserted. Types with parameters, such as decimal(10,2), are matched by the
+// name before the parameters.
+var importedColumnTypes = map[string]string{
+	"string":    "varchar",
+	"varchar":   "varchar",
+	"char":      "char",
+	"tinyint":   "tinyint",
+	"smallint":  "smallint",
+	"int":       "integer",
+	"bigint":    "bigint",
+	"float":     "real",
+	"double":    "double",
+	"decimal":   "decimal",
+	"boolean":   "boolean",
+	"date":      "date",
+	"timestamp": "timestamp",
+}
+
+func (op *Reporting) handleSQLDataSource(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	source := dataSource.Spec.SQL
+	hiveColumns, prestoColumns, err := sqlDataSourceColumns(source)
+	if err != nil {
+		return reasonErrorf(err, cbutil.ValidationErrorReason, "ReportDataSource %q: improperly configured datasource, %v", dataSource.Name, err)
+	}
+
+	if dataSource.Status.TableName != "" {
+		logger.Infof("existing SQL ReportDataSource discovered, tableName: %s", dataSource.Status.TableName)
+	} else {
+		logger.Infof("new SQL ReportDataSource discovered")
+		tableName := op.dataSourceTableName(dataSource)
+		err := op.createTableForStorage(op.workCtx, logger, dataSource, cbTypes.SchemeGroupVersion.WithKind("ReportDataSource"), source.Storage, tableName, hiveColumns, nil)
+		if err != nil {
+			return err
+		}
+
+		dataSource, err = op.updateDataSourceTableName(logger, dataSource, tableName)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to update ReportDataSource TableName field %q", tableName)
+			return err
+		}
+	}
+
+	refreshInterval := defaultSQLDataSourceRefreshInterval
+	if source.RefreshInterval != nil {
+		refreshInterval = source.RefreshInterval.Duration
+	}
+
+	// ReportDataSources are resynced more often than they're refreshed, so
+	// only run the query once the refresh interval has elapsed.
+	now := op.clock.Now().UTC()
+	if status := dataSource.Status.SQLImportStatus; status != nil && status.LastImportTime != nil {
+		nextImport := status.LastImportTime.Add(refreshInterval)
+		if now.Before(nextImport) {
+			logger.Debugf("SQL ReportDataSource %s was last refreshed at %s, queuing to refresh again at %s", dataSource.Name, status.LastImportTime.Time, nextImport)
+			op.enqueueReportDataSourceAfter(dataSource, nextImport.Sub(now))
+			return nil
+		}
+	}
+
+	secret, err := op.kubeClient.Secrets(dataSource.Namespace).Get(source.ConnectionSecret.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to get connection Secret %s for ReportDataSource %s: %v", source.ConnectionSecret.Name, dataSource.Name, err)
+	}
+	connStr, ok := secret.Data[source.ConnectionSecret.Key]
+	if !ok {
+		return reasonErrorf(nil, cbutil.ValidationErrorReason, "connection Secret %s for ReportDataSource %s has no key %s", secret.Name, dataSource.Name, source.ConnectionSecret.Key)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sqlDataSourceQueryTimeout)
+	defer cancel()
+
+	logger.Infof("querying %s database for SQL ReportDataSource %s", source.Driver, dataSource.Name)
+	rows, err := querySQLDataSource(ctx, string(source.Driver), string(connStr), source.Query, len(prestoColumns))
+	if err != nil {
+		return fmt.Errorf("unable to query %s database for ReportDataSource %s: %v", source.Driver, dataSource.Name, err)
+	}
+
+	err = op.sqlRowsRepo.ReplaceRows(ctx, dataSource.Status.TableName, prestoColumns, rows)
+	if err != nil {
+		return reasonErrorf(err, cbutil.PrestoErrorReason, "unable to store query results for ReportDataSource %s: %v", dataSource.Name, err)
+	}
+	logger.Infof("stored %d rows in table %s", len(rows), dataSource.Status.TableName)
+
+	lastImportTime := metav1.NewTime(now)
+	dataSource.Status.SQLImportStatus = &cbTypes.SQLImportStatus{
+		LastImportTime: &lastImportTime,
+		ImportedRows:   int64(len(rows)),
+	}
+	dataSource, err = op.meteringClient.MeteringV1alpha1().ReportDataSources(dataSource.Namespace).Update(dataSource)
+	if err != nil {
+		return fmt.Errorf("unable to update ReportDataSource %s SQLImportStatus: %v", dataSource.Name, err)
+	}
+
+	nextImport := op.clock.Now().Add(refreshInterval).UTC()
+	logger.Infof("queuing SQL ReportDataSource %s to refresh again in %s at %s", dataSource.Name, refreshInterval, nextImport)
+	op.enqueueReportDataSourceAfter(dataSource, refreshInterval)
+	return nil
+}
+
+// sqlDataSourceColumns validates the SQL ReportDataSource and returns the
+// Hive columns used to create it's table, and the Presto columns used to
+// insert rows.
+func sqlDataSourceColumns(source *cbTypes.SQLDataSource) ([]hive.Column, []presto.Column, error) {
+	switch source.Driver {
+	case cbTypes.SQLDataSourceDriverPostgres, cbTypes.SQLDataSourceDriverMySQL:
+	default:
+		return nil, nil, fmt.Errorf("driver must be %s or %s, got %q", cbTypes.SQLDataSourceDriverPostgres, cbTypes.SQLDataSourceDriverMySQL, source.Driver)
+	}
+	if source.ConnectionSecret == nil {
+		return nil, nil, fmt.Errorf("connectionSecret is empty")
+	}
+	if source.Query == "" {
+		return nil, nil, fmt.Errorf("query is empty")
+	}
+	if source.RefreshInterval != nil && source.RefreshInterval.Duration <= 0 {
+		return nil, nil, fmt.Errorf("refreshInterval must be positive")
+	}
+	if len(source.Columns) == 0 {
+		return nil, nil, fmt.Errorf("columns is empty")
+	}
+
+	hiveColumns := make([]hive.Column, len(source.Columns))
+	prestoColumns := make([]presto.Column, len(source.Columns))
+	seen := make(map[string]struct{})
+	for i, column := range source.Columns {
+		if column.Name == "" {
+			return nil, nil, fmt.Errorf("column names cannot be empty")
+		}
+		name := strings.ToLower(column.Name)
+		if _, exists := seen[name]; exists {
+			return nil, nil, fmt.Errorf("multiple columns named %s", column.Name)
+		}
+		seen[name] = struct{}{}
+
+		hiveColumn, prestoColumn, err := importedColumn(column.Name, column.Type)
+		if err != nil {
+			return nil, nil, err
+		}
+		hiveColumns[i] = hiveColumn
+		prestoColumns[i] = prestoColumn
+	}
+	return hiveColumns, prestoColumns, nil
+}
+
+// importedColumn returns the Hive column used to create a table with the
+// column, and the Presto column used to insert values into it.
+func importedColumn(name, columnType string) (hive.Column, presto.Column, error) {
+	hiveType := strings.ToLower(strings.TrimSpace(columnType))
+	if hiveType == "" {
+		hiveType = "string"
+	}
+	typeName, typeParams := hiveType, ""
+	if i := strings.Index(hiveType, "("); i != -1 {
+		typeName, typeParams = hiveType[:i], hiveType[i:]
+	}
+	prestoType, ok := importedColumnTypes[typeName]
+	if !ok {
+		return hive.Column{}, presto.Column{}, fmt.Errorf("column %s has unsupported type %s", name, columnType)
+	}
+	return hive.Column{Name: name, Type: hiveType}, presto.Column{Name: name, Type: prestoType + typeParams}, nil
+}
+
+// querySQLDataSource runs the query against the database and returns every
+// row of the results.
+func querySQLDataSource(ctx context.Context, driver, connStr, query string, numColumns int) ([][]interface{}, error) {
+	db, err := sql.Open(driver, connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if len(cols) != numColumns {
+		return nil, fmt.Errorf("query returned %d columns, but %d columns are configured", len(cols), numColumns)
+	}
+
+	var results [][]interface{}
+	for rows.Next() {
+		values := make([]interface{}, numColumns)
+		dest := make([]interface{}, numColumns)
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		results = append(results, values)
+	}
+	return results, rows.Err()
+}
@@ -1,16 +1,30 @@
 package operator
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/azure"
+	"github.com/operator-framework/operator-metering/pkg/gcs"
 	cbListers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
 	"github.com/operator-framework/operator-metering/pkg/hive"
 	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
 func (op *Reporting) getDefaultStorageLocation(lister cbListers.StorageLocationLister) (*cbTypes.StorageLocation, error) {
+	return op.getAnnotatedStorageLocation(lister, cbTypes.IsDefaultStorageLocationAnnotation)
+}
+
+func (op *Reporting) getDefaultPerNamespaceStorageLocation(lister cbListers.StorageLocationLister) (*cbTypes.StorageLocation, error) {
+	return op.getAnnotatedStorageLocation(lister, cbTypes.IsDefaultPerNamespaceStorageLocationAnnotation)
+}
+
+func (op *Reporting) getAnnotatedStorageLocation(lister cbListers.StorageLocationLister, annotation string) (*cbTypes.StorageLocation, error) {
 	storageLocations, err := lister.StorageLocations(op.cfg.Namespace).List(labels.Everything())
 	if err != nil {
 		return nil, err
@@ -19,7 +33,7 @@ func (op *Reporting) getDefaultStorageLocation(lister cbListers.StorageLocationL
 	var defaultStorageLocations []*cbTypes.StorageLocation
 
 	for _, storageLocation := range storageLocations {
-		if storageLocation.Annotations[cbTypes.IsDefaultStorageLocationAnnotation] == "true" {
+		if storageLocation.Annotations[annotation] == "true" {
 			defaultStorageLocations = append(defaultStorageLocations, storageLocation)
 		}
 	}
@@ -29,20 +43,98 @@ func (op *Reporting) getDefaultStorageLocation(lister cbListers.StorageLocationL
 	}
 
 	if len(defaultStorageLocations) > 1 {
-		op.logger.Infof("getDefaultStorageLocation: %d default storageLocations found", len(defaultStorageLocations))
-		return nil, fmt.Errorf("%d defaultStorageLocations were found", len(defaultStorageLocations))
+		op.logger.Infof("getAnnotatedStorageLocation: %d storageLocations with annotation %s found", len(defaultStorageLocations), annotation)
+		return nil, fmt.Errorf("%d storageLocations with annotation %s were found", len(defaultStorageLocations), annotation)
 	}
 
 	return defaultStorageLocations[0], nil
 
 }
 
-func (op *Reporting) getStorageSpec(logger log.FieldLogger, storage *cbTypes.StorageLocationRef, kind string) (cbTypes.StorageLocationSpec, error) {
+// getStorageLocation gets the named StorageLocation, looking in the
+// namespace of the resource using it first, and falling back to the
+// operator's namespace.
+func (op *Reporting) getStorageLocation(lister cbListers.StorageLocationLister, namespace, name string) (*cbTypes.StorageLocation, error) {
+	if namespace != "" && namespace != op.cfg.Namespace {
+		storageLocation, err := lister.StorageLocations(namespace).Get(name)
+		if err == nil {
+			return storageLocation, nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return lister.StorageLocations(op.cfg.Namespace).Get(name)
+}
+
+// getNamespaceDefaultStorageSpec returns the default storage for resources
+// in the namespace, if one is configured. A StorageLocation named by the
+// namespace's annotation takes precedence over the default-per-namespace
+// StorageLocation. Namespace annotations are only checked when watching
+// multiple namespaces, since it requires permission to get namespaces.
+func (op *Reporting) getNamespaceDefaultStorageSpec(logger log.FieldLogger, namespace string) (*cbTypes.StorageLocationSpec, error) {
+	if namespace == "" {
+		return nil, nil
+	}
+	storageLister := op.storageLocationLister
+
+	if op.cfg.multiNamespace() {
+		ns, err := op.kubeClient.Namespaces().Get(namespace, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("unable to get namespace %s: %v", namespace, err)
+		}
+		if name := ns.Annotations[cbTypes.NamespaceDefaultStorageLocationAnnotation]; name != "" {
+			logger.Debugf("namespace %s configured to use StorageLocation %s by default", namespace, name)
+			storageLocation, err := op.getStorageLocation(storageLister, namespace, name)
+			if err != nil {
+				return nil, err
+			}
+			return &storageLocation.Spec, nil
+		}
+	}
+
+	storageLocation, err := op.getDefaultPerNamespaceStorageLocation(storageLister)
+	if err != nil {
+		return nil, err
+	}
+	if storageLocation == nil {
+		return nil, nil
+	}
+	logger.Debugf("using default-per-namespace StorageLocation %s for namespace %s", storageLocation.Name, namespace)
+	storageSpec := storageLocation.Spec.DeepCopy()
+	switch {
+	case storageSpec.Hive != nil && storageSpec.Hive.TableProperties.Location != "":
+		storageSpec.Hive.TableProperties.Location = strings.TrimSuffix(storageSpec.Hive.TableProperties.Location, "/") + "/" + namespace
+	case storageSpec.Azure != nil:
+		storageSpec.Azure.Path = strings.TrimSuffix(storageSpec.Azure.Path, "/") + "/" + namespace
+	case storageSpec.GCS != nil:
+		storageSpec.GCS.Path = strings.TrimSuffix(storageSpec.GCS.Path, "/") + "/" + namespace
+	case storageSpec.HDFS != nil:
+		storageSpec.HDFS.BasePath = strings.TrimSuffix(storageSpec.HDFS.BasePath, "/") + "/" + namespace
+	case storageSpec.SharedVolume != nil:
+		storageSpec.SharedVolume.Path = strings.TrimSuffix(storageSpec.SharedVolume.Path, "/") + "/" + namespace
+	case storageSpec.S3 != nil:
+		storageSpec.S3.Prefix = strings.TrimSuffix(storageSpec.S3.Prefix, "/") + "/" + namespace
+	default:
+		return nil, fmt.Errorf("default-per-namespace StorageLocation %s must configure spec.hive.tableProperties.location, spec.azure, spec.gcs, spec.hdfs, spec.sharedVolume or spec.s3", storageLocation.Name)
+	}
+	return storageSpec, nil
+}
+
+func (op *Reporting) getStorageSpec(logger log.FieldLogger, namespace string, storage *cbTypes.StorageLocationRef, kind string) (cbTypes.StorageLocationSpec, error) {
 	storageLister := op.storageLocationLister
 	var storageSpec cbTypes.StorageLocationSpec
 	// Nothing specified, try to use default storage location
 	if storage == nil || (storage.StorageSpec == nil && storage.StorageLocationName == "") {
 		logger.Debugf("%s storage does not have a spec or storageLocationName set, getting default storage location", kind)
+		namespaceStorageSpec, err := op.getNamespaceDefaultStorageSpec(logger, namespace)
+		if err != nil {
+			return storageSpec, err
+		}
+		if namespaceStorageSpec != nil {
+			return *namespaceStorageSpec, nil
+		}
+
 		storageLocation, err := op.getDefaultStorageLocation(storageLister)
 		if err != nil {
 			return storageSpec, err
@@ -54,7 +146,7 @@ func (op *Reporting) getStorageSpec(logger log.FieldLogger, storage *cbTypes.Sto
 		storageSpec = storageLocation.Spec
 	} else if storage.StorageLocationName != "" { // Specific storage location specified
 		logger.Debugf("%s configured to use StorageLocation %s", kind, storage.StorageLocationName)
-		storageLocation, err := storageLister.StorageLocations(op.cfg.Namespace).Get(storage.StorageLocationName)
+		storageLocation, err := op.getStorageLocation(storageLister, namespace, storage.StorageLocationName)
 		if err != nil {
 			return storageSpec, err
 		}
@@ -65,15 +157,257 @@ func (op *Reporting) getStorageSpec(logger log.FieldLogger, storage *cbTypes.Sto
 	return storageSpec, nil
 }
 
-func (op *Reporting) getHiveTableProperties(logger log.FieldLogger, storage *cbTypes.StorageLocationRef, kind string) (*hive.TableProperties, error) {
-	storageSpec, err := op.getStorageSpec(logger, storage, kind)
+func (op *Reporting) getHiveTableProperties(logger log.FieldLogger, namespace string, storage *cbTypes.StorageLocationRef, kind string) (*hive.TableProperties, error) {
+	storageSpec, err := op.getStorageSpec(logger, namespace, storage, kind)
 	if err != nil {
 		return nil, err
 	}
-	if storageSpec.Hive != nil {
-		props := hive.TableProperties(storageSpec.Hive.TableProperties)
+	switch {
+	case storageSpec.Hive != nil:
+		return hiveStorageTableProperties(storageSpec.Hive, op.cfg.TableCompression)
+	case storageSpec.Azure != nil:
+		hiveStorage, err := azureHiveStorage(storageSpec.Azure)
+		if err != nil {
+			return nil, err
+		}
+		if err := op.checkAzureStorageAccess(storageSpec.Azure); err != nil {
+			return nil, err
+		}
+		return hiveStorageTableProperties(hiveStorage, op.cfg.TableCompression)
+	case storageSpec.GCS != nil:
+		hiveStorage, err := gcsHiveStorage(storageSpec.GCS)
+		if err != nil {
+			return nil, err
+		}
+		if err := op.checkGCSStorageAccess(storageSpec.GCS); err != nil {
+			return nil, err
+		}
+		return hiveStorageTableProperties(hiveStorage, op.cfg.TableCompression)
+	case storageSpec.HDFS != nil:
+		hiveStorage, err := hdfsHiveStorage(storageSpec.HDFS)
+		if err != nil {
+			return nil, err
+		}
+		if err := createHDFSStorageBasePath(storageSpec.HDFS); err != nil {
+			return nil, err
+		}
+		return hiveStorageTableProperties(hiveStorage, op.cfg.TableCompression)
+	case storageSpec.SharedVolume != nil:
+		hiveStorage, err := sharedVolumeHiveStorage(storageSpec.SharedVolume)
+		if err != nil {
+			return nil, err
+		}
+		return hiveStorageTableProperties(hiveStorage, op.cfg.TableCompression)
+	case storageSpec.S3 != nil:
+		hiveStorage, err := s3HiveStorage(storageSpec.S3)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkS3StorageAccess(storageSpec.S3); err != nil {
+			return nil, err
+		}
+		return hiveStorageTableProperties(hiveStorage, op.cfg.TableCompression)
+	default:
+		return nil, fmt.Errorf("incorrect storage configuration, must configure spec.hive, spec.azure, spec.gcs, spec.hdfs, spec.sharedVolume or spec.s3")
+	}
+}
+
+// storageSpecLocation returns the location tables created using the
+// StorageLocationSpec are stored in, or an empty string if it's the Hive
+// server's default location.
+func storageSpecLocation(storageSpec *cbTypes.StorageLocationSpec) string {
+	switch {
+	case storageSpec.Hive != nil:
+		return storageSpec.Hive.TableProperties.Location
+	case storageSpec.Azure != nil:
+		location, err := azureStorageLocation(storageSpec.Azure)
+		if err != nil {
+			return ""
+		}
+		return location
+	case storageSpec.GCS != nil:
+		location, err := gcsStorageLocation(storageSpec.GCS)
+		if err != nil {
+			return ""
+		}
+		return location
+	case storageSpec.HDFS != nil:
+		location, err := hdfsStorageLocation(storageSpec.HDFS)
+		if err != nil {
+			return ""
+		}
+		return location
+	case storageSpec.SharedVolume != nil:
+		location, err := sharedVolumeStorageLocation(storageSpec.SharedVolume)
+		if err != nil {
+			return ""
+		}
+		return location
+	case storageSpec.S3 != nil:
+		location, err := s3StorageLocation(storageSpec.S3)
+		if err != nil {
+			return ""
+		}
+		return location
+	default:
+		return ""
+	}
+}
+
+// azureStorageLocation returns the URL of the AzureStorage's path, using the
+// Hadoop filesystem for it's type.
+func azureStorageLocation(azureStorage *cbTypes.AzureStorage) (string, error) {
+	if azureStorage.StorageAccount == "" || azureStorage.Container == "" {
+		return "", fmt.Errorf("spec.azure.storageAccount and spec.azure.container must be set")
+	}
+	var scheme, endpoint string
+	switch azureStorage.Type {
+	case cbTypes.AzureStorageTypeBlob, "":
+		scheme, endpoint = "wasbs", "blob.core.windows.net"
+	case cbTypes.AzureStorageTypeADLSGen2:
+		scheme, endpoint = "abfss", "dfs.core.windows.net"
+	default:
+		return "", fmt.Errorf("invalid spec.azure.type %q, must be %s or %s", azureStorage.Type, cbTypes.AzureStorageTypeBlob, cbTypes.AzureStorageTypeADLSGen2)
+	}
+	return fmt.Sprintf("%s://%s@%s.%s/%s", scheme, azureStorage.Container, azureStorage.StorageAccount, endpoint, strings.Trim(azureStorage.Path, "/")), nil
+}
+
+// azureHiveStorage returns the HiveStorage equivalent to the AzureStorage.
+func azureHiveStorage(azureStorage *cbTypes.AzureStorage) (*cbTypes.HiveStorage, error) {
+	location, err := azureStorageLocation(azureStorage)
+	if err != nil {
+		return nil, err
+	}
+	return &cbTypes.HiveStorage{
+		TableProperties: cbTypes.TableProperties{Location: location},
+		FileFormat:      azureStorage.FileFormat,
+		Compression:     azureStorage.Compression,
+	}, nil
+}
+
+// checkAzureStorageAccess returns an error if the AzureStorage's container
+// can't be accessed using it's account key, so misconfigured credentials
+// are reported clearly instead of as a failure to create a table.
+func (op *Reporting) checkAzureStorageAccess(azureStorage *cbTypes.AzureStorage) error {
+	if azureStorage.AccountKeySecret == nil {
+		return fmt.Errorf("spec.azure.accountKeySecret must be set")
+	}
+	secretName := azureStorage.AccountKeySecret.Name
+	secret, err := op.kubeClient.Secrets(op.cfg.Namespace).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to get storage account key Secret %s: %v", secretName, err)
+	}
+	accountKey, ok := secret.Data[azureStorage.AccountKeySecret.Key]
+	if !ok {
+		return fmt.Errorf("storage account key Secret %s has no key %s", secretName, azureStorage.AccountKeySecret.Key)
+	}
+	client, err := azure.NewBlobClient(azureStorage.StorageAccount, string(accountKey))
+	if err != nil {
+		return err
+	}
+	if err := client.CheckContainer(azureStorage.Container); err != nil {
+		return fmt.Errorf("unable to access storage account %s: %v", azureStorage.StorageAccount, err)
+	}
+	return nil
+}
+
+// hiveStorageTableProperties returns the properties of tables created using
+// the HiveStorage, applying it's fileFormat and compression to it's
+// tableProperties. If the HiveStorage doesn't configure compression,
+// defaultCompression is used for tables stored as orc or parquet.
+func hiveStorageTableProperties(hiveStorage *cbTypes.HiveStorage, defaultCompression string) (*hive.TableProperties, error) {
+	props := hive.TableProperties(*hiveStorage.TableProperties.DeepCopy())
+	if hiveStorage.FileFormat != "" {
+		props.FileFormat = strings.ToLower(hiveStorage.FileFormat)
+		if props.FileFormat != "orc" && props.FileFormat != "parquet" {
+			return nil, fmt.Errorf("invalid spec.hive.fileFormat %q, must be orc or parquet", hiveStorage.FileFormat)
+		}
+		// the input and output formats take precedence over the file format
+		props.InputFormat = ""
+		props.OutputFormat = ""
+	}
+
+	compression := hiveStorage.Compression
+	if compression == "" {
+		if defaultCompression == "" || props.InputFormat != "" {
+			return &props, nil
+		}
+		compressionProps, err := hive.CompressionTableProperties(props.FileFormat, defaultCompression)
+		if err != nil {
+			// the default only applies to formats supporting compression
+			return &props, nil
+		}
+		if props.TableProperties == nil {
+			props.TableProperties = make(map[string]string)
+		}
+		// compression configured using tableProperties takes precedence
+		for k, v := range compressionProps {
+			if _, exists := props.TableProperties[k]; !exists {
+				props.TableProperties[k] = v
+			}
+		}
 		return &props, nil
-	} else {
-		return nil, fmt.Errorf("incorrect storage configuration, must configure spec.hive")
 	}
+
+	if props.InputFormat != "" {
+		return nil, fmt.Errorf("spec.hive.compression can only be set when the fileFormat is orc or parquet")
+	}
+	compressionProps, err := hive.CompressionTableProperties(props.FileFormat, compression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec.hive.compression: %v", err)
+	}
+	if props.TableProperties == nil {
+		props.TableProperties = make(map[string]string)
+	}
+	for k, v := range compressionProps {
+		props.TableProperties[k] = v
+	}
+	return &props, nil
+}
+
+// gcsStorageLocation returns the gs:// URL of the GCSStorage's path.
+func gcsStorageLocation(gcsStorage *cbTypes.GCSStorage) (string, error) {
+	if gcsStorage.Bucket == "" {
+		return "", fmt.Errorf("spec.gcs.bucket must be set")
+	}
+	return fmt.Sprintf("gs://%s/%s", gcsStorage.Bucket, strings.Trim(gcsStorage.Path, "/")), nil
+}
+
+// gcsHiveStorage returns the HiveStorage equivalent to the GCSStorage.
+func gcsHiveStorage(gcsStorage *cbTypes.GCSStorage) (*cbTypes.HiveStorage, error) {
+	location, err := gcsStorageLocation(gcsStorage)
+	if err != nil {
+		return nil, err
+	}
+	return &cbTypes.HiveStorage{
+		TableProperties: cbTypes.TableProperties{Location: location},
+		FileFormat:      gcsStorage.FileFormat,
+		Compression:     gcsStorage.Compression,
+	}, nil
+}
+
+// checkGCSStorageAccess returns an error if the GCSStorage's bucket can't be
+// accessed using it's service account key, or the reporting-operator's
+// workload identity if it has no key.
+func (op *Reporting) checkGCSStorageAccess(gcsStorage *cbTypes.GCSStorage) error {
+	var serviceAccountKey []byte
+	if keySecret := gcsStorage.ServiceAccountKeySecret; keySecret != nil {
+		secret, err := op.kubeClient.Secrets(op.cfg.Namespace).Get(keySecret.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("unable to get service account key Secret %s: %v", keySecret.Name, err)
+		}
+		var ok bool
+		serviceAccountKey, ok = secret.Data[keySecret.Key]
+		if !ok {
+			return fmt.Errorf("service account key Secret %s has no key %s", keySecret.Name, keySecret.Key)
+		}
+	}
+	client, err := gcs.NewClient(context.Background(), serviceAccountKey)
+	if err != nil {
+		return err
+	}
+	if err := client.CheckBucket(gcsStorage.Bucket); err != nil {
+		return fmt.Errorf("unable to access bucket %s: %v", gcsStorage.Bucket, err)
+	}
+	return nil
 }
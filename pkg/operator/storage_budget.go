@@ -0,0 +1,282 @@
+package operator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
+)
+
+const (
+	DefaultStorageBudgetCheckInterval = 5 * time.Minute
+
+	defaultStorageBudgetProjectionPeriod = 24 * time.Hour
+	// storageBudgetGrowthWindow is how far back the usage samples used to
+	// estimate the growth rate of a StorageLocation go.
+	storageBudgetGrowthWindow = 6 * time.Hour
+	// maxStorageUsageSamples limits the number of samples kept for each
+	// StorageLocation within storageBudgetGrowthWindow.
+	maxStorageUsageSamples = 100
+)
+
+var (
+	storageLocationUsedBytesGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: prometheusMetricNamespace,
+			Name:      "storagelocation_used_bytes",
+			Help:      "Amount of data stored by tables using a StorageLocation.",
+		},
+		[]string{"namespace", "storagelocation"},
+	)
+	storageLocationProjectedBytesGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: prometheusMetricNamespace,
+			Name:      "storagelocation_projected_bytes",
+			Help:      "Projected amount of data stored by tables using a StorageLocation at the end of it's budget projection period.",
+		},
+		[]string{"namespace", "storagelocation"},
+	)
+	storageLocationBudgetBytesGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: prometheusMetricNamespace,
+			Name:      "storagelocation_budget_bytes",
+			Help:      "Storage budget configured for a StorageLocation.",
+		},
+		[]string{"namespace", "storagelocation"},
+	)
+	storageLocationBudgetExceededGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: prometheusMetricNamespace,
+			Name:      "storagelocation_budget_exceeded",
+			Help:      "Set to 1 when a StorageLocation is projected to exceed it's storage budget.",
+		},
+		[]string{"namespace", "storagelocation"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(storageLocationUsedBytesGauge)
+	prometheus.MustRegister(storageLocationProjectedBytesGauge)
+	prometheus.MustRegister(storageLocationBudgetBytesGauge)
+	prometheus.MustRegister(storageLocationBudgetExceededGauge)
+}
+
+type storageUsageSample struct {
+	bytes int64
+	time  time.Time
+}
+
+// addStorageUsageSample appends sample to samples, dropping samples older
+// than storageBudgetGrowthWindow, and the oldest samples beyond
+// maxStorageUsageSamples.
+func addStorageUsageSample(samples []storageUsageSample, sample storageUsageSample) []storageUsageSample {
+	var recent []storageUsageSample
+	for _, s := range samples {
+		if sample.time.Sub(s.time) <= storageBudgetGrowthWindow {
+			recent = append(recent, s)
+		}
+	}
+	recent = append(recent, sample)
+	if len(recent) > maxStorageUsageSamples {
+		recent = recent[len(recent)-maxStorageUsageSamples:]
+	}
+	return recent
+}
+
+// storageGrowthPerSecond estimates how many bytes a second usage is growing
+// by from the slope of a least squares fit of the samples, so a burst such
+// as a single large import between two checks doesn't dominate the
+// projection.
+func storageGrowthPerSecond(samples []storageUsageSample) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+	origin := samples[0].time
+	var meanSeconds, meanBytes float64
+	for _, s := range samples {
+		meanSeconds += s.time.Sub(origin).Seconds()
+		meanBytes += float64(s.bytes)
+	}
+	meanSeconds /= float64(len(samples))
+	meanBytes /= float64(len(samples))
+	var covariance, variance float64
+	for _, s := range samples {
+		dx := s.time.Sub(origin).Seconds() - meanSeconds
+		covariance += dx * (float64(s.bytes) - meanBytes)
+		variance += dx * dx
+	}
+	if variance == 0 {
+		return 0
+	}
+	return covariance / variance
+}
+
+// runStorageBudgetCheck periodically checks the usage of StorageLocations
+// with a budget configured until stopCh is closed.
+func (op *Reporting) runStorageBudgetCheck(stopCh <-chan struct{}) {
+	wait.Until(op.checkStorageBudgets, op.cfg.StorageBudgetCheckInterval, stopCh)
+}
+
+func (op *Reporting) checkStorageBudgets() {
+	logger := op.logger.WithField("component", "storageBudgetCheck")
+	storageLocations, err := op.storageLocationLister.List(labels.Everything())
+	if err != nil {
+		logger.WithError(err).Errorf("unable to list StorageLocations")
+		return
+	}
+	prestoTables, err := op.prestoTableLister.List(labels.Everything())
+	if err != nil {
+		logger.WithError(err).Errorf("unable to list PrestoTables")
+		return
+	}
+
+	// tables may be within multiple StorageLocations, so only get the size
+	// of each table once per check
+	tableSizes := make(map[string]int64)
+	for _, storageLocation := range storageLocations {
+		if (storageLocation.Spec.Budget == nil && storageLocation.Spec.SharedVolume == nil) || storageSpecLocation(&storageLocation.Spec) == "" || !op.cfg.isWatchedNamespace(storageLocation.Namespace) {
+			continue
+		}
+		err := op.checkStorageLocationUsage(logger.WithField("storageLocation", storageLocation.Name), storageLocation, prestoTables, tableSizes)
+		if err != nil {
+			logger.WithError(err).Errorf("unable to check storage usage of StorageLocation %s", storageLocation.Name)
+		}
+	}
+}
+
+// checkStorageLocationUsage updates the amount of data stored in the
+// StorageLocation, and checks it against the StorageLocation's budget, and
+// the capacity of it's volume if it's a sharedVolume StorageLocation.
+func (op *Reporting) checkStorageLocationUsage(logger log.FieldLogger, storageLocation *cbTypes.StorageLocation, prestoTables []*cbTypes.PrestoTable, tableSizes map[string]int64) error {
+	location := storageSpecLocation(&storageLocation.Spec)
+	var usedBytes int64
+	for _, prestoTable := range prestoTables {
+		if !isWithinLocation(prestoTable.Status.Properties.Location, location) {
+			continue
+		}
+		tableName := prestoTable.Status.Parameters.Name
+		size, exists := tableSizes[tableName]
+		if !exists {
+			var err error
+			size, err = op.tableManager.GetTableSize(op.workCtx, tableName)
+			if err != nil {
+				return fmt.Errorf("unable to get size of table %s: %v", tableName, err)
+			}
+			tableSizes[tableName] = size
+		}
+		usedBytes += size
+	}
+	storageLocationUsedBytesGauge.With(prometheus.Labels{"namespace": storageLocation.Namespace, "storagelocation": storageLocation.Name}).Set(float64(usedBytes))
+
+	now := op.clock.Now().UTC()
+	status := storageLocation.Status.DeepCopy()
+	status.UsedBytes = usedBytes
+	status.LastCheckTime = &metav1.Time{Time: now}
+	if storageLocation.Spec.Budget != nil {
+		op.checkStorageBudget(logger, storageLocation, usedBytes, now, status)
+	}
+	if storageLocation.Spec.SharedVolume != nil {
+		if err := op.checkSharedVolumeCapacity(logger, storageLocation, usedBytes, status); err != nil {
+			return err
+		}
+	}
+
+	storageLocation = storageLocation.DeepCopy()
+	storageLocation.Status = *status
+	_, err := op.meteringClient.MeteringV1alpha1().StorageLocations(storageLocation.Namespace).Update(storageLocation)
+	if err != nil {
+		return fmt.Errorf("unable to update StorageLocation status: %v", err)
+	}
+	return nil
+}
+
+// checkStorageBudget projects the usage of the StorageLocation at the end of
+// it's budget's projection period, and pauses low priority ReportDataSources
+// stored in it if the budget will be exceeded.
+func (op *Reporting) checkStorageBudget(logger log.FieldLogger, storageLocation *cbTypes.StorageLocation, usedBytes int64, now time.Time, status *cbTypes.StorageLocationStatus) {
+	location := storageSpecLocation(&storageLocation.Spec)
+	projectionPeriod := defaultStorageBudgetProjectionPeriod
+	if storageLocation.Spec.Budget.ProjectionPeriod != nil {
+		projectionPeriod = storageLocation.Spec.Budget.ProjectionPeriod.Duration
+	}
+	key := storageLocation.Namespace + "/" + storageLocation.Name
+	projectedBytes := usedBytes
+
+	op.storageBudgetsMu.Lock()
+	samples := addStorageUsageSample(op.storageUsageSamples[key], storageUsageSample{bytes: usedBytes, time: now})
+	op.storageUsageSamples[key] = samples
+	if growthPerSecond := storageGrowthPerSecond(samples); growthPerSecond > 0 {
+		projectedBytes += int64(growthPerSecond * projectionPeriod.Seconds())
+	}
+	budgetBytes := storageLocation.Spec.Budget.MaxSize.Value()
+	exceeded := projectedBytes > budgetBytes
+	if exceeded {
+		op.overBudgetLocations[key] = location
+	} else {
+		delete(op.overBudgetLocations, key)
+	}
+	op.storageBudgetsMu.Unlock()
+
+	promLabels := prometheus.Labels{"namespace": storageLocation.Namespace, "storagelocation": storageLocation.Name}
+	storageLocationProjectedBytesGauge.With(promLabels).Set(float64(projectedBytes))
+	storageLocationBudgetBytesGauge.With(promLabels).Set(float64(budgetBytes))
+	exceededValue := 0.0
+	if exceeded {
+		exceededValue = 1
+	}
+	storageLocationBudgetExceededGauge.With(promLabels).Set(exceededValue)
+
+	logger.Debugf("StorageLocation is using %d bytes, projected to use %d bytes in %s, budget is %d bytes", usedBytes, projectedBytes, projectionPeriod, budgetBytes)
+	if exceeded != storageLocation.Status.BudgetExceeded {
+		if exceeded {
+			logger.Warnf("StorageLocation is projected to use %d bytes in %s, exceeding it's budget of %d bytes, pausing low priority ReportDataSources", projectedBytes, projectionPeriod, budgetBytes)
+			op.eventRecorder.Eventf(storageLocation, v1.EventTypeWarning, "StorageBudgetExceeded", "projected to use %d bytes in %s, exceeding budget of %d bytes, pausing low priority ReportDataSources", projectedBytes, projectionPeriod, budgetBytes)
+		} else {
+			logger.Infof("StorageLocation is within it's budget, resuming low priority ReportDataSources")
+			op.eventRecorder.Eventf(storageLocation, v1.EventTypeNormal, "StorageBudgetWithinLimits", "projected to use %d bytes in %s, within budget of %d bytes, resuming low priority ReportDataSources", projectedBytes, projectionPeriod, budgetBytes)
+		}
+	}
+
+	status.ProjectedBytes = projectedBytes
+	status.BudgetExceeded = exceeded
+}
+
+// overBudgetStorageLocation returns the name of the over budget
+// StorageLocation the ReportDataSource's table is stored in, or an empty
+// string if it's StorageLocation is within it's budget.
+func (op *Reporting) overBudgetStorageLocation(dataSource *cbTypes.ReportDataSource) (string, error) {
+	prestoTable, err := op.prestoTableLister.PrestoTables(dataSource.Namespace).Get(reportingutil.PrestoTableResourceNameFromKind("ReportDataSource", dataSource.Name))
+	if err != nil {
+		return "", err
+	}
+	op.storageBudgetsMu.Lock()
+	defer op.storageBudgetsMu.Unlock()
+	for key, location := range op.overBudgetLocations {
+		if isWithinLocation(prestoTable.Status.Properties.Location, location) {
+			return key, nil
+		}
+	}
+	return "", nil
+}
+
+// isWithinLocation returns true if tableLocation is the same as, or a
+// sub-directory of, storageLocation. Tables without a location use the Hive
+// warehouse directory, and only belong to StorageLocations without a
+// location.
+func isWithinLocation(tableLocation, storageLocation string) bool {
+	if storageLocation == "" || tableLocation == "" {
+		return tableLocation == storageLocation
+	}
+	storageLocation = strings.TrimSuffix(storageLocation, "/")
+	tableLocation = strings.TrimSuffix(tableLocation, "/")
+	return tableLocation == storageLocation || strings.HasPrefix(tableLocation, storageLocation+"/")
+}
@@ -0,0 +1,162 @@
+package operator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
+)
+
+func TestIsWithinLocation(t *testing.T) {
+	tests := []struct {
+		name            string
+		tableLocation   string
+		storageLocation string
+		expected        bool
+	}{
+		{
+			name:            "same location",
+			tableLocation:   "s3a://bucket/metering",
+			storageLocation: "s3a://bucket/metering/",
+			expected:        true,
+		},
+		{
+			name:            "sub-directory",
+			tableLocation:   "s3a://bucket/metering/team-a",
+			storageLocation: "s3a://bucket/metering",
+			expected:        true,
+		},
+		{
+			name:            "shared prefix is not a sub-directory",
+			tableLocation:   "s3a://bucket/metering-other",
+			storageLocation: "s3a://bucket/metering",
+			expected:        false,
+		},
+		{
+			name:            "both using the hive warehouse",
+			tableLocation:   "",
+			storageLocation: "",
+			expected:        true,
+		},
+		{
+			name:            "table in the hive warehouse",
+			tableLocation:   "",
+			storageLocation: "s3a://bucket/metering",
+			expected:        false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isWithinLocation(tt.tableLocation, tt.storageLocation))
+		})
+	}
+}
+
+func TestStorageGrowthPerSecond(t *testing.T) {
+	start := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	samples := func(bytes ...int64) []storageUsageSample {
+		var samples []storageUsageSample
+		for i, b := range bytes {
+			samples = append(samples, storageUsageSample{bytes: b, time: start.Add(time.Duration(i) * 5 * time.Minute)})
+		}
+		return samples
+	}
+	assert.Equal(t, 0.0, storageGrowthPerSecond(samples(1000)), "expected no growth from a single sample")
+	assert.Equal(t, 0.0, storageGrowthPerSecond(samples(1000, 1000, 1000)))
+	assert.InDelta(t, 10.0, storageGrowthPerSecond(samples(0, 3000, 6000, 9000)), 0.0001)
+	// a burst of 3000 bytes in the last 5 minutes would be 10 bytes a second
+	// from the last two samples alone
+	assert.InDelta(t, 3.0, storageGrowthPerSecond(samples(0, 0, 0, 3000)), 0.0001)
+}
+
+func TestAddStorageUsageSample(t *testing.T) {
+	start := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var samples []storageUsageSample
+	for i := 0; i < 2*maxStorageUsageSamples; i++ {
+		samples = addStorageUsageSample(samples, storageUsageSample{bytes: int64(i), time: start.Add(time.Duration(i) * time.Second)})
+	}
+	require.Len(t, samples, maxStorageUsageSamples)
+	assert.Equal(t, int64(maxStorageUsageSamples), samples[0].bytes, "expected the oldest samples to be dropped")
+
+	later := start.Add(storageBudgetGrowthWindow + time.Duration(2*maxStorageUsageSamples-10)*time.Second)
+	samples = addStorageUsageSample(samples, storageUsageSample{bytes: 1000, time: later})
+	require.Len(t, samples, 11)
+	assert.Equal(t, int64(2*maxStorageUsageSamples-10), samples[0].bytes, "expected samples older than the window to be dropped")
+	assert.Equal(t, int64(1000), samples[10].bytes)
+}
+
+func TestCheckStorageBudgetPausesAndResumes(t *testing.T) {
+	start := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	storageLocation := &cbTypes.StorageLocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "budgeted", Namespace: "default"},
+		Spec: cbTypes.StorageLocationSpec{
+			Hive: &cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{Location: "s3a://bucket/metering"}},
+			Budget: &cbTypes.StorageBudget{
+				MaxSize:          resource.MustParse("10000"),
+				ProjectionPeriod: &metav1.Duration{Duration: time.Hour},
+			},
+		},
+	}
+	dataSource := &cbTypes.ReportDataSource{ObjectMeta: metav1.ObjectMeta{Name: "cpu", Namespace: "default"}}
+	prestoTables := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, prestoTables.Add(&cbTypes.PrestoTable{
+		ObjectMeta: metav1.ObjectMeta{Name: reportingutil.PrestoTableResourceNameFromKind("ReportDataSource", "cpu"), Namespace: "default"},
+		Status:     cbTypes.PrestoTableStatus{Properties: cbTypes.TableProperties{Location: "s3a://bucket/metering/datasource_default_cpu"}},
+	}))
+	recorder := record.NewFakeRecorder(10)
+	op := &Reporting{
+		logger:              testLogger,
+		eventRecorder:       recorder,
+		prestoTableLister:   listers.NewPrestoTableLister(prestoTables),
+		storageUsageSamples: make(map[string][]storageUsageSample),
+		overBudgetLocations: make(map[string]string),
+	}
+	check := func(usedBytes int64, now time.Time) *cbTypes.StorageLocationStatus {
+		status := storageLocation.Status.DeepCopy()
+		op.checkStorageBudget(testLogger, storageLocation, usedBytes, now, status)
+		storageLocation.Status = *status
+		return status
+	}
+
+	status := check(1000, start)
+	assert.Equal(t, int64(1000), status.ProjectedBytes)
+	assert.False(t, status.BudgetExceeded)
+	assert.Len(t, recorder.Events, 0)
+
+	// growing 10 bytes a second projects 36000 more bytes in an hour
+	status = check(4000, start.Add(5*time.Minute))
+	assert.Equal(t, int64(40000), status.ProjectedBytes)
+	assert.True(t, status.BudgetExceeded)
+	require.Len(t, recorder.Events, 1)
+	assert.True(t, strings.HasPrefix(<-recorder.Events, "Warning StorageBudgetExceeded"))
+	overBudget, err := op.overBudgetStorageLocation(dataSource)
+	require.NoError(t, err)
+	assert.Equal(t, "default/budgeted", overBudget, "expected the ReportDataSource to be paused")
+
+	// staying over budget doesn't record another event
+	status = check(4000, start.Add(10*time.Minute))
+	assert.True(t, status.BudgetExceeded)
+	assert.Len(t, recorder.Events, 0)
+
+	// once the growth is outside the window, usage is projected to stay
+	// the same
+	status = check(4000, start.Add(storageBudgetGrowthWindow+time.Minute))
+	assert.Equal(t, int64(4000), status.ProjectedBytes)
+	assert.False(t, status.BudgetExceeded)
+	require.Len(t, recorder.Events, 1)
+	assert.True(t, strings.HasPrefix(<-recorder.Events, "Normal StorageBudgetWithinLimits"))
+	overBudget, err = op.overBudgetStorageLocation(dataSource)
+	require.NoError(t, err)
+	assert.Equal(t, "", overBudget, "expected the ReportDataSource to be resumed")
+}
@@ -0,0 +1,526 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/aws"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+)
+
+// fakeCoreV1 gets the namespaces in namespaces. Calling any other method
+// panics.
+type fakeCoreV1 struct {
+	corev1.CoreV1Interface
+	namespaces []*v1.Namespace
+}
+
+func (c *fakeCoreV1) Namespaces() corev1.NamespaceInterface {
+	return &fakeNamespaces{namespaces: c.namespaces}
+}
+
+type fakeNamespaces struct {
+	corev1.NamespaceInterface
+	namespaces []*v1.Namespace
+}
+
+func (n *fakeNamespaces) Get(name string, options metav1.GetOptions) (*v1.Namespace, error) {
+	for _, ns := range n.namespaces {
+		if ns.Name == name {
+			return ns, nil
+		}
+	}
+	return nil, apierrors.NewNotFound(v1.Resource("namespaces"), name)
+}
+
+func newTestStorageLocation(namespace, name string, annotations map[string]string, spec cbTypes.StorageLocationSpec) *cbTypes.StorageLocation {
+	return &cbTypes.StorageLocation{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Annotations: annotations},
+		Spec:       spec,
+	}
+}
+
+func newStorageLocationLister(t *testing.T, storageLocations ...*cbTypes.StorageLocation) listers.StorageLocationLister {
+	indexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	for _, storageLocation := range storageLocations {
+		require.NoError(t, indexer.Add(storageLocation))
+	}
+	return listers.NewStorageLocationLister(indexer)
+}
+
+func TestHiveStorageTableProperties(t *testing.T) {
+	tests := map[string]struct {
+		storage            cbTypes.HiveStorage
+		defaultCompression string
+		expected           hive.TableProperties
+		expectError        bool
+	}{
+		"no file format": {
+			storage:  cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{Location: "s3a://bucket/metering", FileFormat: "textfile"}},
+			expected: hive.TableProperties{Location: "s3a://bucket/metering", FileFormat: "textfile"},
+		},
+		"parquet with compression": {
+			storage: cbTypes.HiveStorage{
+				TableProperties: cbTypes.TableProperties{Location: "s3a://bucket/metering", FileFormat: "textfile", TableProperties: map[string]string{"a": "b"}},
+				FileFormat:      "Parquet",
+				Compression:     "snappy",
+			},
+			expected: hive.TableProperties{Location: "s3a://bucket/metering", FileFormat: "parquet", TableProperties: map[string]string{"a": "b", "parquet.compression": "SNAPPY"}},
+		},
+		"orc with compression": {
+			storage:  cbTypes.HiveStorage{FileFormat: "orc", Compression: "zlib"},
+			expected: hive.TableProperties{FileFormat: "orc", TableProperties: map[string]string{"orc.compress": "ZLIB"}},
+		},
+		"file format overrides input and output formats": {
+			storage:  cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{InputFormat: "in", OutputFormat: "out"}, FileFormat: "orc"},
+			expected: hive.TableProperties{FileFormat: "orc"},
+		},
+		"compression from table properties file format": {
+			storage:  cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{FileFormat: "ORC"}, Compression: "none"},
+			expected: hive.TableProperties{FileFormat: "ORC", TableProperties: map[string]string{"orc.compress": "NONE"}},
+		},
+		"unsupported file format": {
+			storage:     cbTypes.HiveStorage{FileFormat: "avro"},
+			expectError: true,
+		},
+		"compression without file format": {
+			storage:  cbTypes.HiveStorage{Compression: "zstd"},
+			expected: hive.TableProperties{TableProperties: map[string]string{"orc.compress": "ZSTD", "parquet.compression": "ZSTD"}},
+		},
+		"compression with input and output formats": {
+			storage:     cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{InputFormat: "in", OutputFormat: "out"}, Compression: "snappy"},
+			expectError: true,
+		},
+		"compression with textfile": {
+			storage:     cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{FileFormat: "textfile"}, Compression: "snappy"},
+			expectError: true,
+		},
+		"unsupported codec": {
+			storage:     cbTypes.HiveStorage{FileFormat: "orc", Compression: "lz4"},
+			expectError: true,
+		},
+		"default compression": {
+			storage:            cbTypes.HiveStorage{FileFormat: "parquet"},
+			defaultCompression: "gzip",
+			expected:           hive.TableProperties{FileFormat: "parquet", TableProperties: map[string]string{"parquet.compression": "GZIP"}},
+		},
+		"default compression does not override storage compression": {
+			storage:            cbTypes.HiveStorage{FileFormat: "orc", Compression: "none", TableProperties: cbTypes.TableProperties{TableProperties: map[string]string{"parquet.compression": "SNAPPY"}}},
+			defaultCompression: "zstd",
+			expected:           hive.TableProperties{FileFormat: "orc", TableProperties: map[string]string{"orc.compress": "NONE", "parquet.compression": "SNAPPY"}},
+		},
+		"default compression does not override table properties": {
+			storage:            cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{TableProperties: map[string]string{"orc.compress": "SNAPPY"}}},
+			defaultCompression: "zstd",
+			expected:           hive.TableProperties{TableProperties: map[string]string{"orc.compress": "SNAPPY", "parquet.compression": "ZSTD"}},
+		},
+		"default compression ignored for textfile": {
+			storage:            cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{FileFormat: "textfile"}},
+			defaultCompression: "zstd",
+			expected:           hive.TableProperties{FileFormat: "textfile"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			props, err := hiveStorageTableProperties(&test.storage, test.defaultCompression)
+			if test.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, *props)
+		})
+	}
+
+	// the StorageLocation's table properties must not be modified
+	storage := cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{TableProperties: map[string]string{}}, FileFormat: "orc", Compression: "snappy"}
+	_, err := hiveStorageTableProperties(&storage, "")
+	require.NoError(t, err)
+	assert.Empty(t, storage.TableProperties.TableProperties)
+}
+
+func TestAzureHiveStorage(t *testing.T) {
+	tests := map[string]struct {
+		storage     cbTypes.AzureStorage
+		expected    cbTypes.HiveStorage
+		expectError bool
+	}{
+		"blob": {
+			storage:  cbTypes.AzureStorage{Type: cbTypes.AzureStorageTypeBlob, StorageAccount: "account", Container: "metering", Path: "/operator_metering/storage/"},
+			expected: cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{Location: "wasbs://metering@account.blob.core.windows.net/operator_metering/storage"}},
+		},
+		"default type is blob": {
+			storage:  cbTypes.AzureStorage{StorageAccount: "account", Container: "metering"},
+			expected: cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{Location: "wasbs://metering@account.blob.core.windows.net/"}},
+		},
+		"adls gen2 with file format": {
+			storage:  cbTypes.AzureStorage{Type: cbTypes.AzureStorageTypeADLSGen2, StorageAccount: "account", Container: "metering", Path: "storage", FileFormat: "parquet", Compression: "snappy"},
+			expected: cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{Location: "abfss://metering@account.dfs.core.windows.net/storage"}, FileFormat: "parquet", Compression: "snappy"},
+		},
+		"invalid type": {
+			storage:     cbTypes.AzureStorage{Type: "File", StorageAccount: "account", Container: "metering"},
+			expectError: true,
+		},
+		"no container": {
+			storage:     cbTypes.AzureStorage{StorageAccount: "account"},
+			expectError: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			hiveStorage, err := azureHiveStorage(&test.storage)
+			if test.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, *hiveStorage)
+		})
+	}
+}
+
+func TestGCSHiveStorage(t *testing.T) {
+	hiveStorage, err := gcsHiveStorage(&cbTypes.GCSStorage{Bucket: "bucket", Path: "/operator_metering/storage/", FileFormat: "orc"})
+	require.NoError(t, err)
+	assert.Equal(t, cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{Location: "gs://bucket/operator_metering/storage"}, FileFormat: "orc"}, *hiveStorage)
+
+	_, err = gcsHiveStorage(&cbTypes.GCSStorage{Path: "storage"})
+	assert.Error(t, err)
+}
+
+func TestS3HiveStorage(t *testing.T) {
+	s3Storage := &cbTypes.S3Storage{
+		Bucket:     "metering",
+		Prefix:     "/operator_metering/storage",
+		Endpoint:   &cbTypes.S3Endpoint{URL: "https://minio.example.com:9000", ForcePathStyle: true},
+		FileFormat: "orc",
+	}
+	hiveStorage, err := s3HiveStorage(s3Storage)
+	require.NoError(t, err)
+	assert.Equal(t, cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{Location: "s3a://metering/operator_metering/storage/"}, FileFormat: "orc"}, *hiveStorage)
+	assert.Equal(t, "s3a://metering/operator_metering/storage/", storageSpecLocation(&cbTypes.StorageLocationSpec{S3: s3Storage}))
+
+	_, err = s3HiveStorage(&cbTypes.S3Storage{Prefix: "storage"})
+	assert.Error(t, err)
+	assert.Equal(t, "", storageSpecLocation(&cbTypes.StorageLocationSpec{S3: &cbTypes.S3Storage{Prefix: "storage"}}))
+}
+
+func TestS3Endpoint(t *testing.T) {
+	assert.Equal(t, aws.S3Endpoint{}, s3Endpoint(nil), "expected AWS S3 to be used")
+	assert.Equal(t, aws.S3Endpoint{
+		URL:                "https://minio.example.com:9000",
+		ForcePathStyle:     true,
+		InsecureSkipVerify: true,
+	}, s3Endpoint(&cbTypes.S3Endpoint{
+		URL:                "https://minio.example.com:9000",
+		ForcePathStyle:     true,
+		InsecureSkipVerify: true,
+	}))
+}
+
+func TestSharedVolumeHiveStorage(t *testing.T) {
+	tests := map[string]struct {
+		storage     cbTypes.SharedVolumeStorage
+		expected    cbTypes.HiveStorage
+		expectError bool
+	}{
+		"default mount path": {
+			storage:  cbTypes.SharedVolumeStorage{Path: "operator_metering/storage/"},
+			expected: cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{Location: "file:///user/hive/warehouse/operator_metering/storage"}},
+		},
+		"mount path": {
+			storage:  cbTypes.SharedVolumeStorage{MountPath: "/metering-data", FileFormat: "parquet"},
+			expected: cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{Location: "file:///metering-data"}, FileFormat: "parquet"},
+		},
+		"relative mount path": {
+			storage:     cbTypes.SharedVolumeStorage{MountPath: "metering-data"},
+			expectError: true,
+		},
+		"path outside volume": {
+			storage:     cbTypes.SharedVolumeStorage{Path: "storage/../../etc"},
+			expectError: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			hiveStorage, err := sharedVolumeHiveStorage(&test.storage)
+			if test.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, *hiveStorage)
+		})
+	}
+}
+
+func TestS3EncryptionAlgorithm(t *testing.T) {
+	tests := map[string]struct {
+		encryption  *cbTypes.S3Encryption
+		expected    string
+		expectError bool
+	}{
+		"unencrypted": {},
+		"sse-s3": {
+			encryption: &cbTypes.S3Encryption{Type: cbTypes.S3EncryptionTypeSSES3},
+			expected:   "AES256",
+		},
+		"sse-kms": {
+			encryption: &cbTypes.S3Encryption{Type: cbTypes.S3EncryptionTypeSSEKMS, KMSKeyID: "arn:aws:kms:us-east-1:123456789012:key/example"},
+			expected:   "aws:kms",
+		},
+		"sse-kms without key": {
+			encryption:  &cbTypes.S3Encryption{Type: cbTypes.S3EncryptionTypeSSEKMS},
+			expectError: true,
+		},
+		"sse-s3 with key": {
+			encryption:  &cbTypes.S3Encryption{Type: cbTypes.S3EncryptionTypeSSES3, KMSKeyID: "arn:aws:kms:us-east-1:123456789012:key/example"},
+			expectError: true,
+		},
+		"unknown type": {
+			encryption:  &cbTypes.S3Encryption{Type: "SSE-C"},
+			expectError: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			algorithm, err := s3EncryptionAlgorithm(test.encryption)
+			if test.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, algorithm)
+		})
+	}
+}
+
+func TestGetStorageLocation(t *testing.T) {
+	lister := newStorageLocationLister(t,
+		newTestStorageLocation("metering", "shared", nil, cbTypes.StorageLocationSpec{S3: &cbTypes.S3Storage{Bucket: "shared"}}),
+		newTestStorageLocation("team-a", "local", nil, cbTypes.StorageLocationSpec{S3: &cbTypes.S3Storage{Bucket: "team-a-local"}}),
+		newTestStorageLocation("team-b", "shared", nil, cbTypes.StorageLocationSpec{S3: &cbTypes.S3Storage{Bucket: "team-b-shared"}}),
+	)
+	op := &Reporting{cfg: Config{Namespace: "metering", TargetNamespaces: []string{"team-a", "team-b"}}}
+
+	tests := map[string]struct {
+		namespace      string
+		name           string
+		expectedBucket string
+		expectNotFound bool
+	}{
+		"in the namespace": {
+			namespace:      "team-a",
+			name:           "local",
+			expectedBucket: "team-a-local",
+		},
+		"falls back to the operator's namespace": {
+			namespace:      "team-a",
+			name:           "shared",
+			expectedBucket: "shared",
+		},
+		"the namespace takes precedence over the operator's namespace": {
+			namespace:      "team-b",
+			name:           "shared",
+			expectedBucket: "team-b-shared",
+		},
+		"the operator's namespace": {
+			namespace:      "metering",
+			name:           "shared",
+			expectedBucket: "shared",
+		},
+		"no namespace": {
+			name:           "shared",
+			expectedBucket: "shared",
+		},
+		"another namespace's StorageLocations can't be used": {
+			namespace:      "team-b",
+			name:           "local",
+			expectNotFound: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			storageLocation, err := op.getStorageLocation(lister, test.namespace, test.name)
+			if test.expectNotFound {
+				assert.True(t, apierrors.IsNotFound(err), "expected a NotFound error, got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedBucket, storageLocation.Spec.S3.Bucket)
+		})
+	}
+}
+
+func TestGetNamespaceDefaultStorageSpec(t *testing.T) {
+	perNamespace := map[string]string{cbTypes.IsDefaultPerNamespaceStorageLocationAnnotation: "true"}
+	namespaceDefault := func(name string) map[string]string {
+		return map[string]string{cbTypes.NamespaceDefaultStorageLocationAnnotation: name}
+	}
+	kubeClient := &fakeCoreV1{namespaces: []*v1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Annotations: namespaceDefault("team-storage")}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Annotations: namespaceDefault("local")}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "team-c"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "team-d", Annotations: namespaceDefault("missing")}},
+	}}
+	teamStorage := cbTypes.StorageLocationSpec{S3: &cbTypes.S3Storage{Bucket: "team-storage"}}
+	teamBStorage := cbTypes.StorageLocationSpec{S3: &cbTypes.S3Storage{Bucket: "team-b-local"}}
+	defaultStorage := newTestStorageLocation("metering", "per-namespace", perNamespace, cbTypes.StorageLocationSpec{
+		Hive: &cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{Location: "s3a://bucket/metering/"}},
+	})
+	storageLocations := []*cbTypes.StorageLocation{
+		defaultStorage,
+		newTestStorageLocation("metering", "team-storage", nil, teamStorage),
+		newTestStorageLocation("team-b", "local", nil, teamBStorage),
+	}
+	hiveLocation := func(location string) *cbTypes.StorageLocationSpec {
+		return &cbTypes.StorageLocationSpec{Hive: &cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{Location: location}}}
+	}
+
+	tests := map[string]struct {
+		cfg              Config
+		storageLocations []*cbTypes.StorageLocation
+		namespace        string
+		expected         *cbTypes.StorageLocationSpec
+		expectError      bool
+	}{
+		"no namespace": {
+			cfg:              Config{Namespace: "metering", AllNamespaces: true},
+			storageLocations: storageLocations,
+		},
+		"the namespace's annotation": {
+			cfg:              Config{Namespace: "metering", AllNamespaces: true},
+			storageLocations: storageLocations,
+			namespace:        "team-a",
+			expected:         &teamStorage,
+		},
+		"the namespace's annotation naming a StorageLocation in the namespace": {
+			cfg:              Config{Namespace: "metering", AllNamespaces: true},
+			storageLocations: storageLocations,
+			namespace:        "team-b",
+			expected:         &teamBStorage,
+		},
+		"the default-per-namespace StorageLocation with the namespace appended to it's path": {
+			cfg:              Config{Namespace: "metering", AllNamespaces: true},
+			storageLocations: storageLocations,
+			namespace:        "team-c",
+			expected:         hiveLocation("s3a://bucket/metering/team-c"),
+		},
+		"a missing StorageLocation named by the namespace's annotation": {
+			cfg:              Config{Namespace: "metering", AllNamespaces: true},
+			storageLocations: storageLocations,
+			namespace:        "team-d",
+			expectError:      true,
+		},
+		"a missing namespace": {
+			cfg:              Config{Namespace: "metering", AllNamespaces: true},
+			storageLocations: storageLocations,
+			namespace:        "missing",
+			expectError:      true,
+		},
+		"annotations are ignored when only watching the operator's namespace": {
+			cfg:              Config{Namespace: "metering"},
+			storageLocations: storageLocations,
+			namespace:        "team-a",
+			expected:         hiveLocation("s3a://bucket/metering/team-a"),
+		},
+		"no default-per-namespace StorageLocation": {
+			cfg:              Config{Namespace: "metering", AllNamespaces: true},
+			storageLocations: storageLocations[1:],
+			namespace:        "team-c",
+		},
+		"multiple default-per-namespace StorageLocations": {
+			cfg:              Config{Namespace: "metering", AllNamespaces: true},
+			storageLocations: append([]*cbTypes.StorageLocation{newTestStorageLocation("metering", "other", perNamespace, teamStorage)}, storageLocations...),
+			namespace:        "team-c",
+			expectError:      true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			op := &Reporting{
+				logger:                logrus.New(),
+				cfg:                   test.cfg,
+				storageLocationLister: newStorageLocationLister(t, test.storageLocations...),
+			}
+			if test.cfg.multiNamespace() {
+				op.kubeClient = kubeClient
+			}
+			spec, err := op.getNamespaceDefaultStorageSpec(op.logger, test.namespace)
+			if test.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, spec)
+		})
+	}
+
+	// the default-per-namespace StorageLocation itself isn't modified
+	assert.Equal(t, "s3a://bucket/metering/", defaultStorage.Spec.Hive.TableProperties.Location)
+}
+
+func TestGetNamespaceDefaultStorageSpecPath(t *testing.T) {
+	tests := map[string]struct {
+		spec        cbTypes.StorageLocationSpec
+		expected    cbTypes.StorageLocationSpec
+		expectError bool
+	}{
+		"hive location": {
+			spec:     cbTypes.StorageLocationSpec{Hive: &cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{Location: "hdfs://namenode:9820/metering"}}},
+			expected: cbTypes.StorageLocationSpec{Hive: &cbTypes.HiveStorage{TableProperties: cbTypes.TableProperties{Location: "hdfs://namenode:9820/metering/team-a"}}},
+		},
+		"azure": {
+			spec:     cbTypes.StorageLocationSpec{Azure: &cbTypes.AzureStorage{StorageAccount: "account", Container: "metering", Path: "storage/"}},
+			expected: cbTypes.StorageLocationSpec{Azure: &cbTypes.AzureStorage{StorageAccount: "account", Container: "metering", Path: "storage/team-a"}},
+		},
+		"gcs": {
+			spec:     cbTypes.StorageLocationSpec{GCS: &cbTypes.GCSStorage{Bucket: "bucket"}},
+			expected: cbTypes.StorageLocationSpec{GCS: &cbTypes.GCSStorage{Bucket: "bucket", Path: "/team-a"}},
+		},
+		"hdfs": {
+			spec:     cbTypes.StorageLocationSpec{HDFS: &cbTypes.HDFSStorage{BasePath: "/metering"}},
+			expected: cbTypes.StorageLocationSpec{HDFS: &cbTypes.HDFSStorage{BasePath: "/metering/team-a"}},
+		},
+		"shared volume": {
+			spec:     cbTypes.StorageLocationSpec{SharedVolume: &cbTypes.SharedVolumeStorage{Path: "metering"}},
+			expected: cbTypes.StorageLocationSpec{SharedVolume: &cbTypes.SharedVolumeStorage{Path: "metering/team-a"}},
+		},
+		"s3": {
+			spec:     cbTypes.StorageLocationSpec{S3: &cbTypes.S3Storage{Bucket: "bucket", Prefix: "metering/"}},
+			expected: cbTypes.StorageLocationSpec{S3: &cbTypes.S3Storage{Bucket: "bucket", Prefix: "metering/team-a"}},
+		},
+		"hive without a location": {
+			spec:        cbTypes.StorageLocationSpec{Hive: &cbTypes.HiveStorage{}},
+			expectError: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			op := &Reporting{
+				logger: logrus.New(),
+				cfg:    Config{Namespace: "metering"},
+				storageLocationLister: newStorageLocationLister(t,
+					newTestStorageLocation("metering", "per-namespace", map[string]string{cbTypes.IsDefaultPerNamespaceStorageLocationAnnotation: "true"}, test.spec),
+				),
+			}
+			spec, err := op.getNamespaceDefaultStorageSpec(op.logger, "team-a")
+			if test.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, *spec)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/operator-framework/operator-metering/pkg/tracing"
+)
+
+const (
+	// DefaultTracingServiceName is the service name the spans exported by
+	// reporting-operator are grouped under.
+	DefaultTracingServiceName = "reporting-operator"
+
+	tracingExportTimeout = 10 * time.Second
+)
+
+// TracingConfig configures exporting traces of report generation, Prometheus
+// imports, and the queries they run.
+type TracingConfig struct {
+	// OTLPEndpoint is the URL of an OTLP/HTTP endpoint, such as an
+	// OpenTelemetry collector or Jaeger, spans are sent to. Tracing is
+	// disabled if unset.
+	OTLPEndpoint string
+	// ServiceName is the service name of the spans.
+	ServiceName string
+	// SampleRatio is the ratio of traces exported, between 0 and 1.
+	SampleRatio float64
+}
+
+func (cfg *TracingConfig) Enabled() bool {
+	return cfg.OTLPEndpoint != ""
+}
+
+func (cfg *TracingConfig) Valid() error {
+	if !cfg.Enabled() {
+		return nil
+	}
+	u, err := url.Parse(cfg.OTLPEndpoint)
+	if err != nil {
+		return fmt.Errorf("invalid tracing OTLP endpoint: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("tracing OTLP endpoint must be an http or https URL, got %q", cfg.OTLPEndpoint)
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample ratio must be between 0 and 1, got %v", cfg.SampleRatio)
+	}
+	return nil
+}
+
+// newTracer returns the tracer used to trace the operator's work, and the
+// exporter sending it's spans, which are nil if tracing isn't configured.
+func newTracer(logger log.FieldLogger, cfg TracingConfig) (*tracing.Tracer, *tracing.OTLPExporter) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	exporter := tracing.NewOTLPExporter(
+		logger.WithField("component", "tracing"),
+		&http.Client{Timeout: tracingExportTimeout},
+		cfg.OTLPEndpoint,
+		cfg.ServiceName,
+	)
+	return tracing.NewTracer(exporter, cfg.SampleRatio), exporter
+}
+
+// traceCall runs f, which doesn't take a context, such as a call to Hive's
+// TableManager, in a span which is a child of the span of ctx.
+func traceCall(ctx context.Context, name string, f func() error, attrs ...tracing.Attribute) error {
+	_, span := tracing.StartClientSpan(ctx, name, attrs...)
+	err := f()
+	span.SetError(err)
+	span.End()
+	return err
+}
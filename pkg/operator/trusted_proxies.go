@@ -0,0 +1,85 @@
+package operator
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// trustedProxies are the proxies in front of the HTTP API, such as the auth
+// proxy, whose X-Forwarded-User and X-Forwarded-For headers are trusted.
+// The headers of requests made by anyone else are ignored, since any client
+// can set them.
+type trustedProxies []*net.IPNet
+
+// parseTrustedProxies parses each of proxies as an IP address or CIDR
+// range.
+func parseTrustedProxies(proxies []string) (trustedProxies, error) {
+	var trusted trustedProxies
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("%q is not an IP address or CIDR range", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			trusted = append(trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR range", proxy)
+		}
+		trusted = append(trusted, ipNet)
+	}
+	return trusted, nil
+}
+
+// remoteHost returns the address r was made from, without it's port.
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// trusts returns true if r was made by one of the trusted proxies.
+func (p trustedProxies) trusts(r *http.Request) bool {
+	ip := net.ParseIP(remoteHost(r))
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range p {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedUser returns the user the proxy making r says it authenticated,
+// or an empty string if r wasn't made by a trusted proxy.
+func (p trustedProxies) forwardedUser(r *http.Request) string {
+	if !p.trusts(r) {
+		return ""
+	}
+	return r.Header.Get("X-Forwarded-User")
+}
+
+// forwardedFor returns the addresses the proxy making r says the request
+// was forwarded for, or an empty string if r wasn't made by a trusted proxy.
+func (p trustedProxies) forwardedFor(r *http.Request) string {
+	if !p.trusts(r) {
+		return ""
+	}
+	return r.Header.Get("X-Forwarded-For")
+}
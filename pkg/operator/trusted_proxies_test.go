@@ -0,0 +1,48 @@
+package operator
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedProxies(t *testing.T) {
+	proxies, err := parseTrustedProxies([]string{"127.0.0.1", " ::1", "", "10.128.0.0/14"})
+	require.NoError(t, err)
+
+	for remoteAddr, trusted := range map[string]bool{
+		"127.0.0.1:8080":   true,
+		"[::1]:8080":       true,
+		"10.129.2.3:43210": true,
+		"10.132.0.1:43210": false,
+		"192.0.2.1:1234":   false,
+		"not an address":   false,
+	} {
+		r := httptest.NewRequest("GET", "/api/v1/reports/get", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("X-Forwarded-User", "alice")
+		r.Header.Set("X-Forwarded-For", "10.0.0.1")
+		assert.Equal(t, trusted, proxies.trusts(r), "unexpected trust for %s", remoteAddr)
+		if trusted {
+			assert.Equal(t, "alice", proxies.forwardedUser(r))
+			assert.Equal(t, "10.0.0.1", proxies.forwardedFor(r))
+		} else {
+			assert.Empty(t, proxies.forwardedUser(r))
+			assert.Empty(t, proxies.forwardedFor(r))
+		}
+	}
+
+	// no proxies are trusted by default
+	var none trustedProxies
+	r := httptest.NewRequest("GET", "/api/v1/reports/get", nil)
+	r.RemoteAddr = "127.0.0.1:8080"
+	r.Header.Set("X-Forwarded-User", "alice")
+	assert.Empty(t, none.forwardedUser(r))
+
+	for _, invalid := range []string{"localhost", "10.0.0.0/33"} {
+		_, err := parseTrustedProxies([]string{invalid})
+		assert.Error(t, err, "expected %q to be rejected", invalid)
+	}
+}
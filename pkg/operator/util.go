@@ -7,10 +7,21 @@ import (
 	"net/http"
 
 	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
 )
 
 const logIdentifierLength = 10
 
+// The fields identifying the controller, and the resource it's processing,
+// in the logs of the workers, so they can be indexed and correlated.
+const (
+	logFieldController = "controller"
+	logFieldResource   = "resource"
+	logFieldNamespace  = "namespace"
+	logFieldName       = "name"
+)
+
 func randomString(rand *rand.Rand, size int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	b := make([]byte, size)
@@ -26,6 +37,26 @@ func newLogIdentifier(r *rand.Rand) logrus.Fields {
 	}
 }
 
+// newResourceLogger returns a logger including the fields identifying the
+// resource of the kind with key, which is of the form namespace/name.
+func newResourceLogger(logger logrus.FieldLogger, kind, key string) logrus.FieldLogger {
+	namespace, name, _ := cache.SplitMetaNamespaceKey(key)
+	return logger.WithFields(logrus.Fields{
+		logFieldResource:  kind,
+		logFieldNamespace: namespace,
+		logFieldName:      name,
+	})
+}
+
+// newObjectLogger is like newResourceLogger, for the object of the kind.
+func newObjectLogger(logger logrus.FieldLogger, kind string, obj metav1.Object) logrus.FieldLogger {
+	return logger.WithFields(logrus.Fields{
+		logFieldResource:  kind,
+		logFieldNamespace: obj.GetNamespace(),
+		logFieldName:      obj.GetName(),
+	})
+}
+
 func newRequestLogger(logger logrus.FieldLogger, r *http.Request, rand *rand.Rand) logrus.FieldLogger {
 	return logger.WithFields(logrus.Fields{
 		"method": r.Method,
@@ -0,0 +1,49 @@
+package operator
+
+import (
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/operator-framework/operator-metering/pkg/admission"
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha2"
+	"github.com/operator-framework/operator-metering/pkg/conversion"
+)
+
+const (
+	// WebhookAddress is the address the admission webhooks are served on
+	// when they're enabled.
+	WebhookAddress = ":8443"
+
+	ValidateReportWebhookEndpoint         = "/validate/reports"
+	DefaultScheduledReportWebhookEndpoint = "/default/scheduledreports"
+	// ConvertWebhookEndpoint converts Reports and ScheduledReports between
+	// v1alpha1 and v1alpha2.
+	ConvertWebhookEndpoint = "/convert"
+)
+
+// conversionScheme contains the conversion functions between the versions
+// of the metering API.
+var conversionScheme = runtime.NewScheme()
+
+func init() {
+	schemeBuilder := runtime.NewSchemeBuilder(cbTypes.AddToScheme, v1alpha2.AddToScheme)
+	if err := schemeBuilder.AddToScheme(conversionScheme); err != nil {
+		panic(err)
+	}
+}
+
+// newWebhookServer returns a server for the admission and conversion
+// webhooks. The API
+// server requires webhooks to be served using TLS.
+func (op *Reporting) newWebhookServer() *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc(ValidateReportWebhookEndpoint, admission.Serve(op.validateReportAdmission))
+	mux.HandleFunc(DefaultScheduledReportWebhookEndpoint, admission.Serve(op.defaultScheduledReportAdmission))
+	mux.HandleFunc(ConvertWebhookEndpoint, conversion.Serve(conversionScheme))
+	return &http.Server{
+		Addr:    WebhookAddress,
+		Handler: mux,
+	}
+}
@@ -0,0 +1,82 @@
+package presto
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	prestoclient "github.com/prestodb/presto-go-client/presto"
+)
+
+// Dialect is the protocol spoken by the Presto cluster reporting-operator
+// connects to.
+type Dialect string
+
+const (
+	// DialectPresto is the protocol used by PrestoDB and PrestoSQL, which
+	// uses X-Presto-* headers.
+	DialectPresto Dialect = "presto"
+	// DialectTrino is the protocol used by Trino, which renamed the
+	// X-Presto-* headers to X-Trino-*.
+	DialectTrino Dialect = "trino"
+
+	// trinoCustomClient is the name the Trino HTTP client is registered with
+	// the Presto driver as.
+	trinoCustomClient = "trino"
+
+	prestoHeaderPrefix = "X-Presto-"
+	trinoHeaderPrefix  = "X-Trino-"
+)
+
+func init() {
+	// RegisterCustomClient only returns an error if the name is reserved
+	if err := prestoclient.RegisterCustomClient(trinoCustomClient, &http.Client{
+		Transport: &trinoTransport{base: http.DefaultTransport},
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// ConnectionString returns the DSN used to connect to the Presto cluster at
+// host as user, using the protocol of the dialect.
+func ConnectionString(dialect Dialect, user, host string) (string, error) {
+	query := url.Values{
+		"catalog": []string{"hive"},
+		"schema":  []string{"default"},
+	}
+	switch dialect {
+	case DialectPresto, "":
+	case DialectTrino:
+		query.Set("custom_client", trinoCustomClient)
+	default:
+		return "", fmt.Errorf("invalid Presto dialect %q, must be one of %s or %s", dialect, DialectPresto, DialectTrino)
+	}
+	dsn := url.URL{
+		Scheme:   "http",
+		User:     url.User(user),
+		Host:     host,
+		RawQuery: query.Encode(),
+	}
+	return dsn.String(), nil
+}
+
+// trinoTransport translates the X-Presto-* headers sent by the Presto driver
+// into the X-Trino-* headers Trino expects.
+type trinoTransport struct {
+	base http.RoundTripper
+}
+
+func (t *trinoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the request, so modify a copy.
+	trinoReq := new(http.Request)
+	*trinoReq = *req
+	trinoReq.Header = make(http.Header, len(req.Header))
+	for name, values := range req.Header {
+		if strings.HasPrefix(name, prestoHeaderPrefix) {
+			name = trinoHeaderPrefix + strings.TrimPrefix(name, prestoHeaderPrefix)
+		}
+		trinoReq.Header[name] = values
+	}
+	return t.base.RoundTrip(trinoReq)
+}
@@ -0,0 +1,48 @@
+package presto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionString(t *testing.T) {
+	connStr, err := ConnectionString(DialectPresto, "reporting-operator", "presto:8080")
+	require.NoError(t, err)
+	assert.Equal(t, "http://reporting-operator@presto:8080?catalog=hive&schema=default", connStr)
+
+	connStr, err = ConnectionString(DialectTrino, "reporting-operator", "trino:8080")
+	require.NoError(t, err)
+	assert.Equal(t, "http://reporting-operator@trino:8080?catalog=hive&custom_client=trino&schema=default", connStr)
+
+	_, err = ConnectionString("prestgo", "reporting-operator", "presto:8080")
+	assert.Error(t, err)
+}
+
+func TestTrinoTransport(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest("POST", server.URL+"/v1/statement", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Presto-User", "reporting-operator")
+	req.Header.Set("X-Presto-Session", "query_max_run_time=2h")
+	req.Header.Set("Content-Type", "text/plain")
+
+	transport := &trinoTransport{base: http.DefaultTransport}
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "reporting-operator", received.Get("X-Trino-User"))
+	assert.Equal(t, "query_max_run_time=2h", received.Get("X-Trino-Session"))
+	assert.Equal(t, "text/plain", received.Get("Content-Type"))
+	assert.Empty(t, received.Get("X-Presto-User"))
+	assert.Equal(t, "reporting-operator", req.Header.Get("X-Presto-User"), "expected the original request to be unmodified")
+}
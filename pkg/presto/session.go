@@ -0,0 +1,244 @@
+package presto
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+)
+
+// maxSessionDBs is the number of connection pools a SessionQueryer keeps open
+// for distinct Sessions. Once reached, the least recently used pool is
+// closed when another is opened.
+const maxSessionDBs = 16
+
+var sessionPropertyNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
+// Session configures the Presto session a query is run in.
+type Session struct {
+	// Properties are the session properties set for the query, such as
+	// query_max_run_time. Catalog session properties are prefixed with the
+	// catalog's name, for example hive.bucket_execution_enabled.
+	Properties map[string]string
+	// Source identifies the client to Presto, and can be used by resource
+	// group selectors to choose the resource group the query runs in.
+	Source string
+}
+
+// Validate returns an error if the session can't be sent to Presto.
+func (s Session) Validate() error {
+	for name, value := range s.Properties {
+		if !sessionPropertyNameRegex.MatchString(name) {
+			return fmt.Errorf("invalid session property name %q", name)
+		}
+		// the properties are sent as a comma separated list of name=value
+		// pairs
+		if value == "" || strings.ContainsAny(value, ",=") {
+			return fmt.Errorf("invalid value %q for session property %s, values cannot be empty or contain ',' or '='", value, name)
+		}
+	}
+	return nil
+}
+
+func (s Session) isEmpty() bool {
+	return len(s.Properties) == 0 && s.Source == ""
+}
+
+type sessionContextKey struct{}
+
+// WithSession returns a context causing queries run with it by a
+// SessionQueryer to use the session.
+func WithSession(ctx context.Context, session Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, session)
+}
+
+func sessionFromContext(ctx context.Context) (Session, bool) {
+	session, ok := ctx.Value(sessionContextKey{}).(Session)
+	return session, ok && !session.isEmpty()
+}
+
+// SessionQueryer implements db.Queryer, running each query in the Session of
+// it's context, if it has one.
+//
+// The Presto driver configures the session of every query made with a
+// connection using it's DSN, so a separate connection pool is opened for
+// each distinct Session, up to maxSessionDBs.
+type SessionQueryer struct {
+	db      *sql.DB
+	connStr string
+
+	mu sync.Mutex
+	// sessionDBs contains a *sessionDB for each session DSN.
+	sessionDBs *simplelru.LRU
+	// evicted are the pools removed from sessionDBs which are closed once
+	// no queries are being started with them.
+	evicted []*sessionDB
+}
+
+type sessionDB struct {
+	db *sql.DB
+	// starting is the number of queries being started with db.
+	starting int
+}
+
+// NewSessionQueryer returns a SessionQueryer running queries without a
+// Session using db, and queries with a Session using a new connection to
+// connStr, which must be the DSN db was opened with.
+func NewSessionQueryer(db *sql.DB, connStr string) *SessionQueryer {
+	q := &SessionQueryer{
+		db:      db,
+		connStr: connStr,
+	}
+	q.sessionDBs, _ = simplelru.NewLRU(maxSessionDBs, func(_, value interface{}) {
+		q.evicted = append(q.evicted, value.(*sessionDB))
+	})
+	return q
+}
+
+func (q *SessionQueryer) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return q.db.Query(query, args...)
+}
+
+func (q *SessionQueryer) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	session, ok := sessionFromContext(ctx)
+	if !ok {
+		return q.db.QueryContext(ctx, query, args...)
+	}
+	sdb, err := q.startSessionQuery(session)
+	if err != nil {
+		return nil, err
+	}
+	// closing a pool doesn't affect the rows of queries already started
+	// with it, so it only has to stay open until the query has started
+	defer q.finishSessionQuery(sdb)
+	return sdb.db.QueryContext(ctx, query, args...)
+}
+
+func (q *SessionQueryer) Close() error {
+	q.mu.Lock()
+	q.sessionDBs.Purge()
+	toClose := q.evicted
+	q.evicted = nil
+	q.mu.Unlock()
+	closeSessionDBs(toClose)
+	return q.db.Close()
+}
+
+// startSessionQuery returns the pool used to run queries in the session,
+// opening it if necessary. finishSessionQuery must be called once the query
+// has started.
+func (q *SessionQueryer) startSessionQuery(session Session) (*sessionDB, error) {
+	if err := session.Validate(); err != nil {
+		return nil, err
+	}
+	dsn, err := sessionDSN(q.connStr, session)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	sdb, err := q.sessionDB(dsn)
+	if err == nil {
+		sdb.starting++
+	}
+	toClose := q.removeUnusedEvicted()
+	q.mu.Unlock()
+	closeSessionDBs(toClose)
+	return sdb, err
+}
+
+func (q *SessionQueryer) finishSessionQuery(sdb *sessionDB) {
+	q.mu.Lock()
+	sdb.starting--
+	toClose := q.removeUnusedEvicted()
+	q.mu.Unlock()
+	closeSessionDBs(toClose)
+}
+
+// sessionDB returns the pool for the session DSN, opening it if necessary.
+// q.mu must be held.
+func (q *SessionQueryer) sessionDB(dsn string) (*sessionDB, error) {
+	if value, ok := q.sessionDBs.Get(dsn); ok {
+		return value.(*sessionDB), nil
+	}
+	db, err := sql.Open("presto", dsn)
+	if err != nil {
+		return nil, err
+	}
+	sdb := &sessionDB{db: db}
+	q.sessionDBs.Add(dsn, sdb)
+	return sdb, nil
+}
+
+// removeUnusedEvicted removes the evicted pools no queries are being started
+// with from q.evicted and returns them, so they can be closed after
+// releasing q.mu. q.mu must be held.
+func (q *SessionQueryer) removeUnusedEvicted() []*sessionDB {
+	var unused []*sessionDB
+	remaining := q.evicted[:0]
+	for _, sdb := range q.evicted {
+		if sdb.starting == 0 {
+			unused = append(unused, sdb)
+		} else {
+			remaining = append(remaining, sdb)
+		}
+	}
+	q.evicted = remaining
+	return unused
+}
+
+func closeSessionDBs(sdbs []*sessionDB) {
+	for _, sdb := range sdbs {
+		sdb.db.Close()
+	}
+}
+
+// SessionConnectionString returns connStr configured to run every query in
+// the session. Session properties already set by connStr are kept, unless
+// the session sets them.
+func SessionConnectionString(connStr string, session Session) (string, error) {
+	if err := session.Validate(); err != nil {
+		return "", err
+	}
+	return sessionDSN(connStr, session)
+}
+
+// sessionDSN returns connStr with the session's properties and source set.
+// The properties are sorted so the same session always has the same DSN.
+func sessionDSN(connStr string, session Session) (string, error) {
+	dsn, err := url.Parse(connStr)
+	if err != nil {
+		return "", fmt.Errorf("invalid Presto connection string: %v", err)
+	}
+	query := dsn.Query()
+	if len(session.Properties) != 0 {
+		merged := make(map[string]string)
+		if existing := query.Get("session_properties"); existing != "" {
+			for _, property := range strings.Split(existing, ",") {
+				if kv := strings.SplitN(property, "=", 2); len(kv) == 2 {
+					merged[kv[0]] = kv[1]
+				}
+			}
+		}
+		for name, value := range session.Properties {
+			merged[name] = value
+		}
+		properties := make([]string, 0, len(merged))
+		for name, value := range merged {
+			properties = append(properties, name+"="+value)
+		}
+		sort.Strings(properties)
+		query.Set("session_properties", strings.Join(properties, ","))
+	}
+	if session.Source != "" {
+		query.Set("source", session.Source)
+	}
+	dsn.RawQuery = query.Encode()
+	return dsn.String(), nil
+}
@@ -0,0 +1,93 @@
+package presto
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionDSN(t *testing.T) {
+	const connStr = "http://reporting-operator@presto:8080?catalog=hive&schema=default"
+	dsn, err := sessionDSN(connStr, Session{
+		Properties: map[string]string{
+			"query_max_run_time":            "2h",
+			"hive.bucket_execution_enabled": "false",
+			"query_max_execution_time":      "1h",
+		},
+		Source: "metering-large-reports",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "http://reporting-operator@presto:8080?catalog=hive&schema=default&session_properties=hive.bucket_execution_enabled%3Dfalse%2Cquery_max_execution_time%3D1h%2Cquery_max_run_time%3D2h&source=metering-large-reports", dsn)
+}
+
+func TestSessionDSNMergesProperties(t *testing.T) {
+	connStr, err := SessionConnectionString("http://reporting-operator@presto:8080?catalog=hive&schema=default", Session{
+		Properties: map[string]string{"hive.compression_codec": "ZSTD", "query_max_run_time": "1h"},
+	})
+	require.NoError(t, err)
+	dsn, err := sessionDSN(connStr, Session{Properties: map[string]string{"query_max_run_time": "2h"}})
+	require.NoError(t, err)
+	assert.Equal(t, "http://reporting-operator@presto:8080?catalog=hive&schema=default&session_properties=hive.compression_codec%3DZSTD%2Cquery_max_run_time%3D2h", dsn)
+}
+
+func TestSessionValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		session Session
+		valid   bool
+	}{
+		{name: "system property", session: Session{Properties: map[string]string{"query_max_run_time": "2h"}}, valid: true},
+		{name: "catalog property", session: Session{Properties: map[string]string{"hive.bucket_execution_enabled": "false"}}, valid: true},
+		{name: "source only", session: Session{Source: "metering"}, valid: true},
+		{name: "invalid name", session: Session{Properties: map[string]string{"query max run time": "2h"}}},
+		{name: "value containing a comma", session: Session{Properties: map[string]string{"query_max_run_time": "2h,query_priority=1"}}},
+		{name: "empty value", session: Session{Properties: map[string]string{"query_max_run_time": ""}}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.session.Validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestSessionQueryerClosesEvictedDBs(t *testing.T) {
+	db, err := sql.Open("presto", "http://reporting-operator@presto:8080")
+	require.NoError(t, err)
+	q := NewSessionQueryer(db, "http://reporting-operator@presto:8080")
+	defer q.Close()
+	isClosed := func(sdb *sessionDB) bool {
+		err := sdb.db.Ping()
+		return err != nil && err.Error() == "sql: database is closed"
+	}
+
+	// the first pool is still starting a query when it's evicted, so it's
+	// only closed once the query has started
+	first, err := q.startSessionQuery(Session{Source: "source-0"})
+	require.NoError(t, err)
+	var sdbs []*sessionDB
+	for i := 1; i <= maxSessionDBs; i++ {
+		sdb, err := q.startSessionQuery(Session{Source: fmt.Sprintf("source-%d", i)})
+		require.NoError(t, err)
+		q.finishSessionQuery(sdb)
+		sdbs = append(sdbs, sdb)
+	}
+	assert.Equal(t, maxSessionDBs, q.sessionDBs.Len())
+	assert.False(t, isClosed(first), "expected the evicted pool to stay open while a query is being started with it")
+	q.finishSessionQuery(first)
+	assert.True(t, isClosed(first), "expected the evicted pool to be closed")
+
+	_, err = q.startSessionQuery(Session{Source: "source-0"})
+	require.NoError(t, err)
+	assert.Equal(t, maxSessionDBs, q.sessionDBs.Len())
+	assert.True(t, isClosed(sdbs[0]), "expected the least recently used pool to be closed")
+	assert.False(t, isClosed(sdbs[1]))
+}
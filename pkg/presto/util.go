@@ -1,6 +1,7 @@
 package presto
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -14,27 +15,27 @@ const (
 	TimestampFormat = "2006-01-02 15:04:05.000"
 )
 
-func DeleteFrom(queryer db.Queryer, tableName string) error {
-	_, err := queryer.Query(fmt.Sprintf("DELETE FROM %s", tableName))
+func DeleteFrom(ctx context.Context, queryer db.Queryer, tableName string) error {
+	_, err := queryer.QueryContext(ctx, fmt.Sprintf("DELETE FROM %s", tableName))
 	return err
 }
 
-func InsertInto(queryer db.Queryer, tableName, query string) error {
-	return execQuery(queryer, FormatInsertQuery(tableName, query))
+func InsertInto(ctx context.Context, queryer db.Queryer, tableName, query string) error {
+	return execQuery(ctx, queryer, FormatInsertQuery(tableName, query))
 }
 
-func GetRows(queryer db.Queryer, tableName string, columns []Column) ([]Row, error) {
-	return ExecuteSelect(queryer, GenerateGetRowsSQL(tableName, columns))
+func GetRows(ctx context.Context, queryer db.Queryer, tableName string, columns []Column) ([]Row, error) {
+	return ExecuteSelect(ctx, queryer, GenerateGetRowsSQL(tableName, columns))
 }
 
-func CreateView(queryer db.Queryer, viewName string, query string, replace bool) error {
+func CreateView(ctx context.Context, queryer db.Queryer, viewName string, query string, replace bool) error {
 	fullQuery := "CREATE"
 	if replace {
 		fullQuery += " OR REPLACE"
 	}
 	fullQuery += " VIEW %s AS %s"
 	finalQuery := fmt.Sprintf(fullQuery, viewName, query)
-	_, err := queryer.Query(finalQuery)
+	_, err := queryer.QueryContext(ctx, finalQuery)
 	return err
 }
 
@@ -95,15 +96,30 @@ type TablePartition struct {
 
 // ExecuteSelectQuery performs the query on the table target. It's expected
 // target has the correct schema.
-func ExecuteSelect(queryer db.Queryer, query string) ([]Row, error) {
-	rows, err := queryer.Query(query)
+func ExecuteSelect(ctx context.Context, queryer db.Queryer, query string) ([]Row, error) {
+	_, results, err := ExecuteSelectWithColumns(ctx, queryer, query)
+	return results, err
+}
+
+// ExecuteSelectWithColumns performs the query like ExecuteSelect, but also
+// returns the name and Presto type of each column in the results.
+func ExecuteSelectWithColumns(ctx context.Context, queryer db.Queryer, query string) ([]Column, []Row, error) {
+	rows, err := queryer.QueryContext(ctx, query)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 	cols, err := rows.Columns()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, nil, err
+	}
+	resultColumns := make([]Column, len(colTypes))
+	for i, colType := range colTypes {
+		resultColumns[i] = Column{Name: colType.Name(), Type: colType.DatabaseTypeName()}
 	}
 
 	var results []Row
@@ -118,7 +134,7 @@ func ExecuteSelect(queryer db.Queryer, query string) ([]Row, error) {
 
 		// Scan the result into the column pointers...
 		if err := rows.Scan(columnPointers...); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// Create our map, and retrieve the value for each column from the pointers slice,
@@ -131,14 +147,41 @@ func ExecuteSelect(queryer db.Queryer, query string) ([]Row, error) {
 		results = append(results, Row(m))
 	}
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return results, nil
+	return resultColumns, results, nil
+}
+
+// Explain returns the plan Presto would use to execute query, one line per
+// row of the EXPLAIN output. If analyze is true, the query is executed using
+// EXPLAIN ANALYZE, and the plan includes the cost of each stage.
+func Explain(ctx context.Context, queryer db.Queryer, query string, analyze bool) (string, error) {
+	explain := "EXPLAIN"
+	if analyze {
+		explain = "EXPLAIN ANALYZE"
+	}
+	rows, err := queryer.QueryContext(ctx, fmt.Sprintf("%s %s", explain, query))
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return "", err
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("presto SQL error: %v", err)
+	}
+	return strings.Join(lines, "\n"), nil
 }
 
-func execQuery(queryer db.Queryer, query string) error {
-	rows, err := queryer.Query(query)
+func execQuery(ctx context.Context, queryer db.Queryer, query string) error {
+	rows, err := queryer.QueryContext(ctx, query)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,45 @@
+package remotewrite
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// DefaultMaxRequestSize is the default limit on the size of a decompressed
+// WriteRequest.
+const DefaultMaxRequestSize = 32 * 1024 * 1024
+
+// DecodeWriteRequest reads a snappy compressed, protobuf encoded
+// WriteRequest, as sent by Prometheus' remote-write client.
+func DecodeWriteRequest(r io.Reader, maxRequestSize int) (*WriteRequest, error) {
+	// snappy never makes data meaningfully larger, so a compressed body
+	// over the limit would decompress to more than the limit as well.
+	compressed, err := ioutil.ReadAll(io.LimitReader(r, int64(maxRequestSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(compressed) > maxRequestSize {
+		return nil, fmt.Errorf("request exceeds limit of %d bytes", maxRequestSize)
+	}
+	buf, err := decodeSnappy(compressed, maxRequestSize)
+	if err != nil {
+		return nil, err
+	}
+	var req WriteRequest
+	if err := proto.Unmarshal(buf, &req); err != nil {
+		return nil, fmt.Errorf("unable to decode WriteRequest: %v", err)
+	}
+	return &req, nil
+}
+
+// LabelsMap returns the labels of the series as a map.
+func (m *TimeSeries) LabelsMap() map[string]string {
+	labels := make(map[string]string, len(m.Labels))
+	for _, label := range m.Labels {
+		labels[label.Name] = label.Value
+	}
+	return labels
+}
@@ -0,0 +1,107 @@
+package remotewrite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeSnappyLiteral encodes src as a snappy block containing a single
+// literal.
+func encodeSnappyLiteral(src []byte) []byte {
+	var buf bytes.Buffer
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	buf.Write(lenBuf[:binary.PutUvarint(lenBuf, uint64(len(src)))])
+	n := len(src) - 1
+	switch {
+	case n < 60:
+		buf.WriteByte(byte(n) << 2)
+	default:
+		buf.WriteByte(62 << 2)
+		buf.Write([]byte{byte(n), byte(n >> 8), byte(n >> 16)})
+	}
+	buf.Write(src)
+	return buf.Bytes()
+}
+
+func TestDecodeSnappy(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       []byte
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "literal",
+			src:      []byte{0x03, 0x08, 'a', 'b', 'c'},
+			expected: "abc",
+		},
+		{
+			name: "overlapping copy",
+			// "abc" followed by a copy of 9 bytes from 3 bytes back
+			src:      []byte{0x0c, 0x08, 'a', 'b', 'c', 0x15, 0x03},
+			expected: "abcabcabcabc",
+		},
+		{
+			name:      "copy before the start of the output",
+			src:       []byte{0x0c, 0x08, 'a', 'b', 'c', 0x15, 0x04},
+			expectErr: true,
+		},
+		{
+			name:      "truncated literal",
+			src:       []byte{0x03, 0x08, 'a', 'b'},
+			expectErr: true,
+		},
+		{
+			name:      "decoded length over the limit",
+			src:       []byte{0xff, 0xff, 0x7f, 0x00, 'a'},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := decodeSnappy(tt.src, 1024)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(decoded))
+		})
+	}
+}
+
+func TestDecodeWriteRequest(t *testing.T) {
+	expected := &WriteRequest{
+		Timeseries: []*TimeSeries{
+			{
+				Labels: []*Label{
+					{Name: "__name__", Value: "kube_pod_container_resource_requests_cpu_cores"},
+					{Name: "namespace", Value: "team-a"},
+				},
+				Samples: []*Sample{
+					{Value: 0.5, Timestamp: 1546300800000},
+					{Value: 0.75, Timestamp: 1546300860000},
+				},
+			},
+		},
+	}
+	buf, err := proto.Marshal(expected)
+	require.NoError(t, err)
+
+	req, err := DecodeWriteRequest(bytes.NewReader(encodeSnappyLiteral(buf)), DefaultMaxRequestSize)
+	require.NoError(t, err)
+	assert.Equal(t, expected, req)
+	assert.Equal(t, map[string]string{
+		"__name__":  "kube_pod_container_resource_requests_cpu_cores",
+		"namespace": "team-a",
+	}, req.Timeseries[0].LabelsMap())
+
+	_, err = DecodeWriteRequest(bytes.NewReader(encodeSnappyLiteral(buf)), 10)
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package remotewrite
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var errCorruptSnappy = errors.New("snappy: corrupt input")
+
+// decodeSnappy decodes a snappy block, which is how Prometheus compresses
+// remote-write requests. It returns an error if the decoded block would be
+// larger than maxDecodedLen.
+func decodeSnappy(src []byte, maxDecodedLen int) ([]byte, error) {
+	decodedLen, n := binary.Uvarint(src)
+	if n <= 0 {
+		return nil, errCorruptSnappy
+	}
+	if decodedLen > uint64(maxDecodedLen) {
+		return nil, fmt.Errorf("snappy: decoded length %d exceeds limit of %d bytes", decodedLen, maxDecodedLen)
+	}
+	src = src[n:]
+	dst := make([]byte, 0, decodedLen)
+
+	for len(src) > 0 {
+		tag := src[0]
+		var length, offset int
+		switch tag & 0x03 {
+		case 0x00: // literal
+			length = int(tag >> 2)
+			src = src[1:]
+			if length >= 60 {
+				// lengths of 60 to 63 mean the length is stored in the next
+				// 1 to 4 bytes
+				numBytes := length - 59
+				if len(src) < numBytes {
+					return nil, errCorruptSnappy
+				}
+				length = 0
+				for i := 0; i < numBytes; i++ {
+					length |= int(src[i]) << (8 * uint(i))
+				}
+				src = src[numBytes:]
+			}
+			length++
+			if length <= 0 || length > len(src) || len(dst)+length > int(decodedLen) {
+				return nil, errCorruptSnappy
+			}
+			dst = append(dst, src[:length]...)
+			src = src[length:]
+			continue
+		case 0x01: // copy with a 1 byte offset
+			if len(src) < 2 {
+				return nil, errCorruptSnappy
+			}
+			length = 4 + int(tag>>2)&0x07
+			offset = int(tag&0xe0)<<3 | int(src[1])
+			src = src[2:]
+		case 0x02: // copy with a 2 byte offset
+			if len(src) < 3 {
+				return nil, errCorruptSnappy
+			}
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint16(src[1:3]))
+			src = src[3:]
+		case 0x03: // copy with a 4 byte offset
+			if len(src) < 5 {
+				return nil, errCorruptSnappy
+			}
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint32(src[1:5]))
+			src = src[5:]
+		}
+		if offset <= 0 || offset > len(dst) || len(dst)+length > int(decodedLen) {
+			return nil, errCorruptSnappy
+		}
+		// copies may overlap the bytes being written, so copy a byte at a
+		// time
+		start := len(dst) - offset
+		for i := 0; i < length; i++ {
+			dst = append(dst, dst[start+i])
+		}
+	}
+	if len(dst) != int(decodedLen) {
+		return nil, errCorruptSnappy
+	}
+	return dst, nil
+}
@@ -0,0 +1,179 @@
+// Package testharness allows ReportDataSources and ReportGenerationQueries
+// to be tested without a cluster. It runs the same code reporting-operator
+// uses to import Prometheus metrics and render ReportGenerationQueries
+// in-process, against a FakePrometheus, an in-memory MetricsStore and a fake
+// Kubernetes API seeded with metering resources.
+//
+// Executing rendered queries requires Presto, so the harness only renders
+// them; the rendered SQL can be run against a Presto instance created by the
+// test.
+package testharness
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/fake"
+	"github.com/operator-framework/operator-metering/pkg/operator"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/operator/reporting"
+	"github.com/operator-framework/operator-metering/pkg/operator/reportingutil"
+)
+
+// Harness contains the fake dependencies of reporting-operator.
+type Harness struct {
+	// Namespace is the namespace resources are looked up in.
+	Namespace string
+	// MeteringClient is a fake metering API, seeded with the objects passed to
+	// New. Objects can be added or modified during a test.
+	MeteringClient *fake.Clientset
+	Prometheus     *FakePrometheus
+	Metrics        *MetricsStore
+
+	// StepSize and ChunkSize are used when importing metrics for
+	// ReportDataSources which don't set them in spec.promsum.queryConfig.
+	// They default to reporting-operator's defaults.
+	StepSize  time.Duration
+	ChunkSize time.Duration
+
+	Logger logrus.FieldLogger
+}
+
+// New returns a Harness for the namespace, with the metering API seeded with
+// objects.
+func New(namespace string, objects ...runtime.Object) *Harness {
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	return &Harness{
+		Namespace:      namespace,
+		MeteringClient: fake.NewSimpleClientset(objects...),
+		Prometheus:     NewFakePrometheus(),
+		Metrics:        NewMetricsStore(),
+		StepSize:       operator.DefaultPrometheusQueryStepSize,
+		ChunkSize:      operator.DefaultPrometheusQueryChunkSize,
+		Logger:         logger,
+	}
+}
+
+// ImportReportDataSource imports metrics for a Prometheus ReportDataSource
+// between start and end, as reporting-operator would, by executing the
+// query of it's ReportPrometheusQuery against h.Prometheus and storing the
+// results in h.Metrics. If the ReportDataSource doesn't have a table name in
+// it's status yet, it's set, marking the ReportDataSource as initialized.
+func (h *Harness) ImportReportDataSource(name string, start, end time.Time) (*prestostore.PrometheusImportResults, error) {
+	dataSources := h.MeteringClient.MeteringV1alpha1().ReportDataSources(h.Namespace)
+	dataSource, err := dataSources.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if dataSource.Spec.Promsum == nil {
+		return nil, fmt.Errorf("%s is not a Promsum ReportDataSource", dataSource.Name)
+	}
+	promQuery, err := h.MeteringClient.MeteringV1alpha1().ReportPrometheusQueries(h.Namespace).Get(dataSource.Spec.Promsum.Query, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get ReportPrometheusQuery %s for ReportDataSource %s, %s", dataSource.Spec.Promsum.Query, dataSource.Name, err)
+	}
+
+	if dataSource.Status.TableName == "" {
+		dataSource.Status.TableName = reportingutil.DataSourceTableName("", dataSource.Name)
+		dataSource, err = dataSources.Update(dataSource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	stepSize, chunkSize := h.StepSize, h.ChunkSize
+	if queryConf := dataSource.Spec.Promsum.QueryConfig; queryConf != nil {
+		if queryConf.StepSize != nil {
+			stepSize = queryConf.StepSize.Duration
+		}
+		if queryConf.ChunkSize != nil {
+			chunkSize = queryConf.ChunkSize.Duration
+		}
+	}
+	cfg := prestostore.Config{
+		PrometheusQuery:       promQuery.Spec.Query,
+		PrestoTableName:       dataSource.Status.TableName,
+		ChunkSize:             chunkSize,
+		StepSize:              stepSize,
+		MaxTimeRanges:         int64(end.Sub(start)/chunkSize) + 1,
+		MaxQueryRangeDuration: end.Sub(start),
+	}
+
+	results, err := prestostore.ImportFromTimeRange(h.Logger, clock.RealClock{}, h.Prometheus, h.Metrics, newImporterMetricsCollectors(), context.Background(), start, end, cfg, true)
+	return &results, err
+}
+
+// RenderReportGenerationQuery validates the dependencies of a
+// ReportGenerationQuery and renders it for the reporting period, returning
+// the SQL reporting-operator would execute to generate a Report.
+func (h *Harness) RenderReportGenerationQuery(name string, start, end time.Time, inputs cbTypes.ReportGenerationQueryInputValues) (string, error) {
+	client := h.MeteringClient.MeteringV1alpha1()
+	genQuery, err := client.ReportGenerationQueries(h.Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	queryDependencies, err := reporting.GetAndValidateGenerationQueryDependencies(
+		reporting.NewReportGenerationQueryClientGetter(client),
+		reporting.NewReportDataSourceClientGetter(client),
+		reporting.NewReportClientGetter(client),
+		reporting.NewScheduledReportClientGetter(client),
+		genQuery,
+		nil,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to validate dependencies of ReportGenerationQuery %s: %v", name, err)
+	}
+
+	reportQueryInputs, err := reporting.ValidateReportGenerationQueryInputs(genQuery, inputs)
+	if err != nil {
+		return "", fmt.Errorf("failed to validate ReportGenerationQueryInputs: %v", err)
+	}
+	queryTemplate, err := reporting.GenerationQueryTemplate(genQuery)
+	if err != nil {
+		return "", err
+	}
+	return reporting.RenderQuery(queryTemplate, &reporting.ReportQueryTemplateContext{
+		DynamicDependentQueries: queryDependencies.DynamicReportGenerationQueries,
+		Report: &reporting.ReportTemplateInfo{
+			ReportingStart: &start,
+			ReportingEnd:   &end,
+			Inputs:         reportQueryInputs,
+		},
+	})
+}
+
+// newImporterMetricsCollectors returns collectors which aren't registered,
+// so multiple harnesses can be used at once.
+func newImporterMetricsCollectors() prestostore.ImporterMetricsCollectors {
+	newCounter := func(name string) prometheus.Counter {
+		return prometheus.NewCounter(prometheus.CounterOpts{Name: name, Help: name})
+	}
+	newHistogram := func(name string) prometheus.Histogram {
+		return prometheus.NewHistogram(prometheus.HistogramOpts{Name: name, Help: name})
+	}
+	return prestostore.ImporterMetricsCollectors{
+		TotalImportsCounter:              newCounter("total_imports"),
+		FailedImportsCounter:             newCounter("failed_imports"),
+		ImportDurationHistogram:          newHistogram("import_duration_seconds"),
+		TotalPrometheusQueriesCounter:    newCounter("total_prometheus_queries"),
+		FailedPrometheusQueriesCounter:   newCounter("failed_prometheus_queries"),
+		PrometheusQueryDurationHistogram: newHistogram("prometheus_query_duration_seconds"),
+		TotalPrestoStoresCounter:         newCounter("total_presto_stores"),
+		FailedPrestoStoresCounter:        newCounter("failed_presto_stores"),
+		PrestoStoreDurationHistogram:     newHistogram("presto_store_duration_seconds"),
+		MetricsScrapedCounter:            newCounter("metrics_scraped"),
+		MetricsImportedCounter:           newCounter("metrics_imported"),
+		ImportsRunningGauge:              prometheus.NewGauge(prometheus.GaugeOpts{Name: "imports_running", Help: "imports_running"}),
+	}
+}
@@ -0,0 +1,87 @@
+package testharness
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	prom "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const testNamespace = "metering"
+
+func TestHarness(t *testing.T) {
+	h := New(testNamespace,
+		&cbTypes.ReportPrometheusQuery{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-request-cpu-cores", Namespace: testNamespace},
+			Spec:       cbTypes.ReportPrometheusQuerySpec{Query: "kube_pod_container_resource_requests_cpu_cores"},
+		},
+		&cbTypes.ReportDataSource{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-request-cpu-cores", Namespace: testNamespace},
+			Spec: cbTypes.ReportDataSourceSpec{
+				Promsum: &cbTypes.PrometheusMetricsDataSource{Query: "pod-request-cpu-cores"},
+			},
+		},
+		&cbTypes.ReportGenerationQuery{
+			ObjectMeta: metav1.ObjectMeta{Name: "namespace-cpu-request", Namespace: testNamespace},
+			Spec: cbTypes.ReportGenerationQuerySpec{
+				DataSources: []string{"pod-request-cpu-cores"},
+				Query:       `SELECT * FROM {| dataSourceTableName "pod-request-cpu-cores" |} WHERE "timestamp" >= timestamp '{| .Report.ReportingStart | prestoTimestamp |}'`,
+			},
+		},
+	)
+	h.Prometheus.AddSeries("kube_pod_container_resource_requests_cpu_cores", map[string]string{"namespace": "team-a"}, Constant(0.5))
+
+	start := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Minute)
+	results, err := h.ImportReportDataSource("pod-request-cpu-cores", start, end)
+	require.NoError(t, err)
+	assert.Len(t, results.ProcessedTimeRanges, 2)
+
+	metrics, err := h.Metrics.GetPrometheusMetrics("datasource_pod_request_cpu_cores", start, end)
+	require.NoError(t, err)
+	require.NotEmpty(t, metrics)
+	assert.Equal(t, 0.5, metrics[0].Amount)
+	assert.Equal(t, "team-a", metrics[0].Labels["namespace"])
+
+	query, err := h.RenderReportGenerationQuery("namespace-cpu-request", start, end, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM datasource_pod_request_cpu_cores WHERE "timestamp" >= timestamp '2019-01-01 00:00:00.000'`, query)
+}
+
+func TestFakePrometheusHandler(t *testing.T) {
+	p := NewFakePrometheus()
+	p.AddSeries("up", map[string]string{"job": "test"}, Constant(1))
+	server := httptest.NewServer(p.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/query_range?query=up&start=1546300800&end=1546300920&step=60")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data struct {
+			Result model.Matrix `json:"result"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	expected, err := p.QueryRange(context.Background(), "up", prom.Range{
+		Start: time.Unix(1546300800, 0),
+		End:   time.Unix(1546300920, 0),
+		Step:  time.Minute,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expected, body.Data.Result)
+	assert.Len(t, body.Data.Result[0].Values, 3)
+}
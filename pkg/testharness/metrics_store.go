@@ -0,0 +1,82 @@
+package testharness
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+// MetricsStore is an in-memory implementation of the storage used for
+// Prometheus ReportDataSource tables, which can be seeded with metrics.
+type MetricsStore struct {
+	mu     sync.Mutex
+	tables map[string][]*prestostore.PrometheusMetric
+}
+
+var _ prestostore.PrometheusMetricsRepo = &MetricsStore{}
+
+func NewMetricsStore() *MetricsStore {
+	return &MetricsStore{
+		tables: make(map[string][]*prestostore.PrometheusMetric),
+	}
+}
+
+// Seed adds metrics to a table, as if they had previously been imported.
+func (s *MetricsStore) Seed(tableName string, metrics ...*prestostore.PrometheusMetric) {
+	s.StorePrometheusMetrics(context.Background(), tableName, prestostore.PartitionGranularityDaily, metrics)
+}
+
+// StorePrometheusMetrics stores the metrics into the table, skipping any
+// which have already been stored, like reporting-operator does. Metrics
+// aren't partitioned, so the granularity is ignored.
+func (s *MetricsStore) StorePrometheusMetrics(ctx context.Context, tableName string, granularity prestostore.PartitionGranularity, metrics []*prestostore.PrometheusMetric) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := make(map[string]struct{}, len(s.tables[tableName]))
+	for _, metric := range s.tables[tableName] {
+		stored[prestostore.PrometheusMetricKey(metric.Timestamp, metric.Labels, metric.ClusterID)] = struct{}{}
+	}
+	for _, metric := range prestostore.DeduplicatePrometheusMetrics(metrics, stored) {
+		storedMetric := *metric
+		storedMetric.Timestamp = metric.Timestamp.UTC()
+		s.tables[tableName] = append(s.tables[tableName], &storedMetric)
+	}
+	sort.SliceStable(s.tables[tableName], func(i, j int) bool {
+		return s.tables[tableName][i].Timestamp.Before(s.tables[tableName][j].Timestamp)
+	})
+	return nil
+}
+
+// GetPrometheusMetrics returns the metrics in the table with timestamps
+// between start and end, inclusive. A zero start or end is unbounded.
+func (s *MetricsStore) GetPrometheusMetrics(tableName string, start, end time.Time) ([]*prestostore.PrometheusMetric, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var metrics []*prestostore.PrometheusMetric
+	for _, metric := range s.tables[tableName] {
+		if !start.IsZero() && metric.Timestamp.Before(start) {
+			continue
+		}
+		if !end.IsZero() && metric.Timestamp.After(end) {
+			continue
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics, nil
+}
+
+// GetLastTimestampForTable returns the newest timestamp in the table, or nil
+// if it's empty.
+func (s *MetricsStore) GetLastTimestampForTable(tableName string) (*time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	metrics := s.tables[tableName]
+	if len(metrics) == 0 {
+		return nil, nil
+	}
+	last := metrics[len(metrics)-1].Timestamp
+	return &last, nil
+}
@@ -0,0 +1,223 @@
+package testharness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	prom "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// ValueFunc returns the value of a series at a point in time.
+type ValueFunc func(t time.Time) float64
+
+// Constant returns a ValueFunc which always returns value.
+func Constant(value float64) ValueFunc {
+	return func(time.Time) float64 {
+		return value
+	}
+}
+
+type fakeSeries struct {
+	labels model.Metric
+	value  ValueFunc
+}
+
+// FakePrometheus is an in-memory Prometheus which returns the series added
+// to it for a PromQL query, without evaluating the query. It implements the
+// Prometheus API client interface used by reporting-operator, and can serve
+// the Prometheus HTTP API using Handler.
+type FakePrometheus struct {
+	mu     sync.Mutex
+	series map[string][]fakeSeries
+}
+
+var _ prom.API = &FakePrometheus{}
+
+func NewFakePrometheus() *FakePrometheus {
+	return &FakePrometheus{
+		series: make(map[string][]fakeSeries),
+	}
+}
+
+// AddSeries adds a series returned when query is executed. The value of the
+// series at each step is computed by value.
+func (p *FakePrometheus) AddSeries(query string, labels map[string]string, value ValueFunc) {
+	metric := make(model.Metric, len(labels))
+	for name, labelValue := range labels {
+		metric[model.LabelName(name)] = model.LabelValue(labelValue)
+	}
+	p.mu.Lock()
+	p.series[query] = append(p.series[query], fakeSeries{labels: metric, value: value})
+	p.mu.Unlock()
+}
+
+// Query returns the value of each series added for the query at ts.
+func (p *FakePrometheus) Query(ctx context.Context, query string, ts time.Time) (model.Value, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	vector := model.Vector{}
+	for _, series := range p.series[query] {
+		vector = append(vector, &model.Sample{
+			Metric:    series.labels.Clone(),
+			Value:     model.SampleValue(series.value(ts)),
+			Timestamp: model.TimeFromUnixNano(ts.UnixNano()),
+		})
+	}
+	return vector, nil
+}
+
+// QueryRange returns the value of each series added for the query at every
+// step between the start and end of r, inclusive.
+func (p *FakePrometheus) QueryRange(ctx context.Context, query string, r prom.Range) (model.Value, error) {
+	if r.Step <= 0 {
+		return nil, fmt.Errorf("step must be greater than zero")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	matrix := model.Matrix{}
+	for _, series := range p.series[query] {
+		stream := &model.SampleStream{Metric: series.labels.Clone()}
+		for ts := r.Start; !ts.After(r.End); ts = ts.Add(r.Step) {
+			stream.Values = append(stream.Values, model.SamplePair{
+				Timestamp: model.TimeFromUnixNano(ts.UnixNano()),
+				Value:     model.SampleValue(series.value(ts)),
+			})
+		}
+		matrix = append(matrix, stream)
+	}
+	return matrix, nil
+}
+
+// LabelValues returns the values of the label across every series added.
+func (p *FakePrometheus) LabelValues(ctx context.Context, label string) (model.LabelValues, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	seen := make(map[model.LabelValue]bool)
+	values := model.LabelValues{}
+	for _, allSeries := range p.series {
+		for _, series := range allSeries {
+			value, ok := series.labels[model.LabelName(label)]
+			if ok && !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
+	}
+	sort.Sort(values)
+	return values, nil
+}
+
+type apiResponse struct {
+	Status    string      `json:"status"`
+	Data      interface{} `json:"data,omitempty"`
+	ErrorType string      `json:"errorType,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+type queryData struct {
+	ResultType model.ValueType `json:"resultType"`
+	Result     model.Value     `json:"result"`
+}
+
+// Handler returns an http.Handler serving the query and query_range
+// endpoints of the Prometheus HTTP API, allowing a reporting-operator
+// running out of process to use the FakePrometheus by setting it's
+// --prometheus-host flag to the URL of an httptest.Server.
+func (p *FakePrometheus) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/query", func(w http.ResponseWriter, r *http.Request) {
+		ts := time.Now()
+		if r.FormValue("time") != "" {
+			var err error
+			ts, err = parsePrometheusTime(r.FormValue("time"))
+			if err != nil {
+				writeAPIError(w, err)
+				return
+			}
+		}
+		value, err := p.Query(r.Context(), r.FormValue("query"), ts)
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		writeAPIResult(w, value)
+	})
+	mux.HandleFunc("/api/v1/query_range", func(w http.ResponseWriter, r *http.Request) {
+		start, err := parsePrometheusTime(r.FormValue("start"))
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		end, err := parsePrometheusTime(r.FormValue("end"))
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		step, err := parsePrometheusDuration(r.FormValue("step"))
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		value, err := p.QueryRange(r.Context(), r.FormValue("query"), prom.Range{Start: start, End: end, Step: step})
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		writeAPIResult(w, value)
+	})
+	return mux
+}
+
+func writeAPIResult(w http.ResponseWriter, value model.Value) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(apiResponse{
+		Status: "success",
+		Data:   queryData{ResultType: value.Type(), Result: value},
+	})
+}
+
+func writeAPIError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(apiResponse{
+		Status:    "error",
+		ErrorType: "bad_data",
+		Error:     err.Error(),
+	})
+}
+
+// parsePrometheusTime parses a timestamp in either of the formats accepted
+// by the Prometheus HTTP API: a unix timestamp in seconds, or RFC3339.
+func parsePrometheusTime(s string) (time.Time, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		whole, frac := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(frac*float64(time.Second))).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse %q to a valid timestamp", s)
+	}
+	return t, nil
+}
+
+// parsePrometheusDuration parses a duration in either of the formats
+// accepted by the Prometheus HTTP API: a number of seconds, or a duration
+// string.
+func parsePrometheusDuration(s string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	d, err := model.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse %q to a valid duration", s)
+	}
+	return time.Duration(d), nil
+}
@@ -0,0 +1,238 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultOTLPBatchSize is the most spans sent in a single request.
+	DefaultOTLPBatchSize = 512
+	// DefaultOTLPFlushInterval is how often spans are sent when there
+	// aren't enough to fill a batch.
+	DefaultOTLPFlushInterval = 5 * time.Second
+
+	// otlpQueueSize is the number of spans buffered before new spans are
+	// dropped, so a slow or unavailable collector can't block the
+	// operations being traced.
+	otlpQueueSize = 4096
+
+	// statusCodeError is the OTLP status code of failed spans.
+	statusCodeError = 2
+)
+
+// OTLPExporter sends spans to an OpenTelemetry collector, or anything else
+// accepting the OTLP/HTTP protocol using JSON encoding, such as Jaeger.
+type OTLPExporter struct {
+	logger        logrus.FieldLogger
+	client        *http.Client
+	url           string
+	serviceName   string
+	batchSize     int
+	flushInterval time.Duration
+
+	spans chan *Span
+}
+
+// NewOTLPExporter returns an exporter sending spans to the OTLP/HTTP
+// endpoint, such as http://jaeger-collector:4318, as the service named
+// serviceName. Run must be called to send the spans.
+func NewOTLPExporter(logger logrus.FieldLogger, client *http.Client, endpoint, serviceName string) *OTLPExporter {
+	return &OTLPExporter{
+		logger:        logger,
+		client:        client,
+		url:           strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		serviceName:   serviceName,
+		batchSize:     DefaultOTLPBatchSize,
+		flushInterval: DefaultOTLPFlushInterval,
+		spans:         make(chan *Span, otlpQueueSize),
+	}
+}
+
+// ExportSpan queues the span to be sent, dropping it if the queue is full.
+func (e *OTLPExporter) ExportSpan(span *Span) {
+	select {
+	case e.spans <- span:
+	default:
+		e.logger.Warnf("dropping span %s, the queue of spans to export is full", span.Name)
+	}
+}
+
+// Run sends the queued spans in batches until stopCh is closed, when the
+// remaining spans are sent.
+func (e *OTLPExporter) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, e.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.send(batch); err != nil {
+			e.logger.WithError(err).Warnf("unable to export %d spans", len(batch))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case span := <-e.spans:
+			batch = append(batch, span)
+			if len(batch) >= e.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-stopCh:
+			for {
+				select {
+				case span := <-e.spans:
+					batch = append(batch, span)
+					if len(batch) >= e.batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *OTLPExporter) send(spans []*Span) error {
+	body, err := json.Marshal(e.newExportRequest(spans))
+	if err != nil {
+		return err
+	}
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("got status %d from %s: %s", resp.StatusCode, e.url, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// The following types are the JSON encoding of an OTLP
+// ExportTraceServiceRequest. IDs are hex encoded, and 64-bit integers are
+// strings.
+
+type otlpExportRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              SpanKind       `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            *otlpStatus    `json:"status,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func (e *OTLPExporter) newExportRequest(spans []*Span) otlpExportRequest {
+	otlpSpans := make([]otlpSpan, len(spans))
+	for i, span := range spans {
+		otlpSpans[i] = newOTLPSpan(span)
+	}
+	return otlpExportRequest{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: otlpResource{
+					Attributes: []otlpKeyValue{newOTLPKeyValue(String("service.name", e.serviceName))},
+				},
+				ScopeSpans: []otlpScopeSpans{
+					{
+						Scope: otlpScope{Name: "github.com/operator-framework/operator-metering"},
+						Spans: otlpSpans,
+					},
+				},
+			},
+		},
+	}
+}
+
+func newOTLPSpan(span *Span) otlpSpan {
+	otlp := otlpSpan{
+		TraceID:           hex.EncodeToString(span.TraceID[:]),
+		SpanID:            hex.EncodeToString(span.SpanID[:]),
+		Name:              span.Name,
+		Kind:              span.Kind,
+		StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.EndTime().UnixNano(), 10),
+	}
+	if span.ParentSpanID != ([8]byte{}) {
+		otlp.ParentSpanID = hex.EncodeToString(span.ParentSpanID[:])
+	}
+	for _, attr := range span.Attributes() {
+		otlp.Attributes = append(otlp.Attributes, newOTLPKeyValue(attr))
+	}
+	if err := span.Err(); err != nil {
+		otlp.Status = &otlpStatus{Code: statusCodeError, Message: err.Error()}
+	}
+	return otlp
+}
+
+func newOTLPKeyValue(attr Attribute) otlpKeyValue {
+	var value otlpAnyValue
+	switch v := attr.Value.(type) {
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		value.IntValue = &s
+	default:
+		s := fmt.Sprint(v)
+		value.StringValue = &s
+	}
+	return otlpKeyValue{Key: attr.Key, Value: value}
+}
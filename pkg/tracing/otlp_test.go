@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	var path, contentType string
+	var req otlpExportRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+	}))
+	defer srv.Close()
+
+	exporter := NewOTLPExporter(logrus.New(), srv.Client(), srv.URL+"/", "reporting-operator")
+	tracer := NewTracer(exporter, 1)
+
+	ctx, root := tracer.Start(context.Background(), "report.generate", String("metering.key", "metering/test"))
+	require.NotNil(t, root)
+	_, child := StartClientSpan(ctx, "presto.query", Int64("rows", 10))
+	require.NotNil(t, child)
+	child.SetError(errors.New("query failed"))
+	child.End()
+	root.End()
+	root.End()
+
+	stopCh := make(chan struct{})
+	close(stopCh)
+	exporter.Run(stopCh)
+
+	assert.Equal(t, "/v1/traces", path)
+	assert.Equal(t, "application/json", contentType)
+	require.Len(t, req.ResourceSpans, 1)
+	assert.Equal(t, "service.name", req.ResourceSpans[0].Resource.Attributes[0].Key)
+	assert.Equal(t, "reporting-operator", *req.ResourceSpans[0].Resource.Attributes[0].Value.StringValue)
+	require.Len(t, req.ResourceSpans[0].ScopeSpans, 1)
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2, "expected ending a span twice to only export it once")
+
+	exportedChild, exportedRoot := spans[0], spans[1]
+	assert.Equal(t, "presto.query", exportedChild.Name)
+	assert.Equal(t, SpanKindClient, exportedChild.Kind)
+	assert.Equal(t, exportedRoot.TraceID, exportedChild.TraceID)
+	assert.Equal(t, exportedRoot.SpanID, exportedChild.ParentSpanID)
+	assert.Equal(t, TraceIDFromContext(ctx), exportedRoot.TraceID)
+	assert.Len(t, exportedRoot.TraceID, 32)
+	assert.Len(t, exportedRoot.SpanID, 16)
+	assert.Equal(t, "10", *exportedChild.Attributes[0].Value.IntValue)
+	require.NotNil(t, exportedChild.Status)
+	assert.Equal(t, statusCodeError, exportedChild.Status.Code)
+	assert.Equal(t, "query failed", exportedChild.Status.Message)
+
+	assert.Equal(t, "report.generate", exportedRoot.Name)
+	assert.Empty(t, exportedRoot.ParentSpanID)
+	assert.Nil(t, exportedRoot.Status)
+	assert.Equal(t, "metering/test", *exportedRoot.Attributes[0].Value.StringValue)
+}
+
+func TestTracerSampling(t *testing.T) {
+	tracer := NewTracer(NewOTLPExporter(logrus.New(), http.DefaultClient, "http://localhost:4318", "test"), 0)
+	ctx, span := tracer.Start(context.Background(), "report.generate")
+	assert.Nil(t, span, "expected no traces to be sampled")
+
+	// spans which aren't sampled, and their children, can still be used
+	_, child := StartSpan(ctx, "presto.query")
+	assert.Nil(t, child)
+	child.SetAttributes(String("db.system", "presto"))
+	child.SetError(errors.New("query failed"))
+	child.End()
+	assert.Empty(t, TraceIDFromContext(ctx))
+
+	var nilTracer *Tracer
+	_, span = nilTracer.Start(context.Background(), "report.generate")
+	assert.Nil(t, span, "expected a nil Tracer not to trace")
+}
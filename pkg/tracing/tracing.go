@@ -0,0 +1,217 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Exporter sends finished spans to a tracing backend. ExportSpan must not
+// block.
+type Exporter interface {
+	ExportSpan(span *Span)
+}
+
+// Tracer starts the root spans of traces, and exports the spans of the
+// traces it samples.
+type Tracer struct {
+	exporter Exporter
+	// sampleThreshold is compared to the trace ID to sample traces, so every
+	// span of a trace is sampled or not together.
+	sampleThreshold uint64
+	sampleAll       bool
+}
+
+// NewTracer returns a Tracer which exports the given ratio of traces, between
+// 0 and 1, using exporter.
+func NewTracer(exporter Exporter, sampleRatio float64) *Tracer {
+	t := &Tracer{exporter: exporter}
+	switch {
+	case sampleRatio >= 1:
+		t.sampleAll = true
+	case sampleRatio > 0:
+		t.sampleThreshold = uint64(sampleRatio * (1 << 64))
+	}
+	return t
+}
+
+// Attribute is a key and value describing a span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int64 returns an integer attribute.
+func Int64(key string, value int64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is a timed operation within a trace. A nil Span is valid, and
+// ignores everything done with it, so callers don't need to check if the
+// trace was sampled.
+type Span struct {
+	tracer *Tracer
+
+	TraceID      [16]byte
+	SpanID       [8]byte
+	ParentSpanID [8]byte
+	Name         string
+	Kind         SpanKind
+	StartTime    time.Time
+
+	mu         sync.Mutex
+	endTime    time.Time
+	attributes []Attribute
+	err        error
+	ended      bool
+}
+
+// SpanKind describes the relationship of a span to the rest of the trace.
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	// SpanKindClient is the kind of spans for requests to other systems,
+	// such as Presto or Prometheus.
+	SpanKindClient SpanKind = 3
+)
+
+type spanContextKey struct{}
+
+// Start starts the root span of a new trace, returning a context containing
+// it which child spans are started from. The span is nil if the trace isn't
+// sampled, or if t is nil.
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	var traceID [16]byte
+	rand.Read(traceID[:])
+	if !t.sampleAll && binary.BigEndian.Uint64(traceID[:8]) >= t.sampleThreshold {
+		return ctx, nil
+	}
+	span := &Span{
+		tracer:    t,
+		TraceID:   traceID,
+		Name:      name,
+		Kind:      SpanKindInternal,
+		StartTime: time.Now(),
+	}
+	rand.Read(span.SpanID[:])
+	span.SetAttributes(attrs...)
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+// StartSpan starts a span which is a child of the span of ctx, returning a
+// context containing it. The span is nil if ctx has no span, so operations
+// are only traced when something they're part of is traced.
+func StartSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	return startChildSpan(ctx, name, SpanKindInternal, attrs)
+}
+
+// StartClientSpan starts a child span like StartSpan, for a request to
+// another system.
+func StartClientSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	return startChildSpan(ctx, name, SpanKindClient, attrs)
+}
+
+func startChildSpan(ctx context.Context, name string, kind SpanKind, attrs []Attribute) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	span := &Span{
+		tracer:       parent.tracer,
+		TraceID:      parent.TraceID,
+		ParentSpanID: parent.SpanID,
+		Name:         name,
+		Kind:         kind,
+		StartTime:    time.Now(),
+	}
+	rand.Read(span.SpanID[:])
+	span.SetAttributes(attrs...)
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+// SpanFromContext returns the span of ctx, or nil if it has none.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanContextKey{}).(*Span)
+	return span
+}
+
+// TraceIDFromContext returns the hex encoded ID of the trace of ctx, or an
+// empty string if it isn't traced, which is logged so log messages can be
+// found from a trace.
+func TraceIDFromContext(ctx context.Context) string {
+	span := SpanFromContext(ctx)
+	if span == nil {
+		return ""
+	}
+	return hex.EncodeToString(span.TraceID[:])
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil || len(attrs) == 0 {
+		return
+	}
+	s.mu.Lock()
+	s.attributes = append(s.attributes, attrs...)
+	s.mu.Unlock()
+}
+
+// SetError marks the span as failed with err, if it isn't nil.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// End finishes the span and exports it. Calling End more than once has no
+// effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.endTime = time.Now()
+	s.mu.Unlock()
+	s.tracer.exporter.ExportSpan(s)
+}
+
+// EndTime returns when the span ended.
+func (s *Span) EndTime() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.endTime
+}
+
+// Attributes returns the attributes of the span.
+func (s *Span) Attributes() []Attribute {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Attribute(nil), s.attributes...)
+}
+
+// Err returns the error the span failed with, if any.
+func (s *Span) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
@@ -0,0 +1,120 @@
+package prometheus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var (
+	unfinishedWorkDesc = prometheus.NewDesc(
+		"workqueue_unfinished_work_seconds",
+		"How many seconds of work has been done by the items of the workqueue being processed. Large values indicate stuck workers.",
+		[]string{"name"}, nil,
+	)
+	longestRunningProcessorDesc = prometheus.NewDesc(
+		"workqueue_longest_running_processor_seconds",
+		"How many seconds the longest running item of the workqueue has been processed for.",
+		[]string{"name"}, nil,
+	)
+
+	processing = newProcessingCollector(time.Now)
+)
+
+// processingCollector tracks when the items of each queue being processed
+// were taken from the queue, which the version of workqueue we use doesn't
+// expose to it's MetricsProvider.
+type processingCollector struct {
+	mu     sync.Mutex
+	queues map[string]map[interface{}]time.Time
+	now    func() time.Time
+}
+
+func newProcessingCollector(now func() time.Time) *processingCollector {
+	return &processingCollector{
+		queues: make(map[string]map[interface{}]time.Time),
+		now:    now,
+	}
+}
+
+func (c *processingCollector) register(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.queues[name]; !exists {
+		c.queues[name] = make(map[interface{}]time.Time)
+	}
+}
+
+func (c *processingCollector) start(name string, item interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queues[name][item] = c.now()
+}
+
+func (c *processingCollector) done(name string, item interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.queues[name], item)
+}
+
+func (c *processingCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- unfinishedWorkDesc
+	ch <- longestRunningProcessorDesc
+}
+
+func (c *processingCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for name, items := range c.queues {
+		var total, longest float64
+		for _, start := range items {
+			processing := now.Sub(start).Seconds()
+			total += processing
+			if processing > longest {
+				longest = processing
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(unfinishedWorkDesc, prometheus.GaugeValue, total, name)
+		ch <- prometheus.MustNewConstMetric(longestRunningProcessorDesc, prometheus.GaugeValue, longest, name)
+	}
+}
+
+// instrumentedQueue records how long the items taken from the queue have
+// been processed for in the workqueue_unfinished_work_seconds and
+// workqueue_longest_running_processor_seconds metrics.
+type instrumentedQueue struct {
+	workqueue.RateLimitingInterface
+	name       string
+	processing *processingCollector
+}
+
+// NewNamedRateLimitingQueue returns a workqueue.NewNamedRateLimitingQueue,
+// which also records how long it's items have been processed for.
+func NewNamedRateLimitingQueue(rateLimiter workqueue.RateLimiter, name string) workqueue.RateLimitingInterface {
+	return newInstrumentedQueue(workqueue.NewNamedRateLimitingQueue(rateLimiter, name), name, processing)
+}
+
+func newInstrumentedQueue(queue workqueue.RateLimitingInterface, name string, processing *processingCollector) *instrumentedQueue {
+	processing.register(name)
+	return &instrumentedQueue{
+		RateLimitingInterface: queue,
+		name:                  name,
+		processing:            processing,
+	}
+}
+
+func (q *instrumentedQueue) Get() (interface{}, bool) {
+	item, shutdown := q.RateLimitingInterface.Get()
+	if !shutdown {
+		q.processing.start(q.name, item)
+	}
+	return item, shutdown
+}
+
+func (q *instrumentedQueue) Done(item interface{}) {
+	q.processing.done(q.name, item)
+	q.RateLimitingInterface.Done(item)
+}
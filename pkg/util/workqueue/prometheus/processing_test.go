@@ -0,0 +1,65 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestInstrumentedQueue(t *testing.T) {
+	now := time.Date(2019, time.January, 1, 12, 0, 0, 0, time.UTC)
+	collector := newProcessingCollector(func() time.Time { return now })
+	queue := newInstrumentedQueue(workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()), "reports", collector)
+	defer queue.ShutDown()
+
+	assert.Equal(t, map[string]float64{
+		"workqueue_unfinished_work_seconds":           0,
+		"workqueue_longest_running_processor_seconds": 0,
+	}, collectGauges(t, collector), "expected queues without items being processed to be reported")
+
+	queue.Add("metering/first")
+	queue.Add("metering/second")
+	first, _ := queue.Get()
+	now = now.Add(time.Minute)
+	second, _ := queue.Get()
+	now = now.Add(time.Minute)
+
+	assert.Equal(t, map[string]float64{
+		"workqueue_unfinished_work_seconds":           180,
+		"workqueue_longest_running_processor_seconds": 120,
+	}, collectGauges(t, collector))
+
+	queue.Done(first)
+	assert.Equal(t, map[string]float64{
+		"workqueue_unfinished_work_seconds":           60,
+		"workqueue_longest_running_processor_seconds": 60,
+	}, collectGauges(t, collector))
+	queue.Done(second)
+}
+
+// collectGauges returns the values of the gauges of the collector for the
+// reports queue, by name.
+func collectGauges(t *testing.T, collector prometheus.Collector) map[string]float64 {
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(collector))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	gauges := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			require.Equal(t, []*dto.LabelPair{{Name: strPtr("name"), Value: strPtr("reports")}}, metric.GetLabel())
+			gauges[family.GetName()] = metric.GetGauge().GetValue()
+		}
+	}
+	return gauges
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -25,11 +25,80 @@ import (
 
 // Package prometheus sets the workqueue DefaultMetricsFactory to produce
 // prometheus metrics. To use this package, you just have to import it.
+//
+// Besides the metrics named after each queue, the workqueue_* metrics, with
+// the queue's name in the name label, are the same as those of newer
+// Kubernetes controllers, so the same dashboards and alerts can be used.
+
+var (
+	depthVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: "workqueue",
+		Name:      "depth",
+		Help:      "Current depth of the workqueue.",
+	}, []string{"name"})
+	addsVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "workqueue",
+		Name:      "adds_total",
+		Help:      "Total number of adds handled by the workqueue.",
+	}, []string{"name"})
+	latencyVec = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: "workqueue",
+		Name:      "queue_duration_seconds",
+		Help:      "How long in seconds an item stays in the workqueue before being requested.",
+		Buckets:   prometheus.ExponentialBuckets(10e-9, 10, 10),
+	}, []string{"name"})
+	workDurationVec = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: "workqueue",
+		Name:      "work_duration_seconds",
+		Help:      "How long in seconds processing an item from the workqueue takes.",
+		Buckets:   prometheus.ExponentialBuckets(10e-9, 10, 10),
+	}, []string{"name"})
+	retriesVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "workqueue",
+		Name:      "retries_total",
+		Help:      "Total number of retries handled by the workqueue.",
+	}, []string{"name"})
+)
 
 func init() {
+	prometheus.MustRegister(depthVec, addsVec, latencyVec, workDurationVec, retriesVec, processing)
 	workqueue.SetProvider(prometheusMetricsProvider{})
 }
 
+type gauges []workqueue.GaugeMetric
+
+func (g gauges) Inc() {
+	for _, gauge := range g {
+		gauge.Inc()
+	}
+}
+
+func (g gauges) Dec() {
+	for _, gauge := range g {
+		gauge.Dec()
+	}
+}
+
+type counters []workqueue.CounterMetric
+
+func (c counters) Inc() {
+	for _, counter := range c {
+		counter.Inc()
+	}
+}
+
+// microsecondsSummary observes the durations in microseconds observed by the
+// workqueue in summary, and in seconds in histogram.
+type microsecondsSummary struct {
+	summary   workqueue.SummaryMetric
+	histogram prometheus.Observer
+}
+
+func (s microsecondsSummary) Observe(microseconds float64) {
+	s.summary.Observe(microseconds)
+	s.histogram.Observe(microseconds / 1e6)
+}
+
 type prometheusMetricsProvider struct{}
 
 func (_ prometheusMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
@@ -39,7 +108,7 @@ func (_ prometheusMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMe
 		Help:      "Current depth of workqueue: " + name,
 	})
 	prometheus.Register(depth)
-	return depth
+	return gauges{depth, depthVec.WithLabelValues(name)}
 }
 
 func (_ prometheusMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
@@ -49,7 +118,7 @@ func (_ prometheusMetricsProvider) NewAddsMetric(name string) workqueue.CounterM
 		Help:      "Total number of adds handled by workqueue: " + name,
 	})
 	prometheus.Register(adds)
-	return adds
+	return counters{adds, addsVec.WithLabelValues(name)}
 }
 
 func (_ prometheusMetricsProvider) NewLatencyMetric(name string) workqueue.SummaryMetric {
@@ -59,7 +128,7 @@ func (_ prometheusMetricsProvider) NewLatencyMetric(name string) workqueue.Summa
 		Help:      "How long an item stays in workqueue" + name + " before being requested.",
 	})
 	prometheus.Register(latency)
-	return latency
+	return microsecondsSummary{latency, latencyVec.WithLabelValues(name)}
 }
 
 func (_ prometheusMetricsProvider) NewWorkDurationMetric(name string) workqueue.SummaryMetric {
@@ -69,7 +138,7 @@ func (_ prometheusMetricsProvider) NewWorkDurationMetric(name string) workqueue.
 		Help:      "How long processing an item from workqueue" + name + " takes.",
 	})
 	prometheus.Register(workDuration)
-	return workDuration
+	return microsecondsSummary{workDuration, workDurationVec.WithLabelValues(name)}
 }
 
 func (_ prometheusMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
@@ -79,5 +148,5 @@ func (_ prometheusMetricsProvider) NewRetriesMetric(name string) workqueue.Count
 		Help:      "Total number of retries handled by workqueue: " + name,
 	})
 	prometheus.Register(retries)
-	return retries
+	return counters{retries, retriesVec.WithLabelValues(name)}
 }